@@ -0,0 +1,277 @@
+// Package access gates features by app profile. The admin profile can do
+// everything; the editor profile, meant for shared agency machines, can
+// generate and save drafts but cannot publish to WordPress, change the
+// inference providers or edit site credentials. Leaving the editor profile
+// needs the admin password.
+package access
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var logger = logging.For("access")
+
+// MinPasswordLength is the shortest admin password accepted.
+const MinPasswordLength = 8
+
+// profileDocument is the state database document holding the profile.
+const profileDocument = "access_profile"
+
+// Password hashing parameters (Argon2id), lighter than the vault's since
+// the hash only guards a switch of profile.
+const (
+	hashTime    = 1
+	hashMemory  = 32 * 1024 // KiB
+	hashThreads = 2
+	hashLength  = 32
+	saltLength  = 16
+)
+
+// Role is an app profile.
+type Role string
+
+// The app profiles.
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+)
+
+// Label is the role as shown to people.
+func (r Role) Label() string {
+	if r == RoleEditor {
+		return "Editor"
+	}
+	return "Admin"
+}
+
+// Permission is a gated feature.
+type Permission string
+
+// The gated features.
+const (
+	// PermPublish writes to the WordPress site: page content, metadata,
+	// slugs and media.
+	PermPublish Permission = "publish"
+	// PermProviders changes the inference providers: API keys, models and
+	// fallback policies.
+	PermProviders Permission = "providers"
+	// PermCredentials edits, views or deletes saved site credentials.
+	PermCredentials Permission = "credentials"
+)
+
+// describe says what a permission allows, for error messages.
+func (p Permission) describe() string {
+	switch p {
+	case PermPublish:
+		return "publish to WordPress"
+	case PermProviders:
+		return "change the inference providers"
+	case PermCredentials:
+		return "edit site credentials"
+	}
+	return string(p)
+}
+
+// Can reports whether the role has a permission. Editors have none of the
+// gated ones.
+func (r Role) Can(p Permission) bool {
+	return r != RoleEditor
+}
+
+// ErrNotAllowed is returned (wrapped) when the current profile lacks a
+// permission.
+var ErrNotAllowed = errors.New("not allowed in the editor profile")
+
+// ErrWrongPassword is returned when the admin password doesn't match.
+var ErrWrongPassword = errors.New("wrong admin password")
+
+// profile is the stored profile state.
+type profile struct {
+	Role Role   `json:"role"`
+	Salt []byte `json:"salt,omitempty"`
+	Hash []byte `json:"hash,omitempty"` // Argon2id of the admin password; empty until one is set
+}
+
+// Profiles holds the current profile, persisted in the state database. It
+// is safe for concurrent use.
+type Profiles struct {
+	db *storage.DB // nil keeps the profile in memory only
+
+	mu       sync.Mutex
+	current  profile
+	onChange []func(Role)
+}
+
+// Open returns the profile saved in db; the admin profile if none is.
+func Open(db *storage.DB) *Profiles {
+	p := &Profiles{db: db, current: profile{Role: RoleAdmin}}
+	if db == nil {
+		return p
+	}
+	text, ok, err := db.Document(profileDocument)
+	if err != nil {
+		logger.Error("Failed to load the app profile", "error", err)
+	}
+	if ok {
+		var stored profile
+		if err := json.Unmarshal([]byte(text), &stored); err != nil {
+			logger.Warn("Ignoring unreadable app profile", "error", err)
+		} else {
+			if stored.Role != RoleEditor {
+				stored.Role = RoleAdmin
+			}
+			p.current = stored
+		}
+	}
+	return p
+}
+
+// Role returns the current profile.
+func (p *Profiles) Role() Role {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current.Role
+}
+
+// Can reports whether the current profile has a permission.
+func (p *Profiles) Can(perm Permission) bool {
+	return p.Role().Can(perm)
+}
+
+// Check returns an error wrapping ErrNotAllowed if the current profile
+// lacks a permission.
+func (p *Profiles) Check(perm Permission) error {
+	if p.Can(perm) {
+		return nil
+	}
+	return fmt.Errorf("cannot %s: %w; switch to the admin profile in Settings", perm.describe(), ErrNotAllowed)
+}
+
+// HasAdminPassword reports whether an admin password has been set.
+func (p *Profiles) HasAdminPassword() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.current.Hash) > 0
+}
+
+// SetAdminPassword sets or changes the admin password. Only the admin
+// profile can, and changing it needs the current one.
+func (p *Profiles) SetAdminPassword(current, password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("the admin password must have at least %d characters", MinPasswordLength)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current.Role != RoleAdmin {
+		return fmt.Errorf("only the admin profile can set the admin password")
+	}
+	if len(p.current.Hash) > 0 && !p.matchesLocked(current) {
+		return ErrWrongPassword
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate a salt: %w", err)
+	}
+	next := p.current
+	next.Salt = salt
+	next.Hash = hashPassword(password, salt)
+	if err := p.saveLocked(next); err != nil {
+		return err
+	}
+	logger.Info("Set the admin password")
+	return nil
+}
+
+// SwitchToEditor moves to the editor profile. An admin password must be set
+// first, or anyone could switch back.
+func (p *Profiles) SwitchToEditor() error {
+	p.mu.Lock()
+	if len(p.current.Hash) == 0 {
+		p.mu.Unlock()
+		return fmt.Errorf("set an admin password before switching to the editor profile")
+	}
+	if p.current.Role == RoleEditor {
+		p.mu.Unlock()
+		return nil
+	}
+	return p.switchLocked(RoleEditor)
+}
+
+// SwitchToAdmin moves to the admin profile if password is the admin
+// password.
+func (p *Profiles) SwitchToAdmin(password string) error {
+	p.mu.Lock()
+	if p.current.Role == RoleAdmin {
+		p.mu.Unlock()
+		return nil
+	}
+	if !p.matchesLocked(password) {
+		p.mu.Unlock()
+		logger.Warn("Refused switch to the admin profile: wrong password")
+		return ErrWrongPassword
+	}
+	return p.switchLocked(RoleAdmin)
+}
+
+// OnChange registers a listener called with the new role whenever the
+// profile changes.
+func (p *Profiles) OnChange(listener func(Role)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onChange = append(p.onChange, listener)
+}
+
+// switchLocked saves the new role, unlocks p and calls the listeners.
+func (p *Profiles) switchLocked(role Role) error {
+	next := p.current
+	next.Role = role
+	if err := p.saveLocked(next); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	listeners := append([]func(Role){}, p.onChange...)
+	p.mu.Unlock()
+	logger.Info("Switched app profile", "role", role)
+	for _, listener := range listeners {
+		listener(role)
+	}
+	return nil
+}
+
+// saveLocked persists next and makes it current.
+func (p *Profiles) saveLocked(next profile) error {
+	if p.db != nil {
+		data, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		if err := p.db.SetDocument(profileDocument, string(data)); err != nil {
+			return fmt.Errorf("failed to save the app profile: %w", err)
+		}
+	}
+	p.current = next
+	return nil
+}
+
+// matchesLocked reports whether password is the admin password.
+func (p *Profiles) matchesLocked(password string) bool {
+	if len(p.current.Hash) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hashPassword(password, p.current.Salt), p.current.Hash) == 1
+}
+
+// hashPassword derives the stored hash of an admin password.
+func hashPassword(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, hashTime, hashMemory, hashThreads, hashLength)
+}
@@ -0,0 +1,68 @@
+package access
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestProfilesGateEditors(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	p := Open(db)
+	if p.Role() != RoleAdmin || p.Check(PermPublish) != nil {
+		t.Fatalf("Expected to start as admin with every permission")
+	}
+	if err := p.SwitchToEditor(); err == nil {
+		t.Error("Expected switching to editor without an admin password to fail")
+	}
+	if err := p.SetAdminPassword("", "short"); err == nil {
+		t.Error("Expected a short admin password to be rejected")
+	}
+	if err := p.SetAdminPassword("", "agency secret"); err != nil {
+		t.Fatalf("SetAdminPassword failed: %v", err)
+	}
+
+	var changes []Role
+	p.OnChange(func(r Role) { changes = append(changes, r) })
+	if err := p.SwitchToEditor(); err != nil {
+		t.Fatalf("SwitchToEditor failed: %v", err)
+	}
+	for _, perm := range []Permission{PermPublish, PermProviders, PermCredentials} {
+		if err := p.Check(perm); !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Check(%s) = %v, want ErrNotAllowed", perm, err)
+		}
+	}
+	if err := p.SetAdminPassword("agency secret", "another secret"); err == nil {
+		t.Error("Expected the editor profile not to change the admin password")
+	}
+
+	// The profile survives a restart
+	p = Open(db)
+	if p.Role() != RoleEditor {
+		t.Fatalf("Expected the editor profile after reopening, got %s", p.Role())
+	}
+	p.OnChange(func(r Role) { changes = append(changes, r) })
+	if err := p.SwitchToAdmin("wrong password"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("SwitchToAdmin with a wrong password = %v", err)
+	}
+	if err := p.SwitchToAdmin("agency secret"); err != nil {
+		t.Fatalf("SwitchToAdmin failed: %v", err)
+	}
+	if p.Role() != RoleAdmin || len(changes) != 2 || changes[0] != RoleEditor || changes[1] != RoleAdmin {
+		t.Errorf("Unexpected role %s and changes %v", p.Role(), changes)
+	}
+
+	if err := p.SetAdminPassword("wrong password", "another secret"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Changing the password with a wrong current one = %v", err)
+	}
+	if err := p.SetAdminPassword("agency secret", "another secret"); err != nil {
+		t.Errorf("Changing the password failed: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package analytics reads page views from Google Analytics 4 or Jetpack
+// Stats and finds a site's top and declining pages.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("analytics")
+
+// DefaultDays is the length of the period compared with the one before it.
+const DefaultDays = 28
+
+// DecliningDrop is the fall in views, as a fraction of the previous
+// period's, from which a page counts as declining.
+const DecliningDrop = 0.25
+
+// MinViews is the least views a page needs in the previous period to count
+// as declining, so pages with a handful of visits aren't reported.
+const MinViews = 20
+
+// cacheFor is how long trends are reused before they are read again.
+const cacheFor = time.Hour
+
+// Source reads page views for a site.
+type Source interface {
+	// Name identifies the source, e.g. "Google Analytics".
+	Name() string
+	// PageViews returns the views of each page of the site at host from
+	// start to end (inclusive days), keyed by URL path.
+	PageViews(ctx context.Context, host string, start, end time.Time) (map[string]int, error)
+}
+
+// FromEnv returns the configured source: Google Analytics 4 if
+// GA4_PROPERTY_ID is set, else Jetpack Stats if JETPACK_STATS_TOKEN is set.
+// It returns nil without an error when neither is, as analytics are
+// optional.
+func FromEnv() (Source, error) {
+	if property := os.Getenv("GA4_PROPERTY_ID"); property != "" {
+		path := os.Getenv("GA_CREDENTIALS_FILE")
+		if path == "" {
+			path = os.Getenv("GSC_CREDENTIALS_FILE") // One service account can read both
+		}
+		if path == "" {
+			return nil, fmt.Errorf("GA4_PROPERTY_ID is set, but GA_CREDENTIALS_FILE is not")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Google Analytics credentials: %w", err)
+		}
+		return NewGA4Source(property, data)
+	}
+	if token := os.Getenv("JETPACK_STATS_TOKEN"); token != "" {
+		return NewJetpackSource(token), nil
+	}
+	return nil, nil
+}
+
+// PageTrend compares a page's views in two consecutive periods.
+type PageTrend struct {
+	Path          string
+	Views         int // In the latest period
+	PreviousViews int // In the period before
+}
+
+// Change is the change in views as a fraction of the previous period's,
+// e.g. -0.4 for a 40% drop. A page without previous views has a change of 1.
+func (t PageTrend) Change() float64 {
+	if t.PreviousViews == 0 {
+		if t.Views == 0 {
+			return 0
+		}
+		return 1
+	}
+	return float64(t.Views-t.PreviousViews) / float64(t.PreviousViews)
+}
+
+// Declining reports whether the page had at least MinViews and lost at least
+// DecliningDrop of them.
+func (t PageTrend) Declining() bool {
+	return t.PreviousViews >= MinViews && t.Change() <= -DecliningDrop
+}
+
+// Compare builds the trends of every page seen in either period, most
+// viewed first.
+func Compare(current, previous map[string]int) []PageTrend {
+	byPath := map[string]*PageTrend{}
+	trend := func(path string) *PageTrend {
+		path = Path(path)
+		if byPath[path] == nil {
+			byPath[path] = &PageTrend{Path: path}
+		}
+		return byPath[path]
+	}
+	for path, views := range current {
+		trend(path).Views += views
+	}
+	for path, views := range previous {
+		trend(path).PreviousViews += views
+	}
+	trends := make([]PageTrend, 0, len(byPath))
+	for _, t := range byPath {
+		trends = append(trends, *t)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Views != trends[j].Views {
+			return trends[i].Views > trends[j].Views
+		}
+		return trends[i].Path < trends[j].Path
+	})
+	return trends
+}
+
+// Top returns the n most viewed pages of trends, which Compare sorted.
+func Top(trends []PageTrend, n int) []PageTrend {
+	return trends[:min(n, len(trends))]
+}
+
+// Declining returns the declining pages, most views lost first.
+func Declining(trends []PageTrend) []PageTrend {
+	var declining []PageTrend
+	for _, t := range trends {
+		if t.Declining() {
+			declining = append(declining, t)
+		}
+	}
+	sort.SliceStable(declining, func(i, j int) bool {
+		return declining[i].PreviousViews-declining[i].Views > declining[j].PreviousViews-declining[j].Views
+	})
+	return declining
+}
+
+// Path returns the URL path of a page link or path, without a trailing
+// slash or query, to match pages across sources: "/blog/post".
+func Path(link string) string {
+	if u, err := url.Parse(link); err == nil {
+		link = u.Path
+	}
+	if link = strings.TrimRight(link, "/"); link == "" {
+		return "/"
+	}
+	if !strings.HasPrefix(link, "/") {
+		link = "/" + link
+	}
+	return link
+}
+
+// Service reads trends from a source, keeping them for an hour per site.
+type Service struct {
+	source Source
+
+	mu    sync.Mutex
+	cache map[string]cachedTrends
+}
+
+type cachedTrends struct {
+	trends []PageTrend
+	read   time.Time
+}
+
+// NewService creates a service reading from source.
+func NewService(source Source) *Service {
+	return &Service{source: source, cache: map[string]cachedTrends{}}
+}
+
+// SourceName names the service's source.
+func (s *Service) SourceName() string {
+	return s.source.Name()
+}
+
+// Trends compares the views of the site at siteURL over the last DefaultDays
+// with the DefaultDays before, most viewed first.
+func (s *Service) Trends(ctx context.Context, siteURL string) ([]PageTrend, error) {
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid site URL %q", siteURL)
+	}
+	host := u.Hostname()
+	s.mu.Lock()
+	if cached, ok := s.cache[host]; ok && time.Since(cached.read) < cacheFor {
+		s.mu.Unlock()
+		return cached.trends, nil
+	}
+	s.mu.Unlock()
+
+	end := time.Now().AddDate(0, 0, -1) // Today's views are still coming in
+	start := end.AddDate(0, 0, -(DefaultDays - 1))
+	current, err := s.source.PageViews(ctx, host, start, end)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.source.PageViews(ctx, host, start.AddDate(0, 0, -DefaultDays), start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	trends := Compare(current, previous)
+	logger.Info("Read page views", "source", s.source.Name(), "host", host, "pages", len(trends))
+
+	s.mu.Lock()
+	s.cache[host] = cachedTrends{trends: trends, read: time.Now()}
+	s.mu.Unlock()
+	return trends, nil
+}
+
+// Lookup returns the trend of the page at link, if it had views.
+func Lookup(trends []PageTrend, link string) (PageTrend, bool) {
+	path := Path(link)
+	for _, t := range trends {
+		if t.Path == path {
+			return t, true
+		}
+	}
+	return PageTrend{}, false
+}
+
+// RefreshPrompt is the Generator request for improving the page titled
+// title, worded for a declining page or for one that does well.
+func (t PageTrend) RefreshPrompt(title string) string {
+	if t.Declining() {
+		return fmt.Sprintf("Traffic to the page %q fell from %d to %d views (%.0f%%) in the last %d days compared with the %d days before. "+
+			"Refresh it so it serves today's searchers: update outdated facts, answer the questions readers ask now, and sharpen the title and introduction. "+
+			"Keep its structure and everything that is still accurate.", title, t.PreviousViews, t.Views, t.Change()*100, DefaultDays, DefaultDays)
+	}
+	return fmt.Sprintf("The page %q is one of the site's most visited, with %d views in the last %d days. "+
+		"Improve it without losing what already works: keep its structure, headings and main points, fix anything outdated, and add what a reader would still miss.", title, t.Views, DefaultDays)
+}
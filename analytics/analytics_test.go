@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	trends := Compare(
+		map[string]int{"/cold-brew/": 300, "/espresso": 50, "/new-post/": 80, "/": 500},
+		map[string]int{"/cold-brew": 200, "/espresso/": 120, "/grinders/": 40, "/?ref=x": 450, "/tiny/": 10},
+	)
+	if len(trends) != 6 || trends[0].Path != "/" || trends[0].PreviousViews != 450 {
+		t.Fatalf("Unexpected trends: %+v", trends)
+	}
+	if top := Top(trends, 2); len(top) != 2 || top[1].Path != "/cold-brew" || top[1].Change() != 0.5 {
+		t.Errorf("Unexpected top pages: %+v", top)
+	}
+	declining := Declining(trends)
+	if len(declining) != 2 || declining[0].Path != "/espresso" || declining[1].Path != "/grinders" {
+		t.Errorf("Expected /espresso then /grinders to be declining, got %+v", declining)
+	}
+	if trend, ok := Lookup(trends, "https://example.com/new-post/"); !ok || trend.Change() != 1 {
+		t.Errorf("Lookup gave %+v, %v", trend, ok)
+	}
+}
+
+func TestPath(t *testing.T) {
+	for link, want := range map[string]string{
+		"https://example.com/blog/post/?utm=1": "/blog/post",
+		"https://example.com":                  "/",
+		"blog/post/":                           "/blog/post",
+		"/":                                    "/",
+	} {
+		if got := Path(link); got != want {
+			t.Errorf("Path(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+func TestGA4Source(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/properties/1234:runReport" || !strings.Contains(string(body), `"value":"example.com"`) {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"rows": [
+			{"dimensionValues": [{"value": "/cold-brew/"}], "metricValues": [{"value": "120"}]},
+			{"dimensionValues": [{"value": "/cold-brew"}], "metricValues": [{"value": "5"}]}
+		]}`))
+	}))
+	defer server.Close()
+	t.Setenv("GA4_API_ENDPOINT", server.URL)
+
+	views, err := newGA4Source("properties/1234", server.Client()).PageViews(context.Background(), "example.com", time.Now().AddDate(0, 0, -7), time.Now())
+	if err != nil {
+		t.Fatalf("PageViews failed: %v", err)
+	}
+	if len(views) != 1 || views["/cold-brew"] != 125 {
+		t.Errorf("Unexpected views: %v", views)
+	}
+}
+
+func TestJetpackSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sites/example.com/stats/top-posts" || r.URL.Query().Get("num") != "7" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"days": {
+			"2024-05-06": {"postviews": [{"href": "https://example.com/cold-brew/", "views": 30}]},
+			"2024-05-07": {"postviews": [{"href": "https://example.com/cold-brew/", "views": 12}, {"href": "https://example.com/", "views": 4}]}
+		}}`))
+	}))
+	defer server.Close()
+	t.Setenv("JETPACK_API_ENDPOINT", server.URL)
+
+	end := time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC)
+	views, err := NewJetpackSource("token").PageViews(context.Background(), "example.com", end.AddDate(0, 0, -6), end)
+	if err != nil {
+		t.Fatalf("PageViews failed: %v", err)
+	}
+	if views["/cold-brew"] != 42 || views["/"] != 4 {
+		t.Errorf("Unexpected views: %v", views)
+	}
+}
+
+func TestRefreshPrompt(t *testing.T) {
+	declining := PageTrend{Path: "/espresso", Views: 50, PreviousViews: 120}
+	if prompt := declining.RefreshPrompt("Espresso Guide"); !strings.Contains(prompt, `"Espresso Guide" fell from 120 to 50 views (-58%)`) {
+		t.Errorf("Unexpected prompt for a declining page:\n%s", prompt)
+	}
+	top := PageTrend{Path: "/cold-brew", Views: 900, PreviousViews: 800}
+	if prompt := top.RefreshPrompt("Cold Brew"); !strings.Contains(prompt, "900 views in the last 28 days") {
+		t.Errorf("Unexpected prompt for a top page:\n%s", prompt)
+	}
+}
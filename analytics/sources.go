@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// ga4Scope is the OAuth scope for reading Google Analytics data.
+const ga4Scope = "https://www.googleapis.com/auth/analytics.readonly"
+
+// maxRows is the most pages read for one period.
+const maxRows = 10000
+
+// GA4Source reads page views from a Google Analytics 4 property with a
+// service account.
+type GA4Source struct {
+	property string
+	http     *http.Client
+	endpoint string
+}
+
+// NewGA4Source creates a source for the numeric GA4 property ID, using a
+// service account's JSON key. The service account must be added as a viewer
+// of the property.
+func NewGA4Source(property string, credentialsJSON []byte) (*GA4Source, error) {
+	config, err := google.JWTConfigFromJSON(credentialsJSON, ga4Scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Google Analytics credentials: %w", err)
+	}
+	httpClient := config.Client(context.Background())
+	httpClient.Timeout = 60 * time.Second
+	return newGA4Source(property, httpClient), nil
+}
+
+// newGA4Source creates a source sending requests with httpClient to the
+// GA4_API_ENDPOINT base URL.
+func newGA4Source(property string, httpClient *http.Client) *GA4Source {
+	return &GA4Source{
+		property: strings.TrimPrefix(property, "properties/"),
+		http:     httpClient,
+		endpoint: endpointFromEnv("GA4_API_ENDPOINT", "https://analyticsdata.googleapis.com/v1beta/"),
+	}
+}
+
+// Name identifies Google Analytics.
+func (s *GA4Source) Name() string {
+	return "Google Analytics"
+}
+
+// PageViews reads the views of each page path on host.
+func (s *GA4Source) PageViews(ctx context.Context, host string, start, end time.Time) (map[string]int, error) {
+	request := map[string]interface{}{
+		"dateRanges": []map[string]string{{"startDate": start.Format("2006-01-02"), "endDate": end.Format("2006-01-02")}},
+		"dimensions": []map[string]string{{"name": "pagePath"}},
+		"metrics":    []map[string]string{{"name": "screenPageViews"}},
+		"dimensionFilter": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"fieldName":    "hostName",
+				"stringFilter": map[string]string{"matchType": "EXACT", "value": host},
+			},
+		},
+		"limit": maxRows,
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%sproperties/%s:runReport", s.endpoint, s.property), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Google Analytics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var result struct {
+		Rows []struct {
+			DimensionValues []struct {
+				Value string `json:"value"`
+			} `json:"dimensionValues"`
+			MetricValues []struct {
+				Value string `json:"value"`
+			} `json:"metricValues"`
+		} `json:"rows"`
+	}
+	if err := doJSON(s.http, req, "Google Analytics", &result); err != nil {
+		return nil, err
+	}
+	views := map[string]int{}
+	for _, row := range result.Rows {
+		if len(row.DimensionValues) == 0 || len(row.MetricValues) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(row.MetricValues[0].Value)
+		if err != nil {
+			continue
+		}
+		views[Path(row.DimensionValues[0].Value)] += n
+	}
+	return views, nil
+}
+
+// JetpackSource reads page views from Jetpack Stats through the
+// WordPress.com REST API.
+type JetpackSource struct {
+	token    string
+	http     *http.Client
+	endpoint string
+}
+
+// NewJetpackSource creates a source authorized by a WordPress.com OAuth
+// token for the sites it reads.
+func NewJetpackSource(token string) *JetpackSource {
+	return &JetpackSource{
+		token:    token,
+		http:     &http.Client{Timeout: 60 * time.Second},
+		endpoint: endpointFromEnv("JETPACK_API_ENDPOINT", "https://public-api.wordpress.com/rest/v1.1/"),
+	}
+}
+
+// Name identifies Jetpack Stats.
+func (s *JetpackSource) Name() string {
+	return "Jetpack Stats"
+}
+
+// PageViews reads the views of each post and page of the Jetpack site at
+// host, summed over the days of the period.
+func (s *JetpackSource) PageViews(ctx context.Context, host string, start, end time.Time) (map[string]int, error) {
+	days := int(end.Sub(start).Hours()/24+0.5) + 1
+	query := url.Values{
+		"period": {"day"},
+		"date":   {end.Format("2006-01-02")},
+		"num":    {strconv.Itoa(days)},
+		"max":    {strconv.Itoa(maxRows)},
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%ssites/%s/stats/top-posts?%s", s.endpoint, url.PathEscape(host), query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Jetpack Stats request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	var result struct {
+		Days map[string]struct {
+			PostViews []struct {
+				Href  string `json:"href"`
+				Views int    `json:"views"`
+			} `json:"postviews"`
+		} `json:"days"`
+	}
+	if err := doJSON(s.http, req, "Jetpack Stats", &result); err != nil {
+		return nil, err
+	}
+	views := map[string]int{}
+	for _, day := range result.Days {
+		for _, post := range day.PostViews {
+			views[Path(post.Href)] += post.Views
+		}
+	}
+	return views, nil
+}
+
+// endpointFromEnv returns the base URL in the environment variable, or
+// fallback, ending in a slash.
+func endpointFromEnv(variable, fallback string) string {
+	endpoint := os.Getenv(variable)
+	if endpoint == "" {
+		endpoint = fallback
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return endpoint
+}
+
+// doJSON sends req and decodes the JSON response into result.
+func doJSON(client *http.Client, req *http.Request, service string, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s request failed: HTTP %d: %s", service, resp.StatusCode, string(data))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", service, err)
+	}
+	return nil
+}
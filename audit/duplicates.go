@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+
+	"Inference_Engine/embeddings"
+	"Inference_Engine/storage"
+)
+
+// DefaultDuplicateThreshold is the similarity from which two pages are
+// reported as covering the same topic.
+const DefaultDuplicateThreshold = 0.85
+
+// PairKey identifies a pair of pages whichever order they are given in.
+func PairKey(a, b int) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d-%d", a, b)
+}
+
+// Duplicates returns the index's pairs of pages at least threshold similar,
+// most similar first, leaving out pairs the user has dismissed.
+func Duplicates(idx embeddings.Index, threshold float64, dismissed map[string]bool) []embeddings.Pair {
+	var pairs []embeddings.Pair
+	for _, pair := range idx.Pairs(threshold) {
+		if !dismissed[PairKey(pair.A.ID, pair.B.ID)] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// dismissedList is the state database list of a site's dismissed pairs.
+func dismissedList(site string) string {
+	return "duplicates_dismissed:" + site
+}
+
+// DismissedPairs remembers, per site, the duplicate pairs the user has
+// reviewed and decided to keep. It is safe for concurrent use.
+type DismissedPairs struct {
+	db *storage.DB // nil keeps dismissals in memory only
+
+	mu     sync.Mutex
+	memory map[string][]string // Used when db is nil
+}
+
+// NewDismissedPairs returns the dismissals saved in db.
+func NewDismissedPairs(db *storage.DB) *DismissedPairs {
+	return &DismissedPairs{db: db, memory: map[string][]string{}}
+}
+
+// Keys returns the site's dismissed pairs, by PairKey.
+func (d *DismissedPairs) Keys(site string) map[string]bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	keys := map[string]bool{}
+	for _, key := range d.load(site) {
+		keys[key] = true
+	}
+	return keys
+}
+
+// Dismiss stops reporting a pair of pages as duplicates.
+func (d *DismissedPairs) Dismiss(site string, a, b int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	keys := append(d.load(site), PairKey(a, b))
+	if d.db == nil {
+		d.memory[site] = keys
+		return nil
+	}
+	if err := d.db.SetStringList(dismissedList(site), keys); err != nil {
+		return fmt.Errorf("failed to save dismissed duplicates: %w", err)
+	}
+	return nil
+}
+
+// load reads a site's dismissed pairs; the caller holds d.mu.
+func (d *DismissedPairs) load(site string) []string {
+	if d.db == nil {
+		return append([]string(nil), d.memory[site]...)
+	}
+	keys, err := d.db.StringList(dismissedList(site))
+	if err != nil {
+		logger.Error("Failed to load dismissed duplicates", "site", site, "error", err)
+	}
+	return keys
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"testing"
+
+	"Inference_Engine/embeddings"
+)
+
+func TestDuplicates(t *testing.T) {
+	idx := embeddings.Index{Entries: []embeddings.Entry{
+		{ID: 1, Vector: embeddings.Vector{1, 0}},
+		{ID: 2, Vector: embeddings.Vector{0.95, 0.05}},
+		{ID: 3, Vector: embeddings.Vector{0.9, 0.1}},
+		{ID: 4, Vector: embeddings.Vector{0, 1}},
+	}}
+	dismissed := NewDismissedPairs(nil)
+	if pairs := Duplicates(idx, DefaultDuplicateThreshold, dismissed.Keys("site")); len(pairs) != 3 {
+		t.Fatalf("Expected pages 1-3 to pair up, got %+v", pairs)
+	}
+	if err := dismissed.Dismiss("site", 2, 1); err != nil {
+		t.Fatalf("Dismiss failed: %v", err)
+	}
+	pairs := Duplicates(idx, DefaultDuplicateThreshold, dismissed.Keys("site"))
+	for _, pair := range pairs {
+		if PairKey(pair.A.ID, pair.B.ID) == PairKey(1, 2) {
+			t.Errorf("Dismissed pair still reported: %+v", pairs)
+		}
+	}
+	if len(pairs) != 2 {
+		t.Errorf("Expected 2 pairs after dismissing one, got %d", len(pairs))
+	}
+	if len(dismissed.Keys("other")) != 0 {
+		t.Errorf("Expected dismissals to be per site")
+	}
+}
@@ -0,0 +1,230 @@
+// Package audit analyzes a whole site's pages and keeps the resulting
+// reports: content freshness, pages competing for the same topic and SEO
+// issues.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/analytics"
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+var logger = logging.For("audit")
+
+// DefaultStaleMonths is how long a page goes unmodified before a freshness
+// scan looks at it, unless the user picks another age.
+const DefaultStaleMonths = 12
+
+// maxDatedHints caps the candidate references sent to the model per page.
+const maxDatedHints = 20
+
+// OutdatedReference is one statement the model thinks has gone stale.
+type OutdatedReference struct {
+	Text       string `json:"text"`       // As it appears on the page
+	Reason     string `json:"reason"`     // Why it is likely outdated
+	Suggestion string `json:"suggestion"` // What to check or write instead
+}
+
+// PageFreshness is the freshness verdict for one page.
+type PageFreshness struct {
+	PageID     int                 `json:"page_id"`
+	Title      string              `json:"title"`
+	Link       string              `json:"link"`
+	Modified   time.Time           `json:"modified"`
+	Score      int                 `json:"score"` // 0 (current) to 100 (badly outdated)
+	Summary    string              `json:"summary"`
+	References []OutdatedReference `json:"references"`
+	Scanned    time.Time           `json:"scanned"`
+
+	// Views in the last analytics period and the one before; zero without
+	// analytics
+	Views         int `json:"views,omitempty"`
+	PreviousViews int `json:"previous_views,omitempty"`
+}
+
+var (
+	sentenceEnd = regexp.MustCompile(`[.!?]+\s+|\n+`)
+	yearPattern = regexp.MustCompile(`\b(19[5-9]\d|20\d\d)\b`)
+	// "v2.1", "version 3.0" and "1.4.2", but not "4.5 stars"
+	versionPattern = regexp.MustCompile(`(?i)\bv(?:ersion)?\s?\d+(?:\.\d+)+|\b\d+\.\d+\.\d+\b`)
+	pricePattern   = regexp.MustCompile(`[$€£¥]\s?\d[\d,]*(?:\.\d+)?|\b\d[\d,]*(?:\.\d+)?\s?(?:USD|EUR|GBP|dollars|euros)\b`)
+)
+
+// DatedHints finds the sentences of text that mention a past year, a
+// version number or a price: the references most likely to go stale. They
+// are sent to the model as hints, not as the only things to check.
+func DatedHints(text string, now time.Time) []string {
+	var hints []string
+	seen := map[string]bool{}
+	for _, sentence := range sentenceEnd.Split(text, -1) {
+		sentence = strings.TrimSpace(sentence)
+		if sentence == "" || seen[sentence] {
+			continue
+		}
+		dated := versionPattern.MatchString(sentence) || pricePattern.MatchString(sentence)
+		for _, year := range yearPattern.FindAllString(sentence, -1) {
+			if y, _ := strconv.Atoi(year); y < now.Year() {
+				dated = true
+			}
+		}
+		if dated {
+			seen[sentence] = true
+			hints = append(hints, sentence)
+			if len(hints) == maxDatedHints {
+				break
+			}
+		}
+	}
+	return hints
+}
+
+// ParseFreshness reads the model's answer to a freshness prompt into the
+// score, summary and references of result.
+func ParseFreshness(output string, result *PageFreshness) error {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in the model's answer")
+	}
+	var answer struct {
+		Score      json.Number         `json:"score"`
+		Summary    string              `json:"summary"`
+		References []OutdatedReference `json:"references"`
+	}
+	if err := json.Unmarshal([]byte(output[start:end+1]), &answer); err != nil {
+		return fmt.Errorf("failed to parse freshness score: %w", err)
+	}
+	score, err := answer.Score.Float64()
+	if err != nil {
+		return fmt.Errorf("invalid freshness score %q", answer.Score)
+	}
+	result.Score = min(max(int(score+0.5), 0), 100)
+	result.Summary = strings.TrimSpace(answer.Summary)
+	result.References = nil
+	for _, ref := range answer.References {
+		if strings.TrimSpace(ref.Text) != "" {
+			result.References = append(result.References, ref)
+		}
+	}
+	return nil
+}
+
+// RefreshPrompt is the Generator request for updating the page, built from
+// the report's findings.
+func (p PageFreshness) RefreshPrompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Update the page %q so it is current. Keep its structure, tone and everything that is still accurate.", p.Title)
+	if p.PreviousViews > 0 && p.Views < p.PreviousViews {
+		fmt.Fprintf(&b, " Its traffic fell from %d to %d views in the last %d days, so make sure it fully answers what searchers want today.", p.PreviousViews, p.Views, analytics.DefaultDays)
+	}
+	if len(p.References) > 0 {
+		b.WriteString("\n\nThese references look outdated:\n")
+		for _, ref := range p.References {
+			fmt.Fprintf(&b, "- %q: %s", ref.Text, ref.Reason)
+			if ref.Suggestion != "" {
+				fmt.Fprintf(&b, " Suggestion: %s", ref.Suggestion)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// freshnessDocument is the state database document holding a site's report.
+func freshnessDocument(site string) string {
+	return "freshness:" + site
+}
+
+// FreshnessStore keeps the latest freshness report of each site, persisted
+// in the state database. It is safe for concurrent use.
+type FreshnessStore struct {
+	db *storage.DB // nil keeps reports in memory only
+
+	mu     sync.Mutex
+	memory map[string][]PageFreshness // Used when db is nil
+}
+
+// NewFreshnessStore returns the reports saved in db.
+func NewFreshnessStore(db *storage.DB) *FreshnessStore {
+	return &FreshnessStore{db: db, memory: map[string][]PageFreshness{}}
+}
+
+// Report returns a site's pages, most outdated first.
+func (s *FreshnessStore) Report(site string) []PageFreshness {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(site)
+}
+
+// Add records the results of a scan, replacing earlier results for the same
+// pages.
+func (s *FreshnessStore) Add(site string, results ...PageFreshness) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := s.load(site)
+	for _, result := range results {
+		report = append(removePage(report, result.PageID), result)
+	}
+	return s.save(site, report)
+}
+
+// Dismiss removes a page from the report, e.g. once it has been refreshed.
+func (s *FreshnessStore) Dismiss(site string, pageID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(site, removePage(s.load(site), pageID))
+}
+
+// load reads a site's report; the caller holds s.mu.
+func (s *FreshnessStore) load(site string) []PageFreshness {
+	if s.db == nil {
+		return append([]PageFreshness(nil), s.memory[site]...)
+	}
+	text, found, err := s.db.Document(freshnessDocument(site))
+	if err != nil {
+		logger.Error("Failed to load freshness report", "site", site, "error", err)
+	}
+	var report []PageFreshness
+	if found {
+		if err := json.Unmarshal([]byte(text), &report); err != nil {
+			logger.Warn("Saved freshness report no longer parses, ignoring it", "site", site, "error", err)
+		}
+	}
+	return report
+}
+
+// save replaces a site's report, sorted most outdated first; the caller
+// holds s.mu.
+func (s *FreshnessStore) save(site string, report []PageFreshness) error {
+	sort.SliceStable(report, func(i, j int) bool { return report[i].Score > report[j].Score })
+	if s.db == nil {
+		s.memory[site] = report
+		return nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetDocument(freshnessDocument(site), string(data)); err != nil {
+		return fmt.Errorf("failed to save freshness report: %w", err)
+	}
+	return nil
+}
+
+func removePage(report []PageFreshness, pageID int) []PageFreshness {
+	kept := report[:0:0]
+	for _, result := range report {
+		if result.PageID != pageID {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
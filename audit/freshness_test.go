@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatedHints(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	text := "Founded in 2019, we grew fast. Plans start at $19.99 a month.\n" +
+		"We support version 8.1 and plugin v2.3.1. Our team is friendly! " +
+		"In 2026 we opened a second office. Rated 4.5 by users."
+	got := DatedHints(text, now)
+	want := []string{
+		"Founded in 2019, we grew fast",
+		"Plans start at $19.99 a month",
+		"We support version 8.1 and plugin v2.3.1",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("DatedHints = %q, want %q", got, want)
+	}
+}
+
+func TestParseFreshness(t *testing.T) {
+	output := "Sure:\n```json\n{\"score\": 72.6, \"summary\": \" Prices are old. \", \"references\": [" +
+		"{\"text\": \"$19.99\", \"reason\": \"Pricing changed\", \"suggestion\": \"Check the price list\"}, {\"text\": \" \"}]}\n```"
+	var result PageFreshness
+	if err := ParseFreshness(output, &result); err != nil {
+		t.Fatalf("ParseFreshness failed: %v", err)
+	}
+	if result.Score != 73 || result.Summary != "Prices are old." || len(result.References) != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if err := ParseFreshness(`{"score": 250}`, &result); err != nil || result.Score != 100 {
+		t.Errorf("Expected the score to be capped at 100, got %d (%v)", result.Score, err)
+	}
+	if err := ParseFreshness("Nothing looks outdated.", &result); err == nil {
+		t.Errorf("Expected an error without a JSON object")
+	}
+}
+
+func TestFreshnessStore(t *testing.T) {
+	store := NewFreshnessStore(nil)
+	if err := store.Add("site", PageFreshness{PageID: 1, Score: 20}, PageFreshness{PageID: 2, Score: 80}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add("site", PageFreshness{PageID: 1, Score: 90}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	report := store.Report("site")
+	if len(report) != 2 || report[0].PageID != 1 || report[1].PageID != 2 {
+		t.Errorf("Expected page 1 rescored above page 2, got %+v", report)
+	}
+	if err := store.Dismiss("site", 1); err != nil {
+		t.Fatalf("Dismiss failed: %v", err)
+	}
+	if report := store.Report("site"); len(report) != 1 || report[0].PageID != 2 {
+		t.Errorf("Expected only page 2 after dismissing, got %+v", report)
+	}
+	if report := store.Report("other"); len(report) != 0 {
+		t.Errorf("Expected reports to be per site, got %+v", report)
+	}
+}
+
+func TestRefreshPromptTraffic(t *testing.T) {
+	page := PageFreshness{Title: "Cold Brew Guide", References: []OutdatedReference{{Text: "In 2021", Reason: "Past year"}}}
+	if prompt := page.RefreshPrompt(); strings.Contains(prompt, "traffic") || !strings.Contains(prompt, `"In 2021": Past year`) {
+		t.Errorf("Unexpected prompt without analytics:\n%s", prompt)
+	}
+	page.Views, page.PreviousViews = 60, 140
+	if prompt := page.RefreshPrompt(); !strings.Contains(prompt, "traffic fell from 140 to 60 views") {
+		t.Errorf("Expected the traffic drop in the prompt:\n%s", prompt)
+	}
+}
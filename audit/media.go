@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/wordpress"
+)
+
+// maxAltTextLength is the longest alt text kept; screen readers cut longer
+// text short.
+const maxAltTextLength = 125
+
+// MediaText is the alt text and caption proposed for a media library image.
+type MediaText struct {
+	AltText string `json:"alt_text"`
+	Caption string `json:"caption"`
+}
+
+// ParseMediaText reads a vision model's answer to a media text prompt: a
+// JSON object, possibly wrapped in prose or a code fence. Alt text is cut at
+// a word boundary if too long.
+func ParseMediaText(output string) (MediaText, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return MediaText{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var text MediaText
+	if err := json.Unmarshal([]byte(output[start:end+1]), &text); err != nil {
+		return MediaText{}, fmt.Errorf("failed to parse alt text and caption: %w", err)
+	}
+	text.AltText = strings.TrimSpace(text.AltText)
+	text.Caption = strings.TrimSpace(text.Caption)
+	if runes := []rune(text.AltText); len(runes) > maxAltTextLength {
+		cut := string(runes[:maxAltTextLength])
+		if i := strings.LastIndex(cut, " "); i > 0 {
+			cut = cut[:i]
+		}
+		text.AltText = strings.TrimRight(cut, " ,;:")
+	}
+	if text.AltText == "" {
+		return MediaText{}, fmt.Errorf("the model wrote no alt text")
+	}
+	return text, nil
+}
+
+// MissingMediaText returns the images without alt text, or, if captions is
+// set, without alt text or a caption.
+func MissingMediaText(items []wordpress.MediaItem, captions bool) []wordpress.MediaItem {
+	var missing []wordpress.MediaItem
+	for _, item := range items {
+		if item.AltText == "" || (captions && item.Caption == "") {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"Inference_Engine/wordpress"
+)
+
+func TestParseMediaText(t *testing.T) {
+	text, err := ParseMediaText("```json\n{\"alt_text\": \" A red bicycle against a wall \", \"caption\": \"Ready to ride.\"}\n```")
+	if err != nil {
+		t.Fatalf("ParseMediaText failed: %v", err)
+	}
+	if text.AltText != "A red bicycle against a wall" || text.Caption != "Ready to ride." {
+		t.Errorf("Unexpected media text %+v", text)
+	}
+
+	long, err := ParseMediaText(`{"alt_text": "` + strings.Repeat("word ", 40) + `"}`)
+	if err != nil || len(long.AltText) > maxAltTextLength || strings.HasSuffix(long.AltText, " ") {
+		t.Errorf("Expected long alt text to be cut at a word, got %q (%v)", long.AltText, err)
+	}
+
+	if _, err := ParseMediaText(`{"caption": "Only a caption"}`); err == nil {
+		t.Error("Expected an answer without alt text to be rejected")
+	}
+}
+
+func TestMissingMediaText(t *testing.T) {
+	items := []wordpress.MediaItem{
+		{ID: 1, AltText: "Has both", Caption: "Yes"},
+		{ID: 2, AltText: "No caption"},
+		{ID: 3, Caption: "No alt text"},
+	}
+	if got := MissingMediaText(items, false); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("Unexpected images missing alt text %+v", got)
+	}
+	if got := MissingMediaText(items, true); len(got) != 2 {
+		t.Errorf("Unexpected images missing alt text or captions %+v", got)
+	}
+}
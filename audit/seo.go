@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+)
+
+// SEO issue kinds, in report order.
+const (
+	IssueMissingDescription = "Missing meta description"
+	IssueDuplicateTitle     = "Duplicate title"
+	IssueThinContent        = "Thin content"
+	IssueMissingH1          = "Missing H1"
+	IssueMissingAlt         = "Images without alt text"
+)
+
+// SEOIssues lists the issue kinds in report order.
+var SEOIssues = []string{IssueMissingDescription, IssueDuplicateTitle, IssueThinContent, IssueMissingH1, IssueMissingAlt}
+
+// ThinContentWords is the word count below which a page is reported as thin.
+const ThinContentWords = 300
+
+var (
+	h1Pattern  = regexp.MustCompile(`(?i)<h1[\s>]`)
+	imgPattern = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	srcPattern = regexp.MustCompile(`(?is)\bsrc\s*=\s*["']([^"']*)["']`)
+	altPattern = regexp.MustCompile(`(?is)\salt\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// SEOFinding is one SEO problem on one page.
+type SEOFinding struct {
+	PageID int
+	Title  string
+	Link   string
+	Issue  string   // One of the Issue* kinds
+	Detail string   // What was found, e.g. the word count
+	Images []string // For IssueMissingAlt, the sources of the images
+	Others []string // For IssueDuplicateTitle, the other pages' links
+}
+
+// AuditSEO checks every page for the SEO issues and returns the findings,
+// by page, in SEOIssues order.
+func AuditSEO(pages wordpress.PageList) []SEOFinding {
+	byTitle := map[string][]wordpress.Page{}
+	for _, page := range pages {
+		key := strings.ToLower(strings.TrimSpace(html.UnescapeString(page.Title)))
+		byTitle[key] = append(byTitle[key], page)
+	}
+
+	var findings []SEOFinding
+	for _, page := range pages {
+		finding := func(issue, detail string) SEOFinding {
+			return SEOFinding{PageID: page.ID, Title: page.Title, Link: page.Link, Issue: issue, Detail: detail}
+		}
+		if strings.TrimSpace(page.Excerpt) == "" && strings.TrimSpace(page.MetaDescription) == "" {
+			findings = append(findings, finding(IssueMissingDescription, "No excerpt or SEO plugin description is set."))
+		}
+		if title := strings.ToLower(strings.TrimSpace(html.UnescapeString(page.Title))); title != "" && len(byTitle[title]) > 1 {
+			f := finding(IssueDuplicateTitle, "")
+			for _, other := range byTitle[title] {
+				if other.ID != page.ID {
+					f.Others = append(f.Others, other.Link)
+				}
+			}
+			f.Detail = fmt.Sprintf("Also used by %d other pages.", len(f.Others))
+			findings = append(findings, f)
+		}
+		if words := WordCount(page.Content); words < ThinContentWords {
+			findings = append(findings, finding(IssueThinContent, fmt.Sprintf("%d words, fewer than %d.", words, ThinContentWords)))
+		}
+		if !h1Pattern.MatchString(page.Content) {
+			findings = append(findings, finding(IssueMissingH1, "The content has no <h1> heading."))
+		}
+		if images := ImagesWithoutAlt(page.Content); len(images) > 0 {
+			f := finding(IssueMissingAlt, fmt.Sprintf("%d images have no alt text.", len(images)))
+			f.Images = images
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// SortFindings orders findings by issue kind, then page title.
+func SortFindings(findings []SEOFinding) {
+	rank := map[string]int{}
+	for i, issue := range SEOIssues {
+		rank[issue] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Issue != findings[j].Issue {
+			return rank[findings[i].Issue] < rank[findings[j].Issue]
+		}
+		return strings.ToLower(findings[i].Title) < strings.ToLower(findings[j].Title)
+	})
+}
+
+// WordCount counts the words of HTML or plain text content.
+func WordCount(content string) int {
+	text := content
+	if utils.LooksLikeHTML(text) {
+		text = utils.HTMLToMarkdown(text)
+	}
+	return len(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+	}))
+}
+
+// ImagesWithoutAlt returns the sources of the content's images that have no
+// alt text, each once.
+func ImagesWithoutAlt(content string) []string {
+	var sources []string
+	seen := map[string]bool{}
+	for _, tag := range imgPattern.FindAllString(content, -1) {
+		src := srcPattern.FindStringSubmatch(tag)
+		if src == nil || seen[src[1]] || hasAlt(tag) {
+			continue
+		}
+		seen[src[1]] = true
+		sources = append(sources, src[1])
+	}
+	return sources
+}
+
+// hasAlt reports whether an img tag has non-empty alt text.
+func hasAlt(tag string) bool {
+	alt := altPattern.FindStringSubmatch(tag)
+	return alt != nil && strings.TrimSpace(alt[1]+alt[2]) != ""
+}
+
+// SetImageAlts gives the images without alt text the alt text found for
+// their source in alts. Images that already have alt text are left alone.
+func SetImageAlts(content string, alts map[string]string) string {
+	return imgPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		src := srcPattern.FindStringSubmatch(tag)
+		if src == nil || hasAlt(tag) {
+			return tag
+		}
+		alt, ok := alts[src[1]]
+		if !ok || strings.TrimSpace(alt) == "" {
+			return tag
+		}
+		tag = altPattern.ReplaceAllString(tag, "")
+		return strings.Replace(tag, "<img", fmt.Sprintf(`<img alt="%s"`, html.EscapeString(strings.TrimSpace(alt))), 1)
+	})
+}
+
+// InsertH1 adds a main heading at the top of the content.
+func InsertH1(content, heading string) string {
+	return fmt.Sprintf("<h1>%s</h1>\n\n%s", html.EscapeString(heading), strings.TrimLeft(content, "\n"))
+}
+
+// ParseAltText reads the model's answer to an alt text prompt: a JSON
+// object from image source to alt text.
+func ParseAltText(output string) (map[string]string, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var alts map[string]string
+	if err := json.Unmarshal([]byte(output[start:end+1]), &alts); err != nil {
+		return nil, fmt.Errorf("failed to parse alt text: %w", err)
+	}
+	return alts, nil
+}
+
+// CleanLine turns a model's one-line answer (a title or description) into
+// plain text: the first non-empty line, without Markdown emphasis or quotes.
+func CleanLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "*#`\"'“”")
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"Inference_Engine/wordpress"
+)
+
+func TestAuditSEO(t *testing.T) {
+	long := "<h1>Guide</h1><p>" + strings.Repeat("word ", ThinContentWords) + "</p>"
+	pages := wordpress.PageList{
+		{ID: 1, Title: "Pricing", Link: "/pricing", Excerpt: "Our plans", Content: long},
+		{ID: 2, Title: "pricing ", Link: "/pricing-2", MetaDescription: "Plans", Content: long + `<img src="a.png"><img src="b.png" alt="Chart">`},
+		{ID: 3, Title: "About", Link: "/about", Content: "<p>Short page.</p>"},
+	}
+	got := map[int][]string{}
+	for _, f := range AuditSEO(pages) {
+		got[f.PageID] = append(got[f.PageID], f.Issue)
+		if f.Issue == IssueDuplicateTitle && f.PageID == 1 && (len(f.Others) != 1 || f.Others[0] != "/pricing-2") {
+			t.Errorf("Expected page 1's duplicate to be page 2, got %v", f.Others)
+		}
+		if f.Issue == IssueMissingAlt && (len(f.Images) != 1 || f.Images[0] != "a.png") {
+			t.Errorf("Expected only a.png to lack alt text, got %v", f.Images)
+		}
+	}
+	want := map[int][]string{
+		1: {IssueDuplicateTitle},
+		2: {IssueDuplicateTitle, IssueMissingAlt},
+		3: {IssueMissingDescription, IssueThinContent, IssueMissingH1},
+	}
+	for id, issues := range want {
+		if strings.Join(got[id], ", ") != strings.Join(issues, ", ") {
+			t.Errorf("Page %d: got %v, want %v", id, got[id], issues)
+		}
+	}
+}
+
+func TestSetImageAlts(t *testing.T) {
+	content := `<img src="a.png" alt=""><img alt="Kept" src="b.png"><img src='c.png'>`
+	got := SetImageAlts(content, map[string]string{"a.png": "A \"quoted\" chart", "b.png": "Replaced", "c.png": " "})
+	want := `<img alt="A &#34;quoted&#34; chart" src="a.png"><img alt="Kept" src="b.png"><img src='c.png'>`
+	if got != want {
+		t.Errorf("SetImageAlts =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCleanLine(t *testing.T) {
+	if got := CleanLine("\n**\"Fresh Pricing Plans for 2026\"**\nExplanation"); got != "Fresh Pricing Plans for 2026" {
+		t.Errorf("CleanLine = %q", got)
+	}
+}
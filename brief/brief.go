@@ -0,0 +1,144 @@
+// Package brief reads structured content briefs, the plan an editor hands a
+// writer, and turns them into a prompt, instructions and SEO targets.
+package brief
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"Inference_Engine/seo"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Example is offered as a starting point. JSON briefs use the same field
+// names.
+const Example = `title: How to Make Cold Brew at Home
+target_keyword: cold brew
+secondary_keywords: [iced coffee, coffee to water ratio]
+audience: Home baristas with no special equipment
+word_count: 1200
+outline:
+  - Why cold brew tastes different
+  - What you need
+  - Step-by-step method
+  - Storing and serving
+links:
+  - url: https://example.com/shop/grinders
+    anchor: burr grinder
+  - https://example.com/blog/iced-latte
+notes: Friendly tone. Mention our subscription once.
+`
+
+// Link is a URL the content must link to, with optional anchor text.
+type Link struct {
+	URL    string `yaml:"url"`
+	Anchor string `yaml:"anchor,omitempty"`
+}
+
+// UnmarshalYAML accepts a link written as a bare URL or as {url, anchor}.
+func (l *Link) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		l.URL = node.Value
+		return nil
+	}
+	type plain Link
+	return node.Decode((*plain)(l))
+}
+
+// Brief is a content brief.
+type Brief struct {
+	Title             string   `yaml:"title,omitempty"`
+	TargetKeyword     string   `yaml:"target_keyword,omitempty"`
+	SecondaryKeywords []string `yaml:"secondary_keywords,omitempty,flow"`
+	Audience          string   `yaml:"audience,omitempty"`
+	Outline           []string `yaml:"outline,omitempty"`
+	WordCount         int      `yaml:"word_count,omitempty"`
+	Links             []Link   `yaml:"links,omitempty"`
+	Notes             string   `yaml:"notes,omitempty"`
+}
+
+// Parse reads a brief written in YAML or JSON (which is also valid YAML).
+// Unknown fields are rejected so a misspelled one isn't silently ignored.
+func Parse(data []byte) (Brief, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var b Brief
+	if err := decoder.Decode(&b); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Brief{}, fmt.Errorf("the brief is empty")
+		}
+		return Brief{}, fmt.Errorf("invalid brief: %w", err)
+	}
+	if b.Title == "" && b.TargetKeyword == "" && len(b.Outline) == 0 {
+		return Brief{}, fmt.Errorf("the brief needs at least a title, target_keyword or outline")
+	}
+	if b.WordCount < 0 {
+		return Brief{}, fmt.Errorf("word_count must not be negative")
+	}
+	for i, link := range b.Links {
+		if !strings.HasPrefix(link.URL, "http://") && !strings.HasPrefix(link.URL, "https://") {
+			return Brief{}, fmt.Errorf("link %d: %q is not an http(s) URL", i+1, link.URL)
+		}
+	}
+	return b, nil
+}
+
+// YAML writes the brief in the format Parse reads, leaving out empty fields.
+func (b Brief) YAML() (string, error) {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to write brief: %w", err)
+	}
+	return string(data), nil
+}
+
+// Prompt is the request for the Generator's prompt field: what to write,
+// following the outline.
+func (b Brief) Prompt() string {
+	var s strings.Builder
+	switch {
+	case b.Title != "":
+		fmt.Fprintf(&s, "Write an article titled %q.", b.Title)
+	default:
+		fmt.Fprintf(&s, "Write an article about %s.", b.TargetKeyword)
+	}
+	if len(b.Outline) > 0 {
+		s.WriteString("\n\nFollow this outline, one section per point:\n")
+		for i, point := range b.Outline {
+			fmt.Fprintf(&s, "%d. %s\n", i+1, point)
+		}
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+// Instruction is the text for the Generator's instructions field: audience,
+// links to include and notes. SEO targets are sent separately.
+func (b Brief) Instruction() string {
+	var lines []string
+	if b.Audience != "" {
+		lines = append(lines, fmt.Sprintf("Audience: %s", b.Audience))
+	}
+	if len(b.Links) > 0 {
+		lines = append(lines, "Include these links:")
+		for _, link := range b.Links {
+			if link.Anchor != "" {
+				lines = append(lines, fmt.Sprintf("- %s (anchor text: %q)", link.URL, link.Anchor))
+			} else {
+				lines = append(lines, "- "+link.URL)
+			}
+		}
+	}
+	if b.Notes != "" {
+		lines = append(lines, strings.TrimSpace(b.Notes))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Targets returns the brief's SEO targets.
+func (b Brief) Targets() seo.Targets {
+	return seo.Targets{Keyword: b.TargetKeyword, SecondaryKeywords: b.SecondaryKeywords, WordCount: b.WordCount}
+}
@@ -0,0 +1,62 @@
+package brief
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseExample(t *testing.T) {
+	b, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(b.Links) != 2 || b.Links[0].Anchor != "burr grinder" || b.Links[1].URL != "https://example.com/blog/iced-latte" {
+		t.Errorf("Expected both link forms to parse, got %+v", b.Links)
+	}
+	if !strings.HasPrefix(b.Prompt(), `Write an article titled "How to Make Cold Brew at Home".`) || !strings.Contains(b.Prompt(), "\n4. Storing and serving") {
+		t.Errorf("Unexpected prompt:\n%s", b.Prompt())
+	}
+	if !strings.Contains(b.Instruction(), `- https://example.com/shop/grinders (anchor text: "burr grinder")`) {
+		t.Errorf("Unexpected instruction:\n%s", b.Instruction())
+	}
+	if targets := b.Targets(); targets.Keyword != "cold brew" || targets.WordCount != 1200 || len(targets.SecondaryKeywords) != 2 {
+		t.Errorf("Unexpected targets: %+v", targets)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	b, err := Parse([]byte(`{"target_keyword": "cold brew", "links": [{"url": "https://example.com"}]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if b.Prompt() != "Write an article about cold brew." {
+		t.Errorf("Unexpected prompt: %q", b.Prompt())
+	}
+
+	for _, bad := range []string{``, `keyword: cold brew`, `title: x` + "\n" + `links: [notes.txt]`, `audience: everyone`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	b, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	text, err := b.YAML()
+	if err != nil {
+		t.Fatalf("YAML failed: %v", err)
+	}
+	again, err := Parse([]byte(text))
+	if err != nil {
+		t.Fatalf("Parse of written brief failed: %v\n%s", err, text)
+	}
+	if again.Prompt() != b.Prompt() || again.Instruction() != b.Instruction() || again.WordCount != b.WordCount {
+		t.Errorf("Round trip changed the brief:\n%s", text)
+	}
+	if text, _ := (Brief{TargetKeyword: "tea"}).YAML(); text != "target_keyword: tea\n" {
+		t.Errorf("Expected empty fields to be left out, got:\n%s", text)
+	}
+}
@@ -0,0 +1,244 @@
+// Package competitor fetches a competitor's page, extracts its main content
+// and reads the model's gap analysis against one of the site's pages.
+package competitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Inference_Engine/utils"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageBytes caps how much of a competitor's page is read.
+const maxPageBytes = 5 << 20
+
+// minMainWords is the least words an <article> or <main> element needs to
+// be taken as the page's main content rather than the whole body.
+const minMainWords = 150
+
+// Page is a competitor's page reduced to its main content.
+type Page struct {
+	URL      string
+	Title    string
+	Content  string   // Markdown
+	Headings []string // h1 to h3, in order, "##" prefixed by level
+}
+
+// client fetches competitor pages.
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Fetch downloads the page at rawURL and extracts its main content.
+func Fetch(ctx context.Context, rawURL string) (Page, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Page{}, fmt.Errorf("enter the competitor's page as a full http(s) URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; WordPressInferenceEngine/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Page{}, fmt.Errorf("failed to fetch %s: HTTP %d", u, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return Page{}, fmt.Errorf("%s is not an HTML page (%s)", u, contentType)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to read %s: %w", u, err)
+	}
+	return Extract(u.String(), string(data))
+}
+
+// Extract reduces a full HTML document to its main content: the largest
+// <article> or <main> element if it has enough text, else the body, without
+// navigation, headers, footers, sidebars, forms and scripts.
+func Extract(pageURL, document string) (Page, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to parse %s: %w", pageURL, err)
+	}
+	page := Page{URL: pageURL}
+	if title := find(root, func(n *html.Node) bool { return n.DataAtom == atom.Title }); title != nil {
+		page.Title = strings.TrimSpace(text(title))
+	}
+
+	var main *html.Node
+	walk(root, func(n *html.Node) {
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+			if main == nil || len(strings.Fields(text(n))) > len(strings.Fields(text(main))) {
+				main = n
+			}
+		}
+	})
+	if main == nil || len(strings.Fields(text(main))) < minMainWords {
+		main = find(root, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+	}
+	if main == nil {
+		return Page{}, fmt.Errorf("%s has no content", pageURL)
+	}
+	removeBoilerplate(main)
+
+	var b bytes.Buffer
+	for c := main.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&b, c)
+	}
+	page.Content = utils.HTMLToMarkdown(b.String())
+	if strings.TrimSpace(page.Content) == "" {
+		return Page{}, fmt.Errorf("%s has no readable text; it may be built by JavaScript", pageURL)
+	}
+	walk(main, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.H1, atom.H2, atom.H3:
+			if heading := strings.Join(strings.Fields(text(n)), " "); heading != "" {
+				page.Headings = append(page.Headings, strings.Repeat("#", int(n.Data[1]-'0'))+" "+heading)
+			}
+		}
+	})
+	return page, nil
+}
+
+// boilerplate lists the elements that are never part of the main content.
+var boilerplate = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Iframe: true, atom.Svg: true, atom.Button: true,
+}
+
+// removeBoilerplate drops the boilerplate elements under n.
+func removeBoilerplate(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && boilerplate[c.DataAtom] {
+			n.RemoveChild(c)
+		} else {
+			removeBoilerplate(c)
+		}
+		c = next
+	}
+}
+
+// walk calls fn for n and every element under it, depth first.
+func walk(n *html.Node, fn func(*html.Node)) {
+	if n.Type == html.ElementNode {
+		fn(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// find returns the first element under n matching match, or nil.
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// text returns the text under n, skipping scripts and styles.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+			return
+		}
+		if n.DataAtom == atom.Script || n.DataAtom == atom.Style {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return b.String()
+}
+
+// Gap is a subtopic the competitor covers better than the site's page.
+type Gap struct {
+	Subtopic string `json:"subtopic"`
+	Detail   string `json:"detail"` // What the competitor says about it, or does better
+}
+
+// Analysis is the model's comparison of the site's page with a competitor's.
+type Analysis struct {
+	Summary   string   `json:"summary"`
+	Missing   []Gap    `json:"missing"`   // Subtopics only the competitor covers
+	Weaker    []Gap    `json:"weaker"`    // Subtopics both cover, the competitor in more depth
+	Strengths []string `json:"strengths"` // What the site's page does better, to keep
+}
+
+// ParseAnalysis reads the model's answer to a gap analysis prompt.
+func ParseAnalysis(output string) (Analysis, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return Analysis{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var analysis Analysis
+	if err := json.Unmarshal([]byte(output[start:end+1]), &analysis); err != nil {
+		return Analysis{}, fmt.Errorf("failed to parse gap analysis: %w", err)
+	}
+	return analysis, nil
+}
+
+// Gaps lists the missing and weaker subtopics, one per line, for the
+// improvement prompt. It is empty if there are none.
+func (a Analysis) Gaps() string {
+	var lines []string
+	for _, gap := range a.Missing {
+		lines = append(lines, fmt.Sprintf("- Missing: %s. %s", gap.Subtopic, gap.Detail))
+	}
+	for _, gap := range a.Weaker {
+		lines = append(lines, fmt.Sprintf("- Covered too briefly: %s. %s", gap.Subtopic, gap.Detail))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Markdown formats the analysis for reading.
+func (a Analysis) Markdown() string {
+	var b strings.Builder
+	b.WriteString(a.Summary)
+	section := func(heading string, gaps []Gap) {
+		if len(gaps) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n\n## %s\n", heading)
+		for _, gap := range gaps {
+			fmt.Fprintf(&b, "\n- **%s**: %s", gap.Subtopic, gap.Detail)
+		}
+	}
+	section("Missing subtopics", a.Missing)
+	section("Covered in less depth", a.Weaker)
+	if len(a.Strengths) > 0 {
+		b.WriteString("\n\n## Strengths to keep\n")
+		for _, strength := range a.Strengths {
+			fmt.Fprintf(&b, "\n- %s", strength)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
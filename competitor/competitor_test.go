@@ -0,0 +1,88 @@
+package competitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func document(body string) string {
+	return `<!DOCTYPE html><html><head><title>Cold Brew Guide | Rival</title><style>p{}</style></head><body>
+<header><nav><a href="/">Home</a><a href="/shop">Shop</a></nav></header>` + body + `
+<aside><h3>Popular posts</h3></aside><footer>© Rival</footer><script>track()</script></body></html>`
+}
+
+func TestExtract(t *testing.T) {
+	article := "<article><h1>Cold Brew Guide</h1><p>" + strings.Repeat("Steep coarse grounds overnight. ", 60) + "</p><h2>Ratio</h2><p>Use 1:8.</p></article>"
+	page, err := Extract("https://rival.com/cold-brew", document(article+"<div>Subscribe to our newsletter</div>"))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if page.Title != "Cold Brew Guide | Rival" {
+		t.Errorf("Unexpected title %q", page.Title)
+	}
+	if !strings.Contains(page.Content, "## Ratio") || strings.Contains(page.Content, "newsletter") || strings.Contains(page.Content, "Shop") {
+		t.Errorf("Expected only the article's content, got:\n%s", page.Content)
+	}
+	if strings.Join(page.Headings, "|") != "# Cold Brew Guide|## Ratio" {
+		t.Errorf("Unexpected headings %q", page.Headings)
+	}
+
+	// A short article is a teaser; the body is used instead, without its boilerplate
+	page, err = Extract("https://rival.com/cold-brew", document("<article><p>Related: espresso</p></article><div><h2>Steeping</h2><p>Twelve hours.</p></div>"))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !strings.Contains(page.Content, "Twelve hours") || strings.Contains(page.Content, "Popular posts") || strings.Contains(page.Content, "track()") {
+		t.Errorf("Expected the body without boilerplate, got:\n%s", page.Content)
+	}
+
+	if _, err := Extract("https://rival.com/app", document("<div id=\"root\"></div>")); err == nil {
+		t.Errorf("Expected an error for a page without text")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed.json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(document("<main><h2>Steeping</h2><p>Twelve hours.</p></main>")))
+	}))
+	defer server.Close()
+
+	page, err := Fetch(context.Background(), server.URL+"/cold-brew")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !strings.Contains(page.Content, "Twelve hours") {
+		t.Errorf("Unexpected content:\n%s", page.Content)
+	}
+	if _, err := Fetch(context.Background(), server.URL+"/feed.json"); err == nil {
+		t.Errorf("Expected an error for a non-HTML response")
+	}
+	if _, err := Fetch(context.Background(), "rival.com/cold-brew"); err == nil {
+		t.Errorf("Expected an error for a URL without a scheme")
+	}
+}
+
+func TestParseAnalysis(t *testing.T) {
+	analysis, err := ParseAnalysis("```json\n" + `{"summary": "The rival covers more.", "missing": [{"subtopic": "Ratio", "detail": "Gives 1:8."}], "weaker": [{"subtopic": "Storage", "detail": "Says two weeks."}], "strengths": ["Clear steps"]}` + "\n```")
+	if err != nil {
+		t.Fatalf("ParseAnalysis failed: %v", err)
+	}
+	if gaps := analysis.Gaps(); gaps != "- Missing: Ratio. Gives 1:8.\n- Covered too briefly: Storage. Says two weeks." {
+		t.Errorf("Unexpected gaps:\n%s", gaps)
+	}
+	if text := analysis.Markdown(); !strings.Contains(text, "## Missing subtopics\n\n- **Ratio**: Gives 1:8.") || !strings.Contains(text, "## Strengths to keep\n\n- Clear steps") {
+		t.Errorf("Unexpected Markdown:\n%s", text)
+	}
+	if _, err := ParseAnalysis("No differences."); err == nil {
+		t.Errorf("Expected an error without a JSON object")
+	}
+}
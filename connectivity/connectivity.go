@@ -0,0 +1,133 @@
+// Package connectivity watches whether the network is reachable, so the app
+// can switch to offline mode and back.
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("connectivity")
+
+// checkTimeout bounds one connectivity check.
+const checkTimeout = 5 * time.Second
+
+// offlineAfter is how many checks in a row must fail before the network
+// counts as lost, so one dropped connection doesn't flip the app offline.
+const offlineAfter = 2
+
+// probeHosts are dialed to check the network; reaching any one is enough.
+var probeHosts = []string{"1.1.1.1:443", "8.8.8.8:443", "www.wordpress.org:443"}
+
+// CheckFunc reports whether the network is reachable, returning nil if it is.
+type CheckFunc func(ctx context.Context) error
+
+// DialCheck checks the network by opening a TCP connection to well-known
+// hosts.
+func DialCheck(ctx context.Context) error {
+	var dialer net.Dialer
+	var errs []error
+	for _, host := range probeHosts {
+		conn, err := dialer.DialContext(ctx, "tcp", host)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Monitor checks the network periodically and tells its listeners when it
+// goes offline or comes back. It starts out online. It is safe for
+// concurrent use.
+type Monitor struct {
+	check CheckFunc
+
+	mu        sync.Mutex
+	online    bool
+	failures  int // Failed checks in a row
+	listeners []func(online bool)
+}
+
+// NewMonitor returns a monitor using check, or DialCheck if check is nil.
+func NewMonitor(check CheckFunc) *Monitor {
+	if check == nil {
+		check = DialCheck
+	}
+	return &Monitor{check: check, online: true}
+}
+
+// Online reports whether the network was reachable at the last check.
+func (m *Monitor) Online() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.online
+}
+
+// OnChange registers a listener called (from the checking goroutine) when
+// the network goes offline or comes back.
+func (m *Monitor) OnChange(listener func(online bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// CheckNow checks the network and returns whether it is online.
+func (m *Monitor) CheckNow(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	err := m.check(ctx)
+	cancel()
+
+	m.mu.Lock()
+	was := m.online
+	if err == nil {
+		m.failures = 0
+		m.online = true
+	} else {
+		m.failures++
+		if m.failures >= offlineAfter {
+			m.online = false
+		}
+	}
+	online := m.online
+	listeners := append([]func(bool){}, m.listeners...)
+	m.mu.Unlock()
+
+	if online != was {
+		if online {
+			logger.Info("Network is back")
+		} else {
+			logger.Warn("Network lost, switching to offline mode", "error", err)
+		}
+		for _, listener := range listeners {
+			listener(online)
+		}
+	}
+	return online
+}
+
+// Start checks the network every interval until the returned function is
+// called.
+func (m *Monitor) Start(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	crash.Go("connectivity.Monitor", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.CheckNow(ctx)
+			}
+		}
+	})
+	return cancel
+}
@@ -0,0 +1,39 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMonitor(t *testing.T) {
+	var down bool
+	m := NewMonitor(func(context.Context) error {
+		if down {
+			return errors.New("network unreachable")
+		}
+		return nil
+	})
+	var changes []bool
+	m.OnChange(func(online bool) { changes = append(changes, online) })
+
+	ctx := context.Background()
+	if !m.CheckNow(ctx) {
+		t.Fatal("Expected to be online")
+	}
+	down = true
+	if !m.CheckNow(ctx) {
+		t.Error("Expected one failed check not to switch offline")
+	}
+	if m.CheckNow(ctx) || m.Online() {
+		t.Error("Expected two failed checks in a row to switch offline")
+	}
+	m.CheckNow(ctx)
+	down = false
+	if !m.CheckNow(ctx) {
+		t.Error("Expected one good check to switch back online")
+	}
+	if len(changes) != 2 || changes[0] || !changes[1] {
+		t.Errorf("Expected to be told once of each change, got %v", changes)
+	}
+}
@@ -0,0 +1,125 @@
+// Package crash recovers panics in background goroutines and writes crash
+// reports with their stack traces to disk, so one failing view or service
+// doesn't take the whole app down with it.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("crash")
+
+// Report is a recovered panic and where its report was written.
+type Report struct {
+	Time  time.Time
+	Where string // The goroutine or job that panicked, e.g. "ContentManagerView.loadPages"
+	Panic string
+	Stack string
+	Path  string // Empty if the report couldn't be written
+}
+
+var (
+	mu        sync.Mutex
+	dir       string
+	listeners []func(Report)
+)
+
+// SetDir sets the directory crash reports are written to. Until it is set
+// they go to a directory in the system's temporary directory.
+func SetDir(d string) {
+	mu.Lock()
+	defer mu.Unlock()
+	dir = d
+}
+
+// Dir returns the directory crash reports are written to.
+func Dir() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if dir == "" {
+		return filepath.Join(os.TempDir(), "wordpress-inference-crashes")
+	}
+	return dir
+}
+
+// OnCrash registers a listener called, from the recovering goroutine, for
+// every panic recovered by Recover or Go.
+func OnCrash(listener func(Report)) {
+	mu.Lock()
+	defer mu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// Go runs fn in a new goroutine, recovering any panic as Recover does.
+func Go(where string, fn func()) {
+	go func() {
+		defer Recover(where)
+		fn()
+	}()
+}
+
+// Recover recovers a panic, writes its report and calls the OnCrash
+// listeners. Defer it directly at the top of a goroutine:
+//
+//	defer crash.Recover("ContentManagerView.loadPages")
+func Recover(where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	report := Write(where, r, debug.Stack())
+	mu.Lock()
+	callbacks := append([]func(Report){}, listeners...)
+	mu.Unlock()
+	for _, listener := range callbacks {
+		listener(report)
+	}
+}
+
+// Fatal writes the report of a panic and lets it continue, for the main
+// goroutine, which can't carry on after one. Defer it directly.
+func Fatal(where string) {
+	if r := recover(); r != nil {
+		Write(where, r, debug.Stack())
+		panic(r)
+	}
+}
+
+// Write writes a crash report for a recovered panic value and its stack,
+// and logs it. Callers that recover panics themselves, such as the job
+// queue, use it to keep a report.
+func Write(where string, value any, stack []byte) Report {
+	report := Report{Time: time.Now(), Where: where, Panic: fmt.Sprint(value), Stack: string(stack)}
+	path, err := write(report)
+	if err != nil {
+		logger.Error("Failed to write crash report", "where", where, "panic", report.Panic, "error", err)
+	} else {
+		report.Path = path
+		logger.Error("Recovered from a crash", "where", where, "panic", report.Panic, "report", path)
+	}
+	return report
+}
+
+// write saves a report as crash-<time>.txt.
+func write(report Report) (string, error) {
+	d := Dir()
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(d, "crash-"+report.Time.Format("20060102-150405.000000")+".txt")
+	text := fmt.Sprintf("Time: %s\nWhere: %s\nPanic: %s\nGo: %s %s/%s\n\n%s",
+		report.Time.Format(time.RFC3339), report.Where, report.Panic,
+		runtime.Version(), runtime.GOOS, runtime.GOARCH, report.Stack)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,35 @@
+package crash
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoRecoversAndWritesReport(t *testing.T) {
+	SetDir(t.TempDir())
+	reports := make(chan Report, 1)
+	OnCrash(func(r Report) { reports <- r })
+
+	Go("TestView.load", func() {
+		var pages map[int]string
+		pages[1] = "boom" // Panics: assignment to a nil map
+	})
+
+	select {
+	case report := <-reports:
+		if report.Where != "TestView.load" || !strings.Contains(report.Panic, "nil map") {
+			t.Errorf("Unexpected report %+v", report)
+		}
+		data, err := os.ReadFile(report.Path)
+		if err != nil {
+			t.Fatalf("Expected the report on disk: %v", err)
+		}
+		if !strings.Contains(string(data), "Where: TestView.load") || !strings.Contains(string(data), "crash_test.go") {
+			t.Errorf("Expected the report to name the goroutine and include the stack, got:\n%s", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the panic to be recovered and reported")
+	}
+}
@@ -0,0 +1,121 @@
+package editorial
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/utils"
+)
+
+// CitationStyle controls how generated content credits its True sources.
+type CitationStyle string
+
+const (
+	CitationsNone           CitationStyle = "None"
+	CitationsInline         CitationStyle = "Inline"          // [n] markers linked to the source
+	CitationsFootnotes      CitationStyle = "Footnotes"       // [n] markers plus a numbered Sources section
+	CitationsSourcesSection CitationStyle = "Sources Section" // Only a Sources section at the end
+)
+
+// CitationStyles lists the styles in the order the UI offers them.
+var CitationStyles = []CitationStyle{CitationsNone, CitationsInline, CitationsFootnotes, CitationsSourcesSection}
+
+// CitedSource is a True source as it is numbered in the prompt.
+type CitedSource struct {
+	Title string
+	Link  string // Page URL; sources without a web link are cited by title only
+}
+
+// usesMarkers reports whether the model is asked for [n] markers.
+func (s CitationStyle) usesMarkers() bool {
+	return s == CitationsInline || s == CitationsFootnotes
+}
+
+// Instruction tells the model how to cite, or is empty for no citations.
+func (s CitationStyle) Instruction() string {
+	switch {
+	case s.usesMarkers():
+		return "Cite the True Sources inline: after each claim taken from a source, add the source's number in square brackets, e.g. [1] or [2][3]. Use only the Source Numbers given above. Do not add a list of sources yourself."
+	case s == CitationsSourcesSection:
+		return "Do not add a list of sources yourself; one is added automatically."
+	default:
+		return ""
+	}
+}
+
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// ApplyCitations links the model's [n] markers to the sources and adds a
+// Sources section, as the style requires. HTML content gets HTML links and
+// anything else Markdown.
+func ApplyCitations(content string, style CitationStyle, sources []CitedSource) string {
+	if style == CitationsNone || style == "" || len(sources) == 0 {
+		return content
+	}
+	isHTML := utils.LooksLikeHTML(content)
+
+	if style.usesMarkers() {
+		var b strings.Builder
+		last := 0
+		for _, m := range citationMarker.FindAllStringSubmatchIndex(content, -1) {
+			n, _ := strconv.Atoi(content[m[2]:m[3]])
+			linked := strings.HasPrefix(content[m[1]:], "(") || (m[0] > 0 && content[m[0]-1] == '[')
+			if n < 1 || n > len(sources) || sources[n-1].Link == "" || linked {
+				continue // Unknown source, nothing to link to, or already a Markdown link
+			}
+			b.WriteString(content[last:m[0]])
+			link := sources[n-1].Link
+			if isHTML {
+				fmt.Fprintf(&b, `<sup><a href="%s">[%d]</a></sup>`, html.EscapeString(link), n)
+			} else {
+				fmt.Fprintf(&b, "[[%d]](%s)", n, link)
+			}
+			last = m[1]
+		}
+		b.WriteString(content[last:])
+		content = b.String()
+	}
+
+	if style == CitationsFootnotes || style == CitationsSourcesSection {
+		content = strings.TrimRight(content, "\n") + "\n\n" + sourcesSection(sources, isHTML)
+	}
+	return content
+}
+
+// sourcesSection renders the numbered list of sources.
+func sourcesSection(sources []CitedSource, isHTML bool) string {
+	var b strings.Builder
+	if isHTML {
+		b.WriteString("<h2>Sources</h2>\n<ol>\n")
+		for _, source := range sources {
+			if source.Link != "" {
+				fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(source.Link), html.EscapeString(source.Title))
+			} else {
+				fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(source.Title))
+			}
+		}
+		b.WriteString("</ol>\n")
+		return b.String()
+	}
+	b.WriteString("## Sources\n\n")
+	for i, source := range sources {
+		if source.Link != "" {
+			fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, source.Title, source.Link)
+		} else {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, source.Title)
+		}
+	}
+	return b.String()
+}
+
+// WebLink returns link if it is an http(s) URL, which a published page can
+// point to, and "" otherwise (e.g. a local file).
+func WebLink(link string) string {
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
+	}
+	return ""
+}
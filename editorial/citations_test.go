@@ -0,0 +1,55 @@
+package editorial
+
+import (
+	"strings"
+	"testing"
+)
+
+var citedSources = []CitedSource{
+	{Title: "About Us", Link: "https://example.com/about"},
+	{Title: "notes.txt"},
+}
+
+func TestApplyCitationsMarkdown(t *testing.T) {
+	content := "We opened in 1999 [1]. Staff love it [2]. See [[1]](https://example.com/about). Nothing at [3]."
+
+	inline := ApplyCitations(content, CitationsInline, citedSources)
+	if !strings.Contains(inline, "1999 [[1]](https://example.com/about).") {
+		t.Errorf("Expected the marker to be linked:\n%s", inline)
+	}
+	if !strings.Contains(inline, "love it [2].") || !strings.Contains(inline, "at [3].") || strings.Contains(inline, "## Sources") {
+		t.Errorf("Expected unlinkable markers kept and no section:\n%s", inline)
+	}
+	if strings.Count(inline, "(https://example.com/about)") != 2 {
+		t.Errorf("Expected the existing link to be left alone:\n%s", inline)
+	}
+
+	footnotes := ApplyCitations(content, CitationsFootnotes, citedSources)
+	if !strings.HasSuffix(footnotes, "## Sources\n\n1. [About Us](https://example.com/about)\n2. notes.txt\n") {
+		t.Errorf("Unexpected sources section:\n%s", footnotes)
+	}
+	if got := ApplyCitations(content, CitationsNone, citedSources); got != content {
+		t.Errorf("Expected no change without citations, got %q", got)
+	}
+}
+
+func TestApplyCitationsHTML(t *testing.T) {
+	content := "<p>Founded in 1999 [1].</p>"
+	got := ApplyCitations(content, CitationsSourcesSection, citedSources)
+	if !strings.Contains(got, "1999 [1].") {
+		t.Errorf("Expected markers untouched in Sources Section style:\n%s", got)
+	}
+	if !strings.Contains(got, `<li><a href="https://example.com/about">About Us</a></li>`) || !strings.Contains(got, "<li>notes.txt</li>") {
+		t.Errorf("Expected an HTML sources list:\n%s", got)
+	}
+	got = ApplyCitations(content, CitationsInline, citedSources)
+	if !strings.Contains(got, `<sup><a href="https://example.com/about">[1]</a></sup>`) {
+		t.Errorf("Expected an HTML citation link:\n%s", got)
+	}
+}
+
+func TestWebLink(t *testing.T) {
+	if WebLink("file:///home/me/notes.txt") != "" || WebLink("https://example.com") == "" {
+		t.Errorf("Expected only web links to be kept")
+	}
+}
@@ -0,0 +1,208 @@
+package editorial
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+
+	"Inference_Engine/storage"
+	"Inference_Engine/utils"
+)
+
+// DisclaimersExample is the rule set used until the user saves their own. It
+// also documents the format: a "[Category]" header, comma-separated keywords
+// that flag content as that category, and the "Disclaimer:" it requires.
+const DisclaimersExample = `# Content mentioning at least two of a category's keywords, or published
+# with that category ticked, gets its disclaimer before publishing
+[Medical]
+symptom, symptoms, diagnosis, treatment, medication, dosage, side effects, doctor
+Disclaimer: This article is for general information only and is not medical advice. Talk to a qualified health professional about your situation.
+
+[Financial]
+invest, investing, investment, stocks, retirement, loan, mortgage, tax, returns
+Disclaimer: This article is for general information only and is not financial advice. Consider speaking with a licensed financial adviser before making decisions.
+
+[Affiliate]
+affiliate, commission, coupon, discount code, buy now, sponsored
+Disclaimer: This post contains affiliate links. We may earn a commission if you buy through them, at no extra cost to you.
+`
+
+// minKeywordHits is how many different keywords of a category content must
+// mention to be detected as that category, so a passing mention isn't enough.
+const minKeywordHits = 2
+
+// DisclaimerRule is the disclaimer content of one category must carry.
+type DisclaimerRule struct {
+	Category string
+	Keywords []string
+	Text     string
+	patterns []*regexp.Regexp
+}
+
+// Disclaimers is a parsed disclaimer rule set.
+type Disclaimers struct {
+	Rules []DisclaimerRule
+}
+
+// ParseDisclaimers reads a disclaimer rule document (see DisclaimersExample).
+func ParseDisclaimers(text string) (Disclaimers, error) {
+	var d Disclaimers
+	var rule *DisclaimerRule
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			d.Rules = append(d.Rules, DisclaimerRule{Category: strings.TrimSpace(line[1 : len(line)-1])})
+			rule = &d.Rules[len(d.Rules)-1]
+			if rule.Category == "" {
+				return Disclaimers{}, fmt.Errorf("line %d: empty category name", n+1)
+			}
+		case rule == nil:
+			return Disclaimers{}, fmt.Errorf("line %d: %q is not under a [Category] header", n+1, line)
+		case strings.HasPrefix(strings.ToLower(line), "disclaimer:"):
+			rule.Text = strings.TrimSpace(line[len("disclaimer:"):])
+		default:
+			for _, keyword := range strings.Split(line, ",") {
+				if keyword = strings.TrimSpace(keyword); keyword != "" {
+					rule.Keywords = append(rule.Keywords, keyword)
+					rule.patterns = append(rule.patterns, termPattern(keyword))
+				}
+			}
+		}
+	}
+	for _, rule := range d.Rules {
+		if rule.Text == "" {
+			return Disclaimers{}, fmt.Errorf("[%s] has no \"Disclaimer:\" line", rule.Category)
+		}
+	}
+	return d, nil
+}
+
+// Categories returns the category names, in document order.
+func (d Disclaimers) Categories() []string {
+	categories := make([]string, len(d.Rules))
+	for i, rule := range d.Rules {
+		categories[i] = rule.Category
+	}
+	return categories
+}
+
+// Detect returns the categories whose keywords content mentions.
+func (d Disclaimers) Detect(content string) []string {
+	var categories []string
+	for _, rule := range d.Rules {
+		hits := 0
+		for _, pattern := range rule.patterns {
+			if pattern.MatchString(content) {
+				hits++
+			}
+		}
+		if hits > 0 && hits >= min(minKeywordHits, len(rule.patterns)) {
+			categories = append(categories, rule.Category)
+		}
+	}
+	return categories
+}
+
+// Apply appends the disclaimers of categories to content, as a paragraph for
+// HTML content and an italic line for Markdown or text. Disclaimers content
+// already carries are not added again.
+func (d Disclaimers) Apply(content string, categories []string) string {
+	isHTML := utils.LooksLikeHTML(content)
+	for _, rule := range d.Rules {
+		if !containsFold(categories, rule.Category) || strings.Contains(content, rule.Text) || strings.Contains(content, html.EscapeString(rule.Text)) {
+			continue
+		}
+		if isHTML {
+			content = strings.TrimRight(content, "\n") + fmt.Sprintf("\n\n<p class=\"disclaimer\"><em>%s</em></p>", html.EscapeString(rule.Text))
+		} else {
+			content = strings.TrimRight(content, "\n") + "\n\n*" + rule.Text + "*"
+		}
+	}
+	return content
+}
+
+// containsFold reports whether list holds s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// disclaimersDocument is the state database document holding the disclaimer
+// rules.
+const disclaimersDocument = "disclaimers"
+
+// DisclaimerStore holds the disclaimer rules, persisted in the state
+// database. Until rules are saved, DisclaimersExample applies. It is safe for
+// concurrent use.
+type DisclaimerStore struct {
+	db *storage.DB // nil keeps the rules in memory only
+
+	mu    sync.Mutex
+	text  string
+	rules Disclaimers
+}
+
+// NewDisclaimerStore loads the disclaimer rules saved in db, if any.
+func NewDisclaimerStore(db *storage.DB) *DisclaimerStore {
+	s := &DisclaimerStore{db: db, text: DisclaimersExample}
+	s.rules, _ = ParseDisclaimers(DisclaimersExample)
+	if db == nil {
+		return s
+	}
+	text, ok, err := db.Document(disclaimersDocument)
+	if err != nil {
+		logger.Error("Failed to load disclaimer rules", "error", err)
+		return s
+	}
+	if !ok {
+		return s
+	}
+	rules, err := ParseDisclaimers(text)
+	if err != nil {
+		logger.Warn("Saved disclaimer rules no longer parse, ignoring them", "error", err)
+	}
+	s.text, s.rules = text, rules
+	return s
+}
+
+// Text returns the disclaimer rule document as written.
+func (s *DisclaimerStore) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text
+}
+
+// Disclaimers returns the parsed disclaimer rules.
+func (s *DisclaimerStore) Disclaimers() Disclaimers {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules
+}
+
+// Save registers a new disclaimer rule document. Invalid documents are
+// rejected and the previous rules are kept; an empty one disables disclaimers.
+func (s *DisclaimerStore) Save(text string) error {
+	rules, err := ParseDisclaimers(text)
+	if err != nil {
+		return err
+	}
+	if s.db != nil {
+		if err := s.db.SetDocument(disclaimersDocument, text); err != nil {
+			return fmt.Errorf("failed to save disclaimer rules: %w", err)
+		}
+	}
+	s.mu.Lock()
+	s.text, s.rules = text, rules
+	s.mu.Unlock()
+	logger.Info("Saved disclaimer rules", "categories", len(rules.Rules))
+	return nil
+}
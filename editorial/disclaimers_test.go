@@ -0,0 +1,46 @@
+package editorial
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisclaimersDetectAndApply(t *testing.T) {
+	d, err := ParseDisclaimers(DisclaimersExample)
+	if err != nil {
+		t.Fatalf("Example failed to parse: %v", err)
+	}
+	if got := d.Categories(); !reflect.DeepEqual(got, []string{"Medical", "Financial", "Affiliate"}) {
+		t.Fatalf("Unexpected categories %v", got)
+	}
+
+	content := "<p>Common symptoms and their treatment. Ask your doctor.</p>"
+	if got := d.Detect(content); !reflect.DeepEqual(got, []string{"Medical"}) {
+		t.Errorf("Expected Medical to be detected, got %v", got)
+	}
+	if got := d.Detect("<p>One tax mention is not enough.</p>"); len(got) != 0 {
+		t.Errorf("Expected a single keyword not to flag a category, got %v", got)
+	}
+
+	applied := d.Apply(content, []string{"medical", "Affiliate"})
+	if !strings.Contains(applied, `<p class="disclaimer"><em>This article is for general information only and is not medical advice.`) ||
+		!strings.Contains(applied, "affiliate links") {
+		t.Errorf("Expected both disclaimers as paragraphs, got:\n%s", applied)
+	}
+	if again := d.Apply(applied, []string{"Medical", "Affiliate"}); again != applied {
+		t.Errorf("Expected disclaimers not to be added twice, got:\n%s", again)
+	}
+	if md := d.Apply("# Budgeting\n", []string{"Financial"}); !strings.HasSuffix(md, "\n\n*This article is for general information only and is not financial advice. Consider speaking with a licensed financial adviser before making decisions.*") {
+		t.Errorf("Expected an italic Markdown disclaimer, got:\n%s", md)
+	}
+}
+
+func TestParseDisclaimersRejectsRuleWithoutText(t *testing.T) {
+	if _, err := ParseDisclaimers("[Legal]\nlawsuit, attorney"); err == nil {
+		t.Error("Expected a category without a disclaimer to be rejected")
+	}
+	if _, err := ParseDisclaimers("lawsuit"); err == nil {
+		t.Error("Expected keywords outside a category to be rejected")
+	}
+}
@@ -0,0 +1,132 @@
+package editorial
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"Inference_Engine/storage"
+)
+
+// GlossaryExample documents the glossary format: one term per line, written
+// exactly as it must appear, optionally followed by "=" and variants that
+// should be replaced by it.
+const GlossaryExample = `# Product names, trademarks and preferred spellings
+WordPress
+WooCommerce
+Acme Cloud™ = Acme cloud, AcmeCloud
+`
+
+// GlossaryTerm is one term and the variants that should be written as it.
+type GlossaryTerm struct {
+	Term     string
+	Variants []string
+}
+
+// Glossary lists the terms a site's content must spell consistently.
+type Glossary struct {
+	Terms []GlossaryTerm
+}
+
+// ParseGlossary reads a glossary document (see GlossaryExample).
+func ParseGlossary(text string) (Glossary, error) {
+	var glossary Glossary
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		term, variants, _ := strings.Cut(line, "=")
+		entry := GlossaryTerm{Term: strings.TrimSpace(term)}
+		if entry.Term == "" {
+			return Glossary{}, fmt.Errorf("line %d: glossary entry has no term", i+1)
+		}
+		for _, variant := range strings.Split(variants, ",") {
+			if variant = strings.TrimSpace(variant); variant != "" {
+				entry.Variants = append(entry.Variants, variant)
+			}
+		}
+		glossary.Terms = append(glossary.Terms, entry)
+	}
+	return glossary, nil
+}
+
+// StyleGuide returns the glossary as style guide rules, so it is injected
+// into prompts and checked along with the style guide. Any casing of a term
+// other than the defined one, and every variant, is a violation.
+func (g Glossary) StyleGuide() StyleGuide {
+	var guide StyleGuide
+	for _, entry := range g.Terms {
+		for _, term := range append([]string{entry.Term}, entry.Variants...) {
+			guide.Rules = append(guide.Rules, Rule{Kind: KindGlossary, Term: term, Replacement: entry.Term, pattern: termPattern(term)})
+		}
+	}
+	return guide
+}
+
+// glossaryDocument returns the state database document holding a site's
+// glossary; the empty site name is the default glossary.
+func glossaryDocument(site string) string {
+	return "glossary:" + site
+}
+
+// GlossaryStore holds one glossary per saved site, persisted in the state
+// database. It is safe for concurrent use.
+type GlossaryStore struct {
+	db *storage.DB // nil keeps glossaries in memory only
+
+	mu     sync.Mutex
+	memory map[string]string // Used when db is nil
+}
+
+// NewGlossaryStore returns the glossaries saved in db.
+func NewGlossaryStore(db *storage.DB) *GlossaryStore {
+	return &GlossaryStore{db: db, memory: map[string]string{}}
+}
+
+// Text returns the glossary document for a site ("" for the default one).
+func (s *GlossaryStore) Text(site string) string {
+	if s.db == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.memory[site]
+	}
+	text, _, err := s.db.Document(glossaryDocument(site))
+	if err != nil {
+		logger.Error("Failed to load glossary", "site", site, "error", err)
+	}
+	return text
+}
+
+// Glossary returns a site's parsed glossary, falling back to the default
+// glossary when the site has none.
+func (s *GlossaryStore) Glossary(site string) Glossary {
+	text := s.Text(site)
+	if strings.TrimSpace(text) == "" && site != "" {
+		text = s.Text("")
+	}
+	glossary, err := ParseGlossary(text)
+	if err != nil {
+		logger.Warn("Saved glossary no longer parses, ignoring it", "site", site, "error", err)
+	}
+	return glossary
+}
+
+// Save replaces a site's glossary document. Invalid documents are rejected.
+func (s *GlossaryStore) Save(site, text string) error {
+	glossary, err := ParseGlossary(text)
+	if err != nil {
+		return err
+	}
+	if s.db != nil {
+		if err := s.db.SetDocument(glossaryDocument(site), text); err != nil {
+			return fmt.Errorf("failed to save glossary: %w", err)
+		}
+	} else {
+		s.mu.Lock()
+		s.memory[site] = text
+		s.mu.Unlock()
+	}
+	logger.Info("Saved glossary", "site", site, "terms", len(glossary.Terms))
+	return nil
+}
@@ -0,0 +1,49 @@
+package editorial
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlossaryFlagsInconsistentTerms(t *testing.T) {
+	glossary, err := ParseGlossary(GlossaryExample)
+	if err != nil {
+		t.Fatalf("Example failed to parse: %v", err)
+	}
+	if len(glossary.Terms) != 3 || len(glossary.Terms[2].Variants) != 2 {
+		t.Fatalf("Unexpected glossary %+v", glossary)
+	}
+
+	guide := glossary.StyleGuide()
+	text := "Built on WordPress and Wordpress, hosted on AcmeCloud with woocommerce."
+	violations := guide.Check(text)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %+v", violations)
+	}
+	if violations[0].Found != "Wordpress" || violations[0].Suggestion() != "WordPress" {
+		t.Errorf("Unexpected first violation %+v", violations[0])
+	}
+
+	fixed, n := guide.AutoFix(text)
+	if n != 3 || fixed != "Built on WordPress and WordPress, hosted on Acme Cloud™ with WooCommerce." {
+		t.Errorf("Unexpected fix (%d): %q", n, fixed)
+	}
+	if again := guide.Check(fixed); len(again) != 0 {
+		t.Errorf("Expected the fixed text to pass, got %+v", again)
+	}
+	if !strings.Contains(guide.Instruction(), `"Acme Cloud™"`) {
+		t.Errorf("Expected the glossary terms in the instruction:\n%s", guide.Instruction())
+	}
+}
+
+func TestGlossaryStoreFallsBackToDefault(t *testing.T) {
+	s := NewGlossaryStore(nil)
+	s.Save("", "WordPress")
+	s.Save("Shop", "WooCommerce")
+	if got := s.Glossary("Shop").Terms; len(got) != 1 || got[0].Term != "WooCommerce" {
+		t.Errorf("Expected the site's own glossary, got %+v", got)
+	}
+	if got := s.Glossary("Blog").Terms; len(got) != 1 || got[0].Term != "WordPress" {
+		t.Errorf("Expected the default glossary, got %+v", got)
+	}
+}
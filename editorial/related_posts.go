@@ -0,0 +1,102 @@
+package editorial
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"Inference_Engine/storage"
+)
+
+// RelatedPostsSettings configures the "Further reading" block a site's
+// pages get when content is saved.
+type RelatedPostsSettings struct {
+	Enabled       bool    `json:"enabled"`
+	Count         int     `json:"count"`          // Links in the block
+	Heading       string  `json:"heading"`        // The block's heading
+	MinSimilarity float64 `json:"min_similarity"` // Pages less similar are not linked
+}
+
+// DefaultRelatedPostsSettings are used by sites without settings of their
+// own: the block is off until a site turns it on.
+var DefaultRelatedPostsSettings = RelatedPostsSettings{Count: 3, Heading: "Further reading", MinSimilarity: 0.5}
+
+// withDefaults fills in unset fields from DefaultRelatedPostsSettings.
+func (r RelatedPostsSettings) withDefaults() RelatedPostsSettings {
+	if r.Count <= 0 {
+		r.Count = DefaultRelatedPostsSettings.Count
+	}
+	if r.Heading == "" {
+		r.Heading = DefaultRelatedPostsSettings.Heading
+	}
+	if r.MinSimilarity <= 0 || r.MinSimilarity >= 1 {
+		r.MinSimilarity = DefaultRelatedPostsSettings.MinSimilarity
+	}
+	return r
+}
+
+// relatedPostsDocument returns the state database document holding a
+// site's related posts settings.
+func relatedPostsDocument(site string) string {
+	return "related_posts:" + site
+}
+
+// RelatedPostsStore holds the related posts settings of each saved site,
+// persisted in the state database. It is safe for concurrent use.
+type RelatedPostsStore struct {
+	db *storage.DB // nil keeps settings in memory only
+
+	mu     sync.Mutex
+	memory map[string]RelatedPostsSettings // Used when db is nil
+}
+
+// NewRelatedPostsStore returns the related posts settings saved in db.
+func NewRelatedPostsStore(db *storage.DB) *RelatedPostsStore {
+	return &RelatedPostsStore{db: db, memory: map[string]RelatedPostsSettings{}}
+}
+
+// Settings returns a site's settings, or the defaults if it has none.
+func (s *RelatedPostsStore) Settings(site string) RelatedPostsSettings {
+	if site == "" {
+		return DefaultRelatedPostsSettings
+	}
+	if s.db == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.memory[site].withDefaults()
+	}
+	text, ok, err := s.db.Document(relatedPostsDocument(site))
+	if err != nil {
+		logger.Error("Failed to load related posts settings", "site", site, "error", err)
+	}
+	var settings RelatedPostsSettings
+	if ok {
+		if err := json.Unmarshal([]byte(text), &settings); err != nil {
+			logger.Warn("Ignoring unreadable related posts settings", "site", site, "error", err)
+		}
+	}
+	return settings.withDefaults()
+}
+
+// Save replaces a site's settings.
+func (s *RelatedPostsStore) Save(site string, settings RelatedPostsSettings) error {
+	if site == "" {
+		return fmt.Errorf("no site to save related posts settings for")
+	}
+	settings = settings.withDefaults()
+	if s.db != nil {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		if err := s.db.SetDocument(relatedPostsDocument(site), string(data)); err != nil {
+			return fmt.Errorf("failed to save related posts settings: %w", err)
+		}
+	} else {
+		s.mu.Lock()
+		s.memory[site] = settings
+		s.mu.Unlock()
+	}
+	logger.Info("Saved related posts settings", "site", site, "enabled", settings.Enabled, "count", settings.Count)
+	return nil
+}
@@ -0,0 +1,23 @@
+package editorial
+
+import "testing"
+
+func TestRelatedPostsSettingsArePerSite(t *testing.T) {
+	s := NewRelatedPostsStore(nil)
+	if got := s.Settings("Blog"); got != DefaultRelatedPostsSettings || got.Enabled {
+		t.Errorf("Expected the disabled defaults, got %+v", got)
+	}
+	if err := s.Save("Blog", RelatedPostsSettings{Enabled: true, Count: 5}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got := s.Settings("Blog")
+	if !got.Enabled || got.Count != 5 || got.Heading != "Further reading" || got.MinSimilarity != 0.5 {
+		t.Errorf("Unexpected settings %+v", got)
+	}
+	if s.Settings("Shop").Enabled {
+		t.Error("Expected another site to keep the defaults")
+	}
+	if err := s.Save("", got); err == nil {
+		t.Error("Expected saving without a site to fail")
+	}
+}
@@ -0,0 +1,125 @@
+package editorial
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"Inference_Engine/postprocess"
+	"Inference_Engine/storage"
+)
+
+// SiteInstructionsExample shows the kind of default instructions a site
+// carries.
+const SiteInstructionsExample = `Audience: small business owners new to online marketing.
+Tone: friendly and practical; avoid jargon.
+End every post with: "This article is for general information and is not legal advice."`
+
+// siteInstructionsDocument returns the state database document holding a
+// site's default instructions.
+func siteInstructionsDocument(site string) string {
+	return "site_instructions:" + site
+}
+
+// headingCaseDocument returns the state database document holding a site's
+// heading case style.
+func headingCaseDocument(site string) string {
+	return "heading_case:" + site
+}
+
+// SiteInstructionsStore holds the default generation instructions of each
+// saved site, such as its audience, tone and required disclaimers, and the
+// case style of its headings, persisted in the state database. It is safe
+// for concurrent use.
+type SiteInstructionsStore struct {
+	db *storage.DB // nil keeps instructions in memory only
+
+	mu     sync.Mutex
+	memory map[string]string // Used when db is nil, by document name
+}
+
+// NewSiteInstructionsStore returns the site instructions saved in db.
+func NewSiteInstructionsStore(db *storage.DB) *SiteInstructionsStore {
+	return &SiteInstructionsStore{db: db, memory: map[string]string{}}
+}
+
+// Text returns a site's default instructions, empty if it has none.
+func (s *SiteInstructionsStore) Text(site string) string {
+	if site == "" {
+		return ""
+	}
+	if s.db == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.memory[siteInstructionsDocument(site)]
+	}
+	text, _, err := s.db.Document(siteInstructionsDocument(site))
+	if err != nil {
+		logger.Error("Failed to load site instructions", "site", site, "error", err)
+	}
+	return text
+}
+
+// Instruction returns a site's default instructions as an instruction for
+// the model, empty if it has none.
+func (s *SiteInstructionsStore) Instruction(site string) string {
+	text := strings.TrimSpace(s.Text(site))
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("The content is for the site %q. Follow its standing instructions:\n%s", site, text)
+}
+
+// Save replaces a site's default instructions; empty text removes them.
+func (s *SiteInstructionsStore) Save(site, text string) error {
+	if site == "" {
+		return fmt.Errorf("no site to save instructions for")
+	}
+	if s.db != nil {
+		if err := s.db.SetDocument(siteInstructionsDocument(site), text); err != nil {
+			return fmt.Errorf("failed to save site instructions: %w", err)
+		}
+	} else {
+		s.mu.Lock()
+		s.memory[siteInstructionsDocument(site)] = text
+		s.mu.Unlock()
+	}
+	logger.Info("Saved site instructions", "site", site, "chars", len(text))
+	return nil
+}
+
+// HeadingCase returns the case style of a site's headings, as written if it
+// has none.
+func (s *SiteInstructionsStore) HeadingCase(site string) postprocess.CaseStyle {
+	if site == "" {
+		return postprocess.CaseAsWritten
+	}
+	if s.db == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return postprocess.CaseStyle(s.memory[headingCaseDocument(site)])
+	}
+	style, _, err := s.db.Document(headingCaseDocument(site))
+	if err != nil {
+		logger.Error("Failed to load heading case style", "site", site, "error", err)
+	}
+	return postprocess.CaseStyle(style)
+}
+
+// SaveHeadingCase sets the case style of a site's headings.
+func (s *SiteInstructionsStore) SaveHeadingCase(site string, style postprocess.CaseStyle) error {
+	if site == "" {
+		return fmt.Errorf("no site to save the heading case for")
+	}
+	if s.db != nil {
+		if err := s.db.SetDocument(headingCaseDocument(site), string(style)); err != nil {
+			return fmt.Errorf("failed to save heading case style: %w", err)
+		}
+	} else {
+		s.mu.Lock()
+		s.memory[headingCaseDocument(site)] = string(style)
+		s.mu.Unlock()
+	}
+	logger.Info("Saved heading case style", "site", site, "style", string(style))
+	return nil
+}
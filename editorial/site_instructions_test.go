@@ -0,0 +1,39 @@
+package editorial
+
+import (
+	"strings"
+	"testing"
+
+	"Inference_Engine/postprocess"
+)
+
+func TestSiteInstructionsArePerSite(t *testing.T) {
+	s := NewSiteInstructionsStore(nil)
+	if err := s.Save("Clinic", "Tone: reassuring.\nAdd the medical disclaimer."); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	s.Save("Shop", "Tone: playful.")
+
+	got := s.Instruction("Clinic")
+	if !strings.Contains(got, `"Clinic"`) || !strings.Contains(got, "medical disclaimer") || strings.Contains(got, "playful") {
+		t.Errorf("Unexpected instruction for Clinic:\n%s", got)
+	}
+	if got := s.Instruction("Blog"); got != "" {
+		t.Errorf("Expected no instruction for a site without any, got %q", got)
+	}
+	if got := s.Instruction(""); got != "" {
+		t.Errorf("Expected no instruction without a site, got %q", got)
+	}
+	if err := s.Save("", "Tone: formal."); err == nil {
+		t.Error("Expected saving without a site to fail")
+	}
+	if err := s.SaveHeadingCase("Shop", postprocess.CaseSentence); err != nil {
+		t.Fatalf("SaveHeadingCase failed: %v", err)
+	}
+	if got := s.HeadingCase("Shop"); got != postprocess.CaseSentence {
+		t.Errorf("HeadingCase(Shop) = %q, want sentence case", got)
+	}
+	if got := s.HeadingCase("Clinic"); got != postprocess.CaseAsWritten || !strings.Contains(s.Text("Shop"), "playful") {
+		t.Errorf("Expected the heading case to be kept apart from the instructions, got %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+package editorial
+
+import (
+	"sync"
+
+	"Inference_Engine/storage"
+)
+
+// styleGuideDocument is the state database document holding the style guide.
+const styleGuideDocument = "style_guide"
+
+// StyleGuideStore holds the registered style guide, persisted in the state
+// database. It is safe for concurrent use.
+type StyleGuideStore struct {
+	db *storage.DB // nil keeps the guide in memory only
+
+	mu    sync.Mutex
+	text  string
+	guide StyleGuide
+}
+
+// NewStyleGuideStore loads the style guide saved in db, if any. A nil db
+// gives a store that forgets the guide on exit.
+func NewStyleGuideStore(db *storage.DB) *StyleGuideStore {
+	s := &StyleGuideStore{db: db}
+	if db == nil {
+		return s
+	}
+	text, ok, err := db.Document(styleGuideDocument)
+	if err != nil {
+		logger.Error("Failed to load style guide", "error", err)
+		return s
+	}
+	if !ok {
+		return s
+	}
+	guide, err := ParseStyleGuide(text)
+	if err != nil {
+		logger.Warn("Saved style guide no longer parses, ignoring it", "error", err)
+	}
+	s.text, s.guide = text, guide
+	return s
+}
+
+// Text returns the style guide document as written.
+func (s *StyleGuideStore) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text
+}
+
+// Guide returns the parsed style guide.
+func (s *StyleGuideStore) Guide() StyleGuide {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.guide
+}
+
+// Save registers a new style guide document. Invalid documents are rejected
+// and the previous guide is kept.
+func (s *StyleGuideStore) Save(text string) error {
+	guide, err := ParseStyleGuide(text)
+	if err != nil {
+		return err
+	}
+	if s.db != nil {
+		if err := s.db.SetDocument(styleGuideDocument, text); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.text, s.guide = text, guide
+	s.mu.Unlock()
+	logger.Info("Saved style guide", "rules", len(guide.Rules), "voice_rules", len(guide.VoiceRules))
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package editorial checks generated content against a client's editorial
+// rules: banned words, spelling conventions, voice guidelines and glossary
+// terms.
+package editorial
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("editorial")
+
+// StyleGuideExample is shown in the editor as a starting point. It also
+// documents the format: "[Section]" headers, one rule per line, "#" comments.
+const StyleGuideExample = `# Words the client never wants to see, with an optional replacement
+[Banned]
+leverage => use
+synergy
+
+# Spelling conventions (variant => preferred)
+[Spelling]
+color => colour
+e-mail => email
+
+# Voice rules, passed to the model with every generation
+[Voice]
+Address the reader as "you".
+Keep sentences under 25 words.
+`
+
+// Rule kinds, also used as Violation.Kind.
+const (
+	KindBanned   = "Banned"
+	KindSpelling = "Spelling"
+	KindGlossary = "Glossary" // Term must be written exactly as Replacement
+)
+
+// Rule replaces or forbids one term. Replacement is empty for banned words
+// that have no suggested alternative.
+type Rule struct {
+	Kind        string
+	Term        string
+	Replacement string
+	pattern     *regexp.Regexp
+}
+
+// StyleGuide is a parsed style guide document.
+type StyleGuide struct {
+	Rules      []Rule   // Banned words, spelling conventions and glossary terms, in document order
+	VoiceRules []string // Free-form guidance for the model
+}
+
+// Violation is one place where content breaks a rule.
+type Violation struct {
+	Rule   Rule
+	Found  string // The text as it appears in the content
+	Offset int    // Byte offset in the content
+	Line   int    // 1-based line number
+}
+
+// Fixable reports whether AutoFix can correct the violation.
+func (v Violation) Fixable() bool {
+	return v.Rule.Replacement != ""
+}
+
+// Suggestion returns the replacement for the found text, matching its case
+// (glossary terms are always written as defined).
+func (v Violation) Suggestion() string {
+	if v.Rule.Kind == KindGlossary {
+		return v.Rule.Replacement
+	}
+	return matchCase(v.Found, v.Rule.Replacement)
+}
+
+// ParseStyleGuide reads a style guide document (see StyleGuideExample).
+func ParseStyleGuide(text string) (StyleGuide, error) {
+	var guide StyleGuide
+	section := ""
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			if section != "banned" && section != "spelling" && section != "voice" {
+				return StyleGuide{}, fmt.Errorf("line %d: unknown section %s (use [Banned], [Spelling] or [Voice])", i+1, line)
+			}
+			continue
+		}
+		switch section {
+		case "":
+			return StyleGuide{}, fmt.Errorf("line %d: rule outside a section (start with [Banned], [Spelling] or [Voice])", i+1)
+		case "voice":
+			guide.VoiceRules = append(guide.VoiceRules, line)
+		default:
+			term, replacement, _ := strings.Cut(line, "=>")
+			rule := Rule{Kind: KindBanned, Term: strings.TrimSpace(term), Replacement: strings.TrimSpace(replacement)}
+			if section == "spelling" {
+				rule.Kind = KindSpelling
+				if rule.Replacement == "" {
+					return StyleGuide{}, fmt.Errorf("line %d: spelling rule %q needs a preferred form (variant => preferred)", i+1, rule.Term)
+				}
+			}
+			if rule.Term == "" {
+				return StyleGuide{}, fmt.Errorf("line %d: rule has no term", i+1)
+			}
+			rule.pattern = termPattern(rule.Term)
+			guide.Rules = append(guide.Rules, rule)
+		}
+	}
+	return guide, nil
+}
+
+// termPattern matches term as a whole word or phrase, ignoring case.
+func termPattern(term string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(term)
+	if r, _ := utf8.DecodeRuneInString(term); isWordRune(r) {
+		expr = `\b` + expr
+	}
+	if r, _ := utf8.DecodeLastRuneInString(term); isWordRune(r) {
+		expr += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + expr)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// With returns a guide with the rules of both g and other.
+func (g StyleGuide) With(other StyleGuide) StyleGuide {
+	return StyleGuide{
+		Rules:      append(append([]Rule(nil), g.Rules...), other.Rules...),
+		VoiceRules: append(append([]string(nil), g.VoiceRules...), other.VoiceRules...),
+	}
+}
+
+// IsEmpty reports whether the guide has no rules at all.
+func (g StyleGuide) IsEmpty() bool {
+	return len(g.Rules) == 0 && len(g.VoiceRules) == 0
+}
+
+// Instruction describes the guide for the model, to be added to the
+// generation instructions. It is empty for an empty guide.
+func (g StyleGuide) Instruction() string {
+	if g.IsEmpty() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Follow the client's style guide:\n")
+	var glossary []string
+	seen := map[string]bool{}
+	for _, rule := range g.Rules {
+		switch {
+		case rule.Kind == KindGlossary:
+			if !seen[rule.Replacement] {
+				seen[rule.Replacement] = true
+				glossary = append(glossary, fmt.Sprintf("%q", rule.Replacement))
+			}
+		case rule.Kind == KindSpelling:
+			fmt.Fprintf(&b, "- Write %q, not %q.\n", rule.Replacement, rule.Term)
+		case rule.Replacement != "":
+			fmt.Fprintf(&b, "- Never use %q; use %q instead.\n", rule.Term, rule.Replacement)
+		default:
+			fmt.Fprintf(&b, "- Never use %q.\n", rule.Term)
+		}
+	}
+	if len(glossary) > 0 {
+		fmt.Fprintf(&b, "- Write these glossary terms exactly as shown: %s.\n", strings.Join(glossary, ", "))
+	}
+	for _, rule := range g.VoiceRules {
+		fmt.Fprintf(&b, "- %s\n", rule)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Check lists every violation of the guide's banned words, spelling
+// conventions and glossary terms in text, in the order they appear. Voice rules can't be
+// checked mechanically and are left to the model.
+func (g StyleGuide) Check(text string) []Violation {
+	var violations []Violation
+	for _, rule := range g.Rules {
+		for _, loc := range rule.violations(text) {
+			violations = append(violations, Violation{
+				Rule:   rule,
+				Found:  text[loc[0]:loc[1]],
+				Offset: loc[0],
+				Line:   strings.Count(text[:loc[0]], "\n") + 1,
+			})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Offset < violations[j].Offset })
+	return violations
+}
+
+// violations returns the byte ranges in text that break the rule. Glossary
+// terms written exactly as defined are fine, including where a variant
+// matches part of one (e.g. "Acme cloud" inside "Acme Cloud™").
+func (rule Rule) violations(text string) [][]int {
+	locs := rule.pattern.FindAllStringIndex(text, -1)
+	if rule.Kind != KindGlossary {
+		return locs
+	}
+	var correct [][]int
+	for i := 0; ; {
+		j := strings.Index(text[i:], rule.Replacement)
+		if j < 0 || rule.Replacement == "" {
+			break
+		}
+		correct = append(correct, []int{i + j, i + j + len(rule.Replacement)})
+		i += j + len(rule.Replacement)
+	}
+	var wrong [][]int
+	for _, loc := range locs {
+		inside := false
+		for _, c := range correct {
+			if c[0] <= loc[0] && loc[1] <= c[1] {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			wrong = append(wrong, loc)
+		}
+	}
+	return wrong
+}
+
+// AutoFix applies every rule that has a replacement and returns the fixed
+// text with the number of replacements made. Banned words without a
+// replacement are left for the writer.
+func (g StyleGuide) AutoFix(text string) (string, int) {
+	fixed := 0
+	for _, rule := range g.Rules {
+		if rule.Replacement == "" {
+			continue
+		}
+		locs := rule.violations(text)
+		if len(locs) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range locs {
+			b.WriteString(text[last:loc[0]])
+			b.WriteString(Violation{Rule: rule, Found: text[loc[0]:loc[1]]}.Suggestion())
+			last = loc[1]
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+		fixed += len(locs)
+	}
+	if fixed > 0 {
+		logger.Info("Applied style guide fixes", "replacements", fixed)
+	}
+	return text, fixed
+}
+
+// matchCase gives replacement the capitalization of found: all caps, a
+// leading capital, or unchanged.
+func matchCase(found, replacement string) string {
+	if strings.ToUpper(found) == found && strings.ToLower(found) != found && utf8.RuneCountInString(found) > 1 {
+		return strings.ToUpper(replacement)
+	}
+	if r, _ := utf8.DecodeRuneInString(found); unicode.IsUpper(r) {
+		first, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(first)) + replacement[size:]
+	}
+	return replacement
+}
@@ -0,0 +1,75 @@
+package editorial
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStyleGuide(t *testing.T) {
+	guide, err := ParseStyleGuide(StyleGuideExample)
+	if err != nil {
+		t.Fatalf("Example failed to parse: %v", err)
+	}
+	if len(guide.Rules) != 4 || len(guide.VoiceRules) != 2 {
+		t.Fatalf("Expected 4 rules and 2 voice rules, got %+v", guide)
+	}
+	if guide.Rules[1].Kind != KindBanned || guide.Rules[1].Replacement != "" {
+		t.Errorf("Expected a banned word without replacement, got %+v", guide.Rules[1])
+	}
+
+	for _, bad := range []string{"leverage", "[Tone]\nfriendly", "[Spelling]\ncolor", "[Banned]\n=> use"} {
+		if _, err := ParseStyleGuide(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestCheckAndAutoFix(t *testing.T) {
+	guide, _ := ParseStyleGuide(StyleGuideExample)
+	text := "Leverage our synergy.\nPick a COLOR, send an e-mail. Colorful stays."
+
+	violations := guide.Check(text)
+	if len(violations) != 4 {
+		t.Fatalf("Expected 4 violations, got %+v", violations)
+	}
+	if violations[0].Found != "Leverage" || violations[0].Suggestion() != "Use" || violations[0].Line != 1 {
+		t.Errorf("Unexpected first violation %+v", violations[0])
+	}
+	if violations[1].Fixable() {
+		t.Errorf("Expected a banned word without replacement not to be fixable")
+	}
+	if violations[2].Line != 2 || violations[2].Suggestion() != "COLOUR" {
+		t.Errorf("Unexpected spelling violation %+v", violations[2])
+	}
+
+	fixed, n := guide.AutoFix(text)
+	if n != 3 || fixed != "Use our synergy.\nPick a COLOUR, send an email. Colorful stays." {
+		t.Errorf("Unexpected fix (%d): %q", n, fixed)
+	}
+}
+
+func TestInstruction(t *testing.T) {
+	if (StyleGuide{}).Instruction() != "" {
+		t.Errorf("Expected no instruction for an empty guide")
+	}
+	guide, _ := ParseStyleGuide(StyleGuideExample)
+	instruction := guide.Instruction()
+	for _, want := range []string{`Never use "synergy".`, `Write "colour", not "color".`, `Address the reader as "you".`} {
+		if !strings.Contains(instruction, want) {
+			t.Errorf("Expected the instruction to contain %q:\n%s", want, instruction)
+		}
+	}
+}
+
+func TestStyleGuideStoreKeepsValidGuide(t *testing.T) {
+	s := NewStyleGuideStore(nil)
+	if err := s.Save(StyleGuideExample); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.Save("no section"); err == nil {
+		t.Fatalf("Expected an invalid guide to be rejected")
+	}
+	if s.Text() != StyleGuideExample || len(s.Guide().Rules) != 4 {
+		t.Errorf("Expected the previous guide to be kept")
+	}
+}
@@ -0,0 +1,196 @@
+package editorial
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"Inference_Engine/storage"
+	"Inference_Engine/utils"
+)
+
+// voiceProfileDocument is the state database document holding the profile.
+const voiceProfileDocument = "voice_profile"
+
+// maxVocabulary caps how many characteristic words a profile keeps.
+const maxVocabulary = 20
+
+// VoiceProfile is a reusable fingerprint of a brand's writing style, built
+// once from Sample sources so later generations don't need to resend them.
+type VoiceProfile struct {
+	Tone              string    `json:"tone"`                // The model's description of tone and style
+	AvgSentenceWords  float64   `json:"avg_sentence_words"`  // Mean words per sentence
+	AvgParagraphWords float64   `json:"avg_paragraph_words"` // Mean words per paragraph
+	Vocabulary        []string  `json:"vocabulary"`          // Characteristic words, most frequent first
+	SampleTitles      []string  `json:"sample_titles"`       // The samples it was built from
+	Built             time.Time `json:"built"`
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?]+(\s+|$)`)
+
+// MeasureVoice computes a profile's statistics (everything but Tone) from
+// sample texts. HTML samples are converted to plain text first.
+func MeasureVoice(samples []string) VoiceProfile {
+	var sentences, paragraphs, words int
+	counts := map[string]int{}
+	for _, sample := range samples {
+		if utils.LooksLikeHTML(sample) {
+			sample = utils.HTMLToMarkdown(sample)
+		}
+		for _, paragraph := range strings.Split(sample, "\n\n") {
+			paragraphWords := wordsIn(paragraph)
+			if len(paragraphWords) == 0 {
+				continue
+			}
+			paragraphs++
+			words += len(paragraphWords)
+			for _, sentence := range sentenceEnd.Split(paragraph, -1) {
+				if len(wordsIn(sentence)) > 0 {
+					sentences++
+				}
+			}
+			for _, word := range paragraphWords {
+				word = strings.ToLower(word)
+				if len([]rune(word)) >= 4 && !stopWords[word] {
+					counts[word]++
+				}
+			}
+		}
+	}
+
+	profile := VoiceProfile{Vocabulary: topWords(counts, maxVocabulary), Built: time.Now()}
+	if sentences > 0 {
+		profile.AvgSentenceWords = float64(words) / float64(sentences)
+	}
+	if paragraphs > 0 {
+		profile.AvgParagraphWords = float64(words) / float64(paragraphs)
+	}
+	return profile
+}
+
+// wordsIn splits text into words, dropping punctuation and Markdown markup.
+func wordsIn(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+	})
+}
+
+// topWords returns up to n words used at least twice, most frequent first.
+func topWords(counts map[string]int, n int) []string {
+	var words []string
+	for word, count := range counts {
+		if count >= 2 {
+			words = append(words, word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// Instruction describes the profile for the model, to be added to the
+// generation instructions in place of the samples.
+func (p VoiceProfile) Instruction() string {
+	var b strings.Builder
+	b.WriteString("Write in the brand's voice:\n")
+	if p.Tone != "" {
+		fmt.Fprintf(&b, "%s\n", strings.TrimSpace(p.Tone))
+	}
+	if p.AvgSentenceWords > 0 {
+		fmt.Fprintf(&b, "- Aim for about %.0f words per sentence and %.0f words per paragraph.\n", p.AvgSentenceWords, p.AvgParagraphWords)
+	}
+	if len(p.Vocabulary) > 0 {
+		fmt.Fprintf(&b, "- Favor the brand's vocabulary where it fits: %s.\n", strings.Join(p.Vocabulary, ", "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Summary is a one-line description for the UI.
+func (p VoiceProfile) Summary() string {
+	return fmt.Sprintf("%d samples, ~%.0f words/sentence, built %s", len(p.SampleTitles), p.AvgSentenceWords, p.Built.Format("2006-01-02 15:04"))
+}
+
+// VoiceProfileStore holds the brand voice profile, persisted in the state
+// database. It is safe for concurrent use.
+type VoiceProfileStore struct {
+	db *storage.DB // nil keeps the profile in memory only
+
+	mu      sync.Mutex
+	profile *VoiceProfile
+}
+
+// NewVoiceProfileStore loads the profile saved in db, if any.
+func NewVoiceProfileStore(db *storage.DB) *VoiceProfileStore {
+	s := &VoiceProfileStore{db: db}
+	if db == nil {
+		return s
+	}
+	text, ok, err := db.Document(voiceProfileDocument)
+	if err != nil || !ok {
+		if err != nil {
+			logger.Error("Failed to load voice profile", "error", err)
+		}
+		return s
+	}
+	var profile VoiceProfile
+	if err := json.Unmarshal([]byte(text), &profile); err != nil {
+		logger.Warn("Ignoring unreadable voice profile", "error", err)
+		return s
+	}
+	s.profile = &profile
+	return s
+}
+
+// Profile returns the saved profile, if there is one.
+func (s *VoiceProfileStore) Profile() (VoiceProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profile == nil {
+		return VoiceProfile{}, false
+	}
+	return *s.profile, true
+}
+
+// Save replaces the saved profile.
+func (s *VoiceProfileStore) Save(p VoiceProfile) error {
+	if s.db != nil {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		if err := s.db.SetDocument(voiceProfileDocument, string(data)); err != nil {
+			return fmt.Errorf("failed to save voice profile: %w", err)
+		}
+	}
+	s.mu.Lock()
+	s.profile = &p
+	s.mu.Unlock()
+	logger.Info("Saved voice profile", "samples", len(p.SampleTitles), "vocabulary", len(p.Vocabulary))
+	return nil
+}
+
+// stopWords are common English words left out of a profile's vocabulary.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`about above after again against also among because been before being
+		below between both could does doing down during each even every from further have having here
+		into itself just like made make many more most much must need only other over same should some
+		such than that their theirs them then there these they this those through under until very want
+		were what when where which while will with within without would your yours you're it's that's
+		don't can't isn't aren't well into onto upon also ever`) {
+		stopWords[word] = true
+	}
+}
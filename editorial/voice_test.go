@@ -0,0 +1,42 @@
+package editorial
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasureVoice(t *testing.T) {
+	samples := []string{
+		"Fresh bread every morning. Our bakers knead the dough by hand.\n\nFresh croissants too!",
+		"<p>Visit our bakery for fresh bread. The bakers open early.</p>",
+	}
+	profile := MeasureVoice(samples)
+	if profile.AvgSentenceWords < 3 || profile.AvgSentenceWords > 8 {
+		t.Errorf("Unexpected average sentence length %.1f", profile.AvgSentenceWords)
+	}
+	if len(profile.Vocabulary) == 0 || profile.Vocabulary[0] != "fresh" {
+		t.Errorf("Expected 'fresh' to lead the vocabulary, got %v", profile.Vocabulary)
+	}
+	for _, word := range profile.Vocabulary {
+		if stopWords[word] {
+			t.Errorf("Stop word %q in vocabulary", word)
+		}
+	}
+
+	profile.Tone = "- Warm and homely."
+	instruction := profile.Instruction()
+	if !strings.Contains(instruction, "Warm and homely") || !strings.Contains(instruction, "fresh") {
+		t.Errorf("Unexpected instruction:\n%s", instruction)
+	}
+}
+
+func TestVoiceProfileStore(t *testing.T) {
+	s := NewVoiceProfileStore(nil)
+	if _, ok := s.Profile(); ok {
+		t.Fatalf("Expected no profile yet")
+	}
+	s.Save(VoiceProfile{Tone: "Playful"})
+	if p, ok := s.Profile(); !ok || p.Tone != "Playful" {
+		t.Errorf("Expected the saved profile, got %+v", p)
+	}
+}
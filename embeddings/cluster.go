@@ -0,0 +1,78 @@
+package embeddings
+
+import "sort"
+
+// Cluster groups vectors by average-linkage agglomerative clustering: the two
+// most similar groups are merged until no two groups have an average
+// similarity of at least threshold. It returns the groups as vector indexes,
+// largest group first, each in input order. It takes cubic time, so it is
+// meant for hundreds of vectors, not thousands.
+func Cluster(vectors []Vector, threshold float64) [][]int {
+	n := len(vectors)
+	similarity := make([][]float64, n)
+	for i := range vectors {
+		similarity[i] = make([]float64, n)
+		for j := 0; j < i; j++ {
+			similarity[i][j] = Cosine(vectors[i], vectors[j])
+			similarity[j][i] = similarity[i][j]
+		}
+	}
+
+	groups := make([][]int, n)
+	for i := range groups {
+		groups[i] = []int{i}
+	}
+	linkage := func(a, b []int) float64 {
+		var sum float64
+		for _, i := range a {
+			for _, j := range b {
+				sum += similarity[i][j]
+			}
+		}
+		return sum / float64(len(a)*len(b))
+	}
+	for {
+		bestA, bestB, best := -1, -1, threshold
+		for a := range groups {
+			for b := a + 1; b < len(groups); b++ {
+				if s := linkage(groups[a], groups[b]); s >= best {
+					bestA, bestB, best = a, b, s
+				}
+			}
+		}
+		if bestA < 0 {
+			break
+		}
+		groups[bestA] = append(groups[bestA], groups[bestB]...)
+		groups = append(groups[:bestB], groups[bestB+1:]...)
+	}
+
+	for _, group := range groups {
+		sort.Ints(group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
+
+// Central returns the member of a group most similar on average to the
+// others, e.g. the keyword that best names a cluster.
+func Central(vectors []Vector, group []int) int {
+	best, bestScore := group[0], -2.0
+	for _, i := range group {
+		var score float64
+		for _, j := range group {
+			if i != j {
+				score += Cosine(vectors[i], vectors[j])
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,99 @@
+// Package embeddings turns page text into vectors and keeps a per-site index
+// of them, for finding pages that cover the same topic.
+package embeddings
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("embeddings")
+
+// maxEmbedChars caps the text embedded per document. Embedding models read a
+// limited number of tokens, and a page's topic is set by its beginning.
+const maxEmbedChars = 8000
+
+// Embedder turns texts into vectors of a fixed dimension.
+type Embedder interface {
+	// Name identifies the vector space. Vectors from different embedders
+	// can't be compared, so an index built with another one is rebuilt.
+	Name() string
+	Embed(ctx context.Context, texts []string) ([]Vector, error)
+}
+
+// Default returns the Gemini embedder when GEMINI_API_KEY is set, and the
+// local embedder otherwise.
+func Default() Embedder {
+	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+		return NewGeminiEmbedder(apiKey)
+	}
+	logger.Info("GEMINI_API_KEY not set, using local embeddings")
+	return LocalEmbedder{}
+}
+
+// Vector is an embedding. It is stored as base64 little-endian float32s,
+// which is about a third of the size of a JSON number array.
+type Vector []float32
+
+// MarshalJSON encodes the vector as base64.
+func (v Vector) MarshalJSON() ([]byte, error) {
+	data := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(x))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(data))
+}
+
+// UnmarshalJSON decodes a vector written by MarshalJSON.
+func (v *Vector) UnmarshalJSON(text []byte) error {
+	var encoded string
+	if err := json.Unmarshal(text, &encoded); err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data)%4 != 0 {
+		return fmt.Errorf("invalid embedding vector")
+	}
+	*v = make(Vector, len(data)/4)
+	for i := range *v {
+		(*v)[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return nil
+}
+
+// Cosine returns the cosine similarity of two vectors: 1 for the same
+// direction, 0 for unrelated ones. Vectors of different lengths score 0.
+func Cosine(a, b Vector) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// truncate shortens text to maxEmbedChars bytes on a rune boundary.
+func truncate(text string) string {
+	if len(text) <= maxEmbedChars {
+		return text
+	}
+	cut := maxEmbedChars
+	for cut > 0 && text[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return text[:cut]
+}
@@ -0,0 +1,164 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVectorJSON(t *testing.T) {
+	data, err := json.Marshal(Vector{1, -0.5, 0.25})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var v Vector
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(v) != 3 || v[0] != 1 || v[1] != -0.5 || v[2] != 0.25 {
+		t.Errorf("Round trip gave %v", v)
+	}
+	if err := json.Unmarshal([]byte(`"AAA="`), &v); err == nil {
+		t.Errorf("Expected an error for a truncated vector")
+	}
+}
+
+func TestLocalEmbedder(t *testing.T) {
+	vectors, err := LocalEmbedder{}.Embed(context.Background(), []string{
+		"How to repot a fiddle leaf fig: choose a pot one size larger and use fresh potting soil.",
+		"Repotting your fiddle leaf fig: pick a slightly larger pot and fresh potting soil.",
+		"Our quarterly tax filing checklist for small business owners.",
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	same, different := Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2])
+	if same <= different || different > 0.1 {
+		t.Errorf("Expected the fig pages to be closer: same topic %.2f, different topic %.2f", same, different)
+	}
+}
+
+// countingEmbedder gives every text a one-hot vector and counts the calls.
+type countingEmbedder struct{ embedded int }
+
+func (e *countingEmbedder) Name() string { return "counting" }
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	vectors := make([]Vector, len(texts))
+	for i, text := range texts {
+		vectors[i] = Vector{0, 0}
+		if strings.Contains(text, "cats") {
+			vectors[i][0] = 1
+		} else {
+			vectors[i][1] = 1
+		}
+	}
+	e.embedded += len(texts)
+	return vectors, nil
+}
+
+func TestStoreUpdate(t *testing.T) {
+	store := NewStore(nil)
+	embedder := &countingEmbedder{}
+	now := time.Now()
+	docs := []Document{
+		{ID: 1, Title: "Cats", Modified: now, Text: "cats"},
+		{ID: 2, Title: "More cats", Modified: now, Text: "cats again"},
+		{ID: 3, Title: "Dogs", Modified: now, Text: "dogs"},
+	}
+	idx, err := store.Update(context.Background(), "site", embedder, docs, func(int, int) {})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(idx.Entries) != 3 || embedder.embedded != 3 {
+		t.Fatalf("Expected 3 pages embedded, got %d entries and %d embedded", len(idx.Entries), embedder.embedded)
+	}
+
+	// Only the modified page is embedded again; the removed one is dropped
+	docs = []Document{docs[0], {ID: 3, Title: "Dogs", Modified: now.Add(time.Hour), Text: "dogs"}}
+	idx, err = store.Update(context.Background(), "site", embedder, docs, func(int, int) {})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(idx.Entries) != 2 || embedder.embedded != 4 {
+		t.Errorf("Expected 2 entries after re-embedding 1 page, got %d entries and %d embedded", len(idx.Entries), embedder.embedded)
+	}
+	if got := store.Index("site"); len(got.Entries) != 2 {
+		t.Errorf("Expected the update to be stored, got %+v", got)
+	}
+}
+
+func TestIndexPairsAndSimilar(t *testing.T) {
+	idx := Index{Entries: []Entry{
+		{ID: 1, Vector: Vector{1, 0}},
+		{ID: 2, Vector: Vector{0.9, 0.1}},
+		{ID: 3, Vector: Vector{0, 1}},
+	}}
+	pairs := idx.Pairs(0.9)
+	if len(pairs) != 1 || pairs[0].A.ID != 1 || pairs[0].B.ID != 2 {
+		t.Errorf("Expected only pages 1 and 2 to pair, got %+v", pairs)
+	}
+	similar := idx.Similar(3, 1)
+	if len(similar) != 1 || similar[0].ID != 2 {
+		t.Errorf("Expected page 2 to be closest to page 3, got %+v", similar)
+	}
+}
+
+func TestGeminiEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/text-embedding-004:batchEmbedContents") || r.URL.Query().Get("key") != "test-key" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if n := strings.Count(string(body), `"taskType"`); n != 2 {
+			http.Error(w, "expected 2 requests", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"embeddings": [{"values": [1, 0]}, {"values": [0, 1]}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("GEMINI_API_ENDPOINT", server.URL)
+
+	vectors, err := NewGeminiEmbedder("test-key").Embed(context.Background(), []string{"one", "two"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors: %v", vectors)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	vectors := []Vector{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0.9, 0.1, 0},
+		{0, 0.95, 0.05},
+		{0, 0, 1},
+		{0.95, 0, 0.05},
+	}
+	groups := Cluster(vectors, 0.8)
+	want := [][]int{{0, 2, 5}, {1, 3}, {4}}
+	if len(groups) != len(want) {
+		t.Fatalf("Cluster = %v, want %v", groups, want)
+	}
+	for i := range want {
+		if len(groups[i]) != len(want[i]) {
+			t.Fatalf("Cluster = %v, want %v", groups, want)
+		}
+		for j := range want[i] {
+			if groups[i][j] != want[i][j] {
+				t.Fatalf("Cluster = %v, want %v", groups, want)
+			}
+		}
+	}
+	if central := Central(vectors, groups[0]); central != 0 {
+		t.Errorf("Expected vector 0 to be the most central, got %d", central)
+	}
+}
@@ -0,0 +1,116 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// geminiEmbeddingModel is the Gemini model used for embeddings.
+const geminiEmbeddingModel = "text-embedding-004"
+
+// geminiBatchSize is the most texts sent in one batchEmbedContents request.
+const geminiBatchSize = 100
+
+// GeminiEmbedder embeds texts with the Gemini embeddings API.
+type GeminiEmbedder struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewGeminiEmbedder creates an embedder using the given API key and the
+// GEMINI_API_ENDPOINT base URL, like the Gemini provider.
+func NewGeminiEmbedder(apiKey string) *GeminiEmbedder {
+	endpoint := os.Getenv("GEMINI_API_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://generativelanguage.googleapis.com/v1beta/"
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return &GeminiEmbedder{apiKey: apiKey, endpoint: endpoint, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Name identifies the Gemini embedding model.
+func (e *GeminiEmbedder) Name() string {
+	return "gemini/" + geminiEmbeddingModel
+}
+
+type geminiEmbedRequest struct {
+	Model   string `json:"model"`
+	Content struct {
+		Parts []struct {
+			Text string `json:"text"`
+		} `json:"parts"`
+	} `json:"content"`
+	TaskType string `json:"taskType"`
+}
+
+// Embed embeds the texts in batches.
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	vectors := make([]Vector, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiBatchSize {
+		batch, err := e.embedBatch(ctx, texts[start:min(start+geminiBatchSize, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *GeminiEmbedder) embedBatch(ctx context.Context, texts []string) ([]Vector, error) {
+	requests := make([]geminiEmbedRequest, len(texts))
+	for i, text := range texts {
+		requests[i].Model = "models/" + geminiEmbeddingModel
+		requests[i].Content.Parts = []struct {
+			Text string `json:"text"`
+		}{{Text: truncate(text)}}
+		requests[i].TaskType = "SEMANTIC_SIMILARITY"
+	}
+	body, err := json.Marshal(map[string]interface{}{"requests": requests})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%smodels/%s:batchEmbedContents?key=%s", e.endpoint, geminiEmbeddingModel, e.apiKey)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embeddings request failed: HTTP %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embeddings response has %d vectors for %d texts", len(result.Embeddings), len(texts))
+	}
+	vectors := make([]Vector, len(texts))
+	for i, embedding := range result.Embeddings {
+		vectors[i] = embedding.Values
+	}
+	logger.Debug("Embedded texts", "embedder", e.Name(), "count", len(texts))
+	return vectors, nil
+}
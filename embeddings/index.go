@@ -0,0 +1,209 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"Inference_Engine/storage"
+)
+
+// embedBatchSize is how many documents are embedded between progress reports.
+const embedBatchSize = 20
+
+// Document is a page to index.
+type Document struct {
+	ID       int
+	Title    string
+	Link     string
+	Modified time.Time
+	Text     string // Title and body as plain text or Markdown
+}
+
+// Entry is an indexed page.
+type Entry struct {
+	ID       int       `json:"id"`
+	Title    string    `json:"title"`
+	Link     string    `json:"link"`
+	Modified time.Time `json:"modified"`
+	Vector   Vector    `json:"vector"`
+}
+
+// Index holds the vectors of one site's pages.
+type Index struct {
+	Embedder string    `json:"embedder"`
+	Updated  time.Time `json:"updated"`
+	Entries  []Entry   `json:"entries"`
+}
+
+// Match is an indexed page and its similarity to another page.
+type Match struct {
+	Entry
+	Similarity float64
+}
+
+// Pair is two pages whose similarity is at least a threshold.
+type Pair struct {
+	A, B       Entry
+	Similarity float64
+}
+
+// Entry returns the indexed page with the given ID.
+func (idx Index) Entry(id int) (Entry, bool) {
+	for _, entry := range idx.Entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Similar returns the k pages most similar to the page with the given ID,
+// most similar first.
+func (idx Index) Similar(id, k int) []Match {
+	page, ok := idx.Entry(id)
+	if !ok {
+		return nil
+	}
+	var matches []Match
+	for _, entry := range idx.Entries {
+		if entry.ID != id {
+			matches = append(matches, Match{Entry: entry, Similarity: Cosine(page.Vector, entry.Vector)})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// Pairs returns every pair of pages at least threshold similar, most
+// similar first.
+func (idx Index) Pairs(threshold float64) []Pair {
+	var pairs []Pair
+	for i, a := range idx.Entries {
+		for _, b := range idx.Entries[i+1:] {
+			if similarity := Cosine(a.Vector, b.Vector); similarity >= threshold {
+				pairs = append(pairs, Pair{A: a, B: b, Similarity: similarity})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Similarity > pairs[j].Similarity })
+	return pairs
+}
+
+// indexDocument is the state database document holding a site's index.
+func indexDocument(site string) string {
+	return "embeddings:" + site
+}
+
+// Store keeps the embeddings index of each site, persisted in the state
+// database. It is safe for concurrent use.
+type Store struct {
+	db *storage.DB // nil keeps indexes in memory only
+
+	mu     sync.Mutex
+	memory map[string]Index // Used when db is nil
+}
+
+// NewStore returns the indexes saved in db.
+func NewStore(db *storage.DB) *Store {
+	return &Store{db: db, memory: map[string]Index{}}
+}
+
+// Index returns a site's index; it is empty until Update has run.
+func (s *Store) Index(site string) Index {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(site)
+}
+
+// Update brings a site's index in line with docs: pages that are new or
+// modified since they were indexed are embedded, and pages no longer in docs
+// are dropped. progress is called with the number of pages embedded so far.
+func (s *Store) Update(ctx context.Context, site string, embedder Embedder, docs []Document, progress func(done, total int)) (Index, error) {
+	s.mu.Lock()
+	old := s.load(site)
+	s.mu.Unlock()
+
+	current := map[int]Entry{}
+	if old.Embedder == embedder.Name() {
+		for _, entry := range old.Entries {
+			current[entry.ID] = entry
+		}
+	}
+	idx := Index{Embedder: embedder.Name()}
+	var stale []Document
+	for _, doc := range docs {
+		if entry, ok := current[doc.ID]; ok && entry.Modified.Equal(doc.Modified) {
+			entry.Title, entry.Link = doc.Title, doc.Link
+			idx.Entries = append(idx.Entries, entry)
+		} else {
+			stale = append(stale, doc)
+		}
+	}
+
+	for start := 0; start < len(stale); start += embedBatchSize {
+		progress(start, len(stale))
+		batch := stale[start:min(start+embedBatchSize, len(stale))]
+		texts := make([]string, len(batch))
+		for i, doc := range batch {
+			texts[i] = doc.Text
+		}
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return Index{}, fmt.Errorf("failed to embed pages: %w", err)
+		}
+		for i, doc := range batch {
+			idx.Entries = append(idx.Entries, Entry{ID: doc.ID, Title: doc.Title, Link: doc.Link, Modified: doc.Modified, Vector: vectors[i]})
+		}
+	}
+	progress(len(stale), len(stale))
+	sort.Slice(idx.Entries, func(i, j int) bool { return idx.Entries[i].ID < idx.Entries[j].ID })
+	idx.Updated = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.save(site, idx); err != nil {
+		return Index{}, err
+	}
+	logger.Info("Updated embeddings index", "site", site, "embedder", idx.Embedder, "pages", len(idx.Entries), "embedded", len(stale))
+	return idx, nil
+}
+
+// load reads a site's index; the caller holds s.mu.
+func (s *Store) load(site string) Index {
+	if s.db == nil {
+		return s.memory[site]
+	}
+	var idx Index
+	text, found, err := s.db.Document(indexDocument(site))
+	if err != nil {
+		logger.Error("Failed to load embeddings index", "site", site, "error", err)
+	} else if found {
+		if err := json.Unmarshal([]byte(text), &idx); err != nil {
+			logger.Warn("Saved embeddings index no longer parses, ignoring it", "site", site, "error", err)
+		}
+	}
+	return idx
+}
+
+// save replaces a site's index; the caller holds s.mu.
+func (s *Store) save(site string, idx Index) error {
+	if s.db == nil {
+		s.memory[site] = idx
+		return nil
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetDocument(indexDocument(site), string(data)); err != nil {
+		return fmt.Errorf("failed to save embeddings index: %w", err)
+	}
+	return nil
+}
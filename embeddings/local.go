@@ -0,0 +1,90 @@
+package embeddings
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// localDimensions is the size of the local embedder's vectors.
+const localDimensions = 1024
+
+// stopWords are left out of local embeddings; they say nothing about a topic.
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true, "and": true, "any": true,
+	"are": true, "as": true, "at": true, "be": true, "been": true, "but": true, "by": true, "can": true,
+	"do": true, "for": true, "from": true, "has": true, "have": true, "how": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "its": true, "more": true, "most": true, "no": true, "not": true,
+	"of": true, "on": true, "or": true, "our": true, "so": true, "than": true, "that": true, "the": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "up": true, "was": true, "we": true, "were": true, "what": true, "when": true, "which": true,
+	"who": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// LocalEmbedder embeds texts offline by hashing their words and word pairs
+// into a fixed number of buckets. It only sees shared vocabulary, not
+// meaning, but needs no API key and is good at spotting pages that say the
+// same thing in the same words.
+type LocalEmbedder struct{}
+
+// Name identifies the local embedder's vector space.
+func (LocalEmbedder) Name() string {
+	return "local/hashed-terms-1024"
+}
+
+// Embed embeds each text.
+func (LocalEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	vectors := make([]Vector, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = localVector(truncate(text))
+	}
+	return vectors, nil
+}
+
+// localVector counts the text's terms into buckets, with sublinear term
+// frequency so repetition doesn't dominate, and normalizes the result.
+func localVector(text string) Vector {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	counts := make([]float64, localDimensions)
+	previous := ""
+	for _, word := range words {
+		if stopWords[word] || len(word) < 2 {
+			previous = ""
+			continue
+		}
+		counts[bucket(word)]++
+		if previous != "" {
+			counts[bucket(previous+" "+word)]++
+		}
+		previous = word
+	}
+	var norm float64
+	for i, count := range counts {
+		if count > 0 {
+			counts[i] = 1 + math.Log(count)
+			norm += counts[i] * counts[i]
+		}
+	}
+	vector := make(Vector, localDimensions)
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range counts {
+		vector[i] = float32(value / norm)
+	}
+	return vector
+}
+
+func bucket(term string) int {
+	h := fnv.New32a()
+	h.Write([]byte(term))
+	return int(h.Sum32() % localDimensions)
+}
@@ -0,0 +1,138 @@
+// Package facts reads the fact sheet the model extracts from source
+// material: the quotes, statistics and named entities generated content is
+// grounded in.
+package facts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("facts")
+
+// Quote is a passage quoted word for word from a source.
+type Quote struct {
+	Text    string `json:"text"`
+	Speaker string `json:"speaker"` // Who said or wrote it, if known
+	Source  string `json:"source"`  // Title of the source it is from
+}
+
+// Statistic is a figure from a source with what it measures.
+type Statistic struct {
+	Value   string `json:"value"`   // e.g. "42%" or "$1.2 million"
+	Context string `json:"context"` // What the figure measures, when and where
+	Source  string `json:"source"`
+}
+
+// Entity is a person, organization, product or place a source names.
+type Entity struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"` // e.g. "person", "organization"
+	Source string `json:"source"`
+}
+
+// Sheet is the fact sheet of a set of sources.
+type Sheet struct {
+	Quotes     []Quote     `json:"quotes"`
+	Statistics []Statistic `json:"statistics"`
+	Entities   []Entity    `json:"entities"`
+}
+
+// Parse reads the model's answer to a fact sheet prompt: a JSON object,
+// possibly wrapped in prose or a code fence. Entries without their quote,
+// figure or name are dropped.
+func Parse(output string) (Sheet, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return Sheet{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var raw Sheet
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return Sheet{}, fmt.Errorf("failed to parse fact sheet: %w", err)
+	}
+	var sheet Sheet
+	for _, q := range raw.Quotes {
+		if q.Text = strings.Trim(strings.TrimSpace(q.Text), `"“”`); q.Text != "" {
+			sheet.Quotes = append(sheet.Quotes, Quote{q.Text, strings.TrimSpace(q.Speaker), strings.TrimSpace(q.Source)})
+		}
+	}
+	for _, s := range raw.Statistics {
+		if s.Value = strings.TrimSpace(s.Value); s.Value != "" {
+			sheet.Statistics = append(sheet.Statistics, Statistic{s.Value, strings.TrimSpace(s.Context), strings.TrimSpace(s.Source)})
+		}
+	}
+	for _, e := range raw.Entities {
+		if e.Name = strings.TrimSpace(e.Name); e.Name != "" {
+			sheet.Entities = append(sheet.Entities, Entity{e.Name, strings.TrimSpace(e.Type), strings.TrimSpace(e.Source)})
+		}
+	}
+	logger.Info("Parsed fact sheet", "quotes", len(sheet.Quotes), "statistics", len(sheet.Statistics), "entities", len(sheet.Entities))
+	return sheet, nil
+}
+
+// IsEmpty reports whether the sheet has no facts.
+func (s Sheet) IsEmpty() bool {
+	return len(s.Quotes) == 0 && len(s.Statistics) == 0 && len(s.Entities) == 0
+}
+
+// Markdown lists the facts under a heading per kind, with their sources.
+func (s Sheet) Markdown() string {
+	var b strings.Builder
+	if len(s.Quotes) > 0 {
+		b.WriteString("## Quotes\n\n")
+		for _, q := range s.Quotes {
+			fmt.Fprintf(&b, "- \"%s\"%s%s\n", q.Text, prefixed(" — ", q.Speaker), sourceNote(q.Source))
+		}
+		b.WriteString("\n")
+	}
+	if len(s.Statistics) > 0 {
+		b.WriteString("## Statistics\n\n")
+		for _, st := range s.Statistics {
+			fmt.Fprintf(&b, "- **%s**%s%s\n", st.Value, prefixed(": ", st.Context), sourceNote(st.Source))
+		}
+		b.WriteString("\n")
+	}
+	if len(s.Entities) > 0 {
+		b.WriteString("## Named Entities\n\n")
+		for _, e := range s.Entities {
+			fmt.Fprintf(&b, "- %s%s%s\n", e.Name, parenthesized(e.Type), sourceNote(e.Source))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Instruction asks the model to ground the content in the sheet, empty if
+// the sheet has no facts.
+func (s Sheet) Instruction() string {
+	if s.IsEmpty() {
+		return ""
+	}
+	return "Fact sheet extracted from the True Sources. Quote these passages word for word and attribute them to their speakers, use these figures exactly as given with what they measure, and spell the names as listed. Don't add quotes or statistics that are not on the sheet or in the sources.\n\n" + s.Markdown()
+}
+
+// prefixed returns s after prefix, or "" if s is empty.
+func prefixed(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	return prefix + s
+}
+
+// parenthesized returns " (s)", or "" if s is empty.
+func parenthesized(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " (" + s + ")"
+}
+
+// sourceNote names the source a fact is from, if known.
+func sourceNote(source string) string {
+	if source == "" {
+		return ""
+	}
+	return " [" + source + "]"
+}
@@ -0,0 +1,48 @@
+package facts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	output := "Here is the fact sheet:\n```json\n" + `{
+  "quotes": [{"text": "\"We doubled our output.\"", "speaker": "Jane Roe, CEO", "source": "Annual Report"}, {"text": "  "}],
+  "statistics": [{"value": "42%", "context": "share of sales online in 2023", "source": "Annual Report"}],
+  "entities": [{"name": "Acme Corp", "type": "organization"}, {"name": ""}]
+}` + "\n```"
+
+	sheet, err := Parse(output)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(sheet.Quotes) != 1 || sheet.Quotes[0].Text != "We doubled our output." {
+		t.Errorf("Unexpected quotes %+v", sheet.Quotes)
+	}
+	if len(sheet.Statistics) != 1 || len(sheet.Entities) != 1 {
+		t.Errorf("Expected the empty entries to be dropped, got %+v", sheet)
+	}
+
+	md := sheet.Markdown()
+	for _, want := range []string{
+		`- "We doubled our output." — Jane Roe, CEO [Annual Report]`,
+		"- **42%**: share of sales online in 2023 [Annual Report]",
+		"- Acme Corp (organization)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in:\n%s", want, md)
+		}
+	}
+	if !strings.Contains(sheet.Instruction(), md) {
+		t.Error("Expected the instruction to include the sheet")
+	}
+}
+
+func TestParseRejectsNonJSON(t *testing.T) {
+	if _, err := Parse("There are no facts."); err == nil {
+		t.Error("Expected an answer without JSON to be rejected")
+	}
+	if got := (Sheet{}).Instruction(); got != "" {
+		t.Errorf("Expected no instruction for an empty sheet, got %q", got)
+	}
+}
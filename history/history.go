@@ -0,0 +1,225 @@
+// Package history keeps every draft produced by the Generator (prompt,
+// sources, model and output) in the state database, so earlier versions can
+// be browsed and restored after the result box has been overwritten. Drafts
+// also carry their review state and reviewers' notes, so a second person can
+// approve content before it is published.
+package history
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+var logger = logging.For("history")
+
+// DefaultMaxDrafts caps how many drafts a store keeps; the oldest are dropped.
+const DefaultMaxDrafts = 500
+
+// Draft is one generation result.
+type Draft struct {
+	ID           int         `json:"id"`
+	Created      time.Time   `json:"created"`
+	Prompt       string      `json:"prompt"`
+	Instruction  string      `json:"instruction,omitempty"`
+	Model        string      `json:"model"`
+	SourcesHash  string      `json:"sources_hash"`  // Identifies the exact source contents used
+	SourceTitles []string    `json:"source_titles"` // For display; the contents aren't stored
+	Output       string      `json:"output"`
+	Review       ReviewState `json:"review,omitempty"` // Where the draft is in the review workflow
+}
+
+// HashSources returns a short fingerprint of source contents, so drafts made
+// from the same sources can be recognized without storing them.
+func HashSources(contents []string) string {
+	h := sha256.New()
+	for _, c := range contents {
+		fmt.Fprintf(h, "%d:", len(c))
+		h.Write([]byte(c))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Store is the draft history table. It is safe for concurrent use.
+type Store struct {
+	db        *storage.DB
+	maxDrafts int
+	onAdd     func(Draft) // See OnAdd
+}
+
+// Open returns the draft history kept in db.
+func Open(db *storage.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("generation history needs a state database")
+	}
+	return &Store{db: db, maxDrafts: DefaultMaxDrafts}, nil
+}
+
+// ImportFile adds the drafts from a generation_history.json file written by
+// earlier versions, oldest first, and renames the file so it is imported once.
+func (s *Store) ImportFile(path string) error {
+	_, err := storage.ImportLegacyFile(path, func(data []byte) error {
+		var drafts []Draft
+		if err := json.Unmarshal(data, &drafts); err != nil {
+			return err
+		}
+		for _, d := range drafts {
+			if _, err := s.Add(d); err != nil {
+				return err
+			}
+		}
+		logger.Info("Imported generation history", "drafts", len(drafts), "path", path)
+		return nil
+	})
+	return err
+}
+
+// Add stores a draft, assigning its ID and creation time, and returns it.
+func (s *Store) Add(d Draft) (Draft, error) {
+	if d.Created.IsZero() {
+		d.Created = time.Now()
+	}
+	if d.Review == "" {
+		d.Review = ReviewDraft
+	}
+	titles, err := json.Marshal(d.SourceTitles)
+	if err != nil {
+		return d, err
+	}
+	err = s.db.Tx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`INSERT INTO drafts (created, prompt, instruction, model, sources_hash, source_titles, output, review_state)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			storage.EncodeTime(d.Created), d.Prompt, d.Instruction, d.Model, d.SourcesHash, string(titles), d.Output, string(d.Review))
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		d.ID = int(id)
+		_, err = tx.Exec(`DELETE FROM drafts WHERE id NOT IN (SELECT id FROM drafts ORDER BY id DESC LIMIT ?)`, s.maxDrafts)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM review_notes WHERE draft_id NOT IN (SELECT id FROM drafts)`)
+		return err
+	})
+	if err != nil {
+		return d, fmt.Errorf("failed to save generation history: %w", err)
+	}
+	if s.onAdd != nil {
+		s.onAdd(d)
+	}
+	return d, nil
+}
+
+// OnAdd sets a function called with every draft added from then on, after
+// it is stored. It is not safe to call while drafts are being added.
+func (s *Store) OnAdd(hook func(Draft)) {
+	s.onAdd = hook
+}
+
+// List returns the drafts, newest first.
+func (s *Store) List() []Draft {
+	drafts, err := s.query(`ORDER BY id DESC`)
+	if err != nil {
+		logger.Error("Failed to load generation history", "error", err)
+	}
+	return drafts
+}
+
+// Search returns the drafts whose prompt, instruction, model or output
+// contains text (case-insensitive), newest first.
+func (s *Store) Search(text string) []Draft {
+	text = strings.ToLower(strings.TrimSpace(text))
+	drafts := s.List()
+	if text == "" {
+		return drafts
+	}
+	var matching []Draft
+	for _, d := range drafts {
+		haystack := strings.ToLower(d.Prompt + "\n" + d.Instruction + "\n" + d.Model + "\n" + d.Output)
+		if strings.Contains(haystack, text) {
+			matching = append(matching, d)
+		}
+	}
+	return matching
+}
+
+// Get returns the draft with the given ID.
+func (s *Store) Get(id int) (Draft, bool) {
+	drafts, err := s.query(`WHERE id = ?`, id)
+	if err != nil || len(drafts) == 0 {
+		return Draft{}, false
+	}
+	return drafts[0], true
+}
+
+// Delete removes one draft.
+func (s *Store) Delete(id int) error {
+	var n int64
+	err := s.db.Tx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`DELETE FROM drafts WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		n, _ = res.RowsAffected()
+		_, err = tx.Exec(`DELETE FROM review_notes WHERE draft_id = ?`, id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete draft %d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("draft %d not found", id)
+	}
+	return nil
+}
+
+// Clear removes every draft.
+func (s *Store) Clear() error {
+	err := s.db.Tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM drafts`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM review_notes`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear generation history: %w", err)
+	}
+	return nil
+}
+
+// query loads the drafts selected by a WHERE/ORDER BY clause.
+func (s *Store) query(clause string, args ...any) ([]Draft, error) {
+	rows, err := s.db.Query(`SELECT id, created, prompt, instruction, model, sources_hash, source_titles, output, review_state FROM drafts `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var drafts []Draft
+	for rows.Next() {
+		var d Draft
+		var created int64
+		var titles string
+		if err := rows.Scan(&d.ID, &created, &d.Prompt, &d.Instruction, &d.Model, &d.SourcesHash, &titles, &d.Output, &d.Review); err != nil {
+			return drafts, err
+		}
+		d.Created = storage.DecodeTime(created)
+		if err := json.Unmarshal([]byte(titles), &d.SourceTitles); err != nil {
+			logger.Warn("Ignoring unreadable source titles", "draft", d.ID, "error", err)
+		}
+		drafts = append(drafts, d)
+	}
+	return drafts, rows.Err()
+}
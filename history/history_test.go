@@ -0,0 +1,162 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func openTestDB(t *testing.T, path string) *storage.DB {
+	t.Helper()
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	return db
+}
+
+func TestStorePersistsDrafts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	db := openTestDB(t, path)
+	s, err := Open(db)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, err := s.Add(Draft{Prompt: "About cats", Model: "gemini/gemini-flash", Output: "Cats are great."})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := s.Add(Draft{Prompt: "About dogs", Model: "MOA (Mixture of Agents)", Output: "Dogs are loyal."}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if first.ID == 0 || first.Created.IsZero() {
+		t.Errorf("Expected an ID and creation time, got %+v", first)
+	}
+
+	db.Close()
+	db = openTestDB(t, path)
+	defer db.Close()
+	reopened, err := Open(db)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	drafts := reopened.List()
+	if len(drafts) != 2 || drafts[0].Prompt != "About dogs" {
+		t.Fatalf("Expected 2 drafts newest first, got %+v", drafts)
+	}
+	if got := reopened.Search("CATS"); len(got) != 1 || got[0].ID != first.ID {
+		t.Errorf("Search returned %+v", got)
+	}
+	third, _ := reopened.Add(Draft{Prompt: "Again"})
+	if third.ID <= drafts[0].ID {
+		t.Errorf("Expected IDs to keep increasing after reopening, got %d", third.ID)
+	}
+
+	if err := reopened.Delete(first.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := reopened.Get(first.ID); ok {
+		t.Errorf("Expected the draft to be deleted")
+	}
+	if err := reopened.Clear(); err != nil || len(reopened.List()) != 0 {
+		t.Errorf("Clear left %d drafts (err %v)", len(reopened.List()), err)
+	}
+}
+
+func TestStoreDropsOldestDrafts(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "state.db"))
+	defer db.Close()
+	s, _ := Open(db)
+	s.maxDrafts = 2
+	for _, prompt := range []string{"one", "two", "three"} {
+		s.Add(Draft{Prompt: prompt})
+	}
+	drafts := s.List()
+	if len(drafts) != 2 || drafts[1].Prompt != "two" {
+		t.Errorf("Expected the oldest draft to be dropped, got %+v", drafts)
+	}
+}
+
+func TestStoreImportsLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, filepath.Join(dir, "state.db"))
+	defer db.Close()
+	s, _ := Open(db)
+
+	legacy := filepath.Join(dir, "generation_history.json")
+	data := `[{"id":7,"created":"2025-01-02T03:04:05Z","prompt":"Old prompt","model":"deepseek/deepseek-chat","source_titles":["About"],"output":"Old output"}]`
+	if err := os.WriteFile(legacy, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ImportFile(legacy); err != nil {
+		t.Fatalf("ImportFile failed: %v", err)
+	}
+	drafts := s.List()
+	if len(drafts) != 1 || drafts[0].Prompt != "Old prompt" || drafts[0].Created.Year() != 2025 || len(drafts[0].SourceTitles) != 1 {
+		t.Fatalf("Unexpected imported drafts %+v", drafts)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("Expected the legacy file to be renamed after import")
+	}
+	if err := s.ImportFile(legacy); err != nil || len(s.List()) != 1 {
+		t.Errorf("Expected a second import to do nothing (err %v)", err)
+	}
+}
+
+func TestHashSources(t *testing.T) {
+	if HashSources([]string{"ab", "c"}) == HashSources([]string{"a", "bc"}) {
+		t.Errorf("Expected different splits of the same text to hash differently")
+	}
+	if HashSources([]string{"x"}) != HashSources([]string{"x"}) {
+		t.Errorf("Expected the hash to be stable")
+	}
+}
+
+func TestReviewWorkflow(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "state.db"))
+	defer db.Close()
+	s, _ := Open(db)
+	d, _ := s.Add(Draft{Prompt: "Pricing page", Output: "Plans start at $10."})
+	if d.Review != ReviewDraft {
+		t.Fatalf("Expected a new draft to be a draft, got %q", d.Review)
+	}
+
+	if err := s.Move(d.ID, ReviewApproved, "Sam", ""); err == nil {
+		t.Error("Expected a draft not to be approved before review")
+	}
+	if err := s.Move(d.ID, ReviewInReview, "Ann", ""); err != nil {
+		t.Fatalf("Submitting for review failed: %v", err)
+	}
+	if err := s.Move(d.ID, ReviewDraft, "Sam", " "); err == nil {
+		t.Error("Expected sending a draft back without a note to fail")
+	}
+	if err := s.Comment(d.ID, "Sam", "Check the price against the site."); err != nil {
+		t.Fatalf("Comment failed: %v", err)
+	}
+	if err := s.Move(d.ID, ReviewApproved, "Sam", "Price checked."); err != nil {
+		t.Fatalf("Approving failed: %v", err)
+	}
+	if got, _ := s.Get(d.ID); got.Review != ReviewApproved {
+		t.Errorf("Expected the draft to be approved, got %q", got.Review)
+	}
+	if got, ok := s.Approved("Plans start at $10."); !ok || got.ID != d.ID {
+		t.Errorf("Expected the approved output to be found, got %+v", got)
+	}
+	if _, ok := s.Approved("Plans start at $12."); ok {
+		t.Error("Expected other content not to count as approved")
+	}
+
+	notes := s.Notes(d.ID)
+	if len(notes) != 3 || notes[1].Reviewer != "Sam" || notes[1].State != ReviewInReview || notes[2].State != ReviewApproved {
+		t.Errorf("Unexpected review notes %+v", notes)
+	}
+
+	if err := s.Delete(d.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if notes := s.Notes(d.ID); len(notes) != 0 {
+		t.Errorf("Expected a deleted draft's notes to go too, got %+v", notes)
+	}
+}
@@ -0,0 +1,165 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"Inference_Engine/storage"
+)
+
+// ReviewState is where a draft is in the review workflow: draft, in review,
+// approved, then published.
+type ReviewState string
+
+// The review states, in workflow order.
+const (
+	ReviewDraft     ReviewState = "draft"
+	ReviewInReview  ReviewState = "in_review"
+	ReviewApproved  ReviewState = "approved"
+	ReviewPublished ReviewState = "published"
+)
+
+// reviewTransitions lists the states each state can move to. Sending a
+// draft back from review, or reopening an approved one, needs a note.
+var reviewTransitions = map[ReviewState][]ReviewState{
+	ReviewDraft:     {ReviewInReview},
+	ReviewInReview:  {ReviewApproved, ReviewDraft},
+	ReviewApproved:  {ReviewPublished, ReviewInReview},
+	ReviewPublished: nil,
+}
+
+// Label is the state as shown to people.
+func (s ReviewState) Label() string {
+	switch s {
+	case ReviewInReview:
+		return "In review"
+	case ReviewApproved:
+		return "Approved"
+	case ReviewPublished:
+		return "Published"
+	}
+	return "Draft"
+}
+
+// Next returns the states a draft in state s can move to.
+func (s ReviewState) Next() []ReviewState {
+	if s == "" {
+		s = ReviewDraft
+	}
+	return reviewTransitions[s]
+}
+
+// CanMoveTo reports whether a draft in state s can move to state to.
+func (s ReviewState) CanMoveTo(to ReviewState) bool {
+	for _, next := range s.Next() {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// needsNote reports whether moving from one state to another must explain
+// why: sending work back is only useful with the reason.
+func needsNote(from, to ReviewState) bool {
+	return (from == ReviewInReview && to == ReviewDraft) || (from == ReviewApproved && to == ReviewInReview)
+}
+
+// ReviewNote is a reviewer's comment on a draft, or a change of its state
+// with the comment that came with it.
+type ReviewNote struct {
+	ID       int
+	DraftID  int
+	Created  time.Time
+	Reviewer string
+	State    ReviewState // The state the draft moved to, or its state when commented on
+	Note     string
+}
+
+// Move changes a draft's review state, recording who did it and why. It
+// fails if the workflow doesn't allow the change.
+func (s *Store) Move(id int, to ReviewState, reviewer, note string) error {
+	note = strings.TrimSpace(note)
+	return s.db.Tx(func(tx *sql.Tx) error {
+		var from ReviewState
+		if err := tx.QueryRow(`SELECT review_state FROM drafts WHERE id = ?`, id).Scan(&from); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("draft %d not found", id)
+			}
+			return fmt.Errorf("failed to load draft %d: %w", id, err)
+		}
+		if !from.CanMoveTo(to) {
+			return fmt.Errorf("a draft that is %s cannot become %s", strings.ToLower(from.Label()), strings.ToLower(to.Label()))
+		}
+		if note == "" && needsNote(from, to) {
+			return fmt.Errorf("add a note saying what needs to change")
+		}
+		if _, err := tx.Exec(`UPDATE drafts SET review_state = ? WHERE id = ?`, string(to), id); err != nil {
+			return fmt.Errorf("failed to update draft %d: %w", id, err)
+		}
+		if err := insertNote(tx, id, reviewer, to, note); err != nil {
+			return err
+		}
+		logger.Info("Moved draft in review", "draft", id, "from", from, "to", to, "reviewer", reviewer)
+		return nil
+	})
+}
+
+// Approved returns the newest approved draft whose output is exactly
+// output, so content approved as a draft can be recognized where it is saved.
+func (s *Store) Approved(output string) (Draft, bool) {
+	drafts, err := s.query(`WHERE output = ? AND review_state = ? ORDER BY id DESC LIMIT 1`, output, string(ReviewApproved))
+	if err != nil || len(drafts) == 0 {
+		return Draft{}, false
+	}
+	return drafts[0], true
+}
+
+// Comment adds a reviewer's note to a draft without changing its state.
+func (s *Store) Comment(id int, reviewer, note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return fmt.Errorf("the note is empty")
+	}
+	d, ok := s.Get(id)
+	if !ok {
+		return fmt.Errorf("draft %d not found", id)
+	}
+	return s.db.Tx(func(tx *sql.Tx) error {
+		return insertNote(tx, id, reviewer, d.Review, note)
+	})
+}
+
+// insertNote records a review note.
+func insertNote(tx *sql.Tx, id int, reviewer string, state ReviewState, note string) error {
+	_, err := tx.Exec(`INSERT INTO review_notes (draft_id, created, reviewer, state, note) VALUES (?, ?, ?, ?, ?)`,
+		id, storage.EncodeTime(time.Now()), strings.TrimSpace(reviewer), string(state), note)
+	if err != nil {
+		return fmt.Errorf("failed to save review note: %w", err)
+	}
+	return nil
+}
+
+// Notes returns a draft's review notes, oldest first.
+func (s *Store) Notes(id int) []ReviewNote {
+	rows, err := s.db.Query(`SELECT id, draft_id, created, reviewer, state, note FROM review_notes WHERE draft_id = ? ORDER BY id`, id)
+	if err != nil {
+		logger.Error("Failed to load review notes", "draft", id, "error", err)
+		return nil
+	}
+	defer rows.Close()
+	var notes []ReviewNote
+	for rows.Next() {
+		var n ReviewNote
+		var created int64
+		if err := rows.Scan(&n.ID, &n.DraftID, &created, &n.Reviewer, &n.State, &n.Note); err != nil {
+			logger.Error("Failed to read review note", "draft", id, "error", err)
+			return notes
+		}
+		n.Created = storage.DecodeTime(created)
+		notes = append(notes, n)
+	}
+	return notes
+}
@@ -0,0 +1,114 @@
+// Package i18n translates the application's UI strings.
+//
+// Messages are looked up by their English text, so untranslated strings fall
+// back to English. Translations live in locales/<code>.json as a flat map
+// from English text to translated text.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language the UI strings are written in.
+const DefaultLanguage = "en"
+
+// Language is a supported UI language.
+type Language struct {
+	Code string // e.g. "es"
+	Name string // Native name shown in the language picker, e.g. "Español"
+}
+
+// Languages lists the supported UI languages in picker order.
+var Languages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "es", Name: "Español"},
+}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var (
+	mutex    sync.RWMutex
+	current  = DefaultLanguage
+	messages map[string]string // nil for the default language
+)
+
+// SetLanguage switches the active language. Unknown codes return an error and
+// leave the current language unchanged. Strings already shown are not updated.
+func SetLanguage(code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == DefaultLanguage {
+		mutex.Lock()
+		current, messages = DefaultLanguage, nil
+		mutex.Unlock()
+		return nil
+	}
+	catalog, err := loadCatalog(code)
+	if err != nil {
+		return err
+	}
+	mutex.Lock()
+	current, messages = code, catalog
+	mutex.Unlock()
+	return nil
+}
+
+// CurrentLanguage returns the active language code.
+func CurrentLanguage() string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return current
+}
+
+// LanguageName returns the display name for a language code, or the code itself.
+func LanguageName(code string) string {
+	for _, l := range Languages {
+		if l.Code == code {
+			return l.Name
+		}
+	}
+	return code
+}
+
+// LanguageCode returns the code for a display name, or DefaultLanguage.
+func LanguageCode(name string) string {
+	for _, l := range Languages {
+		if l.Name == name {
+			return l.Code
+		}
+	}
+	return DefaultLanguage
+}
+
+// T returns the translation of an English UI string in the active language.
+func T(message string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if translated, ok := messages[message]; ok && translated != "" {
+		return translated
+	}
+	return message
+}
+
+// Tf translates a format string and then formats it with args.
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// loadCatalog reads the embedded translations for a language.
+func loadCatalog(code string) (map[string]string, error) {
+	data, err := localeFiles.ReadFile(path.Join("locales", code+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language %q", code)
+	}
+	var catalog map[string]string
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse %s translations: %w", code, err)
+	}
+	return catalog, nil
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-zA-Z%]`)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for _, l := range Languages {
+		if l.Code == DefaultLanguage {
+			continue
+		}
+		catalog, err := loadCatalog(l.Code)
+		if err != nil {
+			t.Fatalf("%s: %v", l.Code, err)
+		}
+		for message, translated := range catalog {
+			want := formatVerb.FindAllString(message, -1)
+			got := formatVerb.FindAllString(translated, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s: %q has verbs %v, translation has %v", l.Code, message, want, got)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s: %q verb %d is %s, translation uses %s", l.Code, message, i, want[i], got[i])
+				}
+			}
+		}
+	}
+}
+
+// TestCatalogsCoverUI checks every literal passed to T/Tf in the UI has a translation.
+func TestCatalogsCoverUI(t *testing.T) {
+	files, _ := filepath.Glob("../ui/*.go")
+	files = append(files, "../main.go")
+	call := regexp.MustCompile(`i18n\.Tf?\(("(?:[^"\\]|\\.)*")`)
+
+	for _, l := range Languages {
+		if l.Code == DefaultLanguage {
+			continue
+		}
+		catalog, err := loadCatalog(l.Code)
+		if err != nil {
+			t.Fatalf("%s: %v", l.Code, err)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, match := range call.FindAllStringSubmatch(string(data), -1) {
+				message, err := strconv.Unquote(match[1])
+				if err != nil {
+					t.Fatalf("%s: %v", file, err)
+				}
+				if _, ok := catalog[message]; !ok {
+					t.Errorf("%s: missing translation for %q (%s)", l.Code, message, filepath.Base(file))
+				}
+			}
+		}
+	}
+}
+
+func TestTranslateFallsBackToEnglish(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	if err := SetLanguage("es"); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("Settings"); got != "Ajustes" {
+		t.Errorf("T(Settings) = %q", got)
+	}
+	if got := T("Not a UI string"); got != "Not a UI string" {
+		t.Errorf("untranslated message = %q", got)
+	}
+	if got := Tf("%d pages", 3); got != "3 páginas" {
+		t.Errorf("Tf = %q", got)
+	}
+	if err := SetLanguage("xx"); err == nil || CurrentLanguage() != "es" {
+		t.Errorf("unknown language: err %v, current %s", err, CurrentLanguage())
+	}
+}
@@ -9,9 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 
+	"Inference_Engine/logging"
+
 	// "strconv" // Removed if not used directly
 	"strings"
 	"sync"
@@ -171,6 +172,7 @@ type CerebrasProvider struct {
 	topP         *float64
 	seed         *int64
 	extraHeaders map[string]string
+	override     ProviderOverride // Base URL and headers of a proxy or gateway, fixed at creation
 	logger       utils.Logger
 	client       *http.Client // Changed from *CerebrasClient, gollm likely handles HTTP
 
@@ -182,7 +184,7 @@ func init() {
 	registry := providers.GetDefaultRegistry()
 	// Ensure NewCerebrasProvider matches the expected ProviderConstructor signature
 	registry.Register("cerebras", NewCerebrasProvider)
-	log.Println("Registered Cerebras provider constructor with gollm registry")
+	logger.Info("Registered provider constructor with gollm registry", "provider", "cerebras")
 }
 
 // NewCerebrasProvider creates a new Cerebras provider instance.
@@ -190,7 +192,7 @@ func init() {
 // This matches the likely ProviderConstructor type:
 // func(apiKey, model string, extraHeaders map[string]string) providers.Provider
 func NewCerebrasProvider(apiKey, model string, extraHeaders map[string]string) providers.Provider {
-	log.Printf("[DEBUG] NewCerebrasProvider called! apiKey: %t, model: %s", apiKey != "", model) // Log entry and basic args check
+	logger.Debug("NewCerebrasProvider called", "has_api_key", apiKey != "", logging.Model(model))
 	// Initialize with arguments and defaults
 	provider := &CerebrasProvider{
 		apiKey:       apiKey, // Use provided apiKey
@@ -210,7 +212,11 @@ func NewCerebrasProvider(apiKey, model string, extraHeaders map[string]string) p
 			provider.extraHeaders[k] = v
 		}
 	}
-	log.Printf("NewCerebrasProvider created: model=%s", provider.model) // Add log
+	provider.override = ProviderOverrideFor("cerebras")
+	for k, v := range provider.override.headers() {
+		provider.extraHeaders[k] = v
+	}
+	logger.Info("Provider created", "provider", "cerebras", logging.Model(provider.model), "endpoint", provider.Endpoint())
 	return provider
 }
 
@@ -221,9 +227,7 @@ func (p *CerebrasProvider) Name() string {
 
 // Endpoint returns the API endpoint URL.
 func (p *CerebrasProvider) Endpoint() string {
-	// Define CerebrasAPIURL if not defined elsewhere
-	const CerebrasAPIURL = "https://api.cerebras.ai/v1/chat/completions"
-	return CerebrasAPIURL
+	return p.override.endpoint("https://api.cerebras.ai/v1", "/chat/completions")
 }
 
 // Headers returns the necessary HTTP headers.
@@ -281,7 +285,7 @@ func convertToolsToCerebras(gollmTools []utils.Tool) ([]CerebrasTool, error) {
 	apiTools := make([]CerebrasTool, 0, len(gollmTools))
 	for _, tool := range gollmTools {
 		if tool.Type != "function" {
-			log.Printf("Warning: Skipping non-function tool type '%s'", tool.Type)
+			logger.Warn("Skipping non-function tool type", "provider", "cerebras", "type", tool.Type)
 			continue
 		}
 
@@ -297,7 +301,7 @@ func convertToolsToCerebras(gollmTools []utils.Tool) ([]CerebrasTool, error) {
 			if schema := reflector.Reflect(tool.Function.Parameters); schema != nil {
 				paramsSchema = schema
 			} else {
-				log.Printf("Warning: Failed to reflect jsonschema from tool parameters")
+				logger.Warn("Failed to reflect jsonschema from tool parameters", "provider", "cerebras")
 			}
 		}
 
@@ -330,7 +334,7 @@ func getCerebrasToolChoice(gollmChoice interface{}) interface{} {
 			return choiceLower // Return standard keywords as string
 		}
 		// If it's another string, assume it's a function name for specific choice
-		log.Printf("Interpreting tool_choice string '%s' as specific function request.", choiceStr)
+		logger.Info("Interpreting tool_choice string as a specific function request", "provider", "cerebras", "tool_choice", choiceStr)
 		return map[string]interface{}{ // Return the required struct
 			"type": "function",
 			"function": map[string]string{
@@ -348,7 +352,7 @@ func getCerebrasToolChoice(gollmChoice interface{}) interface{} {
 			}
 		}
 	}
-	log.Printf("Warning: Unsupported tool_choice type: %T. Defaulting to 'auto'.", gollmChoice)
+	logger.Warn("Unsupported tool_choice type, defaulting to auto", "provider", "cerebras", "type", fmt.Sprintf("%T", gollmChoice))
 	return "auto" // Fallback to auto
 }
 
@@ -432,7 +436,7 @@ func (p *CerebrasProvider) PrepareRequest(prompt string, options map[string]inte
 			// If gollm passes the map, we might need to adjust how ToolChoice is defined in ChatCompletionRequest
 			// For now, let's try assigning the string representation if it's a map, might fail.
 			// Safest might be to only support string choices here until clarified.
-			log.Printf("Warning: Specific tool_choice map provided, assigning 'auto' for now.")
+			logger.Warn("Specific tool_choice map provided, assigning auto for now", "provider", "cerebras")
 			req.ToolChoice = "auto"
 		} else {
 			// Assign string default if type is unexpected
@@ -577,7 +581,7 @@ func (p *CerebrasProvider) PrepareRequestWithMessages(messages []gollm_types.Mem
 			req.ToolChoice = choiceStr
 		} else {
 			// Handle map case - assuming string for now based on ambiguity
-			log.Printf("Warning: Specific tool_choice map provided, assigning 'auto' for now.")
+			logger.Warn("Specific tool_choice map provided, assigning auto for now", "provider", "cerebras")
 			req.ToolChoice = "auto"
 		}
 	}
@@ -987,11 +991,11 @@ func (c *CerebrasClient) MakeChatCompletionRequest(ctx context.Context, apiKey,
 	if err != nil {
 		// Check for context cancellation
 		if errors.Is(err, context.Canceled) {
-			log.Println("Cerebras request cancelled.")
+			logger.Info("Request cancelled", "provider", "cerebras")
 			return "", err
 		}
 		if errors.Is(err, context.DeadlineExceeded) {
-			log.Println("Cerebras request timed out.")
+			logger.Warn("Request timed out", "provider", "cerebras")
 			return "", err
 		}
 		return "", fmt.Errorf("failed to send request to Cerebras API: %w", err)
@@ -1006,7 +1010,7 @@ func (c *CerebrasClient) MakeChatCompletionRequest(ctx context.Context, apiKey,
 
 	// Check for non-OK status code
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Cerebras API Error Response Body: %s", string(body))
+		logger.Error("API error response", "provider", "cerebras", "status", resp.StatusCode, "body", string(body))
 		return "", fmt.Errorf("Cerebras API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -1018,7 +1022,7 @@ func (c *CerebrasClient) MakeChatCompletionRequest(ctx context.Context, apiKey,
 
 	// Check if there are any choices
 	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
-		log.Printf("Cerebras response body with no choices: %s", string(body))
+		logger.Warn("Response has no choices", "provider", "cerebras", "body", string(body))
 		return "", errors.New("no response choices or empty content returned from Cerebras")
 	}
 
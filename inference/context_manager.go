@@ -4,15 +4,39 @@ package inference
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 	"sync"
 	"time" // Import time package
 
+	"Inference_Engine/crash"
+
 
 )
 
+// minRetryChunkTokens is the smallest piece a chunk rejected as too long is
+// split into before giving up.
+const minRetryChunkTokens = 50
+
+// contextLengthPatterns are the error texts providers use for prompts over
+// the model's context length.
+var contextLengthPatterns = []string{"context_length_exceeded", "token limit", "maximum context length", "context window", "too many tokens", "prompt is too long"}
+
+// IsContextLengthError reports whether err says the prompt was too long for
+// the model.
+func IsContextLengthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	text := strings.ToLower(err.Error())
+	for _, pattern := range contextLengthPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // ChunkingStrategy defines how to split the text.
 type ChunkingStrategy int
 
@@ -44,6 +68,9 @@ type ContextManager struct {
 	chunkOverlap       int              // Number of tokens to overlap between chunks
 	modelName          string           // Model name for token estimation
 	contextTokenBudget int              // Max tokens for summary context in sequential mode
+	chunkLLM           TextGenerator    // Processes the chunks instead of the LLM passed in, if set
+	reduceInstruction  string           // Combines the chunk results in one more request, if set
+	chunkListener      func(index, total int, result string) // Sees each result as it finishes in sequential mode
 }
 
 // ContextManagerOption defines a functional option for configuring ContextManager.
@@ -84,11 +111,37 @@ func WithContextTokenBudget(budget int) ContextManagerOption {
 	}
 }
 
+// WithChunkGenerator sends the chunks to llm instead of the TextGenerator
+// passed to ProcessLargePrompt, so a cheaper model can do the per-chunk work
+// while the one passed in does the final reduce.
+func WithChunkGenerator(llm TextGenerator) ContextManagerOption {
+	return func(cm *ContextManager) {
+		cm.chunkLLM = llm
+	}
+}
+
+// WithReduceInstruction combines the chunk results with one more request to
+// the TextGenerator passed to ProcessLargePrompt, using instruction, instead
+// of returning them joined.
+func WithReduceInstruction(instruction string) ContextManagerOption {
+	return func(cm *ContextManager) {
+		cm.reduceInstruction = instruction
+	}
+}
+
+// WithChunkListener calls listener with each chunk's result (index counts
+// from 1) as soon as it finishes in sequential mode, so callers can show the
+// output before the whole prompt is processed. Parallel mode doesn't call it.
+func WithChunkListener(listener func(index, total int, result string)) ContextManagerOption {
+	return func(cm *ContextManager) {
+		cm.chunkListener = listener
+	}
+}
+
 // TextGenerator defines the minimal interface needed for generating text
 // This allows passing different LLM instances (like those from gollm).
 type TextGenerator interface {
-	GenerateText(prompt string) (string, error)
-	// Add other methods if needed by the context manager, e.g., Generate(ctx, prompt)
+	GenerateText(ctx context.Context, prompt string) (string, error)
 }
 
 // NewContextManager creates a new ContextManager with the given options.
@@ -160,7 +213,7 @@ func (cm *ContextManager) splitIntoChunks(text string) []string {
 		return cm.splitByTokenCount(text)
 
 	default:
-		log.Printf("[WARN] Unknown chunking strategy: %d. Falling back to paragraph.", cm.strategy)
+		logger.Warn("Unknown chunking strategy, falling back to paragraph", "strategy", cm.strategy)
 		// Set to ChunkByParagraph and retry
 		cm.strategy = ChunkByParagraph
 		return cm.splitIntoChunks(text) // Recursive call with default strategy
@@ -259,6 +312,12 @@ func (cm *ContextManager) splitByTokenCount(text string) []string {
 					currentSentenceTokens = 0
 				}
 
+				// A single sentence over the limit is split on word boundaries
+				if sentenceTokens > cm.maxChunkSize {
+					chunks = append(chunks, cm.splitByWords(sentenceTrimmed)...)
+					continue
+				}
+
 				// Add the sentence to the current chunk
 				if currentSentenceTokens > 0 {
 					currentSentenceChunk.WriteString(" ")
@@ -299,6 +358,26 @@ func (cm *ContextManager) splitByTokenCount(text string) []string {
 	return chunks
 }
 
+// splitByWords splits text that has no sentence boundaries within the limit
+// into chunks of whole words, each at most maxChunkSize tokens (unless a
+// single word is larger).
+func (cm *ContextManager) splitByWords(text string) []string {
+	var chunks []string
+	var current []string
+	for _, word := range strings.Fields(text) {
+		candidate := strings.Join(append(current, word), " ")
+		if len(current) > 0 && estimateTokens(candidate, cm.modelName) > cm.maxChunkSize {
+			chunks = append(chunks, strings.Join(current, " "))
+			current = current[:0]
+		}
+		current = append(current, word)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks
+}
+
 // ProcessLargePrompt chunks the input, processes each chunk via the provided LLM,
 // and reassembles the results.
 // Accepts the TextGenerator (LLM instance) to use for processing.
@@ -312,22 +391,41 @@ func (cm *ContextManager) ProcessLargePrompt(ctx context.Context, llm TextGenera
 		return "", fmt.Errorf("prompt resulted in zero chunks")
 	}
 
-	log.Printf("ContextManager: Processing %d chunks using %s mode...",
-		len(chunks),
-		func() string {
-			if cm.processingMode == ParallelProcessing {
-				return "parallel"
-			}
-			return "sequential"
-		}())
+	mode := "parallel"
+	if cm.processingMode == SequentialProcessing {
+		mode = "sequential"
+	}
+	logger.Info("ContextManager: processing chunks", "chunks", len(chunks), "mode", mode, "chunk_generator", cm.chunkLLM != nil, "reduce", cm.reduceInstruction != "")
+
+	chunkLLM := llm
+	if cm.chunkLLM != nil {
+		chunkLLM = cm.chunkLLM
+	}
 
 	// Choose processing method based on mode
+	var result string
+	var err error
 	if cm.processingMode == SequentialProcessing {
-		return cm.processSequentially(ctx, llm, chunks, instructionPerChunk)
+		result, err = cm.processSequentially(ctx, chunkLLM, chunks, instructionPerChunk)
+	} else {
+		// Default to parallel processing
+		result, err = cm.processInParallel(ctx, chunkLLM, chunks, instructionPerChunk)
+	}
+	if err != nil || cm.reduceInstruction == "" {
+		return result, err
 	}
+	return cm.reduce(ctx, llm, result)
+}
 
-	// Default to parallel processing
-	return cm.processInParallel(ctx, llm, chunks, instructionPerChunk)
+// reduce combines the joined chunk results with one request to llm.
+func (cm *ContextManager) reduce(ctx context.Context, llm TextGenerator, results string) (string, error) {
+	logger.Info("ContextManager: combining chunk results", "tokens", estimateTokens(results, cm.modelName))
+	reducePrompt := fmt.Sprintf("%s\n\n---\n%s\n---", cm.reduceInstruction, results)
+	combined, err := llm.GenerateText(ctx, reducePrompt)
+	if err != nil {
+		return results, fmt.Errorf("error combining chunk results: %w", err)
+	}
+	return combined, nil
 }
 
 // processInParallel processes chunks in parallel for speed.
@@ -342,22 +440,25 @@ func (cm *ContextManager) processInParallel(ctx context.Context, llm TextGenerat
 		wg.Add(1)
 		go func(index int, chunkText string) {
 			defer wg.Done()
-			log.Printf("ContextManager: Processing chunk %d/%d in parallel...", index+1, len(chunks))
+			defer crash.Recover("ContextManager.processInParallel")
+			logger.Info("ContextManager: processing chunk in parallel", "chunk", index+1, "chunks", len(chunks))
 
 			// Construct prompt for this chunk
-			chunkPrompt := fmt.Sprintf("%s\n\n---\n%s\n---", instructionPerChunk, chunkText)
+			buildPrompt := func(text string) string {
+				return fmt.Sprintf("%s\n\n---\n%s\n---", instructionPerChunk, text)
+			}
 
-			result, err := llm.GenerateText(chunkPrompt) // Use the passed LLM
+			result, err := cm.generateChunk(ctx, llm, chunkText, buildPrompt) // Use the passed LLM
 			if err != nil {
 				errMutex.Lock()
 				lastError = fmt.Errorf("error processing chunk %d: %w", index+1, err)
 				errMutex.Unlock()
-				log.Printf("ContextManager: Error on chunk %d: %v", index+1, err)
+				logger.Error("ContextManager: error on chunk", "chunk", index+1, "error", err)
 				resultsArray[index] = fmt.Sprintf("[ERROR PROCESSING CHUNK %d]", index+1) // Placeholder
 				return
 			}
 			resultsArray[index] = result
-			log.Printf("ContextManager: Chunk %d processed.", index+1)
+			logger.Info("ContextManager: chunk processed", "chunk", index+1)
 		}(i, chunk)
 	}
 
@@ -366,120 +467,100 @@ func (cm *ContextManager) processInParallel(ctx context.Context, llm TextGenerat
 	// Reassemble results in order
 	finalResult := strings.Join(resultsArray, "\n\n---\n\n") // Join with a separator
 
-	log.Println("ContextManager: Finished processing all chunks in parallel.")
+	logger.Info("ContextManager: finished processing all chunks in parallel")
 	return finalResult, lastError
 }
 
-// processSequentially processes chunks in sequence, passing context between them.
-// Accepts the TextGenerator (LLM instance).
-
-// processSequentially processes chunks in sequence, passing context between them.
-// Accepts the TextGenerator (LLM instance).
+// processSequentially processes the chunks in order, passing a summary of
+// each result into the next prompt. It stops at the first error and returns
+// the results so far along with it.
 func (cm *ContextManager) processSequentially(ctx context.Context, llm TextGenerator, chunks []string, instructionPerChunk string) (string, error) {
-	// Instead of using pre-split chunks, we'll manage the text dynamically.
-	// Join the pre-split chunks back together for this approach.
-	// A better long-term solution might be to pass the raw text here.
-	remainingText := strings.Join(chunks, "\n\n") // Reconstruct (or pass original text)
-
 	var results []string
 	var previousOutputSummary string // Store summary of previous output
 
-	chunkIndex := 0
-
-	for remainingText != "" {
-		chunkIndex++
-		// Estimate tokens for the base instruction and current summary
-		instructionTokens := estimateTokens(instructionPerChunk, cm.modelName)
-		summaryTokens := estimateTokens(previousOutputSummary, cm.modelName)
-		contextTokens := instructionTokens + summaryTokens
-		var chunkPrompt string
-		var currentChunk string
-
-		// Calculate tokens used by instruction and summary
-		summaryTokens = estimateTokens(previousOutputSummary, cm.modelName) // Use =
-		contextTokens = instructionTokens + summaryTokens                   // Use =
-
-		contentBudget := cm.maxChunkSize - contextTokens - 50
-		if contentBudget <= 0 {
-			log.Printf("ContextManager: Warning - No token budget left for chunk %d content after context/instruction. Context Tokens: %d", chunkIndex, contextTokens)
-			// Handle this case - maybe skip chunk, return error, or try with minimal content?
-			// For now, let's try to take a very small chunk.
-			contentBudget = 50 // Arbitrary small budget
+	for i, currentChunk := range chunks {
+		chunkIndex := i + 1
+		if err := ctx.Err(); err != nil {
+			return strings.Join(results, "\n\n---\n\n"), fmt.Errorf("processing stopped before chunk %d: %w", chunkIndex, err)
 		}
-
-		// Extract the next chunk based on the budget
-		// Find the best split point (e.g., end of sentence or paragraph) within the budget
-		chunkEndIndex := findSplitIndex(remainingText, contentBudget, cm.modelName)
-		if chunkEndIndex <= 0 {
-			// If no good split point found within budget, or budget is too small, take the whole remaining text or up to budget limit
-			chunkEndIndex = min(len(remainingText), contentBudget*5) // Use a multiplier as budget is tokens, not chars
-			if chunkEndIndex == 0 && len(remainingText) > 0 {
-				// Force taking at least some characters if budget was effectively zero
-				chunkEndIndex = min(len(remainingText), 100)
+		logger.Info("ContextManager: processing chunk sequentially", "chunk", chunkIndex, "chunks", len(chunks))
+
+		// Construct the prompt for the current chunk; the chunk itself sits
+		// between the --- markers, as in parallel mode.
+		buildPrompt := func(text string) string {
+			promptBuilder := strings.Builder{}
+			promptBuilder.WriteString(instructionPerChunk)
+			if previousOutputSummary != "" {
+				promptBuilder.WriteString("\n\nContext from previous section:\n")
+				promptBuilder.WriteString(previousOutputSummary)
 			}
-			log.Printf("ContextManager: Using fallback split index %d for chunk %d", chunkEndIndex, chunkIndex)
-		}
-
-		currentChunk = strings.TrimSpace(remainingText[:chunkEndIndex])
-		remainingText = strings.TrimSpace(remainingText[chunkEndIndex:])
-
-
-		if currentChunk == "" && remainingText != "" {
-			log.Printf("ContextManager: Warning - Could not extract next chunk within budget for chunk %d.", chunkIndex)
-			results = append(results, fmt.Sprintf("[ERROR SKIPPING REST OF TEXT - CHUNK %d TOO SMALL FOR BUDGET]", chunkIndex))
-			break
+			promptBuilder.WriteString("\n\nCurrent section:\n---\n")
+			promptBuilder.WriteString(text)
+			promptBuilder.WriteString("\n---")
+			return promptBuilder.String()
 		}
+		logger.Debug("ContextManager: sequential prompt", "chunk", chunkIndex, "prompt", buildPrompt(currentChunk))
 
-		log.Printf("ContextManager: Processing chunk %d sequentially (Content Budget: %d tokens)...", chunkIndex, contentBudget)
-
-		// Construct the prompt for the current chunk
-		promptBuilder := strings.Builder{}
-		promptBuilder.WriteString(instructionPerChunk)
-		if previousOutputSummary != "" {
-			promptBuilder.WriteString("\n\nContext from previous section:\n")
-			promptBuilder.WriteString(previousOutputSummary)
-		}
-		promptBuilder.WriteString("\n\n---\nCurrent Section:\n")
-		promptBuilder.WriteString(currentChunk)
-		promptBuilder.WriteString("\n---")
-		chunkPrompt = promptBuilder.String()
-		// --- Add logging for the prompt being sent ---
-
-		log.Printf("ContextManager: Sequential Prompt for Chunk %d:\n%s\n", chunkIndex, chunkPrompt)
-		// --- End logging ---
-
-		result, err := llm.GenerateText(chunkPrompt) // Use the passed LLM
+		result, err := cm.generateChunk(ctx, llm, currentChunk, buildPrompt) // Use the passed LLM
 		if err != nil {
 			// If an error occurs, return the results obtained so far and the error
-
-			log.Printf("ContextManager: Error on chunk %d: %v", chunkIndex, err)
+			logger.Error("ContextManager: error on chunk", "chunk", chunkIndex, "error", err)
 			results = append(results, fmt.Sprintf("[ERROR PROCESSING CHUNK %d]", chunkIndex))
-			return strings.Join(results, "\n\n---\n\n"),
-
-				fmt.Errorf("error processing chunk %d: %w", chunkIndex, err)
+			return strings.Join(results, "\n\n---\n\n"), fmt.Errorf("error processing chunk %d: %w", chunkIndex, err)
 		}
 
 		results = append(results, result)
-		log.Printf("ContextManager: Chunk %d processed.", chunkIndex)
+		logger.Info("ContextManager: chunk processed", "chunk", chunkIndex)
+		if cm.chunkListener != nil {
+			cm.chunkListener(chunkIndex, len(chunks), result)
+		}
 
 		// Generate summary *after* getting the result
 		previousOutputSummary = cm.summarizeForContext(result, cm.contextTokenBudget)
-		log.Printf("ContextManager: Generated summary for next chunk context: %s", previousOutputSummary)
+		logger.Debug("ContextManager: generated summary for next chunk context", "summary", previousOutputSummary)
 
 		// --- Conditional Delay ---
-		if adapter, ok := llm.(*LLMAdapter); ok { // Check if it's our adapter
-			// Access the underlying gollm LLM and its provider
-			if adapter.ProviderName != "" { // Check if provider name is available
-				log.Printf("ContextManager: Adding 10s delay after chunk %d (Provider: %s)...", chunkIndex, adapter.ProviderName)
-				time.Sleep(10 * time.Second) // Apply delay
+		// Space out requests to remote providers to stay under their rate limits
+		if adapter, ok := llm.(*LLMAdapter); ok && adapter.ProviderName != "" && chunkIndex < len(chunks) {
+			logger.Info("ContextManager: adding delay after chunk", "chunk", chunkIndex, "provider", adapter.ProviderName, "delay", "10s")
+			select {
+			case <-time.After(10 * time.Second):
+			case <-ctx.Done():
 			}
 		}
-		// --- END Conditional Delay ---
-	} // End of loop through remainingText
+	}
 	return strings.Join(results, "\n\n---\n\n"), nil
 }
 
-// Reassemble results in order
+// generateChunk sends one chunk to llm in the prompt buildPrompt makes for
+// it. If the model rejects the prompt as too long, the chunk is split by
+// tokens into pieces half its size, each retried the same way, and their
+// results are joined; pieces below minRetryChunkTokens are not split again.
+func (cm *ContextManager) generateChunk(ctx context.Context, llm TextGenerator, chunk string, buildPrompt func(string) string) (string, error) {
+	result, err := llm.GenerateText(ctx, buildPrompt(chunk))
+	if err == nil || !IsContextLengthError(err) || ctx.Err() != nil {
+		return result, err
+	}
+	tokens := estimateTokens(chunk, cm.modelName)
+	if tokens/2 < minRetryChunkTokens {
+		return "", err
+	}
+	splitter := &ContextManager{strategy: ChunkByTokenCount, maxChunkSize: tokens / 2, modelName: cm.modelName}
+	pieces := splitter.splitIntoChunks(chunk)
+	if len(pieces) < 2 {
+		return "", err
+	}
+	logger.Warn("ContextManager: chunk too long for the model, retrying in smaller pieces", "tokens", tokens, "pieces", len(pieces), "error", err)
+	results := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		pieceResult, err := cm.generateChunk(ctx, llm, piece, buildPrompt)
+		if err != nil {
+			return "", err
+		}
+		results = append(results, pieceResult)
+	}
+	return strings.Join(results, "\n\n"), nil
+}
 
 // summarizeForContext creates a short summary of the text for context passing.
 // It aims to stay within the provided token budget.
@@ -535,48 +616,6 @@ func (cm *ContextManager) summarizeForContext(text string, budget int) string {
 	return strings.Join(summarySentences, " ")
 }
 
-// findSplitIndex finds a suitable index to split the text within the token budget.
-// It prioritizes paragraph breaks, then sentence breaks.
-func findSplitIndex(text string, tokenBudget int, modelName string) int {
-	if estimateTokens(text, modelName) <= tokenBudget {
-		return len(text) // Whole text fits
-	}
-
-	bestSplit := -1
-	currentTokens := 0
-
-	// Iterate through characters, estimating tokens and looking for good split points
-	// This is approximate; a more robust method would use actual tokenization.
-	for i := 0; i < len(text); i++ {
-		// Estimate tokens incrementally (very rough)
-		if i%4 == 0 { // Estimate 1 token every 4 chars
-			currentTokens++
-		}
-
-		if currentTokens > tokenBudget {
-			// We've exceeded the budget, return the last good split point found
-			if bestSplit > 0 {
-				return bestSplit
-			}
-			// If no good split found, return the index just before exceeding budget
-			return max(0, i-1) // Ensure non-negative index
-		}
-
-		// Check for preferred split points (double newline, then sentence end)
-		if i > 0 && text[i] == '\n' && text[i-1] == '\n' {
-			bestSplit = i + 1 // Split after double newline
-		} else if bestSplit == -1 && (text[i] == '.' || text[i] == '!' || text[i] == '?') {
-			// If no paragraph break found yet, consider sentence end
-			if i+1 < len(text) && (text[i+1] == ' ' || text[i+1] == '\n') {
-				bestSplit = i + 1 // Split after punctuation and space/newline
-			}
-		}
-	}
-
-	// If loop finishes, the whole text fits (should have been caught earlier)
-	return len(text)
-}
-
 // ProcessLargePromptWithStrategy processes a large prompt with a specific chunking strategy,
 // overriding the default strategy for this call only.
 func (cm *ContextManager) ProcessLargePromptWithStrategy(
@@ -625,7 +664,7 @@ func (cm *ContextManager) GetChunkingStrategy() ChunkingStrategy {
 // SetChunkingStrategy sets a new chunking strategy.
 func (cm *ContextManager) SetChunkingStrategy(strategy ChunkingStrategy) {
 	cm.strategy = strategy
-	log.Printf("ContextManager: Chunking strategy set to %d", strategy)
+	logger.Info("ContextManager: chunking strategy set", "strategy", strategy)
 }
 
 // GetProcessingMode returns the current processing mode.
@@ -636,38 +675,22 @@ func (cm *ContextManager) GetProcessingMode() ProcessingMode {
 // SetProcessingMode sets a new processing mode.
 func (cm *ContextManager) SetProcessingMode(mode ProcessingMode) {
 	cm.processingMode = mode
-	log.Printf("ContextManager: Processing mode set to %d", mode)
+	logger.Info("ContextManager: processing mode set", "mode", mode)
 }
 
 // SetMaxChunkSize sets the maximum chunk size in tokens.
 func (cm *ContextManager) SetMaxChunkSize(size int) {
 	cm.maxChunkSize = size
-	log.Printf("ContextManager: Max chunk size set to %d tokens", size)
+	logger.Info("ContextManager: max chunk size set", "tokens", size)
 }
 
 // SetChunkOverlap sets the overlap between chunks in tokens.
 func (cm *ContextManager) SetChunkOverlap(overlap int) {
 	cm.chunkOverlap = overlap
-	log.Printf("ContextManager: Chunk overlap set to %d tokens", overlap)
+	logger.Info("ContextManager: chunk overlap set", "tokens", overlap)
 }
 
 // Deprecated: LLM is now passed during processing.
 // func (cm *ContextManager) GetInferenceService() TextGenerator {
 // 	return cm.inferenceService
 // }
-
-// Helper min function
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// Helper max function
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
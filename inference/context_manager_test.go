@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ type MockTextGenerator struct {
 }
 
 // GenerateText implements the TextGenerator interface for testing
-func (m *MockTextGenerator) GenerateText(prompt string) (string, error) {
+func (m *MockTextGenerator) GenerateText(ctx context.Context, prompt string) (string, error) {
 	if m.generateFunc != nil {
 		return m.generateFunc(prompt)
 	}
@@ -154,7 +155,7 @@ func TestProcessLargePromptWithError(t *testing.T) {
 			chunk := strings.TrimSpace(parts[1])
 			
 			// Return an error for Chunk 2
-			if chunk == "Chunk 2" {
+			if chunk == "Chunk 2." {
 				return "", fmt.Errorf("simulated error for Chunk 2")
 			}
 			
@@ -233,4 +234,119 @@ func TestOverrideMethodsForStrategyAndMode(t *testing.T) {
 	if cm.processingMode != ParallelProcessing {
 		t.Errorf("ProcessingMode was not restored after ProcessLargePromptWithMode, got %v", cm.processingMode)
 	}
-}
\ No newline at end of file
+}
+func TestCondenseSources(t *testing.T) {
+	var prompts []string
+	var mu sync.Mutex
+	llm := &MockTextGenerator{generateFunc: func(prompt string) (string, error) {
+		mu.Lock()
+		prompts = append(prompts, prompt)
+		mu.Unlock()
+		if strings.Contains(prompt, "Unrelated") {
+			return "No relevant content.", nil
+		}
+		parts := strings.Split(prompt, "---")
+		return "- " + strings.TrimSpace(parts[len(parts)-2]), nil
+	}}
+	sources := "Source Title: Espresso\nEspresso is brewed at 9 bar.\n\nUnrelated section about parking.\n\nCrema forms from CO2."
+	notes, err := condenseSources(context.Background(), llm, nil, sources, "An article about espresso", ChunkingOptions{Strategy: ChunkByParagraph, Mode: SequentialProcessing})
+	if err != nil {
+		t.Fatalf("condenseSources failed: %v", err)
+	}
+	if len(prompts) != 3 || !strings.Contains(prompts[0], "An article about espresso") {
+		t.Fatalf("Expected one notes request per paragraph with the request, got %q", prompts)
+	}
+	want := "- Source Title: Espresso\nEspresso is brewed at 9 bar.\n\n- Crema forms from CO2."
+	if notes != want {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+
+	// Sequential notes are reported as each chunk finishes
+	var streamed []string
+	chunking := ChunkingOptions{Strategy: ChunkByParagraph, Mode: SequentialProcessing, OnNotes: func(index, total int, notes string) {
+		if total != 3 || index != len(streamed)+1 {
+			t.Errorf("Unexpected progress %d of %d", index, total)
+		}
+		streamed = append(streamed, notes)
+	}}
+	if _, err := condenseSources(context.Background(), llm, nil, sources, "An article about espresso", chunking); err != nil {
+		t.Fatalf("condenseSources failed: %v", err)
+	}
+	if len(streamed) != 3 || streamed[1] != "" || streamed[2] != "- Crema forms from CO2." {
+		t.Errorf("Unexpected streamed notes %q", streamed)
+	}
+
+	llm.generateFunc = func(string) (string, error) { return "No relevant content.", nil }
+	if _, err := condenseSources(context.Background(), llm, nil, sources, "Anything", DefaultChunkingOptions); err == nil {
+		t.Error("Expected an error when no chunk is relevant")
+	}
+}
+
+func TestChunkGeneratorAndReduce(t *testing.T) {
+	var mapped, reduced []string
+	var mu sync.Mutex
+	cheap := &MockTextGenerator{generateFunc: func(prompt string) (string, error) {
+		mu.Lock()
+		mapped = append(mapped, prompt)
+		mu.Unlock()
+		parts := strings.Split(prompt, "---")
+		return "note on " + strings.TrimSpace(parts[len(parts)-2]), nil
+	}}
+	strong := &MockTextGenerator{generateFunc: func(prompt string) (string, error) {
+		reduced = append(reduced, prompt)
+		return "combined", nil
+	}}
+	cm := NewContextManager(ChunkByParagraph, WithProcessingMode(SequentialProcessing),
+		WithChunkGenerator(cheap), WithReduceInstruction("Combine the notes:"))
+	result, err := cm.ProcessLargePrompt(context.Background(), strong, "One.\n\nTwo.", "Take notes:")
+	if err != nil {
+		t.Fatalf("ProcessLargePrompt failed: %v", err)
+	}
+	if len(mapped) != 2 || result != "combined" || len(reduced) != 1 {
+		t.Fatalf("Expected 2 chunk requests and 1 reduce, got %d and %d (result %q)", len(mapped), len(reduced), result)
+	}
+	if !strings.HasPrefix(reduced[0], "Combine the notes:") || !strings.Contains(reduced[0], "note on One.") || !strings.Contains(reduced[0], "note on Two.") {
+		t.Errorf("Unexpected reduce prompt %q", reduced[0])
+	}
+
+	// Without the options, the LLM passed in does the chunks and nothing is combined
+	mapped, reduced = nil, nil
+	result, err = NewContextManager(ChunkByParagraph).ProcessLargePrompt(context.Background(), cheap, "One.\n\nTwo.", "Take notes:")
+	if err != nil || len(mapped) != 2 || len(reduced) != 0 || !strings.Contains(result, "---") {
+		t.Errorf("Unexpected plain result %q (err %v, %d chunk requests, %d reduces)", result, err, len(mapped), len(reduced))
+	}
+}
+
+func TestRetryWithSmallerChunks(t *testing.T) {
+	const limit = 90 // Tokens the mock model accepts per prompt
+	var mu sync.Mutex
+	attempts := 0
+	llm := &MockTextGenerator{generateFunc: func(prompt string) (string, error) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		if EstimateTokenCount(prompt) > limit {
+			return "", fmt.Errorf("API error: context_length_exceeded")
+		}
+		return "ok", nil
+	}}
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 12)
+	for _, mode := range []ProcessingMode{ParallelProcessing, SequentialProcessing} {
+		attempts = 0
+		cm := NewContextManager(ChunkByParagraph, WithProcessingMode(mode))
+		result, err := cm.ProcessLargePrompt(context.Background(), llm, text, "Summarize:")
+		if err != nil {
+			t.Fatalf("mode %d: expected the chunk to be retried in smaller pieces, got %v", mode, err)
+		}
+		if strings.Contains(result, "ERROR") || !strings.Contains(result, "ok\n\nok") || attempts < 3 {
+			t.Errorf("mode %d: unexpected result %q after %d attempts", mode, result, attempts)
+		}
+	}
+
+	// Other errors are not retried
+	attempts = 0
+	llm.generateFunc = func(string) (string, error) { attempts++; return "", fmt.Errorf("invalid API key") }
+	if _, err := NewContextManager(ChunkByParagraph).ProcessLargePrompt(context.Background(), llm, text, "Summarize:"); err == nil || attempts != 1 {
+		t.Errorf("Expected one failed attempt, got %d (err %v)", attempts, err)
+	}
+}
@@ -1,12 +1,18 @@
 package inference
 
 import (
-	"log"
 	"sync"
 
 	gollm_types "github.com/teilomillet/gollm/types"
 )
 
+// ConversationMessage is one message of the conversation history, with the
+// role "user" or "assistant".
+type ConversationMessage struct {
+	Role    string
+	Content string
+}
+
 // ConversationMemory defines the interface for managing conversation history.
 type ConversationMemory interface {
 	// AddMessage adds a message to the conversation history.
@@ -41,7 +47,7 @@ func (m *SimpleWindowMemory) AddMessage(message gollm_types.MemoryMessage) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.messages = append(m.messages, message)
-	log.Printf("SimpleWindowMemory: Added message (Role: %s). Total messages: %d", message.Role, len(m.messages))
+	logger.Debug("SimpleWindowMemory: added message", "role", message.Role, "messages", len(m.messages))
 }
 
 // GetMessagesForContext returns the most recent messages that fit within maxTokens.
@@ -68,7 +74,7 @@ func (m *SimpleWindowMemory) GetMessagesForContext(maxTokens int, modelName stri
 			currentTokens += msgTokens
 		} else {
 			// Stop if adding the next message exceeds the token limit
-			log.Printf("SimpleWindowMemory: Token limit (%d) reached. Returning %d messages (%d tokens).", maxTokens, len(contextMessages), currentTokens)
+			logger.Info("SimpleWindowMemory: token limit reached", "limit", maxTokens, "messages", len(contextMessages), "tokens", currentTokens)
 			break
 		}
 	}
@@ -80,7 +86,7 @@ func (m *SimpleWindowMemory) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.messages = make([]gollm_types.MemoryMessage, 0)
-	log.Println("SimpleWindowMemory: History cleared.")
+	logger.Info("SimpleWindowMemory: history cleared")
 }
 
 // GetHistory returns a copy of all messages.
@@ -0,0 +1,109 @@
+package inference
+
+import "fmt"
+
+// moaIterations is the number of rounds the MOA agents run before the
+// aggregator combines their answers.
+const moaIterations = 2
+
+// Price is what a model costs, in US dollars per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// listPrices are the providers' published prices for the default models.
+// Models missing here have no known price.
+var listPrices = map[string]Price{
+	"cerebras/llama-4-scout-17b-16e-instruct": {Input: 0.65, Output: 0.85},
+	"gemini/gemini-1.5-flash-latest":          {Input: 0.075, Output: 0.30},
+	"deepseek/deepseek-chat":                  {Input: 0.27, Output: 1.10},
+}
+
+// PriceOf returns the price of a model, free when its provider runs on this
+// machine, and whether the price is known.
+func PriceOf(conf LLMAttemptConfig) (Price, bool) {
+	if isLocal(conf.ProviderName) {
+		return Price{}, true
+	}
+	price, ok := listPrices[conf.ID()]
+	return price, ok
+}
+
+// CostEstimate is the expected spend of one generation, before it runs.
+type CostEstimate struct {
+	Calls        int // Provider requests, more than one for MOA
+	InputTokens  int
+	OutputTokens int
+	Cost         float64  // US dollars, for the priced models
+	Unpriced     []string // Models with no known price, left out of Cost
+}
+
+// add counts one provider request.
+func (e *CostEstimate) add(conf LLMAttemptConfig, input, output int) {
+	e.Calls++
+	e.InputTokens += input
+	e.OutputTokens += output
+	price, ok := PriceOf(conf)
+	if !ok {
+		for _, id := range e.Unpriced {
+			if id == conf.ID() {
+				return
+			}
+		}
+		e.Unpriced = append(e.Unpriced, conf.ID())
+		return
+	}
+	e.Cost += (float64(input)*price.Input + float64(output)*price.Output) / 1e6
+}
+
+// EstimateCost estimates the tokens and cost of generating from prompt with
+// opts, when the answer is expected to be about expectedOutput tokens long.
+// MOA runs every agent each iteration, later iterations reading the earlier
+// answers, then the aggregator, so they cost several times a single model;
+// with the judge, the agents answer once and the judge reads every answer.
+// Retries, fallbacks and chunking aren't counted.
+func (s *InferenceService) EstimateCost(prompt string, opts GenerateOptions, expectedOutput int) (CostEstimate, error) {
+	input := EstimateTokenCount(withInstruction(opts.Instruction, prompt))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var estimate CostEstimate
+	if opts.UseMOA {
+		primary, fallback := s.findAttempt("", s.moaPrimaryModelName), s.findAttempt("", s.moaFallbackModelName)
+		if s.moa == nil || primary == nil || fallback == nil {
+			return CostEstimate{}, fmt.Errorf("MOA (Mixture of Agents) is not configured")
+		}
+		agents := []LLMAttemptConfig{primary.Config, fallback.Config}
+		if PrefMOAJudge.Get() {
+			for _, agent := range agents {
+				estimate.add(agent, input, expectedOutput)
+			}
+			estimate.add(fallback.Config, input+len(agents)*expectedOutput, expectedOutput) // The judge
+			return estimate, nil
+		}
+		for i := 0; i < moaIterations; i++ {
+			agentInput := input
+			if i > 0 {
+				agentInput += len(agents) * expectedOutput // The previous round's answers
+			}
+			for _, agent := range agents {
+				estimate.add(agent, agentInput, expectedOutput)
+			}
+		}
+		estimate.add(fallback.Config, input+len(agents)*expectedOutput, expectedOutput) // The aggregator
+		return estimate, nil
+	}
+	var attempt *LLMAttempt
+	if opts.Provider != "" || opts.Model != "" {
+		attempt = s.findAttempt(opts.Provider, opts.Model)
+	} else if primary, fallback := routeForTask(opts.Task, s.primaryAttempts, s.fallbackAttempts); len(primary) > 0 {
+		attempt = &primary[0]
+	} else if len(fallback) > 0 {
+		attempt = &fallback[0]
+	}
+	if attempt == nil {
+		return CostEstimate{}, fmt.Errorf("no configured model for %q", opts.Model)
+	}
+	estimate.add(attempt.Config, input, expectedOutput)
+	return estimate, nil
+}
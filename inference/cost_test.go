@@ -0,0 +1,57 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"Inference_Engine/settings"
+
+	"github.com/teilomillet/gollm"
+)
+
+func TestEstimateCost(t *testing.T) {
+	t.Setenv("GEMINI_BASE_URL", "http://localhost:8080/v1beta/")
+	s := NewInferenceService()
+	s.primaryAttempts = []LLMAttempt{{Config: defaultModelConfigs[0]}, {Config: LLMAttemptConfig{ProviderName: "custom", ModelName: "mystery"}}}
+	s.fallbackAttempts = []LLMAttempt{{Config: defaultModelConfigs[1]}, {Config: defaultModelConfigs[2]}}
+
+	// An empty prompt leaves only the expected output, so the counts are exact
+	tests := []struct {
+		name     string
+		opts     GenerateOptions
+		calls    int
+		input    int
+		cost     float64
+		unpriced string
+	}{
+		{"single", GenerateOptions{Model: "llama-4-scout-17b-16e-instruct"}, 1, 0, 1000 * 0.85 / 1e6, "[]"},
+		{"local", GenerateOptions{Model: "gemini-1.5-flash-latest"}, 1, 0, 0, "[]"},
+		{"unpriced", GenerateOptions{Model: "custom/mystery"}, 1, 0, 0, "[custom/mystery]"},
+		// Two agents twice, the second round reading both first answers,
+		// then the aggregator reading the last two
+		{"moa", GenerateOptions{UseMOA: true}, 5, 6000, (2000*0.65 + 2000*0.85 + 4000*0.27 + 3000*1.10) / 1e6, "[]"},
+	}
+	s.moaPrimaryModelName = "llama-4-scout-17b-16e-instruct"
+	s.moaFallbackModelName = "deepseek-chat"
+	s.moa = &gollm.MOA{}
+	for _, tt := range tests {
+		estimate, err := s.EstimateCost("", tt.opts, 1000)
+		if err != nil {
+			t.Errorf("%s: EstimateCost failed: %v", tt.name, err)
+			continue
+		}
+		if estimate.Calls != tt.calls || estimate.InputTokens != tt.input || estimate.OutputTokens != tt.calls*1000 ||
+			math.Abs(estimate.Cost-tt.cost) > 1e-9 || fmt.Sprint(estimate.Unpriced) != tt.unpriced {
+			t.Errorf("%s: got %+v, want %d calls, %d input tokens, $%f, unpriced %s", tt.name, estimate, tt.calls, tt.input, tt.cost, tt.unpriced)
+		}
+	}
+	settings.Use(settings.New(nil))
+	PrefMOAJudge.Set(true) // The agents answer once, then the judge reads both answers
+	if estimate, err := s.EstimateCost("", GenerateOptions{UseMOA: true}, 1000); err != nil || estimate.Calls != 3 || estimate.InputTokens != 2000 {
+		t.Errorf("EstimateCost with the judge = %+v, %v, want 3 calls and 2000 input tokens", estimate, err)
+	}
+	if _, err := s.EstimateCost("", GenerateOptions{Model: "missing"}, 1000); err == nil {
+		t.Error("Expected an unknown model to fail")
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"sync"
 
+	"Inference_Engine/logging"
+
 	// Use official gollm imports
 	"github.com/teilomillet/gollm/config"
 	"github.com/teilomillet/gollm/providers"
@@ -60,6 +61,7 @@ type DeepseekProvider struct {
 	topP         *float64
 	seed         *int64 // Deepseek might support seed
 	extraHeaders map[string]string
+	override     ProviderOverride // Base URL and headers of a proxy or gateway, fixed at creation
 	logger       utils.Logger
 	client       *http.Client // Standard HTTP client
 
@@ -70,12 +72,12 @@ type DeepseekProvider struct {
 func init() {
 	registry := providers.GetDefaultRegistry()
 	registry.Register("deepseek", NewDeepseekProvider)
-	log.Println("Registered Deepseek provider constructor with gollm registry")
+	logger.Info("Registered provider constructor with gollm registry", "provider", "deepseek")
 }
 
 // NewDeepseekProvider creates a new Deepseek provider instance.
 func NewDeepseekProvider(apiKey, model string, extraHeaders map[string]string) providers.Provider {
-	log.Printf("[DEBUG] NewDeepseekProvider called! apiKey: %t, model: %s", apiKey != "", model)
+	logger.Debug("NewDeepseekProvider called", "has_api_key", apiKey != "", logging.Model(model))
 	provider := &DeepseekProvider{
 		apiKey:       apiKey,
 		model:        model,
@@ -87,7 +89,7 @@ func NewDeepseekProvider(apiKey, model string, extraHeaders map[string]string) p
 	// Set default model if provided one is empty
 	if provider.model == "" {
 		provider.model = "deepseek-chat" // Default Deepseek model
-		log.Printf("Deepseek model defaulting to %s", provider.model)
+		logger.Info("Model defaulted", "provider", "deepseek", logging.Model(provider.model))
 	}
 	// Copy provided extraHeaders
 	if extraHeaders != nil {
@@ -95,7 +97,11 @@ func NewDeepseekProvider(apiKey, model string, extraHeaders map[string]string) p
 			provider.extraHeaders[k] = v
 		}
 	}
-	log.Printf("NewDeepseekProvider created: model=%s", provider.model)
+	provider.override = ProviderOverrideFor("deepseek")
+	for k, v := range provider.override.headers() {
+		provider.extraHeaders[k] = v
+	}
+	logger.Info("Provider created", "provider", "deepseek", logging.Model(provider.model), "endpoint", provider.Endpoint())
 	return provider
 }
 
@@ -106,8 +112,8 @@ func (p *DeepseekProvider) Name() string {
 
 // Endpoint returns the API endpoint URL.
 func (p *DeepseekProvider) Endpoint() string {
-	// Official Deepseek API endpoint
-	return "https://api.deepseek.com/v1/chat/completions"
+	// Official Deepseek API endpoint, unless routed through a gateway
+	return p.override.endpoint("https://api.deepseek.com/v1", "/chat/completions")
 }
 
 // Headers returns the necessary HTTP headers.
@@ -222,7 +228,7 @@ func (p *DeepseekProvider) PrepareRequest(prompt string, options map[string]inte
 		if choiceStr, ok := choice.(string); ok {
 			req.ToolChoice = choiceStr
 		} else {
-			log.Printf("Warning: Unsupported tool_choice map provided for Deepseek, assigning 'auto'.")
+			logger.Warn("Unsupported tool_choice map provided, assigning auto", "provider", "deepseek")
 			req.ToolChoice = "auto"
 		}
 	}
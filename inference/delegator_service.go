@@ -5,13 +5,19 @@ import (
 	"context"
 	"errors" // Import the errors package
 	"fmt"
-	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"github.com/pkoukk/tiktoken-go"
 
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+	"Inference_Engine/tracing"
+
 	"github.com/teilomillet/gollm" // Import gollm for MOA type
 	"github.com/teilomillet/gollm/llm"
 	gollm_types "github.com/teilomillet/gollm/types" // Renamed import
+	"go.opentelemetry.io/otel/attribute"
 	// Add other necessary imports if message conversion or specific types are moved here
 )
 
@@ -27,20 +33,22 @@ type DelegatorService struct {
 	tokenLimitThreshold int    // Token limit to decide initial routing
 	tokenLimitCheckModel string // Model name used for token estimation against the limit
 	moa             *gollm.MOA // MOA instance
+
+	attemptsMu sync.RWMutex // Guards the attempt lists and moa, which model switching replaces
 }
 
 // NewDelegatorService creates a new delegator instance.
 // It requires lists of initialized LLM attempts, an optional MOA instance, and a ContextManager.
 func NewDelegatorService(primaryAttempts []LLMAttempt, fallbackAttempts []LLMAttempt, tokenLimit int, tokenModel string, moaInstance *gollm.MOA, ctxManager *ContextManager) *DelegatorService {
 	if len(primaryAttempts) == 0 || len(fallbackAttempts) == 0 {
-		log.Println("CRITICAL: NewDelegatorService called with empty primary or fallback attempts")
+		logger.Error("NewDelegatorService called with empty primary or fallback attempts")
 		return nil
 	}
 	if moaInstance == nil {
-		log.Println("[WARN] NewDelegatorService: MOA instance is nil. MOA features will be disabled.")
+		logger.Warn("NewDelegatorService: MOA instance is nil, MOA features will be disabled")
 	}
 	if ctxManager == nil {
-		log.Println("[WARN] NewDelegatorService: ContextManager instance is nil. Chunking fallback will be disabled.")
+		logger.Warn("NewDelegatorService: ContextManager instance is nil, chunking fallback will be disabled")
 	}
 	return &DelegatorService{
 		primaryAttempts:  primaryAttempts,
@@ -71,7 +79,7 @@ func getEncodingForModel(model string) (*tiktoken.Tiktoken, error) {
 		// Try default model encoding as a fallback before giving up
 		enc, err := tiktoken.GetEncoding("cl100k_base") // Common encoding
 		if err == nil {
-			log.Printf("Warning: Unsupported model '%s' for token estimation, using cl100k_base encoding.", model)
+			logger.Warn("Unsupported model for token estimation, using cl100k_base encoding", logging.Model(model))
 			return enc, nil
 		}
 		return nil, fmt.Errorf("unsupported model and failed to get default encoding: %s", model)
@@ -88,10 +96,40 @@ func estimateTokens(content string, model string) int {
 	}
 
 	// Fallback for unknown models: rough estimate (1 token ~ 4 chars)
-	log.Printf("Warning: Using character-based fallback for token estimation (model: %s)", model)
+	logger.Warn("Using character-based fallback for token estimation", logging.Model(model))
 	return (len(content) / 4) + 5
 }
 
+var (
+	liveCountEncoding     atomic.Pointer[tiktoken.Tiktoken]
+	liveCountEncodingOnce sync.Once
+)
+
+// EstimateTokenCount gives a quick token estimate for display purposes (e.g. live
+// counters while typing). The cl100k_base encoding is loaded once in the background
+// (it may need a download); until it is ready, or if loading fails, the
+// 4-chars-per-token heuristic is used, so callers on the UI thread never block.
+func EstimateTokenCount(content string) int {
+	if content == "" {
+		return 0
+	}
+	liveCountEncodingOnce.Do(func() {
+		go func() {
+			defer crash.Recover("EstimateTokenCount")
+			enc, err := tiktoken.GetEncoding("cl100k_base")
+			if err != nil {
+				logger.Warn("Token counter: cl100k_base encoding unavailable, using character estimate", "error", err)
+				return
+			}
+			liveCountEncoding.Store(enc)
+		}()
+	})
+	if enc := liveCountEncoding.Load(); enc != nil {
+		return len(enc.Encode(content, nil, nil))
+	}
+	return (len(content) + 3) / 4
+}
+
 // estimateTotalTokens estimates tokens for a slice of messages.
 func estimateTotalTokens(messages []gollm_types.MemoryMessage, model string) int {
 	total := 0
@@ -116,63 +154,50 @@ func formatMessagesToPrompt(messages []gollm_types.MemoryMessage) string {
 	return strings.TrimSuffix(builder.String(), "\n")
 }
 
-// shouldRetryWithError determines if the given error warrants a fallback attempt to the base LLM.
-// Customize this logic based on the errors observed from the primary LLM (Cerebras).
-func (d *DelegatorService) shouldFallbackOnError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	log.Printf("DelegatorService: Evaluating error for fallback: %s", errStr)
-
-	// Allow Fallback on context length exceeded
-	if strings.Contains(errStr, "context_length_exceeded") || strings.Contains(errStr, "token limit") {
-		log.Println("DelegatorService: Decision: Allowing Fallback (Context Length Exceeded)")
-		return true
-	}
-
-	// Add other conditions where fallback is desired (e.g., specific server errors, timeouts)
-	// if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "status code 5") {
-	// 	   log.Println("DelegatorService: Decision: Allowing Fallback (Transient Error)")
-	//     return true
-	// }
-
-	// Allow fallback for common transient errors (e.g., 5xx status codes, timeouts)
-	if strings.Contains(errStr, "status code 5") || strings.Contains(errStr, "timeout") || strings.Contains(errStr, "connection refused") {
-		log.Println("DelegatorService: Decision: Allowing Fallback (Transient Error)")
-		return true
-	}
-
-	// TODO: Refine - Should we fallback on API key errors? Probably not to the *same* provider.
-	// if strings.Contains(errStr, "invalid API key") { return false }
-
-	// Default: Allow fallback for now, can be made stricter
-	log.Println("DelegatorService: Decision: Allowing Fallback (Default Error)")
-	return true
-}
-
 // executeGenerationWithRetry attempts generation using a sequence of LLMs, handling retries and fallbacks.
-func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, modelName string, messages []gollm_types.MemoryMessage, instructionText string, operationName string) (string, error) {
-	if len(d.primaryAttempts) == 0 || len(d.fallbackAttempts) == 0 {
+// params override the sampling settings of each attempt (not of the chunking fallbacks).
+// task reorders the attempts so the models suited to it are tried first.
+func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, modelName string, task TaskType, messages []gollm_types.MemoryMessage, instructionText string, params GenerationParams, operationName string) (string, error) {
+	primaryAttempts, fallbackAttempts := d.attempts() // Snapshot; a model switch may replace the lists
+	if len(primaryAttempts) == 0 || len(fallbackAttempts) == 0 {
 		return "", fmt.Errorf("delegator service (%s): not properly configured", operationName)
 	}
 	if len(messages) == 0 {
 		return "", fmt.Errorf("delegator service (%s): cannot generate with empty messages", operationName)
 	}
+	tokenLimit := d.tokenLimitThreshold
+	if task != TaskGeneral {
+		primaryAttempts, fallbackAttempts = routeForTask(task, primaryAttempts, fallbackAttempts)
+		tokenLimit = max(tokenLimit, primaryAttempts[0].Config.MaxTokens) // The routed model's context decides chunking
+	}
+	policy := CurrentFallbackPolicy()
+	if policy.LatencyAware {
+		primaryAttempts, fallbackAttempts = orderByLatency(primaryAttempts), orderByLatency(fallbackAttempts)
+	}
+	if Offline() {
+		primaryAttempts, fallbackAttempts = localOnly(primaryAttempts), localOnly(fallbackAttempts)
+		if len(primaryAttempts) == 0 {
+			primaryAttempts, fallbackAttempts = fallbackAttempts, nil
+		}
+		if len(primaryAttempts) == 0 {
+			return "", fmt.Errorf("delegator service (%s): %w", operationName, ErrNoLocalModel)
+		}
+	}
 
 	// Estimate tokens using the designated model for limit checking
 	estimatedTokens := estimateTotalTokens(messages, d.tokenLimitCheckModel)
-	log.Printf("DelegatorService (%s): Estimated tokens for request: %d (Limit: %d, Check Model: %s). Requested Model: '%s'",
-	operationName, estimatedTokens, d.tokenLimitThreshold, d.tokenLimitCheckModel) // Log estimation, but don't bypass primary based on it.
+	opLog := logger.With("operation", operationName)
+	// Log estimation, but don't bypass primary based on it.
+	opLog.Info("Estimated tokens for request", "tokens", estimatedTokens, "limit", tokenLimit, "check_model", d.tokenLimitCheckModel, logging.Model(modelName), "task", string(task), "routed_to", primaryAttempts[0].Config.ID())
 
 	// --- ADDED: Proactive Chunking Check ---
-	if estimatedTokens > d.tokenLimitThreshold && d.contextManager != nil {
-		log.Printf("DelegatorService (%s): Estimated tokens exceed limit. Attempting PROACTIVE chunking with ContextManager...", operationName)
+	if estimatedTokens > tokenLimit && d.contextManager != nil {
+		opLog.Info("Estimated tokens exceed limit, attempting proactive chunking with ContextManager")
 		// Find a suitable LLM for chunking (e.g., the first primary or a designated one)
 		// Using the first primary attempt for proactive chunking
-		chunkingLLM := d.primaryAttempts[0].Instance
-		chunkingModelName := d.primaryAttempts[0].Config.ModelName
-		log.Printf("DelegatorService (%s): Using LLM '%s' for proactive chunking.", operationName, chunkingModelName)
+		chunkingLLM := primaryAttempts[0].Instance
+		chunkingModelName := primaryAttempts[0].Config.ModelName
+		opLog.Info("Using LLM for proactive chunking", logging.Model(chunkingModelName))
 
 		fullPromptForChunking := formatMessagesToPrompt(messages)
 		chunkInstruction := "Process the following section of text:" // Adjust as needed
@@ -180,11 +205,11 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 
 		chunkedResponse, chunkErr := d.contextManager.ProcessLargePrompt(ctx, wrappedLLM, fullPromptForChunking, chunkInstruction)
 		if chunkErr == nil {
-			log.Printf("DelegatorService (%s): PROACTIVE ContextManager chunking successful.", operationName)
+			opLog.Info("Proactive ContextManager chunking successful")
 			d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: chunkedResponse})
 			return chunkedResponse, nil // Return successful chunked response
 		}
-		log.Printf("DelegatorService (%s): PROACTIVE ContextManager chunking failed: %v. Proceeding to standard attempt logic (will likely fail again or trigger reactive chunking).", operationName, chunkErr)
+		opLog.Warn("Proactive ContextManager chunking failed, proceeding to standard attempts", "error", chunkErr)
 		// If proactive chunking fails, let the standard loop proceed, it might hit the reactive chunking later.
 	}
 	// --- END Proactive Chunking Check ---
@@ -193,10 +218,10 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 	specificModelRequested := modelName != "" && modelName != "No models available" && modelName != "Service unavailable"
 
 	if specificModelRequested {
-		log.Printf("DelegatorService (%s): Specific model '%s' requested. Attempting to find and use it.", operationName, modelName)
+		opLog.Info("Specific model requested, looking it up", logging.Model(modelName))
 		found := false
-		for _, attempt := range append(d.primaryAttempts, d.fallbackAttempts...) {
-			if attempt.Config.ModelName == modelName {
+		for _, attempt := range append(primaryAttempts, fallbackAttempts...) {
+			if attempt.Config.matchesModel(modelName) {
 				attemptsToTry = []LLMAttempt{attempt}
 				found = true
 				break
@@ -206,7 +231,7 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 			return "", fmt.Errorf("delegator service (%s): requested model '%s' not found in configured attempts", operationName, modelName)
 		}
 	} else {
-		attemptsToTry = d.primaryAttempts // Default to primary list if no specific model
+		attemptsToTry = primaryAttempts // Default to primary list if no specific model
 	}
 
 	// Convert messages to a single prompt string
@@ -215,7 +240,10 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 
 	var lastError error
 	currentAttemptList := attemptsToTry
+	failed := 0       // Attempts that sent a request and failed
+	stopReason := "" // Set when the policy ends the attempts early
 
+attempts:
 	for listNum := 0; listNum < 2; listNum++ { // Max 2 lists: primary then fallback (or just fallback)
 		if specificModelRequested && listNum > 0 { // If specific model was requested, only try that list (which is `attemptsToTry`)
 			break
@@ -225,45 +253,54 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 		if !specificModelRequested { // Only consider switching to fallback if no specific model was requested
 			if listNum == 0 {
 				listName = "Primary"
-				currentAttemptList = d.primaryAttempts
+				currentAttemptList = primaryAttempts
 			} else if listNum == 1 && lastError != nil { // Only switch to fallback if primary failed
 				listName = "Fallback"
-				log.Printf("DelegatorService (%s): Primary attempts failed. Switching to fallback attempts.", operationName)
-				currentAttemptList = d.fallbackAttempts
+				opLog.Warn("Primary attempts failed, switching to fallback attempts")
+				currentAttemptList = fallbackAttempts
 			}
 		} else if listNum == 1 { // This case should not be hit if specificModelRequested is true due to the break above
 			break // Already tried fallback, don't try primary
 		}
 
 		for i, attempt := range currentAttemptList {
-			targetName := fmt.Sprintf("%s Attempt %d/%d (Model: %s)", listName, i+1, len(currentAttemptList), attempt.Config.ModelName)
-			log.Printf("DelegatorService (%s): Trying %s", operationName, targetName)
+			attemptLog := opLog.With("list", listName, "attempt", i+1, "attempts", len(currentAttemptList), logging.Model(attempt.Config.ModelName), "provider", attempt.Config.ProviderName)
+			if !policy.AllowsAttempt(failed) {
+				stopReason = fmt.Sprintf("the fallback policy allows at most %d fallback attempts", policy.MaxFallbackAttempts)
+				break attempts
+			}
+			attemptLog.Info("Trying attempt")
 
-			// --- Incorporate Instruction Text ---
-			finalPromptStringForLLM := promptString
-			if instructionText != "" {
-				finalPromptStringForLLM = "Instructions:\n" + instructionText + "\n\n---\n\n" + promptString
+			instance, err := attempt.instanceFor(params)
+			if err != nil {
+				attemptLog.Warn("Attempt skipped", "error", err)
+				lastError = err
+				continue
 			}
-			finalPromptForLLM := llm.NewPrompt(finalPromptStringForLLM)
-			responseContent, err := attempt.Instance.Generate(ctx, finalPromptForLLM)
+			// --- Incorporate Instruction Text ---
+			finalPromptForLLM := llm.NewPrompt(withInstruction(instructionText, promptString))
+			attemptCtx, span := startAttemptSpan(ctx, attempt.Config)
+			span.SetAttributes(attribute.String("llm.list", listName), attribute.Int("llm.attempt", i+1))
+			responseContent, err := instance.Generate(attemptCtx, finalPromptForLLM)
+			tracing.End(span, err)
 
 			if err == nil {
-				log.Printf("DelegatorService (%s): Generation successful with %s.", operationName, targetName)
+				attemptLog.Info("Generation successful")
 				d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: responseContent})
 				return responseContent, nil // Success!
 			}
 
 			// Attempt failed
-			log.Printf("DelegatorService (%s): Attempt with %s failed: %v", operationName, targetName, err)
+			attemptLog.Warn("Attempt failed", "error", err)
 			lastError = err // Store the error
+			failed++
 
 			// Decide if we should continue to the next attempt in *this* list
 			// --- ADDED: Reactive Chunking on Context Error ---
-			errStr := err.Error()
-			isContextError := strings.Contains(errStr, "context_length_exceeded") || strings.Contains(errStr, "token limit")
+			isContextError := IsContextLengthError(err)
 
 			if isContextError && d.contextManager != nil {
-				log.Printf("DelegatorService (%s): Attempt with %s failed with context limit. Attempting REACTIVE chunking with ContextManager using the same LLM...", operationName, targetName)
+				attemptLog.Info("Attempt hit the context limit, attempting reactive chunking with the same LLM")
 
 				// Use the current LLM instance that just failed for chunking
 				chunkingLLM := attempt.Instance
@@ -276,17 +313,23 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 					wrappedLLM := &LLMAdapter{LLM: chunkingLLM, ProviderName: attempt.Config.ProviderName} // Pass ProviderName
 					chunkedResponse, chunkErr := d.contextManager.ProcessLargePrompt(ctx, wrappedLLM, fullPromptForChunking, chunkInstruction)
 					if chunkErr == nil {
-						log.Printf("DelegatorService (%s): REACTIVE ContextManager chunking successful with %s.", operationName, targetName)
+						attemptLog.Info("Reactive ContextManager chunking successful")
 						d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: chunkedResponse})
 						return chunkedResponse, nil // Return successful chunked response
 					}
-					log.Printf("DelegatorService (%s): REACTIVE ContextManager chunking with %s failed: %v. Proceeding to next attempt.", operationName, targetName, chunkErr)
+					attemptLog.Warn("Reactive ContextManager chunking failed, proceeding to next attempt", "error", chunkErr)
 					// If chunking fails, store its error (or keep the original context error?) and let the loop proceed.
 					// lastError = chunkErr // Optionally update lastError to the chunking error
 				}
 			} // --- END REACTIVE Chunking Check ---
 
-			log.Printf("DelegatorService (%s): Error is retryable. Continuing to next attempt...", operationName)
+			fallback, reason := policy.ShouldFallback(err)
+			if !fallback {
+				attemptLog.Warn("Fallback policy stops the attempts", "reason", reason)
+				stopReason = reason
+				break attempts
+			}
+			attemptLog.Info("Error is retryable, continuing to next attempt", "reason", reason)
 		}
 
 		// If we finished a list and haven't succeeded, decide if we should try the *next* list
@@ -300,28 +343,30 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 	}
 
 	// If we exit the loops, all attempts failed
-	log.Printf("DelegatorService (%s): All generation attempts failed.", operationName)
+	opLog.Error("All generation attempts failed")
 	if lastError == nil { // Should not happen if we reach here, but defensive check
 		lastError = errors.New("all attempts failed for unknown reasons")
 	}
+	if stopReason != "" {
+		return "", fmt.Errorf("%s failed without falling back further (%s), last error: %w", operationName, stopReason, lastError)
+	}
 
 	// --- FINAL FALLBACK: Context Manager Chunking ---
 	// Check if the last error suggests a context length issue and if context manager exists
 	// This block now acts as a fallback if the *immediate* chunking attempt (for Cerebras) failed,
 	// or if a fallback LLM (like Gemini) failed with a context error.
-	errStr := lastError.Error()
-	isContextError := strings.Contains(errStr, "context_length_exceeded") || strings.Contains(errStr, "token limit")
+	isContextError := IsContextLengthError(lastError)
 
 	if isContextError && d.contextManager != nil {
-		log.Printf("DelegatorService (%s): All attempts failed, last error indicates context limit. Attempting FINAL chunking fallback with ContextManager...", operationName)
+		opLog.Info("Last error indicates context limit, attempting final chunking fallback with ContextManager")
 
 		// Find the Deepseek instance (or another designated chunking LLM for the final fallback)
 		var chunkingLLM llm.LLM
-		for _, attempt := range d.fallbackAttempts { // Search fallbacks first
+		for _, attempt := range fallbackAttempts { // Search fallbacks first
 			// Use the first fallback LLM found for the final attempt
 			if attempt.Instance != nil {
 				chunkingLLM = attempt.Instance
-				log.Printf("DelegatorService (%s): Found LLM '%s' from provider '%s' for final chunking fallback.", operationName, attempt.Config.ModelName, attempt.Config.ProviderName)
+				opLog.Info("Using LLM for final chunking fallback", logging.Model(attempt.Config.ModelName), "provider", attempt.Config.ProviderName)
 				break // Use the first one found
 			}
 		}
@@ -336,24 +381,24 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 			// Call the context manager with wrapped LLM
 			// Find the provider name for the selected chunkingLLM
 			providerName := "unknown"
-			for _, attempt := range d.fallbackAttempts { // Search again to get the name
+			for _, attempt := range fallbackAttempts { // Search again to get the name
 				if attempt.Instance == chunkingLLM { providerName = attempt.Config.ProviderName; break }
 			}
 			// If not found in fallback, check primary (though unlikely path)
-			if providerName == "unknown" { for _, attempt := range d.primaryAttempts { if attempt.Instance == chunkingLLM { providerName = attempt.Config.ProviderName; break } } }
+			if providerName == "unknown" { for _, attempt := range primaryAttempts { if attempt.Instance == chunkingLLM { providerName = attempt.Config.ProviderName; break } } }
 			wrappedLLM := &LLMAdapter{LLM: chunkingLLM, ProviderName: providerName} // Pass ProviderName
 			chunkedResponse, chunkErr := d.contextManager.ProcessLargePrompt(ctx, wrappedLLM, fullPromptForChunking, chunkInstruction)
 			if chunkErr == nil {
-				log.Printf("DelegatorService (%s): FINAL ContextManager chunking fallback successful.", operationName)
+				opLog.Info("Final ContextManager chunking fallback successful")
 				// Add the potentially long, combined response to memory
 				d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: chunkedResponse})
 				return chunkedResponse, nil // Return successful chunked response
 			}
-			log.Printf("DelegatorService (%s): FINAL ContextManager chunking fallback failed: %v", operationName, chunkErr)
+			opLog.Error("Final ContextManager chunking fallback failed", "error", chunkErr)
 			// If chunking also fails, return its error wrapped with the original context
 			return "", fmt.Errorf("%s failed after all attempts including final chunking, chunking error: %w (original error: %v)", operationName, chunkErr, lastError)
 		}
-		log.Printf("DelegatorService (%s): Context error detected, but no suitable LLM found/configured for final chunking fallback.", operationName)
+		opLog.Warn("Context error detected, but no LLM is configured for the final chunking fallback")
 	}
 	// --- End FINAL FALLBACK ---
 
@@ -365,7 +410,7 @@ func (d *DelegatorService) executeGenerationWithRetry(ctx context.Context, model
 
 // GenerateSimple uses standard delegation/fallback ONLY.
 // It now uses the conversation memory.
-func (d *DelegatorService) GenerateSimple(ctx context.Context, modelName string, promptText string, instructionText string) (string, error) {
+func (d *DelegatorService) GenerateSimple(ctx context.Context, modelName string, task TaskType, promptText string, instructionText string, params GenerationParams) (string, error) {
 	userMessage := gollm_types.MemoryMessage{Role: "user", Content: promptText} // Instruction is handled separately
 
 	// Add user prompt to memory
@@ -381,26 +426,27 @@ func (d *DelegatorService) GenerateSimple(ctx context.Context, modelName string,
 
 	if currentMessageTokens > d.tokenLimitThreshold {
 		// If the current message ALONE exceeds the limit, send only it to the fallback logic
-		log.Printf("DelegatorService (Simple): Current prompt (%d tokens) exceeds limit (%d). Sending only current prompt.", currentMessageTokens, d.tokenLimitThreshold)
+		logger.Warn("Simple: current prompt exceeds the token limit, sending only the current prompt", "tokens", currentMessageTokens, "limit", d.tokenLimitThreshold)
 		messagesForContext = []gollm_types.MemoryMessage{userMessage}
 	} else {
 		// Otherwise, try to get history including the current message, respecting the limit
 		messagesForContext = d.memory.GetMessagesForContext(d.tokenLimitThreshold, tokenCheckModelForContext)
 		if len(messagesForContext) == 0 {
 			// This should ideally not happen if currentMessageTokens <= proxyTokenLimit, but handle defensively
-			log.Printf("DelegatorService (Simple): Warning - GetMessagesForContext returned empty despite current prompt fitting. Sending only current prompt.")
+			logger.Warn("Simple: GetMessagesForContext returned nothing although the current prompt fits, sending only the current prompt")
 			messagesForContext = []gollm_types.MemoryMessage{userMessage}
 			// Alternative: return fmt.Errorf("GenerateSimple: No messages fit within the context window limit (%d tokens)", d.proxyTokenLimit)
 		}
 	}
 
 	// MOA is NOT used for simple generation in this design
-	return d.executeGenerationWithRetry(ctx, modelName, messagesForContext, instructionText, "Simple")
+	return d.executeGenerationWithRetry(ctx, modelName, task, messagesForContext, instructionText, params, "Simple")
 }
 
 // GenerateWithCoT uses MOA if available, otherwise standard fallback.
 // It now uses the conversation memory for the fallback path.
 func (d *DelegatorService) GenerateWithCoT(ctx context.Context, promptText string) (string, error) {
+	moa := d.currentMOA()
 	// Construct CoT prompt
 	cotPromptText := fmt.Sprintf("Think step-by-step to answer the following question:\n%s\n\nReasoning steps:", promptText)
 
@@ -410,14 +456,14 @@ func (d *DelegatorService) GenerateWithCoT(ctx context.Context, promptText strin
 	d.memory.AddMessage(gollm_types.MemoryMessage{Role: "user", Content: promptText})
 
 	// --- Use MOA if available ---
-	if d.moa != nil {
-		log.Println("DelegatorService (CoT): Using MOA for generation...")
-		response, err := d.moa.Generate(ctx, cotPromptText)
+	if moa != nil {
+		logger.Info("CoT: using MOA for generation")
+		response, err := moa.Generate(ctx, cotPromptText)
 		if err != nil {
-			log.Printf("DelegatorService (CoT): MOA generation failed: %v", err)
+			logger.Warn("CoT: MOA generation failed", "error", err)
 			// Optionally, could fall back AGAIN to executeGenerationWithFallback here?
 			// return "", fmt.Errorf("CoT generation failed via MOA: %w", err)
-			log.Println("DelegatorService (CoT): MOA failed, falling back to standard generation...")
+			logger.Info("CoT: MOA failed, falling back to standard generation")
 			// Fall through to standard execution if MOA fails
 		} else {
 			// Add successful MOA response to memory
@@ -425,14 +471,14 @@ func (d *DelegatorService) GenerateWithCoT(ctx context.Context, promptText strin
 				Role:    "assistant",
 				Content: response,
 			})
-			log.Println("DelegatorService (CoT): MOA generation successful.")
+			logger.Info("CoT: MOA generation successful")
 			// TODO: Optional parsing if needed for CoT
 			return response, nil
 		}
 	}
 
 	// --- Standard Fallback if MOA is nil or failed ---
-	log.Println("DelegatorService (CoT): Using standard generation with fallback...")
+	logger.Info("CoT: using standard generation with fallback")
 	// For fallback, we need messages. We'll create a temporary message list
 	// containing the CoT prompt, but ideally, memory should handle this better.
 	// For now, let's get context and append the CoT prompt as the last user message.
@@ -446,7 +492,7 @@ func (d *DelegatorService) GenerateWithCoT(ctx context.Context, promptText strin
 	cotMessage := gollm_types.MemoryMessage{Role: "user", Content: cotPromptText}
 	// We pass only this message for the CoT attempt, ignoring history for this specific fallback.
 	// This assumes CoT doesn't need prior context from memory for this step.
-	fullResponse, err := d.executeGenerationWithRetry(ctx, "", []gollm_types.MemoryMessage{cotMessage}, "", "CoT-Fallback") // No specific model, no instruction for this internal step
+	fullResponse, err := d.executeGenerationWithRetry(ctx, "", TaskGeneral, []gollm_types.MemoryMessage{cotMessage}, "", GenerationParams{}, "CoT-Fallback") // No specific model, no instruction for this internal step
 	if err != nil {
 		return "", err // Error already includes context from helper
 	}
@@ -458,20 +504,21 @@ func (d *DelegatorService) GenerateWithCoT(ctx context.Context, promptText strin
 // GenerateWithReflection uses MOA if available for each step, otherwise standard fallback.
 // It now uses the conversation memory for the fallback paths.
 func (d *DelegatorService) GenerateWithReflection(ctx context.Context, promptText string) (string, error) {
-	log.Println("DelegatorService: GenerateWithReflection - Starting initial generation step")
+	moa := d.currentMOA()
+	logger.Info("Reflection: starting initial generation step")
 
 	// --- Step 1: Initial Response Generation (Use MOA if available) ---
 	var initialResponse string
 	var err error
-	if d.moa != nil {
+	if moa != nil {
 		// Add user prompt to memory before MOA attempt
 		// We add the original prompt here.
 		d.memory.AddMessage(gollm_types.MemoryMessage{Role: "user", Content: promptText})
 
-		log.Println("DelegatorService (Reflection-Initial): Using MOA...")
-		initialResponse, err = d.moa.Generate(ctx, promptText)
+		logger.Info("Reflection: using MOA for the initial step")
+		initialResponse, err = moa.Generate(ctx, promptText)
 		if err != nil {
-			log.Printf("DelegatorService (Reflection-Initial): MOA failed: %v. Falling back...", err)
+			logger.Warn("Reflection: MOA failed on the initial step, falling back", "error", err)
 			// Fall through to standard execution if MOA fails
 		}
 	}
@@ -479,44 +526,44 @@ func (d *DelegatorService) GenerateWithReflection(ctx context.Context, promptTex
 	// If MOA not used or failed, use standard fallback
 	if initialResponse == "" {
 		// If MOA wasn't used, add user prompt to memory now
-		if d.moa == nil {
+		if moa == nil {
 			d.memory.AddMessage(gollm_types.MemoryMessage{Role: "user", Content: promptText})
 		}
 
-		log.Println("DelegatorService (Reflection-Initial): Using standard generation...")
+		logger.Info("Reflection: using standard generation for the initial step")
 		// Get messages for context
 		messagesForContext := d.memory.GetMessagesForContext(d.tokenLimitThreshold, d.tokenLimitCheckModel) // Use default check model
 		if len(messagesForContext) == 0 {
 			return "", fmt.Errorf("reflection initial generation: No messages fit context window")
 		}
-		initialResponse, err = d.executeGenerationWithRetry(ctx, "", messagesForContext, "", "Reflection-Initial") // No specific model, no instruction
+		initialResponse, err = d.executeGenerationWithRetry(ctx, "", TaskGeneral, messagesForContext, "", GenerationParams{}, "Reflection-Initial") // No specific model, no instruction
 	}
 
 	// Handle final error from Step 1
 	if err != nil {
 		return "", fmt.Errorf("reflection initial generation failed: %w", err)
-	} else if d.moa != nil && initialResponse != "" { // If MOA succeeded
+	} else if moa != nil && initialResponse != "" { // If MOA succeeded
 		d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: initialResponse})
 	}
-	log.Println("DelegatorService: GenerateWithReflection - Initial generation successful")
+	logger.Info("Reflection: initial generation successful")
 
 
 	// --- Step 2: Reflection Prompt Construction ---
 	reflectionPromptText := fmt.Sprintf("Original prompt: %s\n\nInitial response: %s\n\nPlease review the initial response for accuracy, completeness, and clarity. Provide a revised and improved response based on your review.", promptText, initialResponse)
-	log.Println("DelegatorService: GenerateWithReflection - Starting reflection generation step")
+	logger.Info("Reflection: starting reflection step")
 
 
 	// --- Step 3: Reflection Response Generation (Use MOA if available) ---
 	var finalResponse string
-	if d.moa != nil {
+	if moa != nil {
 		// Add the reflection prompt "user" message to memory before MOA attempt
 		// This makes the reflection step part of the history.
 		d.memory.AddMessage(gollm_types.MemoryMessage{Role: "user", Content: reflectionPromptText})
 
-		log.Println("DelegatorService (Reflection-Reflect): Using MOA...")
-		finalResponse, err = d.moa.Generate(ctx, reflectionPromptText)
+		logger.Info("Reflection: using MOA for the reflection step")
+		finalResponse, err = moa.Generate(ctx, reflectionPromptText)
 		if err != nil {
-			log.Printf("DelegatorService (Reflection-Reflect): MOA failed: %v. Falling back...", err)
+			logger.Warn("Reflection: MOA failed on the reflection step, falling back", "error", err)
 			// Fall through to standard execution if MOA fails
 		}
 	}
@@ -524,26 +571,26 @@ func (d *DelegatorService) GenerateWithReflection(ctx context.Context, promptTex
 	// If MOA not used or failed, use standard fallback
 	if finalResponse == "" {
 		// If MOA wasn't used, add reflection prompt to memory now
-		if d.moa == nil {
+		if moa == nil {
 			d.memory.AddMessage(gollm_types.MemoryMessage{Role: "user", Content: reflectionPromptText})
 		}
 
-		log.Println("DelegatorService (Reflection-Reflect): Using standard generation...")
+		logger.Info("Reflection: using standard generation for the reflection step")
 		// Get messages for context (including the reflection prompt)
 		messagesForContext := d.memory.GetMessagesForContext(d.tokenLimitThreshold, d.tokenLimitCheckModel) // Use default check model
 		if len(messagesForContext) == 0 {
 			return "", fmt.Errorf("reflection refinement generation: No messages fit context window")
 		}
-		finalResponse, err = d.executeGenerationWithRetry(ctx, "", messagesForContext, "", "Reflection-Reflect") // No specific model, no instruction
+		finalResponse, err = d.executeGenerationWithRetry(ctx, "", TaskGeneral, messagesForContext, "", GenerationParams{}, "Reflection-Reflect") // No specific model, no instruction
 	}
 
 	// Handle final error from Step 3
 	if err != nil {
 		return "", fmt.Errorf("reflection refinement generation failed: %w", err)
-	} else if d.moa != nil && finalResponse != "" { // If MOA succeeded
+	} else if moa != nil && finalResponse != "" { // If MOA succeeded
 		d.memory.AddMessage(gollm_types.MemoryMessage{Role: "assistant", Content: finalResponse})
 	}
-	log.Println("DelegatorService: GenerateWithReflection - Reflection generation successful")
+	logger.Info("Reflection: reflection generation successful")
 
 	return finalResponse, nil
 }
@@ -551,7 +598,8 @@ func (d *DelegatorService) GenerateWithReflection(ctx context.Context, promptTex
 // GenerateStructuredOutput uses MOA if available, otherwise standard fallback.
 // It now uses the conversation memory for the fallback path.
 func (d *DelegatorService) GenerateStructuredOutput(ctx context.Context, content string, schema string) (string, error) {
-	log.Println("DelegatorService: GenerateStructuredOutput - Starting generation")
+	moa := d.currentMOA()
+	logger.Info("StructuredOutput: starting generation")
 
 	// --- Step 1: Construct Structured Prompt ---
 	structuredPromptText := fmt.Sprintf("Analyze the following content:\n\n---\n%s\n---\n\nPlease extract the relevant information and respond ONLY with a valid JSON object strictly adhering to the following JSON schema:\n```json\n%s\n```", content, schema)
@@ -566,11 +614,11 @@ func (d *DelegatorService) GenerateStructuredOutput(ctx context.Context, content
 	var err error
 
 	// --- Use MOA if available ---
-	if d.moa != nil {
-		log.Println("DelegatorService (StructuredOutput): Using MOA...")
-		response, err = d.moa.Generate(ctx, structuredPromptText)
+	if moa != nil {
+		logger.Info("StructuredOutput: using MOA")
+		response, err = moa.Generate(ctx, structuredPromptText)
 		if err != nil {
-			log.Printf("DelegatorService (StructuredOutput): MOA failed: %v. Falling back...", err)
+			logger.Warn("StructuredOutput: MOA failed, falling back", "error", err)
 			// Fall through to standard execution if MOA fails
 		}
 		// If MOA succeeded, add response to memory
@@ -581,13 +629,13 @@ func (d *DelegatorService) GenerateStructuredOutput(ctx context.Context, content
 
 	// If MOA not used or failed, use standard fallback
 	if response == "" {
-		log.Println("DelegatorService (StructuredOutput): Using standard generation...")
+		logger.Info("StructuredOutput: using standard generation")
 		// Get messages for context (including the structured prompt)
 		messagesForContext := d.memory.GetMessagesForContext(d.tokenLimitThreshold, d.tokenLimitCheckModel) // Use default check model
 		if len(messagesForContext) == 0 {
 			return "", fmt.Errorf("structured output generation: No messages fit context window")
 		}
-		response, err = d.executeGenerationWithRetry(ctx, "", messagesForContext, "", "StructuredOutput") // No specific model, no instruction
+		response, err = d.executeGenerationWithRetry(ctx, "", TaskStructured, messagesForContext, "", GenerationParams{}, "StructuredOutput") // No specific model, no instruction
 		// Note: response is added to memory inside executeGenerationWithFallback on success
 	}
 
@@ -598,21 +646,49 @@ func (d *DelegatorService) GenerateStructuredOutput(ctx context.Context, content
 		return "", fmt.Errorf("structured output generation failed: %w", err)
 	}
 
-	log.Println("DelegatorService: GenerateStructuredOutput - Generation successful (validation may still be needed)")
+	logger.Info("StructuredOutput: generation successful (validation may still be needed)")
 	// TODO: Add JSON validation logic here if needed
 
 	return response, nil
 }
 
-// Add method to update MOA instance if needed by SetProxy/BaseModel in InferenceService
+// UpdateMOA replaces the MOA instance, e.g. after its default models change.
 func (d *DelegatorService) UpdateMOA(moaInstance *gollm.MOA) {
-    // This method might not be strictly necessary if NewDelegatorService is always called
-    // after model changes, but provides an alternative update path.
-    if moaInstance == nil {
-        log.Println("[WARN] DelegatorService.UpdateMOA: Received nil MOA instance.")
-    }
-    d.moa = moaInstance
-    log.Println("DelegatorService: Internal MOA instance updated.")
+	if moaInstance == nil {
+		logger.Warn("UpdateMOA: received nil MOA instance")
+	}
+	d.attemptsMu.Lock()
+	d.moa = moaInstance
+	d.attemptsMu.Unlock()
+	logger.Info("Internal MOA instance updated")
+}
+
+// UpdateAttempts replaces the primary and fallback lists after a model
+// switch. Requests already running keep the lists they started with;
+// conversation memory is kept.
+func (d *DelegatorService) UpdateAttempts(primaryAttempts, fallbackAttempts []LLMAttempt) {
+	d.attemptsMu.Lock()
+	d.primaryAttempts = primaryAttempts
+	d.fallbackAttempts = fallbackAttempts
+	d.attemptsMu.Unlock()
+}
+
+// attempts returns the current primary and fallback lists.
+func (d *DelegatorService) attempts() (primary, fallback []LLMAttempt) {
+	d.attemptsMu.RLock()
+	defer d.attemptsMu.RUnlock()
+	return d.primaryAttempts, d.fallbackAttempts
+}
+
+// currentMOA returns the current MOA instance, or nil. Offline there is
+// none, as its models are remote.
+func (d *DelegatorService) currentMOA() *gollm.MOA {
+	if Offline() {
+		return nil
+	}
+	d.attemptsMu.RLock()
+	defer d.attemptsMu.RUnlock()
+	return d.moa
 }
 
 // ClearMemory clears the conversation history.
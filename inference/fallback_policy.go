@@ -0,0 +1,154 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"Inference_Engine/storage"
+)
+
+// fallbackPolicyDocument is the state database document holding the policy
+// saved in the settings.
+const fallbackPolicyDocument = "fallback_policy"
+
+// statusCodePattern finds the HTTP status in a provider error, e.g. "status
+// code 503", "status 429" or "HTTP 401".
+var statusCodePattern = regexp.MustCompile(`(?i)\b(?:status(?:\s+code)?|http)\s*[:=]?\s*([1-5]\d\d)\b`)
+
+// FallbackPolicy decides whether a failed attempt moves on to the next model.
+// Never-fallback rules win over retryable ones; patterns match anywhere in the
+// error, ignoring case.
+type FallbackPolicy struct {
+	RetryablePatterns        []string `json:"retryable_patterns"`
+	RetryableStatusCodes     []int    `json:"retryable_status_codes"`
+	NeverFallbackPatterns    []string `json:"never_fallback_patterns"`
+	NeverFallbackStatusCodes []int    `json:"never_fallback_status_codes"`
+	MaxFallbackAttempts      int      `json:"max_fallback_attempts"`    // Models tried after the first; 0 for no limit
+	FallbackOnOtherErrors    bool     `json:"fallback_on_other_errors"` // For errors no rule matches
+	LatencyAware             bool     `json:"latency_aware"`            // Try the providers the latency probe found fastest first
+}
+
+// DefaultFallbackPolicy falls back on context limits, rate limits, timeouts
+// and server errors, and on errors no rule matches, but not on rejected
+// credentials, which another attempt with the same key can't fix.
+func DefaultFallbackPolicy() FallbackPolicy {
+	return FallbackPolicy{
+		RetryablePatterns:        []string{"context_length_exceeded", "token limit", "rate limit", "timeout", "deadline exceeded", "connection refused", "connection reset", "overloaded"},
+		RetryableStatusCodes:     []int{408, 429, 500, 502, 503, 504},
+		NeverFallbackPatterns:    []string{"invalid api key", "incorrect api key", "api key not valid", "unauthorized", "permission denied"},
+		NeverFallbackStatusCodes: []int{401, 403},
+		FallbackOnOtherErrors:    true,
+	}
+}
+
+// ShouldFallback reports whether err lets the next model be tried, with the
+// reason for the log. A canceled request never falls back.
+func (p FallbackPolicy) ShouldFallback(err error) (bool, string) {
+	if err == nil {
+		return false, "no error"
+	}
+	if errors.Is(err, context.Canceled) {
+		return false, "canceled"
+	}
+	text := strings.ToLower(err.Error())
+	code := statusCode(text)
+	if code != 0 && slices.Contains(p.NeverFallbackStatusCodes, code) {
+		return false, fmt.Sprintf("status %d never falls back", code)
+	}
+	if pattern, ok := matchPattern(text, p.NeverFallbackPatterns); ok {
+		return false, fmt.Sprintf("%q never falls back", pattern)
+	}
+	if code != 0 && slices.Contains(p.RetryableStatusCodes, code) {
+		return true, fmt.Sprintf("status %d is retryable", code)
+	}
+	if pattern, ok := matchPattern(text, p.RetryablePatterns); ok {
+		return true, fmt.Sprintf("%q is retryable", pattern)
+	}
+	if p.FallbackOnOtherErrors {
+		return true, "other errors fall back"
+	}
+	return false, "not retryable"
+}
+
+// AllowsAttempt reports whether the policy allows another model after
+// attempted models were tried.
+func (p FallbackPolicy) AllowsAttempt(attempted int) bool {
+	return p.MaxFallbackAttempts <= 0 || attempted <= p.MaxFallbackAttempts
+}
+
+// statusCode returns the HTTP status in an error text, or 0.
+func statusCode(text string) int {
+	match := statusCodePattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(match[1])
+	return code
+}
+
+// matchPattern returns the first pattern found in text, which is lower case.
+func matchPattern(text string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && strings.Contains(text, strings.ToLower(pattern)) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+var fallbackPolicy = struct {
+	sync.Mutex
+	policy FallbackPolicy
+	db     *storage.DB
+}{policy: DefaultFallbackPolicy()}
+
+// CurrentFallbackPolicy returns the policy the delegator follows.
+func CurrentFallbackPolicy() FallbackPolicy {
+	fallbackPolicy.Lock()
+	defer fallbackPolicy.Unlock()
+	return fallbackPolicy.policy
+}
+
+// SetFallbackPolicy saves the policy; the next request follows it.
+func SetFallbackPolicy(p FallbackPolicy) error {
+	fallbackPolicy.Lock()
+	defer fallbackPolicy.Unlock()
+	if fallbackPolicy.db != nil {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		if err := fallbackPolicy.db.SetDocument(fallbackPolicyDocument, string(data)); err != nil {
+			return fmt.Errorf("failed to save the fallback policy: %w", err)
+		}
+	}
+	fallbackPolicy.policy = p
+	logger.Info("Saved fallback policy", "max_fallback_attempts", p.MaxFallbackAttempts, "fallback_on_other_errors", p.FallbackOnOtherErrors)
+	return nil
+}
+
+// loadFallbackPolicy reads the saved policy from db, or the default without
+// one, and keeps later changes there.
+func loadFallbackPolicy(db *storage.DB) error {
+	text, ok, err := db.Document(fallbackPolicyDocument)
+	if err != nil {
+		return err
+	}
+	policy := DefaultFallbackPolicy()
+	if ok {
+		if err := json.Unmarshal([]byte(text), &policy); err != nil {
+			return fmt.Errorf("failed to read the fallback policy: %w", err)
+		}
+	}
+	fallbackPolicy.Lock()
+	defer fallbackPolicy.Unlock()
+	fallbackPolicy.policy, fallbackPolicy.db = policy, db
+	return nil
+}
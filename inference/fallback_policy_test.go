@@ -0,0 +1,81 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestFallbackPolicyShouldFallback(t *testing.T) {
+	policy := DefaultFallbackPolicy()
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("Cerebras API request failed with status 503: overloaded"), true},
+		{errors.New("status code 429: Too Many Requests"), true},
+		{errors.New("error: context_length_exceeded"), true},
+		{errors.New("Cerebras API request failed with status 401: bad key"), false},
+		{errors.New("HTTP 403 Forbidden"), false},
+		{errors.New("Invalid API key provided"), false},
+		{errors.New("status 429: invalid api key"), false}, // Never-fallback rules win
+		{fmt.Errorf("request failed: %w", context.Canceled), false},
+		{errors.New("something unexpected"), true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got, reason := policy.ShouldFallback(tt.err); got != tt.want {
+			t.Errorf("ShouldFallback(%v) = %v (%s), expected %v", tt.err, got, reason, tt.want)
+		}
+	}
+
+	policy.FallbackOnOtherErrors = false
+	if got, _ := policy.ShouldFallback(errors.New("something unexpected")); got {
+		t.Errorf("Expected no fallback for an unmatched error when other errors don't fall back")
+	}
+	policy.RetryablePatterns = append(policy.RetryablePatterns, "Unexpected")
+	if got, _ := policy.ShouldFallback(errors.New("something unexpected")); !got {
+		t.Errorf("Expected patterns to match regardless of case")
+	}
+}
+
+func TestFallbackPolicyAllowsAttempt(t *testing.T) {
+	policy := FallbackPolicy{MaxFallbackAttempts: 1}
+	if !policy.AllowsAttempt(0) || !policy.AllowsAttempt(1) || policy.AllowsAttempt(2) {
+		t.Errorf("Expected the first model and one fallback only")
+	}
+	if !(FallbackPolicy{}).AllowsAttempt(10) {
+		t.Errorf("Expected no limit without a maximum")
+	}
+}
+
+func TestFallbackPolicySaved(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	if err := loadFallbackPolicy(db); err != nil {
+		t.Fatalf("loadFallbackPolicy failed: %v", err)
+	}
+	defer SetFallbackPolicy(DefaultFallbackPolicy()) // Leaves the default for other tests
+
+	if got := CurrentFallbackPolicy(); got.MaxFallbackAttempts != 0 || !got.FallbackOnOtherErrors {
+		t.Errorf("Expected the default policy without a saved one, got %+v", got)
+	}
+	saved := FallbackPolicy{RetryableStatusCodes: []int{503}, NeverFallbackPatterns: []string{"quota"}, MaxFallbackAttempts: 2}
+	if err := SetFallbackPolicy(saved); err != nil {
+		t.Fatalf("SetFallbackPolicy failed: %v", err)
+	}
+	if err := loadFallbackPolicy(db); err != nil {
+		t.Fatalf("loadFallbackPolicy failed: %v", err)
+	}
+	got := CurrentFallbackPolicy()
+	if got.MaxFallbackAttempts != 2 || got.FallbackOnOtherErrors || len(got.RetryableStatusCodes) != 1 || got.NeverFallbackPatterns[0] != "quota" {
+		t.Errorf("Expected the saved policy after reloading, got %+v", got)
+	}
+}
@@ -5,19 +5,20 @@ import (
 	"context"
 	"encoding/json" // Import json package
 	"fmt"
-	"log"
 	"net/http" // Import net/http package
 	"strings"
 	"sync"
 	"time" 
 
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+
 	// Import Google's Gemini client library
 	// "github.com/google/generative-ai-go/genai" // REMOVE genai client import
 	// "google.golang.org/api/option" // REMOVE unused import
 
 	"os" // Import os package
 
-	"github.com/google/generative-ai-go/genai"
 	"github.com/teilomillet/gollm/config"
 	"github.com/teilomillet/gollm/providers"
 	"github.com/teilomillet/gollm/types"
@@ -60,7 +61,7 @@ type GeminiPart struct {
 // init registers the Gemini provider with the gollm registry.
 // This function runs automatically when the package is imported.
 func init() {
-	log.Println("Registering Gemini provider constructor with gollm registry")
+	logger.Info("Registering provider constructor with gollm registry", "provider", "gemini")
 	providers.GetDefaultRegistry().Register("gemini", NewGeminiProvider)
 }
 
@@ -90,7 +91,7 @@ type GeminiErrorResponse struct {
 // NewGeminiProvider creates an instance of the Gemini provider.
 // It's called by gollm when gollm.NewLLM is used with provider "gemini".
 func NewGeminiProvider(apiKey, model string, extraHeaders map[string]string) providers.Provider {
-	log.Printf("[DEBUG] NewGeminiProvider called! apiKey: %t, model: %s", apiKey != "", model)
+	logger.Debug("NewGeminiProvider called", "has_api_key", apiKey != "", logging.Model(model))
 
 	// Initialize with arguments and defaults
 	provider := &GeminiProvider{
@@ -107,7 +108,7 @@ func NewGeminiProvider(apiKey, model string, extraHeaders map[string]string) pro
 	// Set default model if provided one is empty
 	if provider.model == "" {
 		provider.model = "gemini-1.5-flash-latest" // Use the known working model name
-		log.Printf("Gemini model defaulting to %s", provider.model)
+		logger.Info("Model defaulted", "provider", "gemini", logging.Model(provider.model))
 	}
 
 	// Copy provided extraHeaders
@@ -122,13 +123,23 @@ func NewGeminiProvider(apiKey, model string, extraHeaders map[string]string) pro
 	if apiEndpoint == "" {
 		// Default to the v1beta base path as it's commonly needed
 		apiEndpoint = "https://generativelanguage.googleapis.com/v1beta/"
-		log.Println("GeminiProvider: GEMINI_API_ENDPOINT not set, using default:", apiEndpoint)
+		logger.Info("GEMINI_API_ENDPOINT not set, using default endpoint", "provider", "gemini", "endpoint", apiEndpoint)
 	} else {
-		log.Println("GeminiProvider: Using endpoint from GEMINI_API_ENDPOINT:", apiEndpoint)
+		logger.Info("Using endpoint from GEMINI_API_ENDPOINT", "provider", "gemini", "endpoint", apiEndpoint)
 	}
 	provider.baseEndpoint = apiEndpoint // Store the base endpoint
 
-	log.Printf("NewGeminiProvider created: model=%s", provider.model)
+	// A base URL or headers saved in the settings route it through a gateway
+	override := ProviderOverrideFor("gemini")
+	if override.BaseURL != "" {
+		provider.baseEndpoint = override.BaseURL
+		logger.Info("Using endpoint override", "provider", "gemini", "endpoint", override.BaseURL)
+	}
+	for k, v := range override.headers() {
+		provider.extraHeaders[k] = v
+	}
+
+	logger.Info("Provider created", "provider", "gemini", logging.Model(provider.model))
 	return provider
 }
 
@@ -184,7 +195,7 @@ func (p *GeminiProvider) PrepareRequest(prompt string, options map[string]interf
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	log.Printf("GeminiProvider: Preparing request for model %s", p.model)
+	logger.Info("Preparing request", "provider", "gemini", logging.Model(p.model))
 
 	// Construct the request body manually
 	reqBody := GeminiRequest{
@@ -222,7 +233,7 @@ func (p *GeminiProvider) PrepareRequestWithMessages(messages []types.MemoryMessa
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	log.Printf("GeminiProvider: Preparing request with messages for model %s", p.model)
+	logger.Info("Preparing request with messages", "provider", "gemini", logging.Model(p.model))
 
 	// Convert messages to Gemini format
 	geminiContents := make([]GeminiContent, 0, len(messages))
@@ -479,46 +490,6 @@ func (p *GeminiProvider) GenerateContent(ctx context.Context, prompt string) (st
 	// If this method IS called, it means something is using it directly.
 	// For now, return an error or a placeholder.
 	return "", fmt.Errorf("direct call to GeminiProvider.GenerateContent is not the standard gollm flow")
-	/*
-
-		log.Printf("GeminiProvider (GenerateContent): Constructed URL: %s", fullURL)
-
-		p.logger.Debug("GeminiProvider: Sending HTTP request", "url", fullURL, "body_len", len(reqBytes))
-
-		// Create HTTP request
-		httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(reqBytes))
-		if err != nil {
-			return "", fmt.Errorf("failed to create Gemini HTTP request: %w", err)
-		}
-
-		// Set headers (minimal, Content-Type is important)
-		httpReq.Header.Set("Content-Type", "application/json")
-
-		/* // Generation config is now part of the request body
-		if p.temperature != nil {
-			genModel.SetTemperature(*p.temperature)
-		}
-		if p.topP != nil {
-			genModel.SetTopP(*p.topP)
-		}
-		if p.topK != nil {
-			genModel.SetTopK(*p.topK)
-		}
-		genModel.SetMaxOutputTokens(int32(p.maxTokens))
-		*/
-
-	// --- Add Debug Logging ---
-	p.logger.Debug("GeminiProvider: Attempting GenerateContent", "model", model, "prompt_length", len(prompt))
-	if len(prompt) > 100 {
-		p.logger.Debug("GeminiProvider: Prompt prefix", "prefix", prompt[:100]+"...")
-	} else {
-		p.logger.Debug("GeminiProvider: Prompt", "prompt", prompt)
-	}
-	// --- End Debug Logging ---
-
-	// HTTP request logic removed - handled by gollm
-	return "", fmt.Errorf("direct call to GeminiProvider.GenerateContent is not the standard gollm flow")
-	
 }
 
 // GenerateContentFromMessages sends a conversation to the Gemini API and returns the response.
@@ -550,62 +521,10 @@ func (p *GeminiProvider) GenerateContentFromMessages(ctx context.Context, messag
 		baseEndpoint += "/"
 	}
 	fullURL := fmt.Sprintf("%smodels/%s:generateContent?key=%s", baseEndpoint, model, apiKey)
-	log.Printf("GeminiProvider (GenerateContentFromMessages): Constructed URL: %s", fullURL)
+	logger.Debug("Constructed request URL", "provider", "gemini", "url", fullURL)
 
 	// --- REMOVED: Manual HTTP Request Logic ---
 	return "", fmt.Errorf("direct call to GeminiProvider.GenerateContentFromMessages is not the standard gollm flow")
-	/*
-		p.logger.Debug("GeminiProvider: Sending HTTP request (messages)", "url", fullURL, "body_len", len(reqBytes))
-
-		// Create HTTP request
-		httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(reqBytes))
-		if err != nil {
-			return "", fmt.Errorf("failed to create Gemini HTTP request (messages): %w", err)
-		}
-
-		// Set headers
-		httpReq.Header.Set("Content-Type", "application/json")
-		p.mutex.Lock()
-		for k, v := range p.extraHeaders {
-			httpReq.Header.Set(k, v)
-		}
-		/* // Generation config is now part of the request body
-		if p.temperature != nil {
-			genModel.SetTemperature(*p.temperature)
-		}
-		if p.topP != nil {
-			genModel.SetTopP(*p.topP)
-		}
-		if p.topK != nil {
-			genModel.SetTopK(*p.topK)
-		}
-		genModel.SetMaxOutputTokens(int32(p.maxTokens))
-		*/
-	p.mutex.Unlock()
-
-	// Convert messages to Gemini format
-	var chat []*genai.Content // Use pointer slice
-	for _, msg := range messages {
-		role := msg.Role
-		if role == "assistant" {
-			role = "model"
-		}
-		// Ensure role is either "user" or "model"
-		if role != "user" && role != "model" {
-			p.logger.Warn("Invalid role for Gemini, skipping message", "role", role)
-			continue
-		}
-
-		content := &genai.Content{ // Create pointer
-			Role:  role,
-			Parts: []genai.Part{genai.Text(msg.Content)},
-		}
-		chat = append(chat, content)
-	}
-
-	// HTTP request logic removed - handled by gollm
-	return "", fmt.Errorf("direct call to GeminiProvider.GenerateContentFromMessages is not the standard gollm flow")
-	
 }
 
 // StreamContent streams a response from the Gemini API.
@@ -614,6 +533,7 @@ func (p *GeminiProvider) StreamContent(ctx context.Context, prompt string) (chan
 	errChan := make(chan error, 1)
 
 	go func() {
+		defer crash.Recover("GeminiProvider.StreamContent")
 		defer close(textChan)
 		defer close(errChan)
 
@@ -623,20 +543,6 @@ func (p *GeminiProvider) StreamContent(ctx context.Context, prompt string) (chan
 		// parsing each Server-Sent Event (SSE) chunk.
 		// For now, return an error indicating it's not implemented.
 		errChan <- fmt.Errorf("streaming not yet implemented for manual Gemini HTTP provider")
-		return
-		p.mutex.Lock()
-		// if p.temperature != nil {
-		// 	genModel.SetTemperature(*p.temperature) // undefined: genModel
-		// }
-		// if p.topP != nil {
-		// 	genModel.SetTopP(*p.topP) // undefined: genModel
-		// }
-		// if p.topK != nil {
-		// 	genModel.SetTopK(*p.topK) // undefined: genModel
-		// }
-		// genModel.SetMaxOutputTokens(int32(p.maxTokens)) // undefined: genModel
-		p.mutex.Unlock()
-
 	}()
 
 	return textChan, errChan
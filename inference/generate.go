@@ -0,0 +1,201 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/postprocess"
+	"Inference_Engine/tracing"
+
+	"github.com/teilomillet/gollm"
+	"github.com/teilomillet/gollm/config"
+	"github.com/teilomillet/gollm/llm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GenerateOptions configures a single Generate call. The zero value sends the
+// prompt through the delegator's primary and fallback models with the
+// provider defaults.
+type GenerateOptions struct {
+	Context     context.Context // Cancels the request; defaults to context.Background()
+	Model       string          // Use only this model (name or "provider/model"); empty tries primary, then fallback
+	Provider    string          // Send straight to this provider, bypassing delegation and memory
+	UseMOA      bool            // Use the Mixture of Agents instead of a single model
+	Instruction string          // Prepended to the prompt as "Instructions:"
+	Task        TaskType        // Routes a delegated request to the models suited to it
+	Params      GenerationParams
+	PostProcess bool // Runs the enabled post-processing steps on the answer, for content shown or saved as a page
+}
+
+// GenerationParams overrides sampling settings for one request. Zero values
+// keep the model's configured defaults. MOA requests ignore them.
+type GenerationParams struct {
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+}
+
+// IsZero reports whether p leaves every setting at its default.
+func (p GenerationParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens <= 0
+}
+
+// configOptions returns the gollm options applying p.
+func (p GenerationParams) configOptions() []config.ConfigOption {
+	var opts []config.ConfigOption
+	if p.Temperature != nil {
+		opts = append(opts, config.SetTemperature(*p.Temperature))
+	}
+	if p.TopP != nil {
+		opts = append(opts, config.SetTopP(*p.TopP))
+	}
+	if p.MaxTokens > 0 {
+		opts = append(opts, config.SetMaxTokens(p.MaxTokens))
+	}
+	return opts
+}
+
+// instanceFor returns the attempt's LLM with params applied. Shared instances
+// are never modified; overrides get a short-lived instance built from the
+// attempt's options.
+func (a LLMAttempt) instanceFor(params GenerationParams) (llm.LLM, error) {
+	if params.IsZero() {
+		return a.Instance, nil
+	}
+	opts := append(append([]config.ConfigOption(nil), a.Opts...), params.configOptions()...)
+	instance, err := gollm.NewLLM(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply generation parameters to %s: %w", a.Config.ModelName, err)
+	}
+	llmInstance, ok := instance.(llm.LLM)
+	if !ok {
+		return nil, fmt.Errorf("instance for %s is not an llm.LLM", a.Config.ModelName)
+	}
+	return llmInstance, nil
+}
+
+// withInstruction prepends an instruction block to a prompt, the format every
+// generation path uses.
+func withInstruction(instruction, prompt string) string {
+	if instruction == "" {
+		return prompt
+	}
+	return "Instructions:\n" + instruction + "\n\n---\n\n" + prompt
+}
+
+// Generate produces text for prompt. By default the request goes through the
+// DelegatorService (conversation memory, fallback and chunking); opts can pin
+// a model, target one provider directly, or use MOA.
+func (s *InferenceService) Generate(prompt string, opts GenerateOptions) (string, error) {
+	response, _, err := s.GenerateWithUsage(prompt, opts)
+	return response, err
+}
+
+// GenerateWithUsage is Generate, also returning the tokens the request used.
+func (s *InferenceService) GenerateWithUsage(prompt string, opts GenerateOptions) (string, TokenUsage, error) {
+	if opts.UseMOA && opts.Provider != "" {
+		return "", TokenUsage{}, errors.New("generate: UseMOA and Provider cannot be combined")
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	route := "delegator"
+	if opts.UseMOA {
+		route = "moa"
+	} else if opts.Provider != "" {
+		route = "provider"
+	}
+	ctx, span := tracing.Start(ctx, "generate", attribute.String("generate.route", route),
+		attribute.String("generate.model", opts.Model), attribute.String("generate.provider", opts.Provider), attribute.String("generate.task", string(opts.Task)),
+		attribute.Int("generate.prompt_chars", len(prompt)))
+	response, usage, err := s.generate(ctx, prompt, opts)
+	if err == nil && opts.PostProcess {
+		response = postprocess.Apply(response)
+	}
+	span.SetAttributes(attribute.Int("generate.response_chars", len(response)),
+		attribute.Int("generate.prompt_tokens", usage.PromptTokens), attribute.Int("generate.completion_tokens", usage.CompletionTokens))
+	tracing.End(span, err)
+	return response, usage, err
+}
+
+// generate routes a Generate call.
+func (s *InferenceService) generate(ctx context.Context, prompt string, opts GenerateOptions) (string, TokenUsage, error) {
+
+	s.mutex.Lock()
+	if !s.isRunning {
+		s.mutex.Unlock()
+		return "", TokenUsage{}, errors.New("inference service is not running")
+	}
+	delegatorInstance := s.delegator
+	var attempt *LLMAttempt
+	var routeErr error
+	if opts.Provider != "" {
+		attempt = s.findAttempt(opts.Provider, opts.Model)
+		if attempt == nil {
+			routeErr = s.checkProvider(opts.Provider, opts.Model)
+		}
+	} else if opts.Model != "" && !opts.UseMOA {
+		routeErr = s.checkModelRef(opts.Model)
+	}
+	s.mutex.Unlock()
+	if routeErr != nil {
+		return "", TokenUsage{}, routeErr
+	}
+
+	switch {
+	case opts.UseMOA:
+		return s.generateMOA(ctx, prompt, opts)
+
+	case opts.Provider != "":
+		instance, err := attempt.instanceFor(opts.Params)
+		if err != nil {
+			return "", TokenUsage{}, err
+		}
+		logger.Info("Sending generation request directly to provider", "provider", opts.Provider, logging.Model(attempt.Config.ModelName))
+		fullPrompt := withInstruction(opts.Instruction, prompt)
+		ctx, finish := s.usage.StartJobContext(ctx, fullPrompt)
+		attemptCtx, span := startAttemptSpan(ctx, attempt.Config)
+		response, err := instance.Generate(attemptCtx, llm.NewPrompt(fullPrompt))
+		tracing.End(span, err)
+		return response, finish(response), err
+
+	default:
+		if delegatorInstance == nil {
+			return "", TokenUsage{}, errors.New("inference service delegator is not configured")
+		}
+		logger.Info("Delegating generation request to DelegatorService", logging.Model(opts.Model), "instruction", opts.Instruction)
+		ctx, finish := s.usage.StartJobContext(ctx, opts.Instruction+prompt)
+		response, err := delegatorInstance.GenerateSimple(ctx, opts.Model, opts.Task, prompt, opts.Instruction, opts.Params)
+		usage := finish(response)
+		if err != nil {
+			return "", usage, err
+		}
+		logger.Info("Generation successful via DelegatorService", logging.Model(opts.Model), "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+		return response, usage, nil
+	}
+}
+
+// startAttemptSpan starts the span of one request to a provider.
+func startAttemptSpan(ctx context.Context, conf LLMAttemptConfig) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "llm "+conf.ProviderName, attribute.String("llm.provider", conf.ProviderName), attribute.String("llm.model", conf.ModelName))
+}
+
+// findAttempt returns the first configured attempt matching a provider and a
+// model (a name or "provider/model" reference); an empty argument matches
+// anything. The caller holds s.mutex.
+func (s *InferenceService) findAttempt(providerName, modelName string) *LLMAttempt {
+	for _, attempts := range [][]LLMAttempt{s.primaryAttempts, s.fallbackAttempts} {
+		for i := range attempts {
+			conf := attempts[i].Config
+			if (providerName == "" || conf.ProviderName == providerName) && (modelName == "" || conf.matchesModel(modelName)) {
+				attempt := attempts[i]
+				return &attempt
+			}
+		}
+	}
+	return nil
+}
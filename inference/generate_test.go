@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerationParams(t *testing.T) {
+	if !(GenerationParams{}).IsZero() {
+		t.Errorf("Expected zero params to be IsZero")
+	}
+	temperature := 0.2
+	params := GenerationParams{Temperature: &temperature, MaxTokens: 500}
+	if params.IsZero() {
+		t.Errorf("Expected params with overrides not to be IsZero")
+	}
+	if got := len(params.configOptions()); got != 2 {
+		t.Errorf("Expected 2 config options, got %d", got)
+	}
+}
+
+func TestGenerateValidatesOptions(t *testing.T) {
+	s := NewInferenceService()
+
+	if _, err := s.Generate("Hello", GenerateOptions{UseMOA: true, Provider: "gemini"}); err == nil {
+		t.Errorf("Expected an error when combining UseMOA and Provider")
+	}
+	if _, err := s.Generate("Hello", GenerateOptions{}); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Expected a not running error, got %v", err)
+	}
+}
+
+func TestWithInstruction(t *testing.T) {
+	if got := withInstruction("", "Prompt"); got != "Prompt" {
+		t.Errorf("Expected the prompt unchanged, got %q", got)
+	}
+	if got := withInstruction("Be brief", "Prompt"); got != "Instructions:\nBe brief\n\n---\n\nPrompt" {
+		t.Errorf("Unexpected combined prompt %q", got)
+	}
+}
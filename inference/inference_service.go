@@ -5,18 +5,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"os"
 	"sync"
 	"time"
 
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+
 	// Use gollm types for messages if needed here, or keep them internal to delegator
 	"github.com/teilomillet/gollm"
 	"github.com/teilomillet/gollm/config"
 	"github.com/teilomillet/gollm/llm"
+	gollm_types "github.com/teilomillet/gollm/types"
 	
 )
 
+// logger is shared by the inference package; records carry model and provider fields.
+var logger = logging.For("inference")
+
 // LLMAttemptConfig defines the configuration for a single LLM attempt.
 type LLMAttemptConfig struct {
 	ProviderName  string
@@ -24,6 +29,7 @@ type LLMAttemptConfig struct {
 	APIKeyEnvVar  string // Environment variable name for the API key
 	MaxTokens     int
 	IsPrimary     bool // True if part of initial attempts, false for fallback
+	JSONMode      bool // The provider can be asked for JSON-only answers; structured tasks go to it
 	// Add EndpointOverride string if needed
 }
 
@@ -49,14 +55,20 @@ type InferenceService struct {
 	moaFallbackModelName  string
 	moaPrimaryOpts      []config.ConfigOption
 	moaFallbackOpts     []config.ConfigOption
+	usage               *UsageTracker // Active jobs and estimated token spend, shown in the status bar
+	registry            modelRegistry // Every model Start tried to configure, with its status
+	modelListeners      []func()      // Called after the model configuration changes
+	downgradeListeners  []func(MOADowngrade) // Called when an MOA job falls back to one model
 }
 
 // NewInferenceService creates a new instance of InferenceService.
 func NewInferenceService() *InferenceService {
+	captureTokenUsage()
 	return &InferenceService{
 		// Initialize slices
 		primaryAttempts:  make([]LLMAttempt, 0),
 		fallbackAttempts: make([]LLMAttempt, 0),
+		usage:            NewUsageTracker(),
 		// Initialize ContextManager with default strategy
 		contextManager:   NewContextManager(
 			ChunkByTokenCount, // Use token count for better splitting
@@ -67,20 +79,14 @@ func NewInferenceService() *InferenceService {
 
 // Start configures the service with both proxy and base providers and the delegator.
 func (s *InferenceService) Start() error {
-	log.Println("InferenceService: Starting...")
+	logger.Info("Starting inference service")
+	defer s.notifyModelsChanged() // Runs after the unlock below
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// --- Define the desired attempts ---
-	// Example: Try Cerebras model A, then Cerebras model B, then fallback to Gemini Flash, then Gemini Pro
-	attemptConfigs := []LLMAttemptConfig{
-		{ProviderName: "cerebras", ModelName: "llama-4-scout-17b-16e-instruct", APIKeyEnvVar: "CEREBRAS_API_KEY", MaxTokens: 4000, IsPrimary: true},
-		// {ProviderName: "cerebras", ModelName: "some-other-cerebras-model", APIKeyEnvVar: "CEREBRAS_API_KEY", MaxTokens: 8000, IsPrimary: true}, // Example: another primary
-		// {ProviderName: "cerebras", ModelName: "llama-4-scout-17b-16e-instruct", APIKeyEnvVar: "CEREBRAS_API_KEY_2", MaxTokens: 4000, IsPrimary: true}, // Example: different key
-		{ProviderName: "gemini", ModelName: "gemini-1.5-flash-latest", APIKeyEnvVar: "GEMINI_API_KEY", MaxTokens: 100000, IsPrimary: false},    // Fallback 1 (Use working model name)
-		{ProviderName: "deepseek", ModelName: "deepseek-chat", APIKeyEnvVar: "DEEPSEEK_API_KEY", MaxTokens: 8000, IsPrimary: false},          // Fallback 2 (Target for final chunking)
-		// {ProviderName: "gemini", ModelName: "gemini-1.5-pro-latest", APIKeyEnvVar: "GEMINI_API_KEY", MaxTokens: 1000000, IsPrimary: false}, // Fallback 3 (Example: Use Pro if needed)
-	}
+	attemptConfigs := defaultModelConfigs
+	s.registry = modelRegistry{}
 
 	s.primaryAttempts = make([]LLMAttempt, 0)
 	s.fallbackAttempts = make([]LLMAttempt, 0)
@@ -89,44 +95,22 @@ func (s *InferenceService) Start() error {
 
 	// --- Initialize LLM instances based on config ---
 	for _, attemptConf := range attemptConfigs {
-		log.Printf("InferenceService: Configuring LLM attempt: Provider=%s, Model=%s, Primary=%t", attemptConf.ProviderName, attemptConf.ModelName, attemptConf.IsPrimary)
-		apiKey := os.Getenv(attemptConf.APIKeyEnvVar)
-		if apiKey == "" {
-			log.Printf("[WARN] InferenceService: API Key from env var '%s' not found for model '%s'. Skipping this attempt.", attemptConf.APIKeyEnvVar, attemptConf.ModelName)
-			continue // Skip this attempt if key is missing
-		}
-
-		opts := []config.ConfigOption{
-			config.SetProvider(attemptConf.ProviderName),
-			config.SetAPIKey(apiKey),
-			config.SetModel(attemptConf.ModelName),
-			config.SetMaxTokens(attemptConf.MaxTokens),
-			// Add config.SetEndpoint(attemptConf.EndpointOverride) if needed
-		}
-
-		llmInstance, err := gollm.NewLLM(opts...)
+		logger.Info("Configuring LLM attempt", "provider", attemptConf.ProviderName, logging.Model(attemptConf.ModelName), "primary", attemptConf.IsPrimary)
+		attempt, err := buildAttempt(attemptConf)
 		if err != nil {
-			log.Printf("[ERROR] InferenceService: Failed to create LLM instance for model '%s': %v. Skipping this attempt.", attemptConf.ModelName, err)
-			continue // Skip this attempt on error
+			logger.Warn("Skipping LLM attempt", logging.Model(attemptConf.ModelName), "error", err)
+			s.registry.add(attemptConf, err.Error())
+			continue
 		}
-
-		if initializedLLM, ok := llmInstance.(llm.LLM); ok {
-			attempt := LLMAttempt{
-				Instance: initializedLLM,
-				Config:   attemptConf,
-				Opts:     opts, // STORE THE OPTS
-			}
-			if attemptConf.IsPrimary {
-				s.primaryAttempts = append(s.primaryAttempts, attempt)
-				primaryOptsList = append(primaryOptsList, opts)
-			} else {
-				s.fallbackAttempts = append(s.fallbackAttempts, attempt)
-				fallbackOptsList = append(fallbackOptsList, opts)
-			}
-			log.Printf("InferenceService: Successfully configured LLM instance for model '%s'", attemptConf.ModelName)
+		if attemptConf.IsPrimary {
+			s.primaryAttempts = append(s.primaryAttempts, attempt)
+			primaryOptsList = append(primaryOptsList, attempt.Opts)
 		} else {
-			log.Printf("[ERROR] InferenceService: Initialized instance for model '%s' is not of type llm.LLM. Skipping.", attemptConf.ModelName)
+			s.fallbackAttempts = append(s.fallbackAttempts, attempt)
+			fallbackOptsList = append(fallbackOptsList, attempt.Opts)
 		}
+		s.registry.add(attemptConf, "")
+		logger.Info("Configured LLM instance", logging.Model(attemptConf.ModelName))
 	}
 
 	// --- Validate that we have at least one primary and one fallback ---
@@ -146,7 +130,7 @@ func (s *InferenceService) Start() error {
 
 	// Attempt to create the initial MOA instance
 	if err := s.reconfigureMOAInternal(); err != nil {
-		log.Printf("[WARN] InferenceService: Initial MOA configuration failed: %v. MOA features disabled.", err)
+		logger.Warn("Initial MOA configuration failed, MOA features disabled", "error", err)
 	} // Removed incorrect 'else' block that was setting s.moa = nil on success
 	// --- End MOA Creation ---
 
@@ -158,16 +142,16 @@ func (s *InferenceService) Start() error {
 	// Pass contextManager to DelegatorService
 	s.delegator = NewDelegatorService(s.primaryAttempts, s.fallbackAttempts, delegatorTokenLimit, delegatorTokenModel, s.moa, s.contextManager)
 	if s.delegator == nil {
-		log.Println("[ERROR] InferenceService: Failed to create DelegatorService.") // Corrected log message
+		logger.Error("Failed to create DelegatorService")
 		s.isRunning = false
 		// Clear attempts?
 		s.moa = nil
 		return fmt.Errorf("failed to create delegator service")
 	}
-	log.Println("InferenceService: DelegatorService created.")
+	logger.Info("DelegatorService created")
 
 	s.isRunning = true
-	log.Println("InferenceService: Started successfully.")
+	logger.Info("Inference service started")
 	return nil
 }
 
@@ -186,92 +170,10 @@ func (s *InferenceService) Stop() error {
 	s.moaFallbackOpts = nil
 	s.delegator = nil // Clear delegator
 	// s.contextManager = nil // Keep context manager? Or re-init on Start? Let's keep it.
-	log.Println("InferenceService stopped.")
+	logger.Info("Inference service stopped")
 	return nil
 }
 
-// GenerateText delegates to the DelegatorService.
-func (s *InferenceService) GenerateText(modelName string, promptText string, instructionText string) (string, error) {
-	s.mutex.Lock() // Lock at the beginning
-	if !s.isRunning || s.delegator == nil {
-		s.mutex.Unlock()
-		return "", errors.New("inference service is not running or delegator not configured")
-	}
-	delegatorInstance := s.delegator // Capture instance under lock
-	s.mutex.Unlock()
-
-	ctx := context.Background()
-	log.Printf("InferenceService: Delegating generation request to DelegatorService. Model: '%s', Instruction: '%s'", modelName, instructionText)
-	// --- Adapt GenerateText to potentially use ContextManager ---
-	// The delegator will now handle the potential call to ContextManager internally
-	// Pass modelName and instructionText to the delegator
-	response, err := delegatorInstance.GenerateSimple(ctx, modelName, promptText, instructionText)
-	// --- End Adapt ---
-	if err != nil {
-		return "", err
-	}
-	log.Println("InferenceService: Generation successful via DelegatorService.")
-	return response, nil
-}
-
-// --- ADDED: GenerateTextWithProvider ---
-// GenerateTextWithProvider sends a prompt directly to the first configured instance of a specific provider.
-func (s *InferenceService) GenerateTextWithProvider(providerName string, promptText string) (string, error) {
-	s.mutex.Lock()
-	if !s.isRunning {
-		s.mutex.Unlock()
-		return "", errors.New("inference service is not running")
-	}
-	// Find the specific LLM instance
-	llmInstance := s.findLLMInstance(providerName)
-	if llmInstance == nil {
-		s.mutex.Unlock()
-		return "", fmt.Errorf("provider '%s' not found or not configured", providerName)
-	}
-	s.mutex.Unlock() // Unlock before making the potentially long call
-
-	ctx := context.Background() // Consider allowing context passing
-	log.Printf("InferenceService: Delegating direct generation request to provider '%s'...", providerName)
-
-	// Use the llm.NewPrompt helper from the gollm library
-	prompt := llm.NewPrompt(promptText)
-
-	return llmInstance.Generate(ctx, prompt)
-}
-
-// --- ADDED: GenerateTextWithMOA ---
-// GenerateTextWithMOA directly delegates to the MOA instance.
-func (s *InferenceService) GenerateTextWithMOA(promptText string, instructionText string) (string, error) {
-	s.mutex.Lock()
-	if !s.isRunning {
-		s.mutex.Unlock()
-		return "", errors.New("inference service is not running")
-	}
-	if s.moa == nil {
-		s.mutex.Unlock()
-		return "", errors.New("MOA (Mixture of Agents) is not configured or failed to initialize")
-	}
-	moaInstance := s.moa // Capture instance under lock
-	s.mutex.Unlock()
-
-	ctx := context.Background() // Consider allowing context passing
-	log.Printf("InferenceService: Delegating generation request to MOA. Instruction: '%s'", instructionText)
-
-	combinedPrompt := promptText
-	if instructionText != "" {
-		combinedPrompt = "Instructions:\n" + instructionText + "\n\n---\n\n" + promptText
-	}
-
-	// Note: MOA's Generate might have its own internal timeouts based on AgentTimeout
-	response, err := moaInstance.Generate(ctx, combinedPrompt)
-	if err != nil {
-		log.Printf("InferenceService: Direct MOA generation failed: %v", err)
-		return "", fmt.Errorf("MOA generation failed: %w", err)
-	}
-	log.Println("InferenceService: Direct generation successful via MOA.")
-	return response, nil
-}
-
 // --- ADDED: GenerateTextWithContextManager ---
 // Explicitly trigger context manager processing (useful for testing or specific UI actions)
 func (s *InferenceService) GenerateTextWithContextManager(promptText, instruction string, llmProviderName string) (string, error) {
@@ -280,21 +182,26 @@ func (s *InferenceService) GenerateTextWithContextManager(promptText, instructio
 		s.mutex.Unlock()
 		return "", errors.New("service not running or context manager not configured")
 	}
-	// Find the LLM instance to use (e.g., Deepseek) - simplified lookup
-	llmInstance := s.findLLMInstance(llmProviderName) // Need to implement findLLMInstance
-	if llmInstance == nil {
+	// Find the LLM instance to use (e.g., Deepseek)
+	attempt := s.findAttempt(llmProviderName, "")
+	if attempt == nil {
+		err := s.checkProvider(llmProviderName, "")
 		s.mutex.Unlock()
-		return "", fmt.Errorf("LLM provider '%s' not found or configured", llmProviderName)
+		return "", err
 	}
+	llmInstance := attempt.Instance
 	ctxMgr := s.contextManager
 	s.mutex.Unlock()
 
 	ctx := context.Background()
-	log.Printf("InferenceService: Explicitly calling ContextManager with provider %s", llmProviderName)
+	logger.Info("Calling ContextManager explicitly", "provider", llmProviderName)
 	// Adapt llmInstance to TextGenerator interface if needed
 	// Wrap the LLM in our adapter to implement TextGenerator
 	wrappedLLM := &LLMAdapter{LLM: llmInstance, ProviderName: llmProviderName} // Pass ProviderName
-	return ctxMgr.ProcessLargePrompt(ctx, wrappedLLM, promptText, instruction)
+	ctx, finish := s.usage.StartJobContext(ctx, instruction + promptText)
+	response, err := ctxMgr.ProcessLargePrompt(ctx, wrappedLLM, promptText, instruction)
+	finish(response)
+	return response, err
 }
 
 // --- Update other generation methods to use DelegatorService ---
@@ -308,8 +215,11 @@ func (s *InferenceService) GenerateTextWithCoT(promptText string) (string, error
 	delegatorInstance := s.delegator
 	s.mutex.Unlock()
 	ctx := context.Background()
-	log.Println("InferenceService: Delegating CoT generation to DelegatorService...")
-	return delegatorInstance.GenerateWithCoT(ctx, promptText) // Call delegator
+	logger.Info("Delegating CoT generation to DelegatorService")
+	ctx, finish := s.usage.StartJobContext(ctx, promptText)
+	response, err := delegatorInstance.GenerateWithCoT(ctx, promptText) // Call delegator
+	finish(response)
+	return response, err
 }
 
 func (s *InferenceService) GenerateTextWithReflection(promptText string) (string, error) {
@@ -321,8 +231,11 @@ func (s *InferenceService) GenerateTextWithReflection(promptText string) (string
 	delegatorInstance := s.delegator
 	s.mutex.Unlock()
 	ctx := context.Background()
-	log.Println("InferenceService: Delegating Reflection generation to DelegatorService...")
-	return delegatorInstance.GenerateWithReflection(ctx, promptText) // Call delegator
+	logger.Info("Delegating Reflection generation to DelegatorService")
+	ctx, finish := s.usage.StartJobContext(ctx, promptText)
+	response, err := delegatorInstance.GenerateWithReflection(ctx, promptText) // Call delegator
+	finish(response)
+	return response, err
 }
 
 func (s *InferenceService) GenerateStructuredOutput(content string, schema string) (string, error) {
@@ -334,14 +247,18 @@ func (s *InferenceService) GenerateStructuredOutput(content string, schema strin
 	delegatorInstance := s.delegator
 	s.mutex.Unlock()
 	ctx := context.Background()
-	log.Println("InferenceService: Delegating structured output generation to DelegatorService...")
-	return delegatorInstance.GenerateStructuredOutput(ctx, content, schema) // Call delegator
+	logger.Info("Delegating structured output generation to DelegatorService")
+	ctx, finish := s.usage.StartJobContext(ctx, schema + content)
+	response, err := delegatorInstance.GenerateStructuredOutput(ctx, content, schema) // Call delegator
+	finish(response)
+	return response, err
 }
 
 // --- Model Setting Methods ---
 // SetMOAPrimaryModel sets the default primary model used for MOA configuration.
 // This does NOT change the primary execution/fallback list.
 func (s *InferenceService) SetMOAPrimaryModel(modelName string) error {
+	defer s.notifyModelsChanged()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -364,21 +281,22 @@ func (s *InferenceService) SetMOAPrimaryModel(modelName string) error {
 
 	s.moaPrimaryModelName = modelName
 	s.moaPrimaryOpts = foundOpts
-	log.Printf("InferenceService: MOA primary model default set to '%s'. Reconfiguring MOA...", modelName)
+	logger.Info("MOA primary model default set, reconfiguring MOA", logging.Model(modelName))
 
 	// Reconfigure MOA
 	if err := s.reconfigureMOAInternal(); err != nil {
-		log.Printf("[ERROR] Failed to reconfigure MOA after setting primary model: %v", err)
+		logger.Error("Failed to reconfigure MOA after setting primary model", logging.Model(modelName), "error", err)
 		return fmt.Errorf("failed to reconfigure MOA: %w", err)
 	}
 
-	log.Println("InferenceService: MOA reconfigured successfully.")
+	logger.Info("MOA reconfigured")
 	return nil
 }
 
 // SetMOAFallbackModel sets the default fallback model used for MOA configuration (including aggregator).
 // This does NOT change the primary execution/fallback list.
 func (s *InferenceService) SetMOAFallbackModel(modelName string) error {
+	defer s.notifyModelsChanged()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -401,15 +319,15 @@ func (s *InferenceService) SetMOAFallbackModel(modelName string) error {
 
 	s.moaFallbackModelName = modelName
 	s.moaFallbackOpts = foundOpts
-	log.Printf("InferenceService: MOA fallback model default set to '%s'. Reconfiguring MOA...", modelName)
+	logger.Info("MOA fallback model default set, reconfiguring MOA", logging.Model(modelName))
 
 	// Reconfigure MOA
 	if err := s.reconfigureMOAInternal(); err != nil {
-		log.Printf("[ERROR] Failed to reconfigure MOA after setting fallback model: %v", err)
+		logger.Error("Failed to reconfigure MOA after setting fallback model", logging.Model(modelName), "error", err)
 		return fmt.Errorf("failed to reconfigure MOA: %w", err)
 	}
 
-	log.Println("InferenceService: MOA reconfigured successfully.")
+	logger.Info("MOA reconfigured")
 	return nil
 }
 
@@ -458,6 +376,36 @@ func (s *InferenceService) IsRunning() bool {
 	return s.isRunning
 }
 
+// UsageStats returns the number of in-flight generation jobs and today's estimated token spend.
+func (s *InferenceService) UsageStats() UsageStats {
+	return s.usage.Stats()
+}
+
+// SetDailyTokenBudget sets the estimated tokens that may be spent per day
+// before OnBudgetExceeded listeners are called; 0 removes the budget.
+func (s *InferenceService) SetDailyTokenBudget(tokens int) {
+	s.usage.SetDailyTokenBudget(tokens)
+}
+
+// OnBudgetExceeded registers a listener called the first time each day that
+// the estimated token spend exceeds the daily budget.
+func (s *InferenceService) OnBudgetExceeded(listener func(UsageStats)) {
+	s.usage.OnBudgetExceeded(listener)
+}
+
+// SetStateStore keeps the usage statistics in the state database, so the
+// daily totals survive a restart, along with the provider overrides and the
+// fallback policy.
+func (s *InferenceService) SetStateStore(db *storage.DB) error {
+	if err := loadProviderOverrides(db); err != nil {
+		logger.Error("Could not load the provider overrides", "error", err)
+	}
+	if err := loadFallbackPolicy(db); err != nil {
+		logger.Error("Could not load the fallback policy", "error", err)
+	}
+	return s.usage.SetStore(db)
+}
+
 // GetName identifies the service structure
 func (s *InferenceService) GetName() string {
 	return "InferenceService(Delegator+MOA)" // Updated name
@@ -476,10 +424,26 @@ func (s *InferenceService) ClearConversationHistory() error {
 	return nil
 }
 
+// RestoreConversationHistory replaces the memory in the delegator with
+// messages, to continue a saved conversation.
+func (s *InferenceService) RestoreConversationHistory(messages []ConversationMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.isRunning || s.delegator == nil {
+		return errors.New("inference service is not running or delegator not configured")
+	}
+	s.delegator.memory.Clear()
+	for _, message := range messages {
+		s.delegator.memory.AddMessage(gollm_types.MemoryMessage{Role: message.Role, Content: message.Content})
+	}
+	logger.Info("Restored conversation history", "messages", len(messages))
+	return nil
+}
+
 // reconfigureMOAInternal handles the creation or recreation of the MOA instance.
 // Assumes lock is already held.
 func (s *InferenceService) reconfigureMOAInternal() error {
-	log.Println("InferenceService: Reconfiguring MOA...")
+	logger.Info("Reconfiguring MOA")
 
 	// Check if required opts are set
 	if s.moaPrimaryOpts == nil || s.moaFallbackOpts == nil {
@@ -490,7 +454,7 @@ func (s *InferenceService) reconfigureMOAInternal() error {
 	// --- END DEBUG ---
 	// --- Create the MOA Service ---
 	moaCfg := gollm.MOAConfig{
-		Iterations: 2, // Or make configurable
+		Iterations: moaIterations,
 		Models: []config.ConfigOption{
 			// Use the currently selected MOA primary options
 			func(cfg *config.Config) {
@@ -512,13 +476,13 @@ func (s *InferenceService) reconfigureMOAInternal() error {
 	aggregatorOpts := s.moaFallbackOpts
 	moaInstance, moaErr := gollm.NewMOA(moaCfg, aggregatorOpts...)
 	if moaErr != nil {
-		log.Printf("[ERROR] InferenceService: Failed to create/recreate MOA instance: %v", moaErr)
+		logger.Error("Failed to create MOA instance", "error", moaErr)
 		s.moa = nil // Ensure it's nil on error
 		return moaErr
 	}
 
 	s.moa = moaInstance // Store the new MOA instance
-	log.Printf("InferenceService: MOA instance created/recreated successfully (Primary: %s, Fallback: %s).", s.moaPrimaryModelName, s.moaFallbackModelName)
+	logger.Info("MOA instance created", "primary_model", s.moaPrimaryModelName, "fallback_model", s.moaFallbackModelName)
 
 	// Update the delegator with the new MOA instance
 	if s.delegator != nil {
@@ -526,20 +490,3 @@ func (s *InferenceService) reconfigureMOAInternal() error {
 	}
 	return nil
 }
-
-// findLLMInstance searches primary and fallback attempts for a provider name.
-// NOTE: This is a simplified lookup, might need refinement if multiple models
-// from the same provider exist. Returns the first match.
-func (s *InferenceService) findLLMInstance(providerName string) llm.LLM {
-	for _, attempt := range s.primaryAttempts {
-		if attempt.Config.ProviderName == providerName {
-			return attempt.Instance
-		}
-	}
-	for _, attempt := range s.fallbackAttempts {
-		if attempt.Config.ProviderName == providerName {
-			return attempt.Instance
-		}
-	}
-	return nil
-}
\ No newline at end of file
@@ -12,8 +12,8 @@ type LLMAdapter struct {
 }
 
 // GenerateText implements the TextGenerator interface
-func (a *LLMAdapter) GenerateText(prompt string) (string, error) {
+func (a *LLMAdapter) GenerateText(ctx context.Context, prompt string) (string, error) {
 	// Convert string prompt to llm.Prompt using the package's NewPrompt function
 	p := llm.NewPrompt(prompt)
-	return a.LLM.Generate(context.Background(), p)
+	return a.LLM.Generate(ctx, p)
 }
@@ -0,0 +1,170 @@
+package inference
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalModel is a model a local server has, as it reports it.
+type LocalModel struct {
+	Name      string
+	Size      int64     // Bytes on disk; 0 if not reported
+	Loaded    bool      // In memory now
+	Memory    int64     // Bytes of memory it takes while loaded
+	VRAM      int64     // Of Memory, the bytes on the GPU
+	ExpiresAt time.Time // When an idle loaded model is unloaded; zero if not reported
+}
+
+// LocalServer is what a local provider's server reports about itself.
+type LocalServer struct {
+	Provider string
+	BaseURL  string
+	Ollama   bool // Reports sizes and loaded models; other servers only list models
+	Models   []LocalModel
+	Error    string // Why the server couldn't be read
+}
+
+// getJSON decodes the JSON answer to a GET request into v.
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL.Path)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// readOllama reads an Ollama server's models and the ones it has loaded.
+func readOllama(ctx context.Context, client *http.Client, root string, header http.Header) ([]LocalModel, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		} `json:"models"`
+	}
+	if err := getJSON(ctx, client, root+"/api/tags", header, &tags); err != nil {
+		return nil, err
+	}
+	var ps struct {
+		Models []struct {
+			Name      string    `json:"name"`
+			Size      int64     `json:"size"`
+			SizeVRAM  int64     `json:"size_vram"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"models"`
+	}
+	if err := getJSON(ctx, client, root+"/api/ps", header, &ps); err != nil {
+		return nil, err
+	}
+	models := make([]LocalModel, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		models = append(models, LocalModel{Name: tag.Name, Size: tag.Size})
+	}
+	for _, loaded := range ps.Models {
+		i := slices.IndexFunc(models, func(m LocalModel) bool { return m.Name == loaded.Name })
+		if i < 0 {
+			models = append(models, LocalModel{Name: loaded.Name})
+			i = len(models) - 1
+		}
+		models[i].Loaded, models[i].Memory, models[i].VRAM, models[i].ExpiresAt = true, loaded.Size, loaded.SizeVRAM, loaded.ExpiresAt
+	}
+	return models, nil
+}
+
+// readLocalServer reads what a local provider's server reports: Ollama's
+// model list and loaded models, or else the OpenAI-compatible model list.
+func readLocalServer(ctx context.Context, client *http.Client, provider, apiKey string) LocalServer {
+	override := ProviderOverrideFor(provider)
+	server := LocalServer{Provider: provider, BaseURL: override.BaseURL}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	header := http.Header{}
+	if apiKey != "" {
+		header.Set("Authorization", "Bearer "+apiKey)
+	}
+	for name, value := range override.headers() {
+		header.Set(name, value)
+	}
+	if models, err := readOllama(ctx, client, localRoot(override.BaseURL), header); err == nil {
+		server.Ollama, server.Models = true, models
+		return server
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, client, override.endpoint("", "/models"), header, &list); err != nil {
+		server.Error = err.Error()
+		return server
+	}
+	for _, model := range list.Data {
+		server.Models = append(server.Models, LocalModel{Name: model.ID})
+	}
+	return server
+}
+
+// LocalServers reads the servers of the providers routed to this machine,
+// in provider order, so the settings can show which models they have and
+// what is loaded.
+func (s *InferenceService) LocalServers(ctx context.Context) []LocalServer {
+	var providers []string
+	keys := map[string]string{}
+	for _, conf := range s.localModels() {
+		if _, seen := keys[conf.ProviderName]; !seen {
+			providers = append(providers, conf.ProviderName)
+			keys[conf.ProviderName] = os.Getenv(conf.APIKeyEnvVar)
+		}
+	}
+	slices.Sort(providers)
+	client := &http.Client{}
+	servers := make([]LocalServer, len(providers))
+	for i, provider := range providers {
+		servers[i] = readLocalServer(ctx, client, provider, keys[provider])
+	}
+	return servers
+}
+
+// HostMemory returns the total and available memory of this machine, read
+// from /proc/meminfo; ok is false where there is none.
+func HostMemory() (total, available int64, ok bool) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available = kb * 1024
+		}
+	}
+	return total, available, total > 0
+}
@@ -0,0 +1,50 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalServers(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models": [{"name": "llama3:8b", "size": 4661224676}, {"name": "qwen2:72b", "size": 41000000000}]}`))
+		case "/api/ps":
+			w.Write([]byte(`{"models": [{"name": "llama3:8b", "size": 6000000000, "size_vram": 4500000000, "expires_at": "2030-01-01T00:10:00Z"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ollama.Close()
+	llamacpp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "mistral-7b"}]}`))
+	}))
+	defer llamacpp.Close()
+	t.Setenv("DEEPSEEK_BASE_URL", ollama.URL+"/v1")
+	t.Setenv("GEMINI_BASE_URL", llamacpp.URL+"/v1")
+	t.Setenv("CEREBRAS_BASE_URL", "")
+
+	s := NewInferenceService()
+	s.primaryAttempts = []LLMAttempt{{Config: defaultModelConfigs[0]}, {Config: defaultModelConfigs[2]}}
+	s.fallbackAttempts = []LLMAttempt{{Config: defaultModelConfigs[1]}}
+	servers := s.LocalServers(context.Background())
+	if len(servers) != 2 || servers[0].Provider != "deepseek" || servers[1].Provider != "gemini" {
+		t.Fatalf("Expected the deepseek and gemini servers, got %+v", servers)
+	}
+	if got := servers[0]; !got.Ollama || got.Error != "" || len(got.Models) != 2 ||
+		!got.Models[0].Loaded || got.Models[0].Memory != 6000000000 || got.Models[0].VRAM != 4500000000 || got.Models[0].ExpiresAt.IsZero() ||
+		got.Models[1].Loaded || got.Models[1].Size != 41000000000 {
+		t.Errorf("Unexpected Ollama server %+v", got)
+	}
+	if got := servers[1]; got.Ollama || got.Error != "" || fmt.Sprint(got.Models) != fmt.Sprint([]LocalModel{{Name: "mistral-7b"}}) {
+		t.Errorf("Unexpected OpenAI-compatible server %+v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+	"Inference_Engine/settings"
+)
+
+// PrefLocalKeepAlive keeps the models of local providers loaded between
+// generations.
+var PrefLocalKeepAlive = settings.NewKey("inference.local_keep_alive", true)
+
+// warmUpTimeout bounds loading one local model, which can take minutes for
+// a large model on a slow disk.
+const warmUpTimeout = 10 * time.Minute
+
+// ollamaKeepAlive is how long Ollama keeps a model loaded after each ping;
+// longer than the ping interval, so it stays loaded while the app runs.
+const ollamaKeepAlive = "15m"
+
+// localRoot returns the server root of a local provider's base URL, without
+// the "/v1" OpenAI-compatible prefix.
+func localRoot(base string) string {
+	return strings.TrimSuffix(strings.TrimRight(base, "/"), "/v1")
+}
+
+// warmUpRequest builds a request that loads model on a local server. Ollama
+// loads it for a generate request without a prompt, keeping it for
+// ollamaKeepAlive; other OpenAI-compatible servers get a one-token
+// completion.
+func warmUpRequest(ctx context.Context, conf LLMAttemptConfig, ollama bool) (*http.Request, error) {
+	override := ProviderOverrideFor(conf.ProviderName)
+	var url string
+	var body any
+	if ollama {
+		url = localRoot(override.BaseURL) + "/api/generate"
+		body = map[string]any{"model": conf.ModelName, "keep_alive": ollamaKeepAlive}
+	} else {
+		url = override.endpoint("", "/chat/completions")
+		body = map[string]any{"model": conf.ModelName, "max_tokens": 1, "messages": []map[string]string{{"role": "user", "content": "Hi"}}}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := os.Getenv(conf.APIKeyEnvVar); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	for name, value := range override.headers() {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// warmUp loads one local model, trying Ollama's API first.
+func warmUp(ctx context.Context, client *http.Client, conf LLMAttemptConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, warmUpTimeout)
+	defer cancel()
+	for _, ollama := range []bool{true, false} {
+		req, err := warmUpRequest(ctx, conf, ollama)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if ollama && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) {
+			continue // Not Ollama
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL.Path)
+		}
+		return nil
+	}
+	return nil
+}
+
+// localModels returns the configured models of local providers, each once.
+func (s *InferenceService) localModels() []LLMAttemptConfig {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var models []LLMAttemptConfig
+	for _, attempt := range append(slices.Clone(s.primaryAttempts), s.fallbackAttempts...) {
+		if !isLocal(attempt.Config.ProviderName) || slices.ContainsFunc(models, func(c LLMAttemptConfig) bool { return c.ID() == attempt.Config.ID() }) {
+			continue
+		}
+		models = append(models, attempt.Config)
+	}
+	return models
+}
+
+// WarmUpLocal loads the models of every local provider at once, so the
+// first generation doesn't wait for them to load, and returns how many
+// loaded.
+func (s *InferenceService) WarmUpLocal(ctx context.Context) int {
+	client := &http.Client{}
+	loaded := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, conf := range s.localModels() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer crash.Recover("InferenceService.WarmUpLocal")
+			start := time.Now()
+			if err := warmUp(ctx, client, conf); err != nil {
+				if ctx.Err() == nil {
+					logger.Warn("Failed to warm up local model", "provider", conf.ProviderName, logging.Model(conf.ModelName), "error", err)
+				}
+				return
+			}
+			logger.Debug("Local model warm", "provider", conf.ProviderName, logging.Model(conf.ModelName), "took", time.Since(start))
+			mu.Lock()
+			loaded++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return loaded
+}
+
+// StartLocalKeepAlive warms up the local models now and then pings them
+// every interval, while PrefLocalKeepAlive is on, so they stay loaded. It
+// runs until the returned function is called.
+func (s *InferenceService) StartLocalKeepAlive(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	crash.Go("InferenceService.StartLocalKeepAlive", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		first := true
+		for {
+			if models := s.localModels(); len(models) > 0 && (first || PrefLocalKeepAlive.Get()) {
+				start := time.Now()
+				loaded := s.WarmUpLocal(ctx)
+				if first {
+					logger.Info("Warmed up local models", "loaded", loaded, "models", len(models), "took", time.Since(start))
+				}
+				first = false
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return cancel
+}
@@ -0,0 +1,55 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWarmUpLocal(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]map[string]any{}
+	record := func(r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests[r.Host+r.URL.Path] = body
+		mu.Unlock()
+	}
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Write([]byte(`{"done": true}`))
+	}))
+	defer ollama.Close()
+	llamacpp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		record(r)
+		w.Write([]byte(`{"choices": []}`))
+	}))
+	defer llamacpp.Close()
+	t.Setenv("DEEPSEEK_BASE_URL", ollama.URL+"/v1")
+	t.Setenv("GEMINI_BASE_URL", llamacpp.URL+"/v1/")
+	t.Setenv("CEREBRAS_BASE_URL", "https://gateway.example.com/v1")
+
+	s := NewInferenceService()
+	s.primaryAttempts = []LLMAttempt{{Config: defaultModelConfigs[0]}, {Config: defaultModelConfigs[2]}}
+	s.fallbackAttempts = []LLMAttempt{{Config: defaultModelConfigs[1]}, {Config: defaultModelConfigs[2]}}
+	if loaded := s.WarmUpLocal(context.Background()); loaded != 2 {
+		t.Errorf("WarmUpLocal loaded %d models, want 2", loaded)
+	}
+	if body := requests[ollama.Listener.Addr().String()+"/api/generate"]; body["model"] != "deepseek-chat" || body["keep_alive"] != ollamaKeepAlive {
+		t.Errorf("Expected Ollama to be asked to keep the model loaded, got %v", body)
+	}
+	if body := requests[llamacpp.Listener.Addr().String()+"/v1/chat/completions"]; body["model"] != "gemini-1.5-flash-latest" || body["max_tokens"] != 1.0 {
+		t.Errorf("Expected a one-token completion, got %v", body)
+	}
+	if len(requests) != 2 {
+		t.Errorf("Expected one request per local model and none to remote ones, got %v", requests)
+	}
+}
@@ -0,0 +1,123 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/settings"
+)
+
+// Limits of one MOA job; 0 for no limit.
+var (
+	PrefMOAMaxTokens  = settings.NewKey("moa.max_tokens", 0)
+	PrefMOAMaxSeconds = settings.NewKey("moa.max_seconds", 180)
+)
+
+// DefaultExpectedOutputTokens is the expected length of an answer when
+// nothing says otherwise, about 1,100 words.
+const DefaultExpectedOutputTokens = 1500
+
+var (
+	errMOATokenLimit = errors.New("MOA token limit reached")
+	errMOATimeLimit  = errors.New("MOA time limit reached")
+)
+
+// MOADowngrade describes an MOA job that went over its limits and was
+// generated with a single model instead.
+type MOADowngrade struct {
+	Reason string // Which limit was exceeded, and by how much
+	Model  string // The model that generated the answer instead
+}
+
+// OnMOADowngrade registers a listener called, from the generating
+// goroutine, each time an MOA job falls back to a single model.
+func (s *InferenceService) OnMOADowngrade(listener func(MOADowngrade)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.downgradeListeners = append(s.downgradeListeners, listener)
+}
+
+// generateMOA runs an MOA job within the token and time limits in the
+// settings. A job expected to go over the token limit doesn't start; one
+// that goes over either limit while running is stopped. Both are then
+// generated with the MOA primary model alone.
+func (s *InferenceService) generateMOA(ctx context.Context, prompt string, opts GenerateOptions) (string, TokenUsage, error) {
+	s.mutex.Lock()
+	moaInstance, delegatorInstance, model := s.moa, s.delegator, s.moaPrimaryModelName
+	s.mutex.Unlock()
+	if moaInstance == nil {
+		return "", TokenUsage{}, errors.New("MOA (Mixture of Agents) is not configured or failed to initialize")
+	}
+	if !opts.Params.IsZero() {
+		logger.Warn("Generation parameters are not supported with MOA, using defaults")
+	}
+	maxTokens, maxTime := PrefMOAMaxTokens.Get(), time.Duration(PrefMOAMaxSeconds.Get())*time.Second
+
+	if maxTokens > 0 {
+		if estimate, err := s.EstimateCost(prompt, opts, DefaultExpectedOutputTokens); err == nil && estimate.InputTokens+estimate.OutputTokens > maxTokens {
+			reason := fmt.Sprintf("MOA was expected to use about %d tokens, over the limit of %d", estimate.InputTokens+estimate.OutputTokens, maxTokens)
+			return s.downgradeMOA(ctx, delegatorInstance, model, prompt, opts, reason, TokenUsage{})
+		}
+	}
+
+	logger.Info("Delegating generation request to MOA", "instruction", opts.Instruction, "max_tokens", maxTokens, "max_time", maxTime)
+	combinedPrompt := withInstruction(opts.Instruction, prompt)
+	jobCtx, finish := s.usage.StartJobContext(ctx, combinedPrompt)
+	jobCtx, stop := context.WithCancelCause(jobCtx)
+	defer stop(nil)
+	if maxTime > 0 {
+		timer := time.AfterFunc(maxTime, func() { stop(errMOATimeLimit) })
+		defer timer.Stop()
+	}
+	if maxTokens > 0 {
+		onTokensOver(jobCtx, maxTokens, func() { stop(errMOATokenLimit) })
+	}
+	started := time.Now()
+	var response string
+	var err error
+	if PrefMOAJudge.Get() {
+		response, err = s.judgedMOA(jobCtx, combinedPrompt)
+	} else {
+		response, err = moaInstance.Generate(jobCtx, combinedPrompt)
+	}
+	usage := finish(response)
+	if err != nil {
+		switch cause := context.Cause(jobCtx); {
+		case ctx.Err() != nil:
+		case errors.Is(cause, errMOATimeLimit):
+			return s.downgradeMOA(ctx, delegatorInstance, model, prompt, opts, fmt.Sprintf("MOA was still running after %s, the time limit", maxTime), usage)
+		case errors.Is(cause, errMOATokenLimit):
+			return s.downgradeMOA(ctx, delegatorInstance, model, prompt, opts, fmt.Sprintf("MOA used %d tokens, over the limit of %d", usage.Total(), maxTokens), usage)
+		}
+		logger.Error("MOA generation failed", "error", err)
+		return "", usage, fmt.Errorf("MOA generation failed: %w", err)
+	}
+	logger.Info("Generation successful via MOA", "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens, "duration", time.Since(started))
+	return response, usage, nil
+}
+
+// downgradeMOA generates with model alone after an MOA job went over its
+// limits, adding the tokens the MOA job spent to the usage, and tells the
+// downgrade listeners.
+func (s *InferenceService) downgradeMOA(ctx context.Context, delegatorInstance *DelegatorService, model, prompt string, opts GenerateOptions, reason string, spent TokenUsage) (string, TokenUsage, error) {
+	if delegatorInstance == nil {
+		return "", spent, fmt.Errorf("%s, and no single model is configured to fall back on", reason)
+	}
+	logger.Warn("MOA limit exceeded, generating with a single model instead", "reason", reason, logging.Model(model), "tokens_spent", spent.Total())
+	s.mutex.Lock()
+	listeners := append([]func(MOADowngrade){}, s.downgradeListeners...)
+	s.mutex.Unlock()
+	for _, listener := range listeners {
+		listener(MOADowngrade{Reason: reason, Model: model})
+	}
+	jobCtx, finish := s.usage.StartJobContext(ctx, opts.Instruction+prompt)
+	response, err := delegatorInstance.GenerateSimple(jobCtx, model, opts.Task, prompt, opts.Instruction, opts.Params)
+	usage := finish(response).Plus(spent)
+	if err != nil {
+		return "", usage, fmt.Errorf("%s; the single model failed too: %w", reason, err)
+	}
+	return response, usage, nil
+}
@@ -0,0 +1,144 @@
+package inference
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"Inference_Engine/settings"
+
+	"github.com/teilomillet/gollm"
+	"github.com/teilomillet/gollm/llm"
+)
+
+// useTestSettings gives the test in-memory settings, put back afterwards.
+func useTestSettings(t *testing.T) {
+	previous := settings.Current()
+	settings.Use(settings.New(nil))
+	t.Cleanup(func() { settings.Use(previous) })
+}
+
+// setPref sets key for the rest of the test.
+func setPref[T settings.Value](t *testing.T, key settings.Key[T], value T) {
+	previous := key.Get()
+	key.Set(value)
+	t.Cleanup(func() { key.Set(previous) })
+}
+
+// fakeLLM answers with answer, or, like an MOA agent that runs too long,
+// reports tokens used and then runs until its request is cancelled.
+type fakeLLM struct {
+	llm.LLM
+	answer  string   // Returned straight away, unless blocks
+	blocks  bool     // Runs until the request is cancelled
+	tokens  int      // Reported as used before blocking
+	prompts []string // The prompts answered
+}
+
+func (f *fakeLLM) Generate(ctx context.Context, prompt *llm.Prompt, _ ...llm.GenerateOption) (string, error) {
+	if !f.blocks {
+		f.prompts = append(f.prompts, prompt.Input)
+		return f.answer, nil
+	}
+	if recorder, _ := ctx.Value(usageRecorderKey{}).(*usageRecorder); recorder != nil && f.tokens > 0 {
+		recorder.add(f.tokens/2, f.tokens/2)
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// moaWithFakes returns a service whose two MOA agents are agent, as run in
+// judge mode, and whose delegator answers for the MOA primary model with
+// single.
+func moaWithFakes(agent, single *fakeLLM) *InferenceService {
+	primary := LLMAttempt{Instance: agent, Config: defaultModelConfigs[0]}
+	fallback := LLMAttempt{Instance: agent, Config: defaultModelConfigs[2]}
+	s := NewInferenceService()
+	s.primaryAttempts = []LLMAttempt{primary}
+	s.fallbackAttempts = []LLMAttempt{fallback}
+	s.moaPrimaryModelName = defaultModelConfigs[0].ModelName
+	s.moaFallbackModelName = defaultModelConfigs[2].ModelName
+	s.moa = &gollm.MOA{} // Not run in judge mode
+	delegated := LLMAttempt{Instance: single, Config: defaultModelConfigs[0]}
+	s.delegator = NewDelegatorService([]LLMAttempt{delegated}, []LLMAttempt{delegated}, 100000, "gpt-4", nil, nil)
+	return s
+}
+
+func TestOnTokensOver(t *testing.T) {
+	ctx, recorder := withUsageRecorder(context.Background())
+	calls := 0
+	onTokensOver(ctx, 100, func() { calls++ })
+	recorder.add(60, 30)
+	if calls != 0 {
+		t.Errorf("Expected no call under the limit")
+	}
+	recorder.add(20, 0)
+	recorder.add(50, 50)
+	if calls != 1 {
+		t.Errorf("Expected one call once over the limit, got %d", calls)
+	}
+}
+
+func TestMOADowngradesOverTokenLimit(t *testing.T) {
+	useTestSettings(t)
+	setPref(t, PrefMOAMaxTokens, 1000)
+	s := NewInferenceService()
+	s.primaryAttempts = []LLMAttempt{{Config: defaultModelConfigs[0]}}
+	s.fallbackAttempts = []LLMAttempt{{Config: defaultModelConfigs[2]}}
+	s.moaPrimaryModelName = "llama-4-scout-17b-16e-instruct"
+	s.moaFallbackModelName = "deepseek-chat"
+	s.moa = &gollm.MOA{} // Never run: the estimate is over the limit
+	var downgrades []MOADowngrade
+	s.OnMOADowngrade(func(d MOADowngrade) { downgrades = append(downgrades, d) })
+
+	_, _, err := s.generateMOA(context.Background(), "Write about coffee", GenerateOptions{UseMOA: true})
+	if err == nil || !strings.Contains(err.Error(), "over the limit of 1000") || !strings.Contains(err.Error(), "no single model") {
+		t.Errorf("Expected the job to stop at the limit without a model to fall back on, got %v", err)
+	}
+	if len(downgrades) != 0 {
+		t.Errorf("Expected no downgrade to be reported without a model to fall back on, got %+v", downgrades)
+	}
+}
+
+func TestMOAFallsBackToPrimaryModelOverLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxTokens  int
+		maxSeconds int
+		tokens     int // Reported by each MOA agent
+		reason     string
+	}{
+		{"token limit", 50000, 0, 40000, "over the limit of 50000"},
+		{"time limit", 0, 1, 0, "the time limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestSettings(t)
+			setPref(t, PrefMOAJudge, true)
+			setPref(t, PrefMOAMaxTokens, tt.maxTokens)
+			setPref(t, PrefMOAMaxSeconds, tt.maxSeconds)
+			agent := &fakeLLM{blocks: true, tokens: tt.tokens}
+			single := &fakeLLM{answer: "<p>The primary model's answer.</p>"}
+			s := moaWithFakes(agent, single)
+			var downgrades []MOADowngrade
+			s.OnMOADowngrade(func(d MOADowngrade) { downgrades = append(downgrades, d) })
+
+			answer, usage, err := s.generateMOA(context.Background(), "Write about coffee", GenerateOptions{UseMOA: true})
+			if err != nil {
+				t.Fatalf("generateMOA failed: %v", err)
+			}
+			if answer != "<p>The primary model's answer.</p>" {
+				t.Errorf("answer = %q, want the primary model's", answer)
+			}
+			if len(single.prompts) != 1 || !strings.Contains(single.prompts[0], "Write about coffee") {
+				t.Errorf("Expected the prompt to go to the primary model once, got %q", single.prompts)
+			}
+			if len(downgrades) != 1 || downgrades[0].Model != defaultModelConfigs[0].ModelName || !strings.Contains(downgrades[0].Reason, tt.reason) {
+				t.Errorf("Expected one downgrade to the primary model for %q, got %+v", tt.reason, downgrades)
+			}
+			if usage.Total() < tt.tokens {
+				t.Errorf("usage = %d tokens, want the %d the MOA job spent counted", usage.Total(), tt.tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+	"Inference_Engine/settings"
+	"Inference_Engine/tracing"
+
+	"github.com/teilomillet/gollm/llm"
+)
+
+// PrefMOAJudge replaces the MOA aggregation with a judge that scores each
+// agent's answer and writes the final answer from the best parts.
+var PrefMOAJudge = settings.NewKey("moa.judge", false)
+
+// judgeAnswerMarker separates the judge's scores from its final answer.
+const judgeAnswerMarker = "---ANSWER---"
+
+// JudgeScore is the judge's verdict on one agent's answer.
+type JudgeScore struct {
+	Candidate int     `json:"candidate"` // 1-based, in the order the agents are listed
+	Score     float64 `json:"score"`     // 0 to 10
+	Reason    string  `json:"reason"`
+}
+
+// parseJudgeVerdict reads the judge's answer: a JSON array of scores, the
+// answer marker, then the final answer. The scores are nil when they can't
+// be read; the answer is empty without the marker.
+func parseJudgeVerdict(output string) ([]JudgeScore, string) {
+	head, answer, found := strings.Cut(output, judgeAnswerMarker)
+	if !found {
+		head, answer = output, ""
+	}
+	var scores []JudgeScore
+	if start, end := strings.Index(head, "["), strings.LastIndex(head, "]"); start >= 0 && end > start {
+		if err := json.Unmarshal([]byte(head[start:end+1]), &scores); err != nil {
+			logger.Warn("MOA judge: couldn't read the scores", "error", err)
+			scores = nil
+		}
+	}
+	return scores, strings.TrimSpace(answer)
+}
+
+// judgedMOA runs the MOA agents on prompt side by side, then has the
+// aggregator model judge their answers against the request and sources and
+// write the final answer, logging each score. Without a usable verdict the
+// best-scored answer, or else the first, is kept.
+func (s *InferenceService) judgedMOA(ctx context.Context, prompt string) (string, error) {
+	s.mutex.Lock()
+	primary, fallback := s.findAttempt("", s.moaPrimaryModelName), s.findAttempt("", s.moaFallbackModelName)
+	s.mutex.Unlock()
+	if primary == nil || fallback == nil || primary.Instance == nil || fallback.Instance == nil {
+		return "", errors.New("MOA (Mixture of Agents) is not configured")
+	}
+	agents := []*LLMAttempt{primary, fallback}
+
+	answers := make([]string, len(agents))
+	failures := make([]error, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer crash.Recover("InferenceService.judgedMOA")
+			attemptCtx, span := startAttemptSpan(ctx, agent.Config)
+			answers[i], failures[i] = agent.Instance.Generate(attemptCtx, llm.NewPrompt(prompt))
+			tracing.End(span, failures[i])
+		}()
+	}
+	wg.Wait()
+	var candidates []int // Indexes of the agents that answered
+	for i, err := range failures {
+		if err != nil || strings.TrimSpace(answers[i]) == "" {
+			logger.Warn("MOA judge: agent gave no answer", logging.Model(agents[i].Config.ModelName), "error", err)
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no MOA agent answered: %w", errors.Join(failures...))
+	case 1:
+		logger.Info("MOA judge: only one agent answered, keeping its answer", logging.Model(agents[candidates[0]].Config.ModelName))
+		return answers[candidates[0]], nil
+	}
+
+	var listed strings.Builder
+	for n, i := range candidates {
+		fmt.Fprintf(&listed, "Candidate %d:\n%s\n\n", n+1, strings.TrimSpace(answers[i]))
+	}
+	attemptCtx, span := startAttemptSpan(ctx, fallback.Config)
+	verdict, err := fallback.Instance.Generate(attemptCtx, llm.NewPrompt(GetMOAJudgePrompt(prompt, listed.String())))
+	tracing.End(span, err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		logger.Warn("MOA judge failed, keeping the first answer", logging.Model(fallback.Config.ModelName), "error", err)
+		return answers[candidates[0]], nil
+	}
+	scores, answer := parseJudgeVerdict(verdict)
+	for _, score := range scores {
+		if score.Candidate < 1 || score.Candidate > len(candidates) {
+			continue
+		}
+		logger.Info("MOA judge scored an answer", "candidate", score.Candidate, logging.Model(agents[candidates[score.Candidate-1]].Config.ModelName), "score", score.Score, "reason", score.Reason)
+	}
+	if answer != "" {
+		logger.Info("MOA judge wrote the final answer", logging.Model(fallback.Config.ModelName), "candidates", len(candidates))
+		return answer, nil
+	}
+	sort.SliceStable(scores, func(a, b int) bool { return scores[a].Score > scores[b].Score })
+	for _, score := range scores {
+		if score.Candidate >= 1 && score.Candidate <= len(candidates) {
+			logger.Warn("MOA judge wrote no final answer, keeping the best-scored one", "candidate", score.Candidate)
+			return answers[candidates[score.Candidate-1]], nil
+		}
+	}
+	logger.Warn("MOA judge gave no usable verdict, keeping the first answer")
+	return answers[candidates[0]], nil
+}
@@ -0,0 +1,24 @@
+package inference
+
+import (
+	"testing"
+)
+
+func TestParseJudgeVerdict(t *testing.T) {
+	output := "```json\n[{\"candidate\": 1, \"score\": 6, \"reason\": \"Misses the pricing.\"}, {\"candidate\": 2, \"score\": 8.5, \"reason\": \"Complete.\"}]\n```\n---ANSWER---\n<p>Final.</p>\n"
+	scores, answer := parseJudgeVerdict(output)
+	if len(scores) != 2 || scores[1].Candidate != 2 || scores[1].Score != 8.5 || scores[0].Reason != "Misses the pricing." {
+		t.Errorf("Unexpected scores %+v", scores)
+	}
+	if answer != "<p>Final.</p>" {
+		t.Errorf("answer = %q, want the text after the marker", answer)
+	}
+
+	scores, answer = parseJudgeVerdict(`[{"candidate": 1, "score": 7}]`)
+	if len(scores) != 1 || answer != "" {
+		t.Errorf("Expected scores without an answer, got %+v, %q", scores, answer)
+	}
+	if scores, answer = parseJudgeVerdict("I liked the second one.\n---ANSWER---\nText"); scores != nil || answer != "Text" {
+		t.Errorf("Expected an answer without scores, got %+v, %q", scores, answer)
+	}
+}
@@ -0,0 +1,160 @@
+package inference
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultModelConfigs lists the models Start tries to configure, in
+// delegation order. Models whose API key is missing are still registered, as
+// unavailable, so the UI can say why they can't be used.
+var defaultModelConfigs = []LLMAttemptConfig{
+	{ProviderName: "cerebras", ModelName: "llama-4-scout-17b-16e-instruct", APIKeyEnvVar: "CEREBRAS_API_KEY", MaxTokens: 4000, IsPrimary: true, JSONMode: true},
+	{ProviderName: "gemini", ModelName: "gemini-1.5-flash-latest", APIKeyEnvVar: "GEMINI_API_KEY", MaxTokens: 100000, IsPrimary: false},
+	{ProviderName: "deepseek", ModelName: "deepseek-chat", APIKeyEnvVar: "DEEPSEEK_API_KEY", MaxTokens: 8000, IsPrimary: false, JSONMode: true}, // Target for final chunking
+}
+
+// ModelInfo describes one model known to the service.
+type ModelInfo struct {
+	Provider  string
+	Model     string
+	Primary   bool // In the primary list; otherwise a fallback
+	MaxTokens int
+	Available bool   // An LLM instance was created for it
+	Problem   string // Why it isn't available, e.g. a missing API key
+}
+
+// ID returns the model's "provider/model" reference. GenerateOptions.Model
+// accepts it to route to one provider when several serve the same model.
+func (m ModelInfo) ID() string {
+	return modelID(m.Provider, m.Model)
+}
+
+// ID returns the attempt's "provider/model" reference.
+func (c LLMAttemptConfig) ID() string {
+	return modelID(c.ProviderName, c.ModelName)
+}
+
+func modelID(provider, model string) string {
+	return provider + "/" + model
+}
+
+// matchesModel reports whether ref names the model, either as a bare model
+// name or as a "provider/model" reference.
+func (c LLMAttemptConfig) matchesModel(ref string) bool {
+	return ref == c.ModelName || ref == c.ID()
+}
+
+// modelRegistry records every model Start tried to configure.
+type modelRegistry struct {
+	models []ModelInfo
+}
+
+func (r *modelRegistry) add(conf LLMAttemptConfig, problem string) {
+	r.models = append(r.models, ModelInfo{
+		Provider:  conf.ProviderName,
+		Model:     conf.ModelName,
+		Primary:   conf.IsPrimary,
+		MaxTokens: conf.MaxTokens,
+		Available: problem == "",
+		Problem:   problem,
+	})
+}
+
+// replace updates the entry for old to describe conf, now available.
+func (r *modelRegistry) replace(old, conf LLMAttemptConfig) {
+	for i, m := range r.models {
+		if m.ID() == old.ID() {
+			r.models[i] = ModelInfo{Provider: conf.ProviderName, Model: conf.ModelName, Primary: conf.IsPrimary, MaxTokens: conf.MaxTokens, Available: true}
+			return
+		}
+	}
+	r.add(conf, "")
+}
+
+// find returns the first model matching a bare name or "provider/model" ref.
+func (r *modelRegistry) find(ref string) (ModelInfo, bool) {
+	for _, m := range r.models {
+		if ref == m.Model || ref == m.ID() {
+			return m, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// Models returns every model the service knows about, available or not, in
+// delegation order (primary models first).
+func (s *InferenceService) Models() []ModelInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	models := make([]ModelInfo, 0, len(s.registry.models))
+	for _, m := range s.registry.models {
+		if m.Primary {
+			models = append(models, m)
+		}
+	}
+	for _, m := range s.registry.models {
+		if !m.Primary {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// Providers returns the names of the providers with registered models, sorted.
+func (s *InferenceService) Providers() []string {
+	seen := map[string]bool{}
+	var providers []string
+	for _, m := range s.Models() {
+		if !seen[m.Provider] {
+			seen[m.Provider] = true
+			providers = append(providers, m.Provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// ModelsForProvider returns the models registered for one provider.
+func (s *InferenceService) ModelsForProvider(provider string) []ModelInfo {
+	var models []ModelInfo
+	for _, m := range s.Models() {
+		if m.Provider == provider {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// checkModelRef returns an error explaining why ref can't be used, or nil if
+// it names an available model. The caller holds s.mutex.
+func (s *InferenceService) checkModelRef(ref string) error {
+	m, ok := s.registry.find(ref)
+	if !ok {
+		return fmt.Errorf("model '%s' is not configured", ref)
+	}
+	if !m.Available {
+		return fmt.Errorf("model '%s' is unavailable: %s", ref, m.Problem)
+	}
+	return nil
+}
+
+// checkProvider is checkModelRef for a provider, optionally narrowed to a
+// model. The caller holds s.mutex.
+func (s *InferenceService) checkProvider(provider, model string) error {
+	var problems []string
+	for _, m := range s.registry.models {
+		if m.Provider != provider || (model != "" && model != m.Model && model != m.ID()) {
+			continue
+		}
+		if m.Available {
+			return nil
+		}
+		problems = append(problems, m.Problem)
+	}
+	if len(problems) == 0 {
+		return fmt.Errorf("provider '%s' not found or not configured", provider)
+	}
+	return fmt.Errorf("provider '%s' is unavailable: %s", provider, strings.Join(problems, "; "))
+}
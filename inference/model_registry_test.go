@@ -0,0 +1,46 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelRegistry(t *testing.T) {
+	s := NewInferenceService()
+	s.registry.add(LLMAttemptConfig{ProviderName: "gemini", ModelName: "gemini-flash", IsPrimary: false}, "")
+	s.registry.add(LLMAttemptConfig{ProviderName: "cerebras", ModelName: "llama", IsPrimary: true}, "")
+	s.registry.add(LLMAttemptConfig{ProviderName: "deepseek", ModelName: "deepseek-chat", IsPrimary: false}, "DEEPSEEK_API_KEY is not set")
+
+	models := s.Models()
+	if len(models) != 3 || models[0].ID() != "cerebras/llama" {
+		t.Fatalf("Expected primary models first, got %+v", models)
+	}
+	if got := s.Providers(); strings.Join(got, ",") != "cerebras,deepseek,gemini" {
+		t.Errorf("Unexpected providers %v", got)
+	}
+	if got := s.ModelsForProvider("gemini"); len(got) != 1 || got[0].Model != "gemini-flash" {
+		t.Errorf("Unexpected gemini models %+v", got)
+	}
+
+	for _, ref := range []string{"llama", "cerebras/llama"} {
+		if err := s.checkModelRef(ref); err != nil {
+			t.Errorf("checkModelRef(%q) = %v, want nil", ref, err)
+		}
+	}
+	if err := s.checkModelRef("deepseek/deepseek-chat"); err == nil || !strings.Contains(err.Error(), "DEEPSEEK_API_KEY") {
+		t.Errorf("Expected the missing key to be reported, got %v", err)
+	}
+	if err := s.checkModelRef("gemini/llama"); err == nil {
+		t.Errorf("Expected an error for a model the provider doesn't serve")
+	}
+	if err := s.checkProvider("deepseek", ""); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("Expected deepseek to be unavailable, got %v", err)
+	}
+}
+
+func TestMatchesModel(t *testing.T) {
+	conf := LLMAttemptConfig{ProviderName: "gemini", ModelName: "gemini-flash"}
+	if !conf.matchesModel("gemini-flash") || !conf.matchesModel("gemini/gemini-flash") || conf.matchesModel("cerebras/gemini-flash") {
+		t.Errorf("matchesModel should accept the bare name and the provider/model reference only")
+	}
+}
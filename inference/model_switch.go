@@ -0,0 +1,155 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"Inference_Engine/logging"
+
+	"github.com/teilomillet/gollm"
+	"github.com/teilomillet/gollm/config"
+	"github.com/teilomillet/gollm/llm"
+)
+
+// validationTimeout bounds the test request sent to a model before switching to it.
+const validationTimeout = 30 * time.Second
+
+// buildAttempt creates the LLM instance described by conf.
+func buildAttempt(conf LLMAttemptConfig) (LLMAttempt, error) {
+	apiKey := os.Getenv(conf.APIKeyEnvVar)
+	if apiKey == "" {
+		return LLMAttempt{}, fmt.Errorf("%s is not set", conf.APIKeyEnvVar)
+	}
+	opts := []config.ConfigOption{
+		config.SetProvider(conf.ProviderName),
+		config.SetAPIKey(apiKey),
+		config.SetModel(conf.ModelName),
+		config.SetMaxTokens(conf.MaxTokens),
+	}
+	instance, err := gollm.NewLLM(opts...)
+	if err != nil {
+		return LLMAttempt{}, err
+	}
+	llmInstance, ok := instance.(llm.LLM)
+	if !ok {
+		return LLMAttempt{}, errors.New("the provider returned an unsupported instance")
+	}
+	return LLMAttempt{Instance: llmInstance, Config: conf, Opts: opts}, nil
+}
+
+// validateAttempt sends a tiny request to check the model exists and the key
+// is accepted before it replaces a working one.
+func validateAttempt(ctx context.Context, attempt LLMAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, validationTimeout)
+	defer cancel()
+	_, err := attempt.Instance.Generate(ctx, llm.NewPrompt("Reply with the single word OK."))
+	return err
+}
+
+// SwitchModel replaces the configured model ref (a name or "provider/model")
+// with newModel from the same provider, keeping its role, key and token
+// limit. The new model is validated with a test request first; on success
+// the delegation lists, the registry and, if it used the old model, MOA are
+// updated together, and OnModelsChange listeners are called. Conversation
+// memory and the other models are left alone.
+func (s *InferenceService) SwitchModel(ctx context.Context, ref, newModel string) error {
+	newModel = strings.TrimSpace(newModel)
+	if newModel == "" {
+		return errors.New("new model name cannot be empty")
+	}
+
+	s.mutex.Lock()
+	if !s.isRunning {
+		s.mutex.Unlock()
+		return errors.New("inference service is not running")
+	}
+	old := s.findAttempt("", ref)
+	s.mutex.Unlock()
+	if old == nil {
+		return fmt.Errorf("model '%s' is not configured", ref)
+	}
+	if old.Config.ModelName == newModel {
+		return nil
+	}
+
+	conf := old.Config
+	conf.ModelName = newModel
+	switchLog := logger.With("provider", conf.ProviderName, "from", old.Config.ModelName, logging.Model(newModel))
+	switchLog.Info("Validating model before switching")
+	attempt, err := buildAttempt(conf)
+	if err == nil {
+		err = validateAttempt(ctx, attempt)
+	}
+	if err != nil {
+		switchLog.Warn("Model switch rejected", "error", err)
+		return fmt.Errorf("model '%s' failed validation: %w", conf.ID(), err)
+	}
+
+	s.mutex.Lock()
+	replaced := replaceAttempt(&s.primaryAttempts, old.Config, attempt) || replaceAttempt(&s.fallbackAttempts, old.Config, attempt)
+	if !replaced {
+		s.mutex.Unlock()
+		return fmt.Errorf("model '%s' was removed while switching", ref)
+	}
+	s.registry.replace(old.Config, conf)
+	moaChanged := false
+	if s.moaPrimaryModelName == old.Config.ModelName {
+		s.moaPrimaryModelName, s.moaPrimaryOpts = newModel, attempt.Opts
+		moaChanged = true
+	}
+	if s.moaFallbackModelName == old.Config.ModelName {
+		s.moaFallbackModelName, s.moaFallbackOpts = newModel, attempt.Opts
+		moaChanged = true
+	}
+	var moaErr error
+	if moaChanged {
+		moaErr = s.reconfigureMOAInternal()
+	}
+	if s.delegator != nil {
+		s.delegator.UpdateAttempts(s.primaryAttempts, s.fallbackAttempts)
+	}
+	s.mutex.Unlock()
+
+	switchLog.Info("Switched model")
+	s.notifyModelsChanged()
+	if moaErr != nil {
+		return fmt.Errorf("switched to '%s', but MOA could not be reconfigured: %w", conf.ID(), moaErr)
+	}
+	return nil
+}
+
+// replaceAttempt swaps the attempt configured as old in *attempts with
+// attempt. The slice is copied so requests holding the old list are unaffected.
+func replaceAttempt(attempts *[]LLMAttempt, old LLMAttemptConfig, attempt LLMAttempt) bool {
+	for i, a := range *attempts {
+		if a.Config == old {
+			updated := append([]LLMAttempt(nil), (*attempts)...)
+			updated[i] = attempt
+			*attempts = updated
+			return true
+		}
+	}
+	return false
+}
+
+// OnModelsChange registers a listener called (from any goroutine) after the
+// service starts or a model or MOA default changes.
+func (s *InferenceService) OnModelsChange(listener func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.modelListeners = append(s.modelListeners, listener)
+}
+
+// notifyModelsChanged calls the listeners. Must be called without the mutex held.
+func (s *InferenceService) notifyModelsChanged() {
+	s.mutex.Lock()
+	listeners := append([]func(){}, s.modelListeners...)
+	s.mutex.Unlock()
+	for _, listener := range listeners {
+		listener()
+	}
+}
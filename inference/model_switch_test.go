@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReplaceAttempt(t *testing.T) {
+	oldConf := LLMAttemptConfig{ProviderName: "gemini", ModelName: "gemini-flash"}
+	newConf := LLMAttemptConfig{ProviderName: "gemini", ModelName: "gemini-pro"}
+	attempts := []LLMAttempt{{Config: LLMAttemptConfig{ProviderName: "deepseek", ModelName: "deepseek-chat"}}, {Config: oldConf}}
+	held := attempts // A request in flight keeps using this list
+
+	if !replaceAttempt(&attempts, oldConf, LLMAttempt{Config: newConf}) {
+		t.Fatalf("Expected the attempt to be replaced")
+	}
+	if attempts[1].Config != newConf || held[1].Config != oldConf {
+		t.Errorf("Expected a replaced copy and an untouched original, got %+v and %+v", attempts, held)
+	}
+	if replaceAttempt(&attempts, oldConf, LLMAttempt{Config: newConf}) {
+		t.Errorf("Expected no match for a model that was already replaced")
+	}
+}
+
+func TestSwitchModelRequiresRunningService(t *testing.T) {
+	s := NewInferenceService()
+	notified := 0
+	s.OnModelsChange(func() { notified++ })
+
+	if err := s.SwitchModel(context.Background(), "gemini-flash", ""); err == nil {
+		t.Errorf("Expected an error for an empty model name")
+	}
+	if err := s.SwitchModel(context.Background(), "gemini-flash", "gemini-pro"); err == nil {
+		t.Errorf("Expected an error while the service is stopped")
+	}
+	if notified != 0 {
+		t.Errorf("Listeners should not be called when nothing changed, got %d calls", notified)
+	}
+}
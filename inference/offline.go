@@ -0,0 +1,55 @@
+package inference
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"sync/atomic"
+)
+
+// ErrNoLocalModel is returned by generation offline when no provider is
+// routed to a server on this machine.
+var ErrNoLocalModel = errors.New("offline: no local model is configured; point a provider's base URL at a server on this machine (e.g. http://localhost:11434/v1) to generate offline")
+
+var offline atomic.Bool
+
+// SetOffline switches offline mode, in which generation only uses the
+// providers routed to a server on this machine.
+func SetOffline(on bool) {
+	offline.Store(on)
+	logger.Info("Offline mode changed", "offline", on)
+}
+
+// Offline reports whether generation is limited to local models.
+func Offline() bool {
+	return offline.Load()
+}
+
+// isLocal reports whether the provider's base URL points at this machine.
+func isLocal(provider string) bool {
+	base := ProviderOverrideFor(provider).BaseURL
+	if base == "" {
+		return false
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// localOnly keeps the attempts whose provider is local, in order.
+func localOnly(attempts []LLMAttempt) []LLMAttempt {
+	var local []LLMAttempt
+	for _, attempt := range attempts {
+		if isLocal(attempt.Config.ProviderName) {
+			local = append(local, attempt)
+		}
+	}
+	return local
+}
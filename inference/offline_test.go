@@ -0,0 +1,24 @@
+package inference
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocalOnly(t *testing.T) {
+	t.Setenv("DEEPSEEK_BASE_URL", "http://127.0.0.1:11434/v1")
+	t.Setenv("GEMINI_BASE_URL", "http://localhost:8080/v1beta/")
+	t.Setenv("CEREBRAS_BASE_URL", "https://gateway.example.com/v1")
+	attempts := []LLMAttempt{
+		{Config: LLMAttemptConfig{ProviderName: "cerebras"}},
+		{Config: LLMAttemptConfig{ProviderName: "gemini"}},
+		{Config: LLMAttemptConfig{ProviderName: "deepseek"}},
+	}
+	var local []string
+	for _, attempt := range localOnly(attempts) {
+		local = append(local, attempt.Config.ProviderName)
+	}
+	if fmt.Sprint(local) != "[gemini deepseek]" {
+		t.Errorf("localOnly = %v, want [gemini deepseek]", local)
+	}
+}
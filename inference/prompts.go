@@ -69,6 +69,301 @@ Based *only* on the information provided in the **True Sources**, generate new c
 5.  If there are no True Sources, inform the user that factual content cannot be generated without them.
 6.  Return only the generated content, ready for use, without any explanations, metadata, or introductory/concluding remarks about the process.
 `
+
+	BrandVoiceAnalysisPrompt = `Analyze the writing style of the following samples from one brand:
+
+%s
+
+Describe the brand's voice so another writer could reproduce it without seeing the samples. Cover:
+1. Tone and attitude (e.g. formal, playful, authoritative)
+2. How the reader is addressed
+3. Sentence and paragraph rhythm
+4. Typical structure and formatting habits
+5. Words or phrases the brand favors or avoids
+
+Return 4 to 8 short bullet points starting with "- ", and nothing else.`
+
+	SampleStylePrompt = `The following pages are style samples for an article to be written from other sources:
+
+%s
+
+Describe how they are written so the article can match them without seeing them: tone, how the reader is addressed, sentence and paragraph length, headings, lists and other formatting, and typical openings and endings. Describe the style only, not the topics or facts.
+
+Return 5 to 10 short bullet points starting with "- ", under 150 words in all, and nothing else.`
+
+	SyndicationVariantPrompt = `Rewrite the article below for publication on the site "%s". The same article is syndicated to several sites, and search engines penalize near-duplicates, so this version must read as a distinct article.
+%s
+Requirements:
+1. Keep every fact, figure, name, quote and link, and don't add new facts
+2. Write a new title, opening and conclusion
+3. Reorder and regroup the sections and rename the headings where the content allows
+4. Reword every sentence; don't reuse distinctive phrases from the original
+5. Keep roughly the original length and its formatting (Markdown or HTML)
+%s
+Return only the rewritten article, and nothing else.
+
+Article:
+%s`
+
+	FAQGenerationPrompt = `Write a Frequently Asked Questions section for the following page:
+
+%s
+
+Requirements:
+1. Ask 3 to 6 questions a reader of this page would search for, in their words
+2. Answer each in 1 to 3 plain-text sentences, using only facts stated on the page
+3. Do not repeat a question, and skip questions the page can't answer
+
+Return only a JSON array of objects with "question" and "answer" fields, and nothing else.`
+
+	SocialPostsPrompt = `Write social media posts promoting the following article:
+
+%s
+
+Write:
+1. "x_thread": an X (Twitter) thread of 3 to 6 posts, each under 280 characters; the first hooks the reader and the last invites them to read the article
+2. "linkedin": a LinkedIn post of 100 to 200 words in a professional tone, ending with a question for discussion
+3. "facebook": a friendly Facebook blurb of 40 to 80 words
+
+Use only facts from the article, at most 2 hashtags per post, and no placeholder links.
+Return only a JSON object with the fields "x_thread" (an array of strings), "linkedin" and "facebook", and nothing else.`
+
+	FactSheetPrompt = `Extract a fact sheet from the following sources, for a writer who must not misquote them:
+
+%s
+
+List:
+1. "quotes": passages worth quoting, copied word for word, with "text", "speaker" (who said or wrote it, if stated) and "source" (the source title)
+2. "statistics": every figure that matters (numbers, percentages, amounts, dates of studies), with "value", "context" (what it measures, when and where) and "source"
+3. "entities": the people, organizations, products and places named, with "name" spelled as in the source, "type" and "source"
+
+Include only what the sources state. Return only a JSON object with the fields "quotes", "statistics" and "entities" (arrays of objects), and nothing else.`
+
+	InterviewArticlePrompt = `Turn the interview transcript below into an article written in the third person, with a headline, an introduction that presents the speakers and subheadings.
+
+Speakers: %s
+
+Requirements:
+1. Attribute every quote and every paraphrased view to the speaker who gave it, by name; never put words in another speaker's mouth
+2. Quote speakers word for word inside quotation marks; paraphrase only outside them
+3. Lead with the most newsworthy or useful point, not with the first question
+4. Leave out filler, false starts and small talk%s
+
+Return only the article, in Markdown.
+
+Transcript:
+%s`
+
+	InterviewQASectionRequirement = `
+5. End with a "Q&A" section reproducing the most important exchanges, each question in bold followed by the answer, lightly edited for readability but with the speakers' words kept`
+
+	SeriesPlanPrompt = `The page below is too long for one read. Propose splitting it into a series of 2 to %d posts that each stand on their own, in the order a reader should follow them.
+
+For the series give "series_title", and for each part in "parts": "title" (a post title of its own, not "Part 1"), "summary" (one sentence on what it covers) and "covers" (the page's headings or topics it takes over). Together the parts must cover everything on the page, each topic once.
+
+Return only a JSON object with the fields "series_title" and "parts", and nothing else.
+
+Page:
+%s`
+
+	SeriesPartPrompt = `Write part %d of %d of a series split from the page below, following the plan.
+
+Plan:
+%s
+
+Requirements:
+1. Cover only what the plan gives this part, using the page's facts, examples and wording where they fit
+2. Open with a short introduction that works for readers who haven't read the other parts, and end with a sentence leading to the next part if there is one
+3. Don't add series navigation or links to the other parts; they are added afterwards
+
+Return only the post, in the same format (HTML for WordPress, or Markdown) as the page.
+
+Page:
+%s`
+
+	NewsletterPrompt = `Turn the following post into an email newsletter:
+
+%s
+
+Requirements:
+1. Keep it to about half the post's length, at most 400 words, leading with what matters most to the reader
+2. Use a plain structure: short paragraphs, at most 3 subheadings and simple lists, no tables or images
+3. Write a subject line under 60 characters and a preheader under 100 characters that complements it
+4. End with a sentence leading into a call to action; the button itself is added separately
+
+Return only a JSON object with the fields "subject", "preheader" and "body", where "body" is simple HTML using only <h2>, <p>, <ul>, <ol>, <li>, <a> and <strong>, and nothing else.`
+
+	SchemaExtractionPrompt = `Extract schema.org %s properties from the page below.
+
+Properties to extract:
+%s
+
+Page:
+%s
+
+Use only facts stated on the page, and leave out properties it doesn't state rather than guessing. Return only a JSON object with those property names as keys, and nothing else.`
+
+	FreshnessPrompt = `Today is %s. The page below was last updated on %s. Find the statements in it that are likely outdated by now: past years presented as current, software or product versions, prices, statistics, "latest" or "new" claims, and events described as upcoming.
+
+Statements worth checking first:
+%s
+
+Page:
+%s
+
+Return only a JSON object, and nothing else, with these keys:
+- "score": 0 if the page is still current, up to 100 if most of it is outdated
+- "summary": one or two sentences on what needs refreshing
+- "references": an array of {"text", "reason", "suggestion"} objects, where "text" is quoted exactly from the page`
+
+	MergePagesPrompt = `The following pages from one website cover essentially the same topic and compete with each other in search results. Merge them into one comprehensive article that replaces all of them.
+
+Instructions:
+1.  Keep every fact, example and piece of advice found in any of the pages; remove repetition.
+2.  Where the pages contradict each other, prefer the most recently modified one.
+3.  Give the article a clear structure with headings, and keep the pages' HTML formatting style for WordPress.
+4.  Return only the merged article, starting with its title as an <h1>.
+
+Pages:
+%s`
+
+	MetaDescriptionPrompt = `Write the meta description search engines will show for the page "%s" below: one or two sentences, 120 to 155 characters, that summarize what the reader gets from the page. Return only the description, with no quotes or labels.
+
+Page:
+%s`
+
+	PageTitlePrompt = `The page titled "%s" shares its title with other pages on the same site. Write a new title, under 60 characters, that describes what sets this page apart. Return only the title, with no quotes or labels.
+
+Page:
+%s`
+
+	PageHeadingPrompt = `Write the main (H1) heading for the page titled "%s" below: short, descriptive and in the same language as the page. Return only the heading, with no quotes or labels.
+
+Page:
+%s`
+
+	AltTextPrompt = `Write alt text for these images on the page titled "%s". Each image's alt text should describe what it most likely shows in under 125 characters, judging from its file name and the page content; leave out "image of".
+
+Images:
+%s
+
+Page:
+%s
+
+Return only a JSON object, and nothing else, mapping each image URL exactly as listed to its alt text.`
+
+	MediaTextPrompt = `Look at this image from a website's media library, titled "%s". Write:
+- alt_text: what the image shows, for readers who cannot see it, in under 125 characters; leave out "image of" and don't guess at names you can't read in the image;
+- caption: one short sentence to show under the image.
+Write both in the language of the title, or in English if it has none.
+
+Return only a JSON object, and nothing else, with the keys "alt_text" and "caption".`
+
+	ArticlePlanPrompt = `Plan one article that targets all of the search keywords below, which searchers use for the same topic. The first keyword is the most representative.
+
+The site's most visited pages are listed below with their views over the last 28 days. Don't repeat their topics; plan links to them where they fit, and take their subjects as a hint of what this audience reads.
+%s
+
+Return only a JSON object, and nothing else, with these keys:
+- "title": the article's title, under 60 characters
+- "target_keyword": the keyword the article should rank for first
+- "secondary_keywords": an array of the other keywords to work in
+- "audience": who is searching, in one sentence
+- "outline": an array of the article's section headings, in order
+- "word_count": the length the topic needs, as a number
+- "notes": the search intent to satisfy and anything the writer should know
+
+Keywords:
+%s`
+
+	CompetitorGapPrompt = `Compare my page with a competitor's page on the same topic. Find the subtopics, questions, examples and data the competitor covers that my page is missing or covers in less depth, and what my page does better.
+
+Return only a JSON object, and nothing else, with these keys:
+- "summary": two or three sentences on how the pages compare
+- "missing": an array of {"subtopic", "detail"} objects for what only the competitor covers, where "detail" says what it covers
+- "weaker": the same for subtopics both cover, where the competitor goes deeper
+- "strengths": an array of short strings on what my page does better
+
+My page, "%s":
+%s
+
+Competitor's page (%s):
+%s`
+
+	CompetitorDraftPrompt = `Improve my page below so it covers the gaps a comparison with a competitor found. Add sections or paragraphs for the missing subtopics and deepen the ones covered too briefly, in my page's own voice and structure. Keep everything that is already there and accurate. Don't copy the competitor's wording, and leave out claims you cannot support.
+
+Gaps:
+%s
+
+Return only the improved page, in the same format (HTML for WordPress, or Markdown) as my page.
+
+My page:
+%s`
+
+	SearchQueriesPrompt = `The page already appears in Google search results for the queries below. Keep every one of them answered, and strengthen the coverage of those with many impressions but few clicks or a position beyond 10, without stuffing keywords.
+
+Search queries:
+%s`
+
+	CommentDigestPrompt = `Summarize what readers say in these comments on the page titled "%s", so the page can be updated to answer them. List the recurring themes as short bullet points, most common first: what confuses readers, questions the page leaves open, mistakes or outdated details they point out, and what they ask to see covered. Mention how many comments raise each theme, e.g. "(4 comments)". Ignore spam, thanks and off-topic remarks, and don't quote commenters by name. If nothing in them calls for a change, say so in one line.
+
+Comments, newest first:
+%s`
+
+	ReaderFeedbackPrompt = `Readers' comments on the page raise the points below. Address the ones the page should answer, in the text itself, without mentioning the comments or the readers.
+
+Reader feedback:
+%s`
+
+	SelectionEditPrompt = `%s
+
+Return only the edited passage, in the same format (HTML for WordPress, or Markdown) as the passage and without any explanation, so it can replace the passage where it stands. Keep it consistent with the text around it.
+
+Text before the passage:
+%s
+
+Text after the passage:
+%s
+
+Passage:
+%s`
+
+	SourceNotesInstruction = `You are preparing research notes for an article; the source material is too long to send in one piece, so it comes in sections. The article's request is:
+%s
+
+From the section of source material below, list every fact, figure, name, date, quote and claim relevant to the request as concise bullet points, in the section's order. Keep each "Source Title:" and "Source Number:" line before the notes taken from that source. Don't write the article, don't add anything that isn't in the section, and answer "No relevant content." if nothing in it is.`
+
+	MOAJudgePrompt = `Several writers answered the request below. Judge each candidate answer against the request and its sources: how fully it does what was asked, whether every claim is supported by the sources, and how clear and well organized it is.
+
+First, return a JSON array with one object per candidate, in order, with the keys "candidate" (its number), "score" (0 to 10) and "reason" (one short sentence). Then write a line containing only ---ANSWER--- and, after it, the final answer: the best candidate, improved with the best parts of the others where they add something supported by the sources. Write the final answer in the format the request asks for, without any comment about the candidates.
+
+Request and sources:
+%s
+
+Candidate answers:
+%s`
+
+	SelectionRewriteInstruction   = "Rewrite the passage below in clearer, more engaging words, keeping its meaning and roughly its length."
+	SelectionShortenInstruction   = "Shorten the passage below to about half its length, keeping its key points."
+	SelectionTranslateInstruction = "Translate the passage below into %s, keeping its formatting, links and names."
+
+	TaxonomySuggestionPrompt = `Choose the categories and tags for the post below, to be published on a WordPress site.
+
+The site's categories:
+%s
+
+The site's tags:
+%s
+
+Requirements:
+1. Pick 1 to 3 categories, only from the site's categories, that the post belongs in
+2. Pick the site's tags that describe what the post is about, if any; readers browse by them, so skip tags the post only mentions in passing
+3. Propose at most 5 new tags for topics the post covers that none of the site's tags name, each 1 to 3 words, in the style of the site's tags
+
+Return only a JSON object with the fields "categories", "tags" and "new_tags", each an array of names, and nothing else.
+
+Post:
+%s`
 )
 
 // WordPress Content Prompts
@@ -132,3 +427,207 @@ func GetWordPressContentGenerateWithSourcesPrompt(trueSourcesContent, sampleSour
 	}
 	return formatPrompt(WordPressContentGenerateWithSourcesPrompt, trueSourcesContent, sampleSourcesContent, userRequest)
 }
+
+// GetBrandVoiceAnalysisPrompt asks for a description of the samples' voice.
+func GetBrandVoiceAnalysisPrompt(samples string) string {
+	return formatPrompt(BrandVoiceAnalysisPrompt, samples)
+}
+
+// GetSampleStylePrompt asks for a short description of the Sample sources'
+// style, sent instead of them.
+func GetSampleStylePrompt(samples string) string {
+	return formatPrompt(SampleStylePrompt, samples)
+}
+
+// GetSyndicationVariantPrompt asks for site's distinct version of article.
+// siteInstruction describes the site's audience and tone, if known; attempt
+// counts from 1, and later attempts ask for bigger changes.
+func GetSyndicationVariantPrompt(site, siteInstruction, article string, attempt int) string {
+	if siteInstruction != "" {
+		siteInstruction = "\nWrite for the site's readers:\n" + siteInstruction + "\n"
+	}
+	retry := ""
+	if attempt > 1 {
+		retry = "6. Earlier versions were still too close to the other sites' versions: change the structure, angle and wording much more\n"
+	}
+	return formatPrompt(SyndicationVariantPrompt, site, siteInstruction, retry, article)
+}
+
+// GetFAQGenerationPrompt asks for FAQ pairs, as JSON, derived from a page.
+func GetFAQGenerationPrompt(content string) string {
+	return formatPrompt(FAQGenerationPrompt, content)
+}
+
+// GetSchemaExtractionPrompt asks for a schema.org type's properties, as a
+// JSON object, extracted from a page.
+func GetSchemaExtractionPrompt(schemaType, fields, content string) string {
+	return formatPrompt(SchemaExtractionPrompt, schemaType, fields, content)
+}
+
+// GetFactSheetPrompt asks for the quotes, statistics and named entities of
+// sources, as JSON.
+func GetFactSheetPrompt(sources string) string {
+	return formatPrompt(FactSheetPrompt, sources)
+}
+
+// GetInterviewArticlePrompt asks for an article from an interview
+// transcript of "Speaker: text" turns, ending with a Q&A section if withQA.
+func GetInterviewArticlePrompt(speakers, transcript string, withQA bool) string {
+	qa := ""
+	if withQA {
+		qa = InterviewQASectionRequirement
+	}
+	return formatPrompt(InterviewArticlePrompt, speakers, qa, transcript)
+}
+
+// GetSeriesPlanPrompt asks for a plan, as JSON, splitting a long page into
+// a series of at most maxParts posts.
+func GetSeriesPlanPrompt(content string, maxParts int) string {
+	return formatPrompt(SeriesPlanPrompt, maxParts, content)
+}
+
+// GetSeriesPartPrompt asks for part n of total of a series, following the
+// plan's outline.
+func GetSeriesPartPrompt(n, total int, outline, content string) string {
+	return formatPrompt(SeriesPartPrompt, n, total, outline, content)
+}
+
+// GetSocialPostsPrompt asks for an X thread, LinkedIn post and Facebook
+// blurb, as JSON, promoting an article.
+func GetSocialPostsPrompt(article string) string {
+	return formatPrompt(SocialPostsPrompt, article)
+}
+
+// GetNewsletterPrompt asks for a newsletter version of a post, as JSON.
+func GetNewsletterPrompt(post string) string {
+	return formatPrompt(NewsletterPrompt, post)
+}
+
+// GetFreshnessPrompt asks for an outdatedness score and the stale references
+// of a page, as JSON. hints lists sentences with years, versions or prices.
+func GetFreshnessPrompt(today, modified, hints, content string) string {
+	if hints == "" {
+		hints = "(None found)"
+	}
+	return formatPrompt(FreshnessPrompt, today, modified, hints, content)
+}
+
+// GetMergePagesPrompt asks for one article combining pages on the same
+// topic. pages holds each page's title, modification date and content.
+func GetMergePagesPrompt(pages string) string {
+	return formatPrompt(MergePagesPrompt, pages)
+}
+
+// GetMetaDescriptionPrompt asks for a page's meta description.
+func GetMetaDescriptionPrompt(title, content string) string {
+	return formatPrompt(MetaDescriptionPrompt, title, content)
+}
+
+// GetPageTitlePrompt asks for a new title for a page whose title is used by
+// other pages too.
+func GetPageTitlePrompt(title, content string) string {
+	return formatPrompt(PageTitlePrompt, title, content)
+}
+
+// GetPageHeadingPrompt asks for a page's H1 heading.
+func GetPageHeadingPrompt(title, content string) string {
+	return formatPrompt(PageHeadingPrompt, title, content)
+}
+
+// GetAltTextPrompt asks for alt text for a page's images, as JSON. images
+// lists their URLs, one per line.
+func GetAltTextPrompt(title, images, content string) string {
+	return formatPrompt(AltTextPrompt, title, images, content)
+}
+
+// GetMediaTextPrompt asks a vision model for the alt text and caption of a
+// media library image, as JSON.
+func GetMediaTextPrompt(title string) string {
+	return formatPrompt(MediaTextPrompt, title)
+}
+
+// GetArticlePlanPrompt asks for an article plan, as a JSON content brief,
+// covering a keyword cluster. topPages lists the site's most visited pages
+// and keywords the cluster's keywords, one per line.
+func GetArticlePlanPrompt(topPages, keywords string) string {
+	if topPages == "" {
+		topPages = "(No analytics connected)"
+	}
+	return formatPrompt(ArticlePlanPrompt, topPages, keywords)
+}
+
+// GetSearchQueriesPrompt describes the search queries a page already ranks
+// for, listed one per line with their numbers, as an instruction.
+func GetSearchQueriesPrompt(queries string) string {
+	return formatPrompt(SearchQueriesPrompt, queries)
+}
+
+// WithSearchQueries puts the search queries a page ranks for ahead of an
+// improve, rewrite or expand prompt for it. prompt is returned unchanged if
+// there are no queries.
+func WithSearchQueries(prompt, queries string) string {
+	if queries == "" {
+		return prompt
+	}
+	return GetSearchQueriesPrompt(queries) + "\n\n" + prompt
+}
+
+// GetCommentDigestPrompt asks for a digest of the themes in a page's
+// comments, listed one per paragraph with their authors and dates.
+func GetCommentDigestPrompt(title, comments string) string {
+	return formatPrompt(CommentDigestPrompt, title, comments)
+}
+
+// WithReaderFeedback puts a digest of readers' comments ahead of an improve,
+// rewrite or expand prompt for the page. prompt is returned unchanged if
+// there is no digest.
+func WithReaderFeedback(prompt, digest string) string {
+	if digest == "" {
+		return prompt
+	}
+	return formatPrompt(ReaderFeedbackPrompt, digest) + "\n\n" + prompt
+}
+
+// GetSelectionEditPrompt asks for a passage of a page edited as instruction
+// says, with the text before and after it for context.
+func GetSelectionEditPrompt(instruction, before, passage, after string) string {
+	return formatPrompt(SelectionEditPrompt, instruction, before, after, passage)
+}
+
+// GetCompetitorGapPrompt asks for a gap analysis of a page against a
+// competitor's page, as JSON.
+func GetCompetitorGapPrompt(title, content, competitorURL, competitorContent string) string {
+	return formatPrompt(CompetitorGapPrompt, title, content, competitorURL, competitorContent)
+}
+
+// GetCompetitorDraftPrompt asks for the page improved to cover the gaps, one
+// per line, a competitor analysis found.
+func GetCompetitorDraftPrompt(gaps, content string) string {
+	return formatPrompt(CompetitorDraftPrompt, gaps, content)
+}
+
+// GetSourceNotesInstruction asks for notes on one section of oversized
+// source material, keeping what matters for request.
+func GetSourceNotesInstruction(request string) string {
+	return formatPrompt(SourceNotesInstruction, request)
+}
+
+// GetMOAJudgePrompt asks a judge model to score the MOA agents' answers to
+// request and write the final answer from the best parts. candidates holds
+// each answer under its number.
+func GetMOAJudgePrompt(request, candidates string) string {
+	return formatPrompt(MOAJudgePrompt, request, candidates)
+}
+
+// GetTaxonomySuggestionPrompt asks for the categories and tags, as JSON, a
+// post should be published under: the site's own, one name per line, and
+// new tag candidates.
+func GetTaxonomySuggestionPrompt(categories, tags, post string) string {
+	if categories == "" {
+		categories = "(None)"
+	}
+	if tags == "" {
+		tags = "(None)"
+	}
+	return formatPrompt(TaxonomySuggestionPrompt, categories, tags, post)
+}
@@ -0,0 +1,183 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"Inference_Engine/storage"
+)
+
+// providerOverridesDocument is the state database document holding the
+// overrides saved in the settings.
+const providerOverridesDocument = "provider_overrides"
+
+// organizationHeader carries the organization ID, as OpenAI-compatible APIs
+// and gateways expect it.
+const organizationHeader = "OpenAI-Organization"
+
+// ProviderOverride routes a provider through a proxy or gateway such as
+// Cloudflare AI Gateway or Helicone. Empty fields keep the provider's
+// defaults.
+type ProviderOverride struct {
+	BaseURL      string            `json:"base_url,omitempty"` // Replaces the API's base, e.g. "https://api.cerebras.ai/v1"
+	Headers      map[string]string `json:"headers,omitempty"`  // Added to every request
+	Organization string            `json:"organization,omitempty"`
+}
+
+// IsZero reports whether o changes nothing.
+func (o ProviderOverride) IsZero() bool {
+	return o.BaseURL == "" && len(o.Headers) == 0 && o.Organization == ""
+}
+
+// headers returns the extra headers to send, including the organization.
+func (o ProviderOverride) headers() map[string]string {
+	headers := make(map[string]string, len(o.Headers)+1)
+	for name, value := range o.Headers {
+		headers[name] = value
+	}
+	if o.Organization != "" {
+		headers[organizationHeader] = o.Organization
+	}
+	return headers
+}
+
+// endpoint joins the base URL, or defaultBase without one, with path.
+func (o ProviderOverride) endpoint(defaultBase, path string) string {
+	base := defaultBase
+	if o.BaseURL != "" {
+		base = o.BaseURL
+	}
+	return strings.TrimRight(base, "/") + path
+}
+
+var providerOverrides = struct {
+	sync.Mutex
+	saved map[string]ProviderOverride
+	db    *storage.DB
+}{saved: map[string]ProviderOverride{}}
+
+// ProviderOverrideFor returns the override for a provider: the one saved in
+// the settings, or else the one from <PROVIDER>_BASE_URL,
+// <PROVIDER>_EXTRA_HEADERS ("Name: value" pairs separated by ";" or new
+// lines) and <PROVIDER>_ORGANIZATION.
+func ProviderOverrideFor(provider string) ProviderOverride {
+	providerOverrides.Lock()
+	saved, ok := providerOverrides.saved[provider]
+	providerOverrides.Unlock()
+	if ok {
+		return saved
+	}
+	return providerOverrideFromEnv(provider)
+}
+
+// providerOverrideFromEnv reads a provider's override from the environment.
+func providerOverrideFromEnv(provider string) ProviderOverride {
+	prefix := strings.ToUpper(provider) + "_"
+	headers, err := ParseHeaders(os.Getenv(prefix + "EXTRA_HEADERS"))
+	if err != nil {
+		logger.Warn("Ignoring invalid extra headers", "provider", provider, "error", err)
+	}
+	return ProviderOverride{
+		BaseURL:      strings.TrimSpace(os.Getenv(prefix + "BASE_URL")),
+		Headers:      headers,
+		Organization: strings.TrimSpace(os.Getenv(prefix + "ORGANIZATION")),
+	}
+}
+
+// SetProviderOverride saves a provider's override, replacing the
+// environment's; a zero override goes back to the environment. Providers
+// pick it up when the inference service starts.
+func SetProviderOverride(provider string, o ProviderOverride) error {
+	providerOverrides.Lock()
+	defer providerOverrides.Unlock()
+	saved := make(map[string]ProviderOverride, len(providerOverrides.saved)+1)
+	for name, override := range providerOverrides.saved {
+		saved[name] = override
+	}
+	if o.IsZero() {
+		delete(saved, provider)
+	} else {
+		saved[provider] = o
+	}
+	if providerOverrides.db != nil {
+		data, err := json.Marshal(saved)
+		if err != nil {
+			return err
+		}
+		if err := providerOverrides.db.SetDocument(providerOverridesDocument, string(data)); err != nil {
+			return fmt.Errorf("failed to save the provider overrides: %w", err)
+		}
+	}
+	providerOverrides.saved = saved
+	logger.Info("Saved provider override", "provider", provider, "base_url", o.BaseURL, "headers", len(o.Headers))
+	return nil
+}
+
+// loadProviderOverrides reads the saved overrides from db and keeps later
+// changes there.
+func loadProviderOverrides(db *storage.DB) error {
+	text, ok, err := db.Document(providerOverridesDocument)
+	if err != nil {
+		return err
+	}
+	saved := map[string]ProviderOverride{}
+	if ok {
+		if err := json.Unmarshal([]byte(text), &saved); err != nil {
+			return fmt.Errorf("failed to read the provider overrides: %w", err)
+		}
+	}
+	providerOverrides.Lock()
+	defer providerOverrides.Unlock()
+	providerOverrides.saved, providerOverrides.db = saved, db
+	return nil
+}
+
+// ParseHeaders reads "Name: value" pairs, one per line or separated by ";".
+func ParseHeaders(text string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", line)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// FormatHeaders writes headers one "Name: value" per line, sorted by name.
+func FormatHeaders(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = name + ": " + headers[name]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Providers returns the names of the configured providers, in delegation
+// order.
+func Providers() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, conf := range defaultModelConfigs {
+		if !seen[conf.ProviderName] {
+			seen[conf.ProviderName] = true
+			names = append(names, conf.ProviderName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+package inference
+
+import (
+	"path/filepath"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestProviderOverrides(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	if err := loadProviderOverrides(db); err != nil {
+		t.Fatalf("loadProviderOverrides failed: %v", err)
+	}
+	defer loadProviderOverrides(db) // Leaves no saved overrides for other tests
+
+	t.Setenv("DEEPSEEK_BASE_URL", "https://gateway.example.com/v1/account/gateway/deepseek/")
+	t.Setenv("DEEPSEEK_EXTRA_HEADERS", "cf-aig-authorization: Bearer abc; X-Trace: on")
+	provider := NewDeepseekProvider("key", "", nil)
+	if got := provider.Endpoint(); got != "https://gateway.example.com/v1/account/gateway/deepseek/chat/completions" {
+		t.Errorf("Expected the gateway endpoint from the environment, got %s", got)
+	}
+	if headers := provider.Headers(); headers["cf-aig-authorization"] != "Bearer abc" || headers["X-Trace"] != "on" || headers["Authorization"] != "Bearer key" {
+		t.Errorf("Expected the extra headers with the API key, got %v", headers)
+	}
+
+	saved := ProviderOverride{BaseURL: "https://oai.helicone.ai/v1", Headers: map[string]string{"Helicone-Auth": "Bearer h"}, Organization: "org-1"}
+	if err := SetProviderOverride("cerebras", saved); err != nil {
+		t.Fatalf("SetProviderOverride failed: %v", err)
+	}
+	if err := loadProviderOverrides(db); err != nil {
+		t.Fatalf("loadProviderOverrides failed: %v", err)
+	}
+	provider = NewCerebrasProvider("key", "", nil)
+	if got := provider.Endpoint(); got != "https://oai.helicone.ai/v1/chat/completions" {
+		t.Errorf("Expected the saved endpoint after reloading, got %s", got)
+	}
+	if headers := provider.Headers(); headers["Helicone-Auth"] != "Bearer h" || headers[organizationHeader] != "org-1" {
+		t.Errorf("Expected the saved headers and organization, got %v", headers)
+	}
+
+	if err := SetProviderOverride("cerebras", ProviderOverride{}); err != nil {
+		t.Fatalf("SetProviderOverride failed: %v", err)
+	}
+	if got := NewCerebrasProvider("key", "", nil).Endpoint(); got != "https://api.cerebras.ai/v1/chat/completions" {
+		t.Errorf("Expected the default endpoint once the override is cleared, got %s", got)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("Helicone-Auth: Bearer x\n\nX-Cache: true")
+	if err != nil || len(headers) != 2 || headers["X-Cache"] != "true" {
+		t.Errorf("Unexpected headers %v (%v)", headers, err)
+	}
+	if FormatHeaders(headers) != "Helicone-Auth: Bearer x\nX-Cache: true" {
+		t.Errorf("Unexpected formatting %q", FormatHeaders(headers))
+	}
+	if _, err := ParseHeaders("no colon here"); err == nil {
+		t.Errorf("Expected a header without a colon to be rejected")
+	}
+}
@@ -0,0 +1,223 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+)
+
+// probeTimeout bounds one provider's probe.
+const probeTimeout = 10 * time.Second
+
+// ProviderStatus is the result of the last probe of a provider.
+type ProviderStatus struct {
+	Provider   string
+	Latency    time.Duration // Round trip of the probe request
+	StatusCode int           // HTTP status of the answer, 0 if there was none
+	Error      string        // Why the provider couldn't be reached
+	Checked    time.Time
+}
+
+// Reachable reports whether the provider answered the probe without an
+// error, so requests to it can be expected to work.
+func (s ProviderStatus) Reachable() bool {
+	return s.Error == "" && s.StatusCode > 0 && s.StatusCode < 400
+}
+
+// probeRequest builds the lightweight request that checks a provider: its
+// model list, which every provider serves without spending tokens.
+func probeRequest(ctx context.Context, provider, apiKey string) (*http.Request, error) {
+	override := ProviderOverrideFor(provider)
+	var url string
+	header := http.Header{}
+	switch provider {
+	case "cerebras":
+		url = override.endpoint("https://api.cerebras.ai/v1", "/models")
+		header.Set("Authorization", "Bearer "+apiKey)
+	case "deepseek":
+		url = override.endpoint("https://api.deepseek.com/v1", "/models")
+		header.Set("Authorization", "Bearer "+apiKey)
+	case "gemini":
+		base := os.Getenv("GEMINI_API_ENDPOINT")
+		if base == "" {
+			base = "https://generativelanguage.googleapis.com/v1beta/"
+		}
+		url = override.endpoint(base, "/models")
+		header.Set("x-goog-api-key", apiKey) // Not in the URL, which may be logged
+	default:
+		return nil, fmt.Errorf("no probe for provider '%s'", provider)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	for name, value := range override.headers() {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// probeProvider sends the probe request to a provider and times the answer.
+func probeProvider(ctx context.Context, client *http.Client, provider, apiKey string) ProviderStatus {
+	status := ProviderStatus{Provider: provider, Checked: time.Now()}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := probeRequest(ctx, provider, apiKey)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	status.Latency = time.Since(start)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	resp.Body.Close()
+	status.StatusCode = resp.StatusCode
+	return status
+}
+
+var providerStatuses = struct {
+	sync.Mutex
+	latest    map[string]ProviderStatus
+	listeners []func([]ProviderStatus)
+}{latest: map[string]ProviderStatus{}}
+
+// ProviderStatuses returns the last probe of each provider, by name.
+func ProviderStatuses() []ProviderStatus {
+	providerStatuses.Lock()
+	defer providerStatuses.Unlock()
+	statuses := make([]ProviderStatus, 0, len(providerStatuses.latest))
+	for _, status := range providerStatuses.latest {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
+
+// OnProviderStatus registers a listener called (from the probing goroutine)
+// with every provider's status after each probe.
+func OnProviderStatus(listener func([]ProviderStatus)) {
+	providerStatuses.Lock()
+	defer providerStatuses.Unlock()
+	providerStatuses.listeners = append(providerStatuses.listeners, listener)
+}
+
+// recordProviderStatuses keeps the results of a probe and tells the
+// listeners.
+func recordProviderStatuses(statuses []ProviderStatus) {
+	providerStatuses.Lock()
+	for _, status := range statuses {
+		providerStatuses.latest[status.Provider] = status
+	}
+	listeners := slices.Clone(providerStatuses.listeners)
+	providerStatuses.Unlock()
+	all := ProviderStatuses()
+	for _, listener := range listeners {
+		listener(all)
+	}
+}
+
+// ProbeProviders checks every configured provider at once and returns their
+// statuses, which latency-aware routing then uses.
+func (s *InferenceService) ProbeProviders(ctx context.Context) []ProviderStatus {
+	keys := map[string]string{}
+	s.mutex.Lock()
+	for _, attempt := range append(slices.Clone(s.primaryAttempts), s.fallbackAttempts...) {
+		keys[attempt.Config.ProviderName] = os.Getenv(attempt.Config.APIKeyEnvVar)
+	}
+	s.mutex.Unlock()
+
+	client := &http.Client{}
+	statuses := make([]ProviderStatus, 0, len(keys))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for provider, apiKey := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer crash.Recover("InferenceService.ProbeProviders")
+			status := probeProvider(ctx, client, provider, apiKey)
+			logger.Debug("Probed provider", "provider", provider, "latency", status.Latency, "status", status.StatusCode, "error", status.Error)
+			mu.Lock()
+			statuses = append(statuses, status)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	recordProviderStatuses(statuses)
+	return statuses
+}
+
+// StartProbing probes the providers now and then every interval until the
+// returned function is called.
+func (s *InferenceService) StartProbing(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	crash.Go("InferenceService.StartProbing", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.ProbeProviders(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return cancel
+}
+
+// orderByLatency sorts attempts for latency-aware routing: providers the
+// last probe reached, fastest first, then the ones it couldn't reach. The
+// configured order breaks ties, and providers not probed yet keep their
+// place at the front.
+func orderByLatency(attempts []LLMAttempt) []LLMAttempt {
+	providerStatuses.Lock()
+	latest := providerStatuses.latest
+	rank := func(attempt LLMAttempt) (bool, time.Duration) {
+		status, ok := latest[attempt.Config.ProviderName]
+		if !ok {
+			return false, 0
+		}
+		return !status.Reachable(), status.Latency
+	}
+	ordered := slices.Clone(attempts)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		downI, latencyI := rank(ordered[i])
+		downJ, latencyJ := rank(ordered[j])
+		if downI != downJ {
+			return downJ
+		}
+		return latencyI < latencyJ
+	})
+	providerStatuses.Unlock()
+	return ordered
+}
+
+// Summary describes the status briefly, e.g. "320 ms" or "HTTP 401".
+func (s ProviderStatus) Summary() string {
+	switch {
+	case s.Error != "":
+		return strings.TrimSpace(s.Error)
+	case !s.Reachable():
+		return fmt.Sprintf("HTTP %d", s.StatusCode)
+	default:
+		return fmt.Sprintf("%d ms", s.Latency.Milliseconds())
+	}
+}
@@ -0,0 +1,59 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeProvider(t *testing.T) {
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		if auth != "Bearer good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("DEEPSEEK_BASE_URL", server.URL+"/v1")
+
+	status := probeProvider(context.Background(), server.Client(), "deepseek", "good-key")
+	if !status.Reachable() || path != "/v1/models" {
+		t.Errorf("Expected the model list to be reached, got %+v at %s", status, path)
+	}
+	if status = probeProvider(context.Background(), server.Client(), "deepseek", "bad-key"); status.Reachable() || status.Summary() != "HTTP 401" {
+		t.Errorf("Expected a rejected key to count as unreachable, got %+v", status)
+	}
+	if status = probeProvider(context.Background(), server.Client(), "unknown", ""); status.Error == "" {
+		t.Errorf("Expected no probe for an unknown provider")
+	}
+}
+
+func TestOrderByLatency(t *testing.T) {
+	recordProviderStatuses([]ProviderStatus{
+		{Provider: "cerebras", StatusCode: 200, Latency: 300 * time.Millisecond},
+		{Provider: "gemini", StatusCode: 200, Latency: 100 * time.Millisecond},
+		{Provider: "deepseek", Error: "connection refused"},
+	})
+	defer func() {
+		providerStatuses.Lock()
+		providerStatuses.latest = map[string]ProviderStatus{}
+		providerStatuses.Unlock()
+	}()
+	attempts := []LLMAttempt{
+		{Config: LLMAttemptConfig{ProviderName: "deepseek"}},
+		{Config: LLMAttemptConfig{ProviderName: "cerebras"}},
+		{Config: LLMAttemptConfig{ProviderName: "local"}}, // Not probed
+		{Config: LLMAttemptConfig{ProviderName: "gemini"}},
+	}
+	var order []string
+	for _, attempt := range orderByLatency(attempts) {
+		order = append(order, attempt.Config.ProviderName)
+	}
+	if want := "local gemini cerebras deepseek"; fmt.Sprint(order) != "["+want+"]" {
+		t.Errorf("Order = %v, want [%s]", order, want)
+	}
+}
@@ -0,0 +1,141 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultSourceChunkTokens is the chunk size used when condensing sources
+// if ChunkingOptions leaves it unset.
+const defaultSourceChunkTokens = 3000
+
+// ChunkingOptions configures how source material too large for one request
+// is split and condensed before generation.
+type ChunkingOptions struct {
+	Strategy       ChunkingStrategy
+	Mode           ProcessingMode
+	MaxChunkTokens int    // Tokens per chunk; 0 uses defaultSourceChunkTokens
+	MapModel       string // Model for the per-chunk requests, e.g. a cheaper one; empty uses the request's model
+
+	// OnNotes, if set, is called in sequential mode with each chunk's notes
+	// as they finish (index counts from 1). Chunks with nothing relevant
+	// are reported with empty notes.
+	OnNotes func(index, total int, notes string)
+}
+
+// DefaultChunkingOptions keeps paragraphs whole in chunks of the default
+// size, processed in parallel.
+var DefaultChunkingOptions = ChunkingOptions{Strategy: ChunkByTokenCount, Mode: ParallelProcessing}
+
+// contextManager returns a ContextManager for one call with these options
+// and any extra ones.
+func (o ChunkingOptions) contextManager(extra ...ContextManagerOption) *ContextManager {
+	size := o.MaxChunkTokens
+	if size <= 0 {
+		size = defaultSourceChunkTokens
+	}
+	opts := append([]ContextManagerOption{WithProcessingMode(o.Mode), WithMaxChunkSize(size)}, extra...)
+	return NewContextManager(o.Strategy, opts...)
+}
+
+// generatorFunc adapts a function to TextGenerator.
+type generatorFunc func(ctx context.Context, prompt string) (string, error)
+
+// GenerateText calls f.
+func (f generatorFunc) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return f(ctx, prompt)
+}
+
+// PromptTokenLimit returns the most tokens a prompt for model should have:
+// the model's limit, or the smallest primary model's for delegated and MOA
+// requests (model "" or an unknown name). It returns 0 if no limit is known.
+func (s *InferenceService) PromptTokenLimit(model string) int {
+	limit := 0
+	for _, m := range s.Models() {
+		if m.MaxTokens <= 0 {
+			continue
+		}
+		if model != "" && (m.Model == model || m.ID() == model) {
+			return m.MaxTokens
+		}
+		if m.Primary && m.Available && (limit == 0 || m.MaxTokens < limit) {
+			limit = m.MaxTokens
+		}
+	}
+	return limit
+}
+
+// CondenseSources splits source material too large for one request into
+// chunks, has the model note what each says about request, and returns the
+// notes reassembled in order, with the tokens used. Requests go through
+// Generate with opts, and to chunking.MapModel instead of opts.Model if set.
+func (s *InferenceService) CondenseSources(sources, request string, chunking ChunkingOptions, opts GenerateOptions) (string, TokenUsage, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	opts.Instruction = "" // The notes instruction is part of each chunk's prompt
+	var mu sync.Mutex
+	var total TokenUsage
+	generatorFor := func(model string) TextGenerator {
+		return generatorFunc(func(ctx context.Context, prompt string) (string, error) {
+			chunkOpts := opts
+			chunkOpts.Context = ctx
+			chunkOpts.Model = model
+			response, usage, err := s.GenerateWithUsage(prompt, chunkOpts)
+			mu.Lock()
+			total = total.Plus(usage)
+			mu.Unlock()
+			return response, err
+		})
+	}
+	var mapLLM TextGenerator
+	if chunking.MapModel != "" && chunking.MapModel != opts.Model {
+		mapLLM = generatorFor(chunking.MapModel)
+	}
+	notes, err := condenseSources(ctx, generatorFor(opts.Model), mapLLM, sources, request, chunking)
+	return notes, total, err
+}
+
+// condenseSources runs the chunked note taking of CondenseSources with llm,
+// or with mapLLM if it isn't nil.
+func condenseSources(ctx context.Context, llm, mapLLM TextGenerator, sources, request string, chunking ChunkingOptions) (string, error) {
+	var extra []ContextManagerOption
+	if mapLLM != nil {
+		extra = append(extra, WithChunkGenerator(mapLLM))
+	}
+	if chunking.OnNotes != nil {
+		extra = append(extra, WithChunkListener(func(index, total int, result string) {
+			notes, _ := relevantNotes(result)
+			chunking.OnNotes(index, total, notes)
+		}))
+	}
+	cm := chunking.contextManager(extra...)
+	logger.Info("Condensing oversized sources", "source_tokens", EstimateTokenCount(sources), "chunk_tokens", cm.maxChunkSize, "map_model", chunking.MapModel)
+	notes, err := cm.ProcessLargePrompt(ctx, llm, sources, GetSourceNotesInstruction(request))
+	if err != nil {
+		return "", fmt.Errorf("failed to condense the sources: %w", err)
+	}
+	var kept []string
+	for _, section := range strings.Split(notes, "\n\n---\n\n") {
+		if section, ok := relevantNotes(section); ok {
+			kept = append(kept, section)
+		}
+	}
+	if len(kept) == 0 {
+		return "", fmt.Errorf("no part of the sources is relevant to the request")
+	}
+	return strings.Join(kept, "\n\n"), nil
+}
+
+// relevantNotes trims one chunk's notes and reports whether they say
+// anything, i.e. aren't empty or the "No relevant content." answer.
+func relevantNotes(notes string) (string, bool) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" || strings.EqualFold(strings.TrimSuffix(notes, "."), "No relevant content") {
+		return "", false
+	}
+	return notes, true
+}
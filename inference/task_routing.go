@@ -0,0 +1,95 @@
+package inference
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// TaskType classifies a request so the delegator can send it to the models
+// suited to it.
+type TaskType string
+
+const (
+	TaskGeneral       TaskType = ""              // The configured primary/fallback order
+	TaskChat          TaskType = "chat"          // Short conversational turns: the fast primary models
+	TaskLongForm      TaskType = "long_form"     // Articles and rewrites: the large-context models
+	TaskStructured    TaskType = "structured"    // JSON answers: the models with a JSON mode
+	TaskSummarization TaskType = "summarization" // Condensing long input: the large-context models
+)
+
+// TaskTypes lists the task types that change routing.
+var TaskTypes = []TaskType{TaskChat, TaskLongForm, TaskStructured, TaskSummarization}
+
+// largeContextTokens is the context size from which a model counts as large
+// context.
+const largeContextTokens = 32000
+
+// routeForTask reorders the attempts for a task: the ones suited to it become
+// the primary list, in order of preference, and all the others the fallback
+// list. A TASK_ROUTE_<TASK> environment variable (comma-separated model or
+// "provider/model" references, e.g. TASK_ROUTE_LONG_FORM=gemini) overrides
+// the choice. When no attempt is suited, or every one is, the lists are
+// returned unchanged, as both must stay non-empty.
+func routeForTask(task TaskType, primary, fallback []LLMAttempt) ([]LLMAttempt, []LLMAttempt) {
+	if task == TaskGeneral || task == TaskChat {
+		return primary, fallback
+	}
+	all := append(append([]LLMAttempt(nil), primary...), fallback...)
+	var suited, rest []LLMAttempt
+	if refs := taskRouteFromEnv(task); len(refs) > 0 {
+		used := make([]bool, len(all))
+		for _, ref := range refs {
+			for i, attempt := range all {
+				if !used[i] && attempt.Config.matchesModel(ref) {
+					suited = append(suited, attempt)
+					used[i] = true
+				}
+			}
+		}
+		for i, attempt := range all {
+			if !used[i] {
+				rest = append(rest, attempt)
+			}
+		}
+	} else {
+		for _, attempt := range all {
+			if suitedForTask(task, attempt.Config) {
+				suited = append(suited, attempt)
+			} else {
+				rest = append(rest, attempt)
+			}
+		}
+		if task != TaskStructured {
+			// Largest context first; the configured order breaks ties
+			sort.SliceStable(suited, func(i, j int) bool { return suited[i].Config.MaxTokens > suited[j].Config.MaxTokens })
+		}
+	}
+	if len(suited) == 0 || len(rest) == 0 {
+		return primary, fallback
+	}
+	return suited, rest
+}
+
+// suitedForTask reports whether a model is suited to a task by default.
+func suitedForTask(task TaskType, conf LLMAttemptConfig) bool {
+	switch task {
+	case TaskStructured:
+		return conf.JSONMode
+	case TaskLongForm, TaskSummarization:
+		return conf.MaxTokens >= largeContextTokens
+	default:
+		return false
+	}
+}
+
+// taskRouteFromEnv returns the model references TASK_ROUTE_<TASK> lists.
+func taskRouteFromEnv(task TaskType) []string {
+	var refs []string
+	for _, ref := range strings.Split(os.Getenv("TASK_ROUTE_"+strings.ToUpper(string(task))), ",") {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
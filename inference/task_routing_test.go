@@ -0,0 +1,67 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+)
+
+func testAttempts() ([]LLMAttempt, []LLMAttempt) {
+	primary := []LLMAttempt{{Config: LLMAttemptConfig{ProviderName: "cerebras", ModelName: "llama", MaxTokens: 4000, IsPrimary: true, JSONMode: true}}}
+	fallback := []LLMAttempt{
+		{Config: LLMAttemptConfig{ProviderName: "gemini", ModelName: "gemini-flash", MaxTokens: 100000}},
+		{Config: LLMAttemptConfig{ProviderName: "deepseek", ModelName: "deepseek-chat", MaxTokens: 8000, JSONMode: true}},
+	}
+	return primary, fallback
+}
+
+func attemptIDs(attempts []LLMAttempt) []string {
+	var ids []string
+	for _, attempt := range attempts {
+		ids = append(ids, attempt.Config.ID())
+	}
+	return ids
+}
+
+func TestRouteForTask(t *testing.T) {
+	tests := []struct {
+		task            TaskType
+		primary, others []string
+	}{
+		{TaskGeneral, []string{"cerebras/llama"}, []string{"gemini/gemini-flash", "deepseek/deepseek-chat"}},
+		{TaskChat, []string{"cerebras/llama"}, []string{"gemini/gemini-flash", "deepseek/deepseek-chat"}},
+		{TaskStructured, []string{"cerebras/llama", "deepseek/deepseek-chat"}, []string{"gemini/gemini-flash"}},
+		{TaskLongForm, []string{"gemini/gemini-flash"}, []string{"cerebras/llama", "deepseek/deepseek-chat"}},
+		{TaskSummarization, []string{"gemini/gemini-flash"}, []string{"cerebras/llama", "deepseek/deepseek-chat"}},
+	}
+	for _, tt := range tests {
+		primary, fallback := testAttempts()
+		gotPrimary, gotFallback := routeForTask(tt.task, primary, fallback)
+		if got := attemptIDs(gotPrimary); !slices.Equal(got, tt.primary) {
+			t.Errorf("%q: expected primary %v, got %v", tt.task, tt.primary, got)
+		}
+		if got := attemptIDs(gotFallback); !slices.Equal(got, tt.others) {
+			t.Errorf("%q: expected fallback %v, got %v", tt.task, tt.others, got)
+		}
+	}
+}
+
+func TestRouteForTaskKeepsOrderWithoutSuitedModel(t *testing.T) {
+	primary, fallback := testAttempts()
+	fallback = fallback[1:] // No large-context model left
+	gotPrimary, gotFallback := routeForTask(TaskLongForm, primary, fallback)
+	if len(gotPrimary) != 1 || gotPrimary[0].Config.ID() != "cerebras/llama" || len(gotFallback) != 1 {
+		t.Errorf("Expected the configured order, got %v and %v", attemptIDs(gotPrimary), attemptIDs(gotFallback))
+	}
+}
+
+func TestRouteForTaskFromEnv(t *testing.T) {
+	t.Setenv("TASK_ROUTE_LONG_FORM", "deepseek-chat, cerebras/llama")
+	primary, fallback := testAttempts()
+	gotPrimary, gotFallback := routeForTask(TaskLongForm, primary, fallback)
+	if got := attemptIDs(gotPrimary); !slices.Equal(got, []string{"deepseek/deepseek-chat", "cerebras/llama"}) {
+		t.Errorf("Expected the models from the environment in order, got %v", got)
+	}
+	if got := attemptIDs(gotFallback); !slices.Equal(got, []string{"gemini/gemini-flash"}) {
+		t.Errorf("Expected the other model as fallback, got %v", got)
+	}
+}
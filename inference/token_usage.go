@@ -0,0 +1,153 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenUsage is the tokens one generation used, summed over every provider
+// request it made (fallbacks, chunks, MOA agents).
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Estimated        bool // No provider reported usage; counted with EstimateTokenCount
+}
+
+// Total returns the prompt and completion tokens together.
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Plus returns the usage of u and other together; it is estimated if
+// either is.
+func (u TokenUsage) Plus(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Estimated:        u.Estimated || other.Estimated,
+	}
+}
+
+type usageRecorderKey struct{}
+
+// usageRecorder sums the usage providers report for the requests made with
+// its context.
+type usageRecorder struct {
+	mutex    sync.Mutex
+	usage    TokenUsage
+	reported bool
+
+	limit int    // Tokens after which over is called; 0 for none
+	over  func() // Called once, when the reported tokens pass limit
+}
+
+// withUsageRecorder returns a context whose provider requests are counted by
+// the returned recorder.
+func withUsageRecorder(ctx context.Context) (context.Context, *usageRecorder) {
+	recorder := &usageRecorder{}
+	return context.WithValue(ctx, usageRecorderKey{}, recorder), recorder
+}
+
+// add counts one provider response.
+func (r *usageRecorder) add(prompt, completion int) {
+	r.mutex.Lock()
+	r.usage.PromptTokens += prompt
+	r.usage.CompletionTokens += completion
+	r.reported = true
+	var over func()
+	if r.limit > 0 && r.usage.Total() > r.limit {
+		over, r.over = r.over, nil
+	}
+	r.mutex.Unlock()
+	if over != nil {
+		over()
+	}
+}
+
+// onTokensOver calls over once the providers have reported more than limit
+// tokens for the requests made with ctx, which must carry a usage recorder.
+func onTokensOver(ctx context.Context, limit int, over func()) {
+	recorder, _ := ctx.Value(usageRecorderKey{}).(*usageRecorder)
+	if recorder == nil {
+		return
+	}
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.limit, recorder.over = limit, over
+}
+
+// result returns the reported usage, or the estimate for prompt and response
+// when no provider reported any.
+func (r *usageRecorder) result(prompt, response string) TokenUsage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.reported {
+		return r.usage
+	}
+	return TokenUsage{PromptTokens: EstimateTokenCount(prompt), CompletionTokens: EstimateTokenCount(response), Estimated: true}
+}
+
+// usageTransport reads the token usage from provider responses for requests
+// whose context carries a usage recorder. gollm builds its own HTTP clients
+// and discards the usage, so this is the one place it can be seen; other
+// requests pass through untouched.
+type usageTransport struct {
+	base http.RoundTripper
+}
+
+var installUsageTransport sync.Once
+
+// captureTokenUsage wraps http.DefaultTransport, which gollm's clients use.
+func captureTokenUsage() {
+	installUsageTransport.Do(func() {
+		http.DefaultTransport = usageTransport{base: http.DefaultTransport}
+	})
+}
+
+func (t usageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	recorder, _ := req.Context().Value(usageRecorderKey{}).(*usageRecorder)
+	if err != nil || recorder == nil || resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if prompt, completion, ok := parseTokenUsage(body); ok {
+		recorder.add(prompt, completion)
+	}
+	return resp, nil
+}
+
+// parseTokenUsage reads the usage of an OpenAI-compatible (Cerebras,
+// DeepSeek) or Gemini response.
+func parseTokenUsage(body []byte) (prompt, completion int, ok bool) {
+	var response struct {
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return 0, 0, false
+	}
+	switch {
+	case response.Usage != nil:
+		return response.Usage.PromptTokens, response.Usage.CompletionTokens, true
+	case response.UsageMetadata != nil:
+		return response.UsageMetadata.PromptTokenCount, response.UsageMetadata.CandidatesTokenCount, true
+	}
+	return 0, 0, false
+}
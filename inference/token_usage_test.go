@@ -0,0 +1,64 @@
+package inference
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTokenUsage(t *testing.T) {
+	tests := []struct {
+		body               string
+		prompt, completion int
+		ok                 bool
+	}{
+		{`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":30,"total_tokens":42}}`, 12, 30, true},
+		{`{"candidates":[],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":9}}`, 7, 9, true},
+		{`{"choices":[]}`, 0, 0, false},
+		{`not json`, 0, 0, false},
+	}
+	for _, tt := range tests {
+		prompt, completion, ok := parseTokenUsage([]byte(tt.body))
+		if prompt != tt.prompt || completion != tt.completion || ok != tt.ok {
+			t.Errorf("parseTokenUsage(%s) = %d, %d, %v; expected %d, %d, %v", tt.body, prompt, completion, ok, tt.prompt, tt.completion, tt.ok)
+		}
+	}
+}
+
+func TestUsageTransportRecordsResponses(t *testing.T) {
+	const body = `{"usage":{"prompt_tokens":100,"completion_tokens":25}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: usageTransport{base: server.Client().Transport}}
+
+	tracker := NewUsageTracker()
+	ctx, finish := tracker.StartJobContext(context.Background(), "prompt")
+	for range 2 { // A fallback or a second chunk adds to the same generation
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got, _ := io.ReadAll(resp.Body); string(got) != body {
+			t.Errorf("Expected the response body to stay readable, got %q", got)
+		}
+		resp.Body.Close()
+	}
+	usage := finish("response")
+	if usage != (TokenUsage{PromptTokens: 200, CompletionTokens: 50}) {
+		t.Errorf("Expected the reported usage of both requests, got %+v", usage)
+	}
+	if stats := tracker.Stats(); stats.TokensToday != 250 || stats.PromptTokensToday != 200 || stats.CompletionTokensToday != 50 {
+		t.Errorf("Expected the reported usage in today's totals, got %+v", stats)
+	}
+
+	_, finish = tracker.StartJobContext(context.Background(), "Write about cats")
+	if usage := finish("Cats are great."); !usage.Estimated || usage.PromptTokens != EstimateTokenCount("Write about cats") {
+		t.Errorf("Expected an estimate without reported usage, got %+v", usage)
+	}
+}
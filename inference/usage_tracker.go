@@ -0,0 +1,167 @@
+package inference
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"Inference_Engine/storage"
+)
+
+// UsageStats is a snapshot of the service's current activity and token spend.
+type UsageStats struct {
+	ActiveJobs            int // Generation requests currently in flight
+	TokensToday           int // Prompt + completion tokens since local midnight
+	PromptTokensToday     int
+	CompletionTokensToday int
+	JobsToday             int // Generation requests finished today
+	TokenBudget           int // Tokens allowed per day; 0 for no budget
+}
+
+// UsageTracker counts in-flight generation jobs and token spend per day.
+// Token counts are the ones providers report, or estimates (see
+// EstimateTokenCount) for requests no provider reported usage for.
+type UsageTracker struct {
+	mutex            sync.Mutex
+	day              string
+	activeJobs       int
+	promptTokens     int
+	completionTokens int
+	jobsToday        int
+	db               *storage.DB // Keeps the daily totals across restarts; nil keeps them in memory
+
+	budget         int                // Daily token budget; 0 for none
+	budgetReported string             // Day the budget was last reported exceeded
+	overBudget     []func(UsageStats) // Called when today's tokens first exceed the budget
+}
+
+// NewUsageTracker creates an empty tracker.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{day: time.Now().Format("2006-01-02")}
+}
+
+// SetStore persists the daily totals in db, picking up today's totals from
+// an earlier session. Call it before any jobs start.
+func (u *UsageTracker) SetStore(db *storage.DB) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.rollover()
+	var tokens, completion, jobs int
+	err := db.QueryRow(`SELECT tokens, completion_tokens, jobs FROM usage_days WHERE day = ?`, u.day).Scan(&tokens, &completion, &jobs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	u.db = db
+	u.promptTokens += tokens - completion // Tokens saved before the split count as prompt tokens
+	u.completionTokens += completion
+	u.jobsToday += jobs
+	return nil
+}
+
+// rollover resets the daily counters when the date changes. Caller holds the mutex.
+func (u *UsageTracker) rollover() {
+	today := time.Now().Format("2006-01-02")
+	if u.day != today {
+		u.day = today
+		u.promptTokens = 0
+		u.completionTokens = 0
+		u.jobsToday = 0
+	}
+}
+
+// SetDailyTokenBudget sets how many estimated tokens may be spent per day
+// before the budget listeners are called; 0 removes the budget. Generation
+// is not stopped when the budget is exceeded.
+func (u *UsageTracker) SetDailyTokenBudget(tokens int) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.budget = max(tokens, 0)
+}
+
+// OnBudgetExceeded registers a listener called (from any goroutine) the
+// first time each day that the token spend exceeds the daily budget.
+func (u *UsageTracker) OnBudgetExceeded(listener func(UsageStats)) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.overBudget = append(u.overBudget, listener)
+}
+
+// StartJob records the start of a generation request and returns a function
+// to call with the response when it finishes (empty on failure). Tokens are
+// estimated; StartJobContext counts the ones providers report.
+func (u *UsageTracker) StartJob(prompt string) func(response string) {
+	_, finish := u.StartJobContext(context.Background(), prompt)
+	return func(response string) { finish(response) }
+}
+
+// StartJobContext records the start of a generation request made with the
+// returned context, and returns a function to call with the response when it
+// finishes (empty on failure). The function returns the tokens the request
+// used: those the providers reported, or an estimate if none did.
+func (u *UsageTracker) StartJobContext(ctx context.Context, prompt string) (context.Context, func(response string) TokenUsage) {
+	ctx, recorder := withUsageRecorder(ctx)
+	u.mutex.Lock()
+	u.activeJobs++
+	u.mutex.Unlock()
+
+	var once sync.Once
+	var usage TokenUsage
+	return ctx, func(response string) TokenUsage {
+		once.Do(func() {
+			usage = recorder.result(prompt, response)
+			u.mutex.Lock()
+			u.rollover()
+			u.activeJobs--
+			u.jobsToday++
+			u.promptTokens += usage.PromptTokens
+			u.completionTokens += usage.CompletionTokens
+			db, day := u.db, u.day
+			var listeners []func(UsageStats)
+			if u.budget > 0 && u.promptTokens+u.completionTokens > u.budget && u.budgetReported != day {
+				u.budgetReported = day
+				listeners = append(listeners, u.overBudget...)
+			}
+			stats := u.statsLocked()
+			u.mutex.Unlock()
+
+			if db != nil {
+				_, err := db.Exec(`INSERT INTO usage_days (day, tokens, prompt_tokens, completion_tokens, jobs) VALUES (?, ?, ?, ?, 1)
+					ON CONFLICT (day) DO UPDATE SET tokens = tokens + excluded.tokens, prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+					completion_tokens = completion_tokens + excluded.completion_tokens, jobs = jobs + 1`,
+					day, usage.Total(), usage.PromptTokens, usage.CompletionTokens)
+				if err != nil {
+					logger.Warn("Failed to save usage statistics", "error", err)
+				}
+			}
+			if len(listeners) > 0 {
+				logger.Warn("Daily token budget exceeded", "tokens", stats.TokensToday, "budget", stats.TokenBudget)
+			}
+			for _, listener := range listeners {
+				listener(stats)
+			}
+		})
+		return usage
+	}
+}
+
+// Stats returns the current counters.
+func (u *UsageTracker) Stats() UsageStats {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.rollover()
+	return u.statsLocked()
+}
+
+// statsLocked returns the current counters. Caller holds the mutex.
+func (u *UsageTracker) statsLocked() UsageStats {
+	return UsageStats{
+		ActiveJobs:            u.activeJobs,
+		TokensToday:           u.promptTokens + u.completionTokens,
+		PromptTokensToday:     u.promptTokens,
+		CompletionTokensToday: u.completionTokens,
+		JobsToday:             u.jobsToday,
+		TokenBudget:           u.budget,
+	}
+}
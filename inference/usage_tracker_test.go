@@ -0,0 +1,49 @@
+package inference
+
+import (
+	"path/filepath"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestUsageTrackerPersistsDailyTotals(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	first := NewUsageTracker()
+	if err := first.SetStore(db); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	first.StartJob("Write about cats")("Cats are great.")
+	want := first.Stats()
+
+	second := NewUsageTracker()
+	if err := second.SetStore(db); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	got := second.Stats()
+	if got.JobsToday != 1 || got.TokensToday != want.TokensToday {
+		t.Errorf("Expected today's totals %+v to carry over, got %+v", want, got)
+	}
+}
+
+func TestUsageTrackerReportsBudgetOncePerDay(t *testing.T) {
+	tracker := NewUsageTracker()
+	tracker.SetDailyTokenBudget(EstimateTokenCount("Write about cats") + 1)
+	var reports []UsageStats
+	tracker.OnBudgetExceeded(func(stats UsageStats) { reports = append(reports, stats) })
+
+	tracker.StartJob("Write about cats")("")
+	if len(reports) != 0 {
+		t.Fatalf("Expected no report under the budget, got %+v", reports)
+	}
+	tracker.StartJob("Write about cats")("Cats are great.")
+	tracker.StartJob("Write about dogs")("Dogs are great.")
+	if len(reports) != 1 || reports[0].TokensToday <= reports[0].TokenBudget {
+		t.Errorf("Expected one report over the budget, got %+v", reports)
+	}
+}
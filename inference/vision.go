@@ -0,0 +1,119 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// geminiVisionModel is the Gemini model that describes images.
+const geminiVisionModel = "gemini-1.5-flash-latest"
+
+// maxVisionErrorBody bounds how much of a failed response goes into the error.
+const maxVisionErrorBody = 512
+
+// GeminiVision describes images with a Gemini model. The text-only models
+// of the MOA cannot see images, so it calls the Gemini API directly.
+type GeminiVision struct {
+	apiKey       string
+	endpoint     string
+	extraHeaders http.Header
+	client       *http.Client
+}
+
+// NewGeminiVision creates a vision client using the given API key and the
+// Gemini provider's base URL and headers: its override from the settings,
+// or else GEMINI_API_ENDPOINT.
+func NewGeminiVision(apiKey string) *GeminiVision {
+	endpoint := os.Getenv("GEMINI_API_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://generativelanguage.googleapis.com/v1beta/"
+	}
+	override := ProviderOverrideFor("gemini")
+	if override.BaseURL != "" {
+		endpoint = override.BaseURL
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	extraHeaders := http.Header{}
+	for name, value := range override.headers() {
+		extraHeaders.Set(name, value)
+	}
+	return &GeminiVision{apiKey: apiKey, endpoint: endpoint, extraHeaders: extraHeaders, client: &http.Client{Timeout: 90 * time.Second}}
+}
+
+// DescribeImage sends an image with a prompt and returns the model's answer.
+func (g *GeminiVision) DescribeImage(ctx context.Context, image []byte, mimeType, prompt string) (string, error) {
+	if g.apiKey == "" && g.extraHeaders.Get("x-goog-api-key") == "" {
+		return "", fmt.Errorf("describing images needs GEMINI_API_KEY")
+	}
+	type inlineData struct {
+		MimeType string `json:"mime_type"`
+		Data     string `json:"data"`
+	}
+	type part struct {
+		Text       string      `json:"text,omitempty"`
+		InlineData *inlineData `json:"inline_data,omitempty"`
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"contents": []map[string]interface{}{{
+			"parts": []part{
+				{Text: prompt},
+				{InlineData: &inlineData{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(image)}},
+			},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%smodels/%s:generateContent", g.endpoint, geminiVisionModel)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create vision request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("x-goog-api-key", g.apiKey) // Not in the URL, which errors and logs include
+	}
+	for name, values := range g.extraHeaders {
+		req.Header[name] = values // A gateway's headers, its own key included
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vision request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxVisionErrorBody))
+		return "", fmt.Errorf("vision request failed: HTTP %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result GeminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse vision response: %w", err)
+	}
+	var text strings.Builder
+	for _, candidate := range result.Candidates {
+		if candidate.Content == nil {
+			continue
+		}
+		for _, p := range candidate.Content.Parts {
+			text.WriteString(p.Text)
+		}
+		break
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("vision response has no text")
+	}
+	logger.Debug("Described image", "model", geminiVisionModel, "bytes", len(image))
+	return text.String(), nil
+}
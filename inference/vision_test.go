@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiVisionDescribeImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/"+geminiVisionModel+":generateContent" || r.Header.Get("x-goog-api-key") != "secret" || r.URL.RawQuery != "" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Contents []struct {
+				Parts []struct {
+					Text       string `json:"text"`
+					InlineData *struct {
+						MimeType string `json:"mime_type"`
+						Data     string `json:"data"`
+					} `json:"inline_data"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if parts := body.Contents[0].Parts; len(parts) != 2 || parts[1].InlineData == nil || parts[1].InlineData.Data != "aW1n" {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "A red bicycle"}]}}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("GEMINI_API_ENDPOINT", server.URL)
+
+	got, err := NewGeminiVision("secret").DescribeImage(context.Background(), []byte("img"), "image/png", "Describe")
+	if err != nil {
+		t.Fatalf("DescribeImage failed: %v", err)
+	}
+	if got != "A red bicycle" {
+		t.Errorf("DescribeImage = %q", got)
+	}
+	if _, err := NewGeminiVision("").DescribeImage(context.Background(), nil, "image/png", "Describe"); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
+
+func TestGeminiVisionUsesOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gateway/models/"+geminiVisionModel+":generateContent" || r.Header.Get("X-Gateway") != "on" {
+			http.Error(w, strings.Repeat("x", 4096), http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "A cat"}]}}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("GEMINI_BASE_URL", server.URL+"/gateway")
+	t.Setenv("GEMINI_EXTRA_HEADERS", "X-Gateway: on")
+
+	got, err := NewGeminiVision("secret").DescribeImage(context.Background(), []byte("img"), "image/png", "Describe")
+	if err != nil || got != "A cat" {
+		t.Fatalf("DescribeImage = %q, %v", got, err)
+	}
+
+	t.Setenv("GEMINI_EXTRA_HEADERS", "")
+	_, err = NewGeminiVision("secret").DescribeImage(context.Background(), []byte("img"), "image/png", "Describe")
+	if err == nil {
+		t.Fatal("Expected an error without the gateway header")
+	}
+	if strings.Contains(err.Error(), "secret") || len(err.Error()) > maxVisionErrorBody+100 {
+		t.Errorf("Error leaks the key or the whole body: %d bytes", len(err.Error()))
+	}
+}
@@ -0,0 +1,112 @@
+// Package interview reads Q&A and interview transcripts as speaker turns, so
+// they can be formatted as a Q&A or turned into an article with every quote
+// attributed to the right speaker.
+package interview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("interview")
+
+// Turn is what one speaker says before another speaks.
+type Turn struct {
+	Speaker string
+	Text    string
+}
+
+// Transcript is an interview as speaker turns, in order.
+type Transcript struct {
+	Turns []Turn
+}
+
+// timestamp matches a leading "[00:01:02]", "(1:02)" or "00:01:02 -".
+var timestamp = regexp.MustCompile(`^[\[(]?\d{1,2}:\d{2}(?::\d{2})?[\])]?\s*[-–]?\s*`)
+
+// speakerLabel matches a turn's "Speaker:" label, optionally in bold, with
+// the first words of the turn after it.
+var speakerLabel = regexp.MustCompile(`^(?:\*\*)?([\p{Lu}][\p{L}\p{M}.'’ -]{0,39}?)(?:\*\*)?\s*:(?:\*\*)?\s*(.*)$`)
+
+// maxLabelWords is the most words a speaker label may have, so a sentence
+// with a colon isn't taken for one.
+const maxLabelWords = 4
+
+// Parse reads a transcript with one "Speaker: text" turn per paragraph or
+// line, such as "Q:"/"A:" or named speakers. Lines without a label continue
+// the turn before them; timestamps are dropped.
+func Parse(text string) (Transcript, error) {
+	var t Transcript
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(timestamp.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" {
+			continue
+		}
+		if m := speakerLabel.FindStringSubmatch(line); m != nil && len(strings.Fields(m[1])) <= maxLabelWords {
+			t.Turns = append(t.Turns, Turn{Speaker: strings.TrimSpace(m[1]), Text: strings.TrimSpace(m[2])})
+			continue
+		}
+		if len(t.Turns) == 0 {
+			continue // Title or notes before the first turn
+		}
+		last := &t.Turns[len(t.Turns)-1]
+		last.Text = strings.TrimSpace(last.Text + "\n" + line)
+	}
+	if len(t.Turns) < 2 || len(t.Speakers()) < 2 {
+		return Transcript{}, fmt.Errorf("no interview found: expected turns from at least two speakers, each starting with \"Name:\" or \"Q:\"/\"A:\"")
+	}
+	logger.Info("Parsed interview transcript", "turns", len(t.Turns), "speakers", len(t.Speakers()))
+	return t, nil
+}
+
+// Speakers returns the speakers, in the order they first speak.
+func (t Transcript) Speakers() []string {
+	var speakers []string
+	seen := map[string]bool{}
+	for _, turn := range t.Turns {
+		if !seen[turn.Speaker] {
+			seen[turn.Speaker] = true
+			speakers = append(speakers, turn.Speaker)
+		}
+	}
+	return speakers
+}
+
+// Text returns the transcript as plain "Speaker: text" turns, for prompts.
+func (t Transcript) Text() string {
+	var b strings.Builder
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "%s: %s\n\n", turn.Speaker, turn.Text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Markdown formats the transcript as a Q&A, each turn a paragraph led by its
+// speaker in bold.
+func (t Transcript) Markdown() string {
+	var b strings.Builder
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "**%s:** %s\n\n", turn.Speaker, strings.ReplaceAll(turn.Text, "\n", "  \n"))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// MissingSpeakers returns the named speakers an article never mentions, a
+// sign their words went unattributed. Labels such as "Q" and "A" are not
+// names and are left out.
+func (t Transcript) MissingSpeakers(article string) []string {
+	lower := strings.ToLower(article)
+	var missing []string
+	for _, speaker := range t.Speakers() {
+		if len([]rune(speaker)) <= 2 || strings.EqualFold(speaker, "interviewer") {
+			continue
+		}
+		if !strings.Contains(lower, strings.ToLower(speaker)) {
+			missing = append(missing, speaker)
+		}
+	}
+	return missing
+}
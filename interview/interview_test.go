@@ -0,0 +1,67 @@
+package interview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `Interview with Jane Roe, March 2024
+
+[00:00:05] Interviewer: How did the company start?
+[00:00:09] Jane Roe: In my garage. We had one customer
+and no money.
+**Interviewer:** What changed?
+**Jane Roe:** We hired Sam Lee. Note: he still works here.
+`
+
+func TestParse(t *testing.T) {
+	transcript, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []Turn{
+		{"Interviewer", "How did the company start?"},
+		{"Jane Roe", "In my garage. We had one customer\nand no money."},
+		{"Interviewer", "What changed?"},
+		{"Jane Roe", "We hired Sam Lee. Note: he still works here."},
+	}
+	if !reflect.DeepEqual(transcript.Turns, want) {
+		t.Errorf("Turns = %+v, want %+v", transcript.Turns, want)
+	}
+	if got := transcript.Speakers(); !reflect.DeepEqual(got, []string{"Interviewer", "Jane Roe"}) {
+		t.Errorf("Unexpected speakers %v", got)
+	}
+	if md := transcript.Markdown(); !strings.HasPrefix(md, "**Interviewer:** How did the company start?\n\n**Jane Roe:** In my garage.") {
+		t.Errorf("Unexpected Markdown:\n%s", md)
+	}
+}
+
+func TestParseQAndA(t *testing.T) {
+	transcript, err := Parse("Q: Why now?\nA: Because the market is ready.")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := transcript.Speakers(); !reflect.DeepEqual(got, []string{"Q", "A"}) {
+		t.Errorf("Unexpected speakers %v", got)
+	}
+	if missing := transcript.MissingSpeakers("An article quoting no one."); len(missing) != 0 {
+		t.Errorf("Expected Q and A not to count as names, got %v", missing)
+	}
+}
+
+func TestParseRejectsProse(t *testing.T) {
+	if _, err := Parse("Just an article. It has no speakers at all."); err == nil {
+		t.Error("Expected text without speaker turns to be rejected")
+	}
+}
+
+func TestMissingSpeakers(t *testing.T) {
+	transcript, _ := Parse(sample)
+	if got := transcript.MissingSpeakers("The founder started in a garage."); !reflect.DeepEqual(got, []string{"Jane Roe"}) {
+		t.Errorf("Expected Jane Roe to be missing, got %v", got)
+	}
+	if got := transcript.MissingSpeakers(`"In my garage," says Jane Roe.`); len(got) != 0 {
+		t.Errorf("Expected no missing speakers, got %v", got)
+	}
+}
@@ -0,0 +1,444 @@
+// Package jobs runs long-running application work (generations, page updates,
+// syncs) in the background and keeps a history that the UI can display,
+// cancel and retry.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+	"Inference_Engine/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var logger = logging.For("jobs")
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "Queued"
+	StatusRunning   Status = "Running"
+	StatusSucceeded Status = "Succeeded"
+	StatusFailed    Status = "Failed"
+	StatusCanceled  Status = "Canceled"
+	StatusDeferred  Status = "Deferred" // Finished, with its work put off to later
+)
+
+// IsFinished reports whether the status is terminal.
+func (s Status) IsFinished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled || s == StatusDeferred
+}
+
+// deferredError is a job's reason for putting its work off; see Deferred.
+type deferredError struct{ error }
+
+func (e deferredError) Unwrap() error { return e.error }
+
+// Deferred marks err, returned by a job, as the reason its work was put off
+// to later rather than failed, e.g. a save queued while offline. The job
+// finishes as StatusDeferred with err as its message.
+func Deferred(err error) error {
+	return deferredError{err}
+}
+
+// ProgressFunc reports progress from a running job. fraction is 0..1, or
+// negative when the amount of work is unknown.
+type ProgressFunc func(fraction float64, message string)
+
+// RunFunc does the work of a job. It should return promptly once ctx is
+// canceled; results produced after cancellation are discarded by convention.
+type RunFunc func(ctx context.Context, progress ProgressFunc) error
+
+// Job is a snapshot of a queued, running or finished job.
+type Job struct {
+	ID       int
+	Kind     string // e.g. "Generation", "Chat", "Page Update"
+	Title    string
+	Status   Status
+	Progress float64 // 0..1, or negative if unknown
+	Message  string
+	Err      error
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+// Duration returns how long the job ran (so far, if still running).
+func (j Job) Duration() time.Duration {
+	switch {
+	case j.Started.IsZero():
+		return 0
+	case j.Finished.IsZero():
+		return time.Since(j.Started)
+	default:
+		return j.Finished.Sub(j.Started)
+	}
+}
+
+// entry is the queue's internal record for a job.
+type entry struct {
+	job    Job
+	run    RunFunc
+	cancel context.CancelFunc
+}
+
+// ErrJobNotFound is returned for unknown job IDs.
+var ErrJobNotFound = errors.New("job not found")
+
+// Queue runs jobs with bounded concurrency and keeps a bounded history.
+type Queue struct {
+	mutex      sync.Mutex
+	entries    map[int]*entry
+	nextID     int
+	slots      chan struct{}
+	maxHistory int
+	listeners  []func()
+	finished   []func(Job)
+	paused     bool
+	resumed    chan struct{} // Closed when the queue is resumed
+	db         *storage.DB   // Keeps finished jobs across restarts, see SetStore
+}
+
+// NewQueue creates a queue that runs at most concurrency jobs at once.
+func NewQueue(concurrency int) *Queue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Queue{
+		entries:    make(map[int]*entry),
+		nextID:     1,
+		slots:      make(chan struct{}, concurrency),
+		maxHistory: 200,
+	}
+}
+
+// OnChange registers a listener called (from any goroutine) whenever a job changes.
+func (q *Queue) OnChange(listener func()) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.listeners = append(q.listeners, listener)
+}
+
+// OnFinished registers a listener called (from any goroutine) once with
+// each job that succeeds, fails or is canceled.
+func (q *Queue) OnFinished(listener func(Job)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.finished = append(q.finished, listener)
+}
+
+// notifyFinished calls the finished listeners. Must be called without the mutex held.
+func (q *Queue) notifyFinished(job Job) {
+	q.mutex.Lock()
+	listeners := append([]func(Job){}, q.finished...)
+	q.mutex.Unlock()
+	for _, listener := range listeners {
+		listener(job)
+	}
+}
+
+// notify calls the listeners. Must be called without the mutex held.
+func (q *Queue) notify() {
+	q.mutex.Lock()
+	listeners := append([]func(){}, q.listeners...)
+	q.mutex.Unlock()
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// Submit queues a job and returns its ID. The job starts as soon as a slot is free.
+func (q *Queue) Submit(kind, title string, run RunFunc) int {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q.mutex.Lock()
+	id := q.nextID
+	q.nextID++
+	e := &entry{
+		job: Job{
+			ID:       id,
+			Kind:     kind,
+			Title:    title,
+			Status:   StatusQueued,
+			Progress: -1,
+			Created:  time.Now(),
+		},
+		run:    run,
+		cancel: cancel,
+	}
+	q.entries[id] = e
+	q.pruneLocked()
+	q.mutex.Unlock()
+
+	logger.Info("Queued job", logging.JobID(id), "kind", kind, "title", title)
+	q.notify()
+	go q.execute(ctx, e)
+	return id
+}
+
+// execute waits until the queue is not paused and a slot is free, then runs the job.
+func (q *Queue) execute(ctx context.Context, e *entry) {
+	for {
+		q.mutex.Lock()
+		paused, resumed := q.paused, q.resumed
+		q.mutex.Unlock()
+		if !paused {
+			break
+		}
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return // Canceled while queued; Cancel already updated the status
+	}
+	defer func() { <-q.slots }()
+
+	q.mutex.Lock()
+	if e.job.Status != StatusQueued {
+		q.mutex.Unlock()
+		return
+	}
+	e.job.Status = StatusRunning
+	e.job.Started = time.Now()
+	id, kind, title := e.job.ID, e.job.Kind, e.job.Title
+	q.mutex.Unlock()
+	q.notify()
+
+	progress := func(fraction float64, message string) {
+		q.mutex.Lock()
+		if e.job.Status == StatusRunning {
+			e.job.Progress = fraction
+			e.job.Message = message
+		}
+		q.mutex.Unlock()
+		q.notify()
+	}
+
+	spanCtx, span := tracing.Start(ctx, "job "+kind, attribute.Int("job.id", id), attribute.String("job.title", title))
+	err := q.runSafely(spanCtx, kind, e.run, progress)
+	tracing.End(span, err)
+
+	q.mutex.Lock()
+	e.job.Finished = time.Now()
+	switch {
+	case e.job.Status == StatusCanceled:
+		// Keep the canceled status; any result was discarded
+	case errors.As(err, new(deferredError)):
+		e.job.Status = StatusDeferred
+		e.job.Message = err.Error()
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Err = err
+		e.job.Message = err.Error()
+	default:
+		e.job.Status = StatusSucceeded
+		e.job.Progress = 1
+	}
+	job := e.job
+	q.mutex.Unlock()
+
+	logger.Info("Job finished", logging.JobID(id), "status", job.Status)
+	q.persist(job)
+	q.notify()
+	q.notifyFinished(job)
+}
+
+// runSafely runs a job, turning a panic into an error so one bad job can't
+// take down the app. The panic's stack is kept in a crash report.
+func (q *Queue) runSafely(ctx context.Context, kind string, run RunFunc, progress ProgressFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			report := crash.Write("job "+kind, r, debug.Stack())
+			err = fmt.Errorf("job panicked: %v", r)
+			if report.Path != "" {
+				err = fmt.Errorf("job panicked: %v (crash report: %s)", r, report.Path)
+			}
+		}
+	}()
+	return run(ctx, progress)
+}
+
+// Cancel cancels a queued or running job.
+func (q *Queue) Cancel(id int) error {
+	q.mutex.Lock()
+	e, ok := q.entries[id]
+	if !ok {
+		q.mutex.Unlock()
+		return ErrJobNotFound
+	}
+	if e.job.Status.IsFinished() {
+		q.mutex.Unlock()
+		return fmt.Errorf("job %d has already finished", id)
+	}
+	wasQueued := e.job.Status == StatusQueued
+	if wasQueued {
+		e.job.Finished = time.Now()
+	}
+	e.job.Status = StatusCanceled
+	e.job.Message = "Canceled"
+	e.cancel()
+	job := e.job
+	q.mutex.Unlock()
+
+	logger.Info("Canceled job", logging.JobID(id))
+	if wasQueued {
+		q.persist(job) // A running job is saved when it returns
+	}
+	q.notify()
+	if wasQueued {
+		q.notifyFinished(job)
+	}
+	return nil
+}
+
+// Retry submits a finished job again and returns the new job's ID.
+func (q *Queue) Retry(id int) (int, error) {
+	q.mutex.Lock()
+	e, ok := q.entries[id]
+	if !ok {
+		q.mutex.Unlock()
+		return 0, ErrJobNotFound
+	}
+	if !e.job.Status.IsFinished() {
+		q.mutex.Unlock()
+		return 0, fmt.Errorf("job %d is still %s", id, e.job.Status)
+	}
+	kind, title, run := e.job.Kind, e.job.Title, e.run
+	q.mutex.Unlock()
+	if run == nil {
+		return 0, fmt.Errorf("job %d ran in an earlier session and can't be retried", id)
+	}
+
+	return q.Submit(kind, title, run), nil
+}
+
+// Pause stops queued jobs from starting until Resume is called. Running jobs
+// are not interrupted.
+func (q *Queue) Pause() {
+	q.mutex.Lock()
+	if !q.paused {
+		q.paused = true
+		q.resumed = make(chan struct{})
+	}
+	q.mutex.Unlock()
+	logger.Info("Queue paused")
+	q.notify()
+}
+
+// Resume lets queued jobs start again.
+func (q *Queue) Resume() {
+	q.mutex.Lock()
+	if q.paused {
+		q.paused = false
+		close(q.resumed)
+	}
+	q.mutex.Unlock()
+	logger.Info("Queue resumed")
+	q.notify()
+}
+
+// Paused reports whether the queue is paused.
+func (q *Queue) Paused() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.paused
+}
+
+// LastFinished returns the most recently finished job, if any.
+func (q *Queue) LastFinished() (Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var last Job
+	found := false
+	for _, e := range q.entries {
+		if e.job.Status.IsFinished() && (!found || e.job.Finished.After(last.Finished)) {
+			last, found = e.job, true
+		}
+	}
+	return last, found
+}
+
+// Get returns a snapshot of one job.
+func (q *Queue) Get(id int) (Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	e, ok := q.entries[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Jobs returns snapshots of all jobs, newest first.
+func (q *Queue) Jobs() []Job {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	jobs := make([]Job, 0, len(q.entries))
+	for _, e := range q.entries {
+		jobs = append(jobs, e.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// ActiveCount returns the number of queued and running jobs.
+func (q *Queue) ActiveCount() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	count := 0
+	for _, e := range q.entries {
+		if !e.job.Status.IsFinished() {
+			count++
+		}
+	}
+	return count
+}
+
+// ClearFinished removes finished jobs from the history.
+func (q *Queue) ClearFinished() {
+	q.mutex.Lock()
+	for id, e := range q.entries {
+		if e.job.Status.IsFinished() {
+			delete(q.entries, id)
+		}
+	}
+	q.mutex.Unlock()
+	q.forgetFinished()
+	q.notify()
+}
+
+// pruneLocked drops the oldest finished jobs beyond maxHistory. Caller holds the mutex.
+func (q *Queue) pruneLocked() {
+	if len(q.entries) <= q.maxHistory {
+		return
+	}
+	var finished []int
+	for id, e := range q.entries {
+		if e.job.Status.IsFinished() {
+			finished = append(finished, id)
+		}
+	}
+	sort.Ints(finished)
+	for _, id := range finished {
+		if len(q.entries) <= q.maxHistory {
+			break
+		}
+		delete(q.entries, id)
+	}
+}
@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/storage"
+)
+
+// waitForStatus polls until the job reaches want or the test times out.
+func waitForStatus(t *testing.T, q *Queue, id int, want Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := q.Get(id); ok && job.Status == want {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := q.Get(id)
+	t.Fatalf("job %d: status %s, want %s", id, job.Status, want)
+	return job
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	q := NewQueue(1)
+	ok := q.Submit("Test", "ok", func(ctx context.Context, progress ProgressFunc) error {
+		progress(0.5, "halfway")
+		return nil
+	})
+	failed := q.Submit("Test", "fail", func(ctx context.Context, progress ProgressFunc) error {
+		return errors.New("boom")
+	})
+	deferred := q.Submit("Test", "defer", func(ctx context.Context, progress ProgressFunc) error {
+		return Deferred(errors.New("offline"))
+	})
+
+	if job := waitForStatus(t, q, ok, StatusSucceeded); job.Progress != 1 {
+		t.Errorf("progress = %v, want 1", job.Progress)
+	}
+	if job := waitForStatus(t, q, failed, StatusFailed); job.Err == nil || job.Err.Error() != "boom" {
+		t.Errorf("err = %v, want boom", job.Err)
+	}
+	if job := waitForStatus(t, q, deferred, StatusDeferred); job.Err != nil || job.Message != "offline" {
+		t.Errorf("deferred job = %+v, want no error and the reason as message", job)
+	}
+	if n := q.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount = %d, want 0", n)
+	}
+}
+
+func TestQueueRecoversPanickingJobs(t *testing.T) {
+	crash.SetDir(t.TempDir())
+	q := NewQueue(1)
+	id := q.Submit("Test", "panic", func(ctx context.Context, progress ProgressFunc) error {
+		panic("out of range")
+	})
+
+	job := waitForStatus(t, q, id, StatusFailed)
+	if job.Err == nil || !strings.Contains(job.Err.Error(), "out of range") || !strings.Contains(job.Err.Error(), "crash report") {
+		t.Errorf("err = %v, want the panic and its crash report", job.Err)
+	}
+}
+
+func TestQueueOnFinished(t *testing.T) {
+	q := NewQueue(1)
+	finished := make(chan Job, 2)
+	q.OnFinished(func(job Job) { finished <- job })
+
+	release := make(chan struct{})
+	running := q.Submit("Test", "running", func(ctx context.Context, progress ProgressFunc) error {
+		<-release
+		return errors.New("boom")
+	})
+	queued := q.Submit("Test", "queued", func(ctx context.Context, progress ProgressFunc) error { return nil })
+	if err := q.Cancel(queued); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	close(release)
+
+	got := map[int]Status{}
+	for len(got) < 2 {
+		select {
+		case job := <-finished:
+			got[job.ID] = job.Status
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected both jobs to be reported, got %v", got)
+		}
+	}
+	if got[running] != StatusFailed || got[queued] != StatusCanceled {
+		t.Errorf("Expected the running job to fail and the queued one to be canceled, got %v", got)
+	}
+}
+
+func TestQueueCancelAndRetry(t *testing.T) {
+	q := NewQueue(1)
+	release := make(chan struct{})
+	runs := make(chan struct{}, 10)
+
+	blocking := q.Submit("Test", "blocking", func(ctx context.Context, progress ProgressFunc) error {
+		runs <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	})
+	waitForStatus(t, q, blocking, StatusRunning)
+
+	// A second job waits for the only slot and can be canceled while queued
+	queued := q.Submit("Test", "queued", func(ctx context.Context, progress ProgressFunc) error {
+		t.Error("canceled job should not run")
+		return nil
+	})
+	if err := q.Cancel(queued); err != nil {
+		t.Fatalf("Cancel queued: %v", err)
+	}
+	waitForStatus(t, q, queued, StatusCanceled)
+
+	// Canceling the running job keeps the canceled status even though it returns an error
+	if err := q.Cancel(blocking); err != nil {
+		t.Fatalf("Cancel running: %v", err)
+	}
+	waitForStatus(t, q, blocking, StatusCanceled)
+
+	if _, err := q.Retry(999); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Retry unknown job: err = %v, want ErrJobNotFound", err)
+	}
+	close(release)
+	retried, err := q.Retry(blocking)
+	if err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	waitForStatus(t, q, retried, StatusSucceeded)
+	if len(runs) != 2 {
+		t.Errorf("job ran %d times, want 2", len(runs))
+	}
+}
+
+func TestQueuePauseAndResume(t *testing.T) {
+	q := NewQueue(1)
+	q.Pause()
+	id := q.Submit("Test", "paused", func(ctx context.Context, progress ProgressFunc) error {
+		return nil
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	if job, _ := q.Get(id); job.Status != StatusQueued {
+		t.Fatalf("status while paused = %s, want %s", job.Status, StatusQueued)
+	}
+	if _, ok := q.LastFinished(); ok {
+		t.Error("LastFinished returned a job before any finished")
+	}
+
+	q.Resume()
+	waitForStatus(t, q, id, StatusSucceeded)
+	if last, ok := q.LastFinished(); !ok || last.ID != id {
+		t.Errorf("LastFinished = %v, %v; want job %d", last.ID, ok, id)
+	}
+}
+
+func TestQueueStoreKeepsHistory(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	q := NewQueue(1)
+	if err := q.SetStore(db); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	id := q.Submit("Test", "fail", func(ctx context.Context, progress ProgressFunc) error {
+		return errors.New("boom")
+	})
+	waitForStatus(t, q, id, StatusFailed)
+	// The job is saved just after its status changes
+	var count int
+	for deadline := time.Now().Add(2 * time.Second); count == 0 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&count)
+	}
+
+	restarted := NewQueue(1)
+	if err := restarted.SetStore(db); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	job, ok := restarted.Get(id)
+	if !ok || job.Status != StatusFailed || job.Err == nil || job.Err.Error() != "boom" || job.Finished.IsZero() {
+		t.Fatalf("Expected the failed job to be restored, got %+v", job)
+	}
+	if _, err := restarted.Retry(id); err == nil {
+		t.Errorf("Expected retrying a job from an earlier session to fail")
+	}
+
+	restarted.ClearFinished()
+	db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected ClearFinished to clear the stored history, %d left", count)
+	}
+	if next := restarted.Submit("Test", "new", func(ctx context.Context, progress ProgressFunc) error { return nil }); next <= id {
+		t.Errorf("Expected new job IDs to continue after %d, got %d", id, next)
+	}
+}
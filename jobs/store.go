@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+// SetStore keeps finished jobs in db so the history survives a restart, and
+// loads the history of earlier sessions. Jobs from earlier sessions can't be
+// retried because their work function is gone.
+func (q *Queue) SetStore(db *storage.DB) error {
+	rows, err := db.Query(`SELECT id, kind, title, status, message, error, created, started, finished
+		FROM jobs ORDER BY id DESC LIMIT ?`, q.maxHistory)
+	if err != nil {
+		return fmt.Errorf("failed to load job history: %w", err)
+	}
+	defer rows.Close()
+	var loaded []Job
+	for rows.Next() {
+		var job Job
+		var errText string
+		var created, started, finished int64
+		if err := rows.Scan(&job.ID, &job.Kind, &job.Title, &job.Status, &job.Message, &errText, &created, &started, &finished); err != nil {
+			return fmt.Errorf("failed to load job history: %w", err)
+		}
+		if errText != "" {
+			job.Err = errors.New(errText)
+		}
+		job.Created, job.Started, job.Finished = storage.DecodeTime(created), storage.DecodeTime(started), storage.DecodeTime(finished)
+		loaded = append(loaded, job)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load job history: %w", err)
+	}
+
+	q.mutex.Lock()
+	q.db = db
+	for _, job := range loaded {
+		if _, exists := q.entries[job.ID]; !exists {
+			q.entries[job.ID] = &entry{job: job, cancel: func() {}}
+		}
+		if job.ID >= q.nextID {
+			q.nextID = job.ID + 1
+		}
+	}
+	q.pruneLocked()
+	q.mutex.Unlock()
+
+	logger.Info("Loaded job history", "jobs", len(loaded))
+	q.notify()
+	return nil
+}
+
+// persist saves a finished job, dropping stored jobs beyond maxHistory. Must
+// be called without the mutex held.
+func (q *Queue) persist(job Job) {
+	q.mutex.Lock()
+	db, maxHistory := q.db, q.maxHistory
+	q.mutex.Unlock()
+	if db == nil {
+		return
+	}
+	errText := ""
+	if job.Err != nil {
+		errText = job.Err.Error()
+	}
+	_, err := db.Exec(`INSERT OR REPLACE INTO jobs (id, kind, title, status, message, error, created, started, finished)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.Kind, job.Title, string(job.Status), job.Message, errText,
+		storage.EncodeTime(job.Created), storage.EncodeTime(job.Started), storage.EncodeTime(job.Finished))
+	if err == nil {
+		_, err = db.Exec(`DELETE FROM jobs WHERE id NOT IN (SELECT id FROM jobs ORDER BY id DESC LIMIT ?)`, maxHistory)
+	}
+	if err != nil {
+		logger.Warn("Failed to save job history", logging.JobID(job.ID), "error", err)
+	}
+}
+
+// forgetFinished removes finished jobs from the stored history.
+func (q *Queue) forgetFinished() {
+	q.mutex.Lock()
+	db := q.db
+	q.mutex.Unlock()
+	if db == nil {
+		return
+	}
+	if _, err := db.Exec(`DELETE FROM jobs`); err != nil {
+		logger.Warn("Failed to clear job history", "error", err)
+	}
+}
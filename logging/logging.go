@@ -0,0 +1,236 @@
+// Package logging provides the application's structured logger. Every
+// record is written as a text line to the current output and also handed to
+// subscribers (the log console, the generation log dialog) as a Record, so
+// they can filter on level and fields instead of parsing text.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field keys shared across packages so records can be filtered consistently.
+const (
+	KeyComponent = "component"
+	KeySite      = "site"
+	KeyModel     = "model"
+	KeyJobID     = "job_id"
+)
+
+// Site, Model and JobID build the common record fields.
+func Site(name string) slog.Attr  { return slog.String(KeySite, name) }
+func Model(name string) slog.Attr { return slog.String(KeyModel, name) }
+func JobID(id int) slog.Attr      { return slog.Int(KeyJobID, id) }
+
+// Record is one log entry as seen by subscribers.
+type Record struct {
+	Time      time.Time
+	Level     slog.Level
+	Component string
+	Message   string
+	Attrs     []slog.Attr // All other fields, in the order they were added
+}
+
+// Attr returns the value of a field as a string, or "" if it's not set.
+func (r Record) Attr(key string) string {
+	if key == KeyComponent {
+		return r.Component
+	}
+	for _, a := range r.Attrs {
+		if a.Key == key {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+// String formats the record as a single text line, e.g.
+// "2025/05/03 10:04:05 INFO [wordpress] Connected site=Blog".
+func (r Record) String() string {
+	var b strings.Builder
+	b.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	b.WriteString(r.Level.String())
+	if r.Component != "" {
+		b.WriteString(" [" + r.Component + "]")
+	}
+	b.WriteString(" " + r.Message)
+	for _, a := range r.Attrs {
+		value := a.Value.String()
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		b.WriteString(" " + a.Key + "=" + value)
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the record as a flat JSON object with time, level,
+// component and msg keys followed by the record's fields.
+func (r Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	rec := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+	if r.Component != "" {
+		rec.AddAttrs(slog.String(KeyComponent, r.Component))
+	}
+	rec.AddAttrs(r.Attrs...)
+	if err := h.Handle(context.Background(), rec); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// hub holds the state shared by every handler derived from the root one.
+type hub struct {
+	mu          sync.Mutex
+	out         io.Writer
+	subscribers map[int]func(Record)
+	nextID      int
+}
+
+var root = &hub{out: os.Stderr, subscribers: map[int]func(Record){}}
+
+// minLevel drops records below it; set LOG_LEVEL=debug to see lock and
+// request tracing.
+var minLevel slog.LevelVar
+
+func init() {
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		if err := minLevel.UnmarshalText([]byte(env)); err != nil {
+			fmt.Fprintf(os.Stderr, "logging: ignoring invalid LOG_LEVEL %q\n", env)
+		}
+	}
+	// Route the standard log package (and libraries using it) through the
+	// same handler, so their output reaches the console as records too.
+	slog.SetDefault(slog.New(&handler{hub: root}))
+	log.SetFlags(0)
+}
+
+// For returns a logger whose records carry the given component.
+func For(component string) *slog.Logger {
+	return slog.New(&handler{hub: root, component: component})
+}
+
+// SetOutput sets where formatted records are written (stderr by default)
+// and returns the previous output.
+func SetOutput(w io.Writer) io.Writer {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	previous := root.out
+	root.out = w
+	return previous
+}
+
+// Subscribe calls fn for every record logged from now on, until the returned
+// cancel function is called. fn runs on the logging goroutine and must not
+// block or log.
+func Subscribe(fn func(Record)) (cancel func()) {
+	root.mu.Lock()
+	id := root.nextID
+	root.nextID++
+	root.subscribers[id] = fn
+	root.mu.Unlock()
+	return func() {
+		root.mu.Lock()
+		delete(root.subscribers, id)
+		root.mu.Unlock()
+	}
+}
+
+// handler is the slog.Handler behind every logger from this package.
+type handler struct {
+	hub       *hub
+	component string
+	attrs     []slog.Attr
+	group     string // Prefix for attribute keys added under WithGroup
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= minLevel.Level()
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	rec := Record{
+		Time:      r.Time,
+		Level:     r.Level,
+		Component: h.component,
+		Message:   r.Message,
+		Attrs:     append([]slog.Attr(nil), h.attrs...),
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = h.appendAttr(rec.Attrs, &rec.Component, a)
+		return true
+	})
+
+	h.hub.mu.Lock()
+	out := h.hub.out
+	subscribers := make([]func(Record), 0, len(h.hub.subscribers))
+	for _, fn := range h.hub.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	var err error
+	if out != nil {
+		_, err = io.WriteString(out, rec.String()+"\n")
+	}
+	h.hub.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(rec)
+	}
+	return err
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		next.attrs = h.appendAttr(next.attrs, &next.component, a)
+	}
+	return &next
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = h.group + name + "."
+	return &next
+}
+
+// appendAttr adds a to attrs, flattening groups into dotted keys. A
+// "component" field replaces the handler's component instead.
+func (h *handler) appendAttr(attrs []slog.Attr, component *string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		prefix := h.group
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, member := range a.Value.Group() {
+			member.Key = prefix + member.Key
+			attrs = append(attrs, member)
+		}
+		return attrs
+	}
+	if a.Key == KeyComponent && h.group == "" {
+		*component = a.Value.String()
+		return attrs
+	}
+	a.Key = h.group + a.Key
+	return append(attrs, a)
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// capture collects records and text output until the test ends.
+func capture(t *testing.T) (*[]Record, *bytes.Buffer) {
+	t.Helper()
+	var records []Record
+	var out bytes.Buffer
+	previous := SetOutput(&out)
+	cancel := Subscribe(func(r Record) { records = append(records, r) })
+	t.Cleanup(func() {
+		cancel()
+		SetOutput(previous)
+	})
+	return &records, &out
+}
+
+func TestLoggerRecordsFields(t *testing.T) {
+	records, out := capture(t)
+
+	For("wordpress").With(Site("Blog")).Warn("Page update failed", "page_id", 42, JobID(7))
+
+	if len(*records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(*records))
+	}
+	r := (*records)[0]
+	if r.Component != "wordpress" || r.Level != slog.LevelWarn || r.Message != "Page update failed" {
+		t.Errorf("Unexpected record: %+v", r)
+	}
+	for key, want := range map[string]string{KeySite: "Blog", "page_id": "42", KeyJobID: "7", KeyComponent: "wordpress"} {
+		if got := r.Attr(key); got != want {
+			t.Errorf("Attr(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if !strings.Contains(out.String(), "WARN [wordpress] Page update failed site=Blog page_id=42 job_id=7") {
+		t.Errorf("Unexpected text output: %q", out.String())
+	}
+}
+
+func TestStandardLogIsCaptured(t *testing.T) {
+	records, _ := capture(t)
+
+	log.Printf("legacy message %d", 1)
+
+	if len(*records) != 1 || (*records)[0].Message != "legacy message 1" {
+		t.Fatalf("Expected the standard logger to produce a record, got %+v", *records)
+	}
+}
+
+func TestGroupsAndQuoting(t *testing.T) {
+	records, _ := capture(t)
+
+	For("inference").WithGroup("usage").Info("Tokens counted", "prompt", 10, slog.Group("limits", "max", 20))
+
+	r := (*records)[0]
+	if r.Attr("usage.prompt") != "10" || r.Attr("usage.limits.max") != "20" {
+		t.Errorf("Expected grouped keys, got %+v", r.Attrs)
+	}
+	quoted := Record{Message: "m", Attrs: []slog.Attr{slog.String("error", "bad thing")}}.String()
+	if !strings.HasSuffix(quoted, `error="bad thing"`) {
+		t.Errorf("Expected values with spaces to be quoted, got %q", quoted)
+	}
+}
+
+func TestRecordJSON(t *testing.T) {
+	records, _ := capture(t)
+
+	For("jobs").Info("Job finished", JobID(3), "status", "Done")
+
+	data, err := json.Marshal((*records)[0])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", data, err)
+	}
+	if decoded["msg"] != "Job finished" || decoded[KeyComponent] != "jobs" || decoded[KeyJobID] != float64(3) || decoded["level"] != "INFO" {
+		t.Errorf("Unexpected JSON record: %s", data)
+	}
+}
@@ -1,16 +1,43 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt" // Import fmt
-	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 	
+	"Inference_Engine/access"
+	"Inference_Engine/analytics"
+	"Inference_Engine/audit"
+	"Inference_Engine/connectivity"
+	"Inference_Engine/crash"
+	"Inference_Engine/editorial"
+	"Inference_Engine/embeddings"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/logging"
+	"Inference_Engine/notify"
+	"Inference_Engine/portable"
+	"Inference_Engine/postprocess"
+	"Inference_Engine/searchconsole"
+	"Inference_Engine/settings"
+	"Inference_Engine/storage"
+	"Inference_Engine/tracing"
+	"Inference_Engine/transcript"
 	"Inference_Engine/ui"
+	"Inference_Engine/update"
+	"Inference_Engine/vault"
+	"Inference_Engine/versioning"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/dialog"
 
 	"github.com/joho/godotenv"
 
@@ -18,27 +45,88 @@ import (
 	_ "Inference_Engine/inference"
 )
 
+var logger = logging.For("app")
+
+// providerProbeInterval is how often the providers' reachability and latency
+// are checked.
+const providerProbeInterval = 2 * time.Minute
+
+// localKeepAliveInterval is how often the models of local providers are
+// pinged to stay loaded, shorter than Ollama's default five minutes.
+const localKeepAliveInterval = 4 * time.Minute
+
+// connectivityInterval is how often the network is checked, to switch to
+// offline mode and back.
+const connectivityInterval = 30 * time.Second
+
 func main() {
+	// A panic on the main goroutine still ends the app, but leaves a report
+	defer crash.Fatal("main")
 
-	// --- Setup Logging Early ---
-	// Keep original log output
-	originalLogOutput := log.Writer()
+	portableMode := flag.Bool("portable", false, "keep all settings, caches and databases in a data directory next to the executable")
+	flag.Parse()
+	if *portableMode {
+		dataDir, err := portable.DataDir()
+		if err == nil {
+			err = portable.Enable(dataDir)
+		}
+		if err != nil {
+			logger.Error("Portable mode unavailable", "error", err)
+			os.Exit(1)
+		}
+		// A .env next to the executable travels with it
+		if err := godotenv.Load(filepath.Join(filepath.Dir(dataDir), ".env")); err == nil {
+			logger.Info("Loaded .env from the executable's directory")
+		}
+	}
 
 	// Load .env file contents into environment variables
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Warning: Error loading .env file:", err)
+		logger.Warn("Error loading .env file", "error", err)
 	}
 	// Ensure GEMINI_API_KEY is also loaded if present in .env
 
+	// Trace jobs, generations and saves when an OTLP endpoint is configured
+	stopTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		logger.Error("Tracing unavailable", "error", err)
+		stopTracing = func(context.Context) error { return nil }
+	}
+
 	a := app.NewWithID("com.inc-line.wordpressinferenceengine")
-	a.Settings().SetTheme(&ui.HighContrastTheme{})
+	// Every view reads and writes its settings through the app preferences
+	settings.Use(settings.New(a.Preferences()))
+	if n := settings.ApplyEnv(ui.EnvSettings); n > 0 {
+		logger.Info("Applied settings imported from the environment", "count", n)
+	}
+	ui.ApplyTheme(a, ui.SavedThemeName())
+	ui.ApplySavedLanguage()
 	w := a.NewWindow("Wordpress Inference Engine")
 
 	// Initialize the consolidated inference service
 	inferenceService := inference.NewInferenceService()
 	wpService := wordpress.NewWordPressService()
 
+	// Saved sites, usage, job history, drafts, prompt histories and caches
+	// share one database; without it each falls back to its own files
+	stateDir := a.Storage().RootURI().Path()
+	crash.SetDir(filepath.Join(stateDir, "crashes"))
+	ui.ShowCrashReports(w)
+	stateDB, err := storage.Open(filepath.Join(stateDir, "state.db"))
+	if err != nil {
+		logger.Error("State database unavailable, history will not be kept", "error", err)
+	} else {
+		defer stateDB.Close()
+		ui.SetStateStore(stateDB)
+		if err := wpService.SetStateStore(stateDB); err != nil {
+			logger.Error("Could not move saved sites to the state database", "error", err)
+		}
+		if err := inferenceService.SetStateStore(stateDB); err != nil {
+			logger.Error("Could not load usage statistics", "error", err)
+		}
+	}
+
 	// ... (updateWindowTitle logic remains the same) ...
 	updateWindowTitle := func() {
 		title := "Wordpress Inference Engine"
@@ -59,58 +147,319 @@ func main() {
 
 
 
-	// Try to start the inference service (which now configures both LLMs)
-	if err := inferenceService.Start(); err != nil {
-		log.Printf("ERROR: Failed to start inference service: %v", err)
-		// Provide a more generic error message as specific provider might vary
-		dialog.ShowError(fmt.Errorf("Failed to start inference service components: %v\nPlease check API keys (Cerebras, Gemini) and configuration.", err), w)
-	} else {
-		log.Println("Inference service started successfully.") // More generic success message
+	// Secrets encrypted with a master password; while a vault exists, the
+	// services start once it is unlocked at startup
+	secrets, err := vault.Open(filepath.Join(stateDir, "vault.json"))
+	if err != nil {
+		logger.Error("Vault unavailable", "error", err)
+	}
+	vaultLocked := secrets != nil && secrets.Exists()
+
+	// Try to start the inference service (which now configures both LLMs),
+	// then probe the providers' latency and load the local models in the
+	// background
+	stopProbing := func() {}
+	stopKeepAlive := func() {}
+	startInference := func() {
+		if err := inferenceService.Start(); err != nil {
+			logger.Error("Failed to start inference service", "error", err)
+			// Provide a more generic error message as specific provider might vary
+			ui.ShowError(fmt.Errorf("Failed to start inference service components: %v\nPlease check API keys (Cerebras, Gemini) and configuration.", err), w)
+		} else {
+			logger.Info("Inference service started successfully") // More generic success message
+			stopProbing = inferenceService.StartProbing(providerProbeInterval)
+			stopKeepAlive = inferenceService.StartLocalKeepAlive(localKeepAliveInterval)
+		}
+	}
+	if !vaultLocked {
+		startInference()
+	}
+
+	// Background jobs (generation, chat, page updates) shown in the Activity tab
+	jobQueue := jobs.NewQueue(2)
+	if stateDB != nil {
+		if err := jobQueue.SetStore(stateDB); err != nil {
+			logger.Error("Could not load job history", "error", err)
+		}
 	}
 
 	// Create views
 	contentManagerView := ui.NewContentManagerView(wpService, inferenceService, w)
 	contentGeneratorView := ui.NewContentGeneratorView(wpService, inferenceService, w)
+	contentGeneratorView.RestoreSources()
 	inferenceSettingsView := ui.NewInferenceSettingsView(inferenceService, w)
 	wordpressSettingsView := ui.NewWordPressSettingsView(wpService, w)
+	profiles := access.Open(stateDB)
+	profileSettingsView := ui.NewProfileSettingsView(profiles, w)
+	wpService.SetWriteGuard(func() error { return profiles.Check(access.PermPublish) })
+	wordpressSettingsView.SetCredentialsEditable(profiles.Can(access.PermCredentials))
+	profiles.OnChange(func(role access.Role) {
+		wordpressSettingsView.SetCredentialsEditable(role.Can(access.PermCredentials))
+	})
+	appearanceSettingsView := ui.NewAppearanceSettingsView(a, w)
+	styleGuide := editorial.NewStyleGuideStore(stateDB)
+	styleGuideSettingsView := ui.NewStyleGuideSettingsView(styleGuide, w)
+	glossaries := editorial.NewGlossaryStore(stateDB)
+	glossarySettingsView := ui.NewGlossarySettingsView(glossaries, wpService, w)
+	siteInstructions := editorial.NewSiteInstructionsStore(stateDB)
+	siteInstructionsView := ui.NewSiteInstructionsView(siteInstructions, wpService, w)
+	disclaimers := editorial.NewDisclaimerStore(stateDB)
+	relatedPosts := editorial.NewRelatedPostsStore(stateDB)
+	relatedPostsView := ui.NewRelatedPostsView(relatedPosts, wpService, w)
+	embeddingIndex := embeddings.NewStore(stateDB)
+	notifier := notify.NewNotifier(stateDB)
+	notificationSettingsView := ui.NewNotificationSettingsView(notifier, w)
 	inferenceChatView := ui.NewInferenceChatView(inferenceService, w) // <-- Renamed view instance
 	testInferenceView := ui.NewTestInferenceView(inferenceService, w)   // <-- New view instance
+	statusBar := ui.NewStatusBar(wpService, inferenceService)
+	siteSwitcher := ui.NewSiteSwitcher(wpService, w)
+	activityView := ui.NewActivityView(jobQueue, w)
+	activityView.SetWordPressService(wpService)
+	freshnessView := ui.NewFreshnessView(audit.NewFreshnessStore(stateDB), wpService, inferenceService, w)
+	seoAuditView := ui.NewSEOAuditView(wpService, inferenceService, w)
+	duplicatesView := ui.NewDuplicatesView(embeddingIndex, audit.NewDismissedPairs(stateDB), wpService, inferenceService, w)
+	topicPlannerView := ui.NewTopicPlannerView(wpService, inferenceService, w)
+	trafficView := ui.NewTrafficView(wpService, w)
+
+	versions, err := versioning.FromEnv()
+	if err != nil {
+		logger.Error("Git versioning disabled", "error", err)
+	} else if versions != nil {
+		wpService.SetPageContentHook(versions.RecordPage)
+	}
+
+	contentManagerView.SetJobQueue(jobQueue)
+	contentGeneratorView.SetJobQueue(jobQueue)
+	contentGeneratorView.SetStyleGuide(styleGuide)
+	contentGeneratorView.SetVoiceProfiles(editorial.NewVoiceProfileStore(stateDB))
+	contentGeneratorView.SetGlossaries(glossaries)
+	contentGeneratorView.SetSiteInstructions(siteInstructions)
+	contentGeneratorView.SetDisclaimers(disclaimers)
+	contentGeneratorView.SetRelatedPosts(relatedPosts, embeddingIndex)
+	if drafts, err := history.Open(stateDB); err != nil {
+		logger.Error("Generation history disabled", "error", err)
+	} else {
+		if err := drafts.ImportFile(filepath.Join(stateDir, "generation_history.json")); err != nil {
+			logger.Error("Could not import the previous generation history", "error", err)
+		}
+		if versions != nil {
+			drafts.OnAdd(func(d history.Draft) {
+				versions.RecordDraft(wpService.GetCurrentSiteName(), d)
+			})
+		}
+		contentGeneratorView.SetDraftHistory(drafts)
+		contentManagerView.SetDraftHistory(drafts)
+		seoAuditView.SetDraftHistory(drafts)
+	}
+	inferenceChatView.SetJobQueue(jobQueue)
+	inferenceChatView.SetContentGeneratorView(contentGeneratorView)
+	if conversations, err := transcript.Open(stateDB); err != nil {
+		logger.Error("Saved chat conversations disabled", "error", err)
+	} else {
+		inferenceChatView.SetConversationStore(conversations)
+	}
+	freshnessView.SetJobQueue(jobQueue)
+	freshnessView.SetContentGeneratorView(contentGeneratorView)
+	duplicatesView.SetJobQueue(jobQueue)
+	seoAuditView.SetJobQueue(jobQueue)
+	duplicatesView.SetContentGeneratorView(contentGeneratorView)
+	topicPlannerView.SetJobQueue(jobQueue)
+	topicPlannerView.SetContentGeneratorView(contentGeneratorView)
+	if searchConsole, err := searchconsole.FromEnv(); err != nil {
+		logger.Error("Search Console integration disabled", "error", err)
+	} else if searchConsole != nil {
+		contentGeneratorView.SetSearchConsole(searchConsole)
+		seoAuditView.SetSearchConsole(searchConsole)
+	}
+	if source, err := analytics.FromEnv(); err != nil {
+		logger.Error("Analytics integration disabled", "error", err)
+	} else if source != nil {
+		pageViews := analytics.NewService(source)
+		trafficView.SetAnalytics(pageViews)
+		freshnessView.SetAnalytics(pageViews)
+		topicPlannerView.SetAnalytics(pageViews)
+	}
+	trafficView.SetJobQueue(jobQueue)
+	trafficView.SetContentGeneratorView(contentGeneratorView)
+	var vaultLock *ui.VaultLock
+	var vaultSettingsView *ui.VaultSettingsView
+	if secrets != nil {
+		vaultLock = ui.NewVaultLock(a, secrets, w)
+		vaultSettingsView = ui.NewVaultSettingsView(a, secrets, vaultLock, w)
+		inferenceSettingsView.SetVault(secrets)
+		useVault := func() {
+			if err := wpService.SetSecretStore(secrets); err != nil {
+				logger.Error("Could not move site passwords to the vault", "error", err)
+			}
+		}
+		vaultSettingsView.SetOnCreated(useVault)
+		var unlockOnce sync.Once
+		vaultLock.OnUnlock(func() {
+			secrets.ApplyEnv()
+			unlockOnce.Do(func() {
+				useVault()
+				startInference()
+				inferenceSettingsView.ReloadKeys()
+				siteSwitcher.Resume() // The site's password may be in the vault
+				ui.OfferEnvImport(secrets, w)
+			})
+		})
+	}
+	jobQueue.OnChange(statusBar.Refresh)
+	jobQueue.OnFinished(func(job jobs.Job) {
+		notifier.JobFinished(job, wpService.GetCurrentSiteName())
+	})
+	if budget := os.Getenv("DAILY_TOKEN_BUDGET"); budget != "" {
+		if tokens, err := strconv.Atoi(budget); err != nil {
+			logger.Error("Ignoring invalid DAILY_TOKEN_BUDGET", "value", budget, "error", err)
+		} else {
+			inferenceService.SetDailyTokenBudget(tokens)
+		}
+	}
+	inferenceService.OnMOADowngrade(func(downgrade inference.MOADowngrade) {
+		a.SendNotification(fyne.NewNotification(i18n.T("MOA Limit Reached"), i18n.Tf("%s; generating with %s alone.", downgrade.Reason, downgrade.Model)))
+	})
+	inferenceService.OnBudgetExceeded(func(stats inference.UsageStats) {
+		notifier.Notify(notify.EventBudgetExceeded, "Daily token budget exceeded",
+			fmt.Sprintf("About %d tokens spent today in %d requests, over the budget of %d.", stats.TokensToday, stats.JobsToday, stats.TokenBudget))
+	})
+
+	// Offline, pages are read from the cache, generation uses local models
+	// only and page updates are queued until the network returns
+	var replayQueued func()
+	replayQueued = func() {
+		if wpService.Offline() || len(wpService.QueuedUpdates()) == 0 {
+			return
+		}
+		// The saves that queued these updates weren't reported as publishes, so the replay reports them
+		jobQueue.Submit("Offline Replay", i18n.T("Save updates made offline"), func(ctx context.Context, report jobs.ProgressFunc) error {
+			saved, err := wpService.ReplayQueued(ctx)
+			statusBar.Refresh()
+			site := wpService.GetCurrentSiteName()
+			if saved > 0 {
+				notifier.Published(site, fmt.Sprintf("%d page updates made offline", saved), nil)
+				a.SendNotification(fyne.NewNotification(i18n.T("Back Online"), i18n.Tf("Saved %d page updates made offline.", saved)))
+			}
+			if ui.AskQueuedConflict(err, wpService, w, replayQueued) {
+				return jobs.Deferred(err) // The user decides; the rest of the queue waits
+			}
+			if err != nil {
+				notifier.Published(site, "Page updates made offline", err)
+			}
+			return err
+		})
+	}
+	network := connectivity.NewMonitor(nil)
+	network.OnChange(func(online bool) {
+		wpService.SetOffline(!online)
+		inference.SetOffline(!online)
+		statusBar.Refresh()
+		if online {
+			replayQueued()
+		}
+	})
+	stopNetworkChecks := network.Start(connectivityInterval)
+
+	// Keep the site switcher, settings and manager in sync whichever one changes the connection
+	siteSwitcher.SetOnSiteChanged(func(connected bool) {
+		if connected {
+			replayQueued() // Updates queued for this site in an earlier session
+		}
+		wordpressSettingsView.UpdateConnectionStatus(connected)
+		contentManagerView.SiteChanged()
+		freshnessView.SiteChanged()
+		duplicatesView.SiteChanged()
+		seoAuditView.SiteChanged()
+		trafficView.SiteChanged()
+		ui.ApplyCapabilities(wpService.Capabilities())
+		postprocess.SetHeadingCase(siteInstructions.HeadingCase(wpService.GetCurrentSiteName()))
+		statusBar.Refresh()
+	})
+	wordpressSettingsView.SetOnConnectionChanged(func(connected bool) {
+		siteSwitcher.RefreshSites()
+		contentManagerView.SiteChanged()
+		freshnessView.SiteChanged()
+		duplicatesView.SiteChanged()
+		seoAuditView.SiteChanged()
+		trafficView.SiteChanged()
+		ui.ApplyCapabilities(wpService.Capabilities())
+		postprocess.SetHeadingCase(siteInstructions.HeadingCase(wpService.GetCurrentSiteName()))
+		statusBar.Refresh()
+	})
+	wordpressSettingsView.SetOnSavedSitesChanged(func() {
+		siteSwitcher.RefreshSites()
+		glossarySettingsView.RefreshSites()
+		siteInstructionsView.RefreshSites()
+		relatedPostsView.RefreshSites()
+	})
 	
 	// Link manager and generator
 	contentManagerView.SetContentGeneratorView(contentGeneratorView)
+
+	// Files dropped from the OS file manager go to the generator's source list
+	w.SetOnDropped(contentGeneratorView.HandleDroppedURIs)
 	
 
-	// --- Setup Log Redirection ---
+	// --- Setup Log Console ---
+	// Records still go to stderr; the console keeps them for filtering and export
+	var logConsole *ui.LogConsole
 	logConsoleWidget := testInferenceView.LogConsoleWidget()
 	if logConsoleWidget != nil {
-		logWriter := ui.NewUILogWriter(logConsoleWidget, originalLogOutput)
-		log.SetOutput(logWriter)
-		log.Println("--- Log output redirected to UI console ---")
+		logConsole = ui.NewLogConsole(logConsoleWidget)
+		testInferenceView.SetLogConsole(logConsole)
+		logger.Info("Log console attached")
 	} else {
-		log.Println("Error: Could not get log console widget, log redirection skipped.")
+		logger.Error("Could not get log console widget, log console disabled")
 	}
-	// --- End Log Redirection ---
+	// --- End Log Console ---
 
 	// Combine settings views
-	settingsContent := container.NewAdaptiveGrid(2, // <--- Changed from NewVBox
-		inferenceSettingsView.Container(),
-		wordpressSettingsView.Container(),
+	settingsContent := container.NewVBox(
+		profileSettingsView.Container(),
+		container.NewAdaptiveGrid(2, // <--- Changed from NewVBox
+			ui.NewRoleGate(profiles, access.PermProviders, inferenceSettingsView.Container()),
+			wordpressSettingsView.Container(),
+		),
+		appearanceSettingsView.Container(),
+		styleGuideSettingsView.Container(),
+		ui.NewPostProcessView().Container(),
+		glossarySettingsView.Container(),
+		siteInstructionsView.Container(),
+		ui.NewDisclaimerSettingsView(disclaimers, w).Container(),
+		relatedPostsView.Container(),
+		notificationSettingsView.Container(),
+		ui.NewRoleGate(profiles, access.PermProviders, ui.NewProviderSettingsView(w).Container()),
+		ui.NewRoleGate(profiles, access.PermProviders, ui.NewFallbackPolicyView(w).Container()),
 	)
+	if vaultSettingsView != nil {
+		settingsContent.Add(ui.NewRoleGate(profiles, access.PermCredentials, vaultSettingsView.Container()))
+	}
+	updateChecker := update.NewChecker()
+	settingsContent.Add(ui.NewUpdateSettingsView(a, updateChecker, w).Container())
 
 	
 
 	// --- Main Tabs ---
 	tabs := container.NewAppTabs(
-		container.NewTabItem("Manager", contentManagerView.Container()),
-		container.NewTabItem("Generator", contentGeneratorView.Container()),
-		container.NewTabItem("Settings", container.NewScroll(settingsContent)),
-		container.NewTabItem("Inference Chat", inferenceChatView.Container()), // <-- Renamed tab
-		container.NewTabItem("Test Inference", testInferenceView.Container()),
+		container.NewTabItem(i18n.T("Manager"), contentManagerView.Container()),
+		container.NewTabItem(i18n.T("Generator"), contentGeneratorView.Container()),
+		container.NewTabItem(i18n.T("Settings"), container.NewScroll(settingsContent)),
+		container.NewTabItem(i18n.T("Inference Chat"), inferenceChatView.Container()), // <-- Renamed tab
+		container.NewTabItem(i18n.T("Test Inference"), testInferenceView.Container()),
+		container.NewTabItem(i18n.T("Topic Planner"), topicPlannerView.Container()),
+		container.NewTabItem(i18n.T("Reports"), container.NewAppTabs(
+			container.NewTabItem(i18n.T("Freshness"), freshnessView.Container()),
+			container.NewTabItem(i18n.T("Traffic"), trafficView.Container()),
+			container.NewTabItem(i18n.T("Duplicates"), duplicatesView.Container()),
+			container.NewTabItem(i18n.T("SEO Audit"), seoAuditView.Container()),
+		)),
+		container.NewTabItem(i18n.T("Activity"), activityView.Container()),
 	)
 
 	// --- Add OnSelected callback ---
 	tabs.OnSelected = func(tab *container.TabItem) {
-		if tab.Text == "Manager" {
+		ui.PrefLastTab.Set(tabs.SelectedIndex())
+		if tab.Text == i18n.T("Manager") {
 			// When the Manager tab is selected, refresh its status
 			contentManagerView.RefreshStatus()
 		}
@@ -118,22 +467,71 @@ func main() {
 	}
 	// --- End of OnSelected callback ---
 
-	// Set the initial selected tab (optional, defaults to first)
-	tabs.SelectIndex(2) // Select Manager tab initially
+	// --- Keyboard Shortcuts ---
+	ui.RegisterAppShortcuts(w, ui.AppShortcutViews{
+		Tabs:      tabs,
+		Manager:   contentManagerView,
+		Generator: contentGeneratorView,
+		Chat:      inferenceChatView,
+		Activity:  activityView,
+	})
+	w.SetMainMenu(fyne.NewMainMenu(
+		fyne.NewMenu(i18n.T("Help"),
+			fyne.NewMenuItem(i18n.T("Keyboard Shortcuts"), func() { ui.ShowShortcutCheatsheet(w) }),
+		),
+	))
+
+	// Reopen the tab last open
+	tabs.SelectIndex(min(max(ui.PrefLastTab.Get(), 0), len(tabs.Items)-1))
 
-	// Ensure the service is stopped cleanly on exit
+	// Stop the services once, whether the app exits from the window or the tray
+	var shutdownOnce sync.Once
+	shutdown := func() {
+		shutdownOnce.Do(func() {
+			// Keep the session to resume where it was left at the next start
+			ui.SaveLayout(w)
+			ui.PrefLastSite.Set(wpService.GetCurrentSiteName())
+			contentGeneratorView.SaveSources()
+			statusBar.Stop()
+			stopProbing()
+			stopKeepAlive()
+			stopNetworkChecks()
+			logger.Info("Shutting down inference service")
+			if err := inferenceService.Stop(); err != nil {
+				logger.Error("Error stopping inference service", "error", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := stopTracing(ctx); err != nil {
+				logger.Error("Error flushing traces", "error", err)
+			}
+			cancel()
+			if logConsole != nil {
+				logConsole.Close()
+			}
+		})
+	}
+
+	// Closing the window hides it to the tray if enabled, so jobs keep running
+	hasTray := ui.SetupSystemTray(a, w, jobQueue)
 	w.SetCloseIntercept(func() {
-		log.Println("Shutting down inference service...")
-		if err := inferenceService.Stop(); err != nil {
-			log.Printf("Error stopping inference service: %v", err)
+		if hasTray && ui.MinimizeToTray() {
+			logger.Info("Window hidden to system tray; background jobs keep running")
+			w.Hide()
+			return
 		}
-		// --- Restore Original Log Output ---
-		log.SetOutput(originalLogOutput)
-		// --- End Restore ---
+		shutdown()
 		w.Close()
 	})
 
-	w.SetContent(tabs)
-	w.Resize(fyne.NewSize(1164, 800))
+	w.SetContent(container.NewBorder(siteSwitcher.Container(), statusBar.Container(), nil, nil, tabs))
+	w.Resize(fyne.NewSize(float32(ui.PrefWindowWidth.Get()), float32(ui.PrefWindowHeight.Get())))
+	if vaultLocked {
+		vaultLock.Lock()
+	} else {
+		siteSwitcher.Resume()
+		ui.OfferEnvImport(secrets, w)
+	}
+	ui.CheckForUpdatesAtStartup(a, updateChecker, w)
 	w.ShowAndRun()
+	shutdown() // The app can also quit from the tray menu
 }
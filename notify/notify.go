@@ -0,0 +1,310 @@
+// Package notify posts notifications about finished jobs, publishes and the
+// token budget to Slack, Discord or other webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/jobs"
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+var logger = logging.For("notify")
+
+// Event is something a webhook can be notified of.
+type Event string
+
+const (
+	EventJobFinished      Event = "job_finished"
+	EventPublishSucceeded Event = "publish_succeeded"
+	EventPublishFailed    Event = "publish_failed"
+	EventBudgetExceeded   Event = "budget_exceeded"
+)
+
+// Events lists every event, in the order they are documented.
+var Events = []Event{EventJobFinished, EventPublishSucceeded, EventPublishFailed, EventBudgetExceeded}
+
+// publishKinds are the job kinds of page updates and new posts, which are
+// reported as publishes rather than finished jobs.
+var publishKinds = map[string]bool{"Page Update": true, "Post Publish": true}
+
+// discordLimit is the most characters a Discord message may have.
+const discordLimit = 2000
+
+// webhooksDocument is the state database document holding the webhooks.
+const webhooksDocument = "notification_webhooks"
+
+// Example is shown in the editor as a starting point. It also documents the
+// format: one webhook URL per line, optionally followed by the events it
+// receives; "#" starts a comment.
+const Example = `# Every event
+https://hooks.slack.com/services/T000/B000/XXXX
+
+# Only publishes and the token budget
+https://discord.com/api/webhooks/000/XXXX publish_succeeded, publish_failed, budget_exceeded
+`
+
+// Webhook is a URL notified of some or all events.
+type Webhook struct {
+	URL    string
+	Events []Event // Empty for every event
+}
+
+// Wants reports whether the webhook receives event.
+func (w Webhook) Wants(event Event) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Service names the chat service the webhook belongs to, from its URL:
+// "Slack", "Discord", or "" for a generic JSON webhook.
+func (w Webhook) Service() string {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return "Slack"
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return "Discord"
+	}
+	return ""
+}
+
+// payload builds the JSON body each service expects. Generic webhooks get
+// the event and its parts as separate fields.
+func (w Webhook) payload(event Event, title, message string) ([]byte, error) {
+	text := title
+	if message != "" {
+		text += "\n" + message
+	}
+	switch w.Service() {
+	case "Slack":
+		return json.Marshal(map[string]string{"text": text})
+	case "Discord":
+		if runes := []rune(text); len(runes) > discordLimit {
+			text = string(runes[:discordLimit-1]) + "…"
+		}
+		return json.Marshal(map[string]string{"content": text})
+	}
+	return json.Marshal(map[string]string{"event": string(event), "title": title, "message": message, "text": text})
+}
+
+// ParseWebhooks reads one webhook per line: an http(s) URL, then optionally
+// the events it receives, separated by commas or spaces. Blank lines and
+// lines starting with "#" are skipped.
+func ParseWebhooks(text string) ([]Webhook, error) {
+	var webhooks []Webhook
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		u, err := url.Parse(fields[0])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("line %d: %q is not a webhook URL", n+1, fields[0])
+		}
+		webhook := Webhook{URL: fields[0]}
+		for _, field := range fields[1:] {
+			event, ok := parseEvent(field)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown event %q; use %s", n+1, field, eventNames())
+			}
+			webhook.Events = append(webhook.Events, event)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// parseEvent matches an event name, ignoring case.
+func parseEvent(name string) (Event, bool) {
+	for _, event := range Events {
+		if strings.EqualFold(name, string(event)) {
+			return event, true
+		}
+	}
+	return "", false
+}
+
+// eventNames lists the event names for error messages.
+func eventNames() string {
+	names := make([]string, len(Events))
+	for i, event := range Events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Notifier holds the configured webhooks, persisted in the state database,
+// and posts events to them. It is safe for concurrent use.
+type Notifier struct {
+	db   *storage.DB // nil keeps the webhooks in memory only
+	http *http.Client
+
+	mu       sync.Mutex
+	text     string
+	webhooks []Webhook
+}
+
+// NewNotifier loads the webhooks saved in db, if any. A nil db gives a
+// notifier that forgets them on exit.
+func NewNotifier(db *storage.DB) *Notifier {
+	n := &Notifier{db: db, http: &http.Client{Timeout: 15 * time.Second}}
+	if db == nil {
+		return n
+	}
+	text, ok, err := db.Document(webhooksDocument)
+	if err != nil {
+		logger.Error("Failed to load notification webhooks", "error", err)
+		return n
+	}
+	if !ok {
+		return n
+	}
+	webhooks, err := ParseWebhooks(text)
+	if err != nil {
+		logger.Warn("Saved notification webhooks no longer parse, ignoring them", "error", err)
+	}
+	n.text, n.webhooks = text, webhooks
+	return n
+}
+
+// Text returns the webhook list as written.
+func (n *Notifier) Text() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.text
+}
+
+// Webhooks returns the configured webhooks.
+func (n *Notifier) Webhooks() []Webhook {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Webhook(nil), n.webhooks...)
+}
+
+// Save registers a new webhook list. Invalid lists are rejected and the
+// previous webhooks are kept.
+func (n *Notifier) Save(text string) error {
+	webhooks, err := ParseWebhooks(text)
+	if err != nil {
+		return err
+	}
+	if n.db != nil {
+		if err := n.db.SetDocument(webhooksDocument, text); err != nil {
+			return err
+		}
+	}
+	n.mu.Lock()
+	n.text, n.webhooks = text, webhooks
+	n.mu.Unlock()
+	logger.Info("Saved notification webhooks", "webhooks", len(webhooks))
+	return nil
+}
+
+// Notify posts the event to every webhook that wants it, in the background.
+// Failures are logged, as no one is waiting for them.
+func (n *Notifier) Notify(event Event, title, message string) {
+	for _, webhook := range n.Webhooks() {
+		if !webhook.Wants(event) {
+			continue
+		}
+		go func(webhook Webhook) {
+			defer crash.Recover("Notifier.Notify")
+			if err := n.Send(context.Background(), webhook, event, title, message); err != nil {
+				logger.Warn("Failed to send notification", "event", event, "error", err)
+			}
+		}(webhook)
+	}
+}
+
+// Send posts one notification to webhook and waits for the answer.
+func (n *Notifier) Send(ctx context.Context, webhook Webhook, event Event, title, message string) error {
+	body, err := webhook.payload(event, title, message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook request failed: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// Test posts a test notification to every webhook, whatever its events, and
+// returns the first failure.
+func (n *Notifier) Test(ctx context.Context) error {
+	webhooks := n.Webhooks()
+	if len(webhooks) == 0 {
+		return fmt.Errorf("no webhooks are configured")
+	}
+	for _, webhook := range webhooks {
+		if err := n.Send(ctx, webhook, "test", "Test notification", "Notifications from Wordpress Inference Engine reach this channel."); err != nil {
+			return fmt.Errorf("%s: %w", webhook.URL, err)
+		}
+	}
+	return nil
+}
+
+// JobFinished notifies of a finished job on site: page updates and new
+// posts as publishes that succeeded or failed, every other job as a finished
+// job. Deferred publishes, such as saves queued offline, are left to be
+// reported when they are done.
+func (n *Notifier) JobFinished(job jobs.Job, site string) {
+	if publishKinds[job.Kind] && job.Status != jobs.StatusCanceled {
+		if job.Status != jobs.StatusDeferred {
+			n.Published(site, job.Title, job.Err)
+		}
+		return
+	}
+	message := fmt.Sprintf("%s (%s)", job.Title, job.Duration().Round(time.Second))
+	if job.Err != nil {
+		message += ": " + job.Err.Error()
+	}
+	n.Notify(EventJobFinished, fmt.Sprintf("%s job %s", job.Kind, strings.ToLower(string(job.Status))), message)
+}
+
+// Published notifies of what was published to site, or of the failure to
+// publish it when err is not nil.
+func (n *Notifier) Published(site, what string, err error) {
+	if site == "" {
+		site = "WordPress"
+	}
+	if err != nil {
+		n.Notify(EventPublishFailed, fmt.Sprintf("Publishing to %s failed", site), fmt.Sprintf("%s: %v", what, err))
+		return
+	}
+	n.Notify(EventPublishSucceeded, fmt.Sprintf("Published to %s", site), what)
+}
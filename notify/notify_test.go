@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Inference_Engine/jobs"
+	"Inference_Engine/storage"
+)
+
+func TestParseWebhooks(t *testing.T) {
+	webhooks, err := ParseWebhooks(Example)
+	if err != nil {
+		t.Fatalf("ParseWebhooks failed: %v", err)
+	}
+	if len(webhooks) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %+v", webhooks)
+	}
+	if webhooks[0].Service() != "Slack" || !webhooks[0].Wants(EventJobFinished) {
+		t.Errorf("Expected a Slack webhook for every event, got %+v", webhooks[0])
+	}
+	if webhooks[1].Service() != "Discord" || webhooks[1].Wants(EventJobFinished) || !webhooks[1].Wants(EventBudgetExceeded) {
+		t.Errorf("Expected a Discord webhook for publishes and the budget, got %+v", webhooks[1])
+	}
+
+	for _, text := range []string{"hooks.slack.com/services/x", "https://example.com/hook job_done"} {
+		if _, err := ParseWebhooks(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestNotifierPostsToWantedWebhooks(t *testing.T) {
+	received := make(chan map[string]string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("Expected a JSON body, got %q", data)
+		}
+		body["path"] = r.URL.Path
+		received <- body
+	}))
+	defer server.Close()
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+	if err := NewNotifier(db).Save(server.URL + "/all\n" + server.URL + "/publishes publish_failed"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	n := NewNotifier(db) // Reloads the saved webhooks
+	n.JobFinished(jobs.Job{Kind: "Page Update", Title: "Save page 7", Status: jobs.StatusFailed, Err: errors.New("HTTP 403")}, "Blog")
+	paths := map[string]map[string]string{}
+	for i := 0; i < 2; i++ {
+		select {
+		case body := <-received:
+			paths[body["path"]] = body
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected 2 notifications, got %d", i)
+		}
+	}
+	body := paths["/publishes"]
+	if body["event"] != string(EventPublishFailed) || body["title"] != "Publishing to Blog failed" || body["message"] != "Save page 7: HTTP 403" {
+		t.Errorf("Unexpected notification %+v", body)
+	}
+
+	n.JobFinished(jobs.Job{Kind: "Generation", Title: "Cats", Status: jobs.StatusSucceeded}, "Blog")
+	select {
+	case body := <-received:
+		if body["path"] != "/all" || body["event"] != string(EventJobFinished) {
+			t.Errorf("Expected only the catch-all webhook to get the finished job, got %+v", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification of the finished job")
+	}
+	select {
+	case body := <-received:
+		t.Errorf("Expected the publish webhook to skip the finished job, got %+v", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPayloadMatchesService(t *testing.T) {
+	slack, _ := Webhook{URL: "https://hooks.slack.com/services/T/B/X"}.payload(EventJobFinished, "Title", "Body")
+	if string(slack) != `{"text":"Title\nBody"}` {
+		t.Errorf("Unexpected Slack payload %s", slack)
+	}
+	discord, _ := Webhook{URL: "https://discord.com/api/webhooks/1/X"}.payload(EventJobFinished, "Title", "Body")
+	if string(discord) != `{"content":"Title\nBody"}` {
+		t.Errorf("Unexpected Discord payload %s", discord)
+	}
+}
+
+func TestPublishesOfPostsAndQueuedSaves(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer server.Close()
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+	n := NewNotifier(db)
+	if err := n.Save(server.URL + " publish_succeeded"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	n.JobFinished(jobs.Job{Kind: "Post Publish", Title: "Cold Brew", Status: jobs.StatusSucceeded}, "Blog")
+	select {
+	case body := <-received:
+		if body["event"] != string(EventPublishSucceeded) || body["title"] != "Published to Blog" || body["message"] != "Cold Brew" {
+			t.Errorf("Unexpected notification %+v", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the new post to be reported as a publish")
+	}
+	n.JobFinished(jobs.Job{Kind: "Page Update", Title: "Save page 7", Status: jobs.StatusDeferred, Message: "offline"}, "Blog")
+	select {
+	case body := <-received:
+		t.Errorf("Expected a save queued offline not to be reported yet, got %+v", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,130 @@
+// Package planning turns a keyword list into topic clusters, each planned as
+// one article.
+package planning
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"Inference_Engine/brief"
+	"Inference_Engine/embeddings"
+)
+
+// MaxKeywords caps one clustering run; clustering time grows with the cube
+// of the number of keywords.
+const MaxKeywords = 500
+
+// DefaultClusterThreshold is the average similarity from which keywords are
+// grouped into one article.
+const DefaultClusterThreshold = 0.75
+
+// keywordHeader matches the header of the keyword column in CSV exports of
+// keyword tools and Search Console.
+var keywordHeader = regexp.MustCompile(`(?i)^(keywords?|query|queries|search term|term|keyphrase|top queries)$`)
+
+// ParseKeywords reads keywords pasted one per line or exported as CSV (comma,
+// semicolon or tab separated). A CSV's keyword column is found by its
+// header, and defaults to the first. Duplicates are dropped, ignoring case.
+func ParseKeywords(text string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectDelimiter(text)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var keywords []string
+	seen := map[string]bool{}
+	column := 0
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyword list: %w", err)
+		}
+		if row == 0 && len(record) > 1 {
+			header := false
+			for i, cell := range record {
+				if keywordHeader.MatchString(strings.TrimSpace(cell)) {
+					column, header = i, true
+					break
+				}
+			}
+			if header {
+				continue
+			}
+		}
+		if column >= len(record) {
+			continue
+		}
+		keyword := strings.Join(strings.Fields(record[column]), " ")
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		keywords = append(keywords, keyword)
+	}
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("no keywords found")
+	}
+	if len(keywords) > MaxKeywords {
+		return nil, fmt.Errorf("%d keywords is too many to cluster at once; use at most %d", len(keywords), MaxKeywords)
+	}
+	return keywords, nil
+}
+
+// detectDelimiter picks the CSV delimiter from the first line: tab or
+// semicolon if present without commas, else comma. A plain list has none.
+func detectDelimiter(text string) rune {
+	first, _, _ := strings.Cut(text, "\n")
+	switch {
+	case strings.Contains(first, "\t"):
+		return '\t'
+	case strings.Contains(first, ";") && !strings.Contains(first, ","):
+		return ';'
+	}
+	return ','
+}
+
+// Cluster is a group of keywords one article can target.
+type Cluster struct {
+	Label    string   // The most central keyword
+	Keywords []string // The label first, then the rest in input order
+	Plan     *brief.Brief
+}
+
+// ClusterKeywords embeds the keywords and groups those at least threshold
+// similar, largest cluster first.
+func ClusterKeywords(ctx context.Context, embedder embeddings.Embedder, keywords []string, threshold float64) ([]Cluster, error) {
+	vectors, err := embedder.Embed(ctx, keywords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed keywords: %w", err)
+	}
+	var clusters []Cluster
+	for _, group := range embeddings.Cluster(vectors, threshold) {
+		central := embeddings.Central(vectors, group)
+		cluster := Cluster{Label: keywords[central], Keywords: []string{keywords[central]}}
+		for _, i := range group {
+			if i != central {
+				cluster.Keywords = append(cluster.Keywords, keywords[i])
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+// ParsePlan reads the model's article plan, a JSON object with the fields of
+// a content brief.
+func ParsePlan(output string) (brief.Brief, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return brief.Brief{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	return brief.Parse([]byte(output[start : end+1]))
+}
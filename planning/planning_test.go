@@ -0,0 +1,71 @@
+package planning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"Inference_Engine/embeddings"
+)
+
+func TestParseKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"pasted list", "cold brew\n  iced   coffee \n\nCold Brew\n", []string{"cold brew", "iced coffee"}},
+		{"CSV with header", "Volume,Keyword,KD\n1200,cold brew,12\n800,\"iced coffee, easy\",8\n", []string{"cold brew", "iced coffee, easy"}},
+		{"CSV without header", "cold brew,1200\niced coffee,800\n", []string{"cold brew", "iced coffee"}},
+		{"tab separated", "Top queries\tClicks\ncold brew\t40\n", []string{"cold brew"}},
+		{"semicolon separated", "Keyword;Volume\ncold brew;1200\n", []string{"cold brew"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseKeywords(tt.text)
+		if err != nil {
+			t.Errorf("%s: ParseKeywords failed: %v", tt.name, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err := ParseKeywords(" \n\n"); err == nil {
+		t.Errorf("Expected an error for an empty list")
+	}
+	var many strings.Builder
+	for i := 0; i <= MaxKeywords; i++ {
+		fmt.Fprintf(&many, "keyword %d\n", i)
+	}
+	if _, err := ParseKeywords(many.String()); err == nil {
+		t.Errorf("Expected an error for more than %d keywords", MaxKeywords)
+	}
+}
+
+func TestClusterKeywords(t *testing.T) {
+	keywords := []string{"cold brew coffee recipe", "espresso machine reviews", "cold brew coffee ratio", "best espresso machine", "cold brew coffee concentrate"}
+	clusters, err := ClusterKeywords(context.Background(), embeddings.LocalEmbedder{}, keywords, 0.3)
+	if err != nil {
+		t.Fatalf("ClusterKeywords failed: %v", err)
+	}
+	if len(clusters) != 2 || len(clusters[0].Keywords) != 3 || len(clusters[1].Keywords) != 2 {
+		t.Fatalf("Expected a cold brew and an espresso cluster, got %+v", clusters)
+	}
+	if !strings.Contains(clusters[0].Label, "cold brew") || clusters[0].Keywords[0] != clusters[0].Label {
+		t.Errorf("Expected a cold brew keyword to label the first cluster, got %+v", clusters[0])
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	plan, err := ParsePlan("Here is the plan:\n```json\n{\"title\": \"Cold Brew Guide\", \"target_keyword\": \"cold brew\", \"outline\": [\"Ratio\", \"Steeping\"], \"word_count\": 1500}\n```")
+	if err != nil {
+		t.Fatalf("ParsePlan failed: %v", err)
+	}
+	if plan.Title != "Cold Brew Guide" || len(plan.Outline) != 2 || plan.WordCount != 1500 {
+		t.Errorf("Unexpected plan: %+v", plan)
+	}
+	if _, err := ParsePlan(`{"title": "x", "sections": []}`); err == nil {
+		t.Errorf("Expected unknown fields to be rejected")
+	}
+}
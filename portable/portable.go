@@ -0,0 +1,60 @@
+// Package portable keeps all of the app's configuration, caches and
+// databases in a directory next to the executable, so it can run from a
+// USB stick without writing to the user's home directory.
+package portable
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("portable")
+
+// dataDirName is the directory next to the executable that holds the data.
+const dataDirName = "data"
+
+// DataDir returns the directory portable mode keeps its data in.
+func DataDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", fmt.Errorf("failed to find the executable: %w", err)
+	}
+	return filepath.Join(filepath.Dir(exe), dataDirName), nil
+}
+
+// Enable points the home, configuration and cache directories at dir, so
+// everything that derives its paths from them (Fyne's preferences and app
+// storage, the saved sites, the state database, the vault and the caches)
+// lives there instead. It must run before the Fyne app is created.
+func Enable(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	dirs := map[string]string{
+		"HOME":            filepath.Join(dir, "home"), // Unix, and Fyne's macOS preferences
+		"USERPROFILE":     filepath.Join(dir, "home"), // Windows home, where Fyne keeps its storage
+		"XDG_CONFIG_HOME": filepath.Join(dir, "config"),
+		"XDG_CACHE_HOME":  filepath.Join(dir, "cache"),
+		"XDG_DATA_HOME":   filepath.Join(dir, "share"),
+		"XDG_STATE_HOME":  filepath.Join(dir, "state"),
+		"APPDATA":         filepath.Join(dir, "config"), // Windows os.UserConfigDir
+		"LOCALAPPDATA":    filepath.Join(dir, "cache"),  // Windows os.UserCacheDir
+	}
+	for name, path := range dirs {
+		if err := os.MkdirAll(path, 0o700); err != nil {
+			return fmt.Errorf("failed to create the portable data directory: %w", err)
+		}
+		if err := os.Setenv(name, path); err != nil {
+			return err
+		}
+	}
+	logger.Info("Portable mode: keeping data next to the executable", "dir", dir)
+	return nil
+}
@@ -0,0 +1,31 @@
+package portable
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnableMovesUserDirectories(t *testing.T) {
+	for _, name := range []string{"HOME", "USERPROFILE", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "APPDATA", "LOCALAPPDATA"} {
+		t.Setenv(name, os.Getenv(name)) // Restored after the test
+	}
+	dir := t.TempDir()
+	if err := Enable(dir); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil || home != filepath.Join(dir, "home") {
+		t.Errorf("Expected the home directory in %s, got %s (%v)", dir, home, err)
+	}
+	for _, lookup := range []func() (string, error){os.UserConfigDir, os.UserCacheDir} {
+		if path, err := lookup(); err != nil || !strings.HasPrefix(path, dir) {
+			t.Errorf("Expected %s to be inside %s (%v)", path, dir, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config")); err != nil {
+		t.Errorf("Expected the directories to be created: %v", err)
+	}
+}
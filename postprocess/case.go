@@ -0,0 +1,164 @@
+package postprocess
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"Inference_Engine/utils"
+)
+
+// CaseStyle is a site's rule for the capitalization of headings.
+type CaseStyle string
+
+const (
+	CaseAsWritten CaseStyle = ""         // Headings are left as the model wrote them
+	CaseTitle     CaseStyle = "title"    // Every Major Word Capitalized
+	CaseSentence  CaseStyle = "sentence" // Only the first word capitalized
+)
+
+// CaseStyles lists the styles in the order they are offered.
+var CaseStyles = []CaseStyle{CaseAsWritten, CaseTitle, CaseSentence}
+
+// Label returns the name of the style shown to the user.
+func (c CaseStyle) Label() string {
+	switch c {
+	case CaseTitle:
+		return "Title Case"
+	case CaseSentence:
+		return "Sentence case"
+	default:
+		return "As written"
+	}
+}
+
+var headingCase struct {
+	sync.Mutex
+	style CaseStyle
+}
+
+// SetHeadingCase sets the case style of the connected site, which the
+// heading case step applies.
+func SetHeadingCase(style CaseStyle) {
+	headingCase.Lock()
+	defer headingCase.Unlock()
+	if headingCase.style != style {
+		logger.Info("Heading case style changed", "style", string(style))
+	}
+	headingCase.style = style
+}
+
+// HeadingCase returns the connected site's case style.
+func HeadingCase() CaseStyle {
+	headingCase.Lock()
+	defer headingCase.Unlock()
+	return headingCase.style
+}
+
+// ApplyHeadingCase recases the headings, in HTML or Markdown, in the
+// connected site's style.
+func ApplyHeadingCase(text string) string {
+	return RecaseHeadings(text, HeadingCase())
+}
+
+// RecaseHeadings recases the headings, in HTML or Markdown, in style. Tags,
+// link targets, entities and inline code inside a heading are kept as they
+// are.
+func RecaseHeadings(text string, style CaseStyle) string {
+	if style == CaseAsWritten {
+		return text
+	}
+	if utils.LooksLikeHTML(text) {
+		return htmlHeadingPattern.ReplaceAllStringFunc(text, func(heading string) string {
+			match := htmlHeadingPattern.FindStringSubmatchIndex(heading)
+			return heading[:match[6]] + Recase(heading[match[6]:match[7]], style) + heading[match[7]:]
+		})
+	}
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for n, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil && !inFence {
+			lines[n] = match[1] + Recase(match[2], style)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	// protectedPattern matches the parts of a heading that aren't words:
+	// tags, Markdown link targets, entities and inline code.
+	protectedPattern = regexp.MustCompile("<[^>]*>|\\]\\([^)]*\\)|&#?\\w+;|`[^`]*`")
+	wordPattern      = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’.][\p{L}\p{N}]+)*`)
+)
+
+// minorWords stay lowercase in Title Case unless they start or end the
+// heading: articles, short conjunctions and short prepositions.
+var minorWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"and": true, "but": true, "or": true, "nor": true, "for": true, "so": true, "yet": true,
+	"as": true, "at": true, "by": true, "in": true, "of": true, "off": true, "on": true,
+	"per": true, "to": true, "up": true, "via": true, "vs": true, "with": true, "from": true, "into": true,
+}
+
+// Recase rewrites the words of a heading in style. Words with capitals
+// after their first letter, such as acronyms and brand names, are kept as
+// written.
+func Recase(heading string, style CaseStyle) string {
+	if style == CaseAsWritten {
+		return heading
+	}
+	type word struct{ start, end int }
+	var words []word
+	plain := 0
+	for _, protected := range append(protectedPattern.FindAllStringIndex(heading, -1), []int{len(heading), len(heading)}) {
+		for _, w := range wordPattern.FindAllStringIndex(heading[plain:protected[0]], -1) {
+			words = append(words, word{plain + w[0], plain + w[1]})
+		}
+		plain = protected[1]
+	}
+	var b strings.Builder
+	last := 0
+	for i, w := range words {
+		between := heading[last:w.start]
+		b.WriteString(between)
+		// A word after a colon or the end of a sentence starts anew
+		first := i == 0 || strings.ContainsAny(protectedPattern.ReplaceAllString(between, ""), ":.?!")
+		b.WriteString(recaseWord(heading[w.start:w.end], style, first, i == len(words)-1))
+		last = w.end
+	}
+	b.WriteString(heading[last:])
+	return b.String()
+}
+
+// recaseWord rewrites one word in style.
+func recaseWord(w string, style CaseStyle, first, last bool) string {
+	if hasInnerCapital(w) || w == "I" || strings.HasPrefix(w, "I'") || strings.HasPrefix(w, "I’") {
+		return w
+	}
+	lower := strings.ToLower(w)
+	switch {
+	case first:
+		return upperFirst(lower)
+	case style == CaseTitle && (last || !minorWords[lower]):
+		return upperFirst(lower)
+	default:
+		return lower
+	}
+}
+
+// hasInnerCapital reports whether w has a capital after its first letter.
+func hasInnerCapital(w string) bool {
+	_, size := utf8.DecodeRuneInString(w)
+	return strings.IndexFunc(w[size:], unicode.IsUpper) >= 0
+}
+
+func upperFirst(w string) string {
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToUpper(r)) + w[size:]
+}
@@ -0,0 +1,262 @@
+package postprocess
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/settings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PrefGutenbergBlocks switches the conversion of saved content to Gutenberg
+// blocks.
+var PrefGutenbergBlocks = settings.NewKey("postprocess.gutenberg_blocks", true)
+
+// ForSave prepares generated content to be saved to a page: as Gutenberg
+// blocks, unless switched off. Only the Generator's output goes through it;
+// pages edited by hand are saved as they are.
+func ForSave(content string) string {
+	if !PrefGutenbergBlocks.Get() {
+		return content
+	}
+	return ToBlocks(content)
+}
+
+// ToBlocks converts content, in Markdown or HTML, to Gutenberg block markup,
+// so it opens as blocks in the editor whatever the model wrote. Content that
+// has blocks already is left as it is, and elements without a matching block
+// become Custom HTML blocks. Comments such as <!--more--> are kept between
+// the blocks, and shortcodes are kept exactly as written.
+func ToBlocks(content string) string {
+	if strings.TrimSpace(content) == "" || strings.Contains(content, "<!-- wp:") {
+		return content
+	}
+	protected, shortcodes := protectShortcodes(content)
+	nodes, err := html.ParseFragment(strings.NewReader(MarkdownToHTML(protected)), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		logger.Warn("Failed to parse content for blocks, saving it as it is", "error", err)
+		return content
+	}
+	var blocks []string
+	var inline []*html.Node // Loose text and inline elements, gathered into a paragraph
+	flush := func() {
+		if len(inline) == 0 {
+			return
+		}
+		text := strings.TrimSpace(renderAll(inline))
+		inline = nil
+		if text != "" {
+			blocks = append(blocks, wrapBlock("paragraph", "", "<p>"+text+"</p>"))
+		}
+	}
+	for _, node := range nodes {
+		if node.Type == html.CommentNode {
+			flush()
+			blocks = append(blocks, render(node))
+			continue
+		}
+		if node.Type == html.TextNode || (node.Type == html.ElementNode && isInline(node.DataAtom)) {
+			inline = append(inline, node)
+			continue
+		}
+		flush()
+		if block := nodeBlock(node); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+	return restoreShortcodes(strings.Join(blocks, "\n\n"), shortcodes)
+}
+
+// shortcodePattern matches a WordPress shortcode tag, opening or closing,
+// with its attributes.
+var shortcodePattern = regexp.MustCompile(`\[\[?/?[a-zA-Z][\w-]*(?:\s[^\[\]]*)?/?\]\]?`)
+
+// protectShortcodes swaps the shortcodes in content for placeholders that
+// Markdown and HTML rendering leave alone, so their attributes aren't
+// escaped, and returns the shortcodes in placeholder order. Bracketed text
+// followed by "(" or "[" is a Markdown link and is left for the converter.
+func protectShortcodes(content string) (string, []string) {
+	var shortcodes []string
+	var b strings.Builder
+	last := 0
+	for _, loc := range shortcodePattern.FindAllStringIndex(content, -1) {
+		if loc[1] < len(content) && (content[loc[1]] == '(' || content[loc[1]] == '[') {
+			continue
+		}
+		b.WriteString(content[last:loc[0]])
+		b.WriteString(shortcodePlaceholder(len(shortcodes)))
+		shortcodes = append(shortcodes, content[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(content[last:])
+	return b.String(), shortcodes
+}
+
+// restoreShortcodes puts the shortcodes back in place of their placeholders.
+// A paragraph holding nothing but a shortcode becomes a shortcode block.
+func restoreShortcodes(content string, shortcodes []string) string {
+	for i, code := range shortcodes {
+		placeholder := shortcodePlaceholder(i)
+		content = strings.Replace(content, wrapBlock("paragraph", "", "<p>"+placeholder+"</p>"), wrapBlock("shortcode", "", code), 1)
+		content = strings.Replace(content, placeholder, code, 1)
+	}
+	return content
+}
+
+// shortcodePlaceholder uses private-use characters, which neither renderer
+// escapes or interprets.
+func shortcodePlaceholder(i int) string {
+	return "\ue000" + strconv.Itoa(i) + "\ue001"
+}
+
+// nodeBlock returns the block markup of a top-level element.
+func nodeBlock(node *html.Node) string {
+	switch node.DataAtom {
+	case atom.P:
+		if img := onlyChild(node, atom.Img); img != nil {
+			return wrapBlock("image", "", `<figure class="wp-block-image">`+render(img)+`</figure>`)
+		}
+		if strings.TrimSpace(renderChildren(node)) == "" {
+			return ""
+		}
+		return wrapBlock("paragraph", "", render(node))
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(node.Data[1] - '0')
+		addClass(node, "wp-block-heading")
+		attrs := ""
+		if level != 2 {
+			attrs = fmt.Sprintf(`{"level":%d}`, level)
+		}
+		return wrapBlock("heading", attrs, render(node))
+	case atom.Ul, atom.Ol:
+		attrs := ""
+		if node.DataAtom == atom.Ol {
+			attrs = `{"ordered":true}`
+		}
+		return wrapBlock("list", attrs, listMarkup(node))
+	case atom.Blockquote:
+		inner := ToBlocks(strings.TrimSpace(renderChildren(node)))
+		return wrapBlock("quote", "", `<blockquote class="wp-block-quote">`+inner+`</blockquote>`)
+	case atom.Pre:
+		addClass(node, "wp-block-code")
+		return wrapBlock("code", "", render(node))
+	case atom.Table:
+		return wrapBlock("table", "", `<figure class="wp-block-table">`+render(node)+`</figure>`)
+	case atom.Hr:
+		return wrapBlock("separator", "", `<hr class="wp-block-separator has-alpha-channel-opacity"/>`)
+	case atom.Img:
+		return wrapBlock("image", "", `<figure class="wp-block-image">`+render(node)+`</figure>`)
+	default:
+		return wrapBlock("html", "", render(node))
+	}
+}
+
+// listMarkup renders a list with each item as a list-item block, nested
+// lists included.
+func listMarkup(list *html.Node) string {
+	addClass(list, "wp-block-list")
+	var b strings.Builder
+	b.WriteString("<" + list.Data + attributes(list) + ">")
+	for item := list.FirstChild; item != nil; item = item.NextSibling {
+		if item.DataAtom != atom.Li {
+			continue
+		}
+		var inner strings.Builder
+		for child := item.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom == atom.Ul || child.DataAtom == atom.Ol {
+				attrs := ""
+				if child.DataAtom == atom.Ol {
+					attrs = `{"ordered":true}`
+				}
+				inner.WriteString(wrapBlock("list", attrs, listMarkup(child)))
+				continue
+			}
+			inner.WriteString(render(child))
+		}
+		b.WriteString(wrapBlock("list-item", "", "<li>"+strings.TrimSpace(inner.String())+"</li>"))
+	}
+	b.WriteString("</" + list.Data + ">")
+	return b.String()
+}
+
+// wrapBlock puts markup between the comments of a core block, with its
+// attributes as JSON if any.
+func wrapBlock(name, attrs, markup string) string {
+	open := "<!-- wp:" + name
+	if attrs != "" {
+		open += " " + attrs
+	}
+	return open + " -->\n" + markup + "\n<!-- /wp:" + name + " -->"
+}
+
+// isInline reports whether elements of tag go inside a paragraph.
+func isInline(tag atom.Atom) bool {
+	switch tag {
+	case atom.A, atom.Strong, atom.B, atom.Em, atom.I, atom.Code, atom.Span, atom.Br, atom.Sub, atom.Sup, atom.Mark, atom.S, atom.Del, atom.U, atom.Abbr:
+		return true
+	}
+	return false
+}
+
+// onlyChild returns node's single child element if it is a tag, ignoring
+// whitespace.
+func onlyChild(node *html.Node, tag atom.Atom) *html.Node {
+	var found *html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.TextNode && strings.TrimSpace(child.Data) == "":
+		case child.DataAtom == tag && found == nil:
+			found = child
+		default:
+			return nil
+		}
+	}
+	return found
+}
+
+// addClass adds class to node's class attribute.
+func addClass(node *html.Node, class string) {
+	for i, attr := range node.Attr {
+		if attr.Key == "class" {
+			node.Attr[i].Val = strings.TrimSpace(attr.Val + " " + class)
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "class", Val: class})
+}
+
+// attributes renders node's attributes, with a leading space.
+func attributes(node *html.Node) string {
+	var b strings.Builder
+	for _, attr := range node.Attr {
+		fmt.Fprintf(&b, ` %s="%s"`, attr.Key, html.EscapeString(attr.Val))
+	}
+	return b.String()
+}
+
+func render(node *html.Node) string {
+	var b strings.Builder
+	html.Render(&b, node)
+	return b.String()
+}
+
+func renderAll(nodes []*html.Node) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		html.Render(&b, node)
+	}
+	return b.String()
+}
+
+func renderChildren(node *html.Node) string {
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		html.Render(&b, child)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+// Package postprocess cleans up generated content before it is shown or
+// saved: a pipeline of steps, each of which can be switched off in the
+// settings, run on every content generation.
+package postprocess
+
+import (
+	"sync"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/settings"
+)
+
+var logger = logging.For("postprocess")
+
+// Step is one stage of the pipeline.
+type Step struct {
+	Name    string // Identifies the step in settings and logs
+	Label   string // Shown next to its toggle
+	Default bool   // Whether it runs until the user switches it
+	Apply   func(text string) string
+	enabled settings.Key[bool]
+}
+
+// Enabled reports whether the step runs.
+func (s Step) Enabled() bool {
+	return s.enabled.Get()
+}
+
+// SetEnabled switches the step on or off.
+func (s Step) SetEnabled(on bool) {
+	s.enabled.Set(on)
+}
+
+var pipeline struct {
+	sync.Mutex
+	steps []Step
+}
+
+// Register adds a step at the end of the pipeline. Its toggle is the
+// setting "postprocess.<name>".
+func Register(step Step) {
+	step.enabled = settings.NewKey("postprocess."+step.Name, step.Default)
+	pipeline.Lock()
+	defer pipeline.Unlock()
+	pipeline.steps = append(pipeline.steps, step)
+}
+
+// Steps returns the pipeline's steps in the order they run.
+func Steps() []Step {
+	pipeline.Lock()
+	defer pipeline.Unlock()
+	return append([]Step(nil), pipeline.steps...)
+}
+
+// Apply runs the enabled steps on text, in order.
+func Apply(text string) string {
+	for _, step := range Steps() {
+		if !step.Enabled() {
+			continue
+		}
+		before := len(text)
+		text = step.Apply(text)
+		logger.Debug("Applied post-processing step", "step", step.Name, "chars_before", before, "chars_after", len(text))
+	}
+	return text
+}
+
+func init() {
+	Register(Step{Name: "strip_fences", Label: "Remove code fences around the whole answer", Default: true, Apply: StripFences})
+	Register(Step{Name: "remove_ai_phrases", Label: "Remove \"As an AI\" phrases and chatty preambles", Default: true, Apply: RemoveAIPhrases})
+	Register(Step{Name: "heading_levels", Label: "Start headings at level 2 and don't skip levels", Default: true, Apply: EnforceHeadingLevels})
+	Register(Step{Name: "heading_case", Label: "Apply the connected site's heading case", Default: true, Apply: ApplyHeadingCase})
+	Register(Step{Name: "markdown_to_html", Label: "Convert Markdown answers to HTML", Default: true, Apply: MarkdownToHTML})
+}
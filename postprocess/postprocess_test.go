@@ -0,0 +1,100 @@
+package postprocess
+
+import (
+	"testing"
+
+	"Inference_Engine/settings"
+)
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		step func(string) string
+		in   string
+		want string
+	}{
+		{"fenced", StripFences, "```html\n<p>Hi</p>\n```", "<p>Hi</p>"},
+		{"unfenced", StripFences, "Use ```go``` here", "Use ```go``` here"},
+		{"preamble", RemoveAIPhrases, "Sure! Here's the article you asked for:\n<p>Coffee is good.</p>", "<p>Coffee is good.</p>"},
+		{"ai sentence", RemoveAIPhrases, "<p>As an AI language model, I have no taste. Coffee is good.</p>", "<p>Coffee is good.</p>"},
+		{"closing offer", RemoveAIPhrases, "<p>Coffee is good.</p>\nLet me know if you'd like changes!", "<p>Coffee is good.</p>"},
+		{"html headings", EnforceHeadingLevels, `<h1>Title</h1><h4 id="a">Deep</h4><h2>Next</h2>`, `<h2>Title</h2><h3 id="a">Deep</h3><h2>Next</h2>`},
+		{"markdown headings", EnforceHeadingLevels, "# Title\n```\n# comment\n```\n#### Deep", "## Title\n```\n# comment\n```\n### Deep"},
+		{"markdown", MarkdownToHTML, "## Beans\n\n**Fresh** beans", "<h2>Beans</h2>\n<p><strong>Fresh</strong> beans</p>"},
+		{"html", MarkdownToHTML, "<p>**kept**</p>", "<p>**kept**</p>"},
+	}
+	for _, tt := range tests {
+		if got := tt.step(tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyHonoursToggles(t *testing.T) {
+	settings.Use(settings.New(nil))
+	in := "```markdown\n# Beans\n```"
+	if got, want := Apply(in), "<h2>Beans</h2>"; got != want {
+		t.Errorf("Apply = %q, want %q", got, want)
+	}
+	for _, step := range Steps() {
+		if step.Name == "markdown_to_html" {
+			step.SetEnabled(false)
+		}
+	}
+	if got, want := Apply(in), "## Beans"; got != want {
+		t.Errorf("Apply without Markdown conversion = %q, want %q", got, want)
+	}
+}
+
+func TestToBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"markdown", "# Beans\n\nFresh **beans**.\n\n- one\n- two",
+			"<!-- wp:heading {\"level\":1} -->\n<h1 class=\"wp-block-heading\">Beans</h1>\n<!-- /wp:heading -->\n\n" +
+				"<!-- wp:paragraph -->\n<p>Fresh <strong>beans</strong>.</p>\n<!-- /wp:paragraph -->\n\n" +
+				"<!-- wp:list -->\n<ul class=\"wp-block-list\"><!-- wp:list-item -->\n<li>one</li>\n<!-- /wp:list-item --><!-- wp:list-item -->\n<li>two</li>\n<!-- /wp:list-item --></ul>\n<!-- /wp:list -->"},
+		{"html", "<h2>Beans</h2>Loose text<hr><div>box</div>",
+			"<!-- wp:heading -->\n<h2 class=\"wp-block-heading\">Beans</h2>\n<!-- /wp:heading -->\n\n" +
+				"<!-- wp:paragraph -->\n<p>Loose text</p>\n<!-- /wp:paragraph -->\n\n" +
+				"<!-- wp:separator -->\n<hr class=\"wp-block-separator has-alpha-channel-opacity\"/>\n<!-- /wp:separator -->\n\n" +
+				"<!-- wp:html -->\n<div>box</div>\n<!-- /wp:html -->"},
+		{"image", `<p><img src="a.jpg" alt="A"/></p>`,
+			"<!-- wp:image -->\n<figure class=\"wp-block-image\"><img src=\"a.jpg\" alt=\"A\"/></figure>\n<!-- /wp:image -->"},
+		{"more comment", "<p>Intro</p><!--more--><p>Rest</p>",
+			"<!-- wp:paragraph -->\n<p>Intro</p>\n<!-- /wp:paragraph -->\n\n<!--more-->\n\n<!-- wp:paragraph -->\n<p>Rest</p>\n<!-- /wp:paragraph -->"},
+		{"shortcodes", "Our [button url=\"/shop\"]shop[/button] is open.\n\n[gallery ids=\"1,2\"]\n\nSee [the menu](/menu).",
+			"<!-- wp:paragraph -->\n<p>Our [button url=\"/shop\"]shop[/button] is open.</p>\n<!-- /wp:paragraph -->\n\n" +
+				"<!-- wp:shortcode -->\n[gallery ids=\"1,2\"]\n<!-- /wp:shortcode -->\n\n" +
+				"<!-- wp:paragraph -->\n<p>See <a href=\"/menu\">the menu</a>.</p>\n<!-- /wp:paragraph -->"},
+		{"blocks", "<!-- wp:paragraph -->\n<p>Hi</p>\n<!-- /wp:paragraph -->", "<!-- wp:paragraph -->\n<p>Hi</p>\n<!-- /wp:paragraph -->"},
+	}
+	for _, tt := range tests {
+		if got := ToBlocks(tt.in); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecaseHeadings(t *testing.T) {
+	tests := []struct {
+		name  string
+		style CaseStyle
+		in    string
+		want  string
+	}{
+		{"title", CaseTitle, "<h2>the best way to brew coffee at home</h2>", "<h2>The Best Way to Brew Coffee at Home</h2>"},
+		{"title keeps acronyms", CaseTitle, `<h2 id="x">why SEO matters for an iPhone app <a href="https://a.example/of">on the go</a></h2>`, `<h2 id="x">Why SEO Matters for an iPhone App <a href="https://a.example/of">on the Go</a></h2>`},
+		{"title after colon", CaseTitle, "## coffee: a guide to beans", "## Coffee: A Guide to Beans"},
+		{"sentence", CaseSentence, "<h3>The Best Way To Brew Coffee With AI</h3><p>Keep This As It Is</p>", "<h3>The best way to brew coffee with AI</h3><p>Keep This As It Is</p>"},
+		{"sentence markdown", CaseSentence, "# Why I Love [Fresh Beans](https://x.example/Beans)\n```\n# Keep Comment\n```", "# Why I love [fresh beans](https://x.example/Beans)\n```\n# Keep Comment\n```"},
+		{"as written", CaseAsWritten, "<h2>the Best way</h2>", "<h2>the Best way</h2>"},
+	}
+	for _, tt := range tests {
+		if got := RecaseHeadings(tt.in, tt.style); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package postprocess
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/utils"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// fencePattern matches an answer wrapped whole in a code fence, such as
+// "```html ... ```".
+var fencePattern = regexp.MustCompile("(?s)^\\s*```[\\w-]*[ \\t]*\\n(.*?)\\n?[ \\t]*```\\s*$")
+
+// StripFences unwraps an answer the model put in a code fence.
+func StripFences(text string) string {
+	if match := fencePattern.FindStringSubmatch(text); match != nil && !strings.Contains(match[1], "```") {
+		return match[1]
+	}
+	return text
+}
+
+// aiPhrasePatterns match the phrases models add about themselves, or around
+// the answer, which don't belong in an article.
+var aiPhrasePatterns = []*regexp.Regexp{
+	// A first line announcing the answer, e.g. "Sure! Here's the article:"
+	regexp.MustCompile(`(?i)^\s*(?:sure|certainly|of course|absolutely|okay|ok)?[,!.]?\s*here(?:'s| is| are)\b[^\n]*:\s*\n`),
+	// Sentences about being an AI, e.g. "As an AI language model, I can't..."
+	regexp.MustCompile(`(?i)\b(?:as an ai|as a large language model|as an ai language model|i am an ai|i'm an ai)\b[^.!?\n]*[.!?]\s*`),
+	// A last line offering more help
+	regexp.MustCompile(`(?i)\n\s*(?:i hope this helps|let me know if you(?:'d like| would like| need| want))\b[^\n]*\s*$`),
+}
+
+// RemoveAIPhrases removes the phrases of aiPhrasePatterns.
+func RemoveAIPhrases(text string) string {
+	for _, pattern := range aiPhrasePatterns {
+		text = pattern.ReplaceAllString(text, "")
+	}
+	return strings.TrimSpace(text)
+}
+
+var (
+	htmlHeadingPattern     = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h[1-6]\s*>`)
+	markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})([ \t]+\S.*)$`)
+)
+
+// EnforceHeadingLevels renumbers the headings, in HTML or Markdown, so they
+// start at level 2 (the page title is the level 1 heading) and never skip a
+// level going down.
+func EnforceHeadingLevels(text string) string {
+	if utils.LooksLikeHTML(text) {
+		levels := headingLevels(htmlHeadingPattern.FindAllStringSubmatch(text, -1), func(match []string) int {
+			level, _ := strconv.Atoi(match[1])
+			return level
+		})
+		i := 0
+		return htmlHeadingPattern.ReplaceAllStringFunc(text, func(heading string) string {
+			match := htmlHeadingPattern.FindStringSubmatch(heading)
+			level := levels[i]
+			i++
+			return fmt.Sprintf("<h%d%s>%s</h%d>", level, match[2], match[3], level)
+		})
+	}
+	lines := strings.Split(text, "\n")
+	var headings [][]string
+	var at []int // Line of each heading
+	inFence := false
+	for n, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence // Comments in code aren't headings
+			continue
+		}
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil && !inFence {
+			headings = append(headings, match)
+			at = append(at, n)
+		}
+	}
+	levels := headingLevels(headings, func(match []string) int { return len(match[1]) })
+	for i, n := range at {
+		lines[n] = strings.Repeat("#", levels[i]) + headings[i][2]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// headingLevels returns the corrected level of each heading match.
+func headingLevels(matches [][]string, level func([]string) int) []int {
+	levels := make([]int, len(matches))
+	previous := 1
+	for i, match := range matches {
+		l := min(max(level(match), 2), previous+1)
+		levels[i] = l
+		previous = l
+	}
+	return levels
+}
+
+// markdown converts Markdown with the GitHub extensions (tables,
+// strikethrough, autolinks) that models use.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// MarkdownToHTML converts an answer written in Markdown to HTML. Answers
+// that are HTML already are left as they are.
+func MarkdownToHTML(text string) string {
+	if strings.TrimSpace(text) == "" || utils.LooksLikeHTML(text) {
+		return text
+	}
+	var out bytes.Buffer
+	if err := markdown.Convert([]byte(text), &out); err != nil {
+		logger.Warn("Failed to convert Markdown, keeping the answer as it is", "error", err)
+		return text
+	}
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,106 @@
+package repurpose
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"Inference_Engine/utils"
+)
+
+// DefaultCTAText is the call to action used when none is given.
+const DefaultCTAText = "Read the full post"
+
+// CTA is the newsletter's call to action, added as a button after the body.
+type CTA struct {
+	Text string
+	URL  string // Usually the post's link; no button without it
+}
+
+// Newsletter is a post rewritten for an email newsletter.
+type Newsletter struct {
+	Subject   string `json:"subject"`
+	Preheader string `json:"preheader"` // Preview text shown after the subject in inboxes
+	Body      string `json:"body"`      // Simple HTML: headings, paragraphs, lists, links
+	CTA       CTA    `json:"-"`
+}
+
+// ParseNewsletter reads the model's answer to a newsletter prompt: a JSON
+// object, possibly wrapped in prose or a code fence.
+func ParseNewsletter(output string, cta CTA) (Newsletter, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return Newsletter{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var n Newsletter
+	if err := json.Unmarshal([]byte(output[start:end+1]), &n); err != nil {
+		return Newsletter{}, fmt.Errorf("failed to parse newsletter: %w", err)
+	}
+	n.Subject, n.Preheader, n.Body = strings.TrimSpace(n.Subject), strings.TrimSpace(n.Preheader), strings.TrimSpace(n.Body)
+	if n.Body == "" {
+		return Newsletter{}, fmt.Errorf("the model returned an empty newsletter")
+	}
+	if !utils.LooksLikeHTML(n.Body) {
+		n.Body = paragraphs(n.Body)
+	}
+	if strings.TrimSpace(cta.Text) == "" {
+		cta.Text = DefaultCTAText
+	}
+	n.CTA = cta
+	return n, nil
+}
+
+// paragraphs wraps the blank-line separated blocks of plain text in <p>.
+func paragraphs(text string) string {
+	var blocks []string
+	for _, block := range strings.Split(text, "\n\n") {
+		if block = strings.TrimSpace(block); block != "" {
+			blocks = append(blocks, "<p>"+html.EscapeString(block)+"</p>")
+		}
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// HTML renders a complete email document: one centered column with inline
+// styles, since most email clients ignore style sheets.
+func (n Newsletter) HTML() string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n</head>\n", html.EscapeString(n.Subject))
+	b.WriteString("<body style=\"margin:0;padding:0;background:#f4f4f4;\">\n")
+	if n.Preheader != "" {
+		fmt.Fprintf(&b, "<div style=\"display:none;max-height:0;overflow:hidden;\">%s</div>\n", html.EscapeString(n.Preheader))
+	}
+	b.WriteString("<table role=\"presentation\" width=\"100%\" cellpadding=\"0\" cellspacing=\"0\"><tr><td align=\"center\">\n")
+	b.WriteString("<table role=\"presentation\" width=\"600\" cellpadding=\"24\" cellspacing=\"0\" style=\"max-width:600px;background:#ffffff;font-family:Arial,sans-serif;font-size:16px;line-height:1.5;color:#222222;\"><tr><td>\n")
+	b.WriteString(n.Body)
+	b.WriteString("\n")
+	if n.CTA.URL != "" {
+		fmt.Fprintf(&b, "<p style=\"text-align:center;margin:32px 0;\"><a href=\"%s\" style=\"background:#2271b1;color:#ffffff;padding:12px 24px;border-radius:4px;text-decoration:none;font-weight:bold;\">%s</a></p>\n",
+			html.EscapeString(n.CTA.URL), html.EscapeString(n.CTA.Text))
+	}
+	b.WriteString("</td></tr></table>\n</td></tr></table>\n</body>\n</html>\n")
+	return b.String()
+}
+
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+
+// Text renders the plain-text alternative: the subject, the body with links
+// written out, and the call to action.
+func (n Newsletter) Text() string {
+	body := utils.HTMLToMarkdown(n.Body)
+	body = markdownLink.ReplaceAllString(body, "$1 ($2)")
+	body = strings.NewReplacer("**", "", "__", "").Replace(body)
+	var b strings.Builder
+	if n.Subject != "" {
+		fmt.Fprintf(&b, "%s\n\n", n.Subject)
+	}
+	b.WriteString(body)
+	if n.CTA.URL != "" {
+		fmt.Fprintf(&b, "\n\n%s: %s", n.CTA.Text, n.CTA.URL)
+	}
+	return b.String() + "\n"
+}
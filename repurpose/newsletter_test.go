@@ -0,0 +1,40 @@
+package repurpose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNewsletter(t *testing.T) {
+	output := `{"subject": "Spring is here", "preheader": "New menu inside", "body": "<h2>New menu</h2><p>Try our <a href=\"https://cafe.example/cake\">lemon cake</a>.</p>"}`
+	n, err := ParseNewsletter(output, CTA{URL: "https://cafe.example/spring/"})
+	if err != nil {
+		t.Fatalf("ParseNewsletter failed: %v", err)
+	}
+	if n.CTA.Text != DefaultCTAText {
+		t.Errorf("Expected the default call to action, got %q", n.CTA.Text)
+	}
+
+	doc := n.HTML()
+	for _, want := range []string{"<title>Spring is here</title>", ">New menu inside</div>", "<h2>New menu</h2>", `<a href="https://cafe.example/spring/" style=`, ">Read the full post</a>"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Expected %q in the HTML:\n%s", want, doc)
+		}
+	}
+	text := n.Text()
+	if !strings.HasPrefix(text, "Spring is here\n\n") || !strings.Contains(text, "lemon cake (https://cafe.example/cake)") ||
+		!strings.HasSuffix(text, "Read the full post: https://cafe.example/spring/\n") {
+		t.Errorf("Unexpected text version:\n%s", text)
+	}
+
+	plain, err := ParseNewsletter(`{"subject": "Hi", "body": "First <one>.\n\nSecond."}`, CTA{Text: "Book now"})
+	if err != nil {
+		t.Fatalf("ParseNewsletter failed: %v", err)
+	}
+	if plain.Body != "<p>First &lt;one&gt;.</p>\n<p>Second.</p>" || strings.Contains(plain.HTML(), "Book now") {
+		t.Errorf("Expected plain text wrapped in paragraphs and no button without a URL, got %q", plain.Body)
+	}
+	if _, err := ParseNewsletter(`{"subject": "Hi", "body": ""}`, CTA{}); err == nil {
+		t.Errorf("Expected an error for an empty body")
+	}
+}
@@ -0,0 +1,127 @@
+package repurpose
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"Inference_Engine/utils"
+)
+
+// minSeriesParts is the fewest parts a series has.
+const minSeriesParts = 2
+
+// MaxSeriesParts is the most parts a page is split into.
+const MaxSeriesParts = 8
+
+// SeriesPart is one post of a series: its title, what it covers, and the
+// page's sections it draws on.
+type SeriesPart struct {
+	Title   string   `json:"title"`
+	Summary string   `json:"summary"`
+	Covers  []string `json:"covers"` // Headings or topics of the page the part takes over
+}
+
+// Series is a plan for splitting one long page into a multi-part series.
+type Series struct {
+	Title string       `json:"series_title"`
+	Parts []SeriesPart `json:"parts"`
+}
+
+// ParseSeriesPlan reads the model's answer to a series plan prompt: a JSON
+// object, possibly wrapped in prose or a code fence.
+func ParseSeriesPlan(output string) (Series, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return Series{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var raw Series
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return Series{}, fmt.Errorf("failed to parse series plan: %w", err)
+	}
+	s := Series{Title: strings.TrimSpace(raw.Title)}
+	for _, part := range raw.Parts {
+		if part.Title = strings.TrimSpace(part.Title); part.Title != "" {
+			part.Summary = strings.TrimSpace(part.Summary)
+			s.Parts = append(s.Parts, part)
+		}
+	}
+	if len(s.Parts) < minSeriesParts {
+		return Series{}, fmt.Errorf("the model proposed %d parts; a series needs at least %d", len(s.Parts), minSeriesParts)
+	}
+	if len(s.Parts) > MaxSeriesParts {
+		s.Parts = s.Parts[:MaxSeriesParts]
+	}
+	logger.Info("Parsed series plan", "parts", len(s.Parts))
+	return s, nil
+}
+
+// Outline lists the parts, numbered, with what each covers, for prompts.
+func (s Series) Outline() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Series: %s\n", s.Title)
+	for i, part := range s.Parts {
+		fmt.Fprintf(&b, "Part %d: %s", i+1, part.Title)
+		if part.Summary != "" {
+			fmt.Fprintf(&b, " — %s", part.Summary)
+		}
+		if len(part.Covers) > 0 {
+			fmt.Fprintf(&b, " (covers: %s)", strings.Join(part.Covers, "; "))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// PartSlug returns the slug WordPress gives part i by default, used to link
+// the parts to each other before they are published.
+func (s Series) PartSlug(i int) string {
+	words := strings.FieldsFunc(strings.ToLower(s.Parts[i].Title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return fmt.Sprintf("part-%d", i+1)
+	}
+	return strings.Join(words, "-")
+}
+
+// Navigation returns the block linking part i to the other parts of the
+// series, as HTML or Markdown. Parts are linked by their slugs ("/slug/"),
+// so the links work once every part is published with its title's slug.
+func (s Series) Navigation(i int, asHTML bool) string {
+	intro := fmt.Sprintf("This is part %d of %d of the series %q.", i+1, len(s.Parts), s.Title)
+	if s.Title == "" {
+		intro = fmt.Sprintf("This is part %d of %d of a series.", i+1, len(s.Parts))
+	}
+	var b strings.Builder
+	if asHTML {
+		fmt.Fprintf(&b, "<nav class=\"series-navigation\">\n<p><strong>%s</strong></p>\n<ol>\n", html.EscapeString(intro))
+		for j, part := range s.Parts {
+			if j == i {
+				fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(part.Title))
+			} else {
+				fmt.Fprintf(&b, "<li><a href=\"/%s/\">%s</a></li>\n", s.PartSlug(j), html.EscapeString(part.Title))
+			}
+		}
+		b.WriteString("</ol>\n</nav>")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "**%s**\n\n", intro)
+	for j, part := range s.Parts {
+		if j == i {
+			fmt.Fprintf(&b, "%d. %s\n", j+1, part.Title)
+		} else {
+			fmt.Fprintf(&b, "%d. [%s](/%s/)\n", j+1, part.Title, s.PartSlug(j))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// WithNavigation adds the series navigation of part i at the start and end
+// of the part's content, in its format.
+func (s Series) WithNavigation(i int, content string) string {
+	nav := s.Navigation(i, utils.LooksLikeHTML(content))
+	return nav + "\n\n" + strings.TrimSpace(content) + "\n\n" + nav
+}
@@ -0,0 +1,51 @@
+package repurpose
+
+import (
+	"strings"
+	"testing"
+)
+
+const seriesOutput = "```json\n" + `{
+  "series_title": "Cold Brew at Home",
+  "parts": [
+    {"title": "Choosing Beans for Cold Brew", "summary": "Roasts and grind.", "covers": ["Beans", "Grind size"]},
+    {"title": "  ", "summary": "Dropped"},
+    {"title": "Brewing & Storing It", "summary": "Ratios, steeping and storage."}
+  ]
+}` + "\n```"
+
+func TestParseSeriesPlan(t *testing.T) {
+	s, err := ParseSeriesPlan(seriesOutput)
+	if err != nil {
+		t.Fatalf("ParseSeriesPlan failed: %v", err)
+	}
+	if len(s.Parts) != 2 || s.Title != "Cold Brew at Home" {
+		t.Fatalf("Unexpected plan %+v", s)
+	}
+	if got := s.PartSlug(1); got != "brewing-storing-it" {
+		t.Errorf("PartSlug = %q", got)
+	}
+	if outline := s.Outline(); !strings.Contains(outline, "Part 1: Choosing Beans for Cold Brew — Roasts and grind. (covers: Beans; Grind size)") {
+		t.Errorf("Unexpected outline:\n%s", outline)
+	}
+
+	if _, err := ParseSeriesPlan(`{"parts": [{"title": "Only one"}]}`); err == nil {
+		t.Error("Expected a single part to be rejected")
+	}
+}
+
+func TestSeriesNavigation(t *testing.T) {
+	s, _ := ParseSeriesPlan(seriesOutput)
+
+	md := s.WithNavigation(0, "# Choosing Beans\n\nText.")
+	if !strings.HasPrefix(md, `**This is part 1 of 2 of the series "Cold Brew at Home".**`) ||
+		!strings.Contains(md, "1. Choosing Beans for Cold Brew\n2. [Brewing & Storing It](/brewing-storing-it/)") {
+		t.Errorf("Unexpected Markdown navigation:\n%s", md)
+	}
+
+	html := s.WithNavigation(1, "<h2>Brewing</h2><p>Text.</p>")
+	if !strings.Contains(html, `<li><a href="/choosing-beans-for-cold-brew/">Choosing Beans for Cold Brew</a></li>`) ||
+		!strings.Contains(html, "<li>Brewing &amp; Storing It</li>") {
+		t.Errorf("Unexpected HTML navigation:\n%s", html)
+	}
+}
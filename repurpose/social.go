@@ -0,0 +1,100 @@
+// Package repurpose turns a finished article into other formats: social
+// media posts, email newsletters, multi-part series and distinct per-site
+// versions for syndication.
+package repurpose
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("repurpose")
+
+// XPostLimit is the most characters an X (Twitter) post may have.
+const XPostLimit = 280
+
+// xLinkLength is how many characters X counts for any link.
+const xLinkLength = 23
+
+// SocialPosts are platform-specific snippets promoting one article.
+type SocialPosts struct {
+	XThread  []string `json:"x_thread"` // One entry per post, in order
+	LinkedIn string   `json:"linkedin"`
+	Facebook string   `json:"facebook"`
+}
+
+// ParseSocialPosts reads the model's answer to a social posts prompt: a JSON
+// object, possibly wrapped in prose or a code fence.
+func ParseSocialPosts(output string) (SocialPosts, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return SocialPosts{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var posts SocialPosts
+	if err := json.Unmarshal([]byte(output[start:end+1]), &posts); err != nil {
+		return SocialPosts{}, fmt.Errorf("failed to parse social posts: %w", err)
+	}
+	var thread []string
+	for _, post := range posts.XThread {
+		if post = strings.TrimSpace(post); post != "" {
+			thread = append(thread, post)
+		}
+	}
+	posts.XThread = thread
+	posts.LinkedIn = strings.TrimSpace(posts.LinkedIn)
+	posts.Facebook = strings.TrimSpace(posts.Facebook)
+	if len(posts.XThread) == 0 && posts.LinkedIn == "" && posts.Facebook == "" {
+		return SocialPosts{}, fmt.Errorf("the model returned no posts")
+	}
+	logger.Info("Parsed social posts", "x_posts", len(posts.XThread))
+	return posts, nil
+}
+
+var link = regexp.MustCompile(`https?://\S+`)
+
+// XLength counts a post's characters the way X does for the limit: every
+// link counts as 23 characters whatever its length.
+func XLength(post string) int {
+	links := link.FindAllString(post, -1)
+	length := utf8.RuneCountInString(link.ReplaceAllString(post, ""))
+	return length + len(links)*xLinkLength
+}
+
+// LongXPosts returns the 1-based numbers of thread posts over XPostLimit.
+func (p SocialPosts) LongXPosts() []int {
+	var long []int
+	for i, post := range p.XThread {
+		if XLength(post) > XPostLimit {
+			long = append(long, i+1)
+		}
+	}
+	return long
+}
+
+// XThreadText joins the thread's posts with blank lines, for copying.
+func (p SocialPosts) XThreadText() string {
+	return strings.Join(p.XThread, "\n\n")
+}
+
+// Export renders all the posts as one Markdown document.
+func (p SocialPosts) Export() string {
+	var b strings.Builder
+	if len(p.XThread) > 0 {
+		b.WriteString("# X Thread\n\n")
+		for i, post := range p.XThread {
+			fmt.Fprintf(&b, "## Post %d/%d\n\n%s\n\n", i+1, len(p.XThread), post)
+		}
+	}
+	if p.LinkedIn != "" {
+		fmt.Fprintf(&b, "# LinkedIn\n\n%s\n\n", p.LinkedIn)
+	}
+	if p.Facebook != "" {
+		fmt.Fprintf(&b, "# Facebook\n\n%s\n\n", p.Facebook)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
@@ -0,0 +1,37 @@
+package repurpose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSocialPosts(t *testing.T) {
+	output := "```json\n{\"x_thread\": [\"Big news 🎉\", \"  \", \"Read more: https://example.com/a/very/long/path/to/the/article\"], \"linkedin\": \" We launched. \"}\n```"
+	posts, err := ParseSocialPosts(output)
+	if err != nil {
+		t.Fatalf("ParseSocialPosts failed: %v", err)
+	}
+	if len(posts.XThread) != 2 || posts.LinkedIn != "We launched." || posts.Facebook != "" {
+		t.Errorf("Unexpected posts: %+v", posts)
+	}
+	if got := XLength(posts.XThread[0]); got != 10 {
+		t.Errorf("Expected emoji to count as one character, got %d", got)
+	}
+	if got := XLength(posts.XThread[1]); got != len("Read more: ")+23 {
+		t.Errorf("Expected a link to count as 23 characters, got %d", got)
+	}
+	if _, err := ParseSocialPosts(`{"x_thread": []}`); err == nil {
+		t.Errorf("Expected an error without any posts")
+	}
+}
+
+func TestSocialPostsExport(t *testing.T) {
+	posts := SocialPosts{XThread: []string{"One", strings.Repeat("a", XPostLimit+1)}, Facebook: "Hi"}
+	if long := posts.LongXPosts(); len(long) != 1 || long[0] != 2 {
+		t.Errorf("Expected post 2 to be over the limit, got %v", long)
+	}
+	export := posts.Export()
+	if !strings.HasPrefix(export, "# X Thread\n\n## Post 1/2\n\nOne\n\n") || strings.Contains(export, "LinkedIn") || !strings.HasSuffix(export, "# Facebook\n\nHi\n") {
+		t.Errorf("Unexpected export:\n%s", export)
+	}
+}
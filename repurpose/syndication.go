@@ -0,0 +1,106 @@
+package repurpose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/embeddings"
+)
+
+// DefaultMaxSimilarity is the embedding similarity above which two versions
+// of a syndicated article count as duplicates.
+const DefaultMaxSimilarity = 0.85
+
+// maxVariantAttempts is how many times a site's variant is written before
+// the most distinct one is kept even if it is still too similar.
+const maxVariantAttempts = 3
+
+// Variant is one site's version of a syndicated article.
+type Variant struct {
+	Site       string
+	Content    string
+	Similarity float64 // Highest similarity to the original or another site's variant
+	Attempts   int     // How many versions were written
+}
+
+// TooSimilar reports whether the variant is over the similarity threshold.
+func (v Variant) TooSimilar(threshold float64) bool {
+	return v.Similarity > threshold
+}
+
+// RewriteFunc writes a site's variant of the article. attempt counts from 1;
+// later attempts should differ more, as the earlier ones were too similar.
+type RewriteFunc func(ctx context.Context, site string, attempt int) (string, error)
+
+// Syndicate writes a variant of original for each site, then rewrites the
+// variants that are more than threshold similar to the original or to each
+// other, most similar first, until every pair is under it or each site has
+// had maxVariantAttempts. A site keeps its least similar version.
+func Syndicate(ctx context.Context, original string, sites []string, rewrite RewriteFunc, embedder embeddings.Embedder, threshold float64) ([]Variant, error) {
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("choose at least one site to syndicate to")
+	}
+	variants := make([]Variant, len(sites))
+	texts := []string{original}
+	for i, site := range sites {
+		content, err := rewrite(ctx, site, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write the variant for %s: %w", site, err)
+		}
+		variants[i] = Variant{Site: site, Content: strings.TrimSpace(content), Attempts: 1}
+		texts = append(texts, variants[i].Content)
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the variants: %w", err)
+	}
+	score := func() {
+		for i := range variants {
+			variants[i].Similarity = maxSimilarity(vectors, i+1)
+		}
+	}
+	score()
+
+	for {
+		worst := -1
+		for i, v := range variants {
+			if v.TooSimilar(threshold) && v.Attempts < maxVariantAttempts && (worst < 0 || v.Similarity > variants[worst].Similarity) {
+				worst = i
+			}
+		}
+		if worst < 0 {
+			return variants, nil
+		}
+		v := &variants[worst]
+		logger.Info("Rewriting a syndicated variant that is too similar", "site", v.Site, "similarity", v.Similarity, "attempt", v.Attempts+1)
+		content, err := rewrite(ctx, v.Site, v.Attempts+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite the variant for %s: %w", v.Site, err)
+		}
+		v.Attempts++
+		vector, err := embedder.Embed(ctx, []string{content})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare the variants: %w", err)
+		}
+		previous := vectors[worst+1]
+		vectors[worst+1] = vector[0]
+		if similarity := maxSimilarity(vectors, worst+1); similarity < v.Similarity {
+			v.Content = strings.TrimSpace(content)
+		} else {
+			vectors[worst+1] = previous // The earlier version was more distinct
+		}
+		score()
+	}
+}
+
+// maxSimilarity returns the highest similarity of vectors[i] to any other.
+func maxSimilarity(vectors []embeddings.Vector, i int) float64 {
+	highest := -1.0
+	for j, vector := range vectors {
+		if j != i {
+			highest = max(highest, embeddings.Cosine(vectors[i], vector))
+		}
+	}
+	return highest
+}
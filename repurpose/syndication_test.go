@@ -0,0 +1,45 @@
+package repurpose
+
+import (
+	"context"
+	"testing"
+
+	"Inference_Engine/embeddings"
+)
+
+func TestSyndicate(t *testing.T) {
+	original := "Cold brew coffee is steeped in cold water for twelve hours, giving a smooth, low acid drink."
+	distinct := map[string]string{
+		"Cafe":   "Our baristas explain why slow extraction at fridge temperature makes a sweeter cup.",
+		"Garden": "Summer gardeners love a jug of overnight coffee waiting after weeding the tomato beds.",
+	}
+	attempts := map[string]int{}
+	rewrite := func(ctx context.Context, site string, attempt int) (string, error) {
+		attempts[site] = attempt
+		if site == "Copycat" || (site == "Cafe" && attempt == 1) {
+			return original, nil // Too close to the original
+		}
+		return distinct[site], nil
+	}
+
+	variants, err := Syndicate(context.Background(), original, []string{"Cafe", "Garden", "Copycat"}, rewrite, embeddings.LocalEmbedder{}, DefaultMaxSimilarity)
+	if err != nil {
+		t.Fatalf("Syndicate failed: %v", err)
+	}
+	if attempts["Cafe"] != 2 || attempts["Garden"] != 1 || attempts["Copycat"] != maxVariantAttempts {
+		t.Errorf("Unexpected attempts %v", attempts)
+	}
+	if variants[0].Content != distinct["Cafe"] || variants[0].TooSimilar(DefaultMaxSimilarity) {
+		t.Errorf("Expected the rewritten Cafe variant, got %+v", variants[0])
+	}
+	if variants[1].Attempts != 1 || variants[1].TooSimilar(DefaultMaxSimilarity) {
+		t.Errorf("Unexpected Garden variant %+v", variants[1])
+	}
+	if !variants[2].TooSimilar(DefaultMaxSimilarity) || variants[2].Attempts != maxVariantAttempts {
+		t.Errorf("Expected the Copycat variant to stay too similar after every attempt, got %+v", variants[2])
+	}
+
+	if _, err := Syndicate(context.Background(), original, nil, rewrite, embeddings.LocalEmbedder{}, DefaultMaxSimilarity); err == nil {
+		t.Error("Expected an error without sites")
+	}
+}
@@ -0,0 +1,254 @@
+// Package searchconsole reads the search queries a page already ranks for
+// from Google Search Console, to use as keyword targets.
+package searchconsole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/seo"
+
+	"golang.org/x/oauth2/google"
+)
+
+var logger = logging.For("searchconsole")
+
+// readOnlyScope is the OAuth scope for reading Search Console data.
+const readOnlyScope = "https://www.googleapis.com/auth/webmasters.readonly"
+
+// DefaultDays is how many days of search data are read.
+const DefaultDays = 90
+
+// DefaultLimit is how many of a page's queries are read.
+const DefaultLimit = 20
+
+// dataDelay is how many days Search Console data lags behind.
+const dataDelay = 3
+
+// Query is a search query a page appeared for, with its totals over the
+// period read.
+type Query struct {
+	Text        string
+	Clicks      float64
+	Impressions float64
+	CTR         float64 // Clicks per impression, 0 to 1
+	Position    float64 // Average position in the results, 1 is the top
+}
+
+// Client reads Search Console data with a service account.
+type Client struct {
+	http     *http.Client
+	endpoint string
+
+	mu    sync.Mutex
+	sites []string // Properties the account can read, loaded once
+}
+
+// FromEnv creates a client from the service account key file named by
+// GSC_CREDENTIALS_FILE. It returns nil without an error when the variable
+// is unset, as the integration is optional.
+func FromEnv() (*Client, error) {
+	path := os.Getenv("GSC_CREDENTIALS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Search Console credentials: %w", err)
+	}
+	return NewClient(data)
+}
+
+// NewClient creates a client from a service account's JSON key. The
+// service account must be added as a user of the Search Console properties.
+func NewClient(credentialsJSON []byte) (*Client, error) {
+	config, err := google.JWTConfigFromJSON(credentialsJSON, readOnlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Search Console credentials: %w", err)
+	}
+	httpClient := config.Client(context.Background())
+	httpClient.Timeout = 60 * time.Second
+	return newClient(httpClient), nil
+}
+
+// newClient creates a client sending requests with httpClient to the
+// GSC_API_ENDPOINT base URL.
+func newClient(httpClient *http.Client) *Client {
+	endpoint := os.Getenv("GSC_API_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://www.googleapis.com/webmasters/v3/"
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return &Client{http: httpClient, endpoint: endpoint}
+}
+
+// Sites returns the properties the account can read, as Search Console
+// names them: URL prefixes such as "https://example.com/", or domains such
+// as "sc-domain:example.com".
+func (c *Client) Sites(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sites != nil {
+		return c.sites, nil
+	}
+	var result struct {
+		SiteEntry []struct {
+			SiteURL         string `json:"siteUrl"`
+			PermissionLevel string `json:"permissionLevel"`
+		} `json:"siteEntry"`
+	}
+	if err := c.do(ctx, "GET", "sites", nil, &result); err != nil {
+		return nil, err
+	}
+	sites := []string{}
+	for _, entry := range result.SiteEntry {
+		if entry.PermissionLevel != "siteUnverifiedUser" {
+			sites = append(sites, entry.SiteURL)
+		}
+	}
+	c.sites = sites
+	return sites, nil
+}
+
+// PropertyFor returns the property among sites that covers pageURL: the
+// longest matching URL prefix, else the domain property of the page's host
+// or a parent domain. It returns "" if none does.
+func PropertyFor(sites []string, pageURL string) string {
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Host == "" {
+		return ""
+	}
+	best := ""
+	for _, site := range sites {
+		if !strings.HasPrefix(site, "sc-domain:") && strings.HasPrefix(pageURL, site) && len(site) > len(best) {
+			best = site
+		}
+	}
+	if best != "" {
+		return best
+	}
+	host := strings.ToLower(page.Hostname())
+	for _, site := range sites {
+		domain, ok := strings.CutPrefix(site, "sc-domain:")
+		if ok && (host == domain || strings.HasSuffix(host, "."+domain)) && len(site) > len(best) {
+			best = site
+		}
+	}
+	return best
+}
+
+// PageQueries returns the queries pageURL appeared for in the last days,
+// most impressions first, at most limit of them.
+func (c *Client) PageQueries(ctx context.Context, pageURL string, days, limit int) ([]Query, error) {
+	sites, err := c.Sites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	property := PropertyFor(sites, pageURL)
+	if property == "" {
+		return nil, fmt.Errorf("no Search Console property the service account can read covers %s", pageURL)
+	}
+
+	end := time.Now().AddDate(0, 0, -dataDelay)
+	request := map[string]interface{}{
+		"startDate":  end.AddDate(0, 0, -days).Format("2006-01-02"),
+		"endDate":    end.Format("2006-01-02"),
+		"dimensions": []string{"query"},
+		"dimensionFilterGroups": []map[string]interface{}{{
+			"filters": []map[string]string{{"dimension": "page", "operator": "equals", "expression": pageURL}},
+		}},
+		"rowLimit": limit,
+	}
+	var result struct {
+		Rows []struct {
+			Keys        []string `json:"keys"`
+			Clicks      float64  `json:"clicks"`
+			Impressions float64  `json:"impressions"`
+			CTR         float64  `json:"ctr"`
+			Position    float64  `json:"position"`
+		} `json:"rows"`
+	}
+	path := fmt.Sprintf("sites/%s/searchAnalytics/query", url.PathEscape(property))
+	if err := c.do(ctx, "POST", path, request, &result); err != nil {
+		return nil, err
+	}
+	queries := make([]Query, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		if len(row.Keys) == 0 {
+			continue
+		}
+		queries = append(queries, Query{Text: row.Keys[0], Clicks: row.Clicks, Impressions: row.Impressions, CTR: row.CTR, Position: row.Position})
+	}
+	sort.SliceStable(queries, func(i, j int) bool { return queries[i].Impressions > queries[j].Impressions })
+	logger.Info("Read page queries", "page", pageURL, "property", property, "queries", len(queries))
+	return queries, nil
+}
+
+// do sends a request to the API and decodes the JSON response into result.
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Search Console request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Search Console request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Search Console request failed: HTTP %d: %s", resp.StatusCode, string(data))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse Search Console response: %w", err)
+	}
+	return nil
+}
+
+// Targets makes SEO targets of the queries: the one with the most
+// impressions as the keyword and up to related others as related terms.
+func Targets(queries []Query, related int) seo.Targets {
+	var targets seo.Targets
+	for _, query := range queries {
+		switch {
+		case targets.Keyword == "":
+			targets.Keyword = query.Text
+		case len(targets.SecondaryKeywords) < related:
+			targets.SecondaryKeywords = append(targets.SecondaryKeywords, query.Text)
+		}
+	}
+	return targets
+}
+
+// Summary lists the queries with their numbers, one per line, for a prompt.
+func Summary(queries []Query) string {
+	lines := make([]string, len(queries))
+	for i, query := range queries {
+		lines[i] = fmt.Sprintf("- \"%s\": %.0f impressions, %.0f clicks, average position %.1f", query.Text, query.Impressions, query.Clicks, query.Position)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+package searchconsole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPropertyFor(t *testing.T) {
+	sites := []string{"https://example.com/", "https://example.com/blog/", "sc-domain:example.org"}
+	tests := []struct {
+		page string
+		want string
+	}{
+		{"https://example.com/blog/cold-brew/", "https://example.com/blog/"},
+		{"https://example.com/about/", "https://example.com/"},
+		{"https://shop.example.org/grinders/", "sc-domain:example.org"},
+		{"http://example.com/about/", ""},
+		{"https://notexample.org/", ""},
+	}
+	for _, tt := range tests {
+		if got := PropertyFor(sites, tt.page); got != tt.want {
+			t.Errorf("PropertyFor(%q) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestPageQueries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/sites":
+			w.Write([]byte(`{"siteEntry": [{"siteUrl": "sc-domain:example.com", "permissionLevel": "siteFullUser"}, {"siteUrl": "https://example.com/", "permissionLevel": "siteUnverifiedUser"}]}`))
+		case r.Method == "POST" && r.URL.EscapedPath() == "/sites/sc-domain:example.com/searchAnalytics/query":
+			var request struct {
+				DimensionFilterGroups []struct {
+					Filters []struct {
+						Expression string `json:"expression"`
+					} `json:"filters"`
+				} `json:"dimensionFilterGroups"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			if request.DimensionFilterGroups[0].Filters[0].Expression != "https://example.com/cold-brew/" {
+				http.Error(w, "unexpected page filter", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"rows": [
+				{"keys": ["iced coffee"], "clicks": 3, "impressions": 120, "ctr": 0.025, "position": 14.2},
+				{"keys": ["cold brew"], "clicks": 40, "impressions": 900, "ctr": 0.044, "position": 6.1}
+			]}`))
+		default:
+			http.Error(w, "unexpected request "+r.URL.Path, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GSC_API_ENDPOINT", server.URL)
+
+	client := newClient(server.Client())
+	queries, err := client.PageQueries(context.Background(), "https://example.com/cold-brew/", DefaultDays, 10)
+	if err != nil {
+		t.Fatalf("PageQueries failed: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Text != "cold brew" || queries[1].Position != 14.2 {
+		t.Fatalf("Unexpected queries: %+v", queries)
+	}
+	targets := Targets(queries, 5)
+	if targets.Keyword != "cold brew" || len(targets.SecondaryKeywords) != 1 || targets.SecondaryKeywords[0] != "iced coffee" {
+		t.Errorf("Unexpected targets: %+v", targets)
+	}
+	if summary := Summary(queries); !strings.Contains(summary, `"cold brew": 900 impressions, 40 clicks, average position 6.1`) {
+		t.Errorf("Unexpected summary:\n%s", summary)
+	}
+
+	if _, err := client.PageQueries(context.Background(), "https://other.com/", DefaultDays, 10); err == nil {
+		t.Errorf("Expected an error for a page outside the properties")
+	}
+}
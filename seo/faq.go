@@ -0,0 +1,145 @@
+// Package seo builds the search-facing parts of a page: FAQ sections,
+// schema.org structured data and tables of contents.
+package seo
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/utils"
+)
+
+var logger = logging.For("seo")
+
+// FAQItem is one question and its answer, both plain text.
+type FAQItem struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// FAQ is a page's Frequently Asked Questions section.
+type FAQ struct {
+	Items []FAQItem
+}
+
+// ParseFAQ reads the model's answer to an FAQ prompt: a JSON array of
+// {"question", "answer"} objects, possibly wrapped in prose or a code fence.
+func ParseFAQ(output string) (FAQ, error) {
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return FAQ{}, fmt.Errorf("no JSON array in the model's answer")
+	}
+	var items []FAQItem
+	if err := json.Unmarshal([]byte(output[start:end+1]), &items); err != nil {
+		return FAQ{}, fmt.Errorf("failed to parse FAQ: %w", err)
+	}
+	var faq FAQ
+	for _, item := range items {
+		item.Question = strings.TrimSpace(item.Question)
+		item.Answer = strings.TrimSpace(item.Answer)
+		if item.Question != "" && item.Answer != "" {
+			faq.Items = append(faq.Items, item)
+		}
+	}
+	if err := faq.Validate(); err != nil {
+		return FAQ{}, err
+	}
+	return faq, nil
+}
+
+// Validate checks the FAQ against Google's FAQPage requirements: at least one
+// question, each with a non-empty answer, and no duplicate questions.
+func (f FAQ) Validate() error {
+	if len(f.Items) == 0 {
+		return fmt.Errorf("FAQ has no questions")
+	}
+	seen := map[string]bool{}
+	for i, item := range f.Items {
+		if strings.TrimSpace(item.Question) == "" || strings.TrimSpace(item.Answer) == "" {
+			return fmt.Errorf("FAQ item %d needs both a question and an answer", i+1)
+		}
+		key := strings.ToLower(item.Question)
+		if seen[key] {
+			return fmt.Errorf("FAQ repeats the question %q", item.Question)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// HTML renders the FAQ as a section of headed questions.
+func (f FAQ) HTML() string {
+	var b strings.Builder
+	b.WriteString("<h2>Frequently Asked Questions</h2>\n")
+	for _, item := range f.Items {
+		fmt.Fprintf(&b, "<h3>%s</h3>\n<p>%s</p>\n", html.EscapeString(item.Question), html.EscapeString(item.Answer))
+	}
+	return b.String()
+}
+
+// Markdown renders the FAQ as a Markdown section.
+func (f FAQ) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Frequently Asked Questions\n\n")
+	for _, item := range f.Items {
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", item.Question, item.Answer)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// JSONLD returns the FAQ as schema.org FAQPage structured data.
+func (f FAQ) JSONLD() (string, error) {
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+	type answer struct {
+		Type string `json:"@type"`
+		Text string `json:"text"`
+	}
+	type question struct {
+		Type           string `json:"@type"`
+		Name           string `json:"name"`
+		AcceptedAnswer answer `json:"acceptedAnswer"`
+	}
+	page := struct {
+		Context    string     `json:"@context"`
+		Type       string     `json:"@type"`
+		MainEntity []question `json:"mainEntity"`
+	}{Context: "https://schema.org", Type: "FAQPage"}
+	for _, item := range f.Items {
+		page.MainEntity = append(page.MainEntity, question{
+			Type:           "Question",
+			Name:           item.Question,
+			AcceptedAnswer: answer{Type: "Answer", Text: item.Answer},
+		})
+	}
+	data, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// AppendFAQ adds the FAQ section and its JSON-LD script to the end of the
+// page content, as HTML or Markdown to match the content.
+func AppendFAQ(content string, f FAQ) (string, error) {
+	jsonLD, err := f.JSONLD()
+	if err != nil {
+		return "", err
+	}
+	section := f.Markdown()
+	if utils.LooksLikeHTML(content) {
+		section = f.HTML()
+	}
+	logger.Info("Appending FAQ", "questions", len(f.Items))
+	return strings.TrimRight(content, "\n") + "\n\n" + section + "\n" + ScriptTag(jsonLD) + "\n", nil
+}
+
+// ScriptTag wraps JSON-LD in the script element that carries it on a page.
+// "</" is escaped so text in the data can't close the element early.
+func ScriptTag(jsonLD string) string {
+	return "<script type=\"application/ld+json\">\n" + strings.ReplaceAll(jsonLD, "</", `<\/`) + "\n</script>"
+}
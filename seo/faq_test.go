@@ -0,0 +1,73 @@
+package seo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseFAQ(t *testing.T) {
+	output := "Here you go:\n```json\n[{\"question\": \"When did you open?\", \"answer\": \"In 1999.\"}, {\"question\": \" \", \"answer\": \"dropped\"}]\n```"
+	faq, err := ParseFAQ(output)
+	if err != nil {
+		t.Fatalf("ParseFAQ failed: %v", err)
+	}
+	if len(faq.Items) != 1 || faq.Items[0].Answer != "In 1999." {
+		t.Errorf("Unexpected items: %+v", faq.Items)
+	}
+	if _, err := ParseFAQ("I can't find any questions."); err == nil {
+		t.Errorf("Expected an error without a JSON array")
+	}
+	if _, err := ParseFAQ(`[{"question": "Q?", "answer": "A"}, {"question": "q?", "answer": "B"}]`); err == nil {
+		t.Errorf("Expected duplicate questions to be rejected")
+	}
+}
+
+func TestFAQJSONLD(t *testing.T) {
+	faq := FAQ{Items: []FAQItem{{Question: "Is it <safe>?", Answer: "Yes </script> really."}}}
+	jsonLD, err := faq.JSONLD()
+	if err != nil {
+		t.Fatalf("JSONLD failed: %v", err)
+	}
+	var page struct {
+		Context    string `json:"@context"`
+		Type       string `json:"@type"`
+		MainEntity []struct {
+			Type           string `json:"@type"`
+			Name           string `json:"name"`
+			AcceptedAnswer struct {
+				Type string `json:"@type"`
+				Text string `json:"text"`
+			} `json:"acceptedAnswer"`
+		} `json:"mainEntity"`
+	}
+	if err := json.Unmarshal([]byte(jsonLD), &page); err != nil {
+		t.Fatalf("JSON-LD does not parse: %v", err)
+	}
+	if page.Context != "https://schema.org" || page.Type != "FAQPage" || len(page.MainEntity) != 1 ||
+		page.MainEntity[0].Type != "Question" || page.MainEntity[0].AcceptedAnswer.Type != "Answer" ||
+		page.MainEntity[0].AcceptedAnswer.Text != "Yes </script> really." {
+		t.Errorf("Unexpected FAQPage: %+v", page)
+	}
+	if _, err := (FAQ{}).JSONLD(); err == nil {
+		t.Errorf("Expected an empty FAQ to be invalid")
+	}
+}
+
+func TestAppendFAQ(t *testing.T) {
+	faq := FAQ{Items: []FAQItem{{Question: "Is it <safe>?", Answer: "Yes </script> really."}}}
+	got, err := AppendFAQ("<p>Intro</p>\n", faq)
+	if err != nil {
+		t.Fatalf("AppendFAQ failed: %v", err)
+	}
+	if !strings.HasPrefix(got, "<p>Intro</p>\n\n<h2>Frequently Asked Questions</h2>\n<h3>Is it &lt;safe&gt;?</h3>") {
+		t.Errorf("Expected an HTML section after the content:\n%s", got)
+	}
+	if strings.Count(got, "</script>") != 1 || !strings.Contains(got, `<script type="application/ld+json">`) {
+		t.Errorf("Expected one JSON-LD script that the answer can't close:\n%s", got)
+	}
+	got, _ = AppendFAQ("# Title\n\nIntro", faq)
+	if !strings.Contains(got, "Intro\n\n## Frequently Asked Questions\n\n### Is it <safe>?\n\n") {
+		t.Errorf("Expected a Markdown section for Markdown content:\n%s", got)
+	}
+}
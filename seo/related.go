@@ -0,0 +1,60 @@
+package seo
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// relatedStart and relatedEnd mark the related posts block, so saving the
+// page again replaces it instead of adding a second one.
+const (
+	relatedStart = "<!-- related-posts -->"
+	relatedEnd   = "<!-- /related-posts -->"
+)
+
+// relatedBlockPattern matches a related posts block and the blank lines
+// before it.
+var relatedBlockPattern = regexp.MustCompile(`(?s)\s*` + regexp.QuoteMeta(relatedStart) + `.*?` + regexp.QuoteMeta(relatedEnd))
+
+// RelatedLink is a page linked from the related posts block.
+type RelatedLink struct {
+	Title string
+	URL   string
+}
+
+// RelatedBlock returns a block listing links under heading, as HTML or
+// Markdown, or "" if there are no links.
+func RelatedBlock(heading string, links []RelatedLink, asHTML bool) string {
+	if len(links) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(relatedStart + "\n")
+	if asHTML {
+		fmt.Fprintf(&b, "<section class=\"related-posts\">\n<h2>%s</h2>\n<ul>\n", html.EscapeString(heading))
+		for _, link := range links {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(link.URL), html.EscapeString(link.Title))
+		}
+		b.WriteString("</ul>\n</section>\n")
+	} else {
+		fmt.Fprintf(&b, "## %s\n\n", heading)
+		for _, link := range links {
+			fmt.Fprintf(&b, "- [%s](%s)\n", link.Title, link.URL)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(relatedEnd)
+	return b.String()
+}
+
+// WithRelated puts block at the end of content, replacing the block a
+// previous save added. An empty block removes it.
+func WithRelated(content, block string) string {
+	content = strings.TrimSpace(relatedBlockPattern.ReplaceAllString(content, ""))
+	if block == "" {
+		return content
+	}
+	return content + "\n\n" + block
+}
@@ -0,0 +1,33 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelatedBlock(t *testing.T) {
+	links := []RelatedLink{{"Cold Brew & Ice", "https://example.com/cold-brew/"}, {"Grinders", "https://example.com/grinders/"}}
+
+	block := RelatedBlock("Further reading", links, true)
+	if !strings.Contains(block, `<li><a href="https://example.com/cold-brew/">Cold Brew &amp; Ice</a></li>`) ||
+		!strings.Contains(block, "<h2>Further reading</h2>") {
+		t.Errorf("Unexpected HTML block:\n%s", block)
+	}
+	if md := RelatedBlock("See also", links, false); !strings.Contains(md, "## See also\n\n- [Cold Brew & Ice](https://example.com/cold-brew/)") {
+		t.Errorf("Unexpected Markdown block:\n%s", md)
+	}
+	if RelatedBlock("Further reading", nil, true) != "" {
+		t.Error("Expected no block without links")
+	}
+}
+
+func TestWithRelatedReplacesBlock(t *testing.T) {
+	first := WithRelated("<p>Body.</p>", RelatedBlock("Further reading", []RelatedLink{{"A", "/a/"}}, true))
+	second := WithRelated(first, RelatedBlock("Further reading", []RelatedLink{{"B", "/b/"}}, true))
+	if strings.Count(second, relatedStart) != 1 || strings.Contains(second, "/a/") || !strings.HasPrefix(second, "<p>Body.</p>\n\n") {
+		t.Errorf("Expected the block to be replaced:\n%s", second)
+	}
+	if got := WithRelated(second, ""); got != "<p>Body.</p>" {
+		t.Errorf("Expected an empty block to remove it, got %q", got)
+	}
+}
@@ -0,0 +1,382 @@
+package seo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaType is a schema.org type the app can generate structured data for.
+type SchemaType string
+
+const (
+	SchemaArticle       SchemaType = "Article"
+	SchemaProduct       SchemaType = "Product"
+	SchemaLocalBusiness SchemaType = "LocalBusiness"
+)
+
+// SchemaTypes lists the types in the order the UI offers them.
+var SchemaTypes = []SchemaType{SchemaArticle, SchemaProduct, SchemaLocalBusiness}
+
+// schemaContext is the only @context the app writes and accepts.
+const schemaContext = "https://schema.org"
+
+// Value kinds a property can hold.
+const (
+	kindText   = "text"
+	kindURL    = "url"
+	kindDate   = "date"
+	kindNumber = "number"
+	kindObject = "object" // A nested item, whose @type must be one of types
+)
+
+// propertySpec describes one property of a schema.org type, for validation
+// and for asking the model to extract it.
+type propertySpec struct {
+	name     string
+	kind     string
+	types    []string // Allowed @type values of an object
+	required bool     // Google won't show a rich result without it
+	hint     string   // What the model should put there
+}
+
+// schemaSpecs holds the properties the app knows for each type, including
+// the nested ones. It follows Google's structured data guidelines.
+var schemaSpecs = map[string][]propertySpec{
+	"Article": {
+		{name: "headline", kind: kindText, required: true, hint: "the article's title, at most 110 characters"},
+		{name: "description", kind: kindText, hint: "a one-sentence summary"},
+		{name: "author", kind: kindObject, types: []string{"Person", "Organization"}, hint: `{"@type": "Person", "name": ...} if the page names an author`},
+		{name: "datePublished", kind: kindDate, hint: "YYYY-MM-DD, if the page states it"},
+		{name: "dateModified", kind: kindDate},
+		{name: "image", kind: kindURL, hint: "the main image URL, if the page has one"},
+		{name: "publisher", kind: kindObject, types: []string{"Organization"}},
+		{name: "url", kind: kindURL},
+		{name: "mainEntityOfPage", kind: kindURL},
+	},
+	"Product": {
+		{name: "name", kind: kindText, required: true, hint: "the product name"},
+		{name: "description", kind: kindText, hint: "a one-sentence summary"},
+		{name: "image", kind: kindURL, hint: "the product image URL, if the page has one"},
+		{name: "brand", kind: kindObject, types: []string{"Brand", "Organization"}, hint: `{"@type": "Brand", "name": ...}`},
+		{name: "sku", kind: kindText, hint: "the SKU, if stated"},
+		{name: "offers", kind: kindObject, types: []string{"Offer"}, required: true, hint: `{"@type": "Offer", "price": ..., "priceCurrency": "USD", "availability": "https://schema.org/InStock"}`},
+		{name: "url", kind: kindURL},
+	},
+	"LocalBusiness": {
+		{name: "name", kind: kindText, required: true, hint: "the business name"},
+		{name: "description", kind: kindText, hint: "a one-sentence summary"},
+		{name: "address", kind: kindObject, types: []string{"PostalAddress"}, required: true, hint: `{"@type": "PostalAddress", "streetAddress": ..., "addressLocality": ..., "postalCode": ..., "addressCountry": ...}`},
+		{name: "telephone", kind: kindText, hint: "the phone number, if stated"},
+		{name: "openingHours", kind: kindText, hint: `e.g. "Mo-Fr 09:00-17:00", if stated`},
+		{name: "priceRange", kind: kindText, hint: `e.g. "$$", if stated`},
+		{name: "image", kind: kindURL},
+		{name: "url", kind: kindURL},
+	},
+	"Person": {
+		{name: "name", kind: kindText, required: true},
+		{name: "url", kind: kindURL},
+	},
+	"Organization": {
+		{name: "name", kind: kindText, required: true},
+		{name: "url", kind: kindURL},
+		{name: "logo", kind: kindObject, types: []string{"ImageObject"}},
+	},
+	"Brand": {
+		{name: "name", kind: kindText, required: true},
+	},
+	"ImageObject": {
+		{name: "url", kind: kindURL, required: true},
+	},
+	"Offer": {
+		{name: "price", kind: kindNumber, required: true},
+		{name: "priceCurrency", kind: kindText, required: true},
+		{name: "availability", kind: kindURL},
+		{name: "url", kind: kindURL},
+	},
+	"PostalAddress": {
+		{name: "streetAddress", kind: kindText},
+		{name: "addressLocality", kind: kindText},
+		{name: "addressRegion", kind: kindText},
+		{name: "postalCode", kind: kindText},
+		{name: "addressCountry", kind: kindText},
+	},
+}
+
+// ExtractionFields lists the properties the model should extract for t, one
+// per line, for the extraction prompt.
+func (t SchemaType) ExtractionFields() string {
+	var lines []string
+	for _, spec := range schemaSpecs[string(t)] {
+		if spec.hint != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", spec.name, spec.hint))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PageInfo is what the site already knows about a page, merged into the
+// model's extraction so it doesn't have to guess it.
+type PageInfo struct {
+	Title           string
+	URL             string
+	Modified        time.Time
+	SiteName        string
+	SiteURL         string
+	SiteDescription string
+	LogoURL         string
+}
+
+// ParseSchemaProperties reads the model's answer to an extraction prompt: a
+// JSON object, possibly wrapped in prose or a code fence.
+func ParseSchemaProperties(output string) (map[string]any, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var properties map[string]any
+	if err := json.Unmarshal([]byte(output[start:end+1]), &properties); err != nil {
+		return nil, fmt.Errorf("failed to parse structured data: %w", err)
+	}
+	return properties, nil
+}
+
+// BuildSchema combines the extracted properties with the page and site
+// metadata into a JSON-LD item of type t. Empty extracted values are dropped;
+// metadata only fills properties the model left out.
+func BuildSchema(t SchemaType, extracted map[string]any, page PageInfo) map[string]any {
+	item := map[string]any{}
+	for name, value := range extracted {
+		if s, ok := value.(string); (ok && strings.TrimSpace(s) == "") || value == nil {
+			continue
+		}
+		item[name] = value
+	}
+	item["@context"] = schemaContext
+	item["@type"] = string(t)
+
+	setDefault := func(name string, value any) {
+		if _, ok := item[name]; !ok && value != "" {
+			item[name] = value
+		}
+	}
+	var publisher map[string]any
+	if page.SiteName != "" {
+		publisher = map[string]any{"@type": "Organization", "name": page.SiteName}
+		if page.SiteURL != "" {
+			publisher["url"] = page.SiteURL
+		}
+		if page.LogoURL != "" {
+			publisher["logo"] = map[string]any{"@type": "ImageObject", "url": page.LogoURL}
+		}
+	}
+
+	switch t {
+	case SchemaArticle:
+		setDefault("headline", page.Title)
+		setDefault("url", page.URL)
+		setDefault("mainEntityOfPage", page.URL)
+		if !page.Modified.IsZero() {
+			setDefault("dateModified", page.Modified.Format(time.RFC3339))
+		}
+		if publisher != nil {
+			setDefault("publisher", publisher)
+			setDefault("author", publisher) // The site is the author when the page names none
+		}
+	case SchemaProduct:
+		setDefault("name", page.Title)
+		setDefault("url", page.URL)
+		if offers, ok := item["offers"].(map[string]any); ok {
+			if _, ok := offers["@type"]; !ok {
+				offers["@type"] = "Offer"
+			}
+			if _, ok := offers["url"]; !ok && page.URL != "" {
+				offers["url"] = page.URL
+			}
+		}
+	case SchemaLocalBusiness:
+		setDefault("name", page.SiteName)
+		setDefault("description", page.SiteDescription)
+		setDefault("url", page.SiteURL)
+		if address, ok := item["address"].(map[string]any); ok {
+			if _, ok := address["@type"]; !ok {
+				address["@type"] = "PostalAddress"
+			}
+		}
+	}
+	return item
+}
+
+// SchemaIssue is one problem found by ValidateSchema.
+type SchemaIssue struct {
+	Property string // Path to the property, e.g. "offers.price"
+	Message  string
+	Error    bool // Search engines will reject the item; otherwise a recommendation
+}
+
+func (i SchemaIssue) String() string {
+	if i.Property == "" {
+		return i.Message
+	}
+	return i.Property + ": " + i.Message
+}
+
+// ValidateSchema checks JSON-LD text against the schema for its @type:
+// required properties, value kinds and nested item types. Properties the app
+// doesn't know are reported as warnings, not errors.
+func ValidateSchema(jsonLD string) []SchemaIssue {
+	var item map[string]any
+	if err := json.Unmarshal([]byte(jsonLD), &item); err != nil {
+		return []SchemaIssue{{Message: fmt.Sprintf("not a JSON object: %v", err), Error: true}}
+	}
+	var issues []SchemaIssue
+	if item["@context"] != schemaContext {
+		issues = append(issues, SchemaIssue{Property: "@context", Message: fmt.Sprintf("must be %q", schemaContext), Error: true})
+	}
+	typeName, _ := item["@type"].(string)
+	known := false
+	for _, t := range SchemaTypes {
+		known = known || string(t) == typeName
+	}
+	if !known {
+		return append(issues, SchemaIssue{Property: "@type", Message: fmt.Sprintf("%q is not one of the supported types", typeName), Error: true})
+	}
+	return append(issues, validateItem("", typeName, item)...)
+}
+
+// validateItem checks an item of a known type, prefixing property names with path.
+func validateItem(path, typeName string, item map[string]any) []SchemaIssue {
+	var issues []SchemaIssue
+	specs := schemaSpecs[typeName]
+	knownNames := map[string]bool{"@context": true, "@type": true}
+	for _, spec := range specs {
+		knownNames[spec.name] = true
+		value, ok := item[spec.name]
+		if !ok || value == nil || value == "" {
+			if spec.required {
+				issues = append(issues, SchemaIssue{Property: path + spec.name, Message: "is required", Error: true})
+			}
+			continue
+		}
+		issues = append(issues, validateValue(path+spec.name, spec, value)...)
+	}
+
+	var unknown []string
+	for name := range item {
+		if !knownNames[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		issues = append(issues, SchemaIssue{Property: path + name, Message: fmt.Sprintf("is not a recognized %s property; check it against schema.org", typeName)})
+	}
+
+	if typeName == "Article" {
+		if headline, _ := item["headline"].(string); len([]rune(headline)) > 110 {
+			issues = append(issues, SchemaIssue{Property: path + "headline", Message: "is longer than 110 characters"})
+		}
+	}
+	if typeName == "Offer" {
+		if currency, ok := item["priceCurrency"].(string); ok && !currencyCode.MatchString(currency) {
+			issues = append(issues, SchemaIssue{Property: path + "priceCurrency", Message: "must be a 3-letter ISO 4217 code, e.g. USD", Error: true})
+		}
+	}
+	return issues
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validateValue checks one property value, or each value of a list.
+func validateValue(path string, spec propertySpec, value any) []SchemaIssue {
+	if list, ok := value.([]any); ok {
+		var issues []SchemaIssue
+		for i, v := range list {
+			issues = append(issues, validateValue(fmt.Sprintf("%s[%d]", path, i), spec, v)...)
+		}
+		return issues
+	}
+	bad := func(message string) []SchemaIssue {
+		return []SchemaIssue{{Property: path, Message: message, Error: true}}
+	}
+	switch spec.kind {
+	case kindText:
+		if _, ok := value.(string); !ok {
+			return bad("must be text")
+		}
+	case kindURL:
+		s, _ := value.(string)
+		if object, ok := value.(map[string]any); ok && spec.name == "image" {
+			return validateItem(path+".", "ImageObject", object) // An ImageObject is allowed for images
+		}
+		if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+			return bad("must be an absolute URL")
+		}
+	case kindDate:
+		s, _ := value.(string)
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			if _, err := time.Parse("2006-01-02", s); err != nil {
+				return bad("must be an ISO 8601 date, e.g. 2024-05-31")
+			}
+		}
+	case kindNumber:
+		switch n := value.(type) {
+		case float64:
+		case string:
+			if _, err := strconv.ParseFloat(n, 64); err != nil {
+				return bad("must be a number, e.g. 19.99")
+			}
+		default:
+			return bad("must be a number, e.g. 19.99")
+		}
+	case kindObject:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return bad(fmt.Sprintf("must be an object with @type %s", strings.Join(spec.types, " or ")))
+		}
+		typeName, _ := object["@type"].(string)
+		for _, t := range spec.types {
+			if t == typeName {
+				return validateItem(path+".", typeName, object)
+			}
+		}
+		return []SchemaIssue{{Property: path + ".@type", Message: fmt.Sprintf("must be %s", strings.Join(spec.types, " or ")), Error: true}}
+	}
+	return nil
+}
+
+// HasErrors reports whether any issue would make search engines reject the item.
+func HasErrors(issues []SchemaIssue) bool {
+	for _, issue := range issues {
+		if issue.Error {
+			return true
+		}
+	}
+	return false
+}
+
+var jsonLDScript = regexp.MustCompile(`(?s)\n*<script type="application/ld\+json">\s*(.*?)\s*</script>\n*`)
+
+// EmbedJSONLD adds a JSON-LD script to the end of the page content, replacing
+// any earlier script with the same @type so regenerating doesn't duplicate it.
+func EmbedJSONLD(content, jsonLD string) string {
+	var item struct {
+		Type string `json:"@type"`
+	}
+	json.Unmarshal([]byte(jsonLD), &item)
+	content = jsonLDScript.ReplaceAllStringFunc(content, func(script string) string {
+		var existing struct {
+			Type string `json:"@type"`
+		}
+		body := jsonLDScript.FindStringSubmatch(script)[1]
+		if json.Unmarshal([]byte(strings.ReplaceAll(body, `<\/`, "</")), &existing) == nil && existing.Type == item.Type {
+			return "\n\n"
+		}
+		return script
+	})
+	return strings.TrimRight(content, "\n") + "\n\n" + ScriptTag(jsonLD) + "\n"
+}
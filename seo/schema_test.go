@@ -0,0 +1,73 @@
+package seo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var schemaPage = PageInfo{
+	Title:    "Our Spring Menu",
+	URL:      "https://cafe.example/spring-menu/",
+	Modified: time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC),
+	SiteName: "Corner Cafe",
+	SiteURL:  "https://cafe.example/",
+	LogoURL:  "https://cafe.example/logo.png",
+}
+
+func TestBuildArticleSchema(t *testing.T) {
+	extracted, err := ParseSchemaProperties("```json\n{\"description\": \"What's new this spring.\", \"image\": \"\"}\n```")
+	if err != nil {
+		t.Fatalf("ParseSchemaProperties failed: %v", err)
+	}
+	item := BuildSchema(SchemaArticle, extracted, schemaPage)
+	if item["headline"] != "Our Spring Menu" || item["dateModified"] != "2024-05-31T09:00:00Z" || item["image"] != nil {
+		t.Errorf("Expected page metadata merged and empty values dropped, got %v", item)
+	}
+	data, _ := json.Marshal(item)
+	if issues := ValidateSchema(string(data)); len(issues) != 0 {
+		t.Errorf("Expected a valid article, got %v", issues)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	product := `{
+		"@context": "https://schema.org", "@type": "Product", "name": "Beans",
+		"offers": {"@type": "Offer", "price": "a lot", "priceCurrency": "dollars"},
+		"color": "brown"
+	}`
+	got := map[string]bool{}
+	for _, issue := range ValidateSchema(product) {
+		got[issue.String()] = issue.Error
+	}
+	for want, isError := range map[string]bool{
+		"offers.price: must be a number, e.g. 19.99":                               true,
+		"offers.priceCurrency: must be a 3-letter ISO 4217 code, e.g. USD":         true,
+		"color: is not a recognized Product property; check it against schema.org": false,
+	} {
+		if e, ok := got[want]; !ok || e != isError {
+			t.Errorf("Expected issue %q (error %v), got %v", want, isError, got)
+		}
+	}
+
+	business := `{"@context": "https://schema.org", "@type": "LocalBusiness", "name": "Corner Cafe", "address": "1 Main St"}`
+	issues := ValidateSchema(business)
+	if !HasErrors(issues) || issues[0].Property != "address" {
+		t.Errorf("Expected the address to need a PostalAddress, got %v", issues)
+	}
+	if issues := ValidateSchema(`{"@context": "https://schema.org", "@type": "Recipe"}`); !HasErrors(issues) {
+		t.Errorf("Expected an unsupported type to be rejected")
+	}
+}
+
+func TestEmbedJSONLD(t *testing.T) {
+	content := "<p>Menu</p>\n\n" + ScriptTag(`{"@type": "Article", "headline": "Old"}`) + "\n\n" + ScriptTag(`{"@type": "FAQPage"}`) + "\n"
+	got := EmbedJSONLD(content, `{"@type": "Article", "headline": "New"}`)
+	if strings.Contains(got, "Old") || !strings.Contains(got, "FAQPage") || strings.Count(got, "<script") != 2 {
+		t.Errorf("Expected the earlier Article to be replaced and the FAQ kept:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "<p>Menu</p>\n\n") || !strings.HasSuffix(got, "\"New\"}\n</script>\n") {
+		t.Errorf("Unexpected layout:\n%s", got)
+	}
+}
@@ -0,0 +1,96 @@
+package seo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxSlugWords is the most words a suggested slug keeps; longer slugs are
+// truncated in search results and harder to share.
+const maxSlugWords = 6
+
+// maxSlugSuggestions is how many free slugs SuggestSlugs returns.
+const maxSlugSuggestions = 4
+
+// slugStopWords are dropped from slugs unless the title has nothing else.
+var slugStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true,
+	"of": true, "to": true, "in": true, "on": true, "at": true, "for": true,
+	"with": true, "by": true, "from": true, "into": true, "is": true, "are": true,
+	"be": true, "your": true, "our": true, "its": true,
+}
+
+// slugFolds replaces accented letters by their ASCII base, as WordPress
+// does when it sanitizes a title into a slug.
+var slugFolds = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+	"'", "", "’", "",
+)
+
+// slugWords returns the words of a title as they appear in a slug.
+func slugWords(title string) []string {
+	return strings.FieldsFunc(slugFolds.Replace(strings.ToLower(title)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// keywords drops the stop words, unless that leaves nothing.
+func keywords(words []string) []string {
+	var kept []string
+	for _, word := range words {
+		if !slugStopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// joinSlug joins at most n words with "-".
+func joinSlug(words []string, n int) string {
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, "-")
+}
+
+// Slug returns an SEO-friendly slug for a title: lowercase ASCII keywords
+// joined by "-", without stop words and at most maxSlugWords long. It is
+// empty if the title has no letters or digits.
+func Slug(title string) string {
+	return joinSlug(keywords(slugWords(title)), maxSlugWords)
+}
+
+// SuggestSlugs returns up to maxSlugSuggestions slugs for a title that are
+// not taken, best first: Slug(title), then the title's words with the stop
+// words kept, a shorter slug of its first three keywords, and finally
+// Slug(title) numbered the way WordPress numbers duplicates.
+func SuggestSlugs(title string, taken map[string]bool) []string {
+	words := slugWords(title)
+	base := Slug(title)
+	if base == "" {
+		return nil
+	}
+	candidates := []string{base, joinSlug(words, maxSlugWords+2), joinSlug(keywords(words), 3)}
+	seen := map[string]bool{}
+	var free []string
+	for _, candidate := range candidates {
+		if !seen[candidate] && !taken[candidate] {
+			free = append(free, candidate)
+		}
+		seen[candidate] = true
+	}
+	for n := 2; len(free) < maxSlugSuggestions; n++ {
+		if numbered := fmt.Sprintf("%s-%d", base, n); !taken[numbered] {
+			free = append(free, numbered)
+		}
+	}
+	return free
+}
@@ -0,0 +1,41 @@
+package seo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"The Best Café Guide for Beginners":                "best-cafe-guide-beginners",
+		"Don't Panic: A Guide to the Galaxy":               "dont-panic-guide-galaxy",
+		"How to Brew Cold Brew Coffee at Home in 10 Steps": "how-brew-cold-brew-coffee-home",
+		"The And Of": "the-and-of",
+		"!!!":        "",
+	}
+	for title, want := range tests {
+		if got := Slug(title); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestSuggestSlugs(t *testing.T) {
+	title := "A Guide to Cold Brew Coffee"
+	if got := SuggestSlugs(title, nil); !reflect.DeepEqual(got, []string{
+		"guide-cold-brew-coffee", "a-guide-to-cold-brew-coffee", "guide-cold-brew", "guide-cold-brew-coffee-2",
+	}) {
+		t.Errorf("Unexpected suggestions %v", got)
+	}
+
+	taken := map[string]bool{"guide-cold-brew-coffee": true, "guide-cold-brew-coffee-2": true}
+	if got := SuggestSlugs(title, taken); !reflect.DeepEqual(got, []string{
+		"a-guide-to-cold-brew-coffee", "guide-cold-brew", "guide-cold-brew-coffee-3", "guide-cold-brew-coffee-4",
+	}) {
+		t.Errorf("Unexpected suggestions with taken slugs %v", got)
+	}
+
+	if got := SuggestSlugs("???", nil); got != nil {
+		t.Errorf("Expected no suggestions, got %v", got)
+	}
+}
@@ -0,0 +1,102 @@
+package seo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"Inference_Engine/utils"
+)
+
+// wordCountTolerance is how far from the target word count a draft may be
+// before Check reports it.
+const wordCountTolerance = 0.2
+
+// Targets are the SEO goals for one piece of content.
+type Targets struct {
+	Keyword           string   // The primary keyword to rank for
+	SecondaryKeywords []string // Related terms to work in
+	WordCount         int      // Target length in words; 0 for no target
+}
+
+// IsEmpty reports whether no target is set.
+func (t Targets) IsEmpty() bool {
+	return t.Keyword == "" && len(t.SecondaryKeywords) == 0 && t.WordCount == 0
+}
+
+// Instruction describes the targets for the model, to be added to the
+// generation instructions. It is empty when no target is set.
+func (t Targets) Instruction() string {
+	var lines []string
+	if t.Keyword != "" {
+		lines = append(lines, fmt.Sprintf("- Target keyword: %q. Use it in the title, the first paragraph and at least one subheading, and naturally elsewhere.", t.Keyword))
+	}
+	if len(t.SecondaryKeywords) > 0 {
+		quoted := make([]string, len(t.SecondaryKeywords))
+		for i, keyword := range t.SecondaryKeywords {
+			quoted[i] = fmt.Sprintf("%q", keyword)
+		}
+		lines = append(lines, fmt.Sprintf("- Work in these related terms where they fit: %s.", strings.Join(quoted, ", ")))
+	}
+	if t.WordCount > 0 {
+		lines = append(lines, fmt.Sprintf("- Aim for about %d words.", t.WordCount))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "SEO targets:\n" + strings.Join(lines, "\n")
+}
+
+// Check reports how the content measures up to the targets, one finding per
+// line: keyword use, missing related terms and length.
+func (t Targets) Check(content string) []string {
+	text := content
+	if utils.LooksLikeHTML(text) {
+		text = utils.HTMLToMarkdown(text)
+	}
+	var findings []string
+	if t.Keyword != "" {
+		count := len(phrasePattern(t.Keyword).FindAllStringIndex(text, -1))
+		switch {
+		case count == 0:
+			findings = append(findings, fmt.Sprintf("The target keyword %q is not used.", t.Keyword))
+		case !phrasePattern(t.Keyword).MatchString(firstWords(text, 100)):
+			findings = append(findings, fmt.Sprintf("The target keyword %q is used %d times, but not near the start.", t.Keyword, count))
+		default:
+			findings = append(findings, fmt.Sprintf("The target keyword %q is used %d times.", t.Keyword, count))
+		}
+	}
+	var missing []string
+	for _, keyword := range t.SecondaryKeywords {
+		if !phrasePattern(keyword).MatchString(text) {
+			missing = append(missing, keyword)
+		}
+	}
+	if len(missing) > 0 {
+		findings = append(findings, fmt.Sprintf("Related terms not used: %s.", strings.Join(missing, ", ")))
+	}
+	if t.WordCount > 0 {
+		words := len(strings.FieldsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+		}))
+		if diff := float64(words-t.WordCount) / float64(t.WordCount); diff > wordCountTolerance || diff < -wordCountTolerance {
+			findings = append(findings, fmt.Sprintf("%d words, target %d.", words, t.WordCount))
+		}
+	}
+	return findings
+}
+
+// phrasePattern matches a phrase as whole words, ignoring case.
+func phrasePattern(phrase string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(phrase) + `\b`)
+}
+
+// firstWords returns the start of text, up to n words.
+func firstWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,26 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTargetsCheck(t *testing.T) {
+	targets := Targets{Keyword: "cold brew", SecondaryKeywords: []string{"iced coffee", "steeping"}, WordCount: 10}
+	content := "<h1>Cold Brew at Home</h1><p>Making cold brew is easy, and steeping takes a night.</p>"
+	findings := targets.Check(content)
+	want := []string{
+		`The target keyword "cold brew" is used 2 times.`,
+		"Related terms not used: iced coffee.",
+		"14 words, target 10.",
+	}
+	if strings.Join(findings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected findings:\n%s", strings.Join(findings, "\n"))
+	}
+	if got := (Targets{Keyword: "espresso"}).Check(content); len(got) != 1 || !strings.Contains(got[0], "not used") {
+		t.Errorf("Expected an unused keyword to be reported, got %v", got)
+	}
+	if (Targets{}).Instruction() != "" || !strings.Contains(targets.Instruction(), `"iced coffee", "steeping"`) {
+		t.Errorf("Unexpected instruction:\n%s", targets.Instruction())
+	}
+}
@@ -0,0 +1,87 @@
+package seo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxNewTags is the most new tags a suggestion keeps.
+const maxNewTags = 5
+
+// maxTagLength is the longest new tag kept; longer ones are sentences.
+const maxTagLength = 40
+
+// TaxonomySuggestion is the categories and tags suggested for a post: the
+// site's existing ones, by name, and new tags to create.
+type TaxonomySuggestion struct {
+	Categories []string
+	Tags       []string
+	NewTags    []string
+}
+
+// ParseTaxonomySuggestion reads the model's answer to a taxonomy suggestion
+// prompt: a JSON object, possibly wrapped in prose or a code fence. Only
+// the site's categories and tags are kept, spelled as the site spells them;
+// a "new" tag the site has already counts as one of its tags.
+func ParseTaxonomySuggestion(output string, categories, tags []string) (TaxonomySuggestion, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return TaxonomySuggestion{}, fmt.Errorf("no JSON object in the model's answer")
+	}
+	var raw struct {
+		Categories []string `json:"categories"`
+		Tags       []string `json:"tags"`
+		NewTags    []string `json:"new_tags"`
+	}
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return TaxonomySuggestion{}, fmt.Errorf("failed to parse taxonomy suggestion: %w", err)
+	}
+
+	var s TaxonomySuggestion
+	seen := map[string]bool{}
+	for _, name := range raw.Categories {
+		if match, ok := findName(categories, name); ok && !seen["c:"+strings.ToLower(match)] {
+			seen["c:"+strings.ToLower(match)] = true
+			s.Categories = append(s.Categories, match)
+		}
+	}
+	addTag := func(name string) {
+		if !seen["t:"+strings.ToLower(name)] {
+			seen["t:"+strings.ToLower(name)] = true
+			s.Tags = append(s.Tags, name)
+		}
+	}
+	for _, name := range raw.Tags {
+		if match, ok := findName(tags, name); ok {
+			addTag(match)
+		}
+	}
+	for _, name := range raw.NewTags {
+		name = strings.Join(strings.Fields(name), " ")
+		if match, ok := findName(tags, name); ok {
+			addTag(match)
+			continue
+		}
+		key := "t:" + strings.ToLower(name)
+		if name == "" || len(name) > maxTagLength || seen[key] || len(s.NewTags) == maxNewTags {
+			continue
+		}
+		seen[key] = true
+		s.NewTags = append(s.NewTags, name)
+	}
+	logger.Info("Parsed taxonomy suggestion", "categories", len(s.Categories), "tags", len(s.Tags), "new_tags", len(s.NewTags))
+	return s, nil
+}
+
+// findName returns the name in names equal to name, ignoring case and
+// surrounding spaces.
+func findName(names []string, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return n, true
+		}
+	}
+	return "", false
+}
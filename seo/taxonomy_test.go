@@ -0,0 +1,29 @@
+package seo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTaxonomySuggestion(t *testing.T) {
+	output := "```json\n" + `{"categories": ["recipes", "Travel", "Recipes"],
+	  "tags": ["Cold Brew", "Espresso "],
+	  "new_tags": ["Summer  drinks", "cold brew", "A tag that is far too long to be a useful tag at all", "Ice", "Ice", "Oat milk", "Batch brewing", "Ratios", "Extra"]}` + "\n```"
+	s, err := ParseTaxonomySuggestion(output, []string{"Recipes", "News"}, []string{"Cold Brew", "Espresso", "Milk"})
+	if err != nil {
+		t.Fatalf("ParseTaxonomySuggestion failed: %v", err)
+	}
+	if !reflect.DeepEqual(s.Categories, []string{"Recipes"}) {
+		t.Errorf("Categories = %v", s.Categories)
+	}
+	if !reflect.DeepEqual(s.Tags, []string{"Cold Brew", "Espresso"}) {
+		t.Errorf("Tags = %v", s.Tags)
+	}
+	if want := []string{"Summer drinks", "Ice", "Oat milk", "Batch brewing", "Ratios"}; !reflect.DeepEqual(s.NewTags, want) {
+		t.Errorf("NewTags = %v, want %v", s.NewTags, want)
+	}
+
+	if _, err := ParseTaxonomySuggestion("No idea.", nil, nil); err == nil {
+		t.Error("Expected an error without a JSON object")
+	}
+}
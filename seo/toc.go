@@ -0,0 +1,116 @@
+package seo
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tocClass marks the block InsertTOC adds, so inserting again replaces it.
+const tocClass = "table-of-contents"
+
+// minTOCHeadings is the fewest headings worth a table of contents.
+const minTOCHeadings = 2
+
+var (
+	tocHeading  = regexp.MustCompile(`(?is)<h([23])([^>]*)>(.*?)</h[23]>`)
+	headingID   = regexp.MustCompile(`(?i)\bid\s*=\s*["']([^"']+)["']`)
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+	existingTOC = regexp.MustCompile(`(?is)<nav class="` + tocClass + `">.*?</nav>\n*`)
+)
+
+// tocEntry is one heading listed in the table of contents.
+type tocEntry struct {
+	level int
+	id    string
+	text  string
+}
+
+// InsertTOC gives the HTML's h2 and h3 headings anchors and inserts a table
+// of contents linking to them before the first one, i.e. after the intro.
+// An earlier table of contents is replaced. It reports false, leaving the
+// content unchanged, when there are too few headings.
+func InsertTOC(content string) (string, bool) {
+	content = existingTOC.ReplaceAllString(content, "")
+	matches := tocHeading.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) < minTOCHeadings {
+		return content, false
+	}
+
+	used := map[string]bool{}
+	for _, m := range matches {
+		if id := headingID.FindStringSubmatch(content[m[4]:m[5]]); id != nil {
+			used[id[1]] = true
+		}
+	}
+	var entries []tocEntry
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		attrs, inner := content[m[4]:m[5]], content[m[6]:m[7]]
+		entry := tocEntry{level: int(content[m[2]] - '0'), text: strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(inner, "")))}
+		if id := headingID.FindStringSubmatch(attrs); id != nil {
+			entry.id = id[1]
+		} else {
+			entry.id = uniqueID(slugify(entry.text), used)
+			// Add the id to the heading's opening tag
+			b.WriteString(content[last:m[4]])
+			fmt.Fprintf(&b, ` id="%s"`, entry.id)
+			last = m[4]
+		}
+		entries = append(entries, entry)
+	}
+	b.WriteString(content[last:])
+	content = b.String()
+
+	first := tocHeading.FindStringIndex(content)[0]
+	return content[:first] + tocHTML(entries) + "\n\n" + content[first:], true
+}
+
+// tocHTML renders the entries as a list, with h3s nested under their h2.
+func tocHTML(entries []tocEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<nav class=\"%s\">\n<p><strong>Contents</strong></p>\n<ul>\n", tocClass)
+	nested := false
+	for i, entry := range entries {
+		if entry.level == 3 && !nested && i > 0 {
+			b.WriteString("<ul>\n")
+			nested = true
+		} else if entry.level == 2 && nested {
+			b.WriteString("</li>\n</ul></li>\n")
+			nested = false
+		} else if i > 0 {
+			b.WriteString("</li>\n")
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, entry.id, html.EscapeString(entry.text))
+	}
+	if nested {
+		b.WriteString("</li>\n</ul>")
+	}
+	b.WriteString("</li>\n</ul>\n</nav>")
+	return b.String()
+}
+
+// slugify turns heading text into an anchor: lowercase words joined by "-".
+func slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "section"
+	}
+	return strings.Join(words, "-")
+}
+
+// uniqueID returns id, or id with a number appended if it is taken, and
+// marks it as used.
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,47 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInsertTOC(t *testing.T) {
+	content := `<p>Intro.</p>
+<h2>Why Coffee &amp; Cake?</h2>
+<p>Because.</p>
+<h3 id="beans">Our Beans</h3>
+<h3>Our <em>Cakes</em></h3>
+<h2 class="wide">Why Coffee &amp; Cake?</h2>`
+
+	got, ok := InsertTOC(content)
+	if !ok {
+		t.Fatalf("Expected a table of contents")
+	}
+	want := `<p>Intro.</p>
+<nav class="table-of-contents">
+<p><strong>Contents</strong></p>
+<ul>
+<li><a href="#why-coffee-cake">Why Coffee &amp; Cake?</a><ul>
+<li><a href="#beans">Our Beans</a></li>
+<li><a href="#our-cakes">Our Cakes</a></li>
+</ul></li>
+<li><a href="#why-coffee-cake-2">Why Coffee &amp; Cake?</a></li>
+</ul>
+</nav>
+
+<h2 id="why-coffee-cake">Why Coffee &amp; Cake?</h2>`
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("Unexpected table of contents:\n%s", got)
+	}
+	if !strings.Contains(got, `<h3 id="our-cakes">`) || !strings.Contains(got, `<h2 id="why-coffee-cake-2" class="wide">`) {
+		t.Errorf("Expected anchors on the headings:\n%s", got)
+	}
+
+	again, _ := InsertTOC(got)
+	if again != got {
+		t.Errorf("Expected inserting again to replace the table of contents:\n%s", again)
+	}
+	if _, ok := InsertTOC("<p>Intro.</p><h2>Only</h2>"); ok {
+		t.Errorf("Expected no table of contents for a single heading")
+	}
+}
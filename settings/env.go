@@ -0,0 +1,37 @@
+package settings
+
+import "os"
+
+// EnvKey returns the setting keeping the value of the environment variable
+// name, once imported from the environment or a .env file.
+func EnvKey(name string) Key[string] {
+	return NewKey("env."+name, "")
+}
+
+// ImportEnv keeps the environment variables among names that are set and
+// differ from the kept value, and returns the names it kept.
+func ImportEnv(names []string) []string {
+	var imported []string
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" && value != EnvKey(name).Get() {
+			EnvKey(name).Set(value)
+			imported = append(imported, name)
+		}
+	}
+	return imported
+}
+
+// ApplyEnv sets the environment variables among names that have a kept
+// value, for the code that reads them, and returns how many it set. Kept
+// values win over the environment, so a .env file left behind after
+// importing doesn't undo later changes.
+func ApplyEnv(names []string) int {
+	count := 0
+	for _, name := range names {
+		if value := EnvKey(name).Get(); value != "" {
+			os.Setenv(name, value)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,68 @@
+package settings
+
+import "sync"
+
+// memoryBackend keeps settings in memory, for when there are no app
+// preferences (tests, or before the app starts).
+type memoryBackend struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{values: map[string]any{}}
+}
+
+// lookup returns the value of key if it is set with type T.
+func lookup[T any](m *memoryBackend, key string, fallback T) T {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.values[key].(T); ok {
+		return value
+	}
+	return fallback
+}
+
+func (m *memoryBackend) set(key string, value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+}
+
+func (m *memoryBackend) BoolWithFallback(key string, fallback bool) bool {
+	return lookup(m, key, fallback)
+}
+
+func (m *memoryBackend) SetBool(key string, value bool) { m.set(key, value) }
+
+func (m *memoryBackend) IntWithFallback(key string, fallback int) int {
+	return lookup(m, key, fallback)
+}
+
+func (m *memoryBackend) SetInt(key string, value int) { m.set(key, value) }
+
+func (m *memoryBackend) FloatWithFallback(key string, fallback float64) float64 {
+	return lookup(m, key, fallback)
+}
+
+func (m *memoryBackend) SetFloat(key string, value float64) { m.set(key, value) }
+
+func (m *memoryBackend) StringWithFallback(key string, fallback string) string {
+	return lookup(m, key, fallback)
+}
+
+func (m *memoryBackend) SetString(key string, value string) { m.set(key, value) }
+
+func (m *memoryBackend) StringListWithFallback(key string, fallback []string) []string {
+	return append([]string(nil), lookup(m, key, fallback)...)
+}
+
+func (m *memoryBackend) SetStringList(key string, value []string) {
+	m.set(key, append([]string(nil), value...))
+}
+
+func (m *memoryBackend) RemoveValue(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+}
@@ -0,0 +1,183 @@
+// Package settings keeps the app's local settings: typed keys with their
+// defaults, persisted in the app preferences and watched for changes, so
+// every view reads and writes them the same way.
+package settings
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("settings")
+
+// Backend persists setting values. fyne.Preferences satisfies it.
+type Backend interface {
+	BoolWithFallback(key string, fallback bool) bool
+	SetBool(key string, value bool)
+	IntWithFallback(key string, fallback int) int
+	SetInt(key string, value int)
+	FloatWithFallback(key string, fallback float64) float64
+	SetFloat(key string, value float64)
+	StringWithFallback(key string, fallback string) string
+	SetString(key string, value string)
+	StringListWithFallback(key string, fallback []string) []string
+	SetStringList(key string, value []string)
+	RemoveValue(key string)
+}
+
+// Service reads and writes settings in a backend and tells watchers about
+// changes. It is safe for concurrent use.
+type Service struct {
+	backend Backend
+
+	mu       sync.Mutex
+	watchers map[string]map[int]func() // By setting name, then watcher ID
+	nextID   int
+}
+
+// New returns a service persisting settings in backend, or keeping them in
+// memory if backend is nil.
+func New(backend Backend) *Service {
+	if backend == nil {
+		backend = newMemoryBackend()
+	}
+	return &Service{backend: backend, watchers: map[string]map[int]func(){}}
+}
+
+var current atomic.Pointer[Service]
+
+// Use makes s the service keys read and write.
+func Use(s *Service) {
+	current.Store(s)
+}
+
+// Current returns the service keys use. Until Use is called it is an
+// in-memory one, so nothing is persisted.
+func Current() *Service {
+	if s := current.Load(); s != nil {
+		return s
+	}
+	current.CompareAndSwap(nil, New(nil))
+	return current.Load()
+}
+
+// watch calls fn after every change of the setting name, until the returned
+// function is called.
+func (s *Service) watch(name string, fn func()) (stop func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	if s.watchers[name] == nil {
+		s.watchers[name] = map[int]func(){}
+	}
+	s.watchers[name][id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers[name], id)
+	}
+}
+
+// changed calls the watchers of the setting name.
+func (s *Service) changed(name string) {
+	s.mu.Lock()
+	var fns []func()
+	for _, fn := range s.watchers[name] {
+		fns = append(fns, fn)
+	}
+	s.mu.Unlock()
+	logger.Debug("Setting changed", "setting", name, "watchers", len(fns))
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Value is a type settings can have.
+type Value interface {
+	bool | int | float64 | string | []string
+}
+
+// Key names a setting of type T and gives its default.
+type Key[T Value] struct {
+	name         string
+	defaultValue T
+}
+
+// NewKey returns the key of the setting name, which is defaultValue until
+// set.
+func NewKey[T Value](name string, defaultValue T) Key[T] {
+	return Key[T]{name: name, defaultValue: defaultValue}
+}
+
+// Name returns the setting's name, under which it is persisted.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Default returns the setting's default value.
+func (k Key[T]) Default() T {
+	return k.defaultValue
+}
+
+// Get returns the setting's value in the current service.
+func (k Key[T]) Get() T {
+	return k.In(Current())
+}
+
+// In returns the setting's value in s.
+func (k Key[T]) In(s *Service) T {
+	var value any
+	switch d := any(k.defaultValue).(type) {
+	case bool:
+		value = s.backend.BoolWithFallback(k.name, d)
+	case int:
+		value = s.backend.IntWithFallback(k.name, d)
+	case float64:
+		value = s.backend.FloatWithFallback(k.name, d)
+	case string:
+		value = s.backend.StringWithFallback(k.name, d)
+	case []string:
+		value = s.backend.StringListWithFallback(k.name, d)
+	}
+	return value.(T)
+}
+
+// Set changes the setting in the current service.
+func (k Key[T]) Set(value T) {
+	k.SetIn(Current(), value)
+}
+
+// SetIn changes the setting in s and tells its watchers.
+func (k Key[T]) SetIn(s *Service, value T) {
+	switch v := any(value).(type) {
+	case bool:
+		s.backend.SetBool(k.name, v)
+	case int:
+		s.backend.SetInt(k.name, v)
+	case float64:
+		s.backend.SetFloat(k.name, v)
+	case string:
+		s.backend.SetString(k.name, v)
+	case []string:
+		s.backend.SetStringList(k.name, v)
+	}
+	s.changed(k.name)
+}
+
+// Reset puts the setting back to its default in the current service.
+func (k Key[T]) Reset() {
+	s := Current()
+	s.backend.RemoveValue(k.name)
+	s.changed(k.name)
+}
+
+// Watch calls fn with the setting's new value after every change in the
+// current service, on the goroutine that made it, until the returned
+// function is called.
+func (k Key[T]) Watch(fn func(T)) (stop func()) {
+	s := Current()
+	return s.watch(k.name, func() { fn(k.In(s)) })
+}
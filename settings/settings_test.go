@@ -0,0 +1,64 @@
+package settings
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	Use(New(nil))
+	chunk := NewKey("generator.chunk_sources", true)
+	mode := NewKey("generator.chunk_mode", 1)
+	scale := NewKey("ui.scale", 1.0)
+	model := NewKey("generator.chunk_map_model", "")
+	recent := NewKey("history.recent", []string{"a"})
+
+	if !chunk.Get() || mode.Get() != 1 || scale.Get() != 1.0 || model.Get() != "" || !reflect.DeepEqual(recent.Get(), []string{"a"}) {
+		t.Fatal("Expected the defaults before anything is set")
+	}
+	chunk.Set(false)
+	mode.Set(0)
+	scale.Set(1.25)
+	model.Set("openai/gpt-4o-mini")
+	recent.Set([]string{"b", "c"})
+	if chunk.Get() || mode.Get() != 0 || scale.Get() != 1.25 || model.Get() != "openai/gpt-4o-mini" || !reflect.DeepEqual(recent.Get(), []string{"b", "c"}) {
+		t.Error("Expected the values set")
+	}
+	mode.Reset()
+	if mode.Get() != 1 {
+		t.Errorf("Expected the default after Reset, got %d", mode.Get())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	Use(New(nil))
+	key := NewKey("app.start_tab", 2)
+	var seen []int
+	stop := key.Watch(func(tab int) { seen = append(seen, tab) })
+	key.Set(4)
+	key.Reset()
+	NewKey("app.other", 0).Set(1) // Other settings don't notify
+	stop()
+	key.Set(5)
+	if !reflect.DeepEqual(seen, []int{4, 2}) {
+		t.Errorf("Watcher saw %v, want [4 2]", seen)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	Use(New(nil))
+	t.Setenv("SETTINGS_TEST_DIR", "/srv/versions")
+	t.Setenv("SETTINGS_TEST_UNSET", "")
+	names := []string{"SETTINGS_TEST_DIR", "SETTINGS_TEST_UNSET"}
+	if imported := ImportEnv(names); !reflect.DeepEqual(imported, []string{"SETTINGS_TEST_DIR"}) {
+		t.Errorf("Expected only the set variable to be imported, got %v", imported)
+	}
+	if imported := ImportEnv(names); len(imported) != 0 {
+		t.Errorf("Expected nothing new to import the second time, got %v", imported)
+	}
+	EnvKey("SETTINGS_TEST_DIR").Set("/srv/moved")
+	if n := ApplyEnv(names); n != 1 || os.Getenv("SETTINGS_TEST_DIR") != "/srv/moved" {
+		t.Errorf("Expected the kept value to be applied, set %d", n)
+	}
+}
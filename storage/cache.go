@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// GetCache returns a cached value and when it was stored.
+func (db *DB) GetCache(namespace, key string) ([]byte, time.Time, bool) {
+	var value []byte
+	var updated int64
+	err := db.QueryRow(`SELECT value, updated FROM cache WHERE namespace = ? AND key = ?`, namespace, key).Scan(&value, &updated)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Failed to read cache", "namespace", namespace, "error", err)
+		}
+		return nil, time.Time{}, false
+	}
+	return value, DecodeTime(updated), true
+}
+
+// PutCache stores a value, replacing any previous one under the same key.
+func (db *DB) PutCache(namespace, key string, value []byte) error {
+	_, err := db.Exec(`INSERT INTO cache (namespace, key, value, updated) VALUES (?, ?, ?, ?)
+		ON CONFLICT (namespace, key) DO UPDATE SET value = excluded.value, updated = excluded.updated`,
+		namespace, key, value, EncodeTime(time.Now()))
+	return err
+}
+
+// ClearCache removes every value in a namespace.
+func (db *DB) ClearCache(namespace string) error {
+	_, err := db.Exec(`DELETE FROM cache WHERE namespace = ?`, namespace)
+	return err
+}
+
+// StringList returns a named list of strings, in the order it was saved.
+func (db *DB) StringList(list string) ([]string, error) {
+	rows, err := db.Query(`SELECT value FROM string_lists WHERE list = ? ORDER BY position`, list)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// SetStringList replaces a named list; an empty list removes it.
+func (db *DB) SetStringList(list string, values []string) error {
+	return db.Tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM string_lists WHERE list = ?`, list); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if _, err := tx.Exec(`INSERT INTO string_lists (list, position, value) VALUES (?, ?, ?)`, list, i, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Document returns a named text document (e.g. the style guide) and whether
+// it has been saved.
+func (db *DB) Document(name string) (string, bool, error) {
+	var body string
+	err := db.QueryRow(`SELECT body FROM documents WHERE name = ?`, name).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return body, true, nil
+}
+
+// SetDocument saves a named text document, replacing the previous version.
+func (db *DB) SetDocument(name, body string) error {
+	_, err := db.Exec(`INSERT INTO documents (name, body, updated) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET body = excluded.body, updated = excluded.updated`,
+		name, body, EncodeTime(time.Now()))
+	return err
+}
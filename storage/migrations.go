@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order; the database's user_version records how
+// many have run. Never edit a released migration, append a new one instead.
+var migrations = []string{
+	`CREATE TABLE sites (
+		position     INTEGER NOT NULL,
+		name         TEXT PRIMARY KEY,
+		url          TEXT NOT NULL,
+		username     TEXT NOT NULL,
+		app_password TEXT NOT NULL
+	);
+	CREATE TABLE usage_days (
+		day    TEXT PRIMARY KEY,
+		tokens INTEGER NOT NULL DEFAULT 0,
+		jobs   INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE jobs (
+		id       INTEGER PRIMARY KEY,
+		kind     TEXT NOT NULL,
+		title    TEXT NOT NULL,
+		status   TEXT NOT NULL,
+		message  TEXT NOT NULL DEFAULT '',
+		error    TEXT NOT NULL DEFAULT '',
+		created  INTEGER NOT NULL,
+		started  INTEGER NOT NULL DEFAULT 0,
+		finished INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE drafts (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		created       INTEGER NOT NULL,
+		prompt        TEXT NOT NULL,
+		instruction   TEXT NOT NULL DEFAULT '',
+		model         TEXT NOT NULL DEFAULT '',
+		sources_hash  TEXT NOT NULL DEFAULT '',
+		source_titles TEXT NOT NULL DEFAULT '[]',
+		output        TEXT NOT NULL
+	);
+	CREATE TABLE string_lists (
+		list     TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		value    TEXT NOT NULL,
+		PRIMARY KEY (list, position)
+	);
+	CREATE TABLE prompt_templates (
+		name        TEXT PRIMARY KEY,
+		instruction TEXT NOT NULL DEFAULT '',
+		prompt      TEXT NOT NULL DEFAULT '',
+		updated     INTEGER NOT NULL
+	);
+	CREATE TABLE cache (
+		namespace TEXT NOT NULL,
+		key       TEXT NOT NULL,
+		value     BLOB NOT NULL,
+		updated   INTEGER NOT NULL,
+		PRIMARY KEY (namespace, key)
+	);`,
+	`CREATE TABLE documents (
+		name    TEXT PRIMARY KEY,
+		body    TEXT NOT NULL,
+		updated INTEGER NOT NULL
+	);`,
+	`ALTER TABLE usage_days ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE usage_days ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE conversations (
+		id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		title     TEXT NOT NULL,
+		parent_id INTEGER NOT NULL DEFAULT 0,
+		updated   INTEGER NOT NULL,
+		turns     TEXT NOT NULL DEFAULT '[]'
+	);`,
+	`ALTER TABLE drafts ADD COLUMN review_state TEXT NOT NULL DEFAULT 'draft';
+	CREATE TABLE review_notes (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		draft_id INTEGER NOT NULL,
+		created  INTEGER NOT NULL,
+		reviewer TEXT NOT NULL DEFAULT '',
+		state    TEXT NOT NULL,
+		note     TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX review_notes_draft ON review_notes (draft_id);`,
+	`CREATE TABLE generation_presets (
+		name        TEXT PRIMARY KEY,
+		model       TEXT NOT NULL,
+		temperature REAL,
+		max_tokens  INTEGER NOT NULL DEFAULT 0,
+		template    TEXT NOT NULL DEFAULT '',
+		word_target INTEGER NOT NULL DEFAULT 0,
+		seo_pass    INTEGER NOT NULL DEFAULT 0,
+		updated     INTEGER NOT NULL
+	);`,
+}
+
+// migrate applies the migrations the database hasn't seen yet.
+func (db *DB) migrate() error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("state database schema version %d is newer than this version of the app supports (%d)", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		err := db.Tx(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migrations[i]); err != nil {
+				return err
+			}
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply schema migration %d: %w", i+1, err)
+		}
+		logger.Info("Applied schema migration", "version", i+1)
+	}
+	return nil
+}
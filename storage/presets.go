@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GenerationPreset is a named set of Generator settings applied together.
+type GenerationPreset struct {
+	Name        string
+	Model       string   // As picked in the Generator's model selector
+	Temperature *float64 // nil keeps the model's default
+	MaxTokens   int      // 0 keeps the model's default
+	Template    string   // Prompt template filling the prompt and instructions; "" keeps them
+	WordTarget  int      // 0 for none
+	SEOPass     bool     // Send the SEO targets and check the result against them
+	Updated     time.Time
+}
+
+// SavePreset creates or replaces the preset with p.Name.
+func (db *DB) SavePreset(p GenerationPreset) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("preset name is empty")
+	}
+	var temperature sql.NullFloat64
+	if p.Temperature != nil {
+		temperature = sql.NullFloat64{Float64: *p.Temperature, Valid: true}
+	}
+	_, err := db.Exec(`INSERT INTO generation_presets (name, model, temperature, max_tokens, template, word_target, seo_pass, updated) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET model = excluded.model, temperature = excluded.temperature, max_tokens = excluded.max_tokens,
+			template = excluded.template, word_target = excluded.word_target, seo_pass = excluded.seo_pass, updated = excluded.updated`,
+		p.Name, p.Model, temperature, p.MaxTokens, p.Template, p.WordTarget, p.SEOPass, EncodeTime(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to save preset '%s': %w", p.Name, err)
+	}
+	return nil
+}
+
+// Presets returns every preset, sorted by name.
+func (db *DB) Presets() ([]GenerationPreset, error) {
+	rows, err := db.Query(`SELECT name, model, temperature, max_tokens, template, word_target, seo_pass, updated FROM generation_presets ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load presets: %w", err)
+	}
+	defer rows.Close()
+	var presets []GenerationPreset
+	for rows.Next() {
+		var p GenerationPreset
+		var temperature sql.NullFloat64
+		var updated int64
+		if err := rows.Scan(&p.Name, &p.Model, &temperature, &p.MaxTokens, &p.Template, &p.WordTarget, &p.SEOPass, &updated); err != nil {
+			return nil, fmt.Errorf("failed to load presets: %w", err)
+		}
+		if temperature.Valid {
+			p.Temperature = &temperature.Float64
+		}
+		p.Updated = DecodeTime(updated)
+		presets = append(presets, p)
+	}
+	return presets, rows.Err()
+}
+
+// DeletePreset removes a preset.
+func (db *DB) DeletePreset(name string) error {
+	res, err := db.Exec(`DELETE FROM generation_presets WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete preset '%s': %w", name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("preset '%s' not found", name)
+	}
+	return nil
+}
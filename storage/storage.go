@@ -0,0 +1,105 @@
+// Package storage keeps the application's persistent state (saved sites,
+// usage statistics, job history, generated drafts, prompt histories and
+// templates, and cached content) in a single SQLite database, replacing the
+// JSON files and preference entries each part used to manage on its own.
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Inference_Engine/logging"
+
+	_ "modernc.org/sqlite" // Pure Go driver, registered as "sqlite"
+)
+
+var logger = logging.For("storage")
+
+// DB is the application state database. The embedded *sql.DB is available
+// to packages that own a table; the helpers below cover the shared ones.
+type DB struct {
+	*sql.DB
+	path string
+}
+
+// Open opens (creating if needed) the database at path and brings its schema
+// up to date.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	sqlDB, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	// SQLite allows one writer; a single connection keeps writers from
+	// tripping over each other. Callers must close rows before their next query.
+	sqlDB.SetMaxOpenConns(1)
+
+	db := &DB{DB: sqlDB, path: path}
+	if err := db.migrate(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	logger.Info("Opened state database", "path", path)
+	return db, nil
+}
+
+// Path returns the database file's location.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// Tx runs fn in a transaction, committing if it returns nil.
+func (db *DB) Tx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ImportLegacyFile hands the contents of a file from before the database
+// existed to load, then renames it to path+".migrated" so it is imported only
+// once. It reports whether a file was imported; a missing file is not an error.
+func ImportLegacyFile(path string, load func(data []byte) error) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := load(data); err != nil {
+		return false, fmt.Errorf("failed to import %s: %w", path, err)
+	}
+	if err := os.Rename(path, path+".migrated"); err != nil {
+		return true, fmt.Errorf("imported %s but could not rename it: %w", path, err)
+	}
+	logger.Info("Imported legacy state file", "path", path)
+	return true, nil
+}
+
+// EncodeTime converts t for an INTEGER column; the zero time is stored as 0.
+func EncodeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// DecodeTime is the inverse of EncodeTime.
+func DecodeTime(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestOpenMigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer db.Close()
+	var version int
+	db.QueryRow("PRAGMA user_version").Scan(&version)
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+}
+
+func TestCache(t *testing.T) {
+	db := openTestDB(t)
+	if _, _, ok := db.GetCache("pages", "a"); ok {
+		t.Fatalf("Expected a miss on an empty cache")
+	}
+	db.PutCache("pages", "a", []byte("one"))
+	db.PutCache("pages", "a", []byte("two"))
+	value, updated, ok := db.GetCache("pages", "a")
+	if !ok || string(value) != "two" || time.Since(updated) > time.Minute {
+		t.Errorf("Unexpected cache entry %q %v %v", value, updated, ok)
+	}
+	db.ClearCache("pages")
+	if _, _, ok := db.GetCache("pages", "a"); ok {
+		t.Errorf("Expected ClearCache to remove the entry")
+	}
+}
+
+func TestStringList(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.SetStringList("prompts", []string{"b", "a", "c"}); err != nil {
+		t.Fatalf("SetStringList failed: %v", err)
+	}
+	values, err := db.StringList("prompts")
+	if err != nil || len(values) != 3 || values[0] != "b" || values[2] != "c" {
+		t.Errorf("Expected the saved order, got %v (err %v)", values, err)
+	}
+	db.SetStringList("prompts", nil)
+	if values, _ := db.StringList("prompts"); len(values) != 0 {
+		t.Errorf("Expected an empty list, got %v", values)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	db := openTestDB(t)
+	if _, ok, err := db.Document("style_guide"); ok || err != nil {
+		t.Fatalf("Expected no document yet, got %v %v", ok, err)
+	}
+	db.SetDocument("style_guide", "v1")
+	db.SetDocument("style_guide", "v2")
+	if body, ok, _ := db.Document("style_guide"); !ok || body != "v2" {
+		t.Errorf("Expected the latest version, got %q", body)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.SaveTemplate(PromptTemplate{Name: " "}); err == nil {
+		t.Errorf("Expected an error for a blank name")
+	}
+	db.SaveTemplate(PromptTemplate{Name: "Product page", Prompt: "Describe {{product}}"})
+	db.SaveTemplate(PromptTemplate{Name: "about", Instruction: "Be warm"})
+	db.SaveTemplate(PromptTemplate{Name: "Product page", Prompt: "Sell {{product}}"})
+
+	templates, err := db.Templates()
+	if err != nil || len(templates) != 2 || templates[0].Name != "about" || templates[1].Prompt != "Sell {{product}}" {
+		t.Fatalf("Unexpected templates %+v (err %v)", templates, err)
+	}
+	if err := db.DeleteTemplate("about"); err != nil {
+		t.Errorf("DeleteTemplate failed: %v", err)
+	}
+	if err := db.DeleteTemplate("about"); err == nil {
+		t.Errorf("Expected an error deleting a missing template")
+	}
+}
+
+func TestPresets(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.SavePreset(GenerationPreset{Name: ""}); err == nil {
+		t.Errorf("Expected an error for a blank name")
+	}
+	temperature := 0.6
+	db.SavePreset(GenerationPreset{Name: "Client X blog post", Model: "MOA (Mixture of Agents)", Temperature: &temperature, Template: "Blog post", WordTarget: 1200, SEOPass: true})
+	db.SavePreset(GenerationPreset{Name: "Quick note", Model: "openai/gpt-4o-mini", MaxTokens: 400})
+
+	presets, err := db.Presets()
+	if err != nil || len(presets) != 2 {
+		t.Fatalf("Unexpected presets %+v (err %v)", presets, err)
+	}
+	p := presets[0]
+	if p.Name != "Client X blog post" || p.Temperature == nil || *p.Temperature != 0.6 || p.Template != "Blog post" || p.WordTarget != 1200 || !p.SEOPass {
+		t.Errorf("Unexpected preset %+v", p)
+	}
+	if presets[1].Temperature != nil || presets[1].MaxTokens != 400 || presets[1].SEOPass {
+		t.Errorf("Unexpected preset %+v", presets[1])
+	}
+	if err := db.DeletePreset("Quick note"); err != nil {
+		t.Errorf("DeletePreset failed: %v", err)
+	}
+	if err := db.DeletePreset("Quick note"); err == nil {
+		t.Errorf("Expected an error deleting a missing preset")
+	}
+}
+
+func TestImportLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	if imported, err := ImportLegacyFile(path, nil); imported || err != nil {
+		t.Fatalf("Expected a missing file to be skipped, got %v %v", imported, err)
+	}
+	os.WriteFile(path, []byte("[]"), 0o600)
+	var got string
+	imported, err := ImportLegacyFile(path, func(data []byte) error {
+		got = string(data)
+		return nil
+	})
+	if !imported || err != nil || got != "[]" {
+		t.Fatalf("Expected the file to be imported, got %v %v %q", imported, err, got)
+	}
+	if _, err := os.Stat(path + ".migrated"); err != nil {
+		t.Errorf("Expected the file to be renamed: %v", err)
+	}
+}
+
+func TestEncodeTime(t *testing.T) {
+	if EncodeTime(time.Time{}) != 0 || !DecodeTime(0).IsZero() {
+		t.Errorf("Expected the zero time to round-trip as 0")
+	}
+	now := time.Now()
+	if !DecodeTime(EncodeTime(now)).Equal(now) {
+		t.Errorf("Expected %v to round-trip", now)
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PromptTemplate is a named, reusable prompt and instruction pair.
+type PromptTemplate struct {
+	Name        string
+	Instruction string
+	Prompt      string
+	Updated     time.Time
+}
+
+// SaveTemplate creates or replaces the template with t.Name.
+func (db *DB) SaveTemplate(t PromptTemplate) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("template name is empty")
+	}
+	_, err := db.Exec(`INSERT INTO prompt_templates (name, instruction, prompt, updated) VALUES (?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET instruction = excluded.instruction, prompt = excluded.prompt, updated = excluded.updated`,
+		t.Name, t.Instruction, t.Prompt, EncodeTime(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to save template '%s': %w", t.Name, err)
+	}
+	return nil
+}
+
+// Templates returns every template, sorted by name.
+func (db *DB) Templates() ([]PromptTemplate, error) {
+	rows, err := db.Query(`SELECT name, instruction, prompt, updated FROM prompt_templates ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	defer rows.Close()
+	var templates []PromptTemplate
+	for rows.Next() {
+		var t PromptTemplate
+		var updated int64
+		if err := rows.Scan(&t.Name, &t.Instruction, &t.Prompt, &updated); err != nil {
+			return nil, fmt.Errorf("failed to load templates: %w", err)
+		}
+		t.Updated = DecodeTime(updated)
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate removes a template.
+func (db *DB) DeleteTemplate(name string) error {
+	res, err := db.Exec(`DELETE FROM prompt_templates WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete template '%s': %w", name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("template '%s' not found", name)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package tracing exports OpenTelemetry spans of the request pipeline (job →
+// generation → provider → WordPress save) over OTLP, so slow generations can
+// be followed end to end in a tracing backend.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"Inference_Engine/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var logger = logging.For("tracing")
+
+// serviceName names the app in the tracing backend unless OTEL_SERVICE_NAME
+// is set.
+const serviceName = "wordpress-inference-engine"
+
+// tracerName is the instrumentation scope of every span.
+const tracerName = "Inference_Engine"
+
+// Setup exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set; the exporter reads the other
+// standard OTEL_EXPORTER_OTLP_* variables (headers, timeout, insecure). It
+// returns a function flushing and stopping the export, which does nothing
+// when tracing is off.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName(serviceName)),
+		resource.Environment(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the tracing resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	logger.Info("Exporting traces over OTLP")
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of any span in ctx. Without Setup the span
+// is a no-op.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
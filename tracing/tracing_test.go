@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpansNestAndRecordErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	ctx, job := Start(context.Background(), "job Generation")
+	_, generate := Start(ctx, "generate")
+	End(generate, errors.New("provider unavailable"))
+	End(job, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() || child.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Expected generate to be a child of the job span")
+	}
+	if child.Status().Code != codes.Error || len(child.Events()) != 1 {
+		t.Errorf("Expected the error to be recorded, got %v", child.Status())
+	}
+	if parent.Status().Code == codes.Error {
+		t.Errorf("Expected the job span to succeed")
+	}
+}
+
+func TestSetupIsOffWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	shutdown, err := Setup(context.Background())
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected shutdown to do nothing, got %v", err)
+	}
+}
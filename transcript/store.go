@@ -0,0 +1,145 @@
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+var logger = logging.For("transcript")
+
+// titleLength caps the runes of a title made from the first prompt.
+const titleLength = 60
+
+// Saved is a chat conversation kept in the state database.
+type Saved struct {
+	ID       int
+	Title    string
+	ParentID int // The conversation this one was branched from; 0 for none
+	Updated  time.Time
+	Turns    []Turn
+}
+
+// Title names a conversation after its first prompt.
+func Title(turns []Turn) string {
+	if len(turns) == 0 {
+		return "New conversation"
+	}
+	title := strings.Join(strings.Fields(turns[0].Prompt), " ")
+	if utf8.RuneCountInString(title) > titleLength {
+		title = string([]rune(title)[:titleLength-1]) + "…"
+	}
+	return title
+}
+
+// Branch returns a new conversation with the first n turns of c, to continue
+// differently from there. Save assigns it an ID.
+func (c Saved) Branch(n int) Saved {
+	n = min(max(n, 0), len(c.Turns))
+	return Saved{
+		Title:    c.Title + " (branch)",
+		ParentID: c.ID,
+		Turns:    append([]Turn(nil), c.Turns[:n]...),
+	}
+}
+
+// Store is the conversations table. It is safe for concurrent use.
+type Store struct {
+	db *storage.DB
+}
+
+// Open returns the conversations kept in db.
+func Open(db *storage.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("saved conversations need a state database")
+	}
+	return &Store{db: db}, nil
+}
+
+// Save stores c, assigning an ID to a new conversation, and sets its update
+// time.
+func (s *Store) Save(c *Saved) error {
+	turns, err := json.Marshal(c.Turns)
+	if err != nil {
+		return err
+	}
+	if c.Title == "" {
+		c.Title = Title(c.Turns)
+	}
+	c.Updated = time.Now()
+	if c.ID == 0 {
+		res, err := s.db.Exec(`INSERT INTO conversations (title, parent_id, updated, turns) VALUES (?, ?, ?, ?)`,
+			c.Title, c.ParentID, storage.EncodeTime(c.Updated), string(turns))
+		if err != nil {
+			return fmt.Errorf("failed to save conversation: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		c.ID = int(id)
+		return nil
+	}
+	_, err = s.db.Exec(`UPDATE conversations SET title = ?, parent_id = ?, updated = ?, turns = ? WHERE id = ?`,
+		c.Title, c.ParentID, storage.EncodeTime(c.Updated), string(turns), c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save conversation %d: %w", c.ID, err)
+	}
+	return nil
+}
+
+// List returns the conversations, most recently updated first.
+func (s *Store) List() []Saved {
+	conversations, err := s.query(`ORDER BY updated DESC, id DESC`)
+	if err != nil {
+		logger.Error("Failed to load conversations", "error", err)
+	}
+	return conversations
+}
+
+// Get returns the conversation with the given ID.
+func (s *Store) Get(id int) (Saved, bool) {
+	conversations, err := s.query(`WHERE id = ?`, id)
+	if err != nil || len(conversations) == 0 {
+		return Saved{}, false
+	}
+	return conversations[0], true
+}
+
+// Delete removes one conversation. Its branches are kept.
+func (s *Store) Delete(id int) error {
+	if _, err := s.db.Exec(`DELETE FROM conversations WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete conversation %d: %w", id, err)
+	}
+	return nil
+}
+
+// query loads the conversations selected by a WHERE/ORDER BY clause.
+func (s *Store) query(clause string, args ...any) ([]Saved, error) {
+	rows, err := s.db.Query(`SELECT id, title, parent_id, updated, turns FROM conversations `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var conversations []Saved
+	for rows.Next() {
+		var c Saved
+		var updated int64
+		var turns string
+		if err := rows.Scan(&c.ID, &c.Title, &c.ParentID, &updated, &turns); err != nil {
+			return conversations, err
+		}
+		c.Updated = storage.DecodeTime(updated)
+		if err := json.Unmarshal([]byte(turns), &c.Turns); err != nil {
+			logger.Warn("Ignoring unreadable conversation turns", "conversation", c.ID, "error", err)
+		}
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
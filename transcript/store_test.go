@@ -0,0 +1,66 @@
+package transcript
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestStoreSavesAndBranches(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+	s, err := Open(db)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	c := Saved{Turns: testTurns()}
+	if err := s.Save(&c); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if c.ID == 0 || !strings.HasPrefix(c.Title, "Plan a series on cold brew.") {
+		t.Errorf("Expected an ID and a title from the first prompt, got %d %q", c.ID, c.Title)
+	}
+
+	branch := c.Branch(1)
+	branch.Turns = append(branch.Turns, Turn{Prompt: "Make it five parts instead", Response: "1. Beans"})
+	if err := s.Save(&branch); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if branch.ParentID != c.ID || branch.ID == c.ID {
+		t.Errorf("Expected a new conversation branched from %d, got %+v", c.ID, branch)
+	}
+
+	got, ok := s.Get(c.ID)
+	if !ok || len(got.Turns) != 2 || got.Turns[1].Prompt != "And a fourth part?" {
+		t.Errorf("Expected the original conversation unchanged, got %+v", got)
+	}
+	if list := s.List(); len(list) != 2 || list[0].ID != branch.ID {
+		t.Errorf("Expected the branch first as the last updated, got %+v", list)
+	}
+
+	if err := s.Delete(c.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := s.Get(c.ID); ok {
+		t.Errorf("Expected the conversation to be deleted")
+	}
+	if _, ok := s.Get(branch.ID); !ok {
+		t.Errorf("Expected the branch to be kept")
+	}
+}
+
+func TestTitle(t *testing.T) {
+	if got := Title(nil); got != "New conversation" {
+		t.Errorf("Expected a default title, got %q", got)
+	}
+	long := Title([]Turn{{Prompt: strings.Repeat("word ", 30)}})
+	if len([]rune(long)) != titleLength || !strings.HasSuffix(long, "…") {
+		t.Errorf("Expected a shortened title, got %q", long)
+	}
+}
@@ -0,0 +1,109 @@
+// Package transcript saves chat conversations as JSON or Markdown and reads
+// them back, so a conversation with the assistant can be continued later.
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Version is the JSON format's version.
+const Version = 1
+
+// timeLayout is how Markdown transcripts write times.
+const timeLayout = "2006-01-02 15:04:05"
+
+// Turn is one prompt/response exchange.
+type Turn struct {
+	Time             time.Time `json:"time"`
+	Prompt           string    `json:"prompt"`
+	Response         string    `json:"response,omitempty"`
+	Error            string    `json:"error,omitempty"` // Set instead of Response when the request failed
+	PromptTokens     int       `json:"prompt_tokens,omitempty"`
+	CompletionTokens int       `json:"completion_tokens,omitempty"`
+}
+
+// Conversation is the JSON export of a chat.
+type Conversation struct {
+	Version  int       `json:"version"`
+	Exported time.Time `json:"exported"`
+	Turns    []Turn    `json:"turns"`
+}
+
+// JSON writes turns as an indented Conversation.
+func JSON(turns []Turn, exported time.Time) ([]byte, error) {
+	return json.MarshalIndent(Conversation{Version: Version, Exported: exported, Turns: turns}, "", "  ")
+}
+
+// Markdown renders turns as a readable transcript, which Parse can read back.
+func Markdown(turns []Turn, exported time.Time) string {
+	var b strings.Builder
+	b.WriteString("# Inference Chat Transcript\n\n")
+	fmt.Fprintf(&b, "Exported %s\n", exported.Format(timeLayout))
+	for i, turn := range turns {
+		fmt.Fprintf(&b, "\n---\n\n## Message %d (%s)\n\n", i+1, turn.Time.Format(timeLayout))
+		b.WriteString("**You:**\n\n")
+		b.WriteString(turn.Prompt)
+		b.WriteString("\n\n**AI:**\n\n")
+		if turn.Error != "" {
+			fmt.Fprintf(&b, "_Error: %s_\n", turn.Error)
+		} else {
+			b.WriteString(turn.Response)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// messageHeader starts a turn in a Markdown transcript.
+var messageHeader = regexp.MustCompile(`(?m)^## Message \d+ \(([^)\n]*)\)\n\n\*\*You:\*\*\n\n`)
+
+// Parse reads a conversation exported as JSON or Markdown.
+func Parse(data []byte) ([]Turn, error) {
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		var conversation Conversation
+		if err := json.Unmarshal(trimmed, &conversation); err != nil {
+			return nil, fmt.Errorf("invalid conversation file: %w", err)
+		}
+		if conversation.Version > Version {
+			return nil, fmt.Errorf("the conversation file's version %d is newer than this version of the app supports (%d)", conversation.Version, Version)
+		}
+		return conversation.Turns, nil
+	}
+	return parseMarkdown(strings.ReplaceAll(string(data), "\r\n", "\n"))
+}
+
+// parseMarkdown reads the turns of a transcript written by Markdown.
+func parseMarkdown(text string) ([]Turn, error) {
+	headers := messageHeader.FindAllStringSubmatchIndex(text, -1)
+	if len(headers) == 0 {
+		return nil, errors.New("no chat messages found; expected a transcript exported from the chat")
+	}
+	turns := make([]Turn, 0, len(headers))
+	for i, header := range headers {
+		end := len(text)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := strings.TrimSuffix(text[header[1]:end], "\n---\n\n")
+		prompt, response, ok := strings.Cut(body, "\n\n**AI:**\n\n")
+		if !ok {
+			return nil, fmt.Errorf("message %d has no response", i+1)
+		}
+		turn := Turn{Prompt: prompt}
+		turn.Time, _ = time.ParseInLocation(timeLayout, text[header[2]:header[3]], time.Local)
+		response = strings.TrimSuffix(response, "\n")
+		if strings.HasPrefix(response, "_Error: ") && strings.HasSuffix(response, "_") && !strings.Contains(response, "\n") {
+			turn.Error = strings.TrimSuffix(strings.TrimPrefix(response, "_Error: "), "_")
+		} else {
+			turn.Response = response
+		}
+		turns = append(turns, turn)
+	}
+	return turns, nil
+}
@@ -0,0 +1,52 @@
+package transcript
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testTurns() []Turn {
+	at := time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local)
+	return []Turn{
+		{Time: at, Prompt: "Plan a series on cold brew.\n\n---\n\nKeep it short.", Response: "1. Basics\n2. Ratios", PromptTokens: 12, CompletionTokens: 8},
+		{Time: at.Add(time.Minute), Prompt: "And a fourth part?", Error: "all attempts failed"},
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data, err := JSON(testTurns(), time.Now())
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	turns, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(turns) != 2 || !turns[0].Time.Equal(testTurns()[0].Time) || turns[0].Response != "1. Basics\n2. Ratios" || turns[0].CompletionTokens != 8 || turns[1].Error == "" {
+		t.Errorf("Expected the turns back, got %+v", turns)
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	turns, err := Parse([]byte(Markdown(testTurns(), time.Now())))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := testTurns()
+	for i := range want {
+		want[i].PromptTokens, want[i].CompletionTokens = 0, 0 // Markdown leaves out the usage
+	}
+	if !reflect.DeepEqual(turns, want) {
+		t.Errorf("Expected %+v, got %+v", want, turns)
+	}
+}
+
+func TestParseRejectsOtherFiles(t *testing.T) {
+	if _, err := Parse([]byte("# Notes\n\nNothing here.")); err == nil {
+		t.Errorf("Expected an error for Markdown without chat messages")
+	}
+	if _, err := Parse([]byte(`{"version": 99, "turns": []}`)); err == nil {
+		t.Errorf("Expected an error for a newer format")
+	}
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+)
+
+// newReadingOrderBorder lays objects out like container.NewBorder, but adds
+// them in reading order (top, left, center, right, bottom). Fyne moves
+// keyboard focus through a container's objects in the order they were
+// added, and container.NewBorder puts the center first, so Tab would jump
+// past toolbars and filters above the main content.
+func newReadingOrderBorder(top, bottom, left, right fyne.CanvasObject, center ...fyne.CanvasObject) *fyne.Container {
+	var objects []fyne.CanvasObject
+	for _, o := range []fyne.CanvasObject{top, left} {
+		if o != nil {
+			objects = append(objects, o)
+		}
+	}
+	objects = append(objects, center...)
+	for _, o := range []fyne.CanvasObject{right, bottom} {
+		if o != nil {
+			objects = append(objects, o)
+		}
+	}
+	return container.New(layout.NewBorderLayout(top, bottom, left, right), objects...)
+}
+
+// focusRegions is implemented by views that have several keyboard focus
+// areas (lists, editors) the user can cycle between with Ctrl+F6.
+type focusRegions interface {
+	FocusRegions() []fyne.Focusable
+}
+
+// focusNextRegion moves focus to the next (or previous) region of a view,
+// starting from the first one if focus is elsewhere.
+func focusNextRegion(c fyne.Canvas, view focusRegions, backwards bool) {
+	regions := view.FocusRegions()
+	if len(regions) == 0 {
+		return
+	}
+	next := 0
+	if backwards {
+		next = len(regions) - 1
+	}
+	for i, region := range regions {
+		if region == c.Focused() {
+			if backwards {
+				next = (i + len(regions) - 1) % len(regions)
+			} else {
+				next = (i + 1) % len(regions)
+			}
+			break
+		}
+	}
+	c.Focus(regions[next])
+}
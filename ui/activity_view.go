@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/jobs"
+	"Inference_Engine/logging"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ActivityView lists current and past background jobs with cancel and retry controls
+type ActivityView struct {
+	container fyne.CanvasObject
+	queue     *jobs.Queue
+	wpService *wordpress.WordPressService // Site for the maintenance jobs; nil hides them
+	window    fyne.Window
+
+	jobList      *widget.List
+	detailLabel  *widget.Label
+	cancelButton *widget.Button
+	retryButton  *widget.Button
+	clearButton  *widget.Button
+	summaryLabel *widget.Label
+
+	backfillButton *CapabilityButton // Starts the alt text backfill of the media library
+
+	// Data
+	jobs          []jobs.Job
+	selectedJobID int
+}
+
+// NewActivityView creates a new ActivityView
+func NewActivityView(queue *jobs.Queue, window fyne.Window) *ActivityView {
+	view := &ActivityView{
+		queue:         queue,
+		window:        window,
+		selectedJobID: -1,
+	}
+	view.initialize()
+	queue.OnChange(view.Refresh)
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *ActivityView) initialize() {
+	v.jobList = widget.NewList(
+		func() int {
+			return len(v.jobs)
+		},
+		func() fyne.CanvasObject {
+			progress := widget.NewProgressBar()
+			return newReadingOrderBorder(nil, nil, nil, container.NewGridWrap(fyne.NewSize(140, progress.MinSize().Height), progress),
+				widget.NewLabel("Template job title"))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.jobs) {
+				return
+			}
+			job := v.jobs[id]
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf("#%d  [%s] %s — %s", job.ID, i18n.T(job.Kind), job.Title, i18n.T(string(job.Status))))
+			progress := row.Objects[1].(*fyne.Container).Objects[0].(*widget.ProgressBar)
+			switch {
+			case job.Status.IsFinished():
+				progress.SetValue(1)
+			case job.Progress >= 0:
+				progress.SetValue(job.Progress)
+			default:
+				progress.SetValue(0)
+			}
+		},
+	)
+	v.jobList.OnSelected = func(id widget.ListItemID) {
+		if id < len(v.jobs) {
+			v.selectedJobID = v.jobs[id].ID
+			v.updateDetails()
+		}
+	}
+
+	v.detailLabel = widget.NewLabel(i18n.T("Select a job to see its details."))
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+
+	v.cancelButton = widget.NewButton(i18n.T("Cancel Job"), func() {
+		if err := v.queue.Cancel(v.selectedJobID); err != nil {
+			ShowError(err, v.window)
+		}
+	})
+	v.retryButton = widget.NewButton(i18n.T("Retry Job"), func() {
+		newID, err := v.queue.Retry(v.selectedJobID)
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		logger.Info("ActivityView: retrying job", logging.JobID(v.selectedJobID), "new_job_id", newID)
+	})
+	v.clearButton = widget.NewButton(i18n.T("Clear Finished"), func() {
+		v.selectedJobID = -1
+		v.jobList.UnselectAll()
+		v.queue.ClearFinished()
+	})
+	v.summaryLabel = widget.NewLabel("")
+	v.backfillButton = newCapabilityButton(i18n.T("Alt Text Backfill..."), wordpress.ActionUpload, v.confirmMediaBackfill)
+	v.backfillButton.Hide() // Shown by SetWordPressService
+
+	v.container = newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Background Jobs:")), // Top
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			v.detailLabel,
+			container.NewHBox(v.cancelButton, v.retryButton, v.backfillButton, layout.NewSpacer(), v.summaryLabel, v.clearButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.jobList,
+	)
+	v.reload()
+}
+
+// Refresh schedules a reload of the job list. Safe to call from any goroutine.
+func (v *ActivityView) Refresh() {
+	runOnUI(v.reload)
+}
+
+// reload reloads the job list from the queue
+func (v *ActivityView) reload() {
+	v.jobs = v.queue.Jobs()
+	v.jobList.Refresh()
+	v.summaryLabel.SetText(i18n.Tf("%d active, %d total", v.queue.ActiveCount(), len(v.jobs)))
+	v.updateDetails()
+}
+
+// updateDetails shows the selected job's details and enables the applicable buttons
+func (v *ActivityView) updateDetails() {
+	job, ok := v.queue.Get(v.selectedJobID)
+	if !ok {
+		v.detailLabel.SetText(i18n.T("Select a job to see its details."))
+		v.cancelButton.Disable()
+		v.retryButton.Disable()
+		return
+	}
+
+	details := i18n.Tf("Job #%d (%s): %s\nStatus: %s", job.ID, i18n.T(job.Kind), job.Title, i18n.T(string(job.Status)))
+	details += "\n" + i18n.Tf("Queued: %s", job.Created.Format("15:04:05"))
+	if !job.Started.IsZero() {
+		details += "    " + i18n.Tf("Run time: %s", job.Duration().Round(time.Second))
+	}
+	if job.Message != "" {
+		details += "\n" + job.Message
+	}
+	v.detailLabel.SetText(details)
+
+	if job.Status.IsFinished() {
+		v.cancelButton.Disable()
+		v.retryButton.Enable()
+	} else {
+		v.cancelButton.Enable()
+		v.retryButton.Disable()
+	}
+}
+
+// FocusRegions returns the job list for Ctrl+F6 focus cycling
+func (v *ActivityView) FocusRegions() []fyne.Focusable {
+	return []fyne.Focusable{v.jobList}
+}
+
+// Container returns the container for the Activity view
+func (v *ActivityView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,13 @@
+package ui
+
+import "Inference_Engine/settings"
+
+// Settings of the main window.
+var (
+	// PrefLastTab is the index of the main tab last open, selected at
+	// startup; the Settings tab until another one is opened.
+	PrefLastTab = settings.NewKey("app.last_tab", 2)
+	// PrefWindowWidth and PrefWindowHeight are the main window's size.
+	PrefWindowWidth  = settings.NewKey("app.window_width", 1164.0)
+	PrefWindowHeight = settings.NewKey("app.window_height", 800.0)
+)
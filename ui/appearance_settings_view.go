@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// AppearanceSettingsView represents the appearance (theme, scale, language) settings view
+type AppearanceSettingsView struct {
+	container *fyne.Container
+	app       fyne.App
+	window    fyne.Window
+
+	// UI elements
+	themeSelect     *widget.Select
+	fontScaleSelect *widget.Select
+	uiScaleSelect   *widget.Select
+	languageSelect  *widget.Select
+	trayCheck       *widget.Check
+}
+
+// NewAppearanceSettingsView creates a new appearance settings view
+func NewAppearanceSettingsView(app fyne.App, window fyne.Window) *AppearanceSettingsView {
+	view := &AppearanceSettingsView{
+		app:    app,
+		window: window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the appearance settings view
+func (v *AppearanceSettingsView) initialize() {
+	v.themeSelect = widget.NewSelect(ThemeNames, func(selected string) {
+		logger.Info("Theme selected", "theme", selected)
+		ApplyTheme(v.app, selected)
+	})
+	// Set the current value without re-applying it
+	v.themeSelect.Selected = SavedThemeName()
+
+	fontScale, uiScale := SavedScales()
+	v.fontScaleSelect = widget.NewSelect(ScaleOptions, func(string) { v.applyScale() })
+	v.fontScaleSelect.Selected = ScaleLabel(fontScale)
+	v.uiScaleSelect = widget.NewSelect(ScaleOptions, func(string) { v.applyScale() })
+	v.uiScaleSelect.Selected = ScaleLabel(uiScale)
+
+	var languageNames []string
+	for _, l := range i18n.Languages {
+		languageNames = append(languageNames, l.Name)
+	}
+	v.languageSelect = widget.NewSelect(languageNames, func(selected string) {
+		code := i18n.LanguageCode(selected)
+		logger.Info("Language selected", "language", code)
+		SaveLanguage(code)
+		dialog.ShowInformation(i18n.T("Language Changed"), i18n.Tf("Restart the application to show the interface in %s.", selected), v.window)
+	})
+	v.languageSelect.Selected = i18n.LanguageName(SavedLanguage())
+
+	v.trayCheck = widget.NewCheck(i18n.T("Keep running in the system tray when the window is closed"), func(checked bool) {
+		logger.Info("Minimize to tray changed", "enabled", checked)
+		SetMinimizeToTray(checked)
+	})
+	v.trayCheck.Checked = MinimizeToTray()
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Appearance")),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Theme:"), v.themeSelect),
+			widget.NewFormItem(i18n.T("Font Size:"), v.fontScaleSelect),
+			widget.NewFormItem(i18n.T("UI Scale:"), v.uiScaleSelect),
+			widget.NewFormItem(i18n.T("Language:"), v.languageSelect),
+		),
+		v.trayCheck,
+	)
+}
+
+// Container returns the container for the Appearance Settings view
+func (v *AppearanceSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// applyScale persists the selected font and UI scale and re-applies the theme
+func (v *AppearanceSettingsView) applyScale() {
+	fontScale := ParseScaleLabel(v.fontScaleSelect.Selected)
+	uiScale := ParseScaleLabel(v.uiScaleSelect.Selected)
+	logger.Info("Scale selected", "font_scale", fontScale, "ui_scale", uiScale)
+	ApplyScale(v.app, fontScale, uiScale)
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"sync"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// CapabilityButton is a button for an action on the site that the connected
+// account may lack the WordPress capabilities for. While it does, the button
+// stays disabled whatever the view asks, and hovering it explains why.
+type CapabilityButton struct {
+	widget.Button
+
+	action  wordpress.Action
+	wanted  bool   // Whether the view has it enabled
+	denied  string // Why the account can't do the action; empty if it can
+	tooltip *widget.PopUp
+}
+
+// capabilityButtons are the buttons ApplyCapabilities updates.
+var capabilityButtons struct {
+	sync.Mutex
+	buttons []*CapabilityButton
+}
+
+// newCapabilityButton creates an enabled button for action, which
+// ApplyCapabilities keeps up to date with the connected account.
+func newCapabilityButton(label string, action wordpress.Action, tapped func()) *CapabilityButton {
+	b := &CapabilityButton{action: action, wanted: true}
+	b.Text = label
+	b.OnTapped = tapped
+	b.ExtendBaseWidget(b)
+	capabilityButtons.Lock()
+	capabilityButtons.buttons = append(capabilityButtons.buttons, b)
+	capabilityButtons.Unlock()
+	return b
+}
+
+// ApplyCapabilities disables the buttons for the actions caps doesn't
+// allow, and enables the others the views want enabled. Call it on the UI
+// goroutine whenever the site connection changes.
+func ApplyCapabilities(caps wordpress.Capabilities) {
+	capabilityButtons.Lock()
+	buttons := append([]*CapabilityButton(nil), capabilityButtons.buttons...)
+	capabilityButtons.Unlock()
+	for _, b := range buttons {
+		b.denied = ""
+		if err := caps.Check(b.action); err != nil {
+			b.denied = err.Error()
+		}
+		b.apply()
+	}
+}
+
+// apply enables the button if the view wants it and the account may use it.
+func (b *CapabilityButton) apply() {
+	if b.wanted && b.denied == "" {
+		b.Button.Enable()
+	} else {
+		b.Button.Disable()
+	}
+}
+
+// Enable enables the button, unless the account lacks the capabilities.
+func (b *CapabilityButton) Enable() {
+	b.wanted = true
+	b.apply()
+}
+
+// Disable disables the button.
+func (b *CapabilityButton) Disable() {
+	b.wanted = false
+	b.apply()
+}
+
+// MouseIn shows why the button is disabled, if the account is why.
+func (b *CapabilityButton) MouseIn(e *desktop.MouseEvent) {
+	b.Button.MouseIn(e)
+	c := fyne.CurrentApp().Driver().CanvasForObject(b)
+	if b.denied == "" || c == nil {
+		return
+	}
+	b.tooltip = widget.NewPopUp(widget.NewLabel(i18n.Tf("Not available: %s", b.denied)), c)
+	position := fyne.CurrentApp().Driver().AbsolutePositionForObject(b)
+	b.tooltip.ShowAtPosition(position.Add(fyne.NewPos(0, b.Size().Height+4))) // Below, so it doesn't take the hover
+}
+
+// MouseOut hides the explanation.
+func (b *CapabilityButton) MouseOut() {
+	b.Button.MouseOut()
+	if b.tooltip != nil {
+		b.tooltip.Hide()
+		b.tooltip = nil
+	}
+}
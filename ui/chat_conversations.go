@@ -0,0 +1,386 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/transcript"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newConversationPanel builds the saved conversation picker, the list of the
+// conversation's messages and the per-message actions.
+func (v *InferenceChatView) newConversationPanel() fyne.CanvasObject {
+	v.conversationSelect = widget.NewSelect(nil, func(string) {
+		index := v.conversationSelect.SelectedIndex()
+		if index >= 0 && index < len(v.conversations) && v.conversations[index].ID != v.conversation.ID {
+			v.showConversation(v.conversations[index])
+		}
+	})
+	v.conversationSelect.PlaceHolder = i18n.T("New conversation")
+	newButton := widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() { v.showConversation(transcript.Saved{}) })
+	deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), v.deleteConversation)
+
+	v.turnList = widget.NewList(
+		func() int { return len(v.conversation.Turns) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			turn := v.conversation.Turns[id]
+			text := fmt.Sprintf("%d. %s", id+1, strings.Join(strings.Fields(turn.Prompt), " "))
+			if turn.Error != "" {
+				text += " " + i18n.T("(failed)")
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	v.turnList.OnSelected = v.selectTurn
+	v.turnList.OnUnselected = func(widget.ListItemID) { v.selected = -1; v.updateActions() }
+
+	v.regenerateButton = widget.NewButtonWithIcon(i18n.T("Regenerate"), theme.ViewRefreshIcon(), v.regenerate)
+	v.editButton = widget.NewButtonWithIcon(i18n.T("Edit & Resend"), theme.DocumentCreateIcon(), v.editSelected)
+	v.branchButton = widget.NewButtonWithIcon(i18n.T("Branch"), theme.ContentCopyIcon(), v.branchSelected)
+	v.updateActions()
+	v.refreshConversations()
+
+	return newReadingOrderBorder(
+		container.NewVBox(
+			widget.NewLabel(i18n.T("Conversations:")),
+			newReadingOrderBorder(nil, nil, nil, container.NewHBox(newButton, deleteButton), v.conversationSelect),
+		),
+		container.NewVBox(
+			container.NewGridWithColumns(3, v.regenerateButton, v.editButton, v.branchButton),
+			container.NewGridWithColumns(3,
+				widget.NewButton(i18n.T("Export Transcript"), v.exportTranscript),
+				widget.NewButton(i18n.T("Export JSON"), v.exportJSON),
+				widget.NewButton(i18n.T("Import Conversation"), v.importConversation)),
+		),
+		nil,
+		nil,
+		v.turnList,
+	)
+}
+
+// SetConversationStore keeps the chat conversations in store and shows the
+// most recently updated one
+func (v *InferenceChatView) SetConversationStore(store *transcript.Store) {
+	v.store = store
+	v.conversations = store.List()
+	if len(v.conversations) > 0 {
+		v.showConversation(v.conversations[0])
+		return
+	}
+	v.refreshConversations()
+}
+
+// showConversation makes c the conversation shown and continued
+func (v *InferenceChatView) showConversation(c transcript.Saved) {
+	v.conversation = c
+	v.memorySynced = false
+	v.editing = -1
+	v.sendButton.SetText(i18n.T("Send Message"))
+	v.turnList.UnselectAll()
+	v.selected = -1
+	v.turnList.Refresh()
+	v.responseOutput.SetText("")
+	v.usageLabel.SetText("")
+	if len(c.Turns) > 0 {
+		v.turnList.Select(len(c.Turns) - 1)
+		v.turnList.ScrollToBottom()
+	}
+	v.refreshConversations()
+	v.updateActions()
+}
+
+// addTurn replaces the turns of the conversation with base followed by turn,
+// saves it and shows the new turn
+func (v *InferenceChatView) addTurn(base []transcript.Turn, turn transcript.Turn) {
+	v.conversation.Turns = append(base, turn)
+	if v.editing >= 0 {
+		v.editing = -1
+		v.sendButton.SetText(i18n.T("Send Message"))
+	}
+	v.saveConversation()
+	v.turnList.Refresh()
+	v.turnList.Select(len(v.conversation.Turns) - 1)
+	v.turnList.ScrollToBottom()
+}
+
+// saveConversation stores the conversation shown and moves it to the top of
+// the picker
+func (v *InferenceChatView) saveConversation() {
+	if v.store != nil {
+		if err := v.store.Save(&v.conversation); err != nil {
+			logger.Error("Chat: failed to save conversation", "error", err)
+		}
+	}
+	if v.conversation.ID == 0 { // Not stored; kept for this session only
+		v.lastID--
+		v.conversation.ID = v.lastID
+	}
+	if v.conversation.Title == "" {
+		v.conversation.Title = transcript.Title(v.conversation.Turns)
+	}
+	v.conversation.Updated = time.Now()
+	conversations := []transcript.Saved{v.conversation}
+	for _, c := range v.conversations {
+		if c.ID != v.conversation.ID {
+			conversations = append(conversations, c)
+		}
+	}
+	v.conversations = conversations
+	v.refreshConversations()
+}
+
+// refreshConversations lists the conversations in the picker and selects the
+// one shown
+func (v *InferenceChatView) refreshConversations() {
+	options := make([]string, len(v.conversations))
+	selected := -1
+	for i, c := range v.conversations {
+		options[i] = fmt.Sprintf("%s (%s)", c.Title, c.Updated.Format("Jan 2 15:04"))
+		if c.ID == v.conversation.ID {
+			selected = i
+		}
+	}
+	v.conversationSelect.SetOptions(options)
+	if selected >= 0 {
+		v.conversationSelect.SetSelectedIndex(selected)
+	} else {
+		v.conversationSelect.ClearSelected()
+	}
+}
+
+// selectTurn shows a turn's response
+func (v *InferenceChatView) selectTurn(id widget.ListItemID) {
+	if id < 0 || id >= len(v.conversation.Turns) {
+		return
+	}
+	v.selected = id
+	turn := v.conversation.Turns[id]
+	if turn.Error != "" {
+		v.responseOutput.SetText(i18n.Tf("ERROR:\n%v", turn.Error))
+	} else {
+		v.responseOutput.SetText(turn.Response)
+	}
+	v.usageLabel.SetText("")
+	if turn.PromptTokens+turn.CompletionTokens > 0 {
+		v.usageLabel.SetText(tokenUsageSummary(inference.TokenUsage{PromptTokens: turn.PromptTokens, CompletionTokens: turn.CompletionTokens}))
+	}
+	v.updateActions()
+}
+
+// updateActions enables the message actions that apply
+func (v *InferenceChatView) updateActions() {
+	for button, enabled := range map[*widget.Button]bool{
+		v.regenerateButton: len(v.conversation.Turns) > 0,
+		v.editButton:       v.selected >= 0,
+		v.branchButton:     v.selected >= 0,
+		v.generatorButton:  len(conversationMessages(v.conversation.Turns)) > 0,
+	} {
+		if enabled {
+			button.Enable()
+		} else {
+			button.Disable()
+		}
+	}
+}
+
+// regenerate sends the last message again, replacing its response
+func (v *InferenceChatView) regenerate() {
+	n := len(v.conversation.Turns)
+	if n == 0 {
+		return
+	}
+	v.send(v.conversation.Turns[n-1].Prompt, n-1)
+}
+
+// editSelected puts the selected message in the input; sending it replaces
+// that message and every later one
+func (v *InferenceChatView) editSelected() {
+	if v.selected < 0 {
+		return
+	}
+	v.editing = v.selected
+	v.promptInput.ReplaceText(v.conversation.Turns[v.selected].Prompt)
+	v.sendButton.SetText(i18n.Tf("Resend as Message %d", v.selected+1))
+	v.window.Canvas().Focus(v.promptInput)
+}
+
+// branchSelected starts a new conversation with the messages up to the
+// selected one, keeping the current conversation as it is
+func (v *InferenceChatView) branchSelected() {
+	if v.selected < 0 {
+		return
+	}
+	branch := v.conversation.Branch(v.selected + 1)
+	v.showConversation(branch)
+	v.saveConversation()
+}
+
+// deleteConversation removes the conversation shown, after confirmation
+func (v *InferenceChatView) deleteConversation() {
+	if v.conversation.ID == 0 {
+		v.showConversation(transcript.Saved{})
+		return
+	}
+	dialog.ShowConfirm(i18n.T("Delete Conversation"), i18n.Tf("Delete the conversation '%s'?", v.conversation.Title), func(ok bool) {
+		if !ok {
+			return
+		}
+		if v.store != nil && v.conversation.ID > 0 {
+			if err := v.store.Delete(v.conversation.ID); err != nil {
+				ShowError(err, v.window)
+				return
+			}
+		}
+		var conversations []transcript.Saved
+		for _, c := range v.conversations {
+			if c.ID != v.conversation.ID {
+				conversations = append(conversations, c)
+			}
+		}
+		v.conversations = conversations
+		v.showConversation(transcript.Saved{})
+	}, v.window)
+}
+
+// conversationMessages returns the exchanges of turns as conversation
+// history, leaving out failed requests
+func conversationMessages(turns []transcript.Turn) []inference.ConversationMessage {
+	var messages []inference.ConversationMessage
+	for _, turn := range turns {
+		if turn.Error == "" {
+			messages = append(messages,
+				inference.ConversationMessage{Role: "user", Content: turn.Prompt},
+				inference.ConversationMessage{Role: "assistant", Content: turn.Response})
+		}
+	}
+	return messages
+}
+
+// exportTranscript saves the conversation's exchanges to a timestamped Markdown file
+func (v *InferenceChatView) exportTranscript() {
+	if len(v.conversation.Turns) == 0 {
+		dialog.ShowInformation(i18n.T("Export Transcript"), i18n.T("There are no chat messages to export yet."), v.window)
+		return
+	}
+	exportTextToFile(v.window, "Chat transcript", "chat-transcript", "md", transcript.Markdown(v.conversation.Turns, time.Now()))
+}
+
+// exportJSON saves the conversation's exchanges to a JSON file that Import
+// Conversation reads back
+func (v *InferenceChatView) exportJSON() {
+	if len(v.conversation.Turns) == 0 {
+		dialog.ShowInformation(i18n.T("Export JSON"), i18n.T("There are no chat messages to export yet."), v.window)
+		return
+	}
+	data, err := transcript.JSON(v.conversation.Turns, time.Now())
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	exportTextToFile(v.window, "Chat conversation", "chat-conversation", "json", string(data))
+}
+
+// importConversation loads a conversation exported as JSON or Markdown as a
+// new conversation, to continue it where it left off
+func (v *InferenceChatView) importConversation() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			ShowError(fmt.Errorf("failed to read conversation: %w", err), v.window)
+			return
+		}
+		turns, err := transcript.Parse(data)
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		v.showConversation(transcript.Saved{Turns: turns})
+		v.saveConversation()
+		dialog.ShowInformation(i18n.T("Import Conversation"), i18n.Tf("Imported %d messages. Your next message continues the conversation.", len(turns)), v.window)
+		logger.Info("Chat: imported conversation", "turns", len(turns))
+	}, v.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json", ".md"}))
+	open.Show()
+}
+
+// sendToGenerator adds the response shown, or the whole conversation, to the
+// content generator as a source or as its request
+func (v *InferenceChatView) sendToGenerator() {
+	if v.generatorView == nil {
+		return
+	}
+	response := ""
+	if v.selected >= 0 && v.conversation.Turns[v.selected].Error == "" {
+		response = v.conversation.Turns[v.selected].Response
+	}
+	whatOptions := []string{i18n.T("This response"), i18n.T("Whole conversation")}
+	what := widget.NewRadioGroup(whatOptions, nil)
+	what.Required = true
+	what.SetSelected(whatOptions[0])
+	if response == "" {
+		what.SetSelected(whatOptions[1])
+		what.Disable()
+	}
+	asOptions := []string{i18n.T("Source content"), i18n.T("Generation request")}
+	as := widget.NewRadioGroup(asOptions, nil)
+	as.Required = true
+	as.SetSelected(asOptions[0])
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Send:"), what),
+		widget.NewFormItem(i18n.T("As:"), as),
+	}
+	dialog.ShowForm(i18n.T("Send to Generator"), i18n.T("Send"), i18n.T("Cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		title := v.conversation.Title
+		if title == "" {
+			title = transcript.Title(v.conversation.Turns)
+		}
+		text := response
+		if what.Selected == whatOptions[1] {
+			var turns []transcript.Turn
+			for _, turn := range v.conversation.Turns {
+				if turn.Error == "" {
+					turns = append(turns, turn)
+				}
+			}
+			text = transcript.Markdown(turns, time.Now())
+		} else {
+			title = i18n.Tf("%s (message %d)", title, v.selected+1)
+		}
+		if as.Selected == asOptions[1] {
+			v.generatorView.SetPrompt(text)
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.T("The chat text is the content generator's request."), v.window)
+		} else {
+			v.generatorView.AddSourceContent(title, text, "Chat", "", 0, false)
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.Tf("Added '%s' to the content generator's sources.", title), v.window)
+		}
+		logger.Info("Chat: sent to content generator", "conversation", what.Selected == whatOptions[1], "as_prompt", as.Selected == asOptions[1])
+	}, v.window)
+}
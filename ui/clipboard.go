@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// copyToClipboard puts text on the window's clipboard.
+func copyToClipboard(window fyne.Window, text string) {
+	window.Clipboard().SetContent(text)
+	logger.Info("Copied text to clipboard", "chars", len(text))
+}
+
+// newCopyButton creates a button that copies the text returned by getText.
+// An empty label gives an icon-only button.
+func newCopyButton(window fyne.Window, label string, getText func() string) *widget.Button {
+	return widget.NewButtonWithIcon(label, theme.ContentCopyIcon(), func() {
+		if text := getText(); text != "" {
+			copyToClipboard(window, text)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// digestCommentLimit is how many of the newest comments are summarized.
+const digestCommentLimit = 100
+
+// maxDigestCommentLength cuts long comments (in bytes), so a few essays don't crowd
+// out the others.
+const maxDigestCommentLength = 1500
+
+// digestComments summarizes the recent comments on the selected page in the
+// background and offers to update the page to answer them.
+func (v *ContentManagerView) digestComments() {
+	page := v.GetPageByID(v.selectedPageID)
+	if page == nil {
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+	pageID, title := page.ID, page.Title
+
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		report(0, i18n.T("Fetching comments"))
+		comments, err := v.wpService.GetRecentComments(pageID, digestCommentLimit)
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to fetch comments: %w", err), v.window) })
+			return err
+		}
+		if len(comments) == 0 {
+			runOnUI(func() {
+				dialog.ShowInformation(i18n.T("Comment Digest"), i18n.Tf("'%s' has no approved comments.", title), v.window)
+			})
+			return nil
+		}
+		var b strings.Builder
+		for _, comment := range comments {
+			text := truncateUTF8(strings.TrimSpace(utils.HTMLToMarkdown(comment.Content)), maxDigestCommentLength)
+			fmt.Fprintf(&b, "%s (%s): %s\n\n", comment.Author, comment.Date.Format("2006-01-02"), text)
+		}
+		report(0.2, i18n.Tf("Summarizing %d comments", len(comments)))
+		output, err := v.inferenceService.Generate(inference.GetCommentDigestPrompt(title, b.String()), generateOptions(ctx, "", inference.TaskSummarization))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to summarize comments: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showCommentDigest(pageID, title, len(comments), strings.TrimSpace(output)) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentManagerView.digestComments", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Comment Digest", title, run)
+}
+
+// showCommentDigest shows the digest, editable, and updates the page to
+// answer it on request.
+func (v *ContentManagerView) showCommentDigest(pageID int, title string, count int, digest string) {
+	editor := widget.NewMultiLineEntry()
+	editor.Wrapping = fyne.TextWrapWord
+	editor.SetText(digest)
+	label := widget.NewLabel(i18n.Tf("What readers say in the %d newest comments. Edit it, then update the page to answer it.", count))
+	label.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.Tf("Comment Digest: %s", title), newReadingOrderBorder(label, nil, nil, nil, editor), v.window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		newCopyButton(v.window, i18n.T("Copy"), func() string { return editor.Text }),
+		widget.NewButton(i18n.T("Update Page"), func() {
+			feedback := strings.TrimSpace(editor.Text)
+			if v.selectedPageID != pageID {
+				ShowError(fmt.Errorf("select '%s' again to update it", title), v.window)
+				return
+			}
+			d.Hide()
+			v.runPageAction(pageAction{Name: "Update from Comments", Prompt: func(content string) string {
+				return inference.WithReaderFeedback(inference.GetWordPressContentImprovePrompt(content), feedback)
+			}})
+		}),
+	})
+	d.Resize(fyne.NewSize(680, 520))
+	d.Show()
+}
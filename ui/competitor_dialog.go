@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"Inference_Engine/competitor"
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// compareWithCompetitor asks for a competitor's URL, then in the background
+// fetches that page, has the model list what the site's page is missing
+// compared with it, and drafts the page improved to cover those gaps.
+func compareWithCompetitor(window fyne.Window, wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, generatorView *ContentGeneratorView, page wordpress.Page) {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://")
+
+	dialog.ShowForm(i18n.T("Compare with Competitor"), i18n.T("Analyze"), i18n.T("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Competitor URL:"), urlEntry),
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		competitorURL := strings.TrimSpace(urlEntry.Text)
+		run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+			progress(-1, i18n.T("Fetching the competitor's page"))
+			rival, err := competitor.Fetch(ctx, competitorURL)
+			var content string
+			if err == nil {
+				progress(-1, i18n.Tf("Fetching %s", page.Title))
+				content, err = wpService.GetPageContent(page.ID)
+			}
+			var analysis competitor.Analysis
+			if err == nil {
+				progress(-1, i18n.T("Comparing"))
+				var output string
+				output, err = inferenceService.Generate(inference.GetCompetitorGapPrompt(page.Title, utils.HTMLToMarkdown(content), rival.URL, rival.Content), inference.GenerateOptions{Context: ctx, Task: inference.TaskStructured})
+				if err == nil {
+					analysis, err = competitor.ParseAnalysis(output)
+				}
+			}
+			var draft string
+			if err == nil && analysis.Gaps() != "" {
+				progress(-1, i18n.T("Drafting the improved page"))
+				draft, err = inferenceService.Generate(inference.GetCompetitorDraftPrompt(analysis.Gaps(), content), contentOptions(ctx, ""))
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("competitor analysis failed: %w", err), window) })
+				return err
+			}
+			page.Content = content
+			runOnUI(func() { showCompetitorAnalysis(window, generatorView, page, rival, analysis, strings.TrimSpace(draft)) })
+			return nil
+		}
+		if jobQueue == nil {
+			crash.Go("compareWithCompetitor", func() { run(context.Background(), func(float64, string) {}) })
+			return
+		}
+		host := competitorURL
+		if u, err := url.Parse(competitorURL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		jobQueue.Submit("Competitor Analysis", fmt.Sprintf("%s vs %s", page.Title, host), run)
+	}, window)
+}
+
+// showCompetitorAnalysis shows the gap analysis and the improvement draft,
+// which can be edited, copied, exported or opened in the generator to save
+// over the page.
+func showCompetitorAnalysis(window fyne.Window, generatorView *ContentGeneratorView, page wordpress.Page, rival competitor.Page, analysis competitor.Analysis, draft string) {
+	report := widget.NewRichTextFromMarkdown(analysis.Markdown())
+	report.Wrapping = fyne.TextWrapWord
+	editor := NewEditorEntry()
+	editor.SetText(draft)
+	editor.SetPlaceHolder(i18n.T("The competitor covers nothing your page is missing, so no draft was written."))
+	tabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Gap Analysis"), container.NewVScroll(report)),
+		container.NewTabItem(i18n.T("Improvement Draft"), editor),
+	)
+	header := widget.NewLabel(i18n.Tf("%s compared with %s", page.Title, rival.URL))
+	header.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Competitor Analysis"), newReadingOrderBorder(header, nil, nil, nil, tabs), window)
+	buttons := []fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		widget.NewButton(i18n.T("Export Analysis"), func() {
+			exportTextToFile(window, i18n.T("Export Analysis"), "competitor-analysis", "md",
+				fmt.Sprintf("# %s vs %s\n\n%s\n", page.Title, rival.URL, analysis.Markdown()))
+		}),
+		newCopyButton(window, i18n.T("Copy Draft"), func() string { return editor.Text }),
+	}
+	if generatorView != nil {
+		buttons = append(buttons, widget.NewButton(i18n.T("Open in Generator"), func() {
+			if strings.TrimSpace(editor.Text) == "" {
+				ShowError(fmt.Errorf("there is no draft to open"), window)
+				return
+			}
+			generatorView.AddSourceContent(page.Title, page.Content, "WordPress", page.Link, page.ID, false)
+			generatorView.OpenResult(fmt.Sprintf("Improve '%s' to cover what %s covers:\n%s", page.Title, rival.URL, analysis.Gaps()), editor.Text)
+			d.Hide()
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.T("The page and the improvement draft are in the content generator."), window)
+		}))
+	}
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(780, 640))
+	d.Show()
+}
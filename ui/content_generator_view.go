@@ -1,25 +1,49 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
+	"Inference_Engine/brief"
+	"Inference_Engine/crash"
+	"Inference_Engine/editorial"
+	"Inference_Engine/embeddings"
+	"Inference_Engine/facts"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/logging"
+	"Inference_Engine/repurpose"
+	"Inference_Engine/searchconsole"
+	"Inference_Engine/seo"
+	"Inference_Engine/settings"
 	"Inference_Engine/utils"
 	"Inference_Engine/wordpress"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
+var (
+	// PrefCitationStyle is the setting for the generator's citation style.
+	PrefCitationStyle = settings.NewKey("generator.citation_style", string(editorial.CitationsNone))
+	// PrefInsertTOC is the setting for adding a table of contents when
+	// saving to WordPress.
+	PrefInsertTOC = settings.NewKey("generator.insert_toc", false)
+)
+
 // ContentGeneratorView represents the content generator view
 type ContentGeneratorView struct {
 	container        *container.Split
@@ -30,20 +54,55 @@ type ContentGeneratorView struct {
 	// Source content UI elements
 	sourceList         *widget.List
 	addSourceButton    *widget.Button
-	removeSourceButton *widget.Button
+	removeSourceButton *widget.Button // Removes every ticked source
+	markSampleButton   *widget.Button // Marks the ticked sources as Sample
+	markTrueButton     *widget.Button // Marks the ticked sources as True sources
+	selectAllCheck     *widget.Check
+	selectionLabel     *widget.Label
+	buildVoiceButton   *widget.Button // Builds the brand voice profile from the Sample sources
+	interviewButton    *widget.Button // Turns the ticked interview transcript into an article
 
 	// Generation UI elements
-	promptEntry      *widget.Entry
-	instructionEntry *widget.Entry
+	promptEntry      *EditorEntry
+	instructionEntry *EditorEntry
 	selectedModel    *widget.Select
+	modelsListed     bool // Whether selectedModel lists models, not a placeholder
 	generateButton   *widget.Button
-	resultOutput     *widget.Entry
-	saveToFileButton *widget.Button
-	saveToWPButton   *widget.Button
+	useVoiceCheck    *widget.Check // Send the voice profile instead of the Sample sources
+	voiceLabel       *widget.Label
+	useFactsCheck    *widget.Check // Send the fact sheet with the generation
+	factsLabel       *widget.Label
+	viewFactsButton  *widget.Button
+	citationSelect   *widget.Select // How generated content credits the True sources
+	keywordEntry     *widget.Entry  // SEO targets, checked after generation
+	relatedEntry     *widget.Entry
+	wordCountEntry   *widget.Entry
+	searchConsoleButton *widget.Button // Fills the SEO targets from Search Console; hidden without it
+	seoPassCheck        *widget.Check  // Send the SEO targets and check the result against them
+	presetLabel         *widget.Label  // The preset in use
+	presetBox           *fyne.Container
+	activePreset        string                     // Name of the preset in use; "" for none
+	presetParams        inference.GenerationParams // Sampling settings of the preset in use
+	chunkCheck          *widget.Check  // Condense True sources too large for the model
+	chunkModeSelect     *widget.Select
+	chunkStrategySelect *widget.Select
+	chunkMapModelSelect *widget.Select // Model for the per-chunk notes; the first option is the generation model
+	summarizeSamplesCheck *widget.Check // Send a style description instead of long Sample sources
+	sampleStyles          map[string]string // Style descriptions by SHA-256 of the samples; only the running generation uses it
+	resultOutput      *EditorEntry
+	resultRendered    *widget.RichText // Markdown rendering of resultOutput
+	resultPreview     *widget.RichText // Approximate HTML page preview of resultOutput
+	saveToFileButton  *widget.Button
+	saveToWPButton    *CapabilityButton
+	publishPostButton *CapabilityButton // Creates a post from the result, with suggested categories and tags
+
+	// Live word/char/token counts shown under the editors
+	promptCount      *widget.Label
+	instructionCount *widget.Label
+	resultCount      *widget.Label
 
 	// Data
-	sourceContents      []SourceContent
-	selectedSourceIndex int
+	sourceContents []SourceContent
 
 	// Generation state
 	isGenerating        bool
@@ -54,7 +113,22 @@ type ContentGeneratorView struct {
 	customProgressDialog dialog.Dialog
 	generationLogRelay   *utils.LogRelay
 	generationLogDisplay *widget.Label
-	logger               *log.Logger
+	jobQueue             *jobs.Queue // Runs generations in the background; nil runs them directly
+
+	promptHistory      *PromptHistory // Recent prompts and instructions, offered in history menus
+	instructionHistory *PromptHistory
+	drafts             *history.Store // Every generated draft, browsable and restorable; nil disables it
+	currentDraftID     int            // Draft the result was generated or restored from; 0 for none
+	styleGuide         *editorial.StyleGuideStore // Checked after every generation; nil disables it
+	voiceProfiles      *editorial.VoiceProfileStore // Brand voice built from Sample sources; nil disables it
+	glossaries         *editorial.GlossaryStore     // Per-site terms, checked with the style guide; nil disables them
+	siteInstructions   *editorial.SiteInstructionsStore // Per-site audience, tone and disclaimers; nil disables them
+	disclaimers        *editorial.DisclaimerStore       // Compliance disclaimers added on save to WordPress; nil disables them
+	relatedPosts       *editorial.RelatedPostsStore     // Per-site "Further reading" settings; nil disables the block
+	embeddingIndex     *embeddings.Store                // Finds the pages related to the one saved to
+	searchConsole      *searchconsole.Client        // Queries the sources already rank for; nil disables it
+	faq                *seo.FAQ                     // Appended to the page on the next save to WordPress; nil for none
+	factSheet          *facts.Sheet                 // Quotes, statistics and names of the True sources; nil until extracted
 }
 
 // SourceContent represents a source content item
@@ -63,7 +137,9 @@ type SourceContent struct {
 	Content string
 	Source  string // "WordPress", "File", etc.
 	ID      int    // WordPress page ID or other identifier
+	Link    string // Page URL or file URI, used for citations
 	IsSample bool
+	Selected bool // Ticked in the source list for batch remove/mark actions
 }
 
 // NewContentGeneratorView creates a new content generator view
@@ -72,14 +148,15 @@ func NewContentGeneratorView(wpService *wordpress.WordPressService, inferenceSer
 		wpService:           wpService,
 		inferenceService:    inferenceService,
 		window:              window,
-		sourceContents:      []SourceContent{},
-		selectedSourceIndex: -1,
-		isGenerating:        false,
-		logger:              log.New(os.Stderr, "ContentGeneratorView: ", log.LstdFlags|log.Lshortfile),
+		sourceContents:   []SourceContent{},
+		isGenerating:     false,
 	}
 	view.initialize()
 	view.refreshAvailableModels() // Initial population of models
-	
+	if inferenceService != nil {
+		inferenceService.OnModelsChange(func() { runOnUI(view.refreshAvailableModels) })
+	}
+
 	return view
 }
 
@@ -91,28 +168,42 @@ func (v *ContentGeneratorView) initialize() {
 			return len(v.sourceContents)
 		},
 		func() fyne.CanvasObject {
-			check := widget.NewCheck("Sample", nil) // Checkbox for "Is Sample?"
+			selectCheck := widget.NewCheck("", nil)         // Ticks the source for batch actions
+			check := widget.NewCheck(i18n.T("Sample"), nil) // Checkbox for "Is Sample?"
 			label := widget.NewLabel("Template Source")
 			// Use HBox for layout. Spacer pushes label left if needed, or just box them.
 			// Add padding or adjust layout as needed for aesthetics.
-			return container.NewHBox(check, label)
+			return container.NewHBox(selectCheck, check, label)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
 			if id < len(v.sourceContents) {
 				hbox := obj.(*fyne.Container)
-				check := hbox.Objects[0].(*widget.Check)
-				label := hbox.Objects[1].(*widget.Label)
-	
+				selectCheck := hbox.Objects[0].(*widget.Check)
+				check := hbox.Objects[1].(*widget.Check)
+				label := hbox.Objects[2].(*widget.Label)
+
+				// Detach the handlers first: the row may have shown another
+				// source, and SetChecked would report the change to it.
+				selectCheck.OnChanged = nil
+				check.OnChanged = nil
 				label.SetText(v.sourceContents[id].Title)
+				selectCheck.SetChecked(v.sourceContents[id].Selected)
 				check.SetChecked(v.sourceContents[id].IsSample)
-	
+
+				selectCheck.OnChanged = func(checked bool) {
+					if id < len(v.sourceContents) {
+						v.sourceContents[id].Selected = checked
+						v.updateSourceActions()
+					}
+				}
+
 				// --- Handle Checkbox Changes ---
 				// Use OnChanged within UpdateItem to capture the correct 'id'
 				check.OnChanged = func(checked bool) {
 					// Prevent index out of bounds if list refreshes during interaction
 					if id < len(v.sourceContents) {
 						v.sourceContents[id].IsSample = checked
-						log.Printf("Source '%s' marked as sample: %t", v.sourceContents[id].Title, checked)
+						logger.Info("Source sample flag changed", "source", v.sourceContents[id].Title, "sample", checked)
 						// No list refresh needed here, just update the data model
 					}
 				}
@@ -120,64 +211,148 @@ func (v *ContentGeneratorView) initialize() {
 		},
 	)
 
+	// Activating a row (click, or Space on the focused list) ticks or unticks it
 	v.sourceList.OnSelected = func(id widget.ListItemID) {
-		v.selectedSourceIndex = id
-		v.removeSourceButton.Enable()
+		v.sourceList.UnselectAll()
+		if id < len(v.sourceContents) {
+			v.sourceContents[id].Selected = !v.sourceContents[id].Selected
+			v.sourceList.RefreshItem(id)
+			v.updateSourceActions()
+		}
 	}
 
-	v.addSourceButton = widget.NewButton("Add Source", func() {
+	v.addSourceButton = widget.NewButton(i18n.T("Add Source"), func() {
 		v.showAddSourceDialog()
 	})
 
-	v.removeSourceButton = widget.NewButton("Remove Source", func() {
-		v.removeSourceContent()
+	v.removeSourceButton = widget.NewButton(i18n.T("Remove Selected"), func() {
+		v.removeSelectedSources()
+	})
+	v.markSampleButton = widget.NewButton(i18n.T("Mark Sample"), func() {
+		v.setSelectedSourcesSample(true)
 	})
-	v.removeSourceButton.Disable()
+	v.markTrueButton = widget.NewButton(i18n.T("Mark True"), func() {
+		v.setSelectedSourcesSample(false)
+	})
+	v.selectAllCheck = widget.NewCheck(i18n.T("All"), func(checked bool) {
+		v.setAllSourcesSelected(checked)
+	})
+	v.selectionLabel = widget.NewLabel("")
+	v.buildVoiceButton = widget.NewButton(i18n.T("Build Voice Profile"), func() {
+		v.buildVoiceProfile()
+	})
+	v.interviewButton = widget.NewButton(i18n.T("Interview to Article"), v.interviewFromSource)
+	v.updateSourceActions()
+
+	v.generationLogDisplay = widget.NewLabel("")
+	v.generationLogDisplay.Wrapping = fyne.TextWrapWord
 
 	// Create generation UI elements
-	v.promptEntry = widget.NewMultiLineEntry()
-	v.promptEntry.SetPlaceHolder("Enter a prompt or topic for the AI to generate content about...")
+	v.promptEntry = NewEditorEntry()
+	v.promptEntry.SetPlaceHolder(i18n.T("Enter a prompt or topic for the AI to generate content about..."))
 	v.promptEntry.Wrapping = fyne.TextWrapWord
 	v.promptEntry.SetMinRowsVisible(10) // <--- Add this line
 
-	v.instructionEntry = widget.NewMultiLineEntry()
-	v.instructionEntry.SetPlaceHolder("Enter specific instructions for the AI (optional)...")
+	v.instructionEntry = NewEditorEntry()
+	v.instructionEntry.SetPlaceHolder(i18n.T("Enter specific instructions for the AI (optional)..."))
 	v.instructionEntry.Wrapping = fyne.TextWrapWord
 	v.instructionEntry.SetMinRowsVisible(3)
 
+	v.promptHistory = NewPromptHistory(PrefHistoryGeneratorPrompt)
+	v.instructionHistory = NewPromptHistory(PrefHistoryGeneratorInstruction)
+
+	v.promptCount = newCountLabel()
+	v.promptEntry.OnChanged = func(text string) {
+		v.promptCount.SetText(textStatsSummary(text))
+	}
+	v.instructionCount = newCountLabel()
+	v.instructionEntry.OnChanged = func(text string) {
+		v.instructionCount.SetText(textStatsSummary(text))
+	}
+
 	// Initialize selectedModel with empty options, will be populated by refreshAvailableModels
-	v.selectedModel = widget.NewSelect([]string{"Loading models..."}, func(selected string) {
-		log.Printf("ContentGeneratorView: Model selected: %s", selected)
+	v.selectedModel = widget.NewSelect([]string{i18n.T("Loading models...")}, func(selected string) {
+		logger.Info("ContentGeneratorView: model selected", logging.Model(selected))
 	})
 	v.refreshAvailableModels() // Populate models
 
-	v.generateButton = widget.NewButton("Generate Content", func() {
+	v.generateButton = widget.NewButton(i18n.T("Generate Content"), func() {
 		v.generateContent()
 	})
-
-
-	v.resultOutput = widget.NewMultiLineEntry()
-	v.resultOutput.SetPlaceHolder("Generated content will appear here...")
+	v.useVoiceCheck = widget.NewCheck(i18n.T("Use voice profile instead of Sample sources"), nil)
+	v.voiceLabel = widget.NewLabel("")
+	v.voiceLabel.Wrapping = fyne.TextWrapWord
+	v.refreshVoiceProfile()
+	v.citationSelect = widget.NewSelect(nil, nil)
+	for _, style := range editorial.CitationStyles {
+		v.citationSelect.Options = append(v.citationSelect.Options, i18n.T(string(style)))
+	}
+	v.citationSelect.SetSelectedIndex(0)
+	saved := editorial.CitationStyle(PrefCitationStyle.Get())
+	for i, style := range editorial.CitationStyles {
+		if style == saved {
+			v.citationSelect.SetSelectedIndex(i)
+		}
+	}
+	v.keywordEntry = widget.NewEntry()
+	v.keywordEntry.SetPlaceHolder(i18n.T("Target keyword"))
+	v.relatedEntry = widget.NewEntry()
+	v.relatedEntry.SetPlaceHolder(i18n.T("Related terms, comma-separated"))
+	v.wordCountEntry = widget.NewEntry()
+	v.wordCountEntry.SetPlaceHolder(i18n.T("Target word count"))
+	v.searchConsoleButton = widget.NewButtonWithIcon(i18n.T("From Search Console"), theme.SearchIcon(), v.targetsFromSearchConsole)
+	v.searchConsoleButton.Hide()
+	v.seoPassCheck = widget.NewCheck(i18n.T("SEO pass: send the targets and check the result"), nil)
+	v.seoPassCheck.SetChecked(true)
+	v.citationSelect.OnChanged = func(string) { PrefCitationStyle.Set(string(v.citationStyle())) }
+
+	v.resultOutput = NewEditorEntry()
+	v.resultOutput.SetPlaceHolder(i18n.T("Generated content will appear here..."))
 	v.resultOutput.Wrapping = fyne.TextWrapWord
 	v.resultOutput.MultiLine = true
 
+	v.resultRendered = widget.NewRichTextFromMarkdown("")
+	v.resultRendered.Wrapping = fyne.TextWrapWord
+	v.resultPreview = widget.NewRichText()
+	v.resultPreview.Wrapping = fyne.TextWrapWord
+	v.resultCount = newCountLabel()
+	v.resultOutput.OnChanged = func(text string) {
+		v.resultCount.SetText(textStatsSummary(text))
+		v.resultRendered.ParseMarkdown(text)
+		v.updateHTMLPreview(text)
+	}
+
 	// Create layout
-	sourceContainer := container.NewBorder(
-		widget.NewLabel("Content Source List:"),
-		container.NewHBox(v.addSourceButton, v.removeSourceButton),
+	sourceContainer := newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Content Source List (drop files here):")),
+		container.NewVBox(
+			container.NewHBox(v.selectAllCheck, v.selectionLabel, layout.NewSpacer(), v.markSampleButton, v.markTrueButton),
+			container.NewHBox(v.addSourceButton, v.removeSourceButton, layout.NewSpacer(), v.interviewButton, v.buildVoiceButton),
+		),
 		nil, nil,
 		container.NewScroll(v.sourceList),
 	)
 
 	// --- Enhanced Prompt Area with Model and Instructions ---
 	generationSettingsForm := widget.NewForm(
-		widget.NewFormItem("Model:", v.selectedModel),
-		widget.NewFormItem("Instructions:", v.instructionEntry),
-		widget.NewFormItem("Prompt/Request:", v.promptEntry),
+		widget.NewFormItem(i18n.T("Model:"), v.selectedModel),
+		widget.NewFormItem(i18n.T("Voice:"), container.NewVBox(v.useVoiceCheck, v.voiceLabel)),
+		widget.NewFormItem(i18n.T("Fact Sheet:"), v.newFactSheetControls()),
+		widget.NewFormItem(i18n.T("Citations:"), v.citationSelect),
+		widget.NewFormItem(i18n.T("SEO Targets:"), container.NewVBox(v.seoPassCheck, v.keywordEntry, v.relatedEntry, v.wordCountEntry, v.searchConsoleButton)),
+		widget.NewFormItem(i18n.T("Instructions:"), newReadingOrderBorder(nil, v.instructionCount, nil,
+			newHistoryButton(v.window, v.instructionHistory, v.instructionEntry.ReplaceText), v.instructionEntry)),
+		widget.NewFormItem(i18n.T("Prompt/Request:"), newReadingOrderBorder(nil, v.promptCount, nil,
+			newHistoryButton(v.window, v.promptHistory, v.promptEntry.ReplaceText), v.promptEntry)),
+		widget.NewFormItem("", v.newChunkingControls()),
 	)
 
-	promptContainer := container.NewBorder(
-		widget.NewLabel("Generation Settings:"), // Top
+	presetsButton, activePreset := v.newPresetControls()
+	promptContainer := newReadingOrderBorder(
+		container.NewHBox(widget.NewLabel(i18n.T("Generation Settings:")), activePreset, layout.NewSpacer(), // Top
+			presetsButton,
+			v.newVariablesButton(),
+			widget.NewButtonWithIcon(i18n.T("Import Brief"), theme.FolderOpenIcon(), v.importBrief)),
 		v.generateButton,                        // Bottom
 		nil,                                     // Left
 		nil,                                     // Right
@@ -185,23 +360,52 @@ func (v *ContentGeneratorView) initialize() {
 	)
 
 	// Create save buttons
-	v.saveToFileButton = widget.NewButton("Save to File", func() {
+	v.saveToFileButton = widget.NewButton(i18n.T("Save to File"), func() {
 		v.saveGeneratedContentToFile()
 	})
-	v.saveToWPButton = widget.NewButton("Save to WordPress", func() {
+	v.saveToWPButton = newCapabilityButton(i18n.T("Save to WordPress"), wordpress.ActionPublish, func() {
 		v.saveGeneratedContent()
 	})
+	v.publishPostButton = newCapabilityButton(i18n.T("Publish as Post..."), wordpress.ActionDraftPost, v.publishAsPost)
 
 	// Initially disable save buttons until content is generated
 	v.saveToFileButton.Disable()
 	v.saveToWPButton.Disable()
+	v.publishPostButton.Disable()
+	reviewButton := widget.NewButtonWithIcon(i18n.T("Review"), theme.AccountIcon(), v.reviewResult)
 
-	resultContainer := container.NewBorder(
-		widget.NewLabel("Generated Content:"),                   // Top
-		container.NewHBox(v.saveToFileButton, v.saveToWPButton), // Bottom
-		nil,                                 // Left
-		nil,                                 // Right
-		container.NewScroll(v.resultOutput), // Center - Scroll expands
+	resultTabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Raw"), container.NewScroll(v.resultOutput)),
+		container.NewTabItem(i18n.T("Rendered"), container.NewScroll(v.resultRendered)),
+		container.NewTabItem(i18n.T("HTML Preview"), container.NewScroll(v.resultPreview)),
+	)
+
+	var selectionButton *widget.Button
+	selectionButton = widget.NewButtonWithIcon(i18n.T("Edit Selection"), theme.DocumentCreateIcon(), func() {
+		showSelectionActions(v.window, v.inferenceService, v.jobQueue, v.resultOutput, selectionButton, v.chosenModel())
+	})
+
+	resultContainer := newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Generated Content:")),                   // Top
+		container.NewHBox(v.saveToFileButton, v.saveToWPButton, v.publishPostButton, reviewButton, newCopyButton(v.window, i18n.T("Copy"), func() string { return v.resultOutput.Text }), layout.NewSpacer(), v.resultCount, // Bottom
+			widget.NewButtonWithIcon(i18n.T("Check Style"), theme.ConfirmIcon(), v.checkStyle),
+			widget.NewButtonWithIcon(i18n.T("FAQ"), theme.QuestionIcon(), v.generateFAQ),
+			widget.NewButtonWithIcon(i18n.T("Social Posts"), theme.MailSendIcon(), v.generateSocialPosts),
+			widget.NewButtonWithIcon(i18n.T("Newsletter"), theme.MailComposeIcon(), func() {
+				if strings.TrimSpace(v.resultOutput.Text) == "" {
+					ShowError(fmt.Errorf("no generated content to convert"), v.window)
+					return
+				}
+				convertToNewsletter(v.window, v.inferenceService, v.jobQueue, v.resultOutput.Text, "", v.chosenModel())
+			}),
+			widget.NewButtonWithIcon(i18n.T("Syndicate"), theme.ContentCopyIcon(), v.syndicate),
+			widget.NewButtonWithIcon(i18n.T("Drafts"), theme.HistoryIcon(), v.showDraftHistory),
+			selectionButton,
+			widget.NewButtonWithIcon(i18n.T("Undo"), theme.ContentUndoIcon(), v.resultOutput.Undo),
+			widget.NewButtonWithIcon(i18n.T("Redo"), theme.ContentRedoIcon(), v.resultOutput.Redo)),
+		nil,        // Left
+		nil,        // Right
+		resultTabs, // Center - Tabs expand
 	)
 
 	// Main layout
@@ -210,39 +414,529 @@ func (v *ContentGeneratorView) initialize() {
 		promptContainer,
 	)
 	leftPanel.SetOffset(0.4) // 40% for source list, 60% for prompt
+	rememberSplit("generator.sources", leftPanel)
 
 	v.container = container.NewHSplit(
 		leftPanel,
 		resultContainer,
 	)
 	v.container.SetOffset(0.4) // 40% for left panel, 60% for result
+	rememberSplit("generator.result", v.container)
+}
+
+// updateHTMLPreview renders an approximation of how the generated HTML will look on the page.
+// Plain Markdown output is rendered as-is so the preview is never empty.
+func (v *ContentGeneratorView) updateHTMLPreview(text string) {
+	if utils.LooksLikeHTML(text) {
+		v.resultPreview.ParseMarkdown(utils.HTMLToMarkdown(text))
+		return
+	}
+	v.resultPreview.ParseMarkdown(text)
 }
 
 // AddSourceContent adds a source content item to the list
-func (v *ContentGeneratorView) AddSourceContent(title, content, source string, id int, isSample bool) {
+func (v *ContentGeneratorView) AddSourceContent(title, content, source, link string, id int, isSample bool) {
 	v.sourceContents = append(v.sourceContents, SourceContent{
 		Title:   title,
 		Content: content,
 		Source:  source,
 		ID:      id,
+		Link:    link,
 		IsSample: isSample,
 	})
 	v.sourceList.Refresh()
+	v.updateSourceActions()
 }
 
-// removeSourceContent removes the selected source content item
-func (v *ContentGeneratorView) removeSourceContent() {
-	if v.selectedSourceIndex < 0 || v.selectedSourceIndex >= len(v.sourceContents) {
-		return
+// selectedSourceCount returns how many sources are ticked.
+func (v *ContentGeneratorView) selectedSourceCount() int {
+	count := 0
+	for _, source := range v.sourceContents {
+		if source.Selected {
+			count++
+		}
 	}
+	return count
+}
 
-	// Remove the item
-	v.sourceContents = append(v.sourceContents[:v.selectedSourceIndex], v.sourceContents[v.selectedSourceIndex+1:]...)
+// updateSourceActions enables the batch buttons when sources are ticked and
+// keeps the "All" box and the selection count in step with the list.
+func (v *ContentGeneratorView) updateSourceActions() {
+	count := v.selectedSourceCount()
+	for _, button := range []*widget.Button{v.removeSourceButton, v.markSampleButton, v.markTrueButton} {
+		if count > 0 {
+			button.Enable()
+		} else {
+			button.Disable()
+		}
+	}
+	if count > 0 {
+		v.selectionLabel.SetText(i18n.Tf("%d of %d selected", count, len(v.sourceContents)))
+	} else {
+		v.selectionLabel.SetText("")
+	}
+	if count == 1 {
+		v.interviewButton.Enable()
+	} else {
+		v.interviewButton.Disable()
+	}
+	all := count > 0 && count == len(v.sourceContents)
+	if v.selectAllCheck.Checked != all {
+		onChanged := v.selectAllCheck.OnChanged
+		v.selectAllCheck.OnChanged = nil // Reflect the state without re-applying it to every row
+		v.selectAllCheck.SetChecked(all)
+		v.selectAllCheck.OnChanged = onChanged
+	}
+}
+
+// setAllSourcesSelected ticks or unticks every source.
+func (v *ContentGeneratorView) setAllSourcesSelected(selected bool) {
+	for i := range v.sourceContents {
+		v.sourceContents[i].Selected = selected
+	}
+	v.sourceList.Refresh()
+	v.updateSourceActions()
+}
+
+// setSelectedSourcesSample marks every ticked source as Sample or True.
+func (v *ContentGeneratorView) setSelectedSourcesSample(sample bool) {
+	changed := 0
+	for i := range v.sourceContents {
+		if v.sourceContents[i].Selected && v.sourceContents[i].IsSample != sample {
+			v.sourceContents[i].IsSample = sample
+			changed++
+		}
+	}
+	logger.Info("Source sample flag changed in bulk", "sources", changed, "sample", sample)
 	v.sourceList.Refresh()
+}
+
+// removeSelectedSources removes every ticked source, asking first when more
+// than one would go.
+func (v *ContentGeneratorView) removeSelectedSources() {
+	count := v.selectedSourceCount()
+	remove := func() {
+		kept := v.sourceContents[:0]
+		for _, source := range v.sourceContents {
+			if !source.Selected {
+				kept = append(kept, source)
+			}
+		}
+		v.sourceContents = kept
+		v.sourceList.Refresh()
+		v.updateSourceActions()
+	}
+	switch {
+	case count == 0:
+		return
+	case count == 1:
+		remove()
+	default:
+		dialog.ShowConfirm(i18n.T("Remove Sources"), i18n.Tf("Remove %d sources from the list?", count), func(ok bool) {
+			if ok {
+				remove()
+			}
+		}, v.window)
+	}
+}
+
+// SetJobQueue sets the queue that generations are submitted to
+func (v *ContentGeneratorView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetDraftHistory sets the store that every generated draft is saved to
+func (v *ContentGeneratorView) SetDraftHistory(store *history.Store) {
+	v.drafts = store
+}
+
+// SetStyleGuide sets the style guide that generations follow and are checked against
+func (v *ContentGeneratorView) SetStyleGuide(store *editorial.StyleGuideStore) {
+	v.styleGuide = store
+}
 
-	// Reset selection
-	v.selectedSourceIndex = -1
-	v.removeSourceButton.Disable()
+// SetVoiceProfiles sets the store holding the brand voice profile
+func (v *ContentGeneratorView) SetVoiceProfiles(store *editorial.VoiceProfileStore) {
+	v.voiceProfiles = store
+	v.refreshVoiceProfile()
+}
+
+// refreshVoiceProfile shows the saved voice profile and enables using it
+func (v *ContentGeneratorView) refreshVoiceProfile() {
+	profile, ok := editorial.VoiceProfile{}, false
+	if v.voiceProfiles != nil {
+		profile, ok = v.voiceProfiles.Profile()
+	}
+	if !ok {
+		v.useVoiceCheck.SetChecked(false)
+		v.useVoiceCheck.Disable()
+		v.voiceLabel.SetText(i18n.T("No voice profile yet. Mark Sample sources and click Build Voice Profile."))
+		return
+	}
+	v.useVoiceCheck.Enable()
+	v.voiceLabel.SetText(profile.Summary())
+}
+
+// buildVoiceProfile analyzes the Sample sources once and saves the result as
+// the brand voice profile, so later generations can send it instead of them.
+func (v *ContentGeneratorView) buildVoiceProfile() {
+	if v.voiceProfiles == nil {
+		ShowError(fmt.Errorf("voice profiles are unavailable (see the log for why the state database could not be opened)"), v.window)
+		return
+	}
+	var samples, titles []string
+	for _, source := range v.sourceContents {
+		if source.IsSample {
+			samples = append(samples, source.Content)
+			titles = append(titles, source.Title)
+		}
+	}
+	if len(samples) == 0 {
+		ShowError(fmt.Errorf("mark at least one source as Sample to build a voice profile"), v.window)
+		return
+	}
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		profile := editorial.MeasureVoice(samples)
+		profile.SampleTitles = titles
+		progress(-1, i18n.T("Describing the tone"))
+		tone, err := v.inferenceService.Generate(
+			inference.GetBrandVoiceAnalysisPrompt(strings.Join(samples, "\n\n--- Next Sample ---\n\n")),
+			inference.GenerateOptions{Context: ctx, Task: inference.TaskSummarization})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			profile.Tone = tone
+			err = v.voiceProfiles.Save(profile)
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to build voice profile: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() {
+			v.refreshVoiceProfile()
+			v.useVoiceCheck.SetChecked(true)
+			dialog.ShowInformation(i18n.T("Voice Profile"), profile.Instruction(), v.window)
+		})
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.buildVoiceProfile", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Voice Profile", i18n.Tf("%d samples", len(samples)), run)
+}
+
+// SetGlossaries sets the store holding the per-site glossaries
+func (v *ContentGeneratorView) SetGlossaries(store *editorial.GlossaryStore) {
+	v.glossaries = store
+}
+
+// SetSiteInstructions sets the store holding the per-site default
+// instructions
+func (v *ContentGeneratorView) SetSiteInstructions(store *editorial.SiteInstructionsStore) {
+	v.siteInstructions = store
+}
+
+// SetDisclaimers sets the store holding the compliance disclaimer rules
+func (v *ContentGeneratorView) SetDisclaimers(store *editorial.DisclaimerStore) {
+	v.disclaimers = store
+}
+
+// SetRelatedPosts sets the per-site settings of the "Further reading" block
+// and the embeddings index its related pages are found in.
+func (v *ContentGeneratorView) SetRelatedPosts(store *editorial.RelatedPostsStore, index *embeddings.Store) {
+	v.relatedPosts = store
+	v.embeddingIndex = index
+}
+
+// relatedLinks returns the pages most related to pageID by the site's
+// embeddings index, as the site's settings ask, and the settings. There are
+// none if the block is off or the page is not indexed.
+func (v *ContentGeneratorView) relatedLinks(pageID int) ([]seo.RelatedLink, editorial.RelatedPostsSettings) {
+	if v.relatedPosts == nil || v.embeddingIndex == nil {
+		return nil, editorial.RelatedPostsSettings{}
+	}
+	site := v.wpService.GetCurrentSiteName()
+	settings := v.relatedPosts.Settings(site)
+	if !settings.Enabled {
+		return nil, settings
+	}
+	var links []seo.RelatedLink
+	for _, match := range v.embeddingIndex.Index(site).Similar(pageID, settings.Count) {
+		if match.Similarity >= settings.MinSimilarity {
+			links = append(links, seo.RelatedLink{Title: match.Title, URL: match.Link})
+		}
+	}
+	return links, settings
+}
+
+// SetSearchConsole sets the client that reads the queries a WordPress
+// source already ranks for, and offers to target them.
+func (v *ContentGeneratorView) SetSearchConsole(client *searchconsole.Client) {
+	v.searchConsole = client
+	if client != nil {
+		v.searchConsoleButton.Show()
+	}
+}
+
+// targetsFromSearchConsole reads the queries the first WordPress source
+// ranks for and makes them the SEO targets, adding them to the instructions
+// so the content keeps answering them.
+func (v *ContentGeneratorView) targetsFromSearchConsole() {
+	var page *SourceContent
+	for i := range v.sourceContents {
+		if source := &v.sourceContents[i]; source.Source == "WordPress" && !source.IsSample && source.Link != "" {
+			page = source
+			break
+		}
+	}
+	if page == nil {
+		ShowError(fmt.Errorf("add the WordPress page to improve as a True source first"), v.window)
+		return
+	}
+	title, link := page.Title, page.Link
+	v.searchConsoleButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.searchConsoleButton.Enable)
+		progress(-1, i18n.T("Reading search queries"))
+		queries, err := v.searchConsole.PageQueries(ctx, link, searchconsole.DefaultDays, searchconsole.DefaultLimit)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runOnUI(func() {
+			if err != nil {
+				ShowError(err, v.window)
+				return
+			}
+			if len(queries) == 0 {
+				dialog.ShowInformation(i18n.T("Search Console"), i18n.Tf("'%s' has no search impressions in the last %d days.", title, searchconsole.DefaultDays), v.window)
+				return
+			}
+			targets := searchconsole.Targets(queries, 9)
+			v.keywordEntry.SetText(targets.Keyword)
+			v.relatedEntry.SetText(strings.Join(targets.SecondaryKeywords, ", "))
+			v.instructionEntry.ReplaceText(strings.TrimSpace(v.instructionEntry.Text + "\n\n" + inference.GetSearchQueriesPrompt(searchconsole.Summary(queries))))
+			logger.Info("ContentGeneratorView: set SEO targets from Search Console", "page", link, "queries", len(queries))
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.targetsFromSearchConsole", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Search Console", title, run)
+}
+
+// currentStyleGuide returns the registered style guide combined with the
+// current site's glossary, empty if there is neither.
+func (v *ContentGeneratorView) currentStyleGuide() editorial.StyleGuide {
+	var guide editorial.StyleGuide
+	if v.styleGuide != nil {
+		guide = v.styleGuide.Guide()
+	}
+	if v.glossaries != nil {
+		site := ""
+		if v.wpService != nil {
+			site = v.wpService.GetCurrentSiteName()
+		}
+		guide = guide.With(v.glossaries.Glossary(site).StyleGuide())
+	}
+	return guide
+}
+
+// checkStyle lists the result's style guide violations and offers to fix them
+func (v *ContentGeneratorView) checkStyle() {
+	guide := v.currentStyleGuide()
+	if guide.IsEmpty() {
+		dialog.ShowInformation(i18n.T("Style Guide"), i18n.T("No style guide or glossary registered. Add one in Settings."), v.window)
+		return
+	}
+	showStyleViolations(v.window, guide, v.resultOutput.Text, v.resultOutput.ReplaceText)
+}
+
+// generateFAQ derives an FAQ section from the result and, once accepted, keeps
+// it to be appended (with its FAQPage JSON-LD) when the page is saved.
+func (v *ContentGeneratorView) generateFAQ() {
+	content := v.resultOutput.Text
+	if strings.TrimSpace(content) == "" {
+		ShowError(fmt.Errorf("no generated content to derive an FAQ from"), v.window)
+		return
+	}
+	model := v.chosenModel()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		output, err := v.inferenceService.Generate(inference.GetFAQGenerationPrompt(content), generateOptions(ctx, model, inference.TaskStructured))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var faq seo.FAQ
+		var jsonLD string
+		if err == nil {
+			faq, err = seo.ParseFAQ(output)
+		}
+		if err == nil {
+			jsonLD, err = faq.JSONLD()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to generate FAQ: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showFAQ(faq, jsonLD) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.generateFAQ", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("FAQ", truncateUTF8(content, 60), run)
+}
+
+// showFAQ previews a generated FAQ and its JSON-LD, and keeps it for the
+// next save if accepted.
+func (v *ContentGeneratorView) showFAQ(faq seo.FAQ, jsonLD string) {
+	preview := widget.NewRichTextFromMarkdown(faq.Markdown() + "\n```\n" + jsonLD + "\n```\n")
+	preview.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+	content := container.NewBorder(widget.NewLabel(i18n.T("The FAQ section and its FAQPage structured data are appended to the page when you save it to WordPress.")), nil, nil, nil, scroll)
+	dialog.ShowCustomConfirm(i18n.T("FAQ"), i18n.T("Append on Save"), i18n.T("Discard"), content, func(accept bool) {
+		if !accept {
+			return
+		}
+		v.faq = &faq
+		logger.Info("ContentGeneratorView: FAQ will be appended on save", "questions", len(faq.Items))
+	}, v.window)
+}
+
+// generateSocialPosts writes an X thread, LinkedIn post and Facebook blurb
+// promoting the result, in one request.
+func (v *ContentGeneratorView) generateSocialPosts() {
+	content := v.resultOutput.Text
+	if strings.TrimSpace(content) == "" {
+		ShowError(fmt.Errorf("no generated content to write social posts about"), v.window)
+		return
+	}
+	model := v.chosenModel()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		output, err := v.inferenceService.Generate(inference.GetSocialPostsPrompt(content), generateOptions(ctx, model, inference.TaskStructured))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var posts repurpose.SocialPosts
+		if err == nil {
+			posts, err = repurpose.ParseSocialPosts(output)
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to generate social posts: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() { showSocialPosts(v.window, posts) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.generateSocialPosts", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Social Posts", truncateUTF8(content, 60), run)
+}
+
+// showDraftHistory opens the generation history for browsing and restoring drafts
+func (v *ContentGeneratorView) showDraftHistory() {
+	if v.drafts == nil {
+		ShowError(fmt.Errorf("generation history is unavailable (see the log for why it could not be opened)"), v.window)
+		return
+	}
+	showGenerationHistory(v.drafts, v.window, v.restoreDraft)
+}
+
+// restoreDraft puts a draft's prompt, instructions, model and output back in
+// the editors. The replaced result stays reachable via Undo.
+func (v *ContentGeneratorView) restoreDraft(d history.Draft) {
+	v.promptEntry.ReplaceText(d.Prompt)
+	v.instructionEntry.ReplaceText(d.Instruction)
+	for _, model := range v.selectedModel.Options {
+		if model == d.Model {
+			v.selectedModel.SetSelected(model)
+		}
+	}
+	v.resultOutput.ReplaceText(d.Output)
+	v.faq = nil // Derived from the replaced result
+	v.currentDraftID = d.ID
+	v.saveToFileButton.Enable()
+	v.saveToWPButton.Enable()
+	v.publishPostButton.Enable()
+	logger.Info("ContentGeneratorView: restored draft", "draft_id", d.ID)
+}
+
+// saveDraft records a generated result in the draft history and returns its
+// ID, or 0 if it could not be saved.
+func (v *ContentGeneratorView) saveDraft(sources []SourceContent, promptText, instructionText, model, output string) int {
+	if v.drafts == nil {
+		return 0
+	}
+	contents := make([]string, 0, len(sources))
+	titles := make([]string, 0, len(sources))
+	for _, source := range sources {
+		contents = append(contents, source.Content)
+		titles = append(titles, source.Title)
+	}
+	draft, err := v.drafts.Add(history.Draft{
+		Prompt:       promptText,
+		Instruction:  instructionText,
+		Model:        model,
+		SourcesHash:  history.HashSources(contents),
+		SourceTitles: titles,
+		Output:       output,
+	})
+	if err != nil {
+		logger.Error("ContentGeneratorView: failed to save draft to history", "error", err)
+		return 0
+	}
+	logger.Info("ContentGeneratorView: saved draft to history", "draft_id", draft.ID)
+	return draft.ID
+}
+
+// reviewResult opens the review of the current result. A result edited since
+// it was generated or restored is saved as a new draft first, so that what
+// is reviewed is what would be saved.
+func (v *ContentGeneratorView) reviewResult() {
+	if v.drafts == nil {
+		ShowError(fmt.Errorf("generation history is unavailable (see the log for why it could not be opened)"), v.window)
+		return
+	}
+	output := v.resultOutput.Text
+	if strings.TrimSpace(output) == "" {
+		ShowError(fmt.Errorf("no generated content to review"), v.window)
+		return
+	}
+	if draft, ok := v.drafts.Get(v.currentDraftID); !ok || draft.Output != output {
+		id := v.saveDraft(v.sourceContents, v.promptEntry.Text, v.instructionEntry.Text, v.selectedModel.Selected, output)
+		if id == 0 {
+			ShowError(fmt.Errorf("failed to save the result as a draft (see the log)"), v.window)
+			return
+		}
+		v.currentDraftID = id
+	}
+	showDraftReview(v.drafts, v.window, v.currentDraftID, nil)
+}
+
+// approvedDraft reports whether content is the output of the current draft
+// and that draft is approved.
+func (v *ContentGeneratorView) approvedDraft(content string) bool {
+	if v.drafts == nil {
+		return false
+	}
+	draft, ok := v.drafts.Get(v.currentDraftID)
+	return ok && draft.Output == content && draft.Review == history.ReviewApproved
+}
+
+// FocusRegions returns the source list, prompt inputs and result editor for Ctrl+F6 focus cycling
+func (v *ContentGeneratorView) FocusRegions() []fyne.Focusable {
+	return []fyne.Focusable{v.sourceList, v.promptEntry, v.instructionEntry, v.resultOutput}
 }
 
 // Container returns the container for the content generator view
@@ -259,71 +953,64 @@ func (v *ContentGeneratorView) GetSourceContents() []SourceContent {
 func (v *ContentGeneratorView) ClearSourceContents() {
 	v.sourceContents = []SourceContent{}
 	v.sourceList.Refresh()
-	v.selectedSourceIndex = -1
-	v.removeSourceButton.Disable()
+	v.updateSourceActions()
 }
 
-// refreshAvailableModels populates the model selection dropdown.
+// refreshAvailableModels populates the model selection dropdown with MOA and
+// every available model as a "provider/model" reference, so the request is
+// routed to that provider even if another one serves the same model.
 func (v *ContentGeneratorView) refreshAvailableModels() {
 	if v.inferenceService == nil {
-		v.selectedModel.Options = []string{"Service unavailable"}
+		v.selectedModel.Options = []string{i18n.T("Service unavailable")}
+		v.modelsListed = false
 		v.selectedModel.Refresh()
 		return
 	}
-	primaryModels := v.inferenceService.GetPrimaryModels()
-	moaPrimaryDefault := v.inferenceService.GetProxyModel() // MOA's default primary
-	fallbackModels := v.inferenceService.GetFallbackModels()
-	moaFallbackDefault := v.inferenceService.GetBaseModel() // MOA's default fallback/aggregator
-
-	// Combine unique model names, ensuring MOA defaults are listed if available
-	modelSet := make(map[string]struct{})
-	allModels := []string{"MOA (Mixture of Agents)"} // Add MOA as the first option
-	if moaPrimaryDefault != "" {
-		modelSet[moaPrimaryDefault] = struct{}{}
-	}
-	if moaFallbackDefault != "" {
-		modelSet[moaFallbackDefault] = struct{}{}
-	}
-	modelSet[allModels[0]] = struct{}{}
 
-	for _, model := range append(primaryModels, fallbackModels...) {
-		if _, exists := modelSet[model]; !exists {
-			allModels = append(allModels, model)
-			modelSet[model] = struct{}{}
+	allModels := []string{"MOA (Mixture of Agents)"} // MOA is the first option and the default
+	for _, model := range v.inferenceService.Models() {
+		if model.Available {
+			allModels = append(allModels, model.ID())
 		}
 	}
-
-	if len(allModels) == 0 {
-		allModels = []string{"No models available"}
+	v.modelsListed = len(allModels) > 1
+	if !v.modelsListed { // No model has an API key (or the service isn't started)
+		allModels = []string{i18n.T("No models available")}
 	}
+
+	previous := v.selectedModel.Selected
 	v.selectedModel.Options = allModels
-	// Set default selection to MOA if available, otherwise the first actual model
-	selectedIndex := 0 // Default to MOA
-	if len(allModels) > 1 && allModels[0] != "MOA (Mixture of Agents)" { // Should not happen with current logic
-		// Fallback if MOA wasn't added first for some reason
-		for i, model := range allModels {
-			if model == moaPrimaryDefault {
-				selectedIndex = i
-				break
-			}
+	v.selectedModel.SetSelectedIndex(0)
+	for _, model := range allModels {
+		if model == previous { // Keep the user's choice if it survived the change
+			v.selectedModel.SetSelected(previous)
 		}
-	} else if len(allModels) == 1 && allModels[0] != "MOA (Mixture of Agents)" {
-		// If only "No models available" or a single non-MOA model
-		selectedIndex = 0
 	}
+	v.selectedModel.Refresh()
+	v.refreshChunkMapModels()
+}
 
-	if selectedIndex >= len(allModels) { // Safety check
-		selectedIndex = 0
+// modelChosen reports whether a model is selected, rather than nothing or
+// the placeholder shown while there are no models.
+func (v *ContentGeneratorView) modelChosen() bool {
+	return v.modelsListed && v.selectedModel.Selected != ""
+}
+
+// chosenModel returns the selected model, or "" while there are no models,
+// which leaves the choice to the inference service.
+func (v *ContentGeneratorView) chosenModel() string {
+	if !v.modelChosen() {
+		return ""
 	}
-	v.selectedModel.SetSelectedIndex(selectedIndex)
-	v.selectedModel.Refresh()
+	return v.selectedModel.Selected
 }
+
 // showAddSourceDialog shows a dialog to add a source file
 func (v *ContentGeneratorView) showAddSourceDialog() {
 	// Create a file dialog
 	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
 		if err != nil {
-			dialog.ShowError(err, v.window)
+			ShowError(err, v.window)
 			return
 		}
 		if reader == nil {
@@ -332,197 +1019,601 @@ func (v *ContentGeneratorView) showAddSourceDialog() {
 		}
 
 		// Show progress dialog
-		progress := dialog.NewProgressInfinite("Loading", "Loading file content...", v.window)
+		progress := dialog.NewProgressInfinite(i18n.T("Loading"), i18n.T("Loading file content..."), v.window)
 		progress.Show()
 
 		// Process file in a goroutine
 		go func() {
+			defer crash.Recover("ContentGeneratorView.showAddSourceDialog")
 			defer reader.Close()
-			defer progress.Hide()
-			// Hide progress dialog
-			// Progress dialog is handled by the main defer
-			
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("failed to read file: %w", err), v.window)
-				return
-			}
 
 			// Read file content
-			content, err := io.ReadAll(reader) // Moved after defer progress.Hide()
-			if err != nil { // Check error from ReadAll
-				dialog.ShowError(fmt.Errorf("failed to read file content: %w", err), v.window)
-				return
-			}
+			content, err := io.ReadAll(reader)
 			// Get file name from URI
 			fileName := reader.URI().Name()
-			// Add to source contents
-			v.AddSourceContent(
-				fileName,
-				string(content),
-				"File",
-				-1, // No WordPress ID for files
-				false, 
-			)
-
-			dialog.ShowInformation("Success", fmt.Sprintf("Added file '%s' to source content", fileName), v.window)
+
+			runOnUI(func() {
+				progress.Hide()
+				if err != nil {
+					ShowError(fmt.Errorf("failed to read file content: %w", err), v.window)
+					return
+				}
+				// Add to source contents
+				v.AddSourceContent(
+					fileName,
+					string(content),
+					"File",
+					reader.URI().String(),
+					-1, // No WordPress ID for files
+					false,
+				)
+
+				dialog.ShowInformation(i18n.T("Success"), i18n.Tf("Added file '%s' to source content", fileName), v.window)
+			})
 		}()
 	}, v.window)
 }
 
-// generateContent generates content based on source content and prompt
-func (v *ContentGeneratorView) generateContent() {
-	v.generationMutex.Lock()
-	if v.isGenerating {
-		v.generationMutex.Unlock()
-		dialog.ShowInformation("In Progress", "A content generation task is already running.", v.window)
+// HandleDroppedURIs adds files dropped from the OS file manager as sources.
+// Drops are only accepted when they land on the source list; directories and
+// unreadable URIs are skipped.
+func (v *ContentGeneratorView) HandleDroppedURIs(pos fyne.Position, uris []fyne.URI) {
+	if len(uris) == 0 || !v.isOverSourceList(pos) {
 		return
 	}
-	v.isGenerating = true
-	v.generationMutex.Unlock()
 
-	// Ensure isGenerating is reset, log relay stopped, and dialog hidden when done.
-	defer func() {
-		v.generationMutex.Lock()
-		v.isGenerating = false
-		v.generationMutex.Unlock()
+	progress := dialog.NewProgressInfinite(i18n.T("Loading"), i18n.Tf("Loading %d dropped file(s)...", len(uris)), v.window)
+	progress.Show()
 
-		if v.generationLogRelay != nil {
-			v.generationLogRelay.Stop()
+	go func() {
+		defer crash.Recover("ContentGeneratorView.HandleDroppedURIs")
+		type droppedFile struct{ name, content, uri string }
+		var loaded []droppedFile
+		var failed []string
+		for _, uri := range uris {
+			if isDir, err := storage.CanList(uri); err == nil && isDir {
+				logger.Info("ContentGeneratorView: skipping dropped directory", "name", uri.Name())
+				continue
+			}
+			reader, err := storage.Reader(uri)
+			if err != nil {
+				logger.Error("ContentGeneratorView: failed to open dropped file", "name", uri.Name(), "error", err)
+				failed = append(failed, uri.Name())
+				continue
+			}
+			content, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				logger.Error("ContentGeneratorView: failed to read dropped file", "name", uri.Name(), "error", err)
+				failed = append(failed, uri.Name())
+				continue
+			}
+			loaded = append(loaded, droppedFile{uri.Name(), string(content), uri.String()})
 		}
 
-		v.dialogMutex.Lock()
-		if v.customProgressDialog != nil {
-			v.customProgressDialog.Hide()
-			v.customProgressDialog = nil
-		}
-		v.dialogMutex.Unlock()
+		runOnUI(func() {
+			progress.Hide()
+			for _, file := range loaded {
+				v.AddSourceContent(file.name, file.content, "File", file.uri, -1, false)
+			}
+
+			if len(failed) > 0 {
+				ShowError(fmt.Errorf("failed to load %d file(s): %s", len(failed), strings.Join(failed, ", ")), v.window)
+				return
+			}
+			if len(loaded) > 0 {
+				dialog.ShowInformation(i18n.T("Success"), i18n.Tf("Added %d file(s) to source content", len(loaded)), v.window)
+			}
+		})
 	}()
+}
+
+// isOverSourceList reports whether a window-relative position falls inside the source list.
+func (v *ContentGeneratorView) isOverSourceList(pos fyne.Position) bool {
+	if v.sourceList == nil || !v.sourceList.Visible() {
+		return false
+	}
+	driver := fyne.CurrentApp().Driver()
+	origin := driver.AbsolutePositionForObject(v.sourceList)
+	size := v.sourceList.Size()
+	return pos.X >= origin.X && pos.X <= origin.X+size.Width &&
+		pos.Y >= origin.Y && pos.Y <= origin.Y+size.Height
+}
+
+// generateContent generates content based on source content and prompt
+func (v *ContentGeneratorView) generateContent() {
+	v.generationMutex.Lock()
+	isGenerating := v.isGenerating
+	v.generationMutex.Unlock()
+	if isGenerating {
+		dialog.ShowInformation(i18n.T("In Progress"), i18n.T("A content generation task is already running."), v.window)
+		return
+	}
 
 	// Validate inputs
 	if len(v.sourceContents) == 0 {
-		dialog.ShowError(fmt.Errorf("no source content available"), v.window)
+		ShowError(fmt.Errorf("no source content available"), v.window)
 		return
 	}
 	
 	promptText := v.promptEntry.Text
 	if promptText == "" {
-		dialog.ShowError(fmt.Errorf("prompt cannot be empty"), v.window)
+		ShowError(fmt.Errorf("prompt cannot be empty"), v.window)
 		return
 	}
 	instructionText := v.instructionEntry.Text
 	selectedModelName := v.selectedModel.Selected
-	if selectedModelName == "" || selectedModelName == "No models available" || selectedModelName == "Service unavailable" {
-		dialog.ShowError(fmt.Errorf("please select a valid model"), v.window)
+	if !v.modelChosen() {
+		ShowError(fmt.Errorf("please select a valid model"), v.window)
+		return
+	}
+	targets, err := v.seoTargets()
+	if err != nil {
+		ShowError(err, v.window)
 		return
 	}
+	if !v.seoPassCheck.Checked {
+		targets = seo.Targets{}
+	}
+	v.promptHistory.Add(promptText)
+	v.instructionHistory.Add(instructionText)
+	sources := append([]SourceContent(nil), v.sourceContents...) // Snapshot so a retry uses the same sources
+	siteInstruction := ""
+	if v.siteInstructions != nil && v.wpService != nil {
+		siteInstruction = v.siteInstructions.Instruction(v.wpService.GetCurrentSiteName())
+	}
+	factInstruction := ""
+	if v.useFactsCheck.Checked && v.factSheet != nil {
+		factInstruction = v.factSheet.Instruction()
+	}
+	voiceInstruction := ""
+	if v.useVoiceCheck.Checked && v.voiceProfiles != nil {
+		if profile, ok := v.voiceProfiles.Profile(); ok {
+			voiceInstruction = profile.Instruction()
+		}
+	}
 
-	// Setup progress dialog with log viewer
-	v.dialogMutex.Lock()
-	// Deferring unlock here might be problematic if Show() blocks or another dialog is shown.
-	// Let's unlock after Show() or manage it more carefully.
-	
-	if v.customProgressDialog != nil {
-		v.customProgressDialog.Hide()
+	chunking, chunk := v.chunkingOptions()
+	req := generationRequest{
+		sources:          sources,
+		prompt:           promptText,
+		instruction:      instructionText,
+		model:            selectedModelName,
+		siteInstruction:  siteInstruction,
+		voiceInstruction: voiceInstruction,
+		factInstruction:  factInstruction,
+		citations:        v.citationStyle(),
+		targets:          targets,
+		chunk:            chunk,
+		chunking:         chunking,
+		summarizeSamples: v.summarizeSamplesCheck.Checked,
+		params:           v.presetParams,
+	}
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		return v.runGeneration(ctx, req)
 	}
 
-	v.generationLogDisplay.SetText("Initializing generation process...\n")
+	// The estimate covers what the model will be sent: the sources, the
+	// request and every instruction
+	var estimatePrompt strings.Builder
+	for _, source := range sources {
+		estimatePrompt.WriteString(source.Content + "\n\n")
+	}
+	estimatePrompt.WriteString(promptText)
+	opts := contentOptions(context.Background(), selectedModelName)
+	opts.Instruction = strings.Join([]string{instructionText, siteInstruction, voiceInstruction, factInstruction, req.citations.Instruction(), targets.Instruction()}, "\n\n")
+	confirmGeneration(v.window, v.inferenceService, estimatePrompt.String(), opts, expectedOutputTokens(targets), func() {
+		if v.jobQueue == nil {
+			crash.Go("ContentGeneratorView.generateContent", func() { run(context.Background(), func(float64, string) {}) })
+			return
+		}
+		v.jobQueue.Submit("Generation", fmt.Sprintf("%s (%s)", truncateUTF8(promptText, 60), selectedModelName), run)
+	})
+}
 
-	v.generationLogRelay = utils.NewLogRelay(func(logText string) {
-		if v.window.Canvas() != nil { // Check if canvas is valid
-			// Fyne typically handles marshalling widget updates to the main thread.
-			v.generationLogDisplay.SetText(logText)
+// seoTargets returns the SEO targets entered in the generation settings.
+func (v *ContentGeneratorView) seoTargets() (seo.Targets, error) {
+	targets := seo.Targets{Keyword: strings.TrimSpace(v.keywordEntry.Text)}
+	for _, term := range strings.Split(v.relatedEntry.Text, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			targets.SecondaryKeywords = append(targets.SecondaryKeywords, term)
 		}
+	}
+	if text := strings.TrimSpace(v.wordCountEntry.Text); text != "" {
+		count, err := strconv.Atoi(text)
+		if err != nil || count < 0 {
+			return seo.Targets{}, fmt.Errorf("the target word count must be a whole number")
+		}
+		targets.WordCount = count
+	}
+	return targets, nil
+}
+
+// importBrief pre-fills the prompt, instructions and SEO targets from a
+// YAML or JSON content brief. The replaced prompt and instructions stay
+// reachable via Undo.
+func (v *ContentGeneratorView) importBrief() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			ShowError(fmt.Errorf("failed to read brief: %w", err), v.window)
+			return
+		}
+		b, err := brief.Parse(data)
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		v.ApplyBrief(b)
+		logger.Info("ContentGeneratorView: imported content brief", "file", reader.URI().Name())
+	}, v.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml", ".json"}))
+	open.Show()
+}
+
+// ApplyBrief fills the generation request, instructions and SEO targets from
+// a content brief.
+func (v *ContentGeneratorView) ApplyBrief(b brief.Brief) {
+	v.promptEntry.ReplaceText(b.Prompt())
+	v.instructionEntry.ReplaceText(b.Instruction())
+	targets := b.Targets()
+	v.keywordEntry.SetText(targets.Keyword)
+	v.relatedEntry.SetText(strings.Join(targets.SecondaryKeywords, ", "))
+	v.wordCountEntry.SetText("")
+	if targets.WordCount > 0 {
+		v.wordCountEntry.SetText(strconv.Itoa(targets.WordCount))
+	}
+}
+
+// PrefillRequest replaces the generation request and instructions, e.g. to
+// prepare a page refresh from a report.
+func (v *ContentGeneratorView) PrefillRequest(prompt, instruction string) {
+	v.promptEntry.ReplaceText(prompt)
+	v.instructionEntry.ReplaceText(instruction)
+}
+
+// SetPrompt replaces the generation request, keeping the instructions.
+func (v *ContentGeneratorView) SetPrompt(prompt string) {
+	v.promptEntry.ReplaceText(prompt)
+}
+
+// OpenResult shows output as the result of prompt, as if generated here, so
+// it can be checked, edited and saved like any other result.
+func (v *ContentGeneratorView) OpenResult(prompt, output string) {
+	v.promptEntry.ReplaceText(prompt)
+	v.resultOutput.ReplaceText(output)
+	v.faq = nil // Derived from the replaced result
+	v.currentDraftID = 0
+	v.saveToFileButton.Enable()
+	v.saveToWPButton.Enable()
+	v.publishPostButton.Enable()
+}
+
+// citationStyle returns the selected citation style.
+func (v *ContentGeneratorView) citationStyle() editorial.CitationStyle {
+	if i := v.citationSelect.SelectedIndex(); i >= 0 {
+		return editorial.CitationStyles[i]
+	}
+	return editorial.CitationsNone
+}
+
+// generateOptions routes a request to the model picked in the model
+// selector, which may be MOA, as the given task type.
+func generateOptions(ctx context.Context, model string, task inference.TaskType) inference.GenerateOptions {
+	if model == "MOA (Mixture of Agents)" {
+		return inference.GenerateOptions{Context: ctx, UseMOA: true, Task: task}
+	}
+	return inference.GenerateOptions{Context: ctx, Model: model, Task: task}
+}
+
+// contentOptions are the generateOptions of long-form content that is shown
+// or saved as a page, which goes through the post-processing pipeline.
+func contentOptions(ctx context.Context, model string) inference.GenerateOptions {
+	opts := generateOptions(ctx, model, inference.TaskLongForm)
+	opts.PostProcess = true
+	return opts
+}
+
+// newVariablesButton returns a button listing the site data variables the
+// connected site has the plugins for, which inserts the chosen one at the
+// prompt's cursor.
+func (v *ContentGeneratorView) newVariablesButton() *widget.Button {
+	var button *widget.Button
+	button = widget.NewButtonWithIcon(i18n.T("Variables"), theme.ListIcon(), func() {
+		variables := v.wpService.AvailableTemplateVariables()
+		items := make([]*fyne.MenuItem, len(variables))
+		for i, variable := range variables {
+			placeholder := "{{" + variable.Name + "}}"
+			items[i] = fyne.NewMenuItem(fmt.Sprintf("%s  %s", placeholder, i18n.T(variable.Description)), func() {
+				v.promptEntry.InsertAtCursor(placeholder)
+				v.window.Canvas().Focus(v.promptEntry)
+			})
+		}
+		position := fyne.CurrentApp().Driver().AbsolutePositionForObject(button).Add(fyne.NewPos(0, button.Size().Height))
+		widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), v.window.Canvas(), position)
 	})
-	v.generationLogRelay.Start()
+	return button
+}
 
-	progressBar := widget.NewProgressBarInfinite()
-	logScroll := container.NewVScroll(v.generationLogDisplay)
-	logScroll.SetMinSize(fyne.NewSize(450, 200))
+// expandTemplateVariables fills in the site data variables of the prompt
+// and instructions from the connected site.
+func (v *ContentGeneratorView) expandTemplateVariables(promptText, instructionText string) (string, string, error) {
+	names := wordpress.UsedTemplateVariables(promptText, instructionText)
+	if len(names) == 0 {
+		return promptText, instructionText, nil
+	}
+	if v.wpService == nil || !v.wpService.IsConnected() {
+		return "", "", fmt.Errorf("the prompt uses site variables (%s); connect to a WordPress site first", strings.Join(names, ", "))
+	}
+	values, err := v.wpService.TemplateValues(names)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fill in site variables: %w", err)
+	}
+	logger.Info("ContentGeneratorView: filled in site variables", "variables", names)
+	return wordpress.ExpandTemplateVariables(promptText, values), wordpress.ExpandTemplateVariables(instructionText, values), nil
+}
 
-	dialogContent := container.NewVBox(
-		widget.NewLabelWithStyle("Generating Content with AI...", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
-		progressBar,
-		widget.NewSeparator(),
-		container.NewHBox(widget.NewIcon(theme.InfoIcon()), widget.NewLabel("Backend Activity:")),
-		logScroll,
-	)
+// generationRequest is everything a generation job needs, captured when it is
+// submitted so a retry repeats it exactly.
+type generationRequest struct {
+	sources          []SourceContent
+	prompt           string
+	instruction      string
+	model            string
+	siteInstruction  string // The target site's default instructions
+	voiceInstruction string // Replaces the Sample sources when set
+	factInstruction  string // The fact sheet, if used
+	citations        editorial.CitationStyle
+	targets          seo.Targets
+	chunk            bool // Condense True sources too large for the model
+	chunking         inference.ChunkingOptions
+	summarizeSamples bool // Send a style description instead of long Sample sources
+	params           inference.GenerationParams // Sampling settings of the preset in use
+}
 
-	v.customProgressDialog = dialog.NewCustom("Generation in Progress", "Dismiss", dialogContent, v.window)
-	v.customProgressDialog.SetDismissText("Please Wait")
-	v.customProgressDialog.Show()
-	v.dialogMutex.Unlock() // Unlock after showing the dialog
-	
-	// Generate content in a goroutine
-	go func() {
-		// --- Separate True and Sample Sources ---
-		var trueSourcesBuilder strings.Builder
-		var sampleSourcesBuilder strings.Builder
-		trueCount := 0
-		sampleCount := 0
+// runGeneration builds the prompt from the sources and generates content. It runs
+// as a background job; the progress dialog can be dismissed and the job followed
+// (or canceled) in the Activity tab.
+func (v *ContentGeneratorView) runGeneration(ctx context.Context, req generationRequest) error {
+	v.generationMutex.Lock()
+	if v.isGenerating {
+		v.generationMutex.Unlock()
+		return fmt.Errorf("a content generation task is already running")
+	}
+	v.isGenerating = true
+	v.generationMutex.Unlock()
 
-		for _, source := range v.sourceContents {
-			var builder *strings.Builder
-			var count *int
-
-			if source.IsSample {
-				builder = &sampleSourcesBuilder
-				count = &sampleCount
-			} else {
-				builder = &trueSourcesBuilder
-				count = &trueCount
-			}
+	// Ensure isGenerating is reset, log relay stopped, and dialog hidden when done.
+	defer func() {
+		v.generationMutex.Lock()
+		v.isGenerating = false
+		v.generationMutex.Unlock()
 
-			if *count > 0 {
-				builder.WriteString("\n\n--- Next Source ---\n\n")
-			}
-			builder.WriteString(fmt.Sprintf("Source Title: %s\n", source.Title))
-			builder.WriteString(fmt.Sprintf("Source Type: %s\n", source.Source)) // e.g., WordPress, File
-			builder.WriteString("Content:\n")
-			builder.WriteString(source.Content)
-			*count++
+		if v.generationLogRelay != nil {
+			v.generationLogRelay.Stop()
 		}
-		// --- End Separation ---
 
-		// Check if there are any true sources if generation requires them
-		if trueCount == 0 {
-			dialog.ShowError(fmt.Errorf("cannot generate content without at least one 'True Source' (uncheck 'Sample' for factual sources)"), v.window)
-			return
+		runOnUI(func() {
+			v.dialogMutex.Lock()
+			if v.customProgressDialog != nil {
+				v.customProgressDialog.Hide()
+				v.customProgressDialog = nil
+			}
+			v.dialogMutex.Unlock()
+		})
+	}()
+
+	// Setup progress dialog with log viewer
+	runOnUI(func() {
+		v.dialogMutex.Lock()
+		if v.customProgressDialog != nil {
+			v.customProgressDialog.Hide()
 		}
 
+		v.generationLogDisplay.SetText(i18n.T("Initializing generation process...\n"))
+
+		progressBar := widget.NewProgressBarInfinite()
+		logScroll := container.NewVScroll(v.generationLogDisplay)
+		logScroll.SetMinSize(fyne.NewSize(450, 200))
 
-		// --- Use the new prompt ---
-		finalPrompt := inference.GetWordPressContentGenerateWithSourcesPrompt(
-			trueSourcesBuilder.String(),
-			sampleSourcesBuilder.String(),
-			promptText,
+		dialogContent := container.NewVBox(
+			widget.NewLabelWithStyle(i18n.T("Generating Content with AI..."), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			progressBar,
+			widget.NewSeparator(),
+			container.NewHBox(widget.NewIcon(theme.InfoIcon()), widget.NewLabel(i18n.T("Backend Activity:"))),
+			logScroll,
 		)
-		// --- End Use New Prompt ---
-
-		v.logger.Printf("ContentGeneratorView: Sending to LLM. Model: %s, Instruction Length: %d, Final Prompt Length: %d", selectedModelName, len(instructionText), len(finalPrompt))
-		// Call the inference service
-		var generatedContent string
-		var err error
-		if selectedModelName == "MOA (Mixture of Agents)" {
-			generatedContent, err = v.inferenceService.GenerateTextWithMOA(finalPrompt, instructionText)
+
+		v.customProgressDialog = dialog.NewCustom(i18n.T("Generation in Progress"), i18n.T("Run in Background"), dialogContent, v.window)
+		v.customProgressDialog.Show()
+		v.dialogMutex.Unlock() // Unlock after showing the dialog
+	})
+
+	v.generationLogRelay = utils.NewLogRelay(func(logText string) {
+		runOnUI(func() { v.generationLogDisplay.SetText(logText) })
+	})
+	v.generationLogRelay.Start()
+
+	// --- Separate True and Sample Sources ---
+	// True sources are numbered in prompt order so the model's [n] markers
+	// can be linked back to them
+	var citedSources []editorial.CitedSource
+	var trueSourcesBuilder strings.Builder
+	var sampleSourcesBuilder strings.Builder
+	trueCount := 0
+	sampleCount := 0
+
+	for _, source := range req.sources {
+		if source.IsSample && req.voiceInstruction != "" {
+			continue // The voice profile stands in for the samples
+		}
+		var builder *strings.Builder
+		var count *int
+
+		if source.IsSample {
+			builder = &sampleSourcesBuilder
+			count = &sampleCount
 		} else {
-			generatedContent, err = v.inferenceService.GenerateText(selectedModelName, finalPrompt, instructionText)
+			builder = &trueSourcesBuilder
+			count = &trueCount
+		}
+
+		if *count > 0 {
+			builder.WriteString("\n\n--- Next Source ---\n\n")
+		}
+		builder.WriteString(fmt.Sprintf("Source Title: %s\n", source.Title))
+		if !source.IsSample && req.citations != editorial.CitationsNone {
+			citedSources = append(citedSources, editorial.CitedSource{Title: source.Title, Link: editorial.WebLink(source.Link)})
+			builder.WriteString(fmt.Sprintf("Source Number: [%d]\n", len(citedSources)))
+		}
+		builder.WriteString(fmt.Sprintf("Source Type: %s\n", source.Source)) // e.g., WordPress, File
+		builder.WriteString("Content:\n")
+		builder.WriteString(source.Content)
+		*count++
+	}
+	// --- End Separation ---
+
+	// Check if there are any true sources if generation requires them
+	if trueCount == 0 {
+		err := fmt.Errorf("cannot generate content without at least one 'True Source' (uncheck 'Sample' for factual sources)")
+		runOnUI(func() { ShowError(err, v.window) })
+		return err
+	}
+
+	// Site data variables are filled in now, so the draft keeps the template
+	promptText, instructionText, err := v.expandTemplateVariables(req.prompt, req.instruction)
+	if err != nil {
+		runOnUI(func() { ShowError(err, v.window) })
+		return err
+	}
+
+	// The site's instructions, voice profile, fact sheet, citation style, SEO
+	// targets and the style guide's rules go to the model with the user's
+	// instructions
+	guide := v.currentStyleGuide()
+	generationInstruction := instructionText
+	for _, extra := range []string{req.siteInstruction, req.voiceInstruction, req.factInstruction, req.citations.Instruction(), req.targets.Instruction(), guide.Instruction()} {
+		if extra != "" {
+			generationInstruction = strings.TrimSpace(generationInstruction + "\n\n" + extra)
+		}
+	}
+
+	// Long Sample sources can be replaced by a description of their style
+	sampleSources := sampleSourcesBuilder.String()
+	var samplesUsage inference.TokenUsage
+	if req.summarizeSamples && sampleCount > 0 {
+		style, styleUsage, err := v.summarizeSamples(ctx, sampleSources, sampleCount)
+		samplesUsage = styleUsage
+		if ctx.Err() != nil {
+			logger.Info("ContentGeneratorView: generation job was canceled while summarizing the samples")
+			return ctx.Err()
 		}
-		
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("failed to generate content: %w", err), v.window)
-			return
+			logger.Warn("ContentGeneratorView: sending the Sample sources as they are", "error", err)
+		} else {
+			sampleSources = style
 		}
-		
-		// Update the result output
-		v.resultOutput.SetText(generatedContent)
-		
+	}
+
+	// True sources too large for the model are condensed into notes first
+	rest := inference.GetWordPressContentGenerateWithSourcesPrompt("", sampleSources, promptText) + generationInstruction
+	// Sequentially condensed notes stream into the result pane until the
+	// article replaces them. Only the first update is undoable, so Undo
+	// still goes back to the previous result.
+	streamed := false // Read and written on the UI thread
+	showNotes := func(notes string) {
+		runOnUI(func() {
+			if streamed {
+				v.resultOutput.SetText(notes)
+				return
+			}
+			streamed = true
+			v.resultOutput.ReplaceText(notes)
+			v.faq = nil // Derived from the replaced result
+			v.currentDraftID = 0
+			v.saveToFileButton.Disable()
+			v.saveToWPButton.Disable()
+			v.publishPostButton.Disable()
+		})
+	}
+	trueSources, notesUsage, err := v.condenseOversizedSources(ctx, req, trueSourcesBuilder.String(), promptText, rest, showNotes)
+	if ctx.Err() != nil {
+		logger.Info("ContentGeneratorView: generation job was canceled while condensing sources")
+		return ctx.Err()
+	}
+	if err != nil {
+		runOnUI(func() { ShowError(err, v.window) })
+		return err
+	}
+
+	// --- Use the new prompt ---
+	finalPrompt := inference.GetWordPressContentGenerateWithSourcesPrompt(
+		trueSources,
+		sampleSources,
+		promptText,
+	)
+	// --- End Use New Prompt ---
+
+	logger.Info("ContentGeneratorView: sending to LLM", logging.Model(req.model), "instruction_chars", len(generationInstruction), "prompt_chars", len(finalPrompt))
+
+	// Call the inference service
+	opts := contentOptions(ctx, req.model)
+	opts.Instruction = generationInstruction
+	opts.Params = req.params
+	generatedContent, usage, err := v.inferenceService.GenerateWithUsage(finalPrompt, opts)
+	usage = usage.Plus(notesUsage).Plus(samplesUsage)
+
+	if ctx.Err() != nil {
+		logger.Info("ContentGeneratorView: generation job was canceled, discarding result")
+		return ctx.Err()
+	}
+	if err != nil {
+		runOnUI(func() { ShowError(fmt.Errorf("failed to generate content: %w", err), v.window) })
+		return err
+	}
+	generatedContent = editorial.ApplyCitations(generatedContent, req.citations, citedSources)
+	draftID := v.saveDraft(req.sources, req.prompt, req.instruction, req.model, generatedContent)
+	violations := guide.Check(generatedContent)
+	if len(violations) > 0 {
+		logger.Info("ContentGeneratorView: generated content breaks the style guide", "violations", len(violations))
+	}
+	findings := req.targets.Check(generatedContent)
+
+	runOnUI(func() {
+		// Update the result output; the previous result stays reachable via Undo
+		if streamed {
+			v.resultOutput.SetText(generatedContent) // Replaces the streamed notes
+		} else {
+			v.resultOutput.ReplaceText(generatedContent)
+		}
+		v.faq = nil // Derived from the replaced result
+		v.currentDraftID = draftID
+
 		// Enable save buttons
 		v.saveToFileButton.Enable()
 		v.saveToWPButton.Enable()
-		
-		// Show success dialog
-		dialog.ShowInformation("Success", "Content generated successfully", v.window)
-	}()
+		v.publishPostButton.Enable()
+
+		// Show the compliance pass, or success if there is nothing to fix
+		if len(violations) > 0 {
+			showStyleViolations(v.window, guide, generatedContent, v.resultOutput.ReplaceText)
+			return
+		}
+		message := i18n.T("Content generated successfully") + "\n" + tokenUsageSummary(usage)
+		if len(findings) > 0 {
+			message += "\n\n" + strings.Join(findings, "\n")
+		}
+		dialog.ShowInformation(i18n.T("Success"), message, v.window)
+	})
+	return nil
 }
 
 // saveGeneratedContentToFile saves the generated content to a file
@@ -530,14 +1621,14 @@ func (v *ContentGeneratorView) saveGeneratedContentToFile() {
 	// Get the generated content
 	generatedContent := v.resultOutput.Text
 	if generatedContent == "" {
-		dialog.ShowError(fmt.Errorf("no generated content to save"), v.window)
+		ShowError(fmt.Errorf("no generated content to save"), v.window)
 		return
 	}
 	
 	// Show file save dialog
 	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
 		if err != nil {
-			dialog.ShowError(err, v.window)
+			ShowError(err, v.window)
 			return
 		}
 		if writer == nil {
@@ -546,28 +1637,31 @@ func (v *ContentGeneratorView) saveGeneratedContentToFile() {
 		}
 		
 		// Show progress dialog
-		progress := dialog.NewProgressInfinite("Saving", "Saving content to file...", v.window)
+		progress := dialog.NewProgressInfinite(i18n.T("Saving"), i18n.T("Saving content to file..."), v.window)
 		progress.Show()
 		
 		// Save in a goroutine
 		go func() {
+			defer crash.Recover("ContentGeneratorView.saveGeneratedContentToFile")
 			defer writer.Close()
 			
 			// Write content to file
 			_, err := writer.Write([]byte(generatedContent))
 			
-			// Hide progress dialog
-			progress.Hide()
-			
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("failed to save file: %w", err), v.window)
-				return
-			}
-			
-			// Get file name from URI
-			fileName := filepath.Base(writer.URI().String())
-			
-			dialog.ShowInformation("Success", fmt.Sprintf("Content saved to file '%s'", fileName), v.window)
+			runOnUI(func() {
+				// Hide progress dialog
+				progress.Hide()
+
+				if err != nil {
+					ShowError(fmt.Errorf("failed to save file: %w", err), v.window)
+					return
+				}
+
+				// Get file name from URI
+				fileName := filepath.Base(writer.URI().String())
+
+				dialog.ShowInformation(i18n.T("Success"), i18n.Tf("Content saved to file '%s'", fileName), v.window)
+			})
 		}()
 	}, v.window)
 }
@@ -576,14 +1670,22 @@ func (v *ContentGeneratorView) saveGeneratedContentToFile() {
 func (v *ContentGeneratorView) saveGeneratedContent() {
 	// Check if WordPress service is connected
 	if !v.wpService.IsConnected() {
-		dialog.ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	if err := v.wpService.CanDo(wordpress.ActionPublish); err != nil {
+		ShowError(err, v.window)
 		return
 	}
 	
 	// Get the generated content
 	generatedContent := v.resultOutput.Text
 	if generatedContent == "" {
-		dialog.ShowError(fmt.Errorf("no generated content to save"), v.window)
+		ShowError(fmt.Errorf("no generated content to save"), v.window)
+		return
+	}
+	if requireApproval() && !v.approvedDraft(generatedContent) {
+		ShowError(fmt.Errorf("this content needs approval before it is saved to WordPress; use Review to submit it"), v.window)
 		return
 	}
 	
@@ -597,7 +1699,7 @@ func (v *ContentGeneratorView) saveGeneratedContent() {
 	
 	// If no WordPress pages found, show error
 	if len(wpPages) == 0 {
-		dialog.ShowError(fmt.Errorf("no WordPress pages found in source content"), v.window)
+		ShowError(fmt.Errorf("no WordPress pages found in source content"), v.window)
 		return
 	}
 	
@@ -613,7 +1715,7 @@ func (v *ContentGeneratorView) saveGeneratedContent() {
 		options = append(options, page.Title)
 	}
 	
-	dialog.ShowCustom("Select Page", "Cancel", widget.NewSelect(options, func(selected string) {
+	dialog.ShowCustom(i18n.T("Select Page"), i18n.T("Cancel"), widget.NewSelect(options, func(selected string) {
 		// Find the selected page
 		for _, page := range wpPages {
 			if page.Title == selected {
@@ -626,30 +1728,72 @@ func (v *ContentGeneratorView) saveGeneratedContent() {
 
 // confirmAndSaveToPage confirms and saves content to a WordPress page
 func (v *ContentGeneratorView) confirmAndSaveToPage(pageID int, pageTitle, content string) {
+	message := i18n.Tf("Are you sure you want to save this content to the page '%s'?", pageTitle)
+	if v.faq != nil {
+		message = i18n.Tf("Are you sure you want to save this content, with its FAQ section, to the page '%s'?", pageTitle)
+	}
+	messageLabel := widget.NewLabel(message)
+	messageLabel.Wrapping = fyne.TextWrapWord
+	extras, extraObjects := v.newSaveExtras(content, pageID)
+	confirmContent := container.NewVBox(append([]fyne.CanvasObject{messageLabel}, extraObjects...)...)
+
+	// An approved draft is marked published once saved
+	approvedID := 0
+	if v.approvedDraft(content) {
+		approvedID = v.currentDraftID
+	}
+
 	// Confirm before saving
-	dialog.ShowConfirm("Save to WordPress", fmt.Sprintf("Are you sure you want to save this content to the page '%s'?", pageTitle), func(confirmed bool) {
+	dialog.ShowCustomConfirm(i18n.T("Save to WordPress"), i18n.T("Yes"), i18n.T("No"), confirmContent, func(confirmed bool) {
 		if !confirmed {
 			return
 		}
+		content, err := extras.apply(content)
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
 		
 		// Show progress dialog
-		progress := dialog.NewProgressInfinite("Saving", "Saving content to WordPress...", v.window)
+		progress := dialog.NewProgressInfinite(i18n.T("Saving"), i18n.T("Saving content to WordPress..."), v.window)
 		progress.Show()
 		
-		// Save in a goroutine
-		go func() {
+		run := func(ctx context.Context, report jobs.ProgressFunc) error {
 			// Update the page content
-			err := v.wpService.UpdatePageContent(pageID, content)
-			
-			// Hide progress dialog
-			progress.Hide()
-			
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("failed to save content: %w", err), v.window)
-				return
+			err := v.wpService.UpdatePageContentContext(ctx, pageID, content)
+			queued := errors.Is(err, wordpress.ErrQueuedOffline)
+			if queued {
+				err = jobs.Deferred(err) // Saved, and reported, by the replay once back online
 			}
 			
-			dialog.ShowInformation("Success", fmt.Sprintf("Content saved to page '%s'", pageTitle), v.window)
-		}()
+			runOnUI(func() {
+				// Hide progress dialog
+				progress.Hide()
+
+				if queued {
+					showQueuedOffline(v.window)
+					return
+				}
+				if err != nil {
+					ShowError(fmt.Errorf("failed to save content: %w", err), v.window)
+					return
+				}
+				if approvedID != 0 {
+					if err := v.drafts.Move(approvedID, history.ReviewPublished, reviewerName(), fmt.Sprintf("Saved to page '%s'", pageTitle)); err != nil {
+						logger.Warn("ContentGeneratorView: failed to mark draft published", "draft_id", approvedID, "error", err)
+					}
+				}
+
+				dialog.ShowInformation(i18n.T("Success"), i18n.Tf("Content saved to page '%s'", pageTitle), v.window)
+			})
+			return err
+		}
+
+		// Save in the background
+		if v.jobQueue == nil {
+			crash.Go("ContentGeneratorView.confirmAndSaveToPage", func() { run(context.Background(), func(float64, string) {}) })
+			return
+		}
+		v.jobQueue.Submit("Page Update", pageTitle, run)
 	}, v.window)
 }
\ No newline at end of file
@@ -1,11 +1,20 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"os/exec"
+	"sort"
+	"time"
+	"unicode/utf8"
 
 	"sync" // Import sync package
+	"Inference_Engine/crash"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
 	"Inference_Engine/wordpress"
 
 	"fyne.io/fyne/v2"
@@ -13,6 +22,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -28,25 +38,59 @@ type ContentManagerView struct {
 
 	// Content UI elements
 	pageList          *widget.List
-	contentEditor     *widget.Entry
-	saveButton        *widget.Button
+	contentEditor     *EditorEntry
+	saveButton        *CapabilityButton
 	loadContentButton *widget.Button
+	structuredDataButton *widget.Button // Generates schema.org JSON-LD for the selected page
+	newsletterButton     *widget.Button // Converts the selected page into a newsletter
+	seriesButton         *widget.Button // Splits the selected page into a multi-part series
+	mergeButton          *widget.Button // Merges the selected page with others into one article
+	commentsButton       *widget.Button // Summarizes the selected page's comments
+	competitorButton     *widget.Button // Compares the selected page with a competitor's
+	actionButtons        []*widget.Button // Run the pageActions on the selected page
 	previewImage      *canvas.Image // For displaying image previews
+	capturePreviewButton *widget.Button // Captures a full-size preview on demand
+
+	// Page list search/filter controls
+	searchEntry    *widget.Entry
+	statusFilter   *widget.Select
+	authorFilter   *widget.Select
+	modifiedFilter *widget.Select
+	sortSelect     *widget.Select
+	filterLabel    *widget.Label
+	authorIDs      []int // The authors in authorFilter, after "All authors"
 
 	// Data
 	pages          wordpress.PageList
+	visiblePages   wordpress.PageList // pages after search/filter/sort, as shown in pageList
+	loadedBatches  int                // REST list batches loaded so far
+	totalBatches   int                // total REST list batches reported by the server
+	loadingBatch   bool               // a batch request is in flight
+	pageLoadMutex  sync.Mutex         // guards pages appends and the batch counters
 	selectedPageID int
 
+	contentTruncated bool // contentEditor holds a read-only preview, not the full page
+	aiEdited         bool // contentEditor has AI edits since the page was loaded, which need approval to be saved
+
+	// Page list thumbnails, captured one at a time in the background and cached on disk
+	thumbnails         map[int]fyne.Resource
+	thumbRequested     map[int]bool
+	thumbMutex         sync.Mutex
+	thumbQueue         chan wordpress.Page
+	thumbnailsDisabled bool
+
 	// Reference to content generator view (will be set after creation)
 	contentGeneratorView *ContentGeneratorView
+	jobQueue             *jobs.Queue // Runs page updates in the background; nil runs them directly
+	drafts               *history.Store // Where AI edits are reviewed before they are saved; nil disables saving them when approval is required
 	dialogMutex          sync.Mutex // ADDED: Mutex for dialog operations
 }
 
 // RefreshStatus updates the status label based on the current service connection state.
 func (v *ContentManagerView) RefreshStatus() {
 	if v.wpService == nil {
-		log.Println("ContentManagerView: WordPress service is nil, cannot refresh status.")
-		v.statusLabel.SetText("Status: Error (Service unavailable)")
+		logger.Warn("ContentManagerView: WordPress service is nil, cannot refresh status")
+		v.statusLabel.SetText(i18n.T("Status: Error (Service unavailable)"))
 		return
 	}
 
@@ -55,33 +99,59 @@ func (v *ContentManagerView) RefreshStatus() {
 		if siteName == "" {
 			siteName = "Connected Site" // Fallback if name isn't stored/retrieved
 		}
-		v.statusLabel.SetText(fmt.Sprintf("Status: Connected to %s", siteName))
+		v.statusLabel.SetText(i18n.Tf("Status: Connected to %s", siteName))
 		// --- ADD THIS: Call fetchPages when connected ---
 		// Only fetch if the list is currently empty to avoid redundant calls
 		// every time the tab is selected.
 		if len(v.pages) == 0 {
-			log.Println("ContentManagerView: Connected and page list empty, fetching pages...")
-			go v.fetchPages() // Fetch in the background
+			logger.Info("ContentManagerView: connected and page list empty, fetching pages")
+			v.fetchPages() // Fetches in the background
 		} else {
-			log.Println("ContentManagerView: Connected, pages already loaded.")
+			logger.Info("ContentManagerView: connected, pages already loaded")
 		}
 		// --- END OF ADDED CODE ---
 	} else {
-		v.statusLabel.SetText("Status: Disconnected")
+		v.statusLabel.SetText(i18n.T("Status: Disconnected"))
 		// Clear page list if disconnected
 		if len(v.pages) > 0 { // Only clear if not already empty
-			log.Println("ContentManagerView: Disconnected, clearing page list.")
+			logger.Info("ContentManagerView: disconnected, clearing page list")
+			v.pageLoadMutex.Lock()
 			v.pages = nil
-			v.pageList.Refresh()
+			v.loadedBatches, v.totalBatches = 0, 0
+			v.pageLoadMutex.Unlock()
+			v.applyFilters()
 			v.contentEditor.SetText("")
+			v.contentEditor.ClearHistory()
 			v.saveButton.Disable()
 			v.loadContentButton.Disable()
+			v.structuredDataButton.Disable()
+			v.newsletterButton.Disable()
+			v.competitorButton.Disable()
 			v.selectedPageID = -1 // Reset selected ID
 		}
 	}
 	v.statusLabel.Refresh()
 }
 
+// SiteChanged drops the cached pages of the previous site and reloads the list
+// for the current connection.
+func (v *ContentManagerView) SiteChanged() {
+	v.pageLoadMutex.Lock()
+	v.pages = nil
+	v.loadedBatches, v.totalBatches = 0, 0
+	v.pageLoadMutex.Unlock()
+	v.selectedPageID = -1
+	v.contentEditor.SetText("")
+	v.contentEditor.ClearHistory()
+	v.saveButton.Disable()
+	v.loadContentButton.Disable()
+	v.structuredDataButton.Disable()
+	v.newsletterButton.Disable()
+	v.competitorButton.Disable()
+	v.applyFilters()
+	v.RefreshStatus()
+}
+
 // NewContentManagerView creates a new WordPress content manager view
 func NewContentManagerView(wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, window fyne.Window) *ContentManagerView {
 	view := &ContentManagerView{
@@ -98,47 +168,90 @@ func NewContentManagerView(wpService *wordpress.WordPressService, inferenceServi
 // initialize initializes the content manager view
 func (v *ContentManagerView) initialize() {
 	// Create status label
-	v.statusLabel = widget.NewLabel("Wordpress Connection Status: Initializing...")
+	v.statusLabel = widget.NewLabel(i18n.T("Wordpress Connection Status: Initializing..."))
 
 	// Create content UI elements
 	v.pageList = widget.NewList(
 		func() int {
-			return len(v.pages)
+			return len(v.visiblePages)
 		},
 		func() fyne.CanvasObject {
-			return widget.NewLabel("Template Page Title")
+			thumb := &canvas.Image{FillMode: canvas.ImageFillContain}
+			thumb.SetMinSize(fyne.NewSize(64, 40))
+			return newReadingOrderBorder(nil, nil, thumb, nil, widget.NewLabel("Template Page Title"))
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			if id < len(v.pages) {
-				obj.(*widget.Label).SetText(v.pages[id].Title)
+			if id < len(v.visiblePages) {
+				row := obj.(*fyne.Container)
+				row.Objects[0].(*widget.Label).SetText(v.visiblePages[id].Title)
+				thumb := row.Objects[1].(*canvas.Image)
+				thumb.Resource = v.pageThumbnail(v.visiblePages[id])
+				thumb.Refresh()
+			}
+			// Fetch the next batch when an unfiltered list is scrolled near its end
+			if id >= len(v.visiblePages)-lazyLoadThreshold && v.currentFilter().IsEmpty() {
+				v.loadMorePages()
 			}
 		},
 	)
 
 	v.pageList.OnSelected = func(id widget.ListItemID) {
-		if id < len(v.pages) {
-			v.loadPageContent(v.pages[id].ID)
-			// Load preview if link is available
-			if v.pages[id].Link != "" {
-				v.loadPagePreview(v.pages[id].Link)
-			}
+		if id < len(v.visiblePages) {
+			v.loadPageContent(v.visiblePages[id].ID)
+			// Show a cached preview; full-size captures are only made on request
+			v.showCachedPreview(v.visiblePages[id])
 		}
 	}
 
-	v.contentEditor = widget.NewMultiLineEntry()
-	v.contentEditor.SetPlaceHolder("Page content will appear here...")
+	v.contentEditor = NewEditorEntry()
+	v.contentEditor.SetPlaceHolder(i18n.T("Page content will appear here..."))
 	v.contentEditor.Wrapping = fyne.TextWrapWord
+	v.contentEditor.OnAIEdit = func() { v.aiEdited = true }
 
-	v.saveButton = widget.NewButton("Save Content", func() {
+	v.saveButton = newCapabilityButton(i18n.T("Save Content"), wordpress.ActionPublish, func() {
 		v.savePageContent()
 	})
 	v.saveButton.Disable() // Disable until a page is selected
 
-	v.loadContentButton = widget.NewButton("Load to Generator", func() {
+	v.loadContentButton = widget.NewButton(i18n.T("Load to Generator"), func() {
 		v.loadSelectedContentToGenerator()
 	})
 	v.loadContentButton.Disable() // Disable until a page is selected
 
+	v.structuredDataButton = widget.NewButton(i18n.T("Structured Data"), func() {
+		if page := v.GetPageByID(v.selectedPageID); page != nil {
+			showStructuredDataDialog(*page, v.wpService, v.inferenceService, v.jobQueue, v.drafts, v.window)
+		}
+	})
+	v.structuredDataButton.Disable() // Disable until a page is selected
+
+	v.newsletterButton = widget.NewButton(i18n.T("Newsletter"), v.convertPageToNewsletter)
+	v.newsletterButton.Disable() // Disable until a page is selected
+
+	v.seriesButton = widget.NewButton(i18n.T("Series"), v.splitIntoSeries)
+	v.seriesButton.Disable() // Disable until a page is selected
+
+	v.mergeButton = widget.NewButton(i18n.T("Merge..."), v.mergeWithOtherPages)
+	v.mergeButton.Disable() // Disable until a page is selected
+
+	v.commentsButton = widget.NewButton(i18n.T("Comments"), v.digestComments)
+	v.commentsButton.Disable() // Disable until a page is selected
+
+	v.competitorButton = widget.NewButton(i18n.T("Competitor"), func() {
+		if page := v.GetPageByID(v.selectedPageID); page != nil {
+			compareWithCompetitor(v.window, v.wpService, v.inferenceService, v.jobQueue, v.contentGeneratorView, *page)
+		}
+	})
+	v.competitorButton.Disable() // Disable until a page is selected
+
+	actions := container.NewHBox()
+	for _, action := range pageActions {
+		button := widget.NewButton(i18n.T(action.Name), func() { v.runPageAction(action) })
+		button.Disable() // Disable until a page is selected
+		v.actionButtons = append(v.actionButtons, button)
+		actions.Add(button)
+	}
+
 	// Initialize preview image
 	v.previewImage = &canvas.Image{
 		FillMode:  canvas.ImageFillOriginal,
@@ -147,37 +260,70 @@ func (v *ContentManagerView) initialize() {
 
 	v.previewImage.SetMinSize(fyne.NewSize(600, 350)) // Example: Set minimum width 200, height 150
 
+	v.capturePreviewButton = widget.NewButton(i18n.T("Capture Preview"), func() {
+		if page := v.GetPageByID(v.selectedPageID); page != nil {
+			v.loadPagePreview(*page)
+		}
+	})
+	v.capturePreviewButton.Disable() // Enabled when a page without a cached preview is selected
+
+	v.thumbnails = map[int]fyne.Resource{}
+	v.thumbRequested = map[int]bool{}
+	v.thumbQueue = make(chan wordpress.Page, 100)
+	crash.Go("ContentManagerView.thumbnailWorker", v.thumbnailWorker)
+
+	var selectionButton *widget.Button
+	selectionButton = widget.NewButtonWithIcon(i18n.T("Edit Selection"), theme.DocumentCreateIcon(), func() {
+		showSelectionActions(v.window, v.inferenceService, v.jobQueue, v.contentEditor, selectionButton, "")
+	})
+
 	// Create layout
 	editorAndPreview := container.NewVSplit(
 		container.NewScroll(v.contentEditor),
-		container.NewBorder(
-			widget.NewLabel("Preview:"),
+		newReadingOrderBorder(
+			newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Preview:")), container.NewHBox(
+				newCopyButton(v.window, i18n.T("Copy URL"), func() string {
+					if page := v.GetPageByID(v.selectedPageID); page != nil {
+						return page.Link
+					}
+					return ""
+				}),
+				v.capturePreviewButton,
+			)),
 			nil, nil, nil,
 			container.NewScroll(v.previewImage),
 		),
 	)
 	editorAndPreview.Offset = 0.2 // 20% editor, 80% preview
-
-	rightPanel := container.NewBorder(
-		widget.NewLabel("Content:"),
-		container.NewHBox(layout.NewSpacer(), v.saveButton, v.loadContentButton),
+	rememberSplit("manager.editor", editorAndPreview)
+
+	rightPanel := newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Content:")),
+		container.NewHBox(
+			widget.NewButtonWithIcon(i18n.T("Undo"), theme.ContentUndoIcon(), v.contentEditor.Undo),
+			widget.NewButtonWithIcon(i18n.T("Redo"), theme.ContentRedoIcon(), v.contentEditor.Redo),
+			selectionButton,
+			layout.NewSpacer(), actions, v.structuredDataButton, v.newsletterButton, v.seriesButton, v.mergeButton, v.commentsButton, v.competitorButton, v.saveButton, v.loadContentButton),
 		nil,
 		nil,
 		editorAndPreview,
 	)
 
+	v.initializeFilters()
+
 	contentContainer := container.NewHSplit(
-		container.NewBorder(
-			widget.NewLabel("Pages:"),
-			nil, nil, nil,
+		newReadingOrderBorder(
+			container.NewVBox(widget.NewLabel(i18n.T("Pages:")), v.searchEntry, v.statusFilter, v.authorFilter, v.modifiedFilter, v.sortSelect),
+			v.filterLabel, nil, nil,
 			container.NewScroll(v.pageList),
 		),
 		rightPanel,
 	)
 	contentContainer.SetOffset(0.2) // 20% for page list, 80% for content editor
+	rememberSplit("manager.pages", contentContainer)
 
 	// Main layout with status label at top
-	v.container = container.NewBorder(
+	v.container = newReadingOrderBorder(
 		v.statusLabel,
 		nil,
 		nil,
@@ -187,74 +333,186 @@ func (v *ContentManagerView) initialize() {
 	v.RefreshStatus()
 }
 
-// fetchPages fetches the list of pages from the WordPress site
+// pageBatchSize is how many pages are requested per REST call when loading the list
+const pageBatchSize = 50
+
+// lazyLoadThreshold is how close to the end of the list (in rows) the next batch is requested
+const lazyLoadThreshold = 10
+
+// fetchPages fetches the first batch of pages from the WordPress site.
+// Further batches are loaded on demand as the user scrolls (see loadMorePages).
 func (v *ContentManagerView) fetchPages() {
 	// Show progress dialog
-	progress := dialog.NewProgressInfinite("Fetching", "Fetching pages...", v.window)
+	progress := dialog.NewProgressInfinite(i18n.T("Fetching"), i18n.T("Fetching pages..."), v.window)
 	progress.Show()
 
 	// Fetch pages in a goroutine
 	go func() {
+		defer crash.Recover("ContentManagerView.fetchPages")
 		// Fetch data first
-		pages, err := v.wpService.GetPages(1, 10) // Get first batch with 10 pages
+		pages, totalBatches, err := v.wpService.GetPagesBatch(1, pageBatchSize)
 
-		// --- UI Updates Start Here ---
-		// Hide the progress dialog *before* potentially showing another dialog or updating UI
-		progress.Hide()
-
-		// Now handle results and update UI
-		if err != nil {
-			log.Printf("Error fetching pages: %v", err)
-			// Show error dialog *after* hiding progress
-			dialog.ShowError(fmt.Errorf("failed to fetch pages: %w", err), v.window)
-			return // Exit goroutine after showing error
-		}
+		runOnUI(func() {
+			// --- UI Updates Start Here ---
+			// Hide the progress dialog *before* potentially showing another dialog or updating UI
+			progress.Hide()
 
-		// Update non-dialog UI elements (Ideally queue these)
-		v.pages = pages
-		v.pageList.Refresh() // Refresh the list data
+			// Now handle results and update UI
+			if err != nil {
+				logger.Error("ContentManagerView: error fetching pages", "error", err)
+				// Show error dialog *after* hiding progress
+				ShowError(fmt.Errorf("failed to fetch pages: %w", err), v.window)
+				return // Exit goroutine after showing error
+			}
 
-		// Show success dialog *after* progress is hidden
-		dialog.ShowInformation("Success", fmt.Sprintf("Fetched %d pages", len(pages)), v.window)
+			// Update non-dialog UI elements (Ideally queue these)
+			v.pageLoadMutex.Lock()
+			v.pages = pages
+			v.loadedBatches = 1
+			v.totalBatches = totalBatches
+			v.pageLoadMutex.Unlock()
+			v.refreshAuthorOptions()
+			v.applyFilters() // Refresh the list data
+
+			// Show success dialog *after* progress is hidden
+			message := i18n.Tf("Fetched %d pages", len(pages))
+			if v.hasMorePages() {
+				message += "\nMore pages will load as you scroll the list."
+			}
+			dialog.ShowInformation(i18n.T("Success"), message, v.window)
+		})
 
 	}() // End of goroutine
 }
 
+// hasMorePages reports whether there are page batches left to load from the server
+func (v *ContentManagerView) hasMorePages() bool {
+	v.pageLoadMutex.Lock()
+	defer v.pageLoadMutex.Unlock()
+	return v.loadedBatches < v.totalBatches
+}
+
+// loadMorePages fetches the next batch of pages in the background, if any remain
+// and no other batch is in flight. Called when the list is scrolled near its end.
+func (v *ContentManagerView) loadMorePages() {
+	v.pageLoadMutex.Lock()
+	if v.loadingBatch || v.loadedBatches == 0 || v.loadedBatches >= v.totalBatches {
+		v.pageLoadMutex.Unlock()
+		return
+	}
+	v.loadingBatch = true
+	nextBatch := v.loadedBatches + 1
+	v.pageLoadMutex.Unlock()
+
+	v.filterLabel.SetText(i18n.Tf("%d pages loaded, loading more...", len(v.pages)))
+
+	go func() {
+		defer crash.Recover("ContentManagerView.loadMorePages")
+		pages, totalBatches, err := v.wpService.GetPagesBatch(nextBatch, pageBatchSize)
+
+		runOnUI(func() {
+			v.pageLoadMutex.Lock()
+			v.loadingBatch = false
+			if err != nil {
+				v.pageLoadMutex.Unlock()
+				logger.Error("ContentManagerView: failed to load page batch", "batch", nextBatch, "error", err)
+				v.filterLabel.SetText(i18n.Tf("%d pages loaded (failed to load more)", len(v.pages)))
+				return
+			}
+			added := 0
+			for _, page := range pages {
+				if v.GetPageByID(page.ID) == nil { // Skip pages already merged in by a server search
+					v.pages = append(v.pages, page)
+					added++
+				}
+			}
+			v.loadedBatches = nextBatch
+			v.totalBatches = totalBatches
+			v.pageLoadMutex.Unlock()
+
+			logger.Info("ContentManagerView: loaded page batch", "batch", nextBatch, "total_batches", totalBatches, "new_pages", added)
+			v.refreshAuthorOptions()
+			v.refreshVisiblePages()
+		})
+	}()
+}
+
+// maxEditableLength is the largest page (in bytes) loaded into the editor for editing
+const maxEditableLength = 1 << 20
+
+// previewLength is how much of an oversized page is shown as a read-only preview
+const previewLength = 20000
+
+// truncateUTF8 shortens s to at most n bytes without splitting a UTF-8 character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 // loadPageContent loads the content of the selected page
 func (v *ContentManagerView) loadPageContent(pageID int) {
 	// Show progress dialog
-	progress := dialog.NewProgressInfinite("Loading", "Loading page content...", v.window)
+	progress := dialog.NewProgressInfinite(i18n.T("Loading"), i18n.T("Loading page content..."), v.window)
 	progress.Show()
 
 	// Load content in a goroutine
 	go func() {
+		defer crash.Recover("ContentManagerView.loadPageContent")
 		// Perform the content loading logic
 		content, err := v.wpService.GetPageContent(pageID)
 
-		// --- UI Updates Start Here ---
-		// Hide the progress dialog *before* potentially showing another dialog or updating UI
-		progress.Hide()
-
-		if err != nil {
-			log.Printf("Error loading page content: %v", err)
-			// Show error dialog *after* hiding progress
-			dialog.ShowError(fmt.Errorf("failed to load page content: %w", err), v.window)
-			return // Exit goroutine
-		}
+		runOnUI(func() {
+			// --- UI Updates Start Here ---
+			// Hide the progress dialog *before* potentially showing another dialog or updating UI
+			progress.Hide()
 
-		const maxDisplayLength = 5000 // Adjust as needed, slightly less than capacity
-		displayContent := content
-		if len(content) > maxDisplayLength {
-			log.Printf("Truncating content for display (original length: %d)", len(content))
-			displayContent = content[:maxDisplayLength] + "\n... (Content Truncated)"
-		}
+			if err != nil {
+				logger.Error("ContentManagerView: error loading page content", "page_id", pageID, "error", err)
+				// Show error dialog *after* hiding progress
+				ShowError(fmt.Errorf("failed to load page content: %w", err), v.window)
+				return // Exit goroutine
+			}
 
-		log.Printf("Loading content for page %d, display length: %d", pageID, len(displayContent))
+			// The full content is edited; only pages too large for the editor get a
+			// read-only preview, and saving is blocked so the page can't be overwritten
+			// with a truncated copy.
+			displayContent := content
+			v.contentTruncated = len(content) > maxEditableLength
+			if v.contentTruncated {
+				logger.Warn("ContentManagerView: page is too large to edit, showing read-only preview", "page_id", pageID, "bytes", len(content))
+				displayContent = truncateUTF8(content, previewLength) +
+					fmt.Sprintf("\n\n... (Read-only preview: page is %d bytes. Edit it in WordPress, or use \"Load to Generator\".)", len(content))
+			}
 
-		v.contentEditor.SetText(displayContent) // Use truncated content
-		v.selectedPageID = pageID
-		v.saveButton.Enable()
-		v.loadContentButton.Enable()
+			logger.Info("ContentManagerView: loading page content", "page_id", pageID, "display_chars", len(displayContent))
+
+			v.contentEditor.SetText(displayContent)
+			v.contentEditor.ClearHistory() // Don't let Undo bring back another page's content
+			v.selectedPageID = pageID
+			v.aiEdited = false
+			if v.contentTruncated {
+				v.contentEditor.Disable()
+				v.saveButton.Disable()
+			} else {
+				v.contentEditor.Enable()
+				v.saveButton.Enable()
+			}
+			v.loadContentButton.Enable()
+			v.structuredDataButton.Enable()
+			v.newsletterButton.Enable()
+			v.seriesButton.Enable()
+			v.mergeButton.Enable()
+			v.commentsButton.Enable()
+			v.competitorButton.Enable()
+			for _, button := range v.actionButtons {
+				button.Enable()
+			}
+		})
 
 	}() // End of goroutine
 }
@@ -262,53 +520,117 @@ func (v *ContentManagerView) loadPageContent(pageID int) {
 // savePageContent saves the edited content back to the WordPress site
 func (v *ContentManagerView) savePageContent() {
 	if v.selectedPageID < 0 {
-		dialog.ShowError(fmt.Errorf("no page selected"), v.window)
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+
+	if v.contentTruncated {
+		ShowError(fmt.Errorf("this page is too large to edit here and only a preview is loaded; saving would overwrite it with truncated content"), v.window)
 		return
 	}
 
 	content := v.contentEditor.Text
+	pageID := v.selectedPageID
 
 	// Confirm before saving
-	dialog.ShowConfirm("Save Changes", "Are you sure you want to save these changes to the WordPress page?", func(confirmed bool) {
-		if !confirmed {
-			return
-		}
+	save := func() {
+		dialog.ShowConfirm(i18n.T("Save Changes"), i18n.T("Are you sure you want to save these changes to the WordPress page?"), func(confirmed bool) {
+			if confirmed {
+				v.savePage(pageID, content)
+			}
+		}, v.window)
+	}
+	if v.aiEdited {
+		saveIfApproved(v.drafts, v.window, content, fmt.Sprintf("AI edits to '%s'", v.GetSelectedPageTitle()), save)
+		return
+	}
+	save()
+}
 
-		// Show progress dialog
-		progress := dialog.NewProgressInfinite("Saving", "Saving page content...", v.window)
-		progress.Show()
+// savePage saves content as the content of the page pageID in the background
+func (v *ContentManagerView) savePage(pageID int, content string) {
+	// Show progress dialog
+	progress := dialog.NewProgressInfinite(i18n.T("Saving"), i18n.T("Saving page content..."), v.window)
+	progress.Show()
 
-		// Save content in a goroutine
-		go func() {
-			// Perform the save operation
-			err := v.wpService.UpdatePageContent(v.selectedPageID, content)
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		// Perform the save operation
+		err := v.wpService.UpdatePageContentContext(ctx, pageID, content)
+		queued := errors.Is(err, wordpress.ErrQueuedOffline)
+		if queued {
+			err = jobs.Deferred(err) // Saved, and reported, by the replay once back online
+		}
 
-			// --- UI Updates Start Here ---
+		if err != nil && !queued {
+			logger.Error("ContentManagerView: error saving page content", "page_id", pageID, "error", err)
+		}
+
+		// --- UI Updates Start Here ---
+		runOnUI(func() {
 			// Hide the progress dialog *before* potentially showing another dialog
 			progress.Hide()
 
+			if queued {
+				showQueuedOffline(v.window)
+				return
+			}
 			if err != nil {
-				log.Printf("Error saving page content: %v", err)
 				// Show error dialog *after* hiding progress
-				dialog.ShowError(fmt.Errorf("failed to save page content: %w", err), v.window)
-				return // Exit goroutine
+				ShowError(fmt.Errorf("failed to save page content: %w", err), v.window)
+				return
 			}
 
 			// Show success dialog *after* hiding progress
-			dialog.ShowInformation("Success", "Page content saved successfully", v.window)
-		}() // End of goroutine
-	}, v.window)
+			dialog.ShowInformation(i18n.T("Success"), i18n.T("Page content saved successfully"), v.window)
+		})
+		return err
+	}
+
+	// Save content in the background
+	if v.jobQueue == nil {
+		crash.Go("ContentManagerView.savePage", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Page Update", i18n.Tf("Save page %d", pageID), run)
+}
+
+// convertPageToNewsletter rewrites the selected page as a newsletter whose
+// button links to the page. Pages too large for the editor are fetched in full.
+func (v *ContentManagerView) convertPageToNewsletter() {
+	page := v.GetPageByID(v.selectedPageID)
+	if page == nil {
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+	if !v.contentTruncated {
+		convertToNewsletter(v.window, v.inferenceService, v.jobQueue, v.contentEditor.Text, page.Link, "")
+		return
+	}
+	progress := dialog.NewProgressInfinite(i18n.T("Loading"), i18n.T("Loading page content..."), v.window)
+	progress.Show()
+	go func() {
+		defer crash.Recover("ContentManagerView.convertPageToNewsletter")
+		content, err := v.wpService.GetPageContent(page.ID)
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				ShowError(fmt.Errorf("failed to load page content: %w", err), v.window)
+				return
+			}
+			convertToNewsletter(v.window, v.inferenceService, v.jobQueue, content, page.Link, "")
+		})
+	}()
 }
 
 // loadSelectedContentToGenerator fetches the *text* content for the selected page,
 // sends it to the generator view, and then clears the manager view.
 func (v *ContentManagerView) loadSelectedContentToGenerator() {
 	if v.selectedPageID < 0 {
-		dialog.ShowError(fmt.Errorf("no page selected"), v.window)
+		ShowError(fmt.Errorf("no page selected"), v.window)
 		return
 	}
 	if v.contentGeneratorView == nil {
-		dialog.ShowError(fmt.Errorf("content generator view not available"), v.window)
+		ShowError(fmt.Errorf("content generator view not available"), v.window)
 		return
 	}
 
@@ -321,52 +643,76 @@ func (v *ContentManagerView) loadSelectedContentToGenerator() {
 		}
 	}
 	if selectedPage == nil {
-		dialog.ShowError(fmt.Errorf("selected page details not found"), v.window)
+		ShowError(fmt.Errorf("selected page details not found"), v.window)
 		return
 	}
 
 	// Fetch the actual content (text) on demand
-	progress := dialog.NewProgressInfinite("Loading Content", "Fetching page content for generator...", v.window)
+	progress := dialog.NewProgressInfinite(i18n.T("Loading Content"), i18n.T("Fetching page content for generator..."), v.window)
 	progress.Show()
 
+	pageID := v.selectedPageID
 	go func() {
-		defer progress.Hide()
-		content, err := v.wpService.GetPageContent(v.selectedPageID) // Still need this function!
-		if err != nil {
-			log.Printf("Error loading page content for generator: %v", err)
-			dialog.ShowError(fmt.Errorf("failed to load content for '%s': %w", selectedPage.Title, err), v.window)
-			return
-		}
+		defer crash.Recover("ContentManagerView.loadSelectedContentToGenerator")
+		content, err := v.wpService.GetPageContent(pageID) // Still need this function!
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				logger.Error("ContentManagerView: error loading page content for generator", "page_id", pageID, "error", err)
+				ShowError(fmt.Errorf("failed to load content for '%s': %w", selectedPage.Title, err), v.window)
+				return
+			}
 
-		// Add the fetched content to the generator
-		v.contentGeneratorView.AddSourceContent(
-			selectedPage.Title,
-			content, // The actual text content
-			"WordPress",
-			selectedPage.ID,
-			false,
-		)
-
-		// --- Add code to clear the UI elements ---
-		v.contentEditor.SetText("")    // Clear the editor
-		v.previewImage.Resource = nil  // Clear the preview image resource
-		v.previewImage.Refresh()       // Refresh the image widget
-		v.selectedPageID = -1          // Reset selected ID
-		v.saveButton.Disable()         // Disable save button
-		v.loadContentButton.Disable()  // Disable load button
-		v.pageList.UnselectAll()       // Unselect item in the list
-		log.Println("ContentManagerView: Cleared editor and preview after loading to generator.")
-		// --- End of added code ---
-
-		dialog.ShowInformation("Content Added", fmt.Sprintf("Added content of '%s' to content generator and cleared manager view.", selectedPage.Title), v.window)
+			// Add the fetched content to the generator
+			v.contentGeneratorView.AddSourceContent(
+				selectedPage.Title,
+				content, // The actual text content
+				"WordPress",
+				selectedPage.Link,
+				selectedPage.ID,
+				false,
+			)
+
+			// --- Add code to clear the UI elements ---
+			v.contentEditor.SetText("")    // Clear the editor
+			v.contentEditor.ClearHistory()
+			v.previewImage.Resource = nil  // Clear the preview image resource
+			v.previewImage.Refresh()       // Refresh the image widget
+			v.selectedPageID = -1          // Reset selected ID
+			v.saveButton.Disable()         // Disable save button
+			v.loadContentButton.Disable()  // Disable load button
+			v.structuredDataButton.Disable()
+			v.newsletterButton.Disable()
+			v.competitorButton.Disable()
+			v.pageList.UnselectAll()       // Unselect item in the list
+			logger.Info("ContentManagerView: cleared editor and preview after loading to generator")
+			// --- End of added code ---
+
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.Tf("Added content of '%s' to content generator and cleared manager view.", selectedPage.Title), v.window)
+		})
 	}()
 }
 
+// SetJobQueue sets the queue that page updates are submitted to
+func (v *ContentManagerView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetDraftHistory sets the store that AI edits are reviewed in
+func (v *ContentManagerView) SetDraftHistory(store *history.Store) {
+	v.drafts = store
+}
+
 // SetContentGeneratorView sets the reference to the content generator view
 func (v *ContentManagerView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
 	v.contentGeneratorView = generatorView
 }
 
+// FocusRegions returns the page search, page list and editor for Ctrl+F6 focus cycling
+func (v *ContentManagerView) FocusRegions() []fyne.Focusable {
+	return []fyne.Focusable{v.searchEntry, v.pageList, v.contentEditor}
+}
+
 // Container returns the container for the content manager view
 func (v *ContentManagerView) Container() fyne.CanvasObject {
 	return v.container
@@ -399,7 +745,7 @@ func (v *ContentManagerView) GetPageByID(id int) *wordpress.Page {
 
 // SelectPageByID selects a page in the list by its ID
 func (v *ContentManagerView) SelectPageByID(id int) {
-	for i, page := range v.pages {
+	for i, page := range v.visiblePages {
 		if page.ID == id {
 			v.pageList.Select(i)
 			break
@@ -409,19 +755,43 @@ func (v *ContentManagerView) SelectPageByID(id int) {
 
 // SelectPageByIndex selects a page in the list by its index
 func (v *ContentManagerView) SelectPageByIndex(index int) {
-	if index >= 0 && index < len(v.pages) {
+	if index >= 0 && index < len(v.visiblePages) {
 		v.pageList.Select(index)
 	}
 }
 
+// FocusPageSearch focuses the page list search box
+func (v *ContentManagerView) FocusPageSearch() {
+	v.window.Canvas().Focus(v.searchEntry)
+}
+
 // GetPageCount returns the number of pages
 func (v *ContentManagerView) GetPageCount() int {
 	return len(v.pages)
 }
 
-// loadPagePreview triggers the screenshot capture and updates the image widget.
-func (v *ContentManagerView) loadPagePreview(pageURL string) {
-	if pageURL == "" {
+// showCachedPreview shows a page's cached full-size screenshot, falling back to
+// its thumbnail, and enables the capture button when no full-size preview exists.
+func (v *ContentManagerView) showCachedPreview(page wordpress.Page) {
+	if page.Link == "" {
+		v.previewImage.Resource = nil
+		v.previewImage.Refresh()
+		v.capturePreviewButton.Disable()
+		return
+	}
+	if imgBytes, ok := v.wpService.GetCachedPageScreenshot(page); ok {
+		v.previewImage.Resource = fyne.NewStaticResource(fmt.Sprintf("preview_%d.jpg", page.ID), imgBytes)
+		v.capturePreviewButton.Disable()
+	} else {
+		v.previewImage.Resource = v.pageThumbnail(page) // May be nil until the thumbnail is captured
+		v.capturePreviewButton.Enable()
+	}
+	v.previewImage.Refresh()
+}
+
+// loadPagePreview captures (or loads from the cache) a full-size screenshot and updates the image widget.
+func (v *ContentManagerView) loadPagePreview(page wordpress.Page) {
+	if page.Link == "" {
 		v.previewImage.Resource = nil // Clear image if no URL
 		v.previewImage.Refresh()
 		return
@@ -429,7 +799,7 @@ func (v *ContentManagerView) loadPagePreview(pageURL string) {
 
 	// Show progress indicator
 	v.dialogMutex.Lock() // Lock before showing dialog
-	progress := dialog.NewProgressInfinite("Loading Preview", "Capturing page screenshot...", v.window)
+	progress := dialog.NewProgressInfinite(i18n.T("Loading Preview"), i18n.T("Capturing page screenshot..."), v.window)
 	progress.Show()
 	v.dialogMutex.Unlock() // Unlock after showing
 
@@ -437,31 +807,231 @@ func (v *ContentManagerView) loadPagePreview(pageURL string) {
 	v.previewImage.Refresh()
 
 	go func() {
-		// Don't use defer for hiding; hide explicitly before showing other dialogs.
-		// defer progress.Hide()
+		defer crash.Recover("ContentManagerView.loadPagePreview")
+		imgBytes, err := v.wpService.GetPageScreenshotCached(page)
 
-		imgBytes, err := v.wpService.GetPageScreenshot(pageURL)
-		// Hide progress *before* potentially showing an error dialog.
+		runOnUI(func() {
+			// Hide progress *before* potentially showing an error dialog.
+			v.dialogMutex.Lock()
+			progress.Hide()
+			v.dialogMutex.Unlock()
 
-		v.dialogMutex.Lock() // Lock before hiding/showing next dialog
-		progress.Hide()
-		if err != nil {
-			log.Printf("Error getting page screenshot: %v", err)
-			dialog.ShowError(fmt.Errorf("failed to load preview for %s: %w", pageURL, err), v.window)
-			v.dialogMutex.Unlock() // Unlock after showing error
-			v.previewImage.Resource = nil // Ensure image is cleared on error
+			if err != nil {
+				logger.Error("ContentManagerView: error getting page screenshot", "page_id", page.ID, "error", err)
+				ShowError(fmt.Errorf("failed to load preview for %s: %w", page.Link, err), v.window)
+				v.previewImage.Resource = nil // Ensure image is cleared on error
+				v.previewImage.Refresh()
+				return
+			}
+			if page.ID != v.selectedPageID {
+				return // Another page was selected while capturing
+			}
+
+			// Update the image widget
+			v.previewImage.Resource = fyne.NewStaticResource(fmt.Sprintf("preview_%d.jpg", page.ID), imgBytes)
 			v.previewImage.Refresh()
+			v.capturePreviewButton.Disable()
+		})
+	}()
+}
 
-			return
+// pageThumbnail returns the thumbnail for a page if it is loaded, otherwise
+// queues it for the background worker and returns nil.
+func (v *ContentManagerView) pageThumbnail(page wordpress.Page) fyne.Resource {
+	v.thumbMutex.Lock()
+	defer v.thumbMutex.Unlock()
+	if res, ok := v.thumbnails[page.ID]; ok {
+		return res
+	}
+	if v.thumbnailsDisabled || page.Link == "" || v.thumbRequested[page.ID] {
+		return nil
+	}
+	select {
+	case v.thumbQueue <- page:
+		v.thumbRequested[page.ID] = true
+	default:
+		// Queue full; the row will ask again when it is redrawn
+	}
+	return nil
+}
+
+// thumbnailWorker captures queued thumbnails one at a time, so scrolling
+// never starts more than one headless browser.
+func (v *ContentManagerView) thumbnailWorker() {
+	for page := range v.thumbQueue {
+		if v.thumbnailsDisabled || !v.wpService.IsConnected() {
+			continue
 		}
+		thumb, err := v.wpService.GetPageThumbnail(page)
+		if err != nil {
+			logger.Warn("ContentManagerView: failed to get thumbnail", "page_id", page.ID, "error", err)
+			if errors.Is(err, exec.ErrNotFound) {
+				logger.Warn("ContentManagerView: Chrome not found, page thumbnails disabled")
+				v.thumbMutex.Lock()
+				v.thumbnailsDisabled = true
+				v.thumbMutex.Unlock()
+			}
+			continue
+		}
+		res := fyne.NewStaticResource(fmt.Sprintf("thumb_%d.png", page.ID), thumb)
+		v.thumbMutex.Lock()
+		v.thumbnails[page.ID] = res
+		v.thumbMutex.Unlock()
+		pageID := page.ID
+		runOnUI(func() {
+			v.pageList.Refresh()
+			if pageID == v.selectedPageID && v.previewImage.Resource == nil {
+				v.previewImage.Resource = res
+				v.previewImage.Refresh()
+			}
+		})
+	}
+}
 
-		// Create Fyne resource from image bytes
-		imgResource := fyne.NewStaticResource(fmt.Sprintf("preview_%d.png", v.selectedPageID), imgBytes) // Use PNG if GetPageScreenshot returns PNG
+// modifiedFilterDays are the "modified within" choices, in days; 0 is any time
+var modifiedFilterDays = []int{0, 7, 30, 365}
 
-		// Update the image widget
-		// Unlock here if no error occurred
-		v.dialogMutex.Unlock()
-		v.previewImage.Resource = imgResource
-		v.previewImage.Refresh()
+// modifiedFilterLabels returns the labels of modifiedFilterDays, in order.
+func modifiedFilterLabels() []string {
+	return []string{
+		i18n.T("Modified: any time"),
+		i18n.T("Modified: last 7 days"),
+		i18n.T("Modified: last 30 days"),
+		i18n.T("Modified: last year"),
+	}
+}
+
+// initializeFilters creates the search box and the status/author/date/sort filters above the page list
+func (v *ContentManagerView) initializeFilters() {
+	v.searchEntry = widget.NewEntry()
+	v.searchEntry.SetPlaceHolder(i18n.T("Search pages (Enter searches the server)..."))
+	v.searchEntry.OnChanged = func(string) { v.applyFilters() }
+	v.searchEntry.OnSubmitted = func(string) { v.queryServer() }
+
+	// The first option of each filter is "no filter"; the filters are read by
+	// index, since the labels are translated
+	v.statusFilter = widget.NewSelect(append([]string{i18n.T("All statuses")}, wordpress.PageStatusOptions...), func(string) { v.applyFilters() })
+	v.statusFilter.Selected = v.statusFilter.Options[0]
+
+	v.authorFilter = widget.NewSelect([]string{i18n.T("All authors")}, func(string) { v.applyFilters() })
+	v.authorFilter.Selected = v.authorFilter.Options[0]
+
+	v.modifiedFilter = widget.NewSelect(modifiedFilterLabels(), func(string) { v.applyFilters() })
+	v.modifiedFilter.Selected = v.modifiedFilter.Options[0]
+
+	v.sortSelect = widget.NewSelect(wordpress.PageSortOptions, func(string) { v.applyFilters() })
+	v.sortSelect.Selected = wordpress.SortByID
+
+	v.filterLabel = widget.NewLabel("")
+}
+
+// currentFilter builds a PageFilter from the filter controls
+func (v *ContentManagerView) currentFilter() wordpress.PageFilter {
+	filter := wordpress.PageFilter{
+		Search: v.searchEntry.Text,
+		SortBy: v.sortSelect.Selected,
+	}
+	if i := v.statusFilter.SelectedIndex(); i > 0 {
+		filter.Status = wordpress.PageStatusOptions[i-1]
+	}
+	if i := v.authorFilter.SelectedIndex(); i > 0 && i <= len(v.authorIDs) {
+		filter.Author = v.authorIDs[i-1]
+	}
+	if i := v.modifiedFilter.SelectedIndex(); i > 0 {
+		filter.ModifiedAfter = time.Now().AddDate(0, 0, -modifiedFilterDays[i])
+	}
+	return filter
+}
+
+// applyFilters filters and sorts the cached page list into visiblePages
+func (v *ContentManagerView) applyFilters() {
+	if v.filterLabel == nil {
+		return // Filters not created yet
+	}
+	v.pageList.UnselectAll()
+	v.refreshVisiblePages()
+}
+
+// refreshVisiblePages re-applies the filters to the cached pages without changing the selection
+func (v *ContentManagerView) refreshVisiblePages() {
+	filter := v.currentFilter()
+	v.visiblePages = filter.Apply(v.pages)
+	v.pageList.Refresh()
+	if filter.IsEmpty() && v.hasMorePages() {
+		v.filterLabel.SetText(i18n.Tf("%d pages loaded (scroll for more)", len(v.pages)))
+	} else if filter.IsEmpty() {
+		v.filterLabel.SetText(i18n.Tf("%d pages", len(v.pages)))
+	} else if len(v.visiblePages) == 0 {
+		v.filterLabel.SetText(i18n.T("No cached matches, press Enter in the search box to search the server"))
+	} else {
+		v.filterLabel.SetText(i18n.Tf("%d of %d pages match", len(v.visiblePages), len(v.pages)))
+	}
+}
+
+// refreshAuthorOptions rebuilds the author filter from the authors in the cached page list
+func (v *ContentManagerView) refreshAuthorOptions() {
+	seen := map[int]bool{}
+	var ids []int
+	for _, page := range v.pages {
+		if page.Author != 0 && !seen[page.Author] {
+			seen[page.Author] = true
+			ids = append(ids, page.Author)
+		}
+	}
+	sort.Ints(ids)
+	options := []string{i18n.T("All authors")}
+	for _, id := range ids {
+		options = append(options, i18n.Tf("Author #%d", id))
+	}
+	v.authorIDs = ids
+	v.authorFilter.Options = options
+	v.authorFilter.Refresh()
+}
+
+// queryServer runs the current filter as a server-side search. Matching pages
+// that are not cached yet (e.g. drafts, or on large sites) are merged into the
+// cache so they can be selected and saved like any other page.
+func (v *ContentManagerView) queryServer() {
+	if !v.wpService.IsConnected() {
+		return
+	}
+	filter := v.currentFilter()
+	if filter.IsEmpty() {
+		v.applyFilters()
+		return
+	}
+	v.filterLabel.SetText(i18n.T("Searching server..."))
+
+	go func() {
+		defer crash.Recover("ContentManagerView.queryServer")
+		results, err := v.wpService.QueryPages(filter, 100)
+		if err != nil {
+			logger.Error("ContentManagerView: server page search failed", "error", err)
+			runOnUI(func() {
+				v.filterLabel.SetText(i18n.T("Server search failed"))
+				ShowError(fmt.Errorf("failed to search pages on the server: %w", err), v.window)
+			})
+			return
+		}
+
+		added := 0
+		v.pageLoadMutex.Lock()
+		for _, page := range results {
+			if v.GetPageByID(page.ID) == nil {
+				v.pages = append(v.pages, page)
+				added++
+			}
+		}
+		v.pageLoadMutex.Unlock()
+		logger.Info("ContentManagerView: server search finished", "pages", len(results), "new_pages", added)
+		runOnUI(func() {
+			if added > 0 {
+				v.refreshAuthorOptions()
+			}
+			v.visiblePages = filter.Apply(results)
+			v.pageList.UnselectAll()
+			v.pageList.Refresh()
+			v.filterLabel.SetText(i18n.Tf("%d pages found on server", len(v.visiblePages)))
+		})
 	}()
 }
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/seo"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PrefConfirmGenerationCost shows the cost estimate before each generation.
+var PrefConfirmGenerationCost = settings.NewKey("generation.confirm_cost", true)
+
+// expectedOutputTokens returns the expected length of an article meeting
+// targets, in tokens.
+func expectedOutputTokens(targets seo.Targets) int {
+	if targets.WordCount > 0 {
+		return targets.WordCount * 4 / 3 // About three words per four tokens
+	}
+	return inference.DefaultExpectedOutputTokens
+}
+
+// costEstimateSummary describes an estimate for the Generate confirmation.
+func costEstimateSummary(estimate inference.CostEstimate) string {
+	lines := []string{i18n.Tf("~%d input + ~%d output tokens over %d requests", estimate.InputTokens, estimate.OutputTokens, estimate.Calls)}
+	cost := fmt.Sprintf("$%.2f", estimate.Cost)
+	if estimate.Cost > 0 && estimate.Cost < 0.01 {
+		cost = fmt.Sprintf("$%.4f", estimate.Cost)
+	}
+	if len(estimate.Unpriced) > 0 {
+		lines = append(lines, i18n.Tf("Estimated cost: %s, plus %s (no known price)", cost, strings.Join(estimate.Unpriced, ", ")))
+	} else {
+		lines = append(lines, i18n.Tf("Estimated cost: %s", cost))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// confirmGeneration shows the estimated tokens and cost of generating from
+// prompt with opts, and calls run if the user goes ahead. It runs at once
+// when the estimate is switched off or can't be made, in which case the
+// generation reports the problem itself.
+func confirmGeneration(window fyne.Window, service *inference.InferenceService, prompt string, opts inference.GenerateOptions, expectedOutput int, run func()) {
+	if !PrefConfirmGenerationCost.Get() || service == nil {
+		run()
+		return
+	}
+	estimate, err := service.EstimateCost(prompt, opts, expectedOutput)
+	if err != nil {
+		logger.Warn("Couldn't estimate the generation cost", "error", err)
+		run()
+		return
+	}
+	message := widget.NewLabel(costEstimateSummary(estimate))
+	note := widget.NewLabel(i18n.T("Source condensing, retries and fallbacks aren't included. Local models are free."))
+	note.Wrapping = fyne.TextWrapWord
+	ask := widget.NewCheck(i18n.T("Show this estimate before each generation"), nil)
+	ask.SetChecked(true)
+	d := dialog.NewCustomConfirm(i18n.T("Generate Content"), i18n.T("Generate"), i18n.T("Cancel"), container.NewVBox(message, note, ask), func(confirmed bool) {
+		if !ask.Checked {
+			PrefConfirmGenerationCost.Set(false)
+		}
+		if confirmed {
+			run()
+		}
+	}, window)
+	d.Resize(fyne.NewSize(480, d.MinSize().Height))
+	d.Show()
+}
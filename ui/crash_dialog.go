@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"net/url"
+	"sync"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var (
+	crashDialogMu   sync.Mutex
+	crashDialogOpen bool // One dialog at a time; a panicking loop still writes every report
+)
+
+// ShowCrashReports shows a recovery dialog in window whenever a background
+// goroutine panics and the app recovers from it.
+func ShowCrashReports(window fyne.Window) {
+	crash.OnCrash(func(report crash.Report) {
+		crashDialogMu.Lock()
+		open := crashDialogOpen
+		crashDialogOpen = true
+		crashDialogMu.Unlock()
+		if !open {
+			runOnUI(func() { showCrashDialog(report, window) })
+		}
+	})
+}
+
+// showCrashDialog tells the user what failed and where its report is.
+func showCrashDialog(report crash.Report, window fyne.Window) {
+	text := i18n.Tf("Something went wrong in %s, but the app recovered and kept running. If it misbehaves, save your work and restart it.", report.Where)
+	if report.Path != "" {
+		text += "\n\n" + i18n.Tf("A crash report with the details was saved to %s. Please attach it to a bug report.", report.Path)
+	}
+	message := widget.NewLabel(text)
+	message.Wrapping = fyne.TextWrapWord
+	details := widget.NewLabel(report.Panic)
+	details.Wrapping = fyne.TextWrapWord
+	body := container.NewVBox(message, widget.NewAccordion(widget.NewAccordionItem(i18n.T("Details"), details)))
+
+	d := dialog.NewCustomWithoutButtons(i18n.T("Recovered From a Crash"), newReadingOrderBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), nil, body), window)
+	d.SetButtons([]fyne.CanvasObject{
+		newCopyButton(window, i18n.T("Copy"), func() string { return report.Panic + "\n\n" + report.Stack }),
+		widget.NewButtonWithIcon(i18n.T("Open Folder"), theme.FolderOpenIcon(), func() {
+			if err := fyne.CurrentApp().OpenURL(&url.URL{Scheme: "file", Path: crash.Dir()}); err != nil {
+				ShowError(err, window)
+			}
+		}),
+		widget.NewButton(i18n.T("OK"), d.Hide),
+	})
+	d.SetOnClosed(func() {
+		crashDialogMu.Lock()
+		crashDialogOpen = false
+		crashDialogMu.Unlock()
+	})
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// DisclaimerSettingsView edits the rules that add compliance disclaimers to
+// content before it is published.
+type DisclaimerSettingsView struct {
+	container *fyne.Container
+	store     *editorial.DisclaimerStore
+	window    fyne.Window
+
+	// UI elements
+	editor      *EditorEntry
+	statusLabel *widget.Label
+}
+
+// NewDisclaimerSettingsView creates a new disclaimer settings view
+func NewDisclaimerSettingsView(store *editorial.DisclaimerStore, window fyne.Window) *DisclaimerSettingsView {
+	view := &DisclaimerSettingsView{
+		store:  store,
+		window: window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the disclaimer settings view
+func (v *DisclaimerSettingsView) initialize() {
+	v.editor = NewEditorEntry()
+	v.editor.SetPlaceHolder(editorial.DisclaimersExample)
+	v.editor.SetMinRowsVisible(8)
+	v.editor.SetText(v.store.Text())
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+	v.updateStatus(v.store.Disclaimers())
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Disclaimers"), theme.DocumentSaveIcon(), v.save)
+	exampleButton := widget.NewButton(i18n.T("Insert Example"), func() {
+		v.editor.ReplaceText(editorial.DisclaimersExample)
+	})
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Compliance Disclaimers")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("When content is saved to WordPress, the disclaimers of the categories it is detected as, or that you tick, are added at its end.")),
+		v.editor,
+		container.NewHBox(saveButton, exampleButton),
+		v.statusLabel,
+	)
+}
+
+// save parses and registers the editor's text.
+func (v *DisclaimerSettingsView) save() {
+	if err := v.store.Save(v.editor.Text); err != nil {
+		ShowError(fmt.Errorf("disclaimers not saved: %w", err), v.window)
+		return
+	}
+	v.updateStatus(v.store.Disclaimers())
+}
+
+// updateStatus summarizes the registered rules.
+func (v *DisclaimerSettingsView) updateStatus(d editorial.Disclaimers) {
+	if len(d.Rules) == 0 {
+		v.statusLabel.SetText(i18n.T("No disclaimers are added."))
+		return
+	}
+	v.statusLabel.SetText(i18n.Tf("Disclaimer categories: %s.", strings.Join(d.Categories(), ", ")))
+}
+
+// Container returns the container for the disclaimer settings view
+func (v *DisclaimerSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// runOnUI schedules a widget update from a background goroutine on Fyne's
+// main loop, without waiting for it to run. Updates run in the order they
+// were scheduled, and never concurrently with rendering or event handlers.
+//
+// Call it from goroutines (jobs, network callbacks, tickers) only; event
+// handlers such as button callbacks already run on the UI goroutine and can
+// update widgets directly.
+func runOnUI(fn func()) {
+	fyne.Do(func() { runUIUpdate(fn) })
+}
+
+// runUIUpdate runs one update, logging a panic instead of stopping the main loop.
+func runUIUpdate(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("UI update panicked", "panic", r)
+		}
+	}()
+	fn()
+}
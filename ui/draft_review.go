@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Settings of the review workflow.
+var (
+	// PrefReviewerName is the name review notes are signed with.
+	PrefReviewerName = settings.NewKey("review.reviewer_name", "")
+	// PrefRequireApproval stops generated content from being saved to
+	// WordPress until its draft is approved.
+	PrefRequireApproval = settings.NewKey("review.require_approval", false)
+)
+
+// requireApproval reports whether content must be approved before it is
+// saved to WordPress.
+func requireApproval() bool {
+	return PrefRequireApproval.Get()
+}
+
+// reviewerName returns the name review notes are signed with.
+func reviewerName() string {
+	return PrefReviewerName.Get()
+}
+
+// saveIfApproved calls save if content can go to WordPress: approval isn't
+// required, or content is the output of an approved draft in store. Content
+// that isn't approved is offered for review as a new draft described by
+// what; once the draft is approved, saving the content again goes through.
+func saveIfApproved(store *history.Store, window fyne.Window, content, what string, save func()) {
+	if !requireApproval() {
+		save()
+		return
+	}
+	if store == nil {
+		ShowError(fmt.Errorf("this content needs approval before it is saved to WordPress, but the generation history is unavailable (see the log for why it could not be opened)"), window)
+		return
+	}
+	if _, ok := store.Approved(content); ok {
+		save()
+		return
+	}
+	dialog.ShowConfirm(i18n.T("Approval Needed"), i18n.T("This content was changed by AI and needs approval before it is saved to WordPress. Save it as a draft and open its review?"), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		draft, err := store.Add(history.Draft{Prompt: what, Output: content})
+		if err != nil {
+			ShowError(fmt.Errorf("failed to save the content as a draft: %w", err), window)
+			return
+		}
+		showDraftReview(store, window, draft.ID, nil)
+	}, window)
+}
+
+// reviewActionLabels names the buttons moving a draft between states.
+var reviewActionLabels = map[[2]history.ReviewState]string{
+	{history.ReviewDraft, history.ReviewInReview}:    "Submit for Review",
+	{history.ReviewInReview, history.ReviewApproved}: "Approve",
+	{history.ReviewInReview, history.ReviewDraft}:    "Request Changes",
+	{history.ReviewApproved, history.ReviewInReview}: "Reopen",
+}
+
+// showDraftReview opens the review of a draft: its state, the notes so far,
+// and buttons to comment or move it on. onChange is called after any change.
+func showDraftReview(store *history.Store, window fyne.Window, draftID int, onChange func()) {
+	draft, ok := store.Get(draftID)
+	if !ok {
+		ShowError(fmt.Errorf("draft %d not found", draftID), window)
+		return
+	}
+
+	state := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	notes := widget.NewLabel("")
+	notes.Wrapping = fyne.TextWrapWord
+	preview := widget.NewLabel(draft.Output)
+	preview.Wrapping = fyne.TextWrapWord
+
+	reviewer := widget.NewEntry()
+	reviewer.SetPlaceHolder(i18n.T("Your name"))
+	reviewer.SetText(reviewerName())
+	note := widget.NewMultiLineEntry()
+	note.Wrapping = fyne.TextWrapWord
+	note.SetPlaceHolder(i18n.T("Note for the writer or other reviewers..."))
+	note.SetMinRowsVisible(3)
+
+	actions := container.NewHBox()
+	var refresh func()
+	act := func(do func(name, text string) error) {
+		name := strings.TrimSpace(reviewer.Text)
+		PrefReviewerName.Set(name)
+		if err := do(name, note.Text); err != nil {
+			ShowError(err, window)
+			return
+		}
+		note.SetText("")
+		refresh()
+		if onChange != nil {
+			onChange()
+		}
+	}
+	refresh = func() {
+		draft, _ = store.Get(draftID)
+		state.SetText(i18n.Tf("State: %s", i18n.T(draft.Review.Label())))
+		notes.SetText(reviewNotesText(store.Notes(draftID)))
+		actions.Objects = nil
+		actions.Add(widget.NewButton(i18n.T("Add Note"), func() {
+			act(func(name, text string) error { return store.Comment(draftID, name, text) })
+		}))
+		for _, next := range draft.Review.Next() {
+			label, ok := reviewActionLabels[[2]history.ReviewState{draft.Review, next}]
+			if !ok {
+				continue // Publishing happens when the draft is saved to WordPress
+			}
+			to := next
+			button := widget.NewButton(i18n.T(label), func() {
+				act(func(name, text string) error { return store.Move(draftID, to, name, text) })
+			})
+			if to == history.ReviewApproved {
+				button.Importance = widget.HighImportance
+			}
+			actions.Add(button)
+		}
+		actions.Refresh()
+	}
+	refresh()
+
+	top := container.NewVBox(state, widget.NewLabel(draftDetails(draft)))
+	notesPane := newReadingOrderBorder(widget.NewLabel(i18n.T("Notes:")), nil, nil, nil, container.NewVScroll(notes))
+	split := container.NewVSplit(container.NewVScroll(preview), notesPane)
+	split.Offset = 0.6
+	bottom := container.NewVBox(
+		newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Reviewer:")), nil, reviewer),
+		note,
+		container.NewHBox(layout.NewSpacer(), actions),
+	)
+	d := dialog.NewCustom(i18n.Tf("Review Draft #%d", draftID), i18n.T("Close"), newReadingOrderBorder(top, bottom, nil, nil, split), window)
+	d.Resize(fyne.NewSize(760, 640))
+	d.Show()
+}
+
+// reviewNotesText lists review notes, oldest first, one paragraph each.
+func reviewNotesText(notes []history.ReviewNote) string {
+	if len(notes) == 0 {
+		return i18n.T("No notes yet.")
+	}
+	var b strings.Builder
+	for _, n := range notes {
+		name := n.Reviewer
+		if name == "" {
+			name = i18n.T("Someone")
+		}
+		fmt.Fprintf(&b, "%s — %s (%s)", n.Created.Format("2006-01-02 15:04"), name, i18n.T(n.State.Label()))
+		if n.Note != "" {
+			b.WriteString(": " + n.Note)
+		}
+		b.WriteString("\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
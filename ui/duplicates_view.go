@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/audit"
+	"Inference_Engine/crash"
+	"Inference_Engine/embeddings"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// DuplicatesView lists pairs of pages on the connected site that cover
+// essentially the same topic, found with the embeddings index, and drafts a
+// merged article for a pair.
+type DuplicatesView struct {
+	container        fyne.CanvasObject
+	index            *embeddings.Store
+	dismissed        *audit.DismissedPairs
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+	jobQueue         *jobs.Queue
+	generatorView    *ContentGeneratorView
+	window           fyne.Window
+
+	thresholdEntry *widget.Entry
+	indexButton    *widget.Button
+	statusLabel    *widget.Label
+	pairList       *widget.List
+	detailLabel    *widget.Label
+	mergeButton    *widget.Button
+	dismissButton  *widget.Button
+
+	// Data
+	pairs    []embeddings.Pair
+	selected int
+}
+
+// NewDuplicatesView creates a new DuplicatesView
+func NewDuplicatesView(index *embeddings.Store, dismissed *audit.DismissedPairs, wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, window fyne.Window) *DuplicatesView {
+	view := &DuplicatesView{
+		index:            index,
+		dismissed:        dismissed,
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		window:           window,
+		selected:         -1,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *DuplicatesView) initialize() {
+	v.thresholdEntry = widget.NewEntry()
+	v.thresholdEntry.SetText(strconv.Itoa(int(audit.DefaultDuplicateThreshold * 100)))
+	v.thresholdEntry.OnChanged = func(string) { v.reload() }
+	v.indexButton = widget.NewButton(i18n.T("Update Index"), v.updateIndex)
+	v.statusLabel = widget.NewLabel("")
+
+	v.pairList = widget.NewList(
+		func() int {
+			return len(v.pairs)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template duplicate pair")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.pairs) {
+				return
+			}
+			pair := v.pairs[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%.0f%%  %s  ↔  %s", pair.Similarity*100, pair.A.Title, pair.B.Title))
+		},
+	)
+	v.pairList.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.updateDetails()
+	}
+	v.pairList.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.updateDetails()
+	}
+
+	v.detailLabel = widget.NewLabel("")
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.mergeButton = widget.NewButton(i18n.T("Merge Draft"), v.merge)
+	v.dismissButton = widget.NewButton(i18n.T("Not a Duplicate"), v.dismiss)
+
+	v.container = newReadingOrderBorder(
+		container.NewVBox( // Top
+			widget.NewForm(widget.NewFormItem(i18n.T("Similarity at least (%):"),
+				container.NewBorder(nil, nil, nil, v.indexButton, v.thresholdEntry))),
+			v.statusLabel,
+		),
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			v.detailLabel,
+			container.NewHBox(layout.NewSpacer(), v.dismissButton, v.mergeButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.pairList,
+	)
+	v.reload()
+}
+
+// SetJobQueue sets the queue that indexing and merges are submitted to
+func (v *DuplicatesView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetContentGeneratorView sets the view that merge drafts can be opened in
+func (v *DuplicatesView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
+	v.generatorView = generatorView
+}
+
+// SiteChanged shows the duplicates of the newly connected site.
+func (v *DuplicatesView) SiteChanged() {
+	v.reload()
+}
+
+// threshold returns the entered similarity as a fraction, or the default if
+// the entry isn't a percentage.
+func (v *DuplicatesView) threshold() float64 {
+	percent, err := strconv.Atoi(strings.TrimSpace(v.thresholdEntry.Text))
+	if err != nil || percent <= 0 || percent > 100 {
+		return audit.DefaultDuplicateThreshold
+	}
+	return float64(percent) / 100
+}
+
+// reload lists the current site's duplicate pairs from its index.
+func (v *DuplicatesView) reload() {
+	site := v.wpService.GetCurrentSiteName()
+	idx := v.index.Index(site)
+	v.pairs = audit.Duplicates(idx, v.threshold(), v.dismissed.Keys(site))
+	if len(idx.Entries) == 0 {
+		v.statusLabel.SetText(i18n.T("The site has not been indexed yet. Click \"Update Index\" to compare its pages."))
+	} else {
+		v.statusLabel.SetText(i18n.Tf("%d pages indexed on %s; %d pairs found.", len(idx.Entries), idx.Updated.Format("2006-01-02 15:04"), len(v.pairs)))
+	}
+	v.selected = -1
+	v.pairList.UnselectAll()
+	v.pairList.Refresh()
+	v.updateDetails()
+}
+
+// updateDetails shows the selected pair's links.
+func (v *DuplicatesView) updateDetails() {
+	if v.selected < 0 || v.selected >= len(v.pairs) {
+		v.detailLabel.SetText(i18n.T("Select a pair to merge the pages or mark them as distinct."))
+		v.mergeButton.Disable()
+		v.dismissButton.Disable()
+		return
+	}
+	pair := v.pairs[v.selected]
+	v.detailLabel.SetText(fmt.Sprintf("%s\n%s\n\n%s\n%s", pair.A.Title, pair.A.Link, pair.B.Title, pair.B.Link))
+	v.mergeButton.Enable()
+	v.dismissButton.Enable()
+}
+
+// updateIndex embeds the site's new and modified pages in the background.
+func (v *DuplicatesView) updateIndex() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	site := v.wpService.GetCurrentSiteName()
+	v.indexButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.indexButton.Enable)
+		progress(-1, i18n.T("Fetching pages"))
+		pages, err := v.wpService.GetPages(1, 100)
+		if err == nil {
+			docs := make([]embeddings.Document, len(pages))
+			for i, page := range pages {
+				docs[i] = embeddings.Document{ID: page.ID, Title: page.Title, Link: page.Link, Modified: page.Modified,
+					Text: page.Title + "\n\n" + utils.HTMLToMarkdown(page.Content)}
+			}
+			_, err = v.index.Update(ctx, site, embeddings.Default(), docs, func(done, total int) {
+				progress(float64(done)/float64(max(total, 1)), i18n.Tf("Embedded %d of %d changed pages", done, total))
+			})
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runOnUI(func() {
+			if err != nil {
+				ShowError(fmt.Errorf("failed to update the embeddings index: %w", err), v.window)
+				return
+			}
+			v.reload()
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("DuplicatesView.updateIndex", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Embeddings Index", site, run)
+}
+
+// merge drafts one article from the selected pair.
+func (v *DuplicatesView) merge() {
+	if v.selected < 0 || v.selected >= len(v.pairs) {
+		return
+	}
+	pair := v.pairs[v.selected]
+	pages := []wordpress.Page{
+		{ID: pair.A.ID, Title: pair.A.Title, Link: pair.A.Link, Modified: pair.A.Modified},
+		{ID: pair.B.ID, Title: pair.B.Title, Link: pair.B.Link, Modified: pair.B.Modified},
+	}
+	mergePages(v.window, v.wpService, v.inferenceService, v.jobQueue, v.generatorView, pages)
+}
+
+// dismiss stops reporting the selected pair.
+func (v *DuplicatesView) dismiss() {
+	if v.selected < 0 || v.selected >= len(v.pairs) {
+		return
+	}
+	pair := v.pairs[v.selected]
+	if err := v.dismissed.Dismiss(v.wpService.GetCurrentSiteName(), pair.A.ID, pair.B.ID); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	v.reload()
+}
+
+// Container returns the container for the view
+func (v *DuplicatesView) Container() fyne.CanvasObject {
+	return v.container
+}
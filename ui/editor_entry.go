@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxEditorSnapshots caps how many replaced texts an EditorEntry keeps for undo.
+const maxEditorSnapshots = 50
+
+// EditorEntry is a multi-line entry used for the main editors. It passes the
+// app's keyboard shortcuts (Ctrl+Enter, Ctrl+S, ...) through to the window
+// instead of swallowing them, and keeps snapshots of programmatic text
+// replacements (loaded pages, generated results) so they can be undone too.
+// Fyne's own undo stack only covers typing and is cleared by SetText.
+type EditorEntry struct {
+	widget.Entry
+
+	undoSnapshots []string
+	redoSnapshots []string
+
+	// OnRecall, if set, is called when Up is pressed on the first line
+	// (older=true) or Down on the last line (older=false). It returns false
+	// to let the entry move the cursor as usual.
+	OnRecall func(older bool) bool
+
+	// OnAIEdit, if set, is called after an AI edit replaces part of the text.
+	OnAIEdit func()
+}
+
+// NewEditorEntry creates a new multi-line EditorEntry.
+func NewEditorEntry() *EditorEntry {
+	e := &EditorEntry{}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedShortcut runs a registered app shortcut, or lets the entry handle it.
+func (e *EditorEntry) TypedShortcut(s fyne.Shortcut) {
+	switch s.(type) {
+	case *fyne.ShortcutUndo:
+		e.Undo()
+		return
+	case *fyne.ShortcutRedo:
+		e.Redo()
+		return
+	}
+	if action, ok := findAppShortcut(s); ok {
+		action()
+		return
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// TypedKey lets OnRecall handle Up/Down at the edges of the text.
+func (e *EditorEntry) TypedKey(key *fyne.KeyEvent) {
+	if e.OnRecall != nil {
+		switch {
+		case key.Name == fyne.KeyUp && e.CursorRow == 0:
+			if e.OnRecall(true) {
+				return
+			}
+		case key.Name == fyne.KeyDown && e.CursorRow == strings.Count(e.Text, "\n"):
+			if e.OnRecall(false) {
+				return
+			}
+		}
+	}
+	e.Entry.TypedKey(key)
+}
+
+// ReplaceText sets the text like SetText, but remembers the previous text so
+// the replacement can be undone.
+func (e *EditorEntry) ReplaceText(text string) {
+	if text == e.Text {
+		return
+	}
+	e.undoSnapshots = pushSnapshot(e.undoSnapshots, e.Text)
+	e.redoSnapshots = nil
+	e.SetText(text)
+}
+
+// Selection returns the rune offsets of the selected text. The entry only
+// exposes the selected text and the cursor, which is at one end of the
+// selection, so the selection is found next to the cursor.
+func (e *EditorEntry) Selection() (start, end int, ok bool) {
+	selected := []rune(e.SelectedText())
+	if len(selected) == 0 {
+		return 0, 0, false
+	}
+	text := []rune(e.Text)
+	cursor := e.cursorOffset()
+	if cursor >= len(selected) && string(text[cursor-len(selected):cursor]) == string(selected) {
+		return cursor - len(selected), cursor, true
+	}
+	if cursor+len(selected) <= len(text) && string(text[cursor:cursor+len(selected)]) == string(selected) {
+		return cursor, cursor + len(selected), true
+	}
+	return 0, 0, false
+}
+
+// cursorOffset returns the rune offset of the cursor.
+func (e *EditorEntry) cursorOffset() int {
+	text := []rune(e.Text)
+	cursor := 0
+	for row := 0; row < e.CursorRow && cursor < len(text); cursor++ {
+		if text[cursor] == '\n' {
+			row++
+		}
+	}
+	return min(cursor+e.CursorColumn, len(text))
+}
+
+// InsertAtCursor inserts text at the cursor, undoably like ReplaceText.
+func (e *EditorEntry) InsertAtCursor(text string) {
+	cursor := e.cursorOffset()
+	e.ReplaceRange(cursor, cursor, text)
+}
+
+// ReplaceRange replaces the text between the rune offsets start and end,
+// undoably like ReplaceText.
+func (e *EditorEntry) ReplaceRange(start, end int, replacement string) {
+	text := []rune(e.Text)
+	e.ReplaceText(string(text[:start]) + replacement + string(text[end:]))
+}
+
+// Undo reverts the last edit. Typed edits are undone first; once there are
+// none left, the previous replaced text is restored.
+func (e *EditorEntry) Undo() {
+	before := e.Text
+	e.Entry.Undo()
+	if e.Text != before || len(e.undoSnapshots) == 0 {
+		return
+	}
+	last := len(e.undoSnapshots) - 1
+	e.redoSnapshots = pushSnapshot(e.redoSnapshots, e.Text)
+	text := e.undoSnapshots[last]
+	e.undoSnapshots = e.undoSnapshots[:last]
+	e.SetText(text)
+}
+
+// Redo re-applies the last undone edit or text replacement.
+func (e *EditorEntry) Redo() {
+	before := e.Text
+	e.Entry.Redo()
+	if e.Text != before || len(e.redoSnapshots) == 0 {
+		return
+	}
+	last := len(e.redoSnapshots) - 1
+	e.undoSnapshots = pushSnapshot(e.undoSnapshots, e.Text)
+	text := e.redoSnapshots[last]
+	e.redoSnapshots = e.redoSnapshots[:last]
+	e.SetText(text)
+}
+
+// ClearHistory drops all undo/redo snapshots, e.g. when switching documents.
+func (e *EditorEntry) ClearHistory() {
+	e.undoSnapshots = nil
+	e.redoSnapshots = nil
+}
+
+// pushSnapshot appends text to a snapshot stack, dropping the oldest entry when full.
+func pushSnapshot(stack []string, text string) []string {
+	stack = append(stack, text)
+	if len(stack) > maxEditorSnapshots {
+		stack = stack[len(stack)-maxEditorSnapshots:]
+	}
+	return stack
+}
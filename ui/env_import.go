@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+	"Inference_Engine/vault"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// envSecrets are the environment variables holding secrets, which are only
+// imported into the vault.
+var envSecrets = []string{
+	"CEREBRAS_API_KEY", "GEMINI_API_KEY", "DEEPSEEK_API_KEY", "JETPACK_STATS_TOKEN",
+	"CEREBRAS_EXTRA_HEADERS", "GEMINI_EXTRA_HEADERS", "DEEPSEEK_EXTRA_HEADERS",
+}
+
+// EnvSettings are the environment variables holding plain configuration,
+// which are imported into the app settings. Variables read before the
+// settings are loaded, such as LOG_LEVEL and the tracing ones, are left out.
+var EnvSettings = []string{
+	"GEMINI_API_ENDPOINT", "GSC_API_ENDPOINT", "GSC_CREDENTIALS_FILE", "GA_CREDENTIALS_FILE", "GA4_PROPERTY_ID",
+	"GIT_VERSIONS_DIR", "DAILY_TOKEN_BUDGET",
+	"CEREBRAS_BASE_URL", "GEMINI_BASE_URL", "DEEPSEEK_BASE_URL",
+	"CEREBRAS_ORGANIZATION", "GEMINI_ORGANIZATION", "DEEPSEEK_ORGANIZATION",
+}
+
+// PrefEnvImportSkipped lists the environment variables the user chose not to
+// import, which aren't offered again.
+var PrefEnvImportSkipped = settings.NewKey[[]string]("env.import_skipped", nil)
+
+// pendingEnvImport returns the configuration and secret variables that are
+// set in the environment but not kept, or kept with another value. Secrets
+// are only offered with an unlocked vault to keep them in.
+func pendingEnvImport(secrets *vault.Vault) (config, secret []string) {
+	skipped := PrefEnvImportSkipped.Get()
+	for _, name := range EnvSettings {
+		if value := os.Getenv(name); value != "" && value != settings.EnvKey(name).Get() && !slices.Contains(skipped, name) {
+			config = append(config, name)
+		}
+	}
+	if secrets == nil || secrets.Locked() {
+		return config, nil
+	}
+	for _, name := range envSecrets {
+		stored, _ := secrets.Get(name)
+		if value := os.Getenv(name); value != "" && value != stored && !slices.Contains(skipped, name) {
+			secret = append(secret, name)
+		}
+	}
+	return config, secret
+}
+
+// OfferEnvImport offers to keep the configuration and API keys found in the
+// environment or .env file in the app settings and the vault, so they no
+// longer need the file. Call it on the UI goroutine once the vault is
+// unlocked, if there is one.
+func OfferEnvImport(secrets *vault.Vault, window fyne.Window) {
+	config, secret := pendingEnvImport(secrets)
+	if len(config)+len(secret) == 0 {
+		return
+	}
+	var lines []string
+	if len(config) > 0 {
+		lines = append(lines, i18n.Tf("Settings: %s", strings.Join(config, ", ")))
+	}
+	if len(secret) > 0 {
+		lines = append(lines, i18n.Tf("Into the vault: %s", strings.Join(secret, ", ")))
+	} else if secrets == nil || !secrets.Exists() {
+		for _, name := range envSecrets {
+			if os.Getenv(name) != "" {
+				lines = append(lines, i18n.T("API keys are only imported into a vault; create one in the settings to import them."))
+				break
+			}
+		}
+	}
+	message := widget.NewLabel(i18n.T("These variables are set in the environment or .env file. Import them so the app keeps them itself? Imported values are used from then on, even if the .env file changes or is removed.") +
+		"\n\n" + strings.Join(lines, "\n"))
+	message.Wrapping = fyne.TextWrapWord
+	dontAsk := widget.NewCheck(i18n.T("Don't offer these again"), nil)
+
+	d := dialog.NewCustomConfirm(i18n.T("Import from .env"), i18n.T("Import"), i18n.T("Not Now"), container.NewVBox(message, dontAsk), func(confirmed bool) {
+		if !confirmed {
+			if dontAsk.Checked {
+				PrefEnvImportSkipped.Set(append(append(PrefEnvImportSkipped.Get(), config...), secret...))
+			}
+			return
+		}
+		imported := settings.ImportEnv(config)
+		if len(secret) > 0 {
+			stored, err := secrets.ImportEnv(secret)
+			if err != nil {
+				ShowError(err, window)
+			}
+			imported = append(imported, stored...)
+		}
+		logger.Info("Imported environment variables", "count", len(imported))
+		dialog.ShowInformation(i18n.T("Import from .env"), i18n.Tf("Imported %d variables. You can remove them from the .env file.", len(imported)), window)
+	}, window)
+	d.Resize(fyne.NewSize(520, d.MinSize().Height))
+	d.Show()
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// errorAdvice returns the dialog title and the suggested fix for an error kind.
+func errorAdvice(kind utils.ErrorKind) (title, advice string) {
+	switch kind {
+	case utils.ErrorAuth:
+		return i18n.T("Authentication Failed"),
+			i18n.T("The credentials were rejected. Check the username and application password in Settings, or the provider's API key in your environment.")
+	case utils.ErrorRateLimit:
+		return i18n.T("Rate Limit Reached"),
+			i18n.T("The provider is throttling requests or the quota is used up. Wait a minute and try again, or switch to another model.")
+	case utils.ErrorNetwork:
+		return i18n.T("Connection Problem"),
+			i18n.T("The server could not be reached or took too long to answer. Check your internet connection and the site URL, then try again.")
+	case utils.ErrorModel:
+		return i18n.T("Model Error"),
+			i18n.T("The model could not handle this request. It may be unavailable or overloaded, or the prompt may be too large. Try another model or shorter source content.")
+	default:
+		return i18n.T("Error"), ""
+	}
+}
+
+// errorContext returns what the caller was doing when err happened, i.e. the
+// text added by the outermost fmt.Errorf("...: %w") wrap, if any.
+func errorContext(err error) string {
+	inner := errors.Unwrap(err)
+	if inner == nil {
+		return ""
+	}
+	outer := strings.TrimSuffix(err.Error(), inner.Error())
+	return strings.TrimRight(strings.TrimSpace(outer), ":")
+}
+
+// ShowError shows an error dialog like dialog.ShowError, with a button to copy
+// the full error message (e.g. for bug reports). Recognized errors (auth, rate
+// limit, network, model) get a title and suggested fix, with the technical
+// message folded under "Details".
+func ShowError(err error, window fyne.Window) {
+	if err == nil {
+		return
+	}
+	kind := utils.ClassifyError(err)
+	title, advice := errorAdvice(kind)
+
+	var body fyne.CanvasObject
+	if advice == "" {
+		message := widget.NewLabel(err.Error())
+		message.Wrapping = fyne.TextWrapWord
+		body = message
+	} else {
+		logger.Info("Showing error dialog", "kind", kind, "error", err)
+		items := []fyne.CanvasObject{}
+		if what := errorContext(err); what != "" {
+			summary := widget.NewLabelWithStyle(what, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			summary.Wrapping = fyne.TextWrapWord
+			items = append(items, summary)
+		}
+		message := widget.NewLabel(advice)
+		message.Wrapping = fyne.TextWrapWord
+		details := widget.NewLabel(err.Error())
+		details.Wrapping = fyne.TextWrapWord
+		items = append(items, message, widget.NewAccordion(widget.NewAccordionItem(i18n.T("Details"), details)))
+		body = container.NewVBox(items...)
+	}
+
+	content := newReadingOrderBorder(nil, nil, widget.NewIcon(theme.ErrorIcon()), nil, body)
+	d := dialog.NewCustomWithoutButtons(title, content, window)
+	d.SetButtons([]fyne.CanvasObject{
+		newCopyButton(window, i18n.T("Copy"), err.Error),
+		widget.NewButton(i18n.T("OK"), d.Hide),
+	})
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// timestampedFileName builds a file name like "prefix-20250102-150405.ext".
+func timestampedFileName(prefix, ext string) string {
+	return fmt.Sprintf("%s-%s.%s", prefix, time.Now().Format("20060102-150405"), ext)
+}
+
+// exportTextToFile asks where to save and writes content there. The dialog is
+// pre-filled with a timestamped file name built from prefix and ext.
+func exportTextToFile(window fyne.Window, title, prefix, ext, content string) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			ShowError(err, window)
+			return
+		}
+		if writer == nil {
+			// User cancelled
+			return
+		}
+
+		go func() {
+			defer crash.Recover("exportTextToFile")
+			defer writer.Close()
+			if _, err := writer.Write([]byte(content)); err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to export %s: %w", title, err), window) })
+				return
+			}
+			name := filepath.Base(writer.URI().Path())
+			runOnUI(func() {
+				dialog.ShowInformation(i18n.T("Export Complete"), i18n.Tf("%s saved to '%s'", title, name), window)
+			})
+		}()
+	}, window)
+	saveDialog.SetFileName(timestampedFileName(prefix, ext))
+	saveDialog.Show()
+}
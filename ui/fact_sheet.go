@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/facts"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newFactSheetControls builds the fact sheet setting: whether to send it, a
+// summary, and the buttons to extract and view it.
+func (v *ContentGeneratorView) newFactSheetControls() fyne.CanvasObject {
+	v.useFactsCheck = widget.NewCheck(i18n.T("Ground the content in the fact sheet"), nil)
+	v.factsLabel = widget.NewLabel("")
+	v.factsLabel.Wrapping = fyne.TextWrapWord
+	v.viewFactsButton = widget.NewButtonWithIcon(i18n.T("View"), theme.VisibilityIcon(), func() {
+		if v.factSheet != nil {
+			v.showFactSheet(*v.factSheet, false)
+		}
+	})
+	v.refreshFactSheet()
+	return container.NewVBox(
+		container.NewHBox(v.useFactsCheck, widget.NewButtonWithIcon(i18n.T("Extract Facts"), theme.SearchIcon(), v.extractFactSheet), v.viewFactsButton),
+		v.factsLabel,
+	)
+}
+
+// refreshFactSheet summarizes the fact sheet and enables using it
+func (v *ContentGeneratorView) refreshFactSheet() {
+	if v.factSheet == nil {
+		v.useFactsCheck.SetChecked(false)
+		v.useFactsCheck.Disable()
+		v.viewFactsButton.Disable()
+		v.factsLabel.SetText(i18n.T("No fact sheet yet. Click Extract Facts to pull the quotes, statistics and names from the True sources."))
+		return
+	}
+	v.useFactsCheck.Enable()
+	v.viewFactsButton.Enable()
+	v.factsLabel.SetText(i18n.Tf("%d quotes, %d statistics, %d names", len(v.factSheet.Quotes), len(v.factSheet.Statistics), len(v.factSheet.Entities)))
+}
+
+// extractFactSheet asks the model for the quotes, statistics and named
+// entities of the True sources in the background and shows them for review.
+func (v *ContentGeneratorView) extractFactSheet() {
+	var sources []string
+	for _, source := range v.sourceContents {
+		if !source.IsSample {
+			sources = append(sources, fmt.Sprintf("Source Title: %s\nContent:\n%s", source.Title, source.Content))
+		}
+	}
+	if len(sources) == 0 {
+		ShowError(fmt.Errorf("add at least one True source (not marked Sample) to extract facts from"), v.window)
+		return
+	}
+	model := v.chosenModel()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		output, err := v.inferenceService.Generate(inference.GetFactSheetPrompt(strings.Join(sources, "\n\n--- Next Source ---\n\n")), generateOptions(ctx, model, inference.TaskStructured))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var sheet facts.Sheet
+		if err == nil {
+			sheet, err = facts.Parse(output)
+		}
+		if err == nil && sheet.IsEmpty() {
+			err = fmt.Errorf("the model found no quotes, statistics or names in the sources")
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to extract facts: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showFactSheet(sheet, true) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.extractFactSheet", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Fact Sheet", i18n.Tf("%d sources", len(sources)), run)
+}
+
+// showFactSheet shows a fact sheet. A newly extracted sheet can be used for
+// the next generations or discarded.
+func (v *ContentGeneratorView) showFactSheet(sheet facts.Sheet, extracted bool) {
+	content := widget.NewRichTextFromMarkdown(sheet.Markdown())
+	content.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Fact Sheet"), container.NewScroll(content), v.window)
+	buttons := []fyne.CanvasObject{
+		newCopyButton(v.window, i18n.T("Copy"), sheet.Markdown),
+	}
+	if extracted {
+		buttons = append(buttons,
+			widget.NewButton(i18n.T("Discard"), func() { d.Hide() }),
+			widget.NewButtonWithIcon(i18n.T("Use in Generation"), theme.ConfirmIcon(), func() {
+				d.Hide()
+				v.factSheet = &sheet
+				v.refreshFactSheet()
+				v.useFactsCheck.SetChecked(true)
+			}))
+	} else {
+		buttons = append(buttons, widget.NewButton(i18n.T("Close"), func() { d.Hide() }))
+	}
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(640, 560))
+	d.Show()
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// FallbackPolicyView edits which errors make the delegator try the next
+// model, and how many models it tries.
+type FallbackPolicyView struct {
+	container *fyne.Container
+	window    fyne.Window
+
+	retryablePatternsEntry *EditorEntry
+	retryableCodesEntry    *widget.Entry
+	neverPatternsEntry     *EditorEntry
+	neverCodesEntry        *widget.Entry
+	maxAttemptsEntry       *widget.Entry
+	otherErrorsCheck       *widget.Check
+	latencyCheck           *widget.Check
+	statusLabel            *widget.Label
+}
+
+// NewFallbackPolicyView creates a new fallback policy view
+func NewFallbackPolicyView(window fyne.Window) *FallbackPolicyView {
+	view := &FallbackPolicyView{window: window}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the fallback policy view
+func (v *FallbackPolicyView) initialize() {
+	v.retryablePatternsEntry = NewEditorEntry()
+	v.retryablePatternsEntry.SetMinRowsVisible(3)
+	v.neverPatternsEntry = NewEditorEntry()
+	v.neverPatternsEntry.SetMinRowsVisible(3)
+	v.retryableCodesEntry = widget.NewEntry()
+	v.retryableCodesEntry.SetPlaceHolder("429, 500, 503")
+	v.neverCodesEntry = widget.NewEntry()
+	v.neverCodesEntry.SetPlaceHolder("401, 403")
+	v.maxAttemptsEntry = widget.NewEntry()
+	v.maxAttemptsEntry.SetPlaceHolder(i18n.T("0 for no limit"))
+	v.otherErrorsCheck = widget.NewCheck(i18n.T("Fall back on errors no rule matches"), nil)
+	v.latencyCheck = widget.NewCheck(i18n.T("Try the fastest reachable provider first (see the provider status in Test Inference)"), nil)
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Policy"), theme.DocumentSaveIcon(), v.save)
+	resetButton := widget.NewButton(i18n.T("Reset to Defaults"), func() { v.apply(inference.DefaultFallbackPolicy()) })
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Fallback Policy")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("When a model fails, the next configured model is tried if the error matches a retryable rule. Never-fallback rules, such as a rejected API key, win and end the request with the error. Patterns match anywhere in the error, ignoring case, one per line.")),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Retryable errors:"), v.retryablePatternsEntry),
+			widget.NewFormItem(i18n.T("Retryable status codes:"), v.retryableCodesEntry),
+			widget.NewFormItem(i18n.T("Never fall back on:"), v.neverPatternsEntry),
+			widget.NewFormItem(i18n.T("Never fall back on status codes:"), v.neverCodesEntry),
+			widget.NewFormItem(i18n.T("Max fallback attempts:"), v.maxAttemptsEntry),
+		),
+		v.otherErrorsCheck,
+		v.latencyCheck,
+		container.NewHBox(saveButton, resetButton),
+		v.statusLabel,
+	)
+	v.load()
+}
+
+// load shows the policy the delegator follows.
+func (v *FallbackPolicyView) load() {
+	policy := inference.CurrentFallbackPolicy()
+	v.retryablePatternsEntry.SetText(strings.Join(policy.RetryablePatterns, "\n"))
+	v.retryableCodesEntry.SetText(formatStatusCodes(policy.RetryableStatusCodes))
+	v.neverPatternsEntry.SetText(strings.Join(policy.NeverFallbackPatterns, "\n"))
+	v.neverCodesEntry.SetText(formatStatusCodes(policy.NeverFallbackStatusCodes))
+	v.maxAttemptsEntry.SetText(strconv.Itoa(policy.MaxFallbackAttempts))
+	v.otherErrorsCheck.SetChecked(policy.FallbackOnOtherErrors)
+	v.latencyCheck.SetChecked(policy.LatencyAware)
+	if policy.MaxFallbackAttempts > 0 {
+		v.statusLabel.SetText(i18n.Tf("At most %d fallback models are tried per request.", policy.MaxFallbackAttempts))
+	} else {
+		v.statusLabel.SetText(i18n.T("Every configured model may be tried."))
+	}
+}
+
+// save stores the entered policy, which the next request follows.
+func (v *FallbackPolicyView) save() {
+	retryableCodes, err := parseStatusCodes(v.retryableCodesEntry.Text)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	neverCodes, err := parseStatusCodes(v.neverCodesEntry.Text)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	maxAttempts := 0
+	if text := strings.TrimSpace(v.maxAttemptsEntry.Text); text != "" {
+		if maxAttempts, err = strconv.Atoi(text); err != nil || maxAttempts < 0 {
+			ShowError(fmt.Errorf("the maximum number of fallback attempts must be a whole number, 0 for no limit"), v.window)
+			return
+		}
+	}
+	v.apply(inference.FallbackPolicy{
+		RetryablePatterns:        nonEmptyLines(v.retryablePatternsEntry.Text),
+		RetryableStatusCodes:     retryableCodes,
+		NeverFallbackPatterns:    nonEmptyLines(v.neverPatternsEntry.Text),
+		NeverFallbackStatusCodes: neverCodes,
+		MaxFallbackAttempts:      maxAttempts,
+		FallbackOnOtherErrors:    v.otherErrorsCheck.Checked,
+		LatencyAware:             v.latencyCheck.Checked,
+	})
+}
+
+// apply saves a policy and shows it.
+func (v *FallbackPolicyView) apply(policy inference.FallbackPolicy) {
+	if err := inference.SetFallbackPolicy(policy); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	v.load()
+}
+
+// Container returns the container for the view
+func (v *FallbackPolicyView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// parseStatusCodes reads HTTP status codes separated by commas or spaces.
+func parseStatusCodes(text string) ([]int, error) {
+	var codes []int
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		code, err := strconv.Atoi(field)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q", field)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// formatStatusCodes writes status codes separated by commas.
+func formatStatusCodes(codes []int) string {
+	fields := make([]string, len(codes))
+	for i, code := range codes {
+		fields[i] = strconv.Itoa(code)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// nonEmptyLines returns the trimmed lines of text that aren't blank.
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
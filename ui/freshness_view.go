@@ -0,0 +1,363 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Inference_Engine/analytics"
+	"Inference_Engine/audit"
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxFreshnessPages caps the pages one freshness scan sends to the model,
+// oldest first.
+const maxFreshnessPages = 100
+
+// FreshnessView scans the connected site for pages that have not been
+// modified in a while, has the model score them for outdated references and
+// lists the ones worth refreshing.
+type FreshnessView struct {
+	container        fyne.CanvasObject
+	store            *audit.FreshnessStore
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+	jobQueue         *jobs.Queue
+	generatorView    *ContentGeneratorView
+	analytics        *analytics.Service // Scans declining pages first and notes their traffic; nil disables it
+	window           fyne.Window
+
+	monthsEntry   *widget.Entry
+	scanButton    *widget.Button
+	resultList    *widget.List
+	detailLabel   *widget.Label
+	refreshButton *widget.Button
+	dismissButton *widget.Button
+
+	// Data
+	report   []audit.PageFreshness
+	selected int
+}
+
+// NewFreshnessView creates a new FreshnessView
+func NewFreshnessView(store *audit.FreshnessStore, wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, window fyne.Window) *FreshnessView {
+	view := &FreshnessView{
+		store:            store,
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		window:           window,
+		selected:         -1,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *FreshnessView) initialize() {
+	v.monthsEntry = widget.NewEntry()
+	v.monthsEntry.SetText(strconv.Itoa(audit.DefaultStaleMonths))
+	v.scanButton = widget.NewButton(i18n.T("Scan Site"), v.scan)
+
+	v.resultList = widget.NewList(
+		func() int {
+			return len(v.report)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template freshness result")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.report) {
+				return
+			}
+			result := v.report[id]
+			text := i18n.Tf("%d/100  %s (modified %s)", result.Score, result.Title, result.Modified.Format("2006-01-02"))
+			if trend := (analytics.PageTrend{Views: result.Views, PreviousViews: result.PreviousViews}); trend.Declining() {
+				text += fmt.Sprintf("  ↓%.0f%%", -trend.Change()*100)
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	v.resultList.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.updateDetails()
+	}
+	v.resultList.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.updateDetails()
+	}
+
+	v.detailLabel = widget.NewLabel("")
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.refreshButton = widget.NewButton(i18n.T("Refresh in Generator"), v.refreshInGenerator)
+	v.dismissButton = widget.NewButton(i18n.T("Dismiss"), v.dismiss)
+
+	v.container = newReadingOrderBorder(
+		container.NewVBox( // Top
+			widget.NewForm(widget.NewFormItem(i18n.T("Not modified in (months):"),
+				container.NewBorder(nil, nil, nil, v.scanButton, v.monthsEntry))),
+			widget.NewLabel(i18n.T("Pages to refresh, most outdated first:")),
+		),
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			container.NewHBox(layout.NewSpacer(), v.dismissButton, v.refreshButton),
+		),
+		nil, // Left
+		nil, // Right
+		container.NewVSplit(v.resultList, container.NewVScroll(v.detailLabel)),
+	)
+	v.reload()
+}
+
+// SetJobQueue sets the queue that scans are submitted to
+func (v *FreshnessView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetContentGeneratorView sets the view that refreshes are prepared in
+func (v *FreshnessView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
+	v.generatorView = generatorView
+}
+
+// SetAnalytics sets the service whose page views put pages with declining
+// traffic first in scans.
+func (v *FreshnessView) SetAnalytics(service *analytics.Service) {
+	v.analytics = service
+}
+
+// SiteChanged shows the report of the newly connected site.
+func (v *FreshnessView) SiteChanged() {
+	v.selected = -1
+	v.reload()
+}
+
+// reload reads the current site's report from the store, keeping the
+// selected page selected.
+func (v *FreshnessView) reload() {
+	selectedID := -1
+	if v.selected >= 0 && v.selected < len(v.report) {
+		selectedID = v.report[v.selected].PageID
+	}
+	v.report = v.store.Report(v.wpService.GetCurrentSiteName())
+	v.selected = -1
+	v.resultList.UnselectAll()
+	v.resultList.Refresh()
+	for i, result := range v.report {
+		if result.PageID == selectedID {
+			v.resultList.Select(i)
+			return
+		}
+	}
+	v.updateDetails()
+}
+
+// updateDetails describes the selected page's outdated references.
+func (v *FreshnessView) updateDetails() {
+	if v.selected < 0 || v.selected >= len(v.report) {
+		v.refreshButton.Disable()
+		v.dismissButton.Disable()
+		if len(v.report) == 0 {
+			v.detailLabel.SetText(i18n.T("No scan results yet. Scan the site to find pages with outdated references."))
+		} else {
+			v.detailLabel.SetText(i18n.T("Select a page to see what needs refreshing."))
+		}
+		return
+	}
+	v.refreshButton.Enable()
+	v.dismissButton.Enable()
+
+	result := v.report[v.selected]
+	var b strings.Builder
+	b.WriteString(result.Summary)
+	if len(result.References) > 0 {
+		b.WriteString("\n\n")
+		for _, ref := range result.References {
+			fmt.Fprintf(&b, "• \"%s\": %s", ref.Text, ref.Reason)
+			if ref.Suggestion != "" {
+				b.WriteString(" " + i18n.Tf("Suggestion: %s", ref.Suggestion))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if result.Views > 0 || result.PreviousViews > 0 {
+		b.WriteString("\n" + i18n.Tf("Views in the last %d days: %d (previous %d days: %d)", analytics.DefaultDays, result.Views, analytics.DefaultDays, result.PreviousViews))
+	}
+	b.WriteString("\n" + i18n.Tf("Scanned %s", result.Scanned.Format("2006-01-02 15:04")))
+	v.detailLabel.SetText(strings.TrimSpace(b.String()))
+}
+
+// scan scores every page not modified within the chosen number of months,
+// oldest first, adding each result to the report as it arrives.
+func (v *FreshnessView) scan() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	months, err := strconv.Atoi(strings.TrimSpace(v.monthsEntry.Text))
+	if err != nil || months <= 0 {
+		ShowError(fmt.Errorf("enter the number of months as a whole number above 0"), v.window)
+		return
+	}
+	site := v.wpService.GetCurrentSiteName()
+	cutoff := time.Now().AddDate(0, -months, 0)
+	v.scanButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.scanButton.Enable)
+		scanned, err := v.scanPages(ctx, site, cutoff, progress)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runOnUI(func() {
+			v.reload()
+			if err != nil {
+				ShowError(fmt.Errorf("freshness scan failed: %w", err), v.window)
+				return
+			}
+			dialog.ShowInformation(i18n.T("Scan Complete"), i18n.Tf("Scored %d pages not modified since %s.", scanned, cutoff.Format("2006-01-02")), v.window)
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("FreshnessView.scan", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Freshness Scan", i18n.Tf("Pages not modified in %d months", months), run)
+}
+
+// scanPages scores the stale pages and returns how many were scored. Pages
+// the model fails on are skipped; the scan only fails if all of them do.
+func (v *FreshnessView) scanPages(ctx context.Context, site string, cutoff time.Time, progress jobs.ProgressFunc) (int, error) {
+	progress(-1, i18n.T("Finding stale pages"))
+	pages, err := v.wpService.QueryPages(wordpress.PageFilter{ModifiedBefore: cutoff, SortBy: wordpress.SortByModifiedAsc}, maxFreshnessPages)
+	if err != nil {
+		return 0, err
+	}
+	trends := v.trafficFirst(ctx, pages)
+	now := time.Now()
+	scanned := 0
+	var lastErr error
+	for i, page := range pages {
+		if ctx.Err() != nil {
+			return scanned, ctx.Err()
+		}
+		progress(float64(i)/float64(len(pages)), page.Title)
+		result, err := v.scorePage(ctx, page, now)
+		if trend, ok := analytics.Lookup(trends, page.Link); ok {
+			result.Views, result.PreviousViews = trend.Views, trend.PreviousViews
+		}
+		if err != nil {
+			logger.Warn("FreshnessView: could not score page", "page_id", page.ID, "error", err)
+			lastErr = err
+			continue
+		}
+		if err := v.store.Add(site, result); err != nil {
+			return scanned, err
+		}
+		scanned++
+		runOnUI(v.reload)
+	}
+	logger.Info("FreshnessView: scan finished", "site", site, "pages", len(pages), "scored", scanned)
+	if scanned == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return scanned, nil
+}
+
+// trafficFirst moves the pages with declining traffic, most views lost
+// first, to the front so they are scored first, and returns the site's
+// trends. Without analytics, or if they can't be read, the order is kept.
+func (v *FreshnessView) trafficFirst(ctx context.Context, pages wordpress.PageList) []analytics.PageTrend {
+	if v.analytics == nil {
+		return nil
+	}
+	trends, err := v.analytics.Trends(ctx, v.wpService.GetSiteURL())
+	if err != nil {
+		logger.Warn("FreshnessView: scanning without analytics", "error", err)
+		return nil
+	}
+	lost := func(page wordpress.Page) int {
+		if trend, ok := analytics.Lookup(trends, page.Link); ok && trend.Declining() {
+			return trend.PreviousViews - trend.Views
+		}
+		return 0
+	}
+	sort.SliceStable(pages, func(i, j int) bool { return lost(pages[i]) > lost(pages[j]) })
+	return trends
+}
+
+// scorePage has the model score one page's outdated references.
+func (v *FreshnessView) scorePage(ctx context.Context, page wordpress.Page, now time.Time) (audit.PageFreshness, error) {
+	result := audit.PageFreshness{PageID: page.ID, Title: page.Title, Link: page.Link, Modified: page.Modified, Scanned: now}
+	content, err := v.wpService.GetPageContent(page.ID)
+	if err != nil {
+		return result, err
+	}
+	text := utils.HTMLToMarkdown(content)
+	var hints string
+	for _, hint := range audit.DatedHints(text, now) {
+		hints += "- " + hint + "\n"
+	}
+	output, err := v.inferenceService.Generate(
+		inference.GetFreshnessPrompt(now.Format("2006-01-02"), page.Modified.Format("2006-01-02"), strings.TrimSuffix(hints, "\n"), text),
+		inference.GenerateOptions{Context: ctx, Task: inference.TaskStructured})
+	if err != nil {
+		return result, err
+	}
+	return result, audit.ParseFreshness(output, &result)
+}
+
+// refreshInGenerator adds the selected page to the generator as a True
+// source, with a request to update the outdated references.
+func (v *FreshnessView) refreshInGenerator() {
+	if v.selected < 0 || v.selected >= len(v.report) || v.generatorView == nil {
+		return
+	}
+	result := v.report[v.selected]
+	progress := dialog.NewProgressInfinite(i18n.T("Loading Content"), i18n.T("Fetching page content for generator..."), v.window)
+	progress.Show()
+	go func() {
+		defer crash.Recover("FreshnessView.refreshInGenerator")
+		content, err := v.wpService.GetPageContent(result.PageID)
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				ShowError(fmt.Errorf("failed to load content for '%s': %w", result.Title, err), v.window)
+				return
+			}
+			v.generatorView.AddSourceContent(result.Title, content, "WordPress", result.Link, result.PageID, false)
+			v.generatorView.PrefillRequest(result.RefreshPrompt(), "")
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.Tf("Added '%s' and its refresh request to the content generator.", result.Title), v.window)
+		})
+	}()
+}
+
+// dismiss removes the selected page from the report.
+func (v *FreshnessView) dismiss() {
+	if v.selected < 0 || v.selected >= len(v.report) {
+		return
+	}
+	if err := v.store.Dismiss(v.wpService.GetCurrentSiteName(), v.report[v.selected].PageID); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	v.reload()
+}
+
+// Container returns the container for the view
+func (v *FreshnessView) Container() fyne.CanvasObject {
+	return v.container
+}
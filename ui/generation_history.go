@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showGenerationHistory opens a dialog listing the stored drafts, newest
+// first, with a preview of the selected one. Restore hands the draft to
+// onRestore and closes the dialog.
+func showGenerationHistory(store *history.Store, window fyne.Window, onRestore func(history.Draft)) {
+	var drafts []history.Draft
+	selected := -1
+
+	preview := widget.NewLabel(i18n.T("Select a draft to preview it."))
+	preview.Wrapping = fyne.TextWrapWord
+	details := widget.NewLabel("")
+	details.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int { return len(drafts) },
+		func() fyne.CanvasObject { return widget.NewLabel("Template draft label") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(drafts) {
+				obj.(*widget.Label).SetText(draftLabel(drafts[id]))
+			}
+		},
+	)
+
+	restoreButton := widget.NewButtonWithIcon(i18n.T("Restore"), theme.HistoryIcon(), nil)
+	deleteButton := widget.NewButtonWithIcon(i18n.T("Delete"), theme.DeleteIcon(), nil)
+	reviewButton := widget.NewButtonWithIcon(i18n.T("Review..."), theme.AccountIcon(), nil)
+	restoreButton.Disable()
+	deleteButton.Disable()
+	reviewButton.Disable()
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder(i18n.T("Search prompts, models and outputs..."))
+
+	reload := func() {
+		drafts = store.Search(search.Text)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		preview.SetText(i18n.Tf("%d drafts", len(drafts)))
+		details.SetText("")
+		restoreButton.Disable()
+		deleteButton.Disable()
+		reviewButton.Disable()
+	}
+	search.OnChanged = func(string) { reload() }
+
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= len(drafts) {
+			return
+		}
+		selected = id
+		d := drafts[id]
+		details.SetText(draftDetails(d))
+		preview.SetText(d.Output)
+		restoreButton.Enable()
+		deleteButton.Enable()
+		reviewButton.Enable()
+	}
+
+	var d dialog.Dialog
+	restoreButton.OnTapped = func() {
+		if selected < 0 || selected >= len(drafts) {
+			return
+		}
+		draft := drafts[selected]
+		d.Hide()
+		onRestore(draft)
+	}
+	deleteButton.OnTapped = func() {
+		if selected < 0 || selected >= len(drafts) {
+			return
+		}
+		if err := store.Delete(drafts[selected].ID); err != nil {
+			ShowError(err, window)
+		}
+		reload()
+	}
+	reviewButton.OnTapped = func() {
+		if selected < 0 || selected >= len(drafts) {
+			return
+		}
+		showDraftReview(store, window, drafts[selected].ID, reload)
+	}
+	approvalCheck := widget.NewCheck(i18n.T("Require approval before saving to WordPress"), PrefRequireApproval.Set)
+	approvalCheck.SetChecked(requireApproval())
+	clearButton := widget.NewButton(i18n.T("Clear History"), func() {
+		dialog.ShowConfirm(i18n.T("Clear History"), i18n.T("Delete every saved draft? This cannot be undone."), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := store.Clear(); err != nil {
+				ShowError(err, window)
+			}
+			reload()
+		}, window)
+	})
+
+	previewPane := newReadingOrderBorder(details, nil, nil, nil, container.NewScroll(preview))
+	split := container.NewHSplit(list, previewPane)
+	split.Offset = 0.4
+	content := newReadingOrderBorder(
+		search,
+		container.NewHBox(restoreButton, reviewButton, deleteButton, layout.NewSpacer(), approvalCheck, clearButton),
+		nil, nil,
+		split,
+	)
+
+	d = dialog.NewCustom(i18n.T("Generation History"), i18n.T("Close"), content, window)
+	d.Resize(fyne.NewSize(900, 600))
+	reload()
+	d.Show()
+}
+
+// draftLabel is the one-line list entry for a draft. Drafts under review
+// show their state.
+func draftLabel(d history.Draft) string {
+	label := fmt.Sprintf("%s  %s  %s", d.Created.Format("2006-01-02 15:04"), d.Model, historyLabel(d.Prompt))
+	if d.Review != "" && d.Review != history.ReviewDraft {
+		label = fmt.Sprintf("[%s]  %s", i18n.T(d.Review.Label()), label)
+	}
+	return label
+}
+
+// draftDetails describes where a draft came from.
+func draftDetails(d history.Draft) string {
+	lines := []string{
+		i18n.Tf("Created: %s", d.Created.Format("2006-01-02 15:04:05")),
+		i18n.Tf("Model: %s", d.Model),
+		i18n.Tf("Prompt: %s", historyLabel(d.Prompt)),
+		i18n.Tf("Review: %s", i18n.T(d.Review.Label())),
+	}
+	if d.Instruction != "" {
+		lines = append(lines, i18n.Tf("Instructions: %s", historyLabel(d.Instruction)))
+	}
+	if len(d.SourceTitles) > 0 {
+		lines = append(lines, i18n.Tf("Sources (%s): %s", d.SourcesHash, strings.Join(d.SourceTitles, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Settings for condensing oversized sources in the generator. The mode and
+// strategy are indexes into chunkModes and chunkStrategies, and an empty
+// notes model means the generation model.
+var (
+	PrefChunkSources     = settings.NewKey("generator.chunk_sources", true)
+	PrefChunkMode        = settings.NewKey("generator.chunk_mode", 0)
+	PrefChunkStrategy    = settings.NewKey("generator.chunk_strategy", 0)
+	PrefChunkMapModel    = settings.NewKey("generator.chunk_map_model", "")
+	PrefSummarizeSamples = settings.NewKey("generator.summarize_samples", false)
+)
+
+// sampleStyleMinTokens is the size below which Sample sources are sent as
+// they are, since a style description would save little.
+const sampleStyleMinTokens = 400
+
+// sameModelOption is the notes model option that uses the generation model.
+func sameModelOption() string {
+	return i18n.T("Same as the generation model")
+}
+
+// chunkStrategies are the chunking strategies offered, in the order of
+// chunkStrategyLabels.
+var chunkStrategies = []inference.ChunkingStrategy{
+	inference.ChunkByTokenCount,
+	inference.ChunkBySentence,
+	inference.ChunkByParagraph,
+}
+
+// chunkStrategyLabels names chunkStrategies in the active language.
+func chunkStrategyLabels() []string {
+	return []string{
+		i18n.T("By size, keeping paragraphs whole"),
+		i18n.T("By size, keeping sentences whole"),
+		i18n.T("One request per paragraph"),
+	}
+}
+
+// chunkModes are the processing modes offered, in the order of
+// chunkModeLabels.
+var chunkModes = []inference.ProcessingMode{
+	inference.ParallelProcessing,
+	inference.SequentialProcessing,
+}
+
+// chunkModeLabels names chunkModes in the active language.
+func chunkModeLabels() []string {
+	return []string{
+		i18n.T("Parallel (faster)"),
+		i18n.T("Sequential (each section sees the previous notes)"),
+	}
+}
+
+// newChunkingControls creates the Advanced section of the generation
+// settings, which configures how sources too large for the model are
+// condensed.
+func (v *ContentGeneratorView) newChunkingControls() fyne.CanvasObject {
+	v.chunkCheck = widget.NewCheck(i18n.T("Condense sources too large for the model"), func(on bool) {
+		PrefChunkSources.Set(on)
+		if on {
+			v.chunkModeSelect.Enable()
+			v.chunkStrategySelect.Enable()
+			v.chunkMapModelSelect.Enable()
+		} else {
+			v.chunkModeSelect.Disable()
+			v.chunkStrategySelect.Disable()
+			v.chunkMapModelSelect.Disable()
+		}
+	})
+
+	v.chunkModeSelect = widget.NewSelect(chunkModeLabels(), nil)
+	v.chunkModeSelect.SetSelectedIndex(min(max(PrefChunkMode.Get(), 0), len(chunkModes)-1))
+	v.chunkModeSelect.OnChanged = func(string) { PrefChunkMode.Set(v.chunkModeSelect.SelectedIndex()) }
+
+	v.chunkStrategySelect = widget.NewSelect(chunkStrategyLabels(), nil)
+	v.chunkStrategySelect.SetSelectedIndex(min(max(PrefChunkStrategy.Get(), 0), len(chunkStrategies)-1))
+	v.chunkStrategySelect.OnChanged = func(string) { PrefChunkStrategy.Set(v.chunkStrategySelect.SelectedIndex()) }
+
+	v.chunkMapModelSelect = widget.NewSelect([]string{sameModelOption()}, nil)
+	v.refreshChunkMapModels()
+	v.chunkMapModelSelect.OnChanged = func(string) { PrefChunkMapModel.Set(v.chunkMapModel()) }
+
+	v.chunkCheck.SetChecked(PrefChunkSources.Get())
+	v.summarizeSamplesCheck = widget.NewCheck(i18n.T("Summarize long Sample sources into a style description"), func(on bool) {
+		PrefSummarizeSamples.Set(on)
+	})
+	v.summarizeSamplesCheck.SetChecked(PrefSummarizeSamples.Get())
+	samplesNote := widget.NewLabel(i18n.T("Sample sources only show the style, so long ones can be replaced by a short description of it, written once per set of samples."))
+	samplesNote.Wrapping = fyne.TextWrapWord
+	confirmCost := widget.NewCheck(i18n.T("Show the estimated tokens and cost before generating"), PrefConfirmGenerationCost.Set)
+	confirmCost.SetChecked(PrefConfirmGenerationCost.Get())
+	PrefConfirmGenerationCost.Watch(func(on bool) { runOnUI(func() { confirmCost.SetChecked(on) }) })
+
+	note := widget.NewLabel(i18n.T("True sources that would make the prompt larger than the model accepts are split into chunks; the model notes what each chunk says about the request, and the article is written from the notes. A cheaper notes model cuts the cost of very large sources, while the generation model still writes the article."))
+	note.Wrapping = fyne.TextWrapWord
+	form := widget.NewForm(
+		widget.NewFormItem("", v.chunkCheck),
+		widget.NewFormItem(i18n.T("Chunking:"), v.chunkStrategySelect),
+		widget.NewFormItem(i18n.T("Processing:"), v.chunkModeSelect),
+		widget.NewFormItem(i18n.T("Notes model:"), v.chunkMapModelSelect),
+	)
+	return widget.NewAccordion(widget.NewAccordionItem(i18n.T("Advanced"), container.NewVBox(note, form, widget.NewSeparator(), samplesNote, v.summarizeSamplesCheck, widget.NewSeparator(), confirmCost)))
+}
+
+// refreshChunkMapModels lists the available models as notes models, keeping
+// the saved choice if it is still available.
+func (v *ContentGeneratorView) refreshChunkMapModels() {
+	if v.chunkMapModelSelect == nil {
+		return // The Advanced section isn't built yet
+	}
+	options := []string{sameModelOption()}
+	if v.inferenceService != nil {
+		for _, model := range v.inferenceService.Models() {
+			if model.Available {
+				options = append(options, model.ID())
+			}
+		}
+	}
+	saved := PrefChunkMapModel.Get()
+	onChanged := v.chunkMapModelSelect.OnChanged
+	v.chunkMapModelSelect.OnChanged = nil // Refreshing the list doesn't change the saved choice
+	v.chunkMapModelSelect.Options = options
+	v.chunkMapModelSelect.SetSelectedIndex(0)
+	for _, option := range options[1:] {
+		if option == saved {
+			v.chunkMapModelSelect.SetSelected(option)
+		}
+	}
+	v.chunkMapModelSelect.OnChanged = onChanged
+}
+
+// chunkMapModel returns the chosen notes model, or "" for the generation
+// model.
+func (v *ContentGeneratorView) chunkMapModel() string {
+	if v.chunkMapModelSelect.SelectedIndex() <= 0 {
+		return ""
+	}
+	return v.chunkMapModelSelect.Selected
+}
+
+// chunkingOptions returns the chunking options chosen in the Advanced
+// section, and whether oversized sources are condensed at all.
+func (v *ContentGeneratorView) chunkingOptions() (inference.ChunkingOptions, bool) {
+	options := inference.DefaultChunkingOptions
+	if i := v.chunkStrategySelect.SelectedIndex(); i >= 0 {
+		options.Strategy = chunkStrategies[i]
+	}
+	if i := v.chunkModeSelect.SelectedIndex(); i >= 0 {
+		options.Mode = chunkModes[i]
+	}
+	options.MapModel = v.chunkMapModel()
+	return options, v.chunkCheck.Checked
+}
+
+// condenseOversizedSources returns the True sources as they should go into
+// the prompt: unchanged if the prompt fits the model, otherwise condensed
+// into notes on the request. other is the rest of the prompt and the
+// instructions, which are sent as they are. In sequential mode, showNotes
+// is called from the job with the notes so far as each chunk finishes.
+func (v *ContentGeneratorView) condenseOversizedSources(ctx context.Context, req generationRequest, trueSources, request, other string, showNotes func(string)) (string, inference.TokenUsage, error) {
+	if !req.chunk {
+		return trueSources, inference.TokenUsage{}, nil
+	}
+	limit := v.inferenceService.PromptTokenLimit(req.model)
+	otherTokens := inference.EstimateTokenCount(other)
+	sourceTokens := inference.EstimateTokenCount(trueSources)
+	if limit <= 0 || otherTokens+sourceTokens <= limit {
+		return trueSources, inference.TokenUsage{}, nil
+	}
+	chunking := req.chunking
+	chunkLimit := limit
+	if chunking.MapModel != "" {
+		if mapLimit := v.inferenceService.PromptTokenLimit(chunking.MapModel); mapLimit > 0 {
+			chunkLimit = mapLimit // The notes model reads the chunks
+		}
+	}
+	if chunking.MaxChunkTokens <= 0 || chunking.MaxChunkTokens > chunkLimit/2 {
+		chunking.MaxChunkTokens = chunkLimit / 2 // Leaves room for the notes instruction and the answer
+	}
+	if chunking.Mode == inference.SequentialProcessing && showNotes != nil {
+		var kept []string
+		chunking.OnNotes = func(index, total int, notes string) {
+			if notes != "" {
+				kept = append(kept, notes)
+			}
+			showNotes(i18n.Tf("Notes from the sources, %d of %d sections read. The article is written from them next.", index, total) + "\n\n" + strings.Join(kept, "\n\n"))
+		}
+	}
+	logger.Info("ContentGeneratorView: sources exceed the model's limit, condensing them", "source_tokens", sourceTokens, "other_tokens", otherTokens, "limit", limit, "notes_model", chunking.MapModel)
+	return v.inferenceService.CondenseSources(trueSources, request, chunking, generateOptions(ctx, req.model, inference.TaskLongForm))
+}
+
+// summarizeSamples returns the Sample sources as they should go into the
+// prompt: unchanged if short, otherwise a description of their style, with
+// the tokens used. Descriptions are kept per set of samples, so generating
+// again from the same samples doesn't ask again.
+func (v *ContentGeneratorView) summarizeSamples(ctx context.Context, samples string, count int) (string, inference.TokenUsage, error) {
+	tokens := inference.EstimateTokenCount(samples)
+	if tokens < sampleStyleMinTokens {
+		return samples, inference.TokenUsage{}, nil
+	}
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(samples)))
+	style, ok := v.sampleStyles[key]
+	var usage inference.TokenUsage
+	if !ok {
+		logger.Info("ContentGeneratorView: summarizing Sample sources into a style description", "samples", count, "tokens", tokens)
+		var err error
+		style, usage, err = v.inferenceService.GenerateWithUsage(inference.GetSampleStylePrompt(samples),
+			inference.GenerateOptions{Context: ctx, Task: inference.TaskSummarization})
+		if err != nil {
+			return "", usage, fmt.Errorf("failed to summarize the Sample sources: %w", err)
+		}
+		style = strings.TrimSpace(style)
+		if v.sampleStyles == nil {
+			v.sampleStyles = map[string]string{}
+		}
+		v.sampleStyles[key] = style
+	}
+	return fmt.Sprintf("Style description of %d Sample Sources (follow it instead of seeing them):\n%s", count, style), usage, nil
+}
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/storage"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newPresetControls creates the Presets menu button and the label showing
+// the preset in use, with a button to stop using it.
+func (v *ContentGeneratorView) newPresetControls() (menu *widget.Button, active fyne.CanvasObject) {
+	v.presetLabel = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	clear := widget.NewButtonWithIcon("", theme.CancelIcon(), func() { v.setActivePreset(nil) })
+	clear.Importance = widget.LowImportance
+	v.presetBox = container.NewHBox(v.presetLabel, clear)
+	v.presetBox.Hide()
+
+	menu = widget.NewButtonWithIcon(i18n.T("Presets"), theme.MenuDropDownIcon(), nil)
+	menu.OnTapped = func() {
+		var items []*fyne.MenuItem
+		for _, preset := range v.presets() {
+			preset := preset
+			items = append(items, fyne.NewMenuItem(preset.Name, func() { v.runPreset(preset) }))
+		}
+		if len(items) > 0 {
+			items = append(items, fyne.NewMenuItemSeparator())
+		}
+		items = append(items, fyne.NewMenuItem(i18n.T("Save Current Settings as Preset..."), v.showSavePresetDialog))
+		if len(items) > 2 {
+			items = append(items, fyne.NewMenuItem(i18n.T("Delete Preset..."), v.showDeletePresetDialog))
+		}
+		position := fyne.CurrentApp().Driver().AbsolutePositionForObject(menu).Add(fyne.NewPos(0, menu.Size().Height))
+		widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), v.window.Canvas(), position)
+	}
+	return menu, v.presetBox
+}
+
+// presets returns the saved presets, or none without the state database.
+func (v *ContentGeneratorView) presets() []storage.GenerationPreset {
+	if promptStore == nil {
+		return nil
+	}
+	presets, err := promptStore.Presets()
+	if err != nil {
+		logger.Error("ContentGeneratorView: failed to load presets", "error", err)
+	}
+	return presets
+}
+
+// runPreset applies a preset's settings and starts generating with them.
+func (v *ContentGeneratorView) runPreset(preset storage.GenerationPreset) {
+	if err := v.applyPreset(preset); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	logger.Info("ContentGeneratorView: running preset", "preset", preset.Name)
+	v.generateContent()
+}
+
+// applyPreset fills in the model, prompt template, word target and SEO pass
+// of a preset, and uses its sampling settings until another preset is
+// chosen or it is cleared.
+func (v *ContentGeneratorView) applyPreset(preset storage.GenerationPreset) error {
+	found := false
+	for _, option := range v.selectedModel.Options {
+		if option == preset.Model {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("the preset's model '%s' is not available", preset.Model)
+	}
+	if preset.Template != "" {
+		template, ok := v.promptTemplate(preset.Template)
+		if !ok {
+			return fmt.Errorf("the preset's prompt template '%s' no longer exists", preset.Template)
+		}
+		v.promptEntry.ReplaceText(template.Prompt)
+		v.instructionEntry.ReplaceText(template.Instruction)
+	}
+	v.selectedModel.SetSelected(preset.Model)
+	v.wordCountEntry.SetText("")
+	if preset.WordTarget > 0 {
+		v.wordCountEntry.SetText(strconv.Itoa(preset.WordTarget))
+	}
+	v.seoPassCheck.SetChecked(preset.SEOPass)
+	v.setActivePreset(&preset)
+	return nil
+}
+
+// promptTemplate returns the saved prompt template with name.
+func (v *ContentGeneratorView) promptTemplate(name string) (storage.PromptTemplate, bool) {
+	if promptStore == nil {
+		return storage.PromptTemplate{}, false
+	}
+	templates, err := promptStore.Templates()
+	if err != nil {
+		logger.Error("ContentGeneratorView: failed to load prompt templates", "error", err)
+	}
+	for _, t := range templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return storage.PromptTemplate{}, false
+}
+
+// setActivePreset shows the preset in use and uses its sampling settings,
+// or goes back to the models' defaults for nil.
+func (v *ContentGeneratorView) setActivePreset(preset *storage.GenerationPreset) {
+	v.presetParams = inference.GenerationParams{}
+	v.activePreset = ""
+	if preset == nil {
+		v.presetBox.Hide()
+		return
+	}
+	v.activePreset = preset.Name
+	v.presetParams = inference.GenerationParams{Temperature: preset.Temperature, MaxTokens: preset.MaxTokens}
+	text := i18n.Tf("Preset: %s", preset.Name)
+	if settings := presetParamsSummary(*preset); settings != "" {
+		text += " (" + settings + ")"
+	}
+	v.presetLabel.SetText(text)
+	v.presetBox.Show()
+}
+
+// presetParamsSummary describes a preset's sampling settings, or returns ""
+// if it keeps the defaults.
+func presetParamsSummary(preset storage.GenerationPreset) string {
+	var parts []string
+	if preset.Temperature != nil {
+		parts = append(parts, i18n.Tf("temperature %s", strconv.FormatFloat(*preset.Temperature, 'f', -1, 64)))
+	}
+	if preset.MaxTokens > 0 {
+		parts = append(parts, i18n.Tf("at most %d tokens", preset.MaxTokens))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showSavePresetDialog saves the current model, word target and SEO pass,
+// with the sampling settings and template entered, as a preset.
+func (v *ContentGeneratorView) showSavePresetDialog() {
+	if promptStore == nil {
+		ShowError(fmt.Errorf("presets are unavailable (see the log for why the state database could not be opened)"), v.window)
+		return
+	}
+	model := v.selectedModel.Selected
+	if !v.modelChosen() {
+		ShowError(fmt.Errorf("please select a valid model"), v.window)
+		return
+	}
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(i18n.T("e.g. Client X blog post"))
+	temperatureEntry := widget.NewEntry()
+	temperatureEntry.SetPlaceHolder(i18n.T("Model default"))
+	maxTokensEntry := widget.NewEntry()
+	maxTokensEntry.SetPlaceHolder(i18n.T("Model default"))
+	if v.presetParams.Temperature != nil {
+		temperatureEntry.SetText(strconv.FormatFloat(*v.presetParams.Temperature, 'f', -1, 64))
+	}
+	if v.presetParams.MaxTokens > 0 {
+		maxTokensEntry.SetText(strconv.Itoa(v.presetParams.MaxTokens))
+	}
+	// Keeping the prompt and saving the current one come before the saved templates
+	templateOptions := []string{i18n.T("None (keep the prompt and instructions)"), i18n.T("Current prompt and instructions")}
+	if templates, err := promptStore.Templates(); err == nil {
+		for _, t := range templates {
+			templateOptions = append(templateOptions, t.Name)
+		}
+	}
+	templateSelect := widget.NewSelect(templateOptions, nil)
+	templateSelect.SetSelectedIndex(1)
+	note := widget.NewLabel(i18n.Tf("Saves the model (%s), the target word count and the SEO pass as they are now.", model))
+	note.Wrapping = fyne.TextWrapWord
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Name:"), nameEntry),
+		widget.NewFormItem(i18n.T("Temperature:"), temperatureEntry),
+		widget.NewFormItem(i18n.T("Max tokens:"), maxTokensEntry),
+		widget.NewFormItem(i18n.T("Template:"), templateSelect),
+		widget.NewFormItem("", note),
+	}
+	form := dialog.NewForm(i18n.T("Save Preset"), i18n.T("Save"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		preset := storage.GenerationPreset{Name: strings.TrimSpace(nameEntry.Text), Model: model, SEOPass: v.seoPassCheck.Checked}
+		if preset.Name == "" {
+			ShowError(fmt.Errorf("please enter a name for the preset"), v.window)
+			return
+		}
+		if text := strings.TrimSpace(temperatureEntry.Text); text != "" {
+			temperature, err := strconv.ParseFloat(text, 64)
+			if err != nil || temperature < 0 || temperature > 2 {
+				ShowError(fmt.Errorf("the temperature must be a number from 0 to 2"), v.window)
+				return
+			}
+			preset.Temperature = &temperature
+		}
+		if text := strings.TrimSpace(maxTokensEntry.Text); text != "" {
+			maxTokens, err := strconv.Atoi(text)
+			if err != nil || maxTokens <= 0 {
+				ShowError(fmt.Errorf("the max tokens must be a positive whole number"), v.window)
+				return
+			}
+			preset.MaxTokens = maxTokens
+		}
+		targets, err := v.seoTargets()
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		preset.WordTarget = targets.WordCount
+		switch templateSelect.SelectedIndex() {
+		case 0:
+		case 1:
+			// The current prompt and instructions are saved as a template
+			// named after the preset
+			template := storage.PromptTemplate{Name: preset.Name, Prompt: v.promptEntry.Text, Instruction: v.instructionEntry.Text}
+			if err := promptStore.SaveTemplate(template); err != nil {
+				ShowError(err, v.window)
+				return
+			}
+			preset.Template = template.Name
+		default:
+			preset.Template = templateSelect.Selected
+		}
+		if err := promptStore.SavePreset(preset); err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		logger.Info("ContentGeneratorView: saved preset", "preset", preset.Name, "template", preset.Template)
+		v.setActivePreset(&preset)
+	}, v.window)
+	form.Resize(fyne.NewSize(460, form.MinSize().Height))
+	form.Show()
+}
+
+// showDeletePresetDialog deletes a chosen preset. Its template is kept, as
+// other presets may use it.
+func (v *ContentGeneratorView) showDeletePresetDialog() {
+	var names []string
+	for _, preset := range v.presets() {
+		names = append(names, preset.Name)
+	}
+	nameSelect := widget.NewSelect(names, nil)
+	dialog.ShowForm(i18n.T("Delete Preset"), i18n.T("Delete"), i18n.T("Cancel"),
+		[]*widget.FormItem{widget.NewFormItem(i18n.T("Preset:"), nameSelect)},
+		func(confirmed bool) {
+			if !confirmed || nameSelect.Selected == "" {
+				return
+			}
+			if err := promptStore.DeletePreset(nameSelect.Selected); err != nil {
+				ShowError(err, v.window)
+				return
+			}
+			if v.activePreset == nameSelect.Selected {
+				v.setActivePreset(nil)
+			}
+		}, v.window)
+}
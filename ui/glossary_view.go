@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// GlossarySettingsView edits the per-site glossaries of product names,
+// trademarks and preferred spellings.
+type GlossarySettingsView struct {
+	container *fyne.Container
+	store     *editorial.GlossaryStore
+	wpService *wordpress.WordPressService
+	window    fyne.Window
+
+	// UI elements
+	siteSelect  *widget.Select
+	editor      *EditorEntry
+	statusLabel *widget.Label
+}
+
+// NewGlossarySettingsView creates a new glossary settings view
+func NewGlossarySettingsView(store *editorial.GlossaryStore, wpService *wordpress.WordPressService, window fyne.Window) *GlossarySettingsView {
+	view := &GlossarySettingsView{
+		store:     store,
+		wpService: wpService,
+		window:    window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the glossary settings view
+func (v *GlossarySettingsView) initialize() {
+	v.editor = NewEditorEntry()
+	v.editor.SetPlaceHolder(editorial.GlossaryExample)
+	v.editor.SetMinRowsVisible(6)
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+
+	v.siteSelect = widget.NewSelect(nil, func(string) { v.load() })
+	v.RefreshSites()
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Glossary"), theme.DocumentSaveIcon(), v.save)
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Glossary")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Terms are sent to the model and checked after every generation. Sites without their own glossary use the one for all sites.")),
+		widget.NewForm(widget.NewFormItem(i18n.T("Site:"), v.siteSelect)),
+		v.editor,
+		container.NewHBox(saveButton),
+		v.statusLabel,
+	)
+}
+
+// allSitesLabel is the site option for the default glossary.
+func allSitesLabel() string {
+	return i18n.T("All Sites")
+}
+
+// RefreshSites updates the site options after saved sites change, keeping
+// the current choice if it still exists.
+func (v *GlossarySettingsView) RefreshSites() {
+	options := []string{allSitesLabel()}
+	if v.wpService != nil {
+		for _, site := range v.wpService.GetSavedSites() {
+			options = append(options, site.Name)
+		}
+	}
+	selected := v.siteSelect.Selected
+	v.siteSelect.Options = options
+	for _, option := range options {
+		if option == selected {
+			v.siteSelect.Refresh()
+			return
+		}
+	}
+	v.siteSelect.SetSelected(allSitesLabel())
+}
+
+// site returns the store key for the selected site.
+func (v *GlossarySettingsView) site() string {
+	if v.siteSelect.Selected == allSitesLabel() {
+		return ""
+	}
+	return v.siteSelect.Selected
+}
+
+// load shows the selected site's glossary.
+func (v *GlossarySettingsView) load() {
+	v.editor.SetText(v.store.Text(v.site()))
+	v.editor.ClearHistory()
+	v.updateStatus()
+}
+
+// save registers the editor's text as the selected site's glossary.
+func (v *GlossarySettingsView) save() {
+	if err := v.store.Save(v.site(), v.editor.Text); err != nil {
+		ShowError(fmt.Errorf("glossary not saved: %w", err), v.window)
+		return
+	}
+	v.updateStatus()
+}
+
+// updateStatus summarizes the glossary that applies to the selected site.
+func (v *GlossarySettingsView) updateStatus() {
+	glossary := v.store.Glossary(v.site())
+	if len(glossary.Terms) == 0 {
+		v.statusLabel.SetText(i18n.T("No glossary terms."))
+		return
+	}
+	v.statusLabel.SetText(i18n.Tf("%d glossary terms apply to this site.", len(glossary.Terms)))
+}
+
+// Container returns the container for the glossary settings view
+func (v *GlossarySettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
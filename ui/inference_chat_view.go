@@ -2,14 +2,20 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"time"
 
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference" // Assuming your inference package path
+	"Inference_Engine/jobs"
+	"Inference_Engine/transcript"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -20,9 +26,30 @@ type InferenceChatView struct { // <-- Renamed struct
 	window           fyne.Window
 	
 
-	promptInput    *widget.Entry
-	responseOutput *widget.Entry
-	sendButton     *widget.Button // Renamed button
+	promptInput      *EditorEntry
+	responseOutput   *EditorEntry
+	responseRendered *widget.RichText // Markdown rendering of responseOutput
+	usageLabel       *widget.Label    // Tokens the last response used
+	promptCount      *widget.Label    // Live word/char/token counts for promptInput
+	sendButton       *widget.Button   // Renamed button
+
+	history    *PromptHistory // Recent prompts, recalled with Up/Down or the history button
+	jobQueue   *jobs.Queue // Runs chat requests in the background; nil runs them directly
+	generatorView *ContentGeneratorView // Receives responses sent to the generator; nil hides the action
+
+	conversation       transcript.Saved   // The conversation shown, saved after every exchange
+	conversations      []transcript.Saved // Every conversation, most recently updated first
+	store              *transcript.Store  // Keeps the conversations; nil keeps them for this session
+	lastID             int                // Last ID given to a conversation without a store
+	memorySynced       bool               // The model's conversation history matches the conversation shown
+	selected           int                // Turn shown in the response area; -1 for none
+	editing            int                // Turn being edited and resent; -1 for none
+	conversationSelect *widget.Select
+	turnList           *widget.List
+	regenerateButton   *widget.Button
+	editButton         *widget.Button
+	branchButton       *widget.Button
+	generatorButton    *widget.Button
 }
 
 // NewInferenceChatView creates a new InferenceChatView
@@ -30,6 +57,8 @@ func NewInferenceChatView(service *inference.InferenceService, win fyne.Window)
 	view := &InferenceChatView{ // <-- Use new struct name
 		inferenceService: service,
 		window:           win,
+		selected:         -1,
+		editing:          -1,
 	}
 	view.initialize()
 	return view
@@ -37,88 +66,205 @@ func NewInferenceChatView(service *inference.InferenceService, win fyne.Window)
 
 // initialize sets up the UI elements for the view
 func (v *InferenceChatView) initialize() {
-	v.promptInput = widget.NewMultiLineEntry()
-	v.promptInput.SetPlaceHolder("Enter your message...")
+	v.promptInput = NewEditorEntry()
+	v.promptInput.SetPlaceHolder(i18n.T("Enter your message..."))
 	v.promptInput.Wrapping = fyne.TextWrapWord
 	v.promptInput.SetMinRowsVisible(10)
+	v.history = NewPromptHistory(PrefHistoryChatPrompt)
+	v.promptInput.OnRecall = v.recallPrompt
+	v.promptCount = newCountLabel()
+	v.promptInput.OnChanged = func(text string) {
+		v.promptCount.SetText(textStatsSummary(text))
+	}
 
-	v.responseOutput = widget.NewMultiLineEntry()
-	v.responseOutput.SetPlaceHolder("Response will appear here...")
+	v.responseOutput = NewEditorEntry()
+	v.responseOutput.SetPlaceHolder(i18n.T("Response will appear here..."))
 	v.responseOutput.Wrapping = fyne.TextWrapWord
 	v.responseOutput.MultiLine = true
 	v.responseOutput.SetMinRowsVisible(10)
 	//v.responseOutput.Disable() // Make response read-only
 	//v.responseOutput.ReadOnly = true 
 
+	// Rendered view of the response; kept in sync with the raw entry
+	v.responseRendered = widget.NewRichTextFromMarkdown("")
+	v.responseRendered.Wrapping = fyne.TextWrapWord
+	v.responseOutput.OnChanged = func(text string) {
+		v.responseRendered.ParseMarkdown(text)
+	}
+
+	v.usageLabel = widget.NewLabel("")
+	v.usageLabel.Importance = widget.LowImportance
+
 	// --- Removed Radio Group ---
 
-	v.sendButton = widget.NewButton("Send Message", v.handleSendMessage) // Renamed button and handler
+	v.sendButton = widget.NewButton(i18n.T("Send Message"), v.handleSendMessage) // Renamed button and handler
+
+	v.generatorButton = widget.NewButtonWithIcon(i18n.T("Send to Generator"), theme.MailForwardIcon(), v.sendToGenerator)
+	v.generatorButton.Hide() // Shown once there is a generator to send to
 
-	promptArea := container.NewBorder(
-		widget.NewLabel("Your Message:"), // Top
-		v.sendButton,                    // Bottom (Only send button)
+	historyButton := newHistoryButton(v.window, v.history, func(prompt string) {
+		v.history.ResetRecall()
+		v.promptInput.ReplaceText(prompt)
+	})
+
+	promptArea := newReadingOrderBorder(
+		newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Your Message:")), historyButton), // Top
+		newReadingOrderBorder(nil, nil, v.promptCount, v.sendButton), // Bottom (counts + send)
 		nil,                             // Left
 		nil,                             // Right
 		container.NewScroll(v.promptInput), // Center - Scroll expands
 	)
 
-	responseArea := container.NewBorder(
-		widget.NewLabel("AI Response:"),     // Top
-		nil,                                 // Bottom
-		nil,                                 // Left
-		nil,                                 // Right
-		container.NewScroll(v.responseOutput), // Center - Scroll expands
+	responseTabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Rendered"), container.NewScroll(v.responseRendered)),
+		container.NewTabItem(i18n.T("Raw"), container.NewScroll(v.responseOutput)),
 	)
 
-	v.container = container.NewVSplit(
+	responseArea := newReadingOrderBorder(
+		newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("AI Response:")), // Top
+			container.NewHBox(v.generatorButton, newCopyButton(v.window, i18n.T("Copy"), func() string { return v.responseOutput.Text }))),
+		v.usageLabel,                    // Bottom
+		nil,                             // Left
+		nil,                             // Right
+		responseTabs,                    // Center - Tabs expand
+	)
+
+	chatArea := container.NewVSplit(
 		promptArea,
 		responseArea,
 	)
-	if split, ok := v.container.(*container.Split); ok {
-		split.SetOffset(0.4) // Adjust split ratio if needed
-	}
+	chatArea.SetOffset(0.4) // Adjust split ratio if needed
+	rememberSplit("chat.prompt", chatArea)
+
+	split := container.NewHSplit(v.newConversationPanel(), chatArea)
+	split.SetOffset(0.25)
+	rememberSplit("chat.conversations", split)
+	v.container = split
 }
 
 // handleSendMessage contains the logic executed when the send button is pressed
 func (v *InferenceChatView) handleSendMessage() { // <-- Renamed handler
 	prompt := v.promptInput.Text
 	if prompt == "" {
-		dialog.ShowInformation("Input Required", "Please enter a message", v.window)
+		dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Please enter a message"), v.window)
 		return
 	}
+	keep := len(v.conversation.Turns)
+	if v.editing >= 0 {
+		keep = v.editing // The edited message and everything after it are replaced
+	}
+	if v.send(prompt, keep) {
+		v.history.Add(prompt)
+	}
+}
 
+// send sends prompt as the next message after the first keep turns of the
+// conversation, which replaces the later turns once the response arrives. It
+// reports whether the message was sent.
+func (v *InferenceChatView) send(prompt string, keep int) bool {
 	if !v.inferenceService.IsRunning() {
-		dialog.ShowInformation("Service Error", "Inference service is not running. Check settings and logs.", v.window)
-		return
+		dialog.ShowInformation(i18n.T("Service Error"), i18n.T("Inference service is not running. Check settings and logs."), v.window)
+		return false
 	}
+	base := append([]transcript.Turn(nil), v.conversation.Turns[:keep]...)
+	resync := !v.memorySynced || keep < len(v.conversation.Turns)
 
 	// --- Simplified Logic: Always use proxy logic ---
-	progressMsg := "Sending message via Proxy Logic..."
-	log.Printf("UI: Initiating chat message via Proxy Logic")
+	progressMsg := i18n.T("Sending message via Proxy Logic...")
+	logger.Info("Chat: sending message")
 
 	// Show a loading indicator
-	progress := dialog.NewProgressInfinite("Generating", progressMsg, v.window)
+	progress := dialog.NewProgressInfinite(i18n.T("Generating"), progressMsg, v.window)
 	progress.Show()
-	v.responseOutput.SetText("Generating...") // Indicate activity
+	v.responseOutput.SetText(i18n.T("Generating...")) // Indicate activity
 
-	// Run in a goroutine to avoid blocking the UI
-	go func() {
-		defer progress.Hide()
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		defer runOnUI(progress.Hide)
 
-		// Call GenerateText with empty modelName and instructionText
-		// The DelegatorService will use its default primary model.
-		response, err := v.inferenceService.GenerateText("", prompt, "")
+		// Give the model the conversation up to this message
+		if resync {
+			if err := v.inferenceService.RestoreConversationHistory(conversationMessages(base)); err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("generation failed: %w", err), v.window) })
+				return err
+			}
+		}
+
+		// No model or instruction: the DelegatorService uses its default
+		// primary model. The job's context cancels the request.
+		response, usage, err := v.inferenceService.GenerateWithUsage(prompt, inference.GenerateOptions{Context: ctx, Task: inference.TaskChat})
+		if ctx.Err() != nil {
+			logger.Info("Chat: job was canceled, discarding response")
+			return ctx.Err()
+		}
 
 		if err != nil {
-			log.Printf("UI Error: Chat generation failed: %v", err)
-			dialog.ShowError(fmt.Errorf("Generation failed:\n%w", err), v.window)
-			v.responseOutput.SetText(fmt.Sprintf("ERROR:\n%v", err)) // Show error in output
-			return
+			logger.Error("Chat: generation failed", "error", err)
+			runOnUI(func() {
+				ShowError(fmt.Errorf("generation failed: %w", err), v.window)
+				v.responseOutput.SetText(i18n.Tf("ERROR:\n%v", err)) // Show error in output
+				v.usageLabel.SetText("")
+				v.memorySynced = false // The model kept the failed message
+				if keep < len(v.conversation.Turns) {
+					return // Keep the turns a regenerated or edited message would have replaced
+				}
+				v.addTurn(base, transcript.Turn{Time: time.Now(), Prompt: prompt, Error: err.Error()})
+			})
+			return err
 		}
 
-		v.responseOutput.SetText(response)
-		log.Printf("UI: Chat generation successful.")
-	}()
+		runOnUI(func() {
+			v.memorySynced = true
+			v.addTurn(base, transcript.Turn{Time: time.Now(), Prompt: prompt, Response: response,
+				PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens})
+		})
+		logger.Info("Chat: generation successful")
+		return nil
+	}
+
+	// Run in the background to avoid blocking the UI
+	if v.jobQueue == nil {
+		crash.Go("InferenceChatView.send", func() { run(context.Background(), func(float64, string) {}) })
+		return true
+	}
+	v.jobQueue.Submit("Chat", truncateUTF8(prompt, 60), run)
+	return true
+}
+
+// recallPrompt replaces the prompt with an older or newer history entry
+func (v *InferenceChatView) recallPrompt(older bool) bool {
+	var text string
+	var ok bool
+	if older {
+		text, ok = v.history.Older(v.promptInput.Text)
+	} else {
+		text, ok = v.history.Newer()
+	}
+	if !ok {
+		return false
+	}
+	v.promptInput.SetText(text)
+	v.promptInput.CursorRow = 0
+	v.promptInput.CursorColumn = 0
+	v.promptInput.Refresh()
+	return true
+}
+
+// SetJobQueue sets the queue that chat messages are submitted to
+func (v *InferenceChatView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetContentGeneratorView sets the view that responses and conversations are
+// sent to
+func (v *InferenceChatView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
+	v.generatorView = generatorView
+	v.generatorButton.Show()
+	v.updateActions()
+}
+
+// FocusRegions returns the message input and response for Ctrl+F6 focus cycling
+func (v *InferenceChatView) FocusRegions() []fyne.Focusable {
+	return []fyne.Focusable{v.promptInput, v.responseOutput}
 }
 
 // Container returns the main container for this view
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/interview"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// interviewFromSource turns the ticked source, an interview transcript, into
+// a narrative article, an article with a Q&A section, or a formatted Q&A,
+// shown as the result.
+func (v *ContentGeneratorView) interviewFromSource() {
+	var source *SourceContent
+	for i := range v.sourceContents {
+		if v.sourceContents[i].Selected {
+			if source != nil {
+				ShowError(fmt.Errorf("tick only the interview transcript"), v.window)
+				return
+			}
+			source = &v.sourceContents[i]
+		}
+	}
+	if source == nil {
+		ShowError(fmt.Errorf("tick the interview transcript in the source list"), v.window)
+		return
+	}
+	text := source.Content
+	if utils.LooksLikeHTML(text) {
+		text = utils.HTMLToMarkdown(text)
+	}
+	transcript, err := interview.Parse(text)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	title := source.Title
+	speakers := strings.Join(transcript.Speakers(), ", ")
+
+	formats := []string{i18n.T("Narrative article"), i18n.T("Article with a Q&A section"), i18n.T("Formatted Q&A")}
+	format := widget.NewRadioGroup(formats, nil)
+	format.Required = true
+	format.SetSelected(formats[0])
+	summary := widget.NewLabel(i18n.Tf("%d turns by %s.", len(transcript.Turns), speakers))
+	summary.Wrapping = fyne.TextWrapWord
+
+	form := dialog.NewForm(i18n.T("Interview to Article"), i18n.T("Convert"), i18n.T("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Transcript:"), summary),
+		widget.NewFormItem(i18n.T("Format:"), format),
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		request := fmt.Sprintf("Article from the interview '%s'", title)
+		if format.Selected == formats[2] {
+			v.OpenResult(fmt.Sprintf("Q&A from the interview '%s'", title), transcript.Markdown())
+			return
+		}
+		withQA := format.Selected == formats[1]
+		model := v.chosenModel()
+
+		run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+			output, err := v.inferenceService.Generate(inference.GetInterviewArticlePrompt(speakers, transcript.Text(), withQA), contentOptions(ctx, model))
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to write the article: %w", err), v.window) })
+				return err
+			}
+			article := strings.TrimSpace(output)
+			missing := transcript.MissingSpeakers(article)
+			runOnUI(func() {
+				v.OpenResult(request, article)
+				if len(missing) > 0 {
+					dialog.ShowInformation(i18n.T("Check Attribution"), i18n.Tf("The article never names %s. Check that their words are attributed to them.", strings.Join(missing, ", ")), v.window)
+				}
+			})
+			return nil
+		}
+		if v.jobQueue == nil {
+			crash.Go("ContentGeneratorView.interviewFromSource", func() { run(context.Background(), func(float64, string) {}) })
+			return
+		}
+		v.jobQueue.Submit("Interview", title, run)
+	}, v.window)
+	form.Resize(fyne.NewSize(480, form.MinSize().Height))
+	form.Show()
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2/lang"
+)
+
+// PrefLanguage is the setting for the UI language code (e.g. "es"), empty
+// until the user picks one.
+var PrefLanguage = settings.NewKey("ui.language", "")
+
+// ApplySavedLanguage activates the saved UI language, or the system language
+// if it is supported and none was saved. It must run before the views are
+// built, since their strings are translated when they are created.
+func ApplySavedLanguage() {
+	code := SavedLanguage()
+	if err := i18n.SetLanguage(code); err != nil {
+		logger.Warn("Falling back to default language", "language", i18n.DefaultLanguage, "error", err)
+		i18n.SetLanguage(i18n.DefaultLanguage)
+	}
+}
+
+// SavedLanguage returns the persisted language code, defaulting to the
+// system language when it is one of i18n.Languages.
+func SavedLanguage() string {
+	if code := PrefLanguage.Get(); code != "" {
+		return code
+	}
+	system := strings.ToLower(strings.SplitN(lang.SystemLocale().LanguageString(), "-", 2)[0])
+	for _, l := range i18n.Languages {
+		if l.Code == system {
+			return system
+		}
+	}
+	return i18n.DefaultLanguage
+}
+
+// SaveLanguage persists the UI language; it takes effect on the next start.
+func SaveLanguage(code string) {
+	PrefLanguage.Set(code)
+}
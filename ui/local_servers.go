@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+)
+
+// formatBytes shows a size in GB, or MB below one GB.
+func formatBytes(n int64) string {
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", n>>20)
+}
+
+// describeLocalServers lists the models of each local server, with their
+// sizes and what is loaded, then this machine's memory. A model larger than
+// the available memory is flagged, as it may not fit.
+func describeLocalServers(servers []inference.LocalServer, total, available int64, now time.Time) string {
+	if len(servers) == 0 {
+		return i18n.T("No provider's base URL points at this machine.")
+	}
+	var lines []string
+	for _, server := range servers {
+		lines = append(lines, fmt.Sprintf("%s (%s):", server.Provider, server.BaseURL))
+		if server.Error != "" {
+			lines = append(lines, "    "+i18n.Tf("unreachable: %s", server.Error))
+			continue
+		}
+		if len(server.Models) == 0 {
+			lines = append(lines, "    "+i18n.T("no models"))
+		}
+		for _, model := range server.Models {
+			var details []string
+			if model.Size > 0 {
+				details = append(details, formatBytes(model.Size))
+			}
+			switch {
+			case model.Loaded:
+				loaded := i18n.Tf("loaded, %s of memory", formatBytes(model.Memory))
+				if model.Memory > 0 {
+					loaded += " " + i18n.Tf("(%d%% on the GPU, %s VRAM)", model.VRAM*100/model.Memory, formatBytes(model.VRAM))
+				}
+				if !model.ExpiresAt.IsZero() && model.ExpiresAt.After(now) {
+					loaded += ", " + i18n.Tf("unloads in %s", model.ExpiresAt.Sub(now).Round(time.Minute))
+				}
+				details = append(details, loaded)
+			case server.Ollama:
+				details = append(details, i18n.T("not loaded"))
+				if available > 0 && model.Size > available {
+					details = append(details, i18n.T("may not fit in the available memory"))
+				}
+			}
+			line := "    " + model.Name
+			if len(details) > 0 {
+				line += "  " + strings.Join(details, ", ")
+			}
+			lines = append(lines, line)
+		}
+	}
+	if total > 0 {
+		lines = append(lines, i18n.Tf("This machine: %s of memory available, of %s", formatBytes(available), formatBytes(total)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// refreshLocalServers reads the local servers in the background and shows
+// what they report.
+func (v *InferenceSettingsView) refreshLocalServers() {
+	v.localServersLabel.SetText(i18n.T("Checking local servers..."))
+	crash.Go("InferenceSettingsView.refreshLocalServers", func() {
+		servers := v.inferenceService.LocalServers(context.Background())
+		total, available, _ := inference.HostMemory()
+		text := describeLocalServers(servers, total, available, time.Now())
+		runOnUI(func() { v.localServersLabel.SetText(text) })
+	})
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"Inference_Engine/logging"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+var logger = logging.For("ui")
+
+const (
+	maxConsoleChars   = 10000  // The console widget only shows the tail
+	maxSessionRecords = 100000 // Records kept for filtering and export
+)
+
+// LogFilter selects which records the log console shows and exports.
+type LogFilter struct {
+	MinLevel  slog.Level
+	Component string // Empty matches every component
+	Text      string // Case-insensitive match on the formatted record
+}
+
+// Matches reports whether a record passes the filter.
+func (f LogFilter) Matches(r logging.Record) bool {
+	if r.Level < f.MinLevel {
+		return false
+	}
+	if f.Component != "" && r.Component != f.Component {
+		return false
+	}
+	return f.Text == "" || strings.Contains(strings.ToLower(r.String()), strings.ToLower(f.Text))
+}
+
+// LogConsole collects the session's log records and shows the ones matching
+// its filter in a text widget.
+type LogConsole struct {
+	output *widget.Entry
+	cancel func()
+
+	mu           sync.Mutex
+	records      []logging.Record
+	components   map[string]bool
+	filter       LogFilter
+	flushPending bool
+
+	// OnComponentsChanged is called on the UI goroutine when a record from a
+	// new component arrives, so filter choices can be updated.
+	OnComponentsChanged func(components []string)
+}
+
+// NewLogConsole starts collecting log records into output.
+func NewLogConsole(output *widget.Entry) *LogConsole {
+	c := &LogConsole{output: output, components: map[string]bool{}, filter: LogFilter{MinLevel: slog.LevelDebug}}
+	c.cancel = logging.Subscribe(c.add)
+	return c
+}
+
+// Close stops collecting records.
+func (c *LogConsole) Close() {
+	c.cancel()
+}
+
+// add stores a record. It runs on whichever goroutine logged, including the
+// main loop itself, so the widget update is scheduled from a goroutine of its
+// own rather than run while c.mu is held.
+func (c *LogConsole) add(r logging.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, r)
+	if len(c.records) > maxSessionRecords {
+		c.records = c.records[len(c.records)-maxSessionRecords:]
+	}
+	newComponent := r.Component != "" && !c.components[r.Component]
+	if newComponent {
+		c.components[r.Component] = true
+	}
+	if c.filter.Matches(r) || newComponent {
+		c.scheduleFlush()
+	}
+}
+
+// scheduleFlush queues one widget refresh; the caller holds c.mu.
+func (c *LogConsole) scheduleFlush() {
+	if !c.flushPending {
+		c.flushPending = true
+		go runOnUI(c.flush)
+	}
+}
+
+// flush shows the tail of the matching records in the widget.
+func (c *LogConsole) flush() {
+	c.mu.Lock()
+	c.flushPending = false
+	var lines []string
+	size := 0
+	for i := len(c.records) - 1; i >= 0 && size < maxConsoleChars; i-- {
+		if r := c.records[i]; c.filter.Matches(r) {
+			line := r.String()
+			lines = append(lines, line)
+			size += len(line) + 1
+		}
+	}
+	components := c.componentsLocked()
+	c.mu.Unlock()
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	text := strings.Join(lines, "\n")
+	if len(text) > maxConsoleChars {
+		text = text[len(text)-maxConsoleChars:]
+	}
+	c.output.SetText(text)
+	if c.OnComponentsChanged != nil {
+		c.OnComponentsChanged(components)
+	}
+}
+
+// SetFilter changes which records are shown. Call it from the UI goroutine.
+func (c *LogConsole) SetFilter(f LogFilter) {
+	c.mu.Lock()
+	c.filter = f
+	c.mu.Unlock()
+	c.flush()
+}
+
+// Components returns the components seen so far, sorted.
+func (c *LogConsole) Components() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.componentsLocked()
+}
+
+func (c *LogConsole) componentsLocked() []string {
+	names := make([]string, 0, len(c.components))
+	for name := range c.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Records returns the session's records that match the current filter.
+func (c *LogConsole) Records() []logging.Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var matching []logging.Record
+	for _, r := range c.records {
+		if c.filter.Matches(r) {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+// recordsText formats records as log lines for a text export.
+func recordsText(records []logging.Record) string {
+	var b strings.Builder
+	for _, r := range records {
+		b.WriteString(r.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// recordsJSONLines formats records as one JSON object per line.
+func recordsJSONLines(records []logging.Record) (string, error) {
+	var b strings.Builder
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return "", err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"Inference_Engine/audit"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// mediaBatchSize is how many attachments are requested per REST call.
+const mediaBatchSize = 100
+
+// maxBackfillFailures stops a backfill that keeps failing, e.g. on an
+// invalid API key or exhausted quota.
+const maxBackfillFailures = 10
+
+// SetWordPressService enables the site maintenance jobs, which work on the
+// connected site.
+func (v *ActivityView) SetWordPressService(wpService *wordpress.WordPressService) {
+	v.wpService = wpService
+	v.backfillButton.Show()
+}
+
+// confirmMediaBackfill explains the alt text backfill and starts it.
+func (v *ActivityView) confirmMediaBackfill() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("connect to a WordPress site first"), v.window)
+		return
+	}
+	if err := v.wpService.CanDo(wordpress.ActionUpload); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	if os.Getenv("GEMINI_API_KEY") == "" {
+		ShowError(fmt.Errorf("describing images needs a Gemini API key; set it in Settings"), v.window)
+		return
+	}
+	captions := widget.NewCheck(i18n.T("Also write captions for images without one"), nil)
+	note := widget.NewLabel(i18n.T("Each image of the media library without alt text is sent to Gemini, which describes it; the alt text is then saved to WordPress. Existing alt text and captions are never changed."))
+	note.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustomConfirm(i18n.T("Alt Text Backfill"), i18n.T("Start"), i18n.T("Cancel"), container.NewVBox(note, captions), func(confirmed bool) {
+		if confirmed {
+			v.runMediaBackfill(captions.Checked)
+		}
+	}, v.window)
+	d.Resize(fyne.NewSize(520, d.MinSize().Height))
+	d.Show()
+}
+
+// runMediaBackfill walks the media library in the background and fills in
+// the missing alt text, and captions if captions is set.
+func (v *ActivityView) runMediaBackfill(captions bool) {
+	wpService := v.wpService
+	vision := inference.NewGeminiVision(os.Getenv("GEMINI_API_KEY"))
+	site := wpService.GetCurrentSiteName()
+
+	v.queue.Submit("Alt Text Backfill", site, func(ctx context.Context, report jobs.ProgressFunc) error {
+		filled, failed := 0, 0
+		for batch, total := 1, 1; batch <= total; batch++ {
+			report(float64(batch-1)/float64(total), i18n.Tf("Checking media batch %d of %d", batch, total))
+			items, batches, err := wpService.GetMediaBatch(batch, mediaBatchSize)
+			if err != nil {
+				return err
+			}
+			total = batches
+			for _, item := range audit.MissingMediaText(items, captions) {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				report(float64(batch-1)/float64(max(total, 1)), i18n.Tf("Describing %s (%d filled)", item.Title, filled))
+				if err := backfillMediaItem(ctx, wpService, vision, item, captions); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					logger.Warn("Alt text backfill: skipping image", "media_id", item.ID, "error", err)
+					if failed++; failed >= maxBackfillFailures {
+						return fmt.Errorf("stopped after %d failures, the last: %w", failed, err)
+					}
+					continue
+				}
+				filled++
+			}
+		}
+		logger.Info("Alt text backfill finished", "site", site, "filled", filled, "failed", failed)
+		report(1, i18n.Tf("Filled %d images, %d skipped after errors", filled, failed))
+		return nil
+	})
+}
+
+// backfillMediaItem describes one image and saves the alt text and caption
+// it is missing.
+func backfillMediaItem(ctx context.Context, wpService *wordpress.WordPressService, vision *inference.GeminiVision, item wordpress.MediaItem, captions bool) error {
+	data, err := wpService.DownloadMedia(item)
+	if err != nil {
+		return err
+	}
+	mimeType := item.MimeType
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	output, err := vision.DescribeImage(ctx, data, mimeType, inference.GetMediaTextPrompt(item.Title))
+	if err != nil {
+		return err
+	}
+	text, err := audit.ParseMediaText(output)
+	if err != nil {
+		return err
+	}
+	altText, caption := text.AltText, ""
+	if item.AltText != "" {
+		altText = ""
+	}
+	if captions && item.Caption == "" {
+		caption = text.Caption
+	}
+	return wpService.UpdateMediaText(item.ID, altText, caption)
+}
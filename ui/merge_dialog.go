@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// mergePages has the model merge pages on the same topic into one article in
+// the background, then shows the draft. Only the pages' IDs, titles, links
+// and modification dates are used; their content is fetched in full.
+func mergePages(window fyne.Window, wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, generatorView *ContentGeneratorView, pages []wordpress.Page) {
+	titles := make([]string, len(pages))
+	for i, page := range pages {
+		titles[i] = page.Title
+	}
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		var b strings.Builder
+		for i, page := range pages {
+			progress(-1, i18n.Tf("Fetching %s", page.Title))
+			content, err := wpService.GetPageContent(page.ID)
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to load content for '%s': %w", page.Title, err), window) })
+				return err
+			}
+			pages[i].Content = content
+			fmt.Fprintf(&b, "### Page %d: %s (modified %s)\n\n%s\n\n", i+1, page.Title, page.Modified.Format("2006-01-02"), content)
+		}
+		progress(-1, i18n.T("Merging"))
+		draft, err := inferenceService.Generate(inference.GetMergePagesPrompt(b.String()), contentOptions(ctx, ""))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to merge pages: %w", err), window) })
+			return err
+		}
+		runOnUI(func() { showMergeDraft(window, generatorView, pages, draft) })
+		return nil
+	}
+	if jobQueue == nil {
+		crash.Go("mergePages", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	jobQueue.Submit("Merge Draft", strings.Join(titles, " + "), run)
+}
+
+// showMergeDraft shows a merged article for editing. It can be copied,
+// exported, or opened in the generator with the merged pages as its True
+// sources, to check it and save it over one of them. The Redirects tab lists
+// the redirects from the other pages' URLs to the page whose URL is kept.
+func showMergeDraft(window fyne.Window, generatorView *ContentGeneratorView, pages []wordpress.Page, draft string) {
+	editor := NewEditorEntry()
+	editor.SetText(strings.TrimSpace(draft))
+	editor.SetMinRowsVisible(18)
+
+	titles := make([]string, len(pages))
+	for i, page := range pages {
+		titles[i] = page.Title
+	}
+	header := widget.NewLabel(i18n.Tf("Merged from: %s", strings.Join(titles, ", ")))
+	header.Wrapping = fyne.TextWrapWord
+
+	redirects := widget.NewMultiLineEntry()
+	redirects.Wrapping = fyne.TextWrapOff
+	canonical := widget.NewSelect(titles, func(title string) {
+		for _, page := range pages {
+			if page.Title == title {
+				redirects.SetText(wordpress.RedirectNotes(wordpress.MergeRedirects(pages, page)))
+				return
+			}
+		}
+	})
+	canonical.SetSelectedIndex(0)
+	redirectsTab := newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Publish the article on the kept page, move the others to the trash, then set up these redirects.")),
+		container.NewHBox(newCopyButton(window, i18n.T("Copy Redirects"), func() string { return redirects.Text })),
+		nil, nil, redirects)
+	tabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Article"), editor),
+		container.NewTabItem(i18n.T("Redirects"), redirectsTab),
+	)
+	top := container.NewVBox(header, newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Keep URL of:")), nil, canonical))
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Merge Draft"), newReadingOrderBorder(top, nil, nil, nil, tabs), window)
+	buttons := []fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		newCopyButton(window, i18n.T("Copy"), func() string { return editor.Text }),
+		widget.NewButton(i18n.T("Export"), func() {
+			exportTextToFile(window, i18n.T("Merge Draft"), "merged", "html", editor.Text)
+		}),
+	}
+	if generatorView != nil {
+		buttons = append(buttons, widget.NewButton(i18n.T("Open in Generator"), func() {
+			for _, page := range pages {
+				generatorView.AddSourceContent(page.Title, page.Content, "WordPress", page.Link, page.ID, false)
+			}
+			generatorView.OpenResult(fmt.Sprintf("Merge these pages into one article: %s", strings.Join(titles, ", ")), editor.Text)
+			d.Hide()
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.T("The merged pages and the draft are in the content generator."), window)
+		}))
+	}
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(760, 620))
+	d.Show()
+}
+
+// mergeWithOtherPages asks which loaded pages to merge with the selected
+// page, then drafts the merged article.
+func (v *ContentManagerView) mergeWithOtherPages() {
+	selected := v.GetPageByID(v.selectedPageID)
+	if selected == nil {
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+	labels := make([]string, len(v.visiblePages))
+	byLabel := map[string]wordpress.Page{}
+	for i, page := range v.visiblePages {
+		labels[i] = fmt.Sprintf("%s (#%d)", page.Title, page.ID)
+		byLabel[labels[i]] = page
+	}
+	choices := widget.NewCheckGroup(labels, nil)
+	choices.SetSelected([]string{fmt.Sprintf("%s (#%d)", selected.Title, selected.ID)})
+
+	d := dialog.NewCustomConfirm(i18n.T("Merge Pages"), i18n.T("Merge"), i18n.T("Cancel"), newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Pages to merge into one article (filter the page list to narrow these down):")),
+		nil, nil, nil, container.NewVScroll(choices),
+	), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		var pages []wordpress.Page
+		for _, label := range choices.Selected {
+			page := byLabel[label]
+			pages = append(pages, wordpress.Page{ID: page.ID, Title: page.Title, Link: page.Link, Modified: page.Modified})
+		}
+		if len(pages) < 2 {
+			ShowError(fmt.Errorf("tick at least two pages to merge"), v.window)
+			return
+		}
+		mergePages(v.window, v.wpService, v.inferenceService, v.jobQueue, v.contentGeneratorView, pages)
+	}, v.window)
+	d.Resize(fyne.NewSize(520, 480))
+	d.Show()
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/repurpose"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// convertToNewsletter asks for the call to action, then rewrites the post as
+// a newsletter in the background and shows the result. link pre-fills the
+// button's URL (the published post, if known); model is a model selector
+// choice, or "" for the default.
+func convertToNewsletter(window fyne.Window, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, post, link, model string) {
+	ctaText := widget.NewEntry()
+	ctaText.SetText(repurpose.DefaultCTAText)
+	ctaURL := widget.NewEntry()
+	ctaURL.SetPlaceHolder("https://")
+	ctaURL.SetText(link)
+
+	dialog.ShowForm(i18n.T("Newsletter"), i18n.T("Convert"), i18n.T("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Button Text:"), ctaText),
+		widget.NewFormItem(i18n.T("Button Link:"), ctaURL),
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		cta := repurpose.CTA{Text: ctaText.Text, URL: ctaURL.Text}
+		run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+			if utils.LooksLikeHTML(post) {
+				post = utils.HTMLToMarkdown(post)
+			}
+			output, err := inferenceService.Generate(inference.GetNewsletterPrompt(post), generateOptions(ctx, model, inference.TaskStructured))
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var newsletter repurpose.Newsletter
+			if err == nil {
+				newsletter, err = repurpose.ParseNewsletter(output, cta)
+			}
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to convert to a newsletter: %w", err), window) })
+				return err
+			}
+			runOnUI(func() { showNewsletter(window, newsletter) })
+			return nil
+		}
+		if jobQueue == nil {
+			crash.Go("convertToNewsletter", func() { run(context.Background(), func(float64, string) {}) })
+			return
+		}
+		jobQueue.Submit("Newsletter", truncateUTF8(post, 60), run)
+	}, window)
+}
+
+// showNewsletter previews a newsletter, with its subject, preheader and body
+// editable, and exports it as an HTML email or plain text.
+func showNewsletter(window fyne.Window, newsletter repurpose.Newsletter) {
+	subject := widget.NewEntry()
+	subject.SetText(newsletter.Subject)
+	preheader := widget.NewEntry()
+	preheader.SetText(newsletter.Preheader)
+	body := widget.NewMultiLineEntry()
+	body.Wrapping = fyne.TextWrapWord
+	body.SetText(newsletter.Body)
+	preview := widget.NewRichTextFromMarkdown("")
+	preview.Wrapping = fyne.TextWrapWord
+	body.OnChanged = func(text string) { preview.ParseMarkdown(utils.HTMLToMarkdown(text)) }
+	body.OnChanged(body.Text)
+
+	current := func() repurpose.Newsletter {
+		edited := newsletter
+		edited.Subject, edited.Preheader, edited.Body = subject.Text, preheader.Text, body.Text
+		return edited
+	}
+
+	content := newReadingOrderBorder(
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Subject:"), subject),
+			widget.NewFormItem(i18n.T("Preheader:"), preheader),
+		),
+		nil, nil, nil,
+		container.NewAppTabs(
+			container.NewTabItem(i18n.T("HTML"), container.NewScroll(body)),
+			container.NewTabItem(i18n.T("Preview"), container.NewScroll(preview)),
+		),
+	)
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Newsletter"), content, window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		newCopyButton(window, i18n.T("Copy HTML"), func() string { return current().HTML() }),
+		widget.NewButton(i18n.T("Export HTML"), func() {
+			exportTextToFile(window, i18n.T("Newsletter"), "newsletter", "html", current().HTML())
+		}),
+		widget.NewButton(i18n.T("Export Text"), func() {
+			exportTextToFile(window, i18n.T("Newsletter"), "newsletter", "txt", current().Text())
+		}),
+	})
+	d.Resize(fyne.NewSize(680, 560))
+	d.Show()
+}
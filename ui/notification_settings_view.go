@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/notify"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// NotificationSettingsView edits the Slack, Discord or other webhooks that
+// are told when jobs finish, pages are published or the token budget is
+// exceeded.
+type NotificationSettingsView struct {
+	container *fyne.Container
+	notifier  *notify.Notifier
+	window    fyne.Window
+
+	// UI elements
+	editor      *EditorEntry
+	testButton  *widget.Button
+	statusLabel *widget.Label
+}
+
+// NewNotificationSettingsView creates a new notification settings view
+func NewNotificationSettingsView(notifier *notify.Notifier, window fyne.Window) *NotificationSettingsView {
+	view := &NotificationSettingsView{
+		notifier: notifier,
+		window:   window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the notification settings view
+func (v *NotificationSettingsView) initialize() {
+	v.editor = NewEditorEntry()
+	v.editor.SetPlaceHolder(notify.Example)
+	v.editor.SetMinRowsVisible(5)
+	v.editor.SetText(v.notifier.Text())
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+	v.updateStatus()
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Webhooks"), theme.DocumentSaveIcon(), v.save)
+	v.testButton = widget.NewButton(i18n.T("Send Test"), v.sendTest)
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Notifications")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("One Slack, Discord or other webhook URL per line, optionally followed by the events it receives: job_finished, publish_succeeded, publish_failed, budget_exceeded.")),
+		v.editor,
+		container.NewHBox(saveButton, v.testButton),
+		v.statusLabel,
+	)
+}
+
+// save parses and registers the editor's webhooks.
+func (v *NotificationSettingsView) save() {
+	if err := v.notifier.Save(v.editor.Text); err != nil {
+		ShowError(fmt.Errorf("webhooks not saved: %w", err), v.window)
+		return
+	}
+	v.updateStatus()
+}
+
+// sendTest posts a test notification to every saved webhook.
+func (v *NotificationSettingsView) sendTest() {
+	v.testButton.Disable()
+	go func() {
+		defer crash.Recover("NotificationSettingsView.sendTest")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err := v.notifier.Test(ctx)
+		runOnUI(func() {
+			v.testButton.Enable()
+			if err != nil {
+				ShowError(fmt.Errorf("test notification failed: %w", err), v.window)
+				return
+			}
+			dialog.ShowInformation(i18n.T("Notifications"), i18n.T("A test notification was sent to every webhook."), v.window)
+		})
+	}()
+}
+
+// updateStatus summarizes the saved webhooks.
+func (v *NotificationSettingsView) updateStatus() {
+	webhooks := v.notifier.Webhooks()
+	if len(webhooks) == 0 {
+		v.statusLabel.SetText(i18n.T("No webhooks; notifications are off."))
+		v.testButton.Disable()
+		return
+	}
+	v.statusLabel.SetText(i18n.Tf("%d webhooks will be notified.", len(webhooks)))
+	v.testButton.Enable()
+}
+
+// Container returns the container for the view
+func (v *NotificationSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
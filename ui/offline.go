@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"errors"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showQueuedOffline tells the user a page update was queued, instead of
+// saved, because the app is offline.
+func showQueuedOffline(window fyne.Window) {
+	dialog.ShowInformation(i18n.T("Queued"), i18n.T("You are offline. The update is queued and will be saved when the connection returns."), window)
+}
+
+// AskQueuedConflict reports whether err, from ReplayQueued, is a page
+// changed on the site since it was edited offline. If so it asks whether to
+// overwrite that change or discard the update, then calls resume to save the
+// rest of the queue; Later leaves the update queued.
+func AskQueuedConflict(err error, wpService *wordpress.WordPressService, window fyne.Window, resume func()) bool {
+	var conflict *wordpress.QueuedConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	runOnUI(func() {
+		message := widget.NewLabel(i18n.Tf("Page %d was changed on the site at %s, after you edited it offline. Saving your update would overwrite that change.",
+			conflict.Update.PageID, conflict.Modified.Local().Format("2006-01-02 15:04")))
+		message.Wrapping = fyne.TextWrapWord
+
+		var d *dialog.CustomDialog
+		resolve := func(overwrite bool) func() {
+			return func() {
+				d.Hide()
+				if err := wpService.ResolveQueued(conflict.Update, overwrite); err != nil {
+					ShowError(err, window)
+					return
+				}
+				resume()
+			}
+		}
+		d = dialog.NewCustomWithoutButtons(i18n.T("Page Changed on the Site"), message, window)
+		d.SetButtons([]fyne.CanvasObject{
+			widget.NewButton(i18n.T("Later"), func() { d.Hide() }),
+			widget.NewButton(i18n.T("Discard My Update"), resolve(false)),
+			widget.NewButton(i18n.T("Overwrite"), resolve(true)),
+		})
+		d.Resize(fyne.NewSize(480, 200))
+		d.Show()
+	})
+	return true
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// pageAction is a one-click rewrite of the selected page.
+type pageAction struct {
+	Name   string // Shown on the button and as the job name
+	Prompt func(content string) string
+}
+
+// pageActions are the rewrites offered in the content manager.
+var pageActions = []pageAction{
+	{"Improve", inference.GetWordPressContentImprovePrompt},
+	{"Rewrite", inference.GetWordPressContentRewritePrompt},
+	{"Expand", inference.GetWordPressContentExpandPrompt},
+}
+
+// runPageAction rewrites the selected page with action in the background and
+// shows the changes for review. Pages too large for the editor are fetched in
+// full.
+func (v *ContentManagerView) runPageAction(action pageAction) {
+	page := v.GetPageByID(v.selectedPageID)
+	if page == nil {
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+	pageID, title := page.ID, page.Title
+	content := ""
+	if !v.contentTruncated {
+		content = v.contentEditor.Text
+	}
+
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		if content == "" {
+			report(0, i18n.T("Loading page content"))
+			full, err := v.wpService.GetPageContent(pageID)
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to load page content: %w", err), v.window) })
+				return err
+			}
+			content = full
+		}
+		report(0.1, i18n.T("Generating"))
+		output, err := v.inferenceService.Generate(action.Prompt(content), contentOptions(ctx, ""))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Error("ContentManagerView: page action failed", "action", action.Name, "page_id", pageID, "error", err)
+			runOnUI(func() { ShowError(fmt.Errorf("%s failed: %w", strings.ToLower(action.Name), err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showPageRewrite(action, pageID, title, content, strings.TrimSpace(output)) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentManagerView.runPageAction", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit(action.Name, title, run)
+}
+
+// showPageRewrite shows how rewritten differs from the page's content, with
+// the rewrite editable, and applies it to the editor or saves it on request.
+func (v *ContentManagerView) showPageRewrite(action pageAction, pageID int, title, content, rewritten string) {
+	editor := widget.NewMultiLineEntry()
+	editor.Wrapping = fyne.TextWrapWord
+	editor.SetText(rewritten)
+	changes := widget.NewRichText()
+	changes.Wrapping = fyne.TextWrapWord
+	showChanges := func() { changes.Segments = diffSegments(content, editor.Text); changes.Refresh() }
+	showChanges()
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Changes"), container.NewScroll(changes)),
+		container.NewTabItem(i18n.T("Result"), container.NewScroll(editor)),
+	)
+	tabs.OnSelected = func(tab *container.TabItem) {
+		if tab == tabs.Items[0] {
+			showChanges()
+		}
+	}
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.Tf("%s: %s", i18n.T(action.Name), title), tabs, v.window)
+	applyButton := widget.NewButton(i18n.T("Apply to Editor"), func() {
+		d.Hide()
+		v.contentEditor.ReplaceText(editor.Text) // Undo brings the page's content back
+		v.aiEdited = true
+	})
+	if v.selectedPageID != pageID || v.contentTruncated {
+		applyButton.Disable() // The editor shows another page, or only a preview
+	}
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Discard"), func() { d.Hide() }),
+		applyButton,
+		widget.NewButtonWithIcon(i18n.T("Save to WordPress"), theme.DocumentSaveIcon(), func() {
+			d.Hide()
+			text := editor.Text
+			if v.selectedPageID == pageID && !v.contentTruncated {
+				v.contentEditor.ReplaceText(text)
+				v.aiEdited = true
+			}
+			saveIfApproved(v.drafts, v.window, text, fmt.Sprintf("%s: %s", action.Name, title), func() { v.savePage(pageID, text) })
+		}),
+	})
+	d.Resize(fyne.NewSize(760, 620))
+	d.Show()
+}
+
+// diffSegments shows the line changes from before to after, compared as
+// Markdown so HTML pages read as text: removed lines in red, added lines in
+// green and the lines around them as they are.
+func diffSegments(before, after string) []widget.RichTextSegment {
+	if utils.LooksLikeHTML(before) || utils.LooksLikeHTML(after) {
+		before, after = utils.HTMLToMarkdown(before), utils.HTMLToMarkdown(after)
+	}
+	var segments []widget.RichTextSegment
+	changed := false
+	for _, line := range utils.DiffLines(before, after) {
+		style := widget.RichTextStyleParagraph
+		prefix := "  "
+		switch line.Op {
+		case utils.DiffRemoved:
+			style.ColorName = theme.ColorNameError
+			prefix = "- "
+		case utils.DiffAdded:
+			style.ColorName = theme.ColorNameSuccess
+			prefix = "+ "
+		}
+		changed = changed || line.Op != utils.DiffEqual
+		segments = append(segments, &widget.TextSegment{Style: style, Text: prefix + line.Text})
+	}
+	if !changed {
+		segments = []widget.RichTextSegment{&widget.TextSegment{Style: widget.RichTextStyleParagraph, Text: i18n.T("No changes.")}}
+	}
+	return segments
+}
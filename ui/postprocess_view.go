@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"Inference_Engine/i18n"
+	"Inference_Engine/postprocess"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PostProcessView switches the post-processing steps run on generated
+// content before it is shown or saved.
+type PostProcessView struct {
+	container *fyne.Container
+}
+
+// NewPostProcessView creates a new post-processing settings view
+func NewPostProcessView() *PostProcessView {
+	view := &PostProcessView{}
+	view.initialize()
+	return view
+}
+
+// initialize creates a toggle per step, saved as soon as it changes
+func (v *PostProcessView) initialize() {
+	description := widget.NewLabel(i18n.T("Generated articles, rewrites and drafts go through these steps, in order, before they are shown or saved. Chat answers and JSON are left as they are."))
+	description.Wrapping = fyne.TextWrapWord
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Post-Processing")),
+		widget.NewSeparator(),
+		description,
+	)
+	for _, step := range postprocess.Steps() {
+		check := widget.NewCheck(i18n.T(step.Label), step.SetEnabled)
+		check.SetChecked(step.Enabled())
+		v.container.Add(check)
+	}
+	blocks := widget.NewCheck(i18n.T("Convert to Gutenberg blocks when saving"), postprocess.PrefGutenbergBlocks.Set)
+	blocks.SetChecked(postprocess.PrefGutenbergBlocks.Get())
+	v.container.Add(widget.NewSeparator())
+	v.container.Add(blocks)
+}
+
+// Container returns the container for the view
+func (v *PostProcessView) Container() fyne.CanvasObject {
+	return v.container
+}
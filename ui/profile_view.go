@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"Inference_Engine/access"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ProfileSettingsView shows the app profile (admin or editor), switches
+// between them and sets the admin password.
+type ProfileSettingsView struct {
+	container *fyne.Container
+	profiles  *access.Profiles
+	window    fyne.Window
+
+	// UI elements
+	roleLabel      *widget.Label
+	switchButton   *widget.Button
+	passwordButton *widget.Button
+}
+
+// NewProfileSettingsView creates a new profile settings view
+func NewProfileSettingsView(profiles *access.Profiles, window fyne.Window) *ProfileSettingsView {
+	view := &ProfileSettingsView{
+		profiles: profiles,
+		window:   window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the profile settings view
+func (v *ProfileSettingsView) initialize() {
+	v.roleLabel = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	v.switchButton = widget.NewButtonWithIcon("", theme.AccountIcon(), v.switchProfile)
+	v.passwordButton = widget.NewButtonWithIcon(i18n.T("Set Admin Password"), theme.LoginIcon(), v.setPassword)
+	note := widget.NewLabel(i18n.T("The editor profile can generate and save drafts, but cannot publish to WordPress, change the inference providers or edit site credentials. Switching back to admin needs the admin password."))
+	note.Wrapping = fyne.TextWrapWord
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Profile")),
+		widget.NewSeparator(),
+		note,
+		container.NewHBox(v.roleLabel, v.switchButton, v.passwordButton),
+	)
+	v.profiles.OnChange(func(access.Role) { runOnUI(v.refresh) })
+	v.refresh()
+}
+
+// refresh shows the current profile.
+func (v *ProfileSettingsView) refresh() {
+	role := v.profiles.Role()
+	v.roleLabel.SetText(i18n.Tf("Current profile: %s", i18n.T(role.Label())))
+	if role == access.RoleEditor {
+		v.switchButton.SetText(i18n.T("Switch to Admin..."))
+		v.passwordButton.Hide()
+		return
+	}
+	v.switchButton.SetText(i18n.T("Switch to Editor"))
+	v.passwordButton.Show()
+	if v.profiles.HasAdminPassword() {
+		v.passwordButton.SetText(i18n.T("Change Admin Password"))
+	}
+}
+
+// switchProfile moves to the other profile, asking for the admin password
+// when leaving the editor one.
+func (v *ProfileSettingsView) switchProfile() {
+	if v.profiles.Role() == access.RoleAdmin {
+		if !v.profiles.HasAdminPassword() {
+			ShowError(fmt.Errorf("set an admin password first, so the editor profile cannot switch back without it"), v.window)
+			return
+		}
+		if err := v.profiles.SwitchToEditor(); err != nil {
+			ShowError(err, v.window)
+		}
+		return
+	}
+	password := widget.NewPasswordEntry()
+	password.SetPlaceHolder(i18n.T("Admin password"))
+	dialog.ShowForm(i18n.T("Switch to Admin"), i18n.T("Switch"), i18n.T("Cancel"),
+		[]*widget.FormItem{widget.NewFormItem(i18n.T("Password:"), password)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := v.profiles.SwitchToAdmin(password.Text); errors.Is(err, access.ErrWrongPassword) {
+				ShowError(fmt.Errorf("wrong admin password"), v.window)
+			} else if err != nil {
+				ShowError(err, v.window)
+			}
+		}, v.window)
+}
+
+// setPassword sets the admin password, or changes it given the current one.
+func (v *ProfileSettingsView) setPassword() {
+	current := widget.NewPasswordEntry()
+	password := widget.NewPasswordEntry()
+	confirm := widget.NewPasswordEntry()
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("New password:"), password),
+		widget.NewFormItem(i18n.T("Confirm:"), confirm),
+	}
+	if v.profiles.HasAdminPassword() {
+		items = append([]*widget.FormItem{widget.NewFormItem(i18n.T("Current password:"), current)}, items...)
+	}
+	form := dialog.NewForm(i18n.T("Admin Password"), i18n.T("Save"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if password.Text != confirm.Text {
+			ShowError(fmt.Errorf("the passwords do not match"), v.window)
+			return
+		}
+		if err := v.profiles.SetAdminPassword(current.Text, password.Text); err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		v.refresh()
+		dialog.ShowInformation(i18n.T("Admin Password"), i18n.T("The admin password has been saved."), v.window)
+	}, v.window)
+	form.Resize(fyne.NewSize(420, form.MinSize().Height))
+	form.Show()
+}
+
+// Container returns the container for the profile settings view
+func (v *ProfileSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// NewRoleGate shows content while the current profile has perm, and a note
+// that the admin profile manages it otherwise.
+func NewRoleGate(profiles *access.Profiles, perm access.Permission, content fyne.CanvasObject) fyne.CanvasObject {
+	locked := widget.NewLabelWithStyle(i18n.T("Managed by the admin profile."), fyne.TextAlignCenter, fyne.TextStyle{Italic: true})
+	gate := container.NewStack(content, locked)
+	apply := func() {
+		if profiles.Can(perm) {
+			locked.Hide()
+			content.Show()
+		} else {
+			content.Hide()
+			locked.Show()
+		}
+		gate.Refresh()
+	}
+	profiles.OnChange(func(access.Role) { runOnUI(apply) })
+	apply()
+	return gate
+}
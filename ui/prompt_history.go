@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+	"Inference_Engine/storage"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxPromptHistory caps how many entries each prompt history keeps.
+const maxPromptHistory = 25
+
+// Settings keeping the per-view prompt histories; their names are also the
+// state database keys.
+var (
+	PrefHistoryGeneratorPrompt      = settings.NewKey[[]string]("history.generator.prompt", nil)
+	PrefHistoryGeneratorInstruction = settings.NewKey[[]string]("history.generator.instruction", nil)
+	PrefHistoryChatPrompt           = settings.NewKey[[]string]("history.chat.prompt", nil)
+)
+
+// promptStore keeps the prompt histories when set, see SetStateStore.
+var promptStore *storage.DB
+
+// SetStateStore keeps prompt histories in the state database instead of the
+// app preferences. Call it before creating the views; histories saved in the
+// preferences by earlier versions are moved over as they are loaded.
+func SetStateStore(db *storage.DB) {
+	promptStore = db
+}
+
+// PromptHistory keeps the most recent prompts for one input, newest first,
+// persisted in the state database (or the app preferences without one). It also tracks a recall position so the
+// history can be stepped through with the arrow keys.
+type PromptHistory struct {
+	key     settings.Key[[]string]
+	entries []string
+
+	recallIndex int    // -1 when not recalling
+	draft       string // text that was in the input before recall started
+}
+
+// NewPromptHistory loads the history kept in a setting.
+func NewPromptHistory(key settings.Key[[]string]) *PromptHistory {
+	h := &PromptHistory{key: key, recallIndex: -1}
+	if promptStore != nil {
+		entries, err := promptStore.StringList(key.Name())
+		if err != nil {
+			logger.Warn("Failed to load prompt history", "key", key.Name(), "error", err)
+		}
+		h.entries = entries
+	}
+	if len(h.entries) == 0 {
+		h.entries = key.Get()
+		if promptStore != nil && len(h.entries) > 0 {
+			h.save()
+		}
+	}
+	return h
+}
+
+// save persists the entries.
+func (h *PromptHistory) save() {
+	if promptStore != nil {
+		if err := promptStore.SetStringList(h.key.Name(), h.entries); err != nil {
+			logger.Warn("Failed to save prompt history", "key", h.key.Name(), "error", err)
+			return
+		}
+		h.key.Reset() // Moved to the database
+		return
+	}
+	if len(h.entries) == 0 {
+		h.key.Reset()
+	} else {
+		h.key.Set(h.entries)
+	}
+}
+
+// Add records text as the most recent entry. Blank text is ignored and
+// duplicates are moved to the front.
+func (h *PromptHistory) Add(text string) {
+	h.ResetRecall()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	entries := []string{text}
+	for _, existing := range h.entries {
+		if existing != text {
+			entries = append(entries, existing)
+		}
+	}
+	if len(entries) > maxPromptHistory {
+		entries = entries[:maxPromptHistory]
+	}
+	h.entries = entries
+	h.save()
+}
+
+// Entries returns the history, newest first.
+func (h *PromptHistory) Entries() []string {
+	return append([]string(nil), h.entries...)
+}
+
+// Clear removes every entry.
+func (h *PromptHistory) Clear() {
+	h.entries = nil
+	h.ResetRecall()
+	h.save()
+}
+
+// Older steps back through the history and returns the entry to show.
+// current is the input's text, restored by Newer once past the newest entry.
+func (h *PromptHistory) Older(current string) (string, bool) {
+	if h.recallIndex+1 >= len(h.entries) {
+		return "", false
+	}
+	if h.recallIndex < 0 {
+		h.draft = current
+	}
+	h.recallIndex++
+	return h.entries[h.recallIndex], true
+}
+
+// Newer steps forward through the history, returning the original draft
+// after the newest entry.
+func (h *PromptHistory) Newer() (string, bool) {
+	if h.recallIndex < 0 {
+		return "", false
+	}
+	h.recallIndex--
+	if h.recallIndex < 0 {
+		return h.draft, true
+	}
+	return h.entries[h.recallIndex], true
+}
+
+// ResetRecall ends arrow-key recall, e.g. after a prompt is sent.
+func (h *PromptHistory) ResetRecall() {
+	h.recallIndex = -1
+	h.draft = ""
+}
+
+// newHistoryButton creates a button that lists the history in a popup menu
+// and calls onPick with the chosen entry.
+func newHistoryButton(window fyne.Window, history *PromptHistory, onPick func(string)) *widget.Button {
+	var button *widget.Button
+	button = widget.NewButtonWithIcon(i18n.T("History"), theme.HistoryIcon(), func() {
+		entries := history.Entries()
+		var items []*fyne.MenuItem
+		if len(entries) == 0 {
+			empty := fyne.NewMenuItem(i18n.T("No history yet"), nil)
+			empty.Disabled = true
+			items = append(items, empty)
+		}
+		for _, entry := range entries {
+			text := entry
+			items = append(items, fyne.NewMenuItem(historyLabel(text), func() { onPick(text) }))
+		}
+		if len(entries) > 0 {
+			items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem(i18n.T("Clear History"), history.Clear))
+		}
+		position := fyne.CurrentApp().Driver().AbsolutePositionForObject(button)
+		position = position.Add(fyne.NewPos(0, button.Size().Height))
+		widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), window.Canvas(), position)
+	})
+	return button
+}
+
+// historyLabel flattens an entry to a single short line for the history menu.
+func historyLabel(text string) string {
+	label := strings.Join(strings.Fields(text), " ")
+	if len(label) > 70 {
+		label = truncateUTF8(label, 70) + "…"
+	}
+	return label
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ProviderSettingsView overrides a provider's base URL, headers and
+// organization ID, to route it through a proxy or an AI gateway.
+type ProviderSettingsView struct {
+	container *fyne.Container
+	window    fyne.Window
+
+	providerSelect    *widget.Select
+	baseURLEntry      *widget.Entry
+	organizationEntry *widget.Entry
+	headersEntry      *EditorEntry
+	statusLabel       *widget.Label
+}
+
+// NewProviderSettingsView creates a new provider settings view
+func NewProviderSettingsView(window fyne.Window) *ProviderSettingsView {
+	view := &ProviderSettingsView{window: window}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the provider settings view
+func (v *ProviderSettingsView) initialize() {
+	v.baseURLEntry = widget.NewEntry()
+	v.baseURLEntry.SetPlaceHolder("https://gateway.ai.cloudflare.com/v1/<account>/<gateway>/openai")
+	v.organizationEntry = widget.NewEntry()
+	v.organizationEntry.SetPlaceHolder(i18n.T("Optional"))
+	v.headersEntry = NewEditorEntry()
+	v.headersEntry.SetPlaceHolder("Helicone-Auth: Bearer <key>\ncf-aig-authorization: Bearer <token>")
+	v.headersEntry.SetMinRowsVisible(3)
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+
+	v.providerSelect = widget.NewSelect(inference.Providers(), func(string) { v.load() })
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Override"), theme.DocumentSaveIcon(), v.save)
+	resetButton := widget.NewButton(i18n.T("Reset"), v.reset)
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Provider Endpoints")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Send a provider's requests through a proxy or an AI gateway such as Cloudflare AI Gateway or Helicone. The base URL replaces the part of the API address before /chat/completions (for Gemini, before models/).")),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Provider:"), v.providerSelect),
+			widget.NewFormItem(i18n.T("Base URL:"), v.baseURLEntry),
+			widget.NewFormItem(i18n.T("Organization ID:"), v.organizationEntry),
+			widget.NewFormItem(i18n.T("Extra headers:"), v.headersEntry),
+		),
+		container.NewHBox(saveButton, resetButton),
+		v.statusLabel,
+	)
+	if providers := inference.Providers(); len(providers) > 0 {
+		v.providerSelect.SetSelected(providers[0])
+	}
+}
+
+// load shows the selected provider's override.
+func (v *ProviderSettingsView) load() {
+	override := inference.ProviderOverrideFor(v.providerSelect.Selected)
+	v.baseURLEntry.SetText(override.BaseURL)
+	v.organizationEntry.SetText(override.Organization)
+	v.headersEntry.SetText(inference.FormatHeaders(override.Headers))
+	switch {
+	case override.BaseURL != "":
+		v.statusLabel.SetText(i18n.Tf("Requests go to %s.", override.BaseURL))
+	case override.IsZero():
+		v.statusLabel.SetText(i18n.T("Requests go straight to the provider."))
+	default:
+		v.statusLabel.SetText(i18n.T("Requests go straight to the provider, with extra headers."))
+	}
+}
+
+// save stores the override for the selected provider.
+func (v *ProviderSettingsView) save() {
+	provider := v.providerSelect.Selected
+	if provider == "" {
+		return
+	}
+	headers, err := inference.ParseHeaders(v.headersEntry.Text)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	baseURL := strings.TrimSpace(v.baseURLEntry.Text)
+	if baseURL != "" && !strings.HasPrefix(baseURL, "https://") && !strings.HasPrefix(baseURL, "http://") {
+		ShowError(fmt.Errorf("the base URL must start with https:// or http://"), v.window)
+		return
+	}
+	override := inference.ProviderOverride{BaseURL: baseURL, Headers: headers, Organization: strings.TrimSpace(v.organizationEntry.Text)}
+	if err := inference.SetProviderOverride(provider, override); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	v.load()
+	dialog.ShowInformation(i18n.T("Restart Required"), i18n.Tf("The %s endpoint was saved. Restart the application to use it.", provider), v.window)
+}
+
+// reset removes the saved override, going back to the environment's or the
+// provider's defaults.
+func (v *ProviderSettingsView) reset() {
+	if err := inference.SetProviderOverride(v.providerSelect.Selected, inference.ProviderOverride{}); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	v.load()
+}
+
+// Container returns the container for the view
+func (v *ProviderSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,281 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/seo"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// leadingTitlePattern matches a heading of any level at the start of
+// content, as HTML or a Markdown "#" line. The heading levels step turns the
+// title H1 into an H2, so any level counts.
+var leadingTitlePattern = regexp.MustCompile(`(?is)^\s*(?:<h([1-6])[^>]*>(.*?)</h[1-6]>|#{1,6}[ \t]+([^\n]+))`)
+
+// maxPostTitleLength caps, in bytes, a title taken from the prompt.
+const maxPostTitleLength = 100
+
+// titleTagPattern matches the inline tags inside a title heading.
+var titleTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// splitPostTitle takes the title heading off the start of content, since
+// the theme shows a post's title above its content. Without a leading
+// heading, the first line of prompt is the title and content is returned
+// as it is.
+func splitPostTitle(content, prompt string) (title, body string) {
+	match := leadingTitlePattern.FindStringSubmatchIndex(content)
+	if match == nil {
+		prompt, _, _ = strings.Cut(strings.TrimSpace(prompt), "\n")
+		return strings.TrimSpace(truncateUTF8(prompt, maxPostTitleLength)), content
+	}
+	raw := ""
+	if match[4] >= 0 {
+		raw = content[match[4]:match[5]]
+	} else {
+		raw = content[match[6]:match[7]]
+	}
+	title = strings.TrimSpace(html.UnescapeString(titleTagPattern.ReplaceAllString(raw, "")))
+	return strings.TrimSpace(strings.TrimRight(title, "#")), strings.TrimSpace(content[match[1]:])
+}
+
+// termNames returns the names of terms, in order.
+func termNames(terms []wordpress.Term) []string {
+	names := make([]string, len(terms))
+	for i, term := range terms {
+		names[i] = term.Name
+	}
+	return names
+}
+
+// publishAsPost creates a post from the result. The site's categories and
+// tags are fetched in the background and the model suggests which fit,
+// plus new tags, which the publish dialog has ticked.
+func (v *ContentGeneratorView) publishAsPost() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	if err := v.wpService.CanDo(wordpress.ActionDraftPost); err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	content := v.resultOutput.Text
+	if strings.TrimSpace(content) == "" {
+		ShowError(fmt.Errorf("no generated content to publish"), v.window)
+		return
+	}
+	if requireApproval() && !v.approvedDraft(content) {
+		ShowError(fmt.Errorf("this content needs approval before it is saved to WordPress; use Review to submit it"), v.window)
+		return
+	}
+	approvedID := 0
+	if v.approvedDraft(content) {
+		approvedID = v.currentDraftID
+	}
+	model := v.chosenModel()
+	title, body := splitPostTitle(content, v.promptEntry.Text)
+
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		report(0, i18n.T("Fetching categories and tags"))
+		categories, err := v.wpService.GetCategories()
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to fetch categories: %w", err), v.window) })
+			return err
+		}
+		tags, err := v.wpService.GetTags()
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to fetch tags: %w", err), v.window) })
+			return err
+		}
+
+		report(-1, i18n.T("Suggesting categories and tags"))
+		prompt := inference.GetTaxonomySuggestionPrompt(strings.Join(termNames(categories), "\n"), strings.Join(termNames(tags), "\n"), content)
+		output, err := v.inferenceService.Generate(prompt, generateOptions(ctx, model, inference.TaskStructured))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var suggestion seo.TaxonomySuggestion
+		if err == nil {
+			suggestion, err = seo.ParseTaxonomySuggestion(output, termNames(categories), termNames(tags))
+		}
+		if err != nil {
+			// The post can still be published, with the terms picked by hand
+			logger.Warn("ContentGeneratorView: failed to suggest categories and tags", "error", err)
+		}
+		runOnUI(func() { v.showPublishPost(title, body, approvedID, categories, tags, suggestion) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.publishAsPost", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Tag Suggestions", truncateUTF8(content, 60), run)
+}
+
+// showPublishPost asks for the post's title, status, categories and tags,
+// with the suggested ones ticked, and creates the post on confirmation.
+// Publish stays disabled while the title is empty.
+func (v *ContentGeneratorView) showPublishPost(title, body string, approvedID int, categories, tags []wordpress.Term, suggestion seo.TaxonomySuggestion) {
+	titleEntry := widget.NewEntry()
+	titleEntry.SetText(title)
+	titleEntry.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return errors.New(i18n.T("The post needs a title"))
+		}
+		return nil
+	}
+	statusLabels := map[string]string{i18n.T("Draft"): wordpress.PostDraft, i18n.T("Published"): wordpress.PostPublish}
+	statusOptions := []string{i18n.T("Draft")}
+	if v.wpService.Capabilities().Allows(wordpress.ActionPublishPost) {
+		statusOptions = append(statusOptions, i18n.T("Published")) // Contributors may only write drafts
+	}
+	status := widget.NewRadioGroup(statusOptions, nil)
+	status.Horizontal = true
+	status.SetSelected(i18n.T("Draft"))
+
+	categoryChecks := widget.NewCheckGroup(termNames(categories), nil)
+	categoryChecks.SetSelected(suggestion.Categories)
+
+	// The site's suggested tags, then the new ones; other tags can be typed
+	newTagLabels := map[string]string{}
+	tagOptions := append([]string(nil), suggestion.Tags...)
+	for _, name := range suggestion.NewTags {
+		label := i18n.Tf("%s (new)", name)
+		newTagLabels[label] = name
+		tagOptions = append(tagOptions, label)
+	}
+	tagChecks := widget.NewCheckGroup(tagOptions, nil)
+	tagChecks.Horizontal = true
+	tagChecks.SetSelected(tagOptions)
+	otherTags := widget.NewEntry()
+	otherTags.SetPlaceHolder(i18n.T("More tags, separated by commas"))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Title:"), titleEntry),
+		widget.NewFormItem(i18n.T("Status:"), status),
+		widget.NewFormItem(i18n.T("Categories:"), container.NewVScroll(categoryChecks)),
+		widget.NewFormItem(i18n.T("Tags:"), container.NewVBox(tagChecks, otherTags)),
+	}
+	if len(categories) == 0 {
+		items[2] = widget.NewFormItem(i18n.T("Categories:"), widget.NewLabel(i18n.T("The site has no categories with posts.")))
+	}
+	// The same extras as a save to a page, with the pages related to the
+	// first WordPress source
+	relatedTo := 0
+	for _, source := range v.sourceContents {
+		if source.Source == "WordPress" && source.ID > 0 {
+			relatedTo = source.ID
+			break
+		}
+	}
+	extras, extraObjects := v.newSaveExtras(body, relatedTo)
+	items = append(items, widget.NewFormItem(i18n.T("Add:"), container.NewVBox(extraObjects...)))
+
+	d := dialog.NewForm(i18n.T("Publish as Post"), i18n.T("Publish"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		var tagNames []string
+		for _, label := range tagChecks.Selected {
+			if name, ok := newTagLabels[label]; ok {
+				label = name
+			}
+			tagNames = append(tagNames, label)
+		}
+		for _, name := range strings.Split(otherTags.Text, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				tagNames = append(tagNames, name)
+			}
+		}
+		content, err := extras.apply(body)
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		post := wordpress.NewPost{
+			Title:   strings.TrimSpace(titleEntry.Text),
+			Content: content,
+			Status:  statusLabels[status.Selected],
+		}
+		for _, category := range categories {
+			for _, name := range categoryChecks.Selected {
+				if category.Name == name {
+					post.Categories = append(post.Categories, category.ID)
+				}
+			}
+		}
+		v.createPost(post, tags, tagNames, approvedID)
+	}, v.window)
+	d.Resize(fyne.NewSize(640, 560))
+	d.Show()
+}
+
+// createPost creates the tags in tagNames the site doesn't have yet, then
+// the post with all of them, in the background. An approved draft is marked
+// published once the post is created.
+func (v *ContentGeneratorView) createPost(post wordpress.NewPost, tags []wordpress.Term, tagNames []string, approvedID int) {
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		seen := map[int]bool{}
+		for i, name := range tagNames {
+			report(float64(i)/float64(len(tagNames)+1), i18n.T("Adding tags"))
+			term, ok := wordpress.Term{}, false
+			for _, tag := range tags {
+				if strings.EqualFold(tag.Name, name) {
+					term, ok = tag, true
+					break
+				}
+			}
+			if !ok {
+				var err error
+				if term, err = v.wpService.CreateTag(name); err != nil {
+					runOnUI(func() { ShowError(fmt.Errorf("failed to add the tag '%s': %w", name, err), v.window) })
+					return err
+				}
+			}
+			if !seen[term.ID] {
+				seen[term.ID] = true
+				post.Tags = append(post.Tags, term.ID)
+			}
+		}
+
+		report(float64(len(tagNames))/float64(len(tagNames)+1), i18n.T("Creating the post"))
+		created, err := v.wpService.CreatePost(ctx, post)
+		runOnUI(func() {
+			if err != nil {
+				ShowError(fmt.Errorf("failed to create the post: %w", err), v.window)
+				return
+			}
+			if approvedID != 0 {
+				if err := v.drafts.Move(approvedID, history.ReviewPublished, reviewerName(), fmt.Sprintf("Published as post '%s'", post.Title)); err != nil {
+					logger.Warn("ContentGeneratorView: failed to mark draft published", "draft_id", approvedID, "error", err)
+				}
+			}
+			message := i18n.Tf("Post '%s' saved as a draft.", post.Title)
+			if post.Status == wordpress.PostPublish {
+				message = i18n.Tf("Post '%s' published.", post.Title)
+			}
+			dialog.ShowInformation(i18n.T("Success"), message+"\n"+created.Link, v.window)
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.createPost", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Post Publish", post.Title, run)
+}
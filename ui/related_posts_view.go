@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// relatedCountOptions are the numbers of related pages a block can list.
+var relatedCountOptions = []string{"2", "3", "4", "5", "6"}
+
+// RelatedPostsView edits the "Further reading" settings of each saved site.
+type RelatedPostsView struct {
+	container *fyne.Container
+	store     *editorial.RelatedPostsStore
+	wpService *wordpress.WordPressService
+	window    fyne.Window
+
+	// UI elements
+	siteSelect      *widget.Select
+	enabledCheck    *widget.Check
+	countSelect     *widget.Select
+	headingEntry    *widget.Entry
+	similarity      *widget.Slider
+	similarityLabel *widget.Label
+	saveButton      *widget.Button
+}
+
+// NewRelatedPostsView creates a new related posts settings view
+func NewRelatedPostsView(store *editorial.RelatedPostsStore, wpService *wordpress.WordPressService, window fyne.Window) *RelatedPostsView {
+	view := &RelatedPostsView{
+		store:     store,
+		wpService: wpService,
+		window:    window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the related posts settings view
+func (v *RelatedPostsView) initialize() {
+	v.enabledCheck = widget.NewCheck(i18n.T("Offer a related pages block when saving to a page"), nil)
+	v.countSelect = widget.NewSelect(relatedCountOptions, nil)
+	v.headingEntry = widget.NewEntry()
+	v.headingEntry.SetPlaceHolder(editorial.DefaultRelatedPostsSettings.Heading)
+	v.similarityLabel = widget.NewLabel("")
+	v.similarity = widget.NewSlider(0.3, 0.9)
+	v.similarity.Step = 0.05
+	v.similarity.OnChanged = func(value float64) {
+		v.similarityLabel.SetText(fmt.Sprintf("%.0f%%", value*100))
+	}
+
+	v.siteSelect = widget.NewSelect(nil, func(string) { v.load() })
+	v.saveButton = widget.NewButtonWithIcon(i18n.T("Save Related Pages"), theme.DocumentSaveIcon(), v.save)
+	v.RefreshSites()
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Related Pages")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Links to the most similar pages of the site, from the embeddings index of the Duplicates report, added at the end of content saved to a page.")),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Site:"), v.siteSelect),
+			widget.NewFormItem("", v.enabledCheck),
+			widget.NewFormItem(i18n.T("Links:"), v.countSelect),
+			widget.NewFormItem(i18n.T("Heading:"), v.headingEntry),
+			widget.NewFormItem(i18n.T("Minimum similarity:"), newReadingOrderBorder(nil, nil, nil, v.similarityLabel, v.similarity)),
+		),
+		container.NewHBox(v.saveButton),
+	)
+}
+
+// RefreshSites updates the site options after saved sites change, keeping
+// the current choice if it still exists, or choosing the connected site.
+func (v *RelatedPostsView) RefreshSites() {
+	var options []string
+	if v.wpService != nil {
+		for _, site := range v.wpService.GetSavedSites() {
+			options = append(options, site.Name)
+		}
+	}
+	selected := v.siteSelect.Selected
+	v.siteSelect.Options = options
+	for _, option := range options {
+		if option == selected {
+			v.siteSelect.Refresh()
+			return
+		}
+	}
+	if v.wpService != nil && v.wpService.GetCurrentSiteName() != "" {
+		v.siteSelect.SetSelected(v.wpService.GetCurrentSiteName())
+	} else if len(options) > 0 {
+		v.siteSelect.SetSelected(options[0])
+	} else {
+		v.siteSelect.ClearSelected()
+	}
+	v.load()
+}
+
+// load shows the selected site's settings.
+func (v *RelatedPostsView) load() {
+	settings := v.store.Settings(v.siteSelect.Selected)
+	v.enabledCheck.SetChecked(settings.Enabled)
+	v.countSelect.SetSelected(strconv.Itoa(settings.Count))
+	v.headingEntry.SetText(settings.Heading)
+	v.similarity.SetValue(settings.MinSimilarity)
+	if v.siteSelect.Selected == "" {
+		v.saveButton.Disable()
+	} else {
+		v.saveButton.Enable()
+	}
+}
+
+// save stores the form as the selected site's settings.
+func (v *RelatedPostsView) save() {
+	count, _ := strconv.Atoi(v.countSelect.Selected)
+	settings := editorial.RelatedPostsSettings{
+		Enabled:       v.enabledCheck.Checked,
+		Count:         count,
+		Heading:       v.headingEntry.Text,
+		MinSimilarity: v.similarity.Value,
+	}
+	if err := v.store.Save(v.siteSelect.Selected, settings); err != nil {
+		ShowError(fmt.Errorf("related pages settings not saved: %w", err), v.window)
+	}
+}
+
+// Container returns the container for the related posts settings view
+func (v *RelatedPostsView) Container() fyne.CanvasObject {
+	return v.container
+}
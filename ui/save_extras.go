@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+	"Inference_Engine/postprocess"
+	"Inference_Engine/seo"
+	"Inference_Engine/utils"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// saveExtras are what the Generator adds to its result on the way to
+// WordPress, as pages and as posts alike: a table of contents, the accepted
+// FAQ section, related pages and compliance disclaimers, each offered in
+// the save confirmation.
+type saveExtras struct {
+	faq              *seo.FAQ
+	tocCheck         *widget.Check
+	disclaimers      editorial.Disclaimers
+	disclaimerChecks *widget.CheckGroup
+	related          []seo.RelatedLink
+	relatedSettings  editorial.RelatedPostsSettings
+	relatedCheck     *widget.Check
+}
+
+// newSaveExtras offers the extras for content, with the pages related to
+// relatedTo (0 for none), and returns the widgets to show them with.
+func (v *ContentGeneratorView) newSaveExtras(content string, relatedTo int) (*saveExtras, []fyne.CanvasObject) {
+	e := &saveExtras{faq: v.faq}
+	e.tocCheck = widget.NewCheck(i18n.T("Insert a table of contents after the intro"), nil)
+	if utils.LooksLikeHTML(content) {
+		e.tocCheck.SetChecked(PrefInsertTOC.Get())
+	} else {
+		e.tocCheck.Disable() // The anchors need HTML headings
+	}
+	objects := []fyne.CanvasObject{e.tocCheck}
+
+	// The disclaimers of the detected categories are ticked; any can be
+	// ticked or unticked
+	if v.disclaimers != nil {
+		e.disclaimers = v.disclaimers.Disclaimers()
+	}
+	if len(e.disclaimers.Rules) > 0 {
+		e.disclaimerChecks = widget.NewCheckGroup(e.disclaimers.Categories(), nil)
+		e.disclaimerChecks.Horizontal = true
+		e.disclaimerChecks.SetSelected(e.disclaimers.Detect(content))
+		objects = append(objects, widget.NewLabel(i18n.T("Add disclaimers:")), e.disclaimerChecks)
+	}
+
+	// Related pages from the embeddings index, if the site has the block on
+	if relatedTo != 0 {
+		e.related, e.relatedSettings = v.relatedLinks(relatedTo)
+	}
+	if len(e.related) > 0 {
+		titles := make([]string, len(e.related))
+		for i, link := range e.related {
+			titles[i] = link.Title
+		}
+		e.relatedCheck = widget.NewCheck(i18n.Tf("Add \"%s\": %s", e.relatedSettings.Heading, strings.Join(titles, ", ")), nil)
+		e.relatedCheck.SetChecked(true)
+		objects = append(objects, e.relatedCheck)
+	}
+	return e, objects
+}
+
+// apply returns content with the ticked extras, converted for saving, and
+// remembers whether a table of contents was asked for.
+func (e *saveExtras) apply(content string) (string, error) {
+	if !e.tocCheck.Disabled() {
+		PrefInsertTOC.Set(e.tocCheck.Checked)
+		if e.tocCheck.Checked {
+			withTOC, ok := seo.InsertTOC(content)
+			if !ok {
+				logger.Info("ContentGeneratorView: too few headings for a table of contents")
+			}
+			content = withTOC
+		}
+	}
+	if e.faq != nil {
+		withFAQ, err := seo.AppendFAQ(content, *e.faq)
+		if err != nil {
+			return "", fmt.Errorf("failed to append FAQ: %w", err)
+		}
+		content = withFAQ
+	}
+	if e.relatedCheck != nil && e.relatedCheck.Checked {
+		content = seo.WithRelated(content, seo.RelatedBlock(e.relatedSettings.Heading, e.related, utils.LooksLikeHTML(content)))
+	}
+	if e.disclaimerChecks != nil && len(e.disclaimerChecks.Selected) > 0 {
+		content = e.disclaimers.Apply(content, e.disclaimerChecks.Selected)
+		logger.Info("ContentGeneratorView: added disclaimers", "categories", e.disclaimerChecks.Selected)
+	}
+	return postprocess.ForSave(content), nil
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// selectionContextLength is how many characters before and after a selection
+// are given to the model as context.
+const selectionContextLength = 1500
+
+// translationLanguages are offered for Translate Selection; any other
+// language can be typed in.
+var translationLanguages = []string{"English", "Spanish", "French", "German", "Italian", "Portuguese", "Dutch"}
+
+// showSelectionActions shows, below anchor, the AI edits that apply to the
+// text selected in editor. model is a model selector choice, or "" for the
+// default.
+func showSelectionActions(window fyne.Window, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, editor *EditorEntry, anchor fyne.CanvasObject, model string) {
+	start, end, ok := editor.Selection()
+	if !ok {
+		dialog.ShowInformation(i18n.T("Edit Selection"), i18n.T("Select the text to edit in the editor first."), window)
+		return
+	}
+	edit := func(name, instruction string) {
+		editSelection(window, inferenceService, jobQueue, editor, start, end, name, instruction, model)
+	}
+	items := []*fyne.MenuItem{
+		fyne.NewMenuItem(i18n.T("Rewrite"), func() { edit("Rewrite", inference.SelectionRewriteInstruction) }),
+		fyne.NewMenuItem(i18n.T("Shorten"), func() { edit("Shorten", inference.SelectionShortenInstruction) }),
+		fyne.NewMenuItem(i18n.T("Translate..."), func() {
+			language := widget.NewSelectEntry(translationLanguages)
+			language.SetText(translationLanguages[0])
+			dialog.ShowForm(i18n.T("Translate"), i18n.T("Translate"), i18n.T("Cancel"), []*widget.FormItem{
+				widget.NewFormItem(i18n.T("Language:"), language),
+			}, func(confirmed bool) {
+				if confirmed && strings.TrimSpace(language.Text) != "" {
+					edit("Translate", fmt.Sprintf(inference.SelectionTranslateInstruction, strings.TrimSpace(language.Text)))
+				}
+			}, window)
+		}),
+	}
+	position := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor)
+	position = position.Add(fyne.NewPos(0, anchor.Size().Height))
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), window.Canvas(), position)
+}
+
+// editSelection asks the model for the passage between the rune offsets start
+// and end of editor edited as instruction says, in the background, and puts
+// the reviewed result in its place.
+func editSelection(window fyne.Window, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, editor *EditorEntry, start, end int, name, instruction, model string) {
+	text := editor.Text
+	runes := []rune(text)
+	passage := string(runes[start:end])
+	before := string(runes[max(0, start-selectionContextLength):start])
+	after := string(runes[end:min(len(runes), end+selectionContextLength)])
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		output, err := inferenceService.Generate(inference.GetSelectionEditPrompt(instruction, before, passage, after), generateOptions(ctx, model, inference.TaskGeneral))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Error("Selection edit failed", "action", name, "error", err)
+			runOnUI(func() { ShowError(fmt.Errorf("failed to edit the selection: %w", err), window) })
+			return err
+		}
+		runOnUI(func() {
+			if editor.Text != text {
+				ShowError(fmt.Errorf("the text changed while the selection was being edited; select it and try again"), window)
+				return
+			}
+			reviewSelectionEdit(window, editor, start, end, passage, strings.TrimSpace(output))
+		})
+		return nil
+	}
+	if jobQueue == nil {
+		crash.Go("editSelection", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	jobQueue.Submit(name+" Selection", truncateUTF8(passage, 60), run)
+}
+
+// reviewSelectionEdit shows the edited passage, editable, and replaces the
+// original passage with it on confirmation. The replacement can be undone.
+func reviewSelectionEdit(window fyne.Window, editor *EditorEntry, start, end int, passage, edited string) {
+	original := widget.NewLabel(passage)
+	original.Wrapping = fyne.TextWrapWord
+	result := widget.NewMultiLineEntry()
+	result.Wrapping = fyne.TextWrapWord
+	result.SetMinRowsVisible(8)
+	result.SetText(edited)
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("Selected:"), original),
+		widget.NewFormItem(i18n.T("Edited:"), result),
+	)
+	d := dialog.NewCustomConfirm(i18n.T("Edit Selection"), i18n.T("Replace"), i18n.T("Cancel"), form, func(confirmed bool) {
+		if confirmed {
+			editor.ReplaceRange(start, end, result.Text)
+			if editor.OnAIEdit != nil {
+				editor.OnAIEdit()
+			}
+		}
+	}, window)
+	d.Resize(fyne.NewSize(640, 480))
+	d.Show()
+}
@@ -0,0 +1,497 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"Inference_Engine/audit"
+	"Inference_Engine/crash"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/searchconsole"
+	"Inference_Engine/seo"
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SEOAuditView checks every page of the connected site for common SEO
+// problems and fixes a finding with the model's help: it proposes the
+// missing description, title, content, heading or alt text, which can be
+// edited before it is written to the page.
+type SEOAuditView struct {
+	container        fyne.CanvasObject
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+	jobQueue         *jobs.Queue
+	searchConsole    *searchconsole.Client // Queries the page ranks for, kept when expanding it; nil disables it
+	drafts           *history.Store        // Where fixes are reviewed before they are applied; nil disables them when approval is required
+	window           fyne.Window
+
+	runButton    *widget.Button
+	issueSelect  *widget.Select
+	summaryLabel *widget.Label
+	findingList  *widget.List
+	detailLabel  *widget.Label
+	fixButton    *CapabilityButton
+
+	// Data
+	findings []audit.SEOFinding // All findings of the last audit
+	shown    []audit.SEOFinding // Those matching the issue filter
+	selected int
+}
+
+// NewSEOAuditView creates a new SEOAuditView
+func NewSEOAuditView(wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, window fyne.Window) *SEOAuditView {
+	view := &SEOAuditView{
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		window:           window,
+		selected:         -1,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *SEOAuditView) initialize() {
+	v.runButton = widget.NewButton(i18n.T("Run Audit"), v.runAudit)
+	options := []string{i18n.T("All issues")}
+	for _, issue := range audit.SEOIssues {
+		options = append(options, i18n.T(issue))
+	}
+	v.issueSelect = widget.NewSelect(options, func(string) { v.applyFilter() })
+	v.issueSelect.SetSelectedIndex(0)
+	v.summaryLabel = widget.NewLabel(i18n.T("Run the audit to check every page of the site."))
+
+	v.findingList = widget.NewList(
+		func() int {
+			return len(v.shown)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template SEO finding")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.shown) {
+				return
+			}
+			finding := v.shown[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("[%s] %s", i18n.T(finding.Issue), finding.Title))
+		},
+	)
+	v.findingList.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.updateDetails()
+	}
+	v.findingList.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.updateDetails()
+	}
+
+	v.detailLabel = widget.NewLabel("")
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.fixButton = newCapabilityButton(i18n.T("Fix with AI"), wordpress.ActionPublish, v.fix)
+
+	v.container = newReadingOrderBorder(
+		container.NewVBox( // Top
+			container.NewHBox(v.runButton, v.issueSelect, v.summaryLabel),
+		),
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			v.detailLabel,
+			container.NewHBox(layout.NewSpacer(), v.fixButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.findingList,
+	)
+	v.updateDetails()
+}
+
+// SetJobQueue sets the queue that audits and fixes are submitted to
+func (v *SEOAuditView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetSearchConsole sets the client that reads the queries a thin page
+// already ranks for, so expanding it keeps them covered.
+func (v *SEOAuditView) SetSearchConsole(client *searchconsole.Client) {
+	v.searchConsole = client
+}
+
+// SetDraftHistory sets the store that fixes are reviewed in before they are
+// applied, when approval is required.
+func (v *SEOAuditView) SetDraftHistory(store *history.Store) {
+	v.drafts = store
+}
+
+// SiteChanged clears the findings of the previous site.
+func (v *SEOAuditView) SiteChanged() {
+	v.findings = nil
+	v.summaryLabel.SetText(i18n.T("Run the audit to check every page of the site."))
+	v.applyFilter()
+}
+
+// applyFilter shows the findings of the selected issue kind.
+func (v *SEOAuditView) applyFilter() {
+	v.shown = nil
+	index := v.issueSelect.SelectedIndex()
+	for _, finding := range v.findings {
+		if index <= 0 || finding.Issue == audit.SEOIssues[index-1] {
+			v.shown = append(v.shown, finding)
+		}
+	}
+	v.selected = -1
+	v.findingList.UnselectAll()
+	v.findingList.Refresh()
+	v.updateDetails()
+}
+
+// updateDetails describes the selected finding.
+func (v *SEOAuditView) updateDetails() {
+	if v.selected < 0 || v.selected >= len(v.shown) {
+		v.detailLabel.SetText("")
+		v.fixButton.Disable()
+		return
+	}
+	finding := v.shown[v.selected]
+	lines := []string{finding.Link, finding.Detail}
+	lines = append(lines, finding.Others...)
+	lines = append(lines, finding.Images...)
+	v.detailLabel.SetText(strings.Join(lines, "\n"))
+	v.fixButton.Enable()
+}
+
+// runAudit fetches every page and checks it.
+func (v *SEOAuditView) runAudit() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	v.runButton.Disable()
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.runButton.Enable)
+		progress(-1, i18n.T("Fetching pages"))
+		pages, err := v.wpService.GetPagesForAudit()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("SEO audit failed: %w", err), v.window) })
+			return err
+		}
+		findings := audit.AuditSEO(pages)
+		audit.SortFindings(findings)
+		runOnUI(func() {
+			v.findings = findings
+			v.summaryLabel.SetText(i18n.Tf("%d findings on %d pages", len(findings), len(pages)))
+			v.applyFilter()
+		})
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("SEOAuditView.runAudit", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("SEO Audit", v.wpService.GetCurrentSiteName(), run)
+}
+
+// fix has the model propose a fix for the selected finding, then shows it
+// for review.
+func (v *SEOAuditView) fix() {
+	if v.selected < 0 || v.selected >= len(v.shown) {
+		return
+	}
+	finding := v.shown[v.selected]
+	v.fixButton.Disable()
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.updateDetails)
+		progress(-1, i18n.T("Fetching page"))
+		content, err := v.wpService.GetPageContent(finding.PageID)
+		var proposal string
+		if err == nil {
+			progress(-1, i18n.T("Writing the fix"))
+			proposal, err = v.propose(ctx, finding, content)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to fix '%s': %w", finding.Title, err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showFix(finding, proposal) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("SEOAuditView.fix", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("SEO Fix", fmt.Sprintf("%s: %s", finding.Issue, finding.Title), run)
+}
+
+// propose asks the model for the fix of a finding, as the text to review.
+func (v *SEOAuditView) propose(ctx context.Context, finding audit.SEOFinding, content string) (string, error) {
+	text := utils.HTMLToMarkdown(content)
+	opts := inference.GenerateOptions{Context: ctx, Task: inference.TaskSummarization}
+	switch finding.Issue {
+	case audit.IssueMissingDescription:
+		output, err := v.inferenceService.Generate(inference.GetMetaDescriptionPrompt(finding.Title, text), opts)
+		return audit.CleanLine(output), err
+	case audit.IssueDuplicateTitle:
+		output, err := v.inferenceService.Generate(inference.GetPageTitlePrompt(finding.Title, text), opts)
+		return audit.CleanLine(output), err
+	case audit.IssueMissingH1:
+		output, err := v.inferenceService.Generate(inference.GetPageHeadingPrompt(finding.Title, text), opts)
+		return audit.CleanLine(output), err
+	case audit.IssueThinContent:
+		prompt := inference.GetWordPressContentExpandPrompt(content)
+		if v.searchConsole != nil {
+			queries, err := v.searchConsole.PageQueries(ctx, finding.Link, searchconsole.DefaultDays, searchconsole.DefaultLimit)
+			if err != nil {
+				logger.Warn("SEOAuditView: expanding without search queries", "page_id", finding.PageID, "error", err)
+			}
+			prompt = inference.WithSearchQueries(prompt, searchconsole.Summary(queries))
+		}
+		output, err := v.inferenceService.Generate(prompt, opts)
+		return strings.TrimSpace(output), err
+	case audit.IssueMissingAlt:
+		opts.Task = inference.TaskStructured
+		output, err := v.inferenceService.Generate(inference.GetAltTextPrompt(finding.Title, strings.Join(finding.Images, "\n"), text), opts)
+		if err != nil {
+			return "", err
+		}
+		alts, err := audit.ParseAltText(output)
+		return formatAltText(finding.Images, alts), err
+	}
+	return "", fmt.Errorf("no fix for %q", finding.Issue)
+}
+
+// showFix shows the proposed fix, editable, and applies it on confirmation.
+func (v *SEOAuditView) showFix(finding audit.SEOFinding, proposal string) {
+	var editor *widget.Entry
+	if finding.Issue == audit.IssueThinContent || finding.Issue == audit.IssueMissingAlt {
+		editor = widget.NewMultiLineEntry()
+		editor.Wrapping = fyne.TextWrapWord
+		editor.SetMinRowsVisible(14)
+	} else {
+		editor = widget.NewEntry()
+	}
+	editor.SetText(proposal)
+	help := map[string]string{
+		audit.IssueMissingDescription: i18n.T("This description will be saved as the page's excerpt, which themes and SEO plugins use when no other description is set."),
+		audit.IssueDuplicateTitle:     i18n.T("The page will be renamed to this title."),
+		audit.IssueThinContent:        i18n.T("This expanded content will replace the page's content."),
+		audit.IssueMissingH1:          i18n.T("This heading will be added at the top of the page."),
+		audit.IssueMissingAlt:         i18n.T("One image per line: its URL, \" = \", then its alt text."),
+	}[finding.Issue]
+	label := widget.NewLabel(help)
+	label.Wrapping = fyne.TextWrapWord
+
+	// A renamed page can get a slug matching its new title
+	var body fyne.CanvasObject = editor
+	var slugEntry *widget.Entry
+	currentSlug := pageSlug(finding.Link)
+	if finding.Issue == audit.IssueDuplicateTitle {
+		slugEntry = widget.NewEntry()
+		slugEntry.SetText(currentSlug)
+		slugStatus := widget.NewLabel(i18n.T("Keep the slug, or suggest one matching the new title."))
+		slugStatus.Wrapping = fyne.TextWrapWord
+		suggestions := widget.NewSelect(nil, func(slug string) { slugEntry.SetText(slug) })
+		suggestions.PlaceHolder = i18n.T("Suggestions")
+		suggestButton := widget.NewButton(i18n.T("Suggest Slug"), func() {
+			v.suggestSlugs(finding.PageID, editor.Text, slugEntry, suggestions, slugStatus)
+		})
+		body = container.NewVBox(editor,
+			newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Slug:")), container.NewHBox(suggestions, suggestButton), slugEntry),
+			slugStatus)
+	}
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.Tf("Fix: %s", finding.Title), newReadingOrderBorder(label, nil, nil, nil, body), v.window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Cancel"), func() { d.Hide() }),
+		widget.NewButton(i18n.T("Apply"), func() {
+			value := strings.TrimSpace(editor.Text)
+			if value == "" {
+				ShowError(fmt.Errorf("the fix is empty"), v.window)
+				return
+			}
+			slug := ""
+			if slugEntry != nil && strings.TrimSpace(slugEntry.Text) != currentSlug {
+				if slug = seo.Slug(slugEntry.Text); slug == "" {
+					ShowError(fmt.Errorf("the slug needs letters or digits"), v.window)
+					return
+				}
+			}
+			d.Hide()
+			saveIfApproved(v.drafts, v.window, value, fmt.Sprintf("%s: %s", finding.Issue, finding.Title), func() { v.apply(finding, value, slug) })
+		}),
+	})
+	if editor.MultiLine {
+		d.Resize(fyne.NewSize(720, 560))
+	} else if slugEntry != nil {
+		d.Resize(fyne.NewSize(620, 340))
+	} else {
+		d.Resize(fyne.NewSize(560, 200))
+	}
+	d.Show()
+}
+
+// apply writes a reviewed fix to the page and drops the finding. A renamed
+// page's slug is changed too unless slug is empty.
+func (v *SEOAuditView) apply(finding audit.SEOFinding, value, slug string) {
+	write := func() error {
+		switch finding.Issue {
+		case audit.IssueMissingDescription:
+			return v.wpService.UpdatePageExcerpt(finding.PageID, value)
+		case audit.IssueDuplicateTitle:
+			if err := v.wpService.UpdatePageTitle(finding.PageID, value); err != nil || slug == "" {
+				return err
+			}
+			return v.wpService.UpdatePageSlug(finding.PageID, slug)
+		case audit.IssueThinContent:
+			return v.wpService.UpdatePageContent(finding.PageID, value)
+		}
+		content, err := v.wpService.GetPageContent(finding.PageID)
+		if err != nil {
+			return err
+		}
+		if finding.Issue == audit.IssueMissingH1 {
+			return v.wpService.UpdatePageContent(finding.PageID, audit.InsertH1(content, value))
+		}
+		return v.wpService.UpdatePageContent(finding.PageID, audit.SetImageAlts(content, parseAltText(value)))
+	}
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		err := write()
+		queued := errors.Is(err, wordpress.ErrQueuedOffline)
+		if queued {
+			err = jobs.Deferred(err) // Saved, and reported, by the replay once back online
+		}
+		runOnUI(func() {
+			if queued {
+				showQueuedOffline(v.window)
+				v.removeFinding(finding)
+				return
+			}
+			if err != nil {
+				ShowError(fmt.Errorf("failed to apply the fix to '%s': %w", finding.Title, err), v.window)
+				return
+			}
+			v.removeFinding(finding)
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("SEOAuditView.apply", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Page Update", fmt.Sprintf("%s: %s", finding.Issue, finding.Title), run)
+}
+
+// suggestSlugs checks the slug being typed and the slugs suggested for title
+// against the site's pages and posts, and offers the free ones.
+func (v *SEOAuditView) suggestSlugs(pageID int, title string, slugEntry *widget.Entry, suggestions *widget.Select, status *widget.Label) {
+	typed := seo.Slug(slugEntry.Text)
+	candidates := seo.SuggestSlugs(title, nil)
+	if len(candidates) == 0 {
+		ShowError(fmt.Errorf("the title needs letters or digits"), v.window)
+		return
+	}
+	// Enough numbered variants are checked to replace the plain ones if taken
+	base := candidates[0]
+	check := candidates
+	if typed != "" {
+		check = append(check, typed)
+	}
+	for n := 2; n < 2+len(candidates); n++ {
+		check = append(check, fmt.Sprintf("%s-%d", base, n))
+	}
+	status.SetText(i18n.T("Checking the site's slugs..."))
+	crash.Go("SEOAuditView.suggestSlugs", func() {
+		used, err := v.wpService.SlugsInUse(check, pageID)
+		runOnUI(func() {
+			if err != nil {
+				status.SetText("")
+				ShowError(fmt.Errorf("failed to check slugs: %w", err), v.window)
+				return
+			}
+			taken := map[string]bool{}
+			for slug := range used {
+				taken[slug] = true
+			}
+			free := seo.SuggestSlugs(title, taken)
+			suggestions.Options = free
+			suggestions.ClearSelected()
+			if typed == "" {
+				slugEntry.SetText(free[0])
+				status.SetText(i18n.T("The suggested slugs are free."))
+				return
+			}
+			if id, ok := used[typed]; ok {
+				status.SetText(i18n.Tf("'%s' is already used by page or post #%d; pick a suggestion.", typed, id))
+				slugEntry.SetText(free[0])
+				return
+			}
+			status.SetText(i18n.Tf("'%s' is free.", typed))
+		})
+	})
+}
+
+// pageSlug returns the last path segment of a page's link, its slug for
+// pretty permalinks.
+func pageSlug(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return parts[len(parts)-1]
+}
+
+// removeFinding drops a fixed finding from the report.
+func (v *SEOAuditView) removeFinding(fixed audit.SEOFinding) {
+	for i, finding := range v.findings {
+		if finding.PageID == fixed.PageID && finding.Issue == fixed.Issue {
+			v.findings = append(v.findings[:i], v.findings[i+1:]...)
+			break
+		}
+	}
+	v.applyFilter()
+}
+
+// formatAltText lists alt text for review, one "URL = alt text" per line, in
+// the order the images appear.
+func formatAltText(images []string, alts map[string]string) string {
+	lines := make([]string, 0, len(images))
+	for _, src := range images {
+		lines = append(lines, src+" = "+alts[src])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseAltText reads back alt text edited in the format of formatAltText.
+func parseAltText(text string) map[string]string {
+	alts := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		if src, alt, ok := strings.Cut(line, " = "); ok {
+			alts[strings.TrimSpace(src)] = strings.TrimSpace(alt)
+		}
+	}
+	return alts
+}
+
+// Container returns the container for the view
+func (v *SEOAuditView) Container() fyne.CanvasObject {
+	return v.container
+}
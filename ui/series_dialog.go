@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/repurpose"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// splitIntoSeries asks the model in the background how the selected page
+// splits into a series and shows the plan for review. Pages too large for
+// the editor are fetched in full.
+func (v *ContentManagerView) splitIntoSeries() {
+	selected := v.GetPageByID(v.selectedPageID)
+	if selected == nil {
+		ShowError(fmt.Errorf("no page selected"), v.window)
+		return
+	}
+	page := *selected
+	content := ""
+	if !v.contentTruncated {
+		content = v.contentEditor.Text
+	}
+
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		if content == "" {
+			report(0, i18n.T("Loading page content"))
+			full, err := v.wpService.GetPageContent(page.ID)
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to load page content: %w", err), v.window) })
+				return err
+			}
+			content = full
+		}
+		report(-1, i18n.T("Planning the series"))
+		output, err := v.inferenceService.Generate(inference.GetSeriesPlanPrompt(content, repurpose.MaxSeriesParts), generateOptions(ctx, "", inference.TaskStructured))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var plan repurpose.Series
+		if err == nil {
+			plan, err = repurpose.ParseSeriesPlan(output)
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to plan the series: %w", err), v.window) })
+			return err
+		}
+		runOnUI(func() { v.showSeriesPlan(page, content, plan) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentManagerView.splitIntoSeries", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Series Plan", page.Title, run)
+}
+
+// showSeriesPlan shows the proposed parts with their titles editable, and
+// writes them on confirmation.
+func (v *ContentManagerView) showSeriesPlan(page wordpress.Page, content string, plan repurpose.Series) {
+	seriesTitle := widget.NewEntry()
+	seriesTitle.SetText(plan.Title)
+	items := []*widget.FormItem{widget.NewFormItem(i18n.T("Series:"), seriesTitle)}
+	titles := make([]*widget.Entry, len(plan.Parts))
+	for i, part := range plan.Parts {
+		titles[i] = widget.NewEntry()
+		titles[i].SetText(part.Title)
+		summary := widget.NewLabel(part.Summary)
+		summary.Wrapping = fyne.TextWrapWord
+		summary.Importance = widget.LowImportance
+		items = append(items, widget.NewFormItem(i18n.Tf("Part %d:", i+1), container.NewVBox(titles[i], summary)))
+	}
+
+	d := dialog.NewForm(i18n.T("Split into Series"), i18n.T("Write Parts"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		plan.Title = strings.TrimSpace(seriesTitle.Text)
+		for i := range plan.Parts {
+			if title := strings.TrimSpace(titles[i].Text); title != "" {
+				plan.Parts[i].Title = title
+			}
+		}
+		v.writeSeries(page, content, plan)
+	}, v.window)
+	d.Resize(fyne.NewSize(620, 560))
+	d.Show()
+}
+
+// writeSeries writes each part of plan in the background, links the parts
+// to each other and keeps each as a draft in the generation history.
+func (v *ContentManagerView) writeSeries(page wordpress.Page, content string, plan repurpose.Series) {
+	outline := plan.Outline()
+	source := SourceContent{Title: page.Title, Content: content, Source: "WordPress", ID: page.ID, Link: page.Link}
+
+	run := func(ctx context.Context, report jobs.ProgressFunc) error {
+		parts := make([]string, len(plan.Parts))
+		for i := range plan.Parts {
+			report(float64(i)/float64(len(plan.Parts)), i18n.Tf("Writing part %d of %d", i+1, len(plan.Parts)))
+			output, err := v.inferenceService.Generate(inference.GetSeriesPartPrompt(i+1, len(plan.Parts), outline, content), contentOptions(ctx, ""))
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				runOnUI(func() { ShowError(fmt.Errorf("failed to write part %d: %w", i+1, err), v.window) })
+				return err
+			}
+			parts[i] = plan.WithNavigation(i, strings.TrimSpace(output))
+			if v.contentGeneratorView != nil {
+				v.contentGeneratorView.saveDraft([]SourceContent{source}, seriesPartRequest(plan, i), "", "", parts[i])
+			}
+		}
+		report(1, i18n.T("Done"))
+		runOnUI(func() { v.showSeries(plan, parts) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentManagerView.writeSeries", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Series", i18n.Tf("%s (%d parts)", page.Title, len(plan.Parts)), run)
+}
+
+// seriesPartRequest describes part i of a series, as the request it was
+// written for.
+func seriesPartRequest(plan repurpose.Series, i int) string {
+	return fmt.Sprintf("Part %d of %d of the series '%s': %s", i+1, len(plan.Parts), plan.Title, plan.Parts[i].Title)
+}
+
+// showSeries lists the written parts, each of which can be opened in the
+// generator to be checked and published.
+func (v *ContentManagerView) showSeries(plan repurpose.Series, parts []string) {
+	list := container.NewVBox()
+	for i, part := range plan.Parts {
+		text := parts[i]
+		row := container.NewHBox(widget.NewLabel(fmt.Sprintf("%d. %s", i+1, part.Title)))
+		row.Add(newCopyButton(v.window, i18n.T("Copy"), func() string { return text }))
+		if v.contentGeneratorView != nil {
+			request := seriesPartRequest(plan, i)
+			row.Add(widget.NewButton(i18n.T("Open in Generator"), func() { v.contentGeneratorView.OpenResult(request, text) }))
+		}
+		list.Add(row)
+	}
+	note := widget.NewLabel(i18n.T("Each part is saved in the generator's Drafts and links to the others by its title's slug, so publish every part under its title."))
+	note.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustom(i18n.T("Series"), i18n.T("Close"), newReadingOrderBorder(note, nil, nil, nil, container.NewVScroll(list)), v.window)
+	d.Resize(fyne.NewSize(620, 420))
+	d.Show()
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"encoding/json"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+)
+
+// PrefLastSite is the saved site connected when the app last quit, which is
+// connected again at the next start; empty if none was.
+var PrefLastSite = settings.NewKey("session.site", "")
+
+// sessionSourcesDocument is the state database document keeping the
+// Generator's sources between runs.
+const sessionSourcesDocument = "session.generator_sources"
+
+// sessionSplit is a split whose offset is saved with the session.
+type sessionSplit struct {
+	key   settings.Key[float64]
+	split *container.Split
+}
+
+// sessionSplits are the splits saved by SaveLayout. They are only used on
+// the UI goroutine.
+var sessionSplits []sessionSplit
+
+// rememberSplit restores a split's offset from the last session and saves it
+// with the layout; the offset it has now is the default.
+func rememberSplit(name string, split *container.Split) {
+	key := settings.NewKey("layout."+name+"_split", split.Offset)
+	split.SetOffset(key.Get())
+	sessionSplits = append(sessionSplits, sessionSplit{key: key, split: split})
+}
+
+// SaveLayout saves the window size and the split offsets, to restore them at
+// the next start. Call it on the UI goroutine before the window closes.
+func SaveLayout(w fyne.Window) {
+	if size := w.Canvas().Size(); size.Width > 0 && size.Height > 0 {
+		PrefWindowWidth.Set(float64(size.Width))
+		PrefWindowHeight.Set(float64(size.Height))
+	}
+	for _, s := range sessionSplits {
+		s.key.Set(s.split.Offset)
+	}
+}
+
+// SaveSources keeps the loaded sources, with their Sample flags and ticks,
+// for the next start. Without the state database they are not kept.
+func (v *ContentGeneratorView) SaveSources() {
+	if promptStore == nil {
+		return
+	}
+	data, err := json.Marshal(v.sourceContents)
+	if err == nil {
+		err = promptStore.SetDocument(sessionSourcesDocument, string(data))
+	}
+	if err != nil {
+		logger.Error("ContentGeneratorView: failed to save the sources", "error", err)
+	}
+}
+
+// RestoreSources loads the sources kept by SaveSources.
+func (v *ContentGeneratorView) RestoreSources() {
+	if promptStore == nil {
+		return
+	}
+	text, ok, err := promptStore.Document(sessionSourcesDocument)
+	if err == nil && ok {
+		var sources []SourceContent
+		if err = json.Unmarshal([]byte(text), &sources); err == nil {
+			v.sourceContents = sources
+			v.sourceList.Refresh()
+			v.updateSourceActions()
+			logger.Info("ContentGeneratorView: restored the sources", "sources", len(sources))
+		}
+	}
+	if err != nil {
+		logger.Error("ContentGeneratorView: failed to restore the sources", "error", err)
+	}
+}
+
+// Resume connects again to the saved site connected when the app last quit.
+// A site that is no longer saved is skipped.
+func (s *SiteSwitcher) Resume() {
+	name := PrefLastSite.Get()
+	if name == "" || s.wpService.IsConnected() {
+		return
+	}
+	if _, found := s.wpService.GetSavedSite(name); !found {
+		logger.Info("SiteSwitcher: last site is no longer saved", logging.Site(name))
+		return
+	}
+	s.switchTo(name)
+}
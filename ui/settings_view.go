@@ -1,12 +1,18 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference"
+	"Inference_Engine/logging"
+	"Inference_Engine/vault"
 	"Inference_Engine/wordpress"
 
 	"fyne.io/fyne/v2"
@@ -32,6 +38,9 @@ type WordPressSettingsView struct {
 	connectButton *widget.Button
 	statusLabel   *widget.Label
 
+	credentialsForm *fyne.Container // The credential fields, hidden in the editor profile
+	credentialsNote *widget.Label   // Shown instead of them
+
 	// Saved sites UI elements
 	savedSitesList   *widget.List
 	loadSiteButton   *widget.Button
@@ -43,6 +52,8 @@ type WordPressSettingsView struct {
 
 	// Callback for when connection status changes
 	onConnectionChanged func(connected bool)
+	// Callback for when the saved sites list changes (e.g. for the site switcher)
+	onSavedSitesChanged func()
 }
 
 // NewWordPressSettingsView creates a new WordPress settings view
@@ -64,22 +75,22 @@ func NewWordPressSettingsView(wpService *wordpress.WordPressService, window fyne
 func (v *WordPressSettingsView) initialize() {
 	// Create connection UI elements
 	v.siteNameEntry = widget.NewEntry()
-	v.siteNameEntry.SetPlaceHolder("Site Name (for saving)")
+	v.siteNameEntry.SetPlaceHolder(i18n.T("Site Name (for saving)"))
 
 	v.siteURLEntry = widget.NewEntry()
-	v.siteURLEntry.SetPlaceHolder("WordPress Site URL (e.g., https://example.com/)")
+	v.siteURLEntry.SetPlaceHolder(i18n.T("WordPress Site URL (e.g., https://example.com/)"))
 
 	v.usernameEntry = widget.NewEntry()
-	v.usernameEntry.SetPlaceHolder("Username")
+	v.usernameEntry.SetPlaceHolder(i18n.T("Username"))
 
 	v.passwordEntry = widget.NewPasswordEntry()
-	v.passwordEntry.SetPlaceHolder("Application Password")
+	v.passwordEntry.SetPlaceHolder(i18n.T("Application Password"))
 
-	v.rememberCheck = widget.NewCheck("Remember Me", nil)
+	v.rememberCheck = widget.NewCheck(i18n.T("Remember Me"), nil)
 
-	v.connectButton = widget.NewButton("Connect", nil) // Action set later by updateConnectButtonState
+	v.connectButton = widget.NewButton(i18n.T("Connect"), nil) // Action set later by updateConnectButtonState
 
-	v.statusLabel = widget.NewLabel("Status: Disconnected")
+	v.statusLabel = widget.NewLabel(i18n.T("Status: Disconnected"))
 
 	// Create saved sites UI elements
 	v.savedSitesList = widget.NewList(
@@ -102,33 +113,41 @@ func (v *WordPressSettingsView) initialize() {
 		v.deleteSiteButton.Enable()
 	}
 
-	v.loadSiteButton = widget.NewButton("Load Site", func() {
+	v.loadSiteButton = widget.NewButton(i18n.T("Load Site"), func() {
 		v.loadSavedSite()
 	})
 	v.loadSiteButton.Disable()
 
-	v.deleteSiteButton = widget.NewButton("Delete Site", func() {
+	v.deleteSiteButton = widget.NewButton(i18n.T("Delete Site"), func() {
 		v.deleteSavedSite()
 	})
 	v.deleteSiteButton.Disable()
 
+	v.credentialsNote = widget.NewLabel(i18n.T("Site credentials are managed by the admin profile. Use the site switcher to connect to a saved site."))
+	v.credentialsNote.Wrapping = fyne.TextWrapWord
+	v.credentialsNote.Hide()
+
 	// Create layout
-	connectionForm := container.NewVBox(
-		widget.NewLabel("WordPress Connection"),
-		widget.NewLabel("Site Name:"),
+	v.credentialsForm = container.NewVBox(
+		widget.NewLabel(i18n.T("Site Name:")),
 		v.siteNameEntry,
-		widget.NewLabel("Site URL:"),
+		widget.NewLabel(i18n.T("Site URL:")),
 		v.siteURLEntry,
-		widget.NewLabel("Username:"),
+		widget.NewLabel(i18n.T("Username:")),
 		v.usernameEntry,
-		widget.NewLabel("Application Password:"),
+		widget.NewLabel(i18n.T("Application Password:")),
 		v.passwordEntry,
 		v.rememberCheck,
+	)
+	connectionForm := container.NewVBox(
+		widget.NewLabel(i18n.T("WordPress Connection")),
+		v.credentialsForm,
+		v.credentialsNote,
 		v.connectButton,
 		v.statusLabel,
 	)
 
-	savedSitesContent := container.NewBorder(
+	savedSitesContent := newReadingOrderBorder(
 		nil, // Top
 		// Buttons go at the bottom of this inner border layout
 		container.NewHBox(layout.NewSpacer(), v.loadSiteButton, v.deleteSiteButton),
@@ -137,8 +156,8 @@ func (v *WordPressSettingsView) initialize() {
 		v.savedSitesList, // List goes in the center
 	)
 
-	savedSitesContainer := container.NewBorder(
-		widget.NewLabel("Saved Sites"),         // Top
+	savedSitesContainer := newReadingOrderBorder(
+		widget.NewLabel(i18n.T("Saved Sites")),         // Top
 		nil,                                    // Bottom
 		nil,                                    // Left
 		nil,                                    // Right
@@ -146,7 +165,7 @@ func (v *WordPressSettingsView) initialize() {
 	)
 
 	// Main layout
-	v.container = container.NewBorder(
+	v.container = newReadingOrderBorder(
 		container.NewVBox(connectionForm, widget.NewSeparator()), // Top
 		nil,                 // Bottom
 		nil,                 // Left
@@ -160,6 +179,7 @@ type InferenceSettingsView struct {
 	container        *fyne.Container // Keep this unexported
 	inferenceService *inference.InferenceService
 	window           fyne.Window
+	vault            *vault.Vault // Keeps the API keys when set; nil leaves them in the environment only
 
 	// UI elements
 	cerebrasKeyEntry *widget.Entry
@@ -168,10 +188,16 @@ type InferenceSettingsView struct {
 	// Removed modelEntry, replaced with display labels
 	primaryModelsLabel   *widget.Label
 	fallbackModelsLabel *widget.Label
+	providerModelsLabel *widget.Label // Every registered model per provider, with its status
 
 	// --- ADDED: MOA Default Model Settings ---
 	moaPrimaryModelSelect   *widget.Select // Changed from Entry to Select
 	moaFallbackModelSelect *widget.Select // Changed from Entry to Select
+
+	switchModelSelect *widget.Select // Configured model to replace
+	newModelEntry     *widget.Entry  // Model from the same provider to switch to
+
+	localServersLabel *widget.Label // Models, memory and load status of the local servers
 }
 
 // NewInferenceSettingsView creates a new inference settings view
@@ -181,6 +207,12 @@ func NewInferenceSettingsView(inferenceService *inference.InferenceService, wind
 		window:           window,
 	}
 	view.initialize()
+	inferenceService.OnModelsChange(func() {
+		runOnUI(func() {
+			view.refreshDisplayedModels()
+			view.refreshLocalServers()
+		})
+	})
 	return view
 }
 
@@ -190,18 +222,19 @@ func (v *InferenceSettingsView) initialize() {
 
 	// API Key Inputs
 	v.cerebrasKeyEntry = widget.NewPasswordEntry()
-	v.cerebrasKeyEntry.SetPlaceHolder("Cerebras API Key (loaded from CEREBRAS_API_KEY)")
+	v.cerebrasKeyEntry.SetPlaceHolder(i18n.T("Cerebras API Key (loaded from CEREBRAS_API_KEY)"))
 	if key := os.Getenv("CEREBRAS_API_KEY"); key != "" {
 		v.cerebrasKeyEntry.SetText(key)
 	}
-	saveCerebrasButton := widget.NewButton("Set Cerebras Key Env Var", func() {
+	saveCerebrasButton := widget.NewButton(i18n.T("Set Cerebras Key Env Var"), func() {
 		key := v.cerebrasKeyEntry.Text
 		if key != "" {
 			os.Setenv("CEREBRAS_API_KEY", key)
-			dialog.ShowInformation("Restart Required", "Cerebras API key environment variable set.\nPlease restart the application.", v.window)
+			v.storeKey("CEREBRAS_API_KEY", key)
+			dialog.ShowInformation(i18n.T("Restart Required"), i18n.T("Cerebras API key environment variable set.\nPlease restart the application."), v.window)
 			v.cerebrasKeyEntry.Disable()
 		} else {
-			dialog.ShowInformation("Input Required", "Please enter the Cerebras API Key.", v.window)
+			dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Please enter the Cerebras API Key."), v.window)
 		}
 	})
 	v.cerebrasKeyEntry.OnChanged = func(_ string) {
@@ -210,18 +243,19 @@ func (v *InferenceSettingsView) initialize() {
 
 	// --- Add Gemini Key Input ---
 	v.geminiKeyEntry = widget.NewPasswordEntry() // Use v.geminiKeyEntry
-	v.geminiKeyEntry.SetPlaceHolder("Gemini API Key (loaded from GEMINI_API_KEY)")
+	v.geminiKeyEntry.SetPlaceHolder(i18n.T("Gemini API Key (loaded from GEMINI_API_KEY)"))
 	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
 		v.geminiKeyEntry.SetText(key)
 	}
-	saveGeminiButton := widget.NewButton("Set Gemini Key Env Var", func() {
+	saveGeminiButton := widget.NewButton(i18n.T("Set Gemini Key Env Var"), func() {
 		key := v.geminiKeyEntry.Text
 		if key != "" {
 			os.Setenv("GEMINI_API_KEY", key)
-			dialog.ShowInformation("Restart Required", "Gemini API key environment variable set.\nPlease restart the application.", v.window)
+			v.storeKey("GEMINI_API_KEY", key)
+			dialog.ShowInformation(i18n.T("Restart Required"), i18n.T("Gemini API key environment variable set.\nPlease restart the application."), v.window)
 			v.geminiKeyEntry.Disable()
 		} else {
-			dialog.ShowInformation("Input Required", "Please enter the Gemini API Key.", v.window)
+			dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Please enter the Gemini API Key."), v.window)
 		}
 	})
 	v.geminiKeyEntry.OnChanged = func(_ string) {
@@ -230,18 +264,19 @@ func (v *InferenceSettingsView) initialize() {
 
 	// --- ADDED: Deepseek Key Input ---
 	v.deepseekKeyEntry = widget.NewPasswordEntry()
-	v.deepseekKeyEntry.SetPlaceHolder("Deepseek API Key (loaded from DEEPSEEK_API_KEY)")
+	v.deepseekKeyEntry.SetPlaceHolder(i18n.T("Deepseek API Key (loaded from DEEPSEEK_API_KEY)"))
 	if key := os.Getenv("DEEPSEEK_API_KEY"); key != "" {
 		v.deepseekKeyEntry.SetText(key)
 	}
-	saveDeepseekButton := widget.NewButton("Set Deepseek Key Env Var", func() {
+	saveDeepseekButton := widget.NewButton(i18n.T("Set Deepseek Key Env Var"), func() {
 		key := v.deepseekKeyEntry.Text
 		if key != "" {
 			os.Setenv("DEEPSEEK_API_KEY", key)
-			dialog.ShowInformation("Restart Required", "Deepseek API key environment variable set.\nPlease restart the application.", v.window)
+			v.storeKey("DEEPSEEK_API_KEY", key)
+			dialog.ShowInformation(i18n.T("Restart Required"), i18n.T("Deepseek API key environment variable set.\nPlease restart the application."), v.window)
 			v.deepseekKeyEntry.Disable()
 		} else {
-			dialog.ShowInformation("Input Required", "Please enter the Deepseek API Key.", v.window)
+			dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Please enter the Deepseek API Key."), v.window)
 		}
 	})
 	v.deepseekKeyEntry.OnChanged = func(_ string) {
@@ -249,65 +284,83 @@ func (v *InferenceSettingsView) initialize() {
 	}
 	// --- End ADDED ---
 	// --- Display Configured Models ---
-	v.primaryModelsLabel = widget.NewLabel("Primary Models: Loading...")
-	v.fallbackModelsLabel = widget.NewLabel("Fallback Models: Loading...")
+	v.primaryModelsLabel = widget.NewLabel(i18n.T("Primary Models: Loading..."))
+	v.fallbackModelsLabel = widget.NewLabel(i18n.T("Fallback Models: Loading..."))
+	v.providerModelsLabel = widget.NewLabel("")
+	v.providerModelsLabel.Wrapping = fyne.TextWrapWord
 
 	// Refresh button to update displayed models (in case service restarts or config changes)
-	refreshModelsButton := widget.NewButtonWithIcon("Refresh Models", theme.ViewRefreshIcon(), func() {
+	refreshModelsButton := widget.NewButtonWithIcon(i18n.T("Refresh Models"), theme.ViewRefreshIcon(), func() {
 		v.refreshDisplayedModels()
 	})
 
 	// --- ADDED: MOA Default Model Settings ---
-	moaSettingsLabel := widget.NewLabel("MOA Default Models (Affects Mixture-of-Agents):")
+	moaSettingsLabel := widget.NewLabel(i18n.T("MOA Default Models (Affects Mixture-of-Agents):"))
 
 	// Create Select widgets, initially empty, will be populated by refreshDisplayedModels
 	v.moaPrimaryModelSelect = widget.NewSelect([]string{}, func(selected string) {
 		// Optional: Handle selection change directly if needed, otherwise button press is fine
-		log.Printf("UI: MOA Primary dropdown selected: %s", selected)
+		logger.Info("MOA primary dropdown selected", logging.Model(selected))
 	})
 
-	setMOAPrimaryButton := widget.NewButton("Set MOA Primary", func() {
+	setMOAPrimaryButton := widget.NewButton(i18n.T("Set MOA Primary"), func() {
 		model := v.moaPrimaryModelSelect.Selected // Get value from Select
 		if model == "" {
-			dialog.ShowInformation("Input Required", "Please enter a model name.", v.window)
+			dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Please enter a model name."), v.window)
 			return
 		}
 		err := v.inferenceService.SetMOAPrimaryModel(model)
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Failed to set MOA primary model: %w", err), v.window)
+			ShowError(fmt.Errorf("Failed to set MOA primary model: %w", err), v.window)
 		} else {
-			dialog.ShowInformation("Success", fmt.Sprintf("MOA primary default set to '%s'. MOA reconfigured.", model), v.window)
+			dialog.ShowInformation(i18n.T("Success"), i18n.Tf("MOA primary default set to '%s'. MOA reconfigured.", model), v.window)
 		}
 	})
 
 	v.moaFallbackModelSelect = widget.NewSelect([]string{}, func(selected string) {
 		// Optional: Handle selection change directly if needed
-		log.Printf("UI: MOA Fallback dropdown selected: %s", selected)
+		logger.Info("MOA fallback dropdown selected", logging.Model(selected))
 	})
 
-	setMOAFallbackButton := widget.NewButton("Set MOA Fallback", func() {
+	setMOAFallbackButton := widget.NewButton(i18n.T("Set MOA Fallback"), func() {
 		// Similar logic to setMOAPrimaryButton, calling SetMOAFallbackModel
 		model := v.moaFallbackModelSelect.Selected // Get value from Select
 		// ... (validation) ...
 		err := v.inferenceService.SetMOAFallbackModel(model)
 		// ... (handle error/success dialog) ...
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Failed to set MOA fallback model: %w", err), v.window)
+			ShowError(fmt.Errorf("Failed to set MOA fallback model: %w", err), v.window)
 		} else {
-			dialog.ShowInformation("Success", fmt.Sprintf("MOA fallback/aggregator default set to '%s'. MOA reconfigured.", model), v.window)
+			dialog.ShowInformation(i18n.T("Success"), i18n.Tf("MOA fallback/aggregator default set to '%s'. MOA reconfigured.", model), v.window)
 		}
 	})
 	// --- End ADDED ---
+
+	// Models on this machine are loaded at startup; pings keep them loaded
+	keepAliveCheck := widget.NewCheck(i18n.T("Keep local models loaded (ping every 4 minutes)"), inference.PrefLocalKeepAlive.Set)
+	keepAliveCheck.SetChecked(inference.PrefLocalKeepAlive.Get())
+	v.localServersLabel = widget.NewLabel("")
+	v.localServersLabel.Wrapping = fyne.TextWrapWord
+	refreshLocalButton := widget.NewButton(i18n.T("Check Local Servers"), v.refreshLocalServers)
+
+	// Switch one configured model without restarting the service
+	v.switchModelSelect = widget.NewSelect([]string{}, nil)
+	v.newModelEntry = widget.NewEntry()
+	v.newModelEntry.SetPlaceHolder(i18n.T("New model name from the same provider"))
+	switchModelButton := widget.NewButton(i18n.T("Switch Model"), v.switchModel)
+
 	// Create layout
 	v.container = container.NewVBox(
-		widget.NewLabel("Inference Settings"),
+		widget.NewLabel(i18n.T("Inference Settings")),
 		widget.NewSeparator(),
-		widget.NewLabel("Configured Models (Read-Only):"),
+		widget.NewLabel(i18n.T("Configured Models (Read-Only):")),
 		v.primaryModelsLabel,
 		v.fallbackModelsLabel,
+		widget.NewLabel(i18n.T("Models by Provider:")),
+		v.providerModelsLabel,
 		refreshModelsButton,
 		widget.NewSeparator(),
-		widget.NewLabel("API Keys (Set Environment Variable & Restart):"),
+		widget.NewLabel(i18n.T("API Keys (Set Environment Variable & Restart):")),
 		v.cerebrasKeyEntry,
 		saveCerebrasButton,
 		v.geminiKeyEntry, // Add Gemini key entry
@@ -315,15 +368,26 @@ func (v *InferenceSettingsView) initialize() {
 		v.deepseekKeyEntry, // ADDED: Deepseek key entry
 		saveDeepseekButton, // ADDED: Deepseek save button
 		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Local Servers:")),
+		v.localServersLabel,
+		refreshLocalButton,
+		keepAliveCheck,
 		moaSettingsLabel,
 		v.moaPrimaryModelSelect, // Use Select widget
 		setMOAPrimaryButton,
 		v.moaFallbackModelSelect, // Use Select widget
 		setMOAFallbackButton,
+		v.moaOptionsForm(),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Switch Model (validated with a test request first):")),
+		v.switchModelSelect,
+		v.newModelEntry,
+		switchModelButton,
 	)
 
 	// Initial refresh of displayed models
 	v.refreshDisplayedModels()
+	v.refreshLocalServers()
 }
 
 // refreshDisplayedModels updates the labels showing the configured models.
@@ -334,8 +398,9 @@ func (v *InferenceSettingsView) refreshDisplayedModels() {
 	fallbackModels := v.inferenceService.GetFallbackModels()
 	currentFallback := v.inferenceService.GetBaseModel() // Get current MOA fallback
 
-	v.primaryModelsLabel.SetText(fmt.Sprintf("Primary Models: %v", primaryModels))
-	v.fallbackModelsLabel.SetText(fmt.Sprintf("Fallback Models: %v", fallbackModels))
+	v.primaryModelsLabel.SetText(i18n.Tf("Primary Models: %v", primaryModels))
+	v.fallbackModelsLabel.SetText(i18n.Tf("Fallback Models: %v", fallbackModels))
+	v.providerModelsLabel.SetText(describeProviderModels(v.inferenceService))
 
 	// Update options and selected value for MOA dropdowns
 	v.moaPrimaryModelSelect.Options = primaryModels
@@ -343,6 +408,94 @@ func (v *InferenceSettingsView) refreshDisplayedModels() {
 
 	v.moaFallbackModelSelect.Options = fallbackModels
 	v.moaFallbackModelSelect.SetSelected(currentFallback) // Set current selection
+
+	var configured []string
+	for _, model := range v.inferenceService.Models() {
+		if model.Available {
+			configured = append(configured, model.ID())
+		}
+	}
+	v.switchModelSelect.Options = configured
+	v.switchModelSelect.Refresh()
+}
+
+// switchModel replaces the selected model with the one typed in, after the
+// service has validated it. The views refresh through OnModelsChange.
+func (v *InferenceSettingsView) switchModel() {
+	ref := v.switchModelSelect.Selected
+	newModel := strings.TrimSpace(v.newModelEntry.Text)
+	if ref == "" || newModel == "" {
+		dialog.ShowInformation(i18n.T("Input Required"), i18n.T("Choose a model to replace and enter the new model name."), v.window)
+		return
+	}
+	progress := dialog.NewProgressInfinite(i18n.T("Switching Model"), i18n.Tf("Validating %s...", newModel), v.window)
+	progress.Show()
+	go func() {
+		defer crash.Recover("InferenceSettingsView.switchModel")
+		err := v.inferenceService.SwitchModel(context.Background(), ref, newModel)
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				ShowError(fmt.Errorf("failed to switch %s to %s: %w", ref, newModel, err), v.window)
+				return
+			}
+			v.newModelEntry.SetText("")
+			dialog.ShowInformation(i18n.T("Success"), i18n.Tf("Now using '%s' instead of '%s'.", newModel, ref), v.window)
+		})
+	}()
+}
+
+// describeProviderModels lists every registered model under its provider,
+// marking the role and why unavailable models can't be used.
+func describeProviderModels(service *inference.InferenceService) string {
+	var lines []string
+	for _, provider := range service.Providers() {
+		lines = append(lines, provider+":")
+		for _, model := range service.ModelsForProvider(provider) {
+			role := i18n.T("fallback")
+			if model.Primary {
+				role = i18n.T("primary")
+			}
+			status := role
+			if !model.Available {
+				status = i18n.Tf("%s, unavailable: %s", role, model.Problem)
+			}
+			lines = append(lines, fmt.Sprintf("    %s (%s)", model.Model, status))
+		}
+	}
+	if len(lines) == 0 {
+		return i18n.T("No models registered. Start the inference service to load them.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetVault keeps the API keys set from now on in vault.
+func (v *InferenceSettingsView) SetVault(vault *vault.Vault) {
+	v.vault = vault
+}
+
+// ReloadKeys fills the empty key fields from the environment, after the
+// vault has set the keys it holds.
+func (v *InferenceSettingsView) ReloadKeys() {
+	for env, entry := range map[string]*widget.Entry{
+		"CEREBRAS_API_KEY": v.cerebrasKeyEntry,
+		"GEMINI_API_KEY":   v.geminiKeyEntry,
+		"DEEPSEEK_API_KEY": v.deepseekKeyEntry,
+	} {
+		if key := os.Getenv(env); key != "" && entry.Text == "" {
+			entry.SetText(key)
+		}
+	}
+}
+
+// storeKey saves an API key in the vault, if there is an unlocked one.
+func (v *InferenceSettingsView) storeKey(env, key string) {
+	if v.vault == nil || v.vault.Locked() {
+		return
+	}
+	if err := v.vault.Set(env, key); err != nil {
+		ShowError(fmt.Errorf("the key was not saved in the vault: %w", err), v.window)
+	}
 }
 
 // Container returns the container for the Inference Settings view
@@ -359,8 +512,8 @@ func (v *WordPressSettingsView) Container() fyne.CanvasObject {
 // updateConnectButtonState updates the connect button's text and action
 func (v *WordPressSettingsView) updateConnectButtonState() {
 	if v.wpService == nil {
-		log.Println("WordPressSettingsView: Cannot update button state, wpService is nil")
-		v.connectButton.SetText("Connect")
+		logger.Warn("WordPressSettingsView: cannot update button state, wpService is nil")
+		v.connectButton.SetText(i18n.T("Connect"))
 		v.connectButton.OnTapped = nil // Or set to a function showing an error
 		v.connectButton.Disable()      // Disable if service is missing
 		return
@@ -369,40 +522,41 @@ func (v *WordPressSettingsView) updateConnectButtonState() {
 	v.connectButton.Enable() // Ensure button is enabled unless explicitly disabled elsewhere
 
 	if v.wpService.IsConnected() {
-		v.connectButton.SetText("Disconnect")
+		v.connectButton.SetText(i18n.T("Disconnect"))
 		v.connectButton.OnTapped = func() {
-			log.Println("Disconnect button tapped. Starting disconnect goroutine.")
+			logger.Debug("Disconnect button tapped. Starting disconnect goroutine")
 			// Disable button immediately to prevent double clicks
 			v.connectButton.Disable()
-			v.connectButton.SetText("Disconnecting...")
+			v.connectButton.SetText(i18n.T("Disconnecting..."))
 			v.connectButton.Refresh()
 
 			// Perform disconnect in a goroutine
 			go func() {
-				log.Println("Disconnect goroutine: Calling v.wpService.Disconnect()...") // <-- Add log BEFORE call
+				defer crash.Recover("WordPressSettingsView.updateConnectButtonState")
+				logger.Debug("Disconnect goroutine: calling v.wpService.Disconnect()")
 				v.wpService.Disconnect()
-				log.Println("Disconnect goroutine: v.wpService.Disconnect() returned.") // <-- Add log AFTER call
-				
-				// --- Directly Update UI Elements After Disconnect ---
-				log.Println("Disconnect UI update: Setting status and button directly.")
-				v.statusLabel.SetText("Status: Disconnected")
-				v.statusLabel.Refresh()
-
-				v.connectButton.SetText("Connect")
-				v.connectButton.OnTapped = v.connectToWordPress // Reset action to connect
-				v.connectButton.Enable()                       // Ensure button is enabled
-				v.connectButton.Refresh()                      // Refresh the button's appearance
-
-				// Notify other parts of the application *after* this view's UI is updated
-				if v.onConnectionChanged != nil {
-						v.onConnectionChanged(false)
-					}
-					log.Println("Disconnect UI update: Complete.")
-			
+				logger.Debug("Disconnect goroutine: v.wpService.Disconnect() returned")
+				runOnUI(func() {
+					// --- Directly Update UI Elements After Disconnect ---
+					logger.Debug("Disconnect UI update: setting status and button directly")
+					v.statusLabel.SetText(i18n.T("Status: Disconnected"))
+					v.statusLabel.Refresh()
+
+					v.connectButton.SetText(i18n.T("Connect"))
+					v.connectButton.OnTapped = v.connectToWordPress // Reset action to connect
+					v.connectButton.Enable()                       // Ensure button is enabled
+					v.connectButton.Refresh()                      // Refresh the button's appearance
+
+					// Notify other parts of the application *after* this view's UI is updated
+					if v.onConnectionChanged != nil {
+							v.onConnectionChanged(false)
+						}
+						logger.Debug("Disconnect UI update: complete")
+				})
 			}()
 		}
 	} else {
-		v.connectButton.SetText("Connect")
+		v.connectButton.SetText(i18n.T("Connect"))
 		v.connectButton.OnTapped = func() {
 			// Call the existing connect function
 			v.connectToWordPress()
@@ -418,161 +572,168 @@ func (v *WordPressSettingsView) connectToWordPress() {
 	username := v.usernameEntry.Text
 	password := v.passwordEntry.Text
 	remember := v.rememberCheck.Checked
-	log.Printf("connectToWordPress: Initiated for URL: %s, User: %s", siteURL, username) // Log start
+	connectLog := logger.With(logging.Site(siteURL))
+	connectLog.Info("connectToWordPress: initiated", "user", username)
 
 	if siteURL == "" || username == "" || password == "" {
-		log.Println("connectToWordPress: Missing connection fields.")
-		dialog.ShowError(fmt.Errorf("please fill in all connection fields"), v.window)
+		connectLog.Warn("connectToWordPress: missing connection fields")
+		ShowError(fmt.Errorf("please fill in all connection fields"), v.window)
 		return
 	}
 
 	// --- Update Status Immediately ---
-	log.Println("connectToWordPress: Updating status to Connecting and disabling button.")
-	v.statusLabel.SetText("Status: Connecting...")
+	connectLog.Debug("connectToWordPress: updating status to Connecting and disabling button")
+	v.statusLabel.SetText(i18n.T("Status: Connecting..."))
 	v.statusLabel.Refresh()   // Ensure UI updates
 	// v.connectButton.Disable() // Don't disable, let updateConnectButtonState handle it if needed
-	v.connectButton.SetText("Connecting...") // Optionally change text during attempt
+	v.connectButton.SetText(i18n.T("Connecting...")) // Optionally change text during attempt
 	v.connectButton.Refresh()
 
 	// Show progress dialog
-	log.Println("connectToWordPress: Showing progress dialog.")
-	progress := dialog.NewProgressInfinite("Connecting", "Connecting to WordPress site...", v.window)
+	connectLog.Debug("connectToWordPress: showing progress dialog")
+	progress := dialog.NewProgressInfinite(i18n.T("Connecting"), i18n.T("Connecting to WordPress site..."), v.window)
 	progress.Show()
 
 	// Use a channel to signal completion and pass the error back
-	done := make(chan error)
-	log.Println("connectToWordPress: Created 'done' channel.")
+	done := make(chan error, 1) // Buffered so the result is never dropped
+	connectLog.Debug("connectToWordPress: created 'done' channel")
 
 	// --- Connection Goroutine ---
-	log.Println("connectToWordPress: Starting connection goroutine.")
+	connectLog.Debug("connectToWordPress: starting connection goroutine")
 	// This goroutine ONLY performs the network call.
 	go func() {
-		log.Println("connectToWordPress (goroutine): Started.")
-		log.Printf("connectToWordPress (goroutine): Calling wpService.Connect for URL: %s", siteURL)
+		defer crash.Recover("WordPressSettingsView.connectToWordPress")
+		connectLog.Debug("connectToWordPress (goroutine): started")
+		connectLog.Debug("connectToWordPress (goroutine): calling wpService.Connect")
 		// Perform the connection attempt. The service now has a timeout.
 		err := v.wpService.Connect(siteURL, username, password)
-		log.Printf("connectToWordPress (goroutine): wpService.Connect finished. Error: %v", err)
+		connectLog.Debug("connectToWordPress (goroutine): wpService.Connect finished", "error", err)
 		// Check if channel is still open before sending
 		// (Could be closed if main UI context is gone, though less likely here)
-		log.Println("connectToWordPress (goroutine): Attempting to send result to 'done' channel.")
+		connectLog.Debug("connectToWordPress (goroutine): attempting to send result to 'done' channel")
 		select {
 		case done <- err: // Send the result (nil or error) back
-			log.Println("connectToWordPress (goroutine): Successfully sent result to 'done' channel.")
+			connectLog.Debug("connectToWordPress (goroutine): successfully sent result to 'done' channel")
 		default:
 			// Channel closed or blocked, log if necessary
-			log.Println("connectToWordPress (goroutine): 'done' channel blocked or closed before sending.")
+			connectLog.Debug("connectToWordPress (goroutine): 'done' channel blocked or closed before sending")
 		}
-		log.Println("connectToWordPress (goroutine): Closing 'done' channel.")
+		connectLog.Debug("connectToWordPress (goroutine): closing 'done' channel")
 		close(done) // Close channel once done
-		log.Println("connectToWordPress (goroutine): Finished.")
+		connectLog.Debug("connectToWordPress (goroutine): finished")
 
 	}()
 
 	// --- UI Update Handling ---
-	log.Println("connectToWordPress: Starting UI update handling goroutine.")
+	connectLog.Debug("connectToWordPress: starting UI update handling goroutine")
 	go func() {
-		log.Println("connectToWordPress (UI goroutine): Started. Waiting for result from 'done' channel.")
+		defer crash.Recover("WordPressSettingsView.connectToWordPress")
+		connectLog.Debug("connectToWordPress (UI goroutine): started. Waiting for result from 'done' channel")
 		err, ok := <-done // Receive the result from the connection goroutine
-		log.Printf("connectToWordPress (UI goroutine): Received from 'done' channel. Error: %v, OK: %t", err, ok)
-
-		// Ensure progress dialog is hidden in all cases
-		defer progress.Hide()
-
-		if !ok {
-			// Channel was closed without sending a value, unusual case
-			log.Println("connectToWordPress (UI goroutine): 'done' channel closed unexpectedly.")
-			// Attempt cleanup just in case
-			log.Println("connectToWordPress (UI goroutine): Unexpected close - updating UI state")
-			v.updateConnectButtonState()
-			v.connectButton.Refresh()
-			log.Println("connectToWordPress (UI goroutine): Setting status to Error (unexpected close).")
-			v.statusLabel.SetText("Status: Error (Connection Aborted)")
-			v.statusLabel.Refresh()
-			log.Println("connectToWordPress (UI goroutine): Finished (unexpected close).")
-			return
-		}
+		connectLog.Debug("connectToWordPress (UI goroutine): received result", "error", err, "ok", ok)
+		runOnUI(func() {
+			// Ensure progress dialog is hidden in all cases
+			defer progress.Hide()
+
+			if !ok {
+				// Channel was closed without sending a value, unusual case
+				connectLog.Debug("connectToWordPress (UI goroutine): 'done' channel closed unexpectedly")
+				// Attempt cleanup just in case
+				connectLog.Debug("connectToWordPress (UI goroutine): unexpected close - updating UI state")
+				v.updateConnectButtonState()
+				v.connectButton.Refresh()
+				connectLog.Debug("connectToWordPress (UI goroutine): setting status to Error (unexpected close)")
+				v.statusLabel.SetText(i18n.T("Status: Error (Connection Aborted)"))
+				v.statusLabel.Refresh()
+				connectLog.Debug("connectToWordPress (UI goroutine): finished (unexpected close)")
+				return
+			}
 
-		// --- All UI updates happen here, after the network call is done ---
-		log.Println("connectToWordPress (UI goroutine): Hiding progress.")
-		progress.Hide() // Hide progress first
-		log.Println("connectToWordPress (UI goroutine): Enabling connect button.")
-		// v.connectButton.Enable() // Let updateConnectButtonState handle enabling
+			// --- All UI updates happen here, after the network call is done ---
+			connectLog.Debug("connectToWordPress (UI goroutine): hiding progress")
+			progress.Hide() // Hide progress first
+			connectLog.Debug("connectToWordPress (UI goroutine): enabling connect button")
+			// v.connectButton.Enable() // Let updateConnectButtonState handle enabling
 
-		if err != nil {
-			log.Printf("connectToWordPress (UI goroutine): Connection failed. Error: %v", err)
-			v.statusLabel.SetText(fmt.Sprintf("Status: Connection failed (%s)", err.Error()))
-			v.statusLabel.Refresh()
-			log.Println("connectToWordPress (UI goroutine): Showing error dialog.")
-			dialog.ShowError(fmt.Errorf("failed to connect: %w", err), v.window)
-			if v.onConnectionChanged != nil {
-				log.Println("connectToWordPress (UI goroutine): Calling onConnectionChanged(false).")
-				v.onConnectionChanged(false)
+			if err != nil {
+				connectLog.Error("connectToWordPress: connection failed", "error", err)
+				v.statusLabel.SetText(i18n.Tf("Status: Connection failed (%s)", err.Error()))
+				v.statusLabel.Refresh()
+				connectLog.Debug("connectToWordPress (UI goroutine): showing error dialog")
+				ShowError(fmt.Errorf("failed to connect: %w", err), v.window)
+				if v.onConnectionChanged != nil {
+					connectLog.Debug("connectToWordPress (UI goroutine): calling onConnectionChanged(false)")
+					v.onConnectionChanged(false)
+				}
+				connectLog.Debug("connectToWordPress (UI goroutine): finished (error path)")
+				return // Exit this UI update goroutine
 			}
-			log.Println("connectToWordPress (UI goroutine): Finished (error path).")
-			return // Exit this UI update goroutine
-		}
 
-		// Success path
-		log.Println("connectToWordPress (UI goroutine): Connection successful.")
-		v.statusLabel.SetText("Status: Connected")
-		v.statusLabel.Refresh()
+			// Success path
+			connectLog.Info("connectToWordPress: connection successful")
+			v.statusLabel.SetText(v.connectedStatus())
+			v.statusLabel.Refresh()
 		
-		// Update button state and force refresh
-		v.updateConnectButtonState()
-		v.connectButton.Refresh()
-		v.window.Canvas().Refresh(v.connectButton)
-		v.statusLabel.Refresh()
+			// Update button state and force refresh
+			v.updateConnectButtonState()
+			v.connectButton.Refresh()
+			v.window.Canvas().Refresh(v.connectButton)
+			v.statusLabel.Refresh()
 		
-		// Update button state again to ensure consistency
-		v.updateConnectButtonState()
-		v.connectButton.Refresh()
+			// Update button state again to ensure consistency
+			v.updateConnectButtonState()
+			v.connectButton.Refresh()
 		
-		if v.onConnectionChanged != nil {
-			log.Println("connectToWordPress (UI goroutine): Calling onConnectionChanged(true).")
-			v.onConnectionChanged(true)
-		}
+			if v.onConnectionChanged != nil {
+				connectLog.Debug("connectToWordPress (UI goroutine): calling onConnectionChanged(true)")
+				v.onConnectionChanged(true)
+			}
 		
-		// Final refresh to ensure all UI updates are visible
-		v.window.Canvas().Refresh(v.connectButton)
-		v.window.Canvas().Refresh(v.statusLabel)
-
-		// Save site if remember is checked
-		if remember {
-			log.Println("connectToWordPress (UI goroutine): 'Remember Me' checked. Proceeding to save.")
-			effectiveSiteName := siteName
-			if effectiveSiteName == "" {
-				u, parseErr := url.Parse(siteURL)
-				if parseErr == nil && u != nil {
-					effectiveSiteName = u.Host
-				} else {
-					effectiveSiteName = "WordPress Site" // Fallback
+			// Final refresh to ensure all UI updates are visible
+			v.window.Canvas().Refresh(v.connectButton)
+			v.window.Canvas().Refresh(v.statusLabel)
+
+			// Save site if remember is checked
+			if remember {
+				connectLog.Debug("connectToWordPress (UI goroutine): 'Remember Me' checked. Proceeding to save")
+				effectiveSiteName := siteName
+				if effectiveSiteName == "" {
+					u, parseErr := url.Parse(siteURL)
+					if parseErr == nil && u != nil {
+						effectiveSiteName = u.Host
+					} else {
+						effectiveSiteName = "WordPress Site" // Fallback
+					}
+					connectLog.Debug("connectToWordPress (UI goroutine): generated effective site name", "name", effectiveSiteName)
+					v.siteNameEntry.SetText(effectiveSiteName)
+					// v.siteNameEntry.Refresh() // Refresh might be needed
 				}
-				log.Printf("connectToWordPress (UI goroutine): Generated effective site name: %s", effectiveSiteName)
-				v.siteNameEntry.SetText(effectiveSiteName)
-				// v.siteNameEntry.Refresh() // Refresh might be needed
-			}
 
-			log.Printf("connectToWordPress (UI goroutine): Calling wpService.SaveSite for name: %s", effectiveSiteName)
-			saveErr := v.wpService.SaveSite(effectiveSiteName, siteURL, username, password)
-			if saveErr != nil {
-				log.Printf("connectToWordPress (UI goroutine): Error saving site: %v", saveErr)
-				dialog.ShowError(fmt.Errorf("connection successful, but failed to save site: %w", saveErr), v.window)
+				connectLog.Info("connectToWordPress: saving site", "name", effectiveSiteName)
+				saveErr := v.wpService.SaveSite(effectiveSiteName, siteURL, username, password)
+				if saveErr != nil {
+					connectLog.Error("connectToWordPress: error saving site", "error", saveErr)
+					ShowError(fmt.Errorf("connection successful, but failed to save site: %w", saveErr), v.window)
+				} else {
+					connectLog.Debug("connectToWordPress (UI goroutine): site saved successfully. Refreshing saved sites list")
+					v.refreshSavedSites() // Refresh list after successful save
+				}
 			} else {
-				log.Println("connectToWordPress (UI goroutine): Site saved successfully. Refreshing saved sites list.")
-				v.refreshSavedSites() // Refresh list after successful save
+				connectLog.Debug("connectToWordPress (UI goroutine): 'Remember Me' not checked. Skipping save")
 			}
-		} else {
-			log.Println("connectToWordPress (UI goroutine): 'Remember Me' not checked. Skipping save.")
-		}
-		log.Println("connectToWordPress (UI goroutine): Finished (success path).")
+			connectLog.Debug("connectToWordPress (UI goroutine): finished (success path)")
+		})
 	}() // End of UI update handling goroutine
-	log.Println("connectToWordPress: Exiting main function.")
+	connectLog.Debug("connectToWordPress: exiting main function")
 } // End of connectToWordPress
 
 // refreshSavedSites refreshes the list of saved sites
 func (v *WordPressSettingsView) refreshSavedSites() {
 	v.savedSites = v.wpService.GetSavedSites()
 	v.savedSitesList.Refresh()
+	if v.onSavedSitesChanged != nil {
+		v.onSavedSitesChanged()
+	}
 
 	// Reset selection
 	v.selectedSiteIndex = -1
@@ -589,7 +750,7 @@ func (v *WordPressSettingsView) loadSavedSite() {
 	siteName := v.savedSites[v.selectedSiteIndex].Name
 	site, found := v.wpService.GetSavedSite(siteName)
 	if !found {
-		dialog.ShowError(fmt.Errorf("site not found"), v.window)
+		ShowError(fmt.Errorf("site not found"), v.window)
 		return
 	}
 
@@ -613,14 +774,14 @@ func (v *WordPressSettingsView) deleteSavedSite() {
 	siteName := v.savedSites[v.selectedSiteIndex].Name
 
 	// Confirm deletion
-	dialog.ShowConfirm("Delete Site", fmt.Sprintf("Are you sure you want to delete the saved site '%s'?", siteName), func(confirmed bool) {
+	dialog.ShowConfirm(i18n.T("Delete Site"), i18n.Tf("Are you sure you want to delete the saved site '%s'?", siteName), func(confirmed bool) {
 		if !confirmed {
 			return
 		}
 
 		err := v.wpService.DeleteSavedSite(siteName)
 		if err != nil {
-			dialog.ShowError(err, v.window)
+			ShowError(err, v.window)
 			return
 		}
 
@@ -640,13 +801,95 @@ func (v *WordPressSettingsView) SetOnConnectionChanged(callback func(connected b
 	}
 }
 
+// SetCredentialsEditable shows or hides the credential fields and the saved
+// site actions, which the editor profile may not use. Hiding them clears the
+// form so no password stays behind.
+func (v *WordPressSettingsView) SetCredentialsEditable(editable bool) {
+	if editable {
+		v.credentialsNote.Hide()
+		v.credentialsForm.Show()
+		v.loadSiteButton.Show()
+		v.deleteSiteButton.Show()
+		return
+	}
+	v.siteNameEntry.SetText("")
+	v.siteURLEntry.SetText("")
+	v.usernameEntry.SetText("")
+	v.passwordEntry.SetText("")
+	v.credentialsForm.Hide()
+	v.credentialsNote.Show()
+	v.loadSiteButton.Hide()
+	v.deleteSiteButton.Hide()
+}
+
+// SetOnSavedSitesChanged sets the callback for when sites are saved or deleted
+func (v *WordPressSettingsView) SetOnSavedSitesChanged(callback func()) {
+	v.onSavedSitesChanged = callback
+}
+
+// connectedStatus is the status shown when connected, with the plugins found
+// on the site, whose features are enabled for it.
+func (v *WordPressSettingsView) connectedStatus() string {
+	detected := v.wpService.Features().Detected()
+	if len(detected) == 0 {
+		return i18n.T("Status: Connected")
+	}
+	names := make([]string, len(detected))
+	for i, feature := range detected {
+		names[i] = string(feature)
+	}
+	return i18n.Tf("Status: Connected (found %s)", strings.Join(names, ", "))
+}
+
 // UpdateConnectionStatus updates the connection status label
 func (v *WordPressSettingsView) UpdateConnectionStatus(connected bool) {
 	if connected {
-		v.statusLabel.SetText("Status: Connected")
+		v.statusLabel.SetText(v.connectedStatus())
 	} else {
-		v.statusLabel.SetText("Status: Disconnected")
+		v.statusLabel.SetText(i18n.T("Status: Disconnected"))
 	}
 	v.statusLabel.Refresh()
 	v.updateConnectButtonState() // Update button whenever status is explicitly updated
 }
+
+// moaOptionsForm switches the MOA judge and edits the token and time limits
+// of one MOA job, past which it is generated with the MOA primary model
+// alone.
+func (v *InferenceSettingsView) moaOptionsForm() fyne.CanvasObject {
+	judgeCheck := widget.NewCheck(i18n.T("Judge the agents' answers instead of aggregating them"), inference.PrefMOAJudge.Set)
+	judgeCheck.SetChecked(inference.PrefMOAJudge.Get())
+	judgeNote := widget.NewLabel(i18n.T("The fallback model scores each agent's answer against the request and sources, then writes the final answer from the best parts. The scores are in the log."))
+	judgeNote.Wrapping = fyne.TextWrapWord
+
+	tokensEntry := widget.NewEntry()
+	tokensEntry.SetText(strconv.Itoa(inference.PrefMOAMaxTokens.Get()))
+	secondsEntry := widget.NewEntry()
+	secondsEntry.SetText(strconv.Itoa(inference.PrefMOAMaxSeconds.Get()))
+	note := widget.NewLabel(i18n.T("An MOA job expected to use more tokens than the limit, or still running past either limit, is generated with the MOA primary model alone, and you are told. 0 means no limit."))
+	note.Wrapping = fyne.TextWrapWord
+	saveButton := widget.NewButton(i18n.T("Set MOA Limits"), func() {
+		tokens, err := strconv.Atoi(strings.TrimSpace(tokensEntry.Text))
+		if err != nil || tokens < 0 {
+			ShowError(fmt.Errorf("the MOA token limit must be a whole number"), v.window)
+			return
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(secondsEntry.Text))
+		if err != nil || seconds < 0 {
+			ShowError(fmt.Errorf("the MOA time limit must be a whole number of seconds"), v.window)
+			return
+		}
+		inference.PrefMOAMaxTokens.Set(tokens)
+		inference.PrefMOAMaxSeconds.Set(seconds)
+		logger.Info("MOA limits set", "max_tokens", tokens, "max_seconds", seconds)
+	})
+	return container.NewVBox(
+		judgeCheck,
+		judgeNote,
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Max tokens per MOA job:"), tokensEntry),
+			widget.NewFormItem(i18n.T("Max seconds per MOA job:"), secondsEntry),
+		),
+		note,
+		saveButton,
+	)
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"strings"
+
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// appShortcut is a window-wide keyboard shortcut with a cheatsheet description.
+type appShortcut struct {
+	shortcut    *desktop.CustomShortcut
+	description string
+	action      func()
+}
+
+// appShortcuts holds the registered shortcuts in cheatsheet order.
+// Shortcuts are only registered from the UI goroutine.
+var appShortcuts []appShortcut
+
+// findAppShortcut returns the registered action for a shortcut, if any.
+func findAppShortcut(s fyne.Shortcut) (func(), bool) {
+	for _, sc := range appShortcuts {
+		if sc.shortcut.ShortcutName() == s.ShortcutName() {
+			return sc.action, true
+		}
+	}
+	return nil, false
+}
+
+// AppShortcutViews groups the views the global shortcuts act on.
+type AppShortcutViews struct {
+	Tabs      *container.AppTabs
+	Manager   *ContentManagerView
+	Generator *ContentGeneratorView
+	Chat      *InferenceChatView
+	Activity  *ActivityView
+}
+
+// regionView returns the view shown in a tab, if it supports focus cycling.
+func (views AppShortcutViews) regionView(item *container.TabItem) focusRegions {
+	if item == nil {
+		return nil
+	}
+	candidates := []interface {
+		focusRegions
+		Container() fyne.CanvasObject
+	}{views.Manager, views.Generator, views.Chat}
+	if views.Activity != nil {
+		candidates = append(candidates, views.Activity)
+	}
+	for _, view := range candidates {
+		if view.Container() == item.Content {
+			return view
+		}
+	}
+	return nil
+}
+
+// RegisterAppShortcuts adds the app's keyboard shortcuts to the window canvas.
+// Actions that depend on context (send/generate, save) act on the selected tab.
+func RegisterAppShortcuts(w fyne.Window, views AppShortcutViews) {
+	selectedTab := func() string {
+		if item := views.Tabs.Selected(); item != nil {
+			return item.Text
+		}
+		return ""
+	}
+
+	add := func(key fyne.KeyName, modifier fyne.KeyModifier, description string, action func()) {
+		sc := appShortcut{
+			shortcut:    &desktop.CustomShortcut{KeyName: key, Modifier: modifier},
+			description: description,
+			action:      action,
+		}
+		appShortcuts = append(appShortcuts, sc)
+		w.Canvas().AddShortcut(sc.shortcut, func(fyne.Shortcut) { sc.action() })
+	}
+
+	add(fyne.KeyReturn, fyne.KeyModifierShortcutDefault, i18n.T("Send message (Chat) / Generate content (Generator)"), func() {
+		switch selectedTab() {
+		case i18n.T("Generator"):
+			if !views.Generator.generateButton.Disabled() {
+				views.Generator.generateContent()
+			}
+		case i18n.T("Inference Chat"):
+			if !views.Chat.sendButton.Disabled() {
+				views.Chat.handleSendMessage()
+			}
+		}
+	})
+	add(fyne.KeyS, fyne.KeyModifierShortcutDefault, i18n.T("Save page (Manager) / Save result to file (Generator)"), func() {
+		switch selectedTab() {
+		case i18n.T("Manager"):
+			if !views.Manager.saveButton.Disabled() {
+				views.Manager.savePageContent()
+			}
+		case i18n.T("Generator"):
+			if !views.Generator.saveToFileButton.Disabled() {
+				views.Generator.saveGeneratedContentToFile()
+			}
+		}
+	})
+	add(fyne.KeyTab, fyne.KeyModifierControl, i18n.T("Next tab"), func() {
+		n := len(views.Tabs.Items)
+		views.Tabs.SelectIndex((views.Tabs.SelectedIndex() + 1) % n)
+	})
+	add(fyne.KeyTab, fyne.KeyModifierControl|fyne.KeyModifierShift, i18n.T("Previous tab"), func() {
+		n := len(views.Tabs.Items)
+		views.Tabs.SelectIndex((views.Tabs.SelectedIndex() + n - 1) % n)
+	})
+	add(fyne.KeyF6, fyne.KeyModifierControl, i18n.T("Move focus to the next area of the tab"), func() {
+		if view := views.regionView(views.Tabs.Selected()); view != nil {
+			focusNextRegion(w.Canvas(), view, false)
+		}
+	})
+	add(fyne.KeyF6, fyne.KeyModifierControl|fyne.KeyModifierShift, i18n.T("Move focus to the previous area of the tab"), func() {
+		if view := views.regionView(views.Tabs.Selected()); view != nil {
+			focusNextRegion(w.Canvas(), view, true)
+		}
+	})
+	for i, key := range []fyne.KeyName{fyne.Key1, fyne.Key2, fyne.Key3, fyne.Key4, fyne.Key5, fyne.Key6, fyne.Key7, fyne.Key8, fyne.Key9} {
+		index := i
+		if index >= len(views.Tabs.Items) {
+			break
+		}
+		add(key, fyne.KeyModifierAlt, i18n.Tf("Go to tab %d", index+1), func() {
+			views.Tabs.SelectIndex(index)
+			if view := views.regionView(views.Tabs.Items[index]); view != nil {
+				w.Canvas().Focus(view.FocusRegions()[0])
+			}
+		})
+	}
+	add(fyne.KeyF, fyne.KeyModifierShortcutDefault, i18n.T("Search pages (Manager)"), func() {
+		views.Tabs.SelectIndex(tabIndex(views.Tabs, i18n.T("Manager")))
+		views.Manager.FocusPageSearch()
+	})
+	add(fyne.KeySlash, fyne.KeyModifierShortcutDefault, i18n.T("Show this keyboard shortcut list"), func() {
+		ShowShortcutCheatsheet(w)
+	})
+
+	logger.Info("Registered keyboard shortcuts", "count", len(appShortcuts))
+}
+
+// tabIndex returns the index of the tab with the given title, or the current index if not found.
+func tabIndex(tabs *container.AppTabs, title string) int {
+	for i, item := range tabs.Items {
+		if item.Text == title {
+			return i
+		}
+	}
+	return tabs.SelectedIndex()
+}
+
+// ShowShortcutCheatsheet shows a dialog listing the registered keyboard shortcuts.
+func ShowShortcutCheatsheet(w fyne.Window) {
+	form := widget.NewForm()
+	for _, sc := range appShortcuts {
+		form.Append(shortcutLabel(sc.shortcut), widget.NewLabel(sc.description))
+	}
+	dialog.ShowCustom(i18n.T("Keyboard Shortcuts"), i18n.T("Close"), form, w)
+}
+
+// shortcutLabel renders a shortcut as e.g. "Ctrl+Shift+Tab".
+func shortcutLabel(s *desktop.CustomShortcut) string {
+	var parts []string
+	mod := s.Modifier
+	if mod&fyne.KeyModifierShortcutDefault != 0 && fyne.KeyModifierShortcutDefault == fyne.KeyModifierSuper {
+		parts = append(parts, "Cmd")
+		mod &^= fyne.KeyModifierSuper
+	}
+	if mod&fyne.KeyModifierControl != 0 {
+		parts = append(parts, "Ctrl")
+	}
+	if mod&fyne.KeyModifierAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if mod&fyne.KeyModifierShift != 0 {
+		parts = append(parts, "Shift")
+	}
+	if mod&fyne.KeyModifierSuper != 0 {
+		parts = append(parts, "Super")
+	}
+	key := string(s.KeyName)
+	switch s.KeyName {
+	case fyne.KeyReturn:
+		key = "Enter"
+	case fyne.KeySlash:
+		key = "/"
+	}
+	return strings.Join(append(parts, key), "+")
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+	"Inference_Engine/postprocess"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SiteInstructionsView edits the default generation instructions and the
+// heading case style of each saved site.
+type SiteInstructionsView struct {
+	container *fyne.Container
+	store     *editorial.SiteInstructionsStore
+	wpService *wordpress.WordPressService
+	window    fyne.Window
+
+	// UI elements
+	siteSelect *widget.Select
+	editor     *EditorEntry
+	caseSelect *widget.Select
+	saveButton *widget.Button
+}
+
+// NewSiteInstructionsView creates a new site instructions view
+func NewSiteInstructionsView(store *editorial.SiteInstructionsStore, wpService *wordpress.WordPressService, window fyne.Window) *SiteInstructionsView {
+	view := &SiteInstructionsView{
+		store:     store,
+		wpService: wpService,
+		window:    window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the site instructions view
+func (v *SiteInstructionsView) initialize() {
+	v.editor = NewEditorEntry()
+	v.editor.SetPlaceHolder(editorial.SiteInstructionsExample)
+	v.editor.SetMinRowsVisible(4)
+
+	var caseOptions []string
+	for _, style := range postprocess.CaseStyles {
+		caseOptions = append(caseOptions, i18n.T(style.Label()))
+	}
+	v.caseSelect = widget.NewSelect(caseOptions, nil)
+
+	v.siteSelect = widget.NewSelect(nil, func(string) { v.load() })
+	v.saveButton = widget.NewButtonWithIcon(i18n.T("Save Instructions"), theme.DocumentSaveIcon(), v.save)
+	v.RefreshSites()
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Site Instructions")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Audience, tone and required disclaimers added to every generation while the site is connected.")),
+		widget.NewForm(widget.NewFormItem(i18n.T("Site:"), v.siteSelect)),
+		v.editor,
+		widget.NewForm(widget.NewFormItem(i18n.T("Heading case:"), v.caseSelect)),
+		container.NewHBox(v.saveButton),
+	)
+}
+
+// RefreshSites updates the site options after saved sites change, keeping
+// the current choice if it still exists, or choosing the connected site.
+func (v *SiteInstructionsView) RefreshSites() {
+	var options []string
+	if v.wpService != nil {
+		for _, site := range v.wpService.GetSavedSites() {
+			options = append(options, site.Name)
+		}
+	}
+	selected := v.siteSelect.Selected
+	v.siteSelect.Options = options
+	for _, option := range options {
+		if option == selected {
+			v.siteSelect.Refresh()
+			return
+		}
+	}
+	if v.wpService != nil && v.wpService.GetCurrentSiteName() != "" {
+		v.siteSelect.SetSelected(v.wpService.GetCurrentSiteName())
+	} else if len(options) > 0 {
+		v.siteSelect.SetSelected(options[0])
+	} else {
+		v.siteSelect.ClearSelected()
+	}
+	v.load()
+}
+
+// load shows the selected site's instructions.
+func (v *SiteInstructionsView) load() {
+	v.editor.SetText(v.store.Text(v.siteSelect.Selected))
+	v.editor.ClearHistory()
+	v.caseSelect.SetSelectedIndex(0)
+	style := v.store.HeadingCase(v.siteSelect.Selected)
+	for i, option := range postprocess.CaseStyles {
+		if option == style {
+			v.caseSelect.SetSelectedIndex(i)
+		}
+	}
+	if v.siteSelect.Selected == "" {
+		v.editor.Disable()
+		v.caseSelect.Disable()
+		v.saveButton.Disable()
+	} else {
+		v.editor.Enable()
+		v.caseSelect.Enable()
+		v.saveButton.Enable()
+	}
+}
+
+// save stores the editor's text as the selected site's instructions, and
+// its heading case style, applied at once if the site is connected.
+func (v *SiteInstructionsView) save() {
+	site := v.siteSelect.Selected
+	if err := v.store.Save(site, v.editor.Text); err != nil {
+		ShowError(fmt.Errorf("site instructions not saved: %w", err), v.window)
+		return
+	}
+	style := postprocess.CaseStyles[max(v.caseSelect.SelectedIndex(), 0)]
+	if err := v.store.SaveHeadingCase(site, style); err != nil {
+		ShowError(fmt.Errorf("heading case not saved: %w", err), v.window)
+		return
+	}
+	if v.wpService != nil && site == v.wpService.GetCurrentSiteName() {
+		postprocess.SetHeadingCase(style)
+	}
+}
+
+// Container returns the container for the site instructions view
+func (v *SiteInstructionsView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/logging"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// disconnectOption is the site switcher entry that disconnects from the current site
+const disconnectOption = "Disconnect"
+
+// SiteSwitcher is a toolbar dropdown that connects to a saved site, or
+// disconnects, with a single selection.
+type SiteSwitcher struct {
+	container *fyne.Container
+	wpService *wordpress.WordPressService
+	window    fyne.Window
+
+	siteSelect *widget.Select
+	switching  bool // Ignore OnChanged while the selection is set programmatically
+
+	// Called on the UI side after a switch, with the new connection state
+	onSiteChanged func(connected bool)
+}
+
+// NewSiteSwitcher creates a new saved-site quick switcher
+func NewSiteSwitcher(wpService *wordpress.WordPressService, window fyne.Window) *SiteSwitcher {
+	s := &SiteSwitcher{
+		wpService:     wpService,
+		window:        window,
+		onSiteChanged: func(bool) {},
+	}
+	s.initialize()
+	return s
+}
+
+// initialize creates the dropdown
+func (s *SiteSwitcher) initialize() {
+	s.siteSelect = widget.NewSelect(nil, func(selected string) {
+		if s.switching {
+			return
+		}
+		if selected == disconnectOption {
+			s.disconnect()
+		} else {
+			s.switchTo(selected)
+		}
+	})
+	s.siteSelect.PlaceHolder = "(no site connected)"
+
+	s.container = container.NewHBox(widget.NewLabel(i18n.T("Site:")), s.siteSelect)
+	s.RefreshSites()
+}
+
+// SetOnSiteChanged sets the callback run after the switcher connects or disconnects
+func (s *SiteSwitcher) SetOnSiteChanged(callback func(connected bool)) {
+	s.onSiteChanged = callback
+}
+
+// RefreshSites reloads the saved site names and shows the current site as selected
+func (s *SiteSwitcher) RefreshSites() {
+	var options []string
+	for _, site := range s.wpService.GetSavedSites() {
+		options = append(options, site.Name)
+	}
+	if s.wpService.IsConnected() {
+		options = append(options, disconnectOption)
+	}
+
+	s.switching = true
+	s.siteSelect.Options = options
+	s.siteSelect.Selected = s.wpService.GetCurrentSiteName()
+	s.siteSelect.Refresh()
+	s.switching = false
+}
+
+// switchTo connects to a saved site, disconnecting from the current one first
+func (s *SiteSwitcher) switchTo(name string) {
+	site, found := s.wpService.GetSavedSite(name)
+	if !found {
+		ShowError(fmt.Errorf("saved site '%s' not found", name), s.window)
+		s.RefreshSites()
+		return
+	}
+
+	progress := dialog.NewProgressInfinite(i18n.T("Connecting"), i18n.Tf("Connecting to %s...", name), s.window)
+	progress.Show()
+
+	go func() {
+		defer crash.Recover("SiteSwitcher.switchTo")
+		if s.wpService.IsConnected() {
+			logger.Info("SiteSwitcher: disconnecting", logging.Site(s.wpService.GetCurrentSiteName()))
+			s.wpService.Disconnect()
+		}
+		logger.Info("SiteSwitcher: connecting to saved site", logging.Site(name))
+		err := s.wpService.Connect(site.URL, site.Username, site.AppPassword)
+		runOnUI(func() {
+			progress.Hide()
+
+			s.RefreshSites()
+			if err != nil {
+				logger.Error("SiteSwitcher: failed to connect", logging.Site(name), "error", err)
+				ShowError(fmt.Errorf("failed to connect to '%s': %w", name, err), s.window)
+				s.onSiteChanged(false)
+				return
+			}
+			s.onSiteChanged(true)
+		})
+	}()
+}
+
+// disconnect disconnects from the current site
+func (s *SiteSwitcher) disconnect() {
+	go func() {
+		defer crash.Recover("SiteSwitcher.disconnect")
+		logger.Info("SiteSwitcher: disconnecting")
+		s.wpService.Disconnect()
+		runOnUI(func() {
+			s.RefreshSites()
+			s.onSiteChanged(false)
+		})
+	}()
+}
+
+// Container returns the container for the site switcher
+func (s *SiteSwitcher) Container() fyne.CanvasObject {
+	return s.container
+}
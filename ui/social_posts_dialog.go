@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"Inference_Engine/i18n"
+	"Inference_Engine/repurpose"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showSocialPosts shows the social media variants of an article in one tab
+// per platform. Each post can be edited and copied on its own, and all of
+// them exported to a Markdown file.
+func showSocialPosts(window fyne.Window, posts repurpose.SocialPosts) {
+	var threadEntries []*widget.Entry
+	thread := container.NewVBox()
+	for i, post := range posts.XThread {
+		entry := widget.NewMultiLineEntry()
+		entry.Wrapping = fyne.TextWrapWord
+		entry.SetMinRowsVisible(3)
+		count := widget.NewLabel("")
+		entry.OnChanged = func(text string) {
+			if n := repurpose.XLength(text); n > repurpose.XPostLimit {
+				count.SetText(i18n.Tf("%d/%d characters, over the limit", n, repurpose.XPostLimit))
+			} else {
+				count.SetText(i18n.Tf("%d/%d characters", n, repurpose.XPostLimit))
+			}
+		}
+		entry.SetText(post)
+		threadEntries = append(threadEntries, entry)
+		thread.Add(container.NewHBox(widget.NewLabel(i18n.Tf("Post %d/%d", i+1, len(posts.XThread))), layout.NewSpacer(), count,
+			newCopyButton(window, i18n.T("Copy"), func() string { return entry.Text })))
+		thread.Add(entry)
+	}
+	linkedIn := newPostEditor(posts.LinkedIn)
+	facebook := newPostEditor(posts.Facebook)
+	current := func() repurpose.SocialPosts {
+		edited := repurpose.SocialPosts{LinkedIn: linkedIn.Text, Facebook: facebook.Text}
+		for _, entry := range threadEntries {
+			edited.XThread = append(edited.XThread, entry.Text)
+		}
+		return edited
+	}
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("X Thread"), newReadingOrderBorder(nil,
+			container.NewHBox(layout.NewSpacer(), newCopyButton(window, i18n.T("Copy Thread"), func() string { return current().XThreadText() })),
+			nil, nil, container.NewVScroll(thread))),
+		container.NewTabItem(i18n.T("LinkedIn"), newReadingOrderBorder(nil,
+			container.NewHBox(layout.NewSpacer(), newCopyButton(window, i18n.T("Copy"), func() string { return linkedIn.Text })),
+			nil, nil, container.NewVScroll(linkedIn))),
+		container.NewTabItem(i18n.T("Facebook"), newReadingOrderBorder(nil,
+			container.NewHBox(layout.NewSpacer(), newCopyButton(window, i18n.T("Copy"), func() string { return facebook.Text })),
+			nil, nil, container.NewVScroll(facebook))),
+	)
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Social Posts"), tabs, window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		widget.NewButton(i18n.T("Export"), func() {
+			exportTextToFile(window, i18n.T("Social posts"), "social-posts", "md", current().Export())
+		}),
+	})
+	d.Resize(fyne.NewSize(640, 520))
+	d.Show()
+}
+
+// newPostEditor is an editable, word-wrapped post.
+func newPostEditor(text string) *widget.Entry {
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetMinRowsVisible(10)
+	entry.SetText(text)
+	return entry
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// statusBarInterval is how often the status bar polls the services
+const statusBarInterval = 2 * time.Second
+
+// StatusBar is the strip along the bottom of the window showing the WordPress
+// connection, inference service health, active jobs and today's token spend,
+// and offline mode when the network is lost.
+type StatusBar struct {
+	container        fyne.CanvasObject
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+
+	siteLabel      *widget.Label
+	inferenceLabel *widget.Label
+	jobsLabel      *widget.Label
+	tokensLabel    *widget.Label
+	offlineLabel   *widget.Label
+
+	stop chan struct{}
+}
+
+// NewStatusBar creates a new status bar and starts polling the services
+func NewStatusBar(wpService *wordpress.WordPressService, inferenceService *inference.InferenceService) *StatusBar {
+	bar := &StatusBar{
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		stop:             make(chan struct{}),
+	}
+	bar.initialize()
+	crash.Go("StatusBar.poll", bar.poll)
+	return bar
+}
+
+// initialize creates the status labels
+func (b *StatusBar) initialize() {
+	b.siteLabel = widget.NewLabel("")
+	b.inferenceLabel = widget.NewLabel("")
+	b.jobsLabel = widget.NewLabel("")
+	b.tokensLabel = widget.NewLabel("")
+	b.offlineLabel = widget.NewLabel("")
+	b.offlineLabel.Importance = widget.WarningImportance
+
+	b.container = container.NewVBox(
+		widget.NewSeparator(),
+		container.NewHBox(
+			b.offlineLabel,
+			b.siteLabel,
+			widget.NewSeparator(),
+			b.inferenceLabel,
+			widget.NewSeparator(),
+			b.jobsLabel,
+			widget.NewSeparator(),
+			b.tokensLabel,
+		),
+	)
+	b.update()
+}
+
+// Refresh schedules a label update. Safe to call from any goroutine.
+func (b *StatusBar) Refresh() {
+	runOnUI(b.update)
+}
+
+// update sets the labels from the current service state
+func (b *StatusBar) update() {
+	if b.wpService.IsConnected() {
+		site := b.wpService.GetCurrentSiteName()
+		if site == "" {
+			site = "Connected Site"
+		}
+		b.siteLabel.SetText(i18n.T("WordPress: ") + site)
+	} else {
+		b.siteLabel.SetText(i18n.T("WordPress: disconnected"))
+	}
+
+	if b.wpService.Offline() {
+		b.offlineLabel.SetText(i18n.Tf("Offline (%d updates queued)", len(b.wpService.QueuedUpdates())))
+		b.offlineLabel.Show()
+	} else {
+		b.offlineLabel.Hide()
+	}
+
+	if b.inferenceService.IsRunning() {
+		b.inferenceLabel.SetText(i18n.Tf("Inference: running (%d models)",
+			len(b.inferenceService.GetPrimaryModels())+len(b.inferenceService.GetFallbackModels())))
+	} else {
+		b.inferenceLabel.SetText(i18n.T("Inference: stopped"))
+	}
+
+	stats := b.inferenceService.UsageStats()
+	b.jobsLabel.SetText(i18n.Tf("Active jobs: %d", stats.ActiveJobs))
+	if stats.TokenBudget > 0 {
+		b.tokensLabel.SetText(i18n.Tf("Tokens today: %d of %d (%d prompt, %d completion; %d requests)",
+			stats.TokensToday, stats.TokenBudget, stats.PromptTokensToday, stats.CompletionTokensToday, stats.JobsToday))
+	} else {
+		b.tokensLabel.SetText(i18n.Tf("Tokens today: %d (%d prompt, %d completion; %d requests)",
+			stats.TokensToday, stats.PromptTokensToday, stats.CompletionTokensToday, stats.JobsToday))
+	}
+}
+
+// poll refreshes the bar periodically until Stop is called
+func (b *StatusBar) poll() {
+	ticker := time.NewTicker(statusBarInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.Refresh()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the background polling
+func (b *StatusBar) Stop() {
+	close(b.stop)
+}
+
+// Container returns the container for the status bar
+func (b *StatusBar) Container() fyne.CanvasObject {
+	return b.container
+}
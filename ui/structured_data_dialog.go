@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/history"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/seo"
+	"Inference_Engine/utils"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultSchemaMetaKey is the custom field offered for structured data.
+const defaultSchemaMetaKey = "schema_json_ld"
+
+// structuredDataDialog generates schema.org JSON-LD for a page, validates it
+// and writes it to the page content or a custom field.
+type structuredDataDialog struct {
+	page             wordpress.Page
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+	jobQueue         *jobs.Queue
+	drafts           *history.Store // Where the JSON-LD is reviewed before it is written, when approval is required
+	window           fyne.Window
+
+	typeSelect     *widget.Select
+	editor         *EditorEntry
+	issuesLabel    *widget.Label
+	metaKeyEntry   *widget.Entry
+	generateButton *widget.Button
+}
+
+// showStructuredDataDialog opens the structured data dialog for a page.
+func showStructuredDataDialog(page wordpress.Page, wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, jobQueue *jobs.Queue, drafts *history.Store, window fyne.Window) {
+	d := &structuredDataDialog{
+		page:             page,
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		jobQueue:         jobQueue,
+		drafts:           drafts,
+		window:           window,
+	}
+
+	var types []string
+	for _, t := range seo.SchemaTypes {
+		types = append(types, string(t))
+	}
+	d.typeSelect = widget.NewSelect(types, nil)
+	d.typeSelect.SetSelectedIndex(0)
+	d.generateButton = widget.NewButton(i18n.T("Generate"), d.generate)
+
+	d.editor = NewEditorEntry()
+	d.editor.SetPlaceHolder(i18n.T("Generate structured data, or paste JSON-LD here..."))
+	d.editor.SetMinRowsVisible(14)
+	d.issuesLabel = widget.NewLabel("")
+	d.issuesLabel.Wrapping = fyne.TextWrapWord
+
+	d.metaKeyEntry = widget.NewEntry()
+	d.metaKeyEntry.SetText(defaultSchemaMetaKey)
+
+	content := container.NewBorder(
+		widget.NewForm(widget.NewFormItem(i18n.T("Type:"), container.NewBorder(nil, nil, nil, d.generateButton, d.typeSelect))),
+		container.NewVBox(
+			d.issuesLabel,
+			widget.NewForm(widget.NewFormItem(i18n.T("Meta Field:"), d.metaKeyEntry)),
+		),
+		nil, nil,
+		container.NewScroll(d.editor),
+	)
+	var dlg *dialog.CustomDialog
+	dlg = dialog.NewCustomWithoutButtons(i18n.Tf("Structured Data: %s", page.Title), content, window)
+	dlg.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { dlg.Hide() }),
+		widget.NewButton(i18n.T("Validate"), func() { d.validate() }),
+		widget.NewButton(i18n.T("Write to Meta Field"), d.writeToMeta),
+		widget.NewButton(i18n.T("Write to Page"), d.writeToPage),
+	})
+	dlg.Resize(fyne.NewSize(720, 600))
+	dlg.Show()
+}
+
+// generate asks the model for the selected type's properties and merges them
+// with the page and site metadata.
+func (d *structuredDataDialog) generate() {
+	schemaType := seo.SchemaType(d.typeSelect.Selected)
+	page := d.page
+	d.generateButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(d.generateButton.Enable)
+		jsonLD, err := d.build(ctx, schemaType, page, progress)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to generate structured data: %w", err), d.window) })
+			return err
+		}
+		runOnUI(func() {
+			d.editor.ReplaceText(jsonLD)
+			d.validate()
+		})
+		return nil
+	}
+	if d.jobQueue == nil {
+		crash.Go("structuredDataDialog.generate", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	d.jobQueue.Submit("Structured Data", fmt.Sprintf("%s: %s", schemaType, page.Title), run)
+}
+
+// build fetches the page and site metadata and returns the JSON-LD text.
+func (d *structuredDataDialog) build(ctx context.Context, schemaType seo.SchemaType, page wordpress.Page, progress jobs.ProgressFunc) (string, error) {
+	progress(-1, i18n.T("Fetching page"))
+	content, err := d.wpService.GetPageContent(page.ID)
+	if err != nil {
+		return "", err
+	}
+	info := seo.PageInfo{Title: page.Title, URL: page.Link, Modified: page.Modified}
+	if site, err := d.wpService.GetSiteInfo(); err != nil {
+		logger.Warn("StructuredData: site info unavailable, continuing without it", "error", err)
+	} else {
+		info.SiteName, info.SiteURL, info.SiteDescription, info.LogoURL = site.Name, site.URL, site.Description, site.IconURL
+	}
+
+	progress(-1, i18n.T("Extracting properties"))
+	output, err := d.inferenceService.Generate(
+		inference.GetSchemaExtractionPrompt(string(schemaType), schemaType.ExtractionFields(), utils.HTMLToMarkdown(content)),
+		inference.GenerateOptions{Context: ctx, Task: inference.TaskStructured})
+	if err != nil {
+		return "", err
+	}
+	extracted, err := seo.ParseSchemaProperties(output)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(seo.BuildSchema(schemaType, extracted, info), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// validate checks the editor's JSON-LD and lists the issues. It returns the
+// normalized JSON-LD, or an error if search engines would reject it.
+func (d *structuredDataDialog) validate() (string, error) {
+	text := strings.TrimSpace(d.editor.Text)
+	issues := seo.ValidateSchema(text)
+	if len(issues) == 0 {
+		d.issuesLabel.SetText(i18n.T("Valid: no issues found."))
+	} else {
+		lines := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			prefix := i18n.T("Warning")
+			if issue.Error {
+				prefix = i18n.T("Error")
+			}
+			lines = append(lines, prefix+": "+issue.String())
+		}
+		d.issuesLabel.SetText(strings.Join(lines, "\n"))
+	}
+	if seo.HasErrors(issues) {
+		return "", fmt.Errorf("the structured data has errors; fix them before writing it")
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(text), "", "  "); err != nil {
+		return "", err
+	}
+	return indented.String(), nil
+}
+
+// writeToPage embeds the JSON-LD in the page content, replacing an earlier
+// script of the same type.
+func (d *structuredDataDialog) writeToPage() {
+	jsonLD, err := d.validate()
+	if err != nil {
+		ShowError(err, d.window)
+		return
+	}
+	pageID := d.page.ID
+	saveIfApproved(d.drafts, d.window, jsonLD, fmt.Sprintf("Structured data for '%s'", d.page.Title), func() {
+		d.submitWrite(i18n.Tf("Structured data written to page '%s'", d.page.Title), func() error {
+			content, err := d.wpService.GetPageContent(pageID)
+			if err != nil {
+				return err
+			}
+			return d.wpService.UpdatePageContent(pageID, seo.EmbedJSONLD(content, jsonLD))
+		})
+	})
+}
+
+// writeToMeta stores the JSON-LD in the page's custom field, for themes or
+// plugins that print it in the page head.
+func (d *structuredDataDialog) writeToMeta() {
+	jsonLD, err := d.validate()
+	if err != nil {
+		ShowError(err, d.window)
+		return
+	}
+	key := strings.TrimSpace(d.metaKeyEntry.Text)
+	if key == "" {
+		ShowError(fmt.Errorf("enter the name of the meta field to write"), d.window)
+		return
+	}
+	pageID := d.page.ID
+	saveIfApproved(d.drafts, d.window, jsonLD, fmt.Sprintf("Structured data for '%s'", d.page.Title), func() {
+		d.submitWrite(i18n.Tf("Structured data written to the '%s' field", key), func() error {
+			return d.wpService.UpdatePageMeta(pageID, key, jsonLD)
+		})
+	})
+}
+
+// submitWrite runs a page update in the background and reports the outcome.
+func (d *structuredDataDialog) submitWrite(success string, write func() error) {
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		err := write()
+		runOnUI(func() {
+			if err != nil {
+				ShowError(fmt.Errorf("failed to write structured data: %w", err), d.window)
+				return
+			}
+			dialog.ShowInformation(i18n.T("Success"), success, d.window)
+		})
+		return err
+	}
+	if d.jobQueue == nil {
+		crash.Go("structuredDataDialog.submitWrite", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	d.jobQueue.Submit("Page Update", i18n.Tf("Structured data for page %d", d.page.ID), run)
+}
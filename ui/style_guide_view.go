@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"Inference_Engine/editorial"
+	"Inference_Engine/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// StyleGuideSettingsView lets the user register the editorial style guide
+// that every generation is checked against.
+type StyleGuideSettingsView struct {
+	container *fyne.Container
+	store     *editorial.StyleGuideStore
+	window    fyne.Window
+
+	// UI elements
+	editor      *EditorEntry
+	statusLabel *widget.Label
+}
+
+// NewStyleGuideSettingsView creates a new style guide settings view
+func NewStyleGuideSettingsView(store *editorial.StyleGuideStore, window fyne.Window) *StyleGuideSettingsView {
+	view := &StyleGuideSettingsView{
+		store:  store,
+		window: window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the style guide settings view
+func (v *StyleGuideSettingsView) initialize() {
+	v.editor = NewEditorEntry()
+	v.editor.SetPlaceHolder(editorial.StyleGuideExample)
+	v.editor.SetMinRowsVisible(10)
+	v.editor.SetText(v.store.Text())
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+	v.updateStatus(v.store.Guide())
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save Style Guide"), theme.DocumentSaveIcon(), v.save)
+	loadButton := widget.NewButtonWithIcon(i18n.T("Load from File..."), theme.FolderOpenIcon(), v.loadFromFile)
+	exampleButton := widget.NewButton(i18n.T("Insert Example"), func() {
+		v.editor.ReplaceText(editorial.StyleGuideExample)
+	})
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Editorial Style Guide")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Banned words and spelling conventions are checked after every generation; voice rules are sent to the model.")),
+		v.editor,
+		container.NewHBox(saveButton, loadButton, exampleButton),
+		v.statusLabel,
+	)
+}
+
+// save parses and registers the editor's text.
+func (v *StyleGuideSettingsView) save() {
+	if err := v.store.Save(v.editor.Text); err != nil {
+		ShowError(fmt.Errorf("style guide not saved: %w", err), v.window)
+		return
+	}
+	v.updateStatus(v.store.Guide())
+}
+
+// loadFromFile replaces the editor's text with a style guide file; it is
+// registered once saved.
+func (v *StyleGuideSettingsView) loadFromFile() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			ShowError(fmt.Errorf("failed to read style guide: %w", err), v.window)
+			return
+		}
+		v.editor.ReplaceText(string(data))
+	}, v.window)
+}
+
+// updateStatus summarizes the registered guide.
+func (v *StyleGuideSettingsView) updateStatus(guide editorial.StyleGuide) {
+	if guide.IsEmpty() {
+		v.statusLabel.SetText(i18n.T("No style guide registered."))
+		return
+	}
+	v.statusLabel.SetText(i18n.Tf("Registered: %d word rules, %d voice rules.", len(guide.Rules), len(guide.VoiceRules)))
+}
+
+// Container returns the container for the style guide settings view
+func (v *StyleGuideSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// showStyleViolations lists where text breaks the style guide. Auto-fix
+// applies every rule with a replacement and hands the result to onFix.
+func showStyleViolations(window fyne.Window, guide editorial.StyleGuide, text string, onFix func(fixed string)) {
+	violations := guide.Check(text)
+	if len(violations) == 0 {
+		dialog.ShowInformation(i18n.T("Style Guide"), i18n.T("No style guide violations found."), window)
+		return
+	}
+
+	fixable := 0
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		line := i18n.Tf("Line %d: %s \"%s\"", violation.Line, i18n.T(violation.Rule.Kind), violation.Found)
+		if violation.Fixable() {
+			fixable++
+			line += " → " + violation.Suggestion()
+		}
+		lines = append(lines, line)
+	}
+	list := widget.NewLabel(strings.Join(lines, "\n"))
+	list.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(480, 240))
+	summary := widget.NewLabel(i18n.Tf("%d violations, %d can be fixed automatically.", len(violations), fixable))
+
+	var d *dialog.CustomDialog
+	fixButton := widget.NewButtonWithIcon(i18n.T("Auto-fix"), theme.ConfirmIcon(), func() {
+		fixed, _ := guide.AutoFix(text)
+		d.Hide()
+		onFix(fixed)
+	})
+	if fixable == 0 {
+		fixButton.Disable()
+	}
+	d = dialog.NewCustomWithoutButtons(i18n.T("Style Guide"), newReadingOrderBorder(summary, nil, nil, nil, scroll), window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		fixButton,
+	})
+	d.Show()
+}
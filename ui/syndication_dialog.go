@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/embeddings"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/repurpose"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// syndicate asks which saved sites to syndicate the result to, then writes
+// a distinct version for each in the background and shows them.
+func (v *ContentGeneratorView) syndicate() {
+	article := strings.TrimSpace(v.resultOutput.Text)
+	if article == "" {
+		ShowError(fmt.Errorf("no generated content to syndicate"), v.window)
+		return
+	}
+	var names []string
+	if v.wpService != nil {
+		for _, site := range v.wpService.GetSavedSites() {
+			names = append(names, site.Name)
+		}
+	}
+	if len(names) == 0 {
+		ShowError(fmt.Errorf("save the sites to syndicate to in the WordPress settings first"), v.window)
+		return
+	}
+	sites := widget.NewCheckGroup(names, nil)
+	threshold := widget.NewEntry()
+	threshold.SetText(strconv.FormatFloat(repurpose.DefaultMaxSimilarity, 'f', -1, 64))
+	note := widget.NewLabel(i18n.T("Each site gets its own version. Versions more similar than the threshold to the article or to each other (1 means identical) are rewritten, writing at most 3 versions per site."))
+	note.Wrapping = fyne.TextWrapWord
+
+	form := dialog.NewForm(i18n.T("Syndicate"), i18n.T("Write Versions"), i18n.T("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Sites:"), sites),
+		widget.NewFormItem(i18n.T("Max similarity:"), threshold),
+		widget.NewFormItem("", note),
+	}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		maxSimilarity, err := strconv.ParseFloat(strings.TrimSpace(threshold.Text), 64)
+		if err != nil || maxSimilarity <= 0 || maxSimilarity >= 1 {
+			ShowError(fmt.Errorf("the max similarity must be a number between 0 and 1"), v.window)
+			return
+		}
+		v.runSyndication(article, append([]string(nil), sites.Selected...), maxSimilarity)
+	}, v.window)
+	form.Resize(fyne.NewSize(460, form.MinSize().Height))
+	form.Show()
+}
+
+// runSyndication writes and scores the per-site versions as a job.
+func (v *ContentGeneratorView) runSyndication(article string, sites []string, maxSimilarity float64) {
+	model := v.chosenModel()
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		written := 0
+		rewrite := func(ctx context.Context, site string, attempt int) (string, error) {
+			progress(-1, i18n.Tf("Writing the version for %s (attempt %d)", site, attempt))
+			siteInstruction := ""
+			if v.siteInstructions != nil {
+				siteInstruction = v.siteInstructions.Instruction(site)
+			}
+			written++
+			return v.inferenceService.Generate(inference.GetSyndicationVariantPrompt(site, siteInstruction, article, attempt),
+				contentOptions(ctx, model))
+		}
+		variants, err := repurpose.Syndicate(ctx, article, sites, rewrite, embeddings.Default(), maxSimilarity)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to syndicate: %w", err), v.window) })
+			return err
+		}
+		logger.Info("ContentGeneratorView: wrote syndicated versions", "sites", len(sites), "versions_written", written)
+		runOnUI(func() { v.showSyndicationVariants(variants, maxSimilarity) })
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("ContentGeneratorView.runSyndication", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Syndication", i18n.Tf("%d sites", len(sites)), run)
+}
+
+// showSyndicationVariants shows each site's version with its similarity
+// score, to copy or open in the Generator for saving.
+func (v *ContentGeneratorView) showSyndicationVariants(variants []repurpose.Variant, maxSimilarity float64) {
+	tabs := container.NewAppTabs()
+	var d *dialog.CustomDialog
+	for _, variant := range variants {
+		score := i18n.Tf("Similarity %.2f (at most %.2f), %d versions written", variant.Similarity, maxSimilarity, variant.Attempts)
+		if variant.TooSimilar(maxSimilarity) {
+			score += "\n" + i18n.T("Still too similar: edit it further before publishing.")
+		}
+		scoreLabel := widget.NewLabel(score)
+		scoreLabel.Wrapping = fyne.TextWrapWord
+		content := widget.NewMultiLineEntry()
+		content.Wrapping = fyne.TextWrapWord
+		content.SetText(variant.Content)
+		site := variant.Site
+		buttons := container.NewHBox(
+			newCopyButton(v.window, i18n.T("Copy"), func() string { return content.Text }),
+			widget.NewButton(i18n.T("Open in Generator"), func() {
+				v.OpenResult(v.promptEntry.Text, content.Text)
+				logger.Info("ContentGeneratorView: opened syndicated version", "site", site)
+				d.Hide()
+			}),
+		)
+		tabs.Append(container.NewTabItem(site, newReadingOrderBorder(scoreLabel, buttons, nil, nil, container.NewScroll(content))))
+	}
+	d = dialog.NewCustom(i18n.T("Syndicated Versions"), i18n.T("Close"), tabs, v.window)
+	d.Resize(fyne.NewSize(720, 580))
+	d.Show()
+}
@@ -2,13 +2,15 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"strings"
-	"sync"
 
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
 	"Inference_Engine/inference"
+	"Inference_Engine/logging"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -16,51 +18,6 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// uiLogWriter struct and NewUILogWriter remain the same...
-type uiLogWriter struct {
-	logOutput    *widget.Entry
-	originalOut  io.Writer
-	mu           sync.Mutex
-	buffer       []byte
-	maxLogLength int
-}
-
-func NewUILogWriter(logWidget *widget.Entry, original io.Writer) *uiLogWriter {
-	return &uiLogWriter{
-		logOutput:    logWidget,
-		originalOut:  original,
-		maxLogLength: 10000,
-	}
-}
-
-func (w *uiLogWriter) Write(p []byte) (n int, err error) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	// Write to original output if set
-	if w.originalOut != nil {
-		w.originalOut.Write(p)
-	}
-
-	// Append to buffer and process complete lines
-	w.buffer = append(w.buffer, p...)
-	for strings.Contains(string(w.buffer), "\n") {
-		idx := strings.Index(string(w.buffer), "\n")
-		line := string(w.buffer[:idx+1])
-		w.buffer = w.buffer[idx+1:]
-
-		// Update UI log widget
-		w.logOutput.SetText(w.logOutput.Text + line)
-
-		// Trim log if too long
-		if len(w.logOutput.Text) > w.maxLogLength {
-			w.logOutput.SetText(w.logOutput.Text[len(w.logOutput.Text)-w.maxLogLength:])
-		}
-	}
-
-	return len(p), nil
-}
-
 // TestInferenceView represents the UI for the new Test Inference tab
 type TestInferenceView struct {
 	container        fyne.CanvasObject
@@ -71,6 +28,13 @@ type TestInferenceView struct {
 	testMOAButton  *widget.Button // Test direct MOA call
 	testGeminiButton *widget.Button // Test direct Gemini call
 	logConsole     *widget.Entry
+	providerStatus *widget.Label  // Reachability and latency of each provider
+	probeButton    *widget.Button
+	console        *LogConsole // Set once log capture is active; holds the session's records
+
+	levelFilter     *widget.Select
+	componentFilter *widget.Select
+	searchFilter    *widget.Entry
 }
 
 // NewTestInferenceView creates a new TestInferenceView
@@ -85,38 +49,128 @@ func NewTestInferenceView(service *inference.InferenceService, win fyne.Window)
 
 // initialize sets up the UI elements for the view
 func (v *TestInferenceView) initialize() {
-	v.fallbackButton = widget.NewButton("Trigger Fallback Test (Oversize Prompt)", v.handleFallbackTest)
+	v.fallbackButton = widget.NewButton(i18n.T("Trigger Fallback Test (Oversize Prompt)"), v.handleFallbackTest)
 
 	// --- ADDED: MOA Test Button ---
-	v.testMOAButton = widget.NewButton("Test with MOA (Simple Prompt)", v.handleMOATest)
+	v.testMOAButton = widget.NewButton(i18n.T("Test with MOA (Simple Prompt)"), v.handleMOATest)
 
 	// --- ADDED: Gemini Test Button ---
-	v.testGeminiButton = widget.NewButton("Test Gemini Endpoint (Simple Prompt)", v.handleGeminiTest)
+	v.testGeminiButton = widget.NewButton(i18n.T("Test Gemini Endpoint (Simple Prompt)"), v.handleGeminiTest)
 	// --- End Added ---
 
 	v.logConsole = widget.NewMultiLineEntry()
-	v.logConsole.SetPlaceHolder("Application logs will appear here...")
+	v.logConsole.SetPlaceHolder(i18n.T("Application logs will appear here..."))
 	v.logConsole.Wrapping = fyne.TextWrapOff // Keep lines intact
 	v.logConsole.MultiLine = true
 	
 
+	v.providerStatus = widget.NewLabel(i18n.T("Not checked yet."))
+	v.probeButton = widget.NewButton(i18n.T("Check Now"), v.probeProviders)
+	v.showProviderStatus(inference.ProviderStatuses())
+	inference.OnProviderStatus(func(statuses []inference.ProviderStatus) {
+		runOnUI(func() { v.showProviderStatus(statuses) })
+	})
+
 	// --- Update Layout ---
 	topPanel := container.NewVBox(
-		widget.NewLabel("Test Inference Mechanisms"),
+		widget.NewLabel(i18n.T("Test Inference Mechanisms")),
 		v.fallbackButton,
 		v.testMOAButton, // Add MOA button
 		v.testGeminiButton, // Add Gemini button
+		widget.NewSeparator(),
+		newReadingOrderBorder(nil, nil, widget.NewLabel(i18n.T("Provider status:")), v.probeButton, v.providerStatus),
 	)
 
-	v.container = container.NewBorder(
-		topPanel,                          // Top
-		nil,                               // Bottom
+	// Log filters apply to both the console and the exports
+	v.levelFilter = widget.NewSelect(logLevelOptions(), func(string) { v.applyLogFilter() })
+	v.levelFilter.SetSelectedIndex(0)
+	v.componentFilter = widget.NewSelect([]string{i18n.T("All components")}, func(string) { v.applyLogFilter() })
+	v.componentFilter.SetSelectedIndex(0)
+	v.searchFilter = widget.NewEntry()
+	v.searchFilter.SetPlaceHolder(i18n.T("Filter log..."))
+	v.searchFilter.OnChanged = func(string) { v.applyLogFilter() }
+	filterBar := newReadingOrderBorder(nil, nil,
+		container.NewHBox(v.levelFilter, v.componentFilter), nil,
+		v.searchFilter,
+	)
+
+	exportButton := widget.NewButton(i18n.T("Export Log"), v.exportLog)
+	exportJSONButton := widget.NewButton(i18n.T("Export JSON"), v.exportLogJSON)
+
+	v.container = newReadingOrderBorder(
+		container.NewVBox(topPanel, filterBar),            // Top
+		container.NewHBox(exportButton, exportJSONButton), // Bottom
 		nil,                               // Left
 		nil,                               // Right
 		container.NewScroll(v.logConsole), // Center - Log console takes remaining space
 	)
 }
 
+// showProviderStatus lists each provider's reachability and latency from
+// the last probe.
+func (v *TestInferenceView) showProviderStatus(statuses []inference.ProviderStatus) {
+	if len(statuses) == 0 {
+		return
+	}
+	var lines []string
+	for _, status := range statuses {
+		state := i18n.T("reachable")
+		if !status.Reachable() {
+			state = i18n.T("unreachable")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s, %s (%s)", status.Provider, state, status.Summary(), status.Checked.Format("15:04:05")))
+	}
+	v.providerStatus.SetText(strings.Join(lines, "\n"))
+}
+
+// probeProviders checks the providers now instead of waiting for the next
+// periodic probe.
+func (v *TestInferenceView) probeProviders() {
+	v.probeButton.Disable()
+	crash.Go("TestInferenceView.probeProviders", func() {
+		statuses := v.inferenceService.ProbeProviders(context.Background())
+		runOnUI(func() {
+			v.probeButton.Enable()
+			if len(statuses) == 0 {
+				v.providerStatus.SetText(i18n.T("No providers are configured."))
+			}
+		})
+	})
+}
+
+// logLevels are the minimum levels offered by the level filter, matching
+// logLevelOptions by index.
+var logLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+func logLevelOptions() []string {
+	return []string{i18n.T("All levels"), i18n.T("Info and above"), i18n.T("Warnings and errors"), i18n.T("Errors only")}
+}
+
+// applyLogFilter passes the filter controls to the console.
+func (v *TestInferenceView) applyLogFilter() {
+	if v.console == nil {
+		return
+	}
+	filter := LogFilter{MinLevel: slog.LevelDebug, Text: v.searchFilter.Text}
+	if i := v.levelFilter.SelectedIndex(); i > 0 {
+		filter.MinLevel = logLevels[i]
+	}
+	if v.componentFilter.SelectedIndex() > 0 {
+		filter.Component = v.componentFilter.Selected
+	}
+	v.console.SetFilter(filter)
+}
+
+// updateComponentOptions lists the components seen so far in the filter.
+func (v *TestInferenceView) updateComponentOptions(components []string) {
+	options := append([]string{i18n.T("All components")}, components...)
+	if len(options) == len(v.componentFilter.Options) {
+		return
+	}
+	v.componentFilter.Options = options
+	v.componentFilter.Refresh()
+}
+
 // handleFallbackTest sends an oversized prompt to trigger the fallback
 func (v *TestInferenceView) handleFallbackTest() {
 	if !v.inferenceService.IsRunning() { /* ... service not running dialog ... */
@@ -124,28 +178,29 @@ func (v *TestInferenceView) handleFallbackTest() {
 	}
 
 	// Create oversized prompt
-	log.Println("UI: Preparing oversized prompt for fallback test...")
+	logger.Info("Test: preparing oversized prompt for fallback test")
 	oversizedPrompt := strings.Repeat("This is part of a very long test prompt designed to exceed the context window limit... ", 300)
-	log.Printf("UI: Oversized prompt length: %d chars", len(oversizedPrompt))
+	logger.Info("Test: oversized prompt built", "chars", len(oversizedPrompt))
 
-	progressMsg := "Sending oversized prompt via Delegator..."
-	log.Printf("UI: Initiating fallback test...")
-	progress := dialog.NewProgressInfinite("Testing Fallback", progressMsg, v.window)
+	progressMsg := i18n.T("Sending oversized prompt via Delegator...")
+	logger.Info("Test: initiating fallback test")
+	progress := dialog.NewProgressInfinite(i18n.T("Testing Fallback"), progressMsg, v.window)
 	progress.Show()
 
 	go func() {
-		defer progress.Hide()
-		// Call GenerateText with empty modelName and instructionText
-		// to trigger default primary/fallback logic in DelegatorService.
-		response, err := v.inferenceService.GenerateText("", oversizedPrompt, "")
+		defer crash.Recover("TestInferenceView.handleFallbackTest")
+		defer runOnUI(progress.Hide)
+		// No model or instruction, to trigger the default primary/fallback
+		// logic in DelegatorService.
+		response, err := v.inferenceService.Generate(oversizedPrompt, inference.GenerateOptions{})
 
 		if err != nil {
-			log.Printf("UI Error: Fallback test failed: %v", err)
-			dialog.ShowError(fmt.Errorf("Fallback test failed:\n%w\n\nCheck log console for details.", err), v.window)
+			logger.Error("Test: fallback test failed", "error", err)
+			runOnUI(func() { ShowError(fmt.Errorf("fallback test failed (check the log console for details): %w", err), v.window) })
 			return
 		}
-		log.Printf("UI: Fallback test completed successfully (response length: %d). Check log console for trace.", len(response))
-		dialog.ShowInformation("Fallback Test Complete", "Request finished. Check the log console below for the trace (Proxy failure -> Base success).", v.window)
+		logger.Info("Test: fallback test completed, check the log console for the trace", "response_chars", len(response))
+		runOnUI(func() { dialog.ShowInformation(i18n.T("Fallback Test Complete"), i18n.T("Request finished. Check the log console below for the trace (Proxy failure -> Base success)."), v.window) })
 	}()
 }
 
@@ -153,31 +208,31 @@ func (v *TestInferenceView) handleFallbackTest() {
 // handleMOATest sends a simple prompt directly to the MOA service
 func (v *TestInferenceView) handleMOATest() {
 	if !v.inferenceService.IsRunning() {
-		dialog.ShowInformation("Service Error", "Inference service is not running. Check settings and logs.", v.window)
+		dialog.ShowInformation(i18n.T("Service Error"), i18n.T("Inference service is not running. Check settings and logs."), v.window)
 		return
 	}
 
 	// Use a simple, standard prompt for MOA testing
 	testPrompt := "Explain the concept of a Mixture of Agents (MOA) in large language models in a concise paragraph."
-	log.Println("UI: Preparing simple prompt for MOA test...")
+	logger.Info("Test: preparing simple prompt for MOA test")
 
-	progressMsg := "Sending prompt directly to MOA..."
-	log.Printf("UI: Initiating MOA test...")
-	progress := dialog.NewProgressInfinite("Testing MOA", progressMsg, v.window)
+	progressMsg := i18n.T("Sending prompt directly to MOA...")
+	logger.Info("Test: initiating MOA test")
+	progress := dialog.NewProgressInfinite(i18n.T("Testing MOA"), progressMsg, v.window)
 	progress.Show()
 
 	go func() {
-		defer progress.Hide()
-		// Call the specific MOA generation method
-		response, err := v.inferenceService.GenerateTextWithMOA(testPrompt, "") // Pass empty instruction
+		defer crash.Recover("TestInferenceView.handleMOATest")
+		defer runOnUI(progress.Hide)
+		response, err := v.inferenceService.Generate(testPrompt, inference.GenerateOptions{UseMOA: true})
 
 		if err != nil {
-			log.Printf("UI Error: MOA test failed: %v", err)
-			dialog.ShowError(fmt.Errorf("MOA test failed:\n%w\n\nCheck log console for details.", err), v.window)
+			logger.Error("Test: MOA test failed", "error", err)
+			runOnUI(func() { ShowError(fmt.Errorf("MOA test failed (check the log console for details): %w", err), v.window) })
 			return
 		}
-		log.Printf("UI: MOA test completed successfully (response length: %d). Check log console for trace.", len(response))
-		dialog.ShowInformation("MOA Test Complete", "Request finished via MOA. Check the log console below for the trace.", v.window)
+		logger.Info("Test: MOA test completed, check the log console for the trace", "response_chars", len(response))
+		runOnUI(func() { dialog.ShowInformation(i18n.T("MOA Test Complete"), i18n.T("Request finished via MOA. Check the log console below for the trace."), v.window) })
 		// Optionally, display the MOA response somewhere if needed,
 		// but the primary goal here is observing the logs.
 	}()
@@ -187,36 +242,37 @@ func (v *TestInferenceView) handleMOATest() {
 // handleGeminiTest sends a simple prompt directly to the configured Gemini provider
 func (v *TestInferenceView) handleGeminiTest() {
 	if !v.inferenceService.IsRunning() {
-		dialog.ShowInformation("Service Error", "Inference service is not running. Check settings and logs.", v.window)
+		dialog.ShowInformation(i18n.T("Service Error"), i18n.T("Inference service is not running. Check settings and logs."), v.window)
 		return
 	}
 
 	// Use a simple, standard prompt for Gemini testing
 	testPrompt := "What is Google Gemini?"
-	log.Println("UI: Preparing simple prompt for Gemini test...")
+	logger.Info("Test: preparing simple prompt for Gemini test")
 
-	progressMsg := "Sending prompt directly to Gemini..."
-	log.Printf("UI: Initiating Gemini test...")
-	progress := dialog.NewProgressInfinite("Testing Gemini", progressMsg, v.window)
+	progressMsg := i18n.T("Sending prompt directly to Gemini...")
+	logger.Info("Test: initiating Gemini test")
+	progress := dialog.NewProgressInfinite(i18n.T("Testing Gemini"), progressMsg, v.window)
 	progress.Show()
 
 	go func() {
-		defer progress.Hide()
-		// Call a new method in InferenceService to target a specific provider
-		response, err := v.inferenceService.GenerateTextWithProvider("gemini", testPrompt)
+		defer crash.Recover("TestInferenceView.handleGeminiTest")
+		defer runOnUI(progress.Hide)
+		// Target the provider directly, bypassing the delegator
+		response, err := v.inferenceService.Generate(testPrompt, inference.GenerateOptions{Provider: "gemini"})
 
 		if err != nil {
-			log.Printf("UI Error: Gemini test failed: %v", err)
+			logger.Error("Test: Gemini test failed", "error", err)
 			// Check specifically for the 404 error we saw earlier
 			if strings.Contains(err.Error(), "status 404") {
-				dialog.ShowError(fmt.Errorf("Gemini test failed with 404 Not Found.\nPlease check the API endpoint configuration in gemini_provider.go.\n\nError: %w", err), v.window)
+				runOnUI(func() { ShowError(fmt.Errorf("Gemini test failed with 404 Not Found.\nPlease check the API endpoint configuration in gemini_provider.go.\n\nError: %w", err), v.window) })
 			} else {
-				dialog.ShowError(fmt.Errorf("Gemini test failed:\n%w\n\nCheck log console for details.", err), v.window)
+				runOnUI(func() { ShowError(fmt.Errorf("Gemini test failed (check the log console for details): %w", err), v.window) })
 			}
 			return
 		}
-		log.Printf("UI: Gemini test completed successfully (response length: %d). Check log console for trace.", len(response))
-		dialog.ShowInformation("Gemini Test Complete", "Request finished via Gemini. Check the log console below for the trace.", v.window)
+		logger.Info("Test: Gemini test completed, check the log console for the trace", "response_chars", len(response))
+		runOnUI(func() { dialog.ShowInformation(i18n.T("Gemini Test Complete"), i18n.T("Request finished via Gemini. Check the log console below for the trace."), v.window) })
 	}()
 }
 // --- End Added ---
@@ -226,7 +282,47 @@ func (v *TestInferenceView) Container() fyne.CanvasObject {
 	return v.container
 }
 
-// LogConsoleWidget returns the log console widget for log redirection
+// SetLogConsole connects the view to the console collecting the session's
+// log records, for filtering and export.
+func (v *TestInferenceView) SetLogConsole(c *LogConsole) {
+	v.console = c
+	c.OnComponentsChanged = v.updateComponentOptions
+	v.applyLogFilter()
+}
+
+// exportLog saves the records matching the filters to a timestamped text file
+func (v *TestInferenceView) exportLog() {
+	content := v.logConsole.Text
+	if v.console != nil {
+		content = recordsText(v.console.Records())
+	}
+	if content == "" {
+		dialog.ShowInformation(i18n.T("Export Log"), i18n.T("The log is empty."), v.window)
+		return
+	}
+	exportTextToFile(v.window, "Log", "inference-engine-log", "txt", content)
+}
+
+// exportLogJSON saves the records matching the filters as JSON Lines, one
+// object per record with its structured fields.
+func (v *TestInferenceView) exportLogJSON() {
+	var records []logging.Record
+	if v.console != nil {
+		records = v.console.Records()
+	}
+	if len(records) == 0 {
+		dialog.ShowInformation(i18n.T("Export Log"), i18n.T("The log is empty."), v.window)
+		return
+	}
+	content, err := recordsJSONLines(records)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	exportTextToFile(v.window, "Log", "inference-engine-log", "jsonl", content)
+}
+
+// LogConsoleWidget returns the log console widget that shows the captured records
 func (v *TestInferenceView) LogConsoleWidget() *widget.Entry {
 	return v.logConsole
 }
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+// newCountLabel creates a small label showing word/character/token counts for text.
+func newCountLabel() *widget.Label {
+	label := widget.NewLabel(textStatsSummary(""))
+	label.Importance = widget.LowImportance
+	return label
+}
+
+// textStatsSummary formats live counts for an editor, e.g. "120 words · 640 chars · ~150 tokens".
+func textStatsSummary(text string) string {
+	return fmt.Sprintf("%d words · %d chars · ~%d tokens",
+		len(strings.Fields(text)),
+		utf8.RuneCountInString(text),
+		inference.EstimateTokenCount(text),
+	)
+}
+
+// tokenUsageSummary formats the tokens a generation used, e.g. "1200 prompt +
+// 350 completion tokens", marking estimates with "~".
+func tokenUsageSummary(usage inference.TokenUsage) string {
+	if usage.Estimated {
+		return i18n.Tf("~%d prompt + ~%d completion tokens (estimated)", usage.PromptTokens, usage.CompletionTokens)
+	}
+	return i18n.Tf("%d prompt + %d completion tokens", usage.PromptTokens, usage.CompletionTokens)
+}
@@ -2,12 +2,145 @@
 package ui
 
 import (
+	"fmt"
 	"image/color"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/settings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/theme"
 )
 
+// Theme names stored in the PrefTheme setting.
+const (
+	ThemeSystem       = "System Default"
+	ThemeLight        = "Light"
+	ThemeDark         = "Dark"
+	ThemeHighContrast = "High Contrast"
+)
+
+// Appearance settings: the theme name, and the text and overall UI scale
+// factors (1.0 = 100%).
+var (
+	PrefTheme     = settings.NewKey("ui.theme", ThemeHighContrast)
+	PrefFontScale = settings.NewKey("ui.font_scale", 1.0)
+	PrefUIScale   = settings.NewKey("ui.scale", 1.0)
+)
+
+// ThemeNames lists the selectable themes in display order.
+var ThemeNames = []string{ThemeSystem, ThemeLight, ThemeDark, ThemeHighContrast}
+
+// ScaleOptions lists the selectable scale factors, in percent.
+var ScaleOptions = []string{"80%", "90%", "100%", "110%", "125%", "150%", "175%", "200%"}
+
+// ThemeForName returns the fyne.Theme for a theme name, defaulting to high contrast.
+func ThemeForName(name string) fyne.Theme {
+	switch name {
+	case ThemeSystem:
+		return theme.DefaultTheme()
+	case ThemeLight:
+		return theme.LightTheme()
+	case ThemeDark:
+		return theme.DarkTheme()
+	default:
+		return &HighContrastTheme{}
+	}
+}
+
+// ApplyTheme sets the named theme on the app and persists the choice.
+// Fyne refreshes all open windows, so no restart is needed.
+func ApplyTheme(a fyne.App, name string) {
+	PrefTheme.Set(name)
+	applySavedTheme(a)
+}
+
+// ApplyScale persists the font and UI scale factors and re-applies the current theme.
+func ApplyScale(a fyne.App, fontScale, uiScale float32) {
+	PrefFontScale.Set(float64(fontScale))
+	PrefUIScale.Set(float64(uiScale))
+	applySavedTheme(a)
+}
+
+// SavedThemeName returns the persisted theme name, or high contrast if none was saved.
+func SavedThemeName() string {
+	return PrefTheme.Get()
+}
+
+// SavedScales returns the persisted font and UI scale factors, defaulting to 1.0.
+func SavedScales() (fontScale, uiScale float32) {
+	return float32(PrefFontScale.Get()), float32(PrefUIScale.Get())
+}
+
+// applySavedTheme builds the theme from the saved preferences and sets it on the app.
+func applySavedTheme(a fyne.App) {
+	fontScale, uiScale := SavedScales()
+	a.Settings().SetTheme(NewScaledTheme(ThemeForName(SavedThemeName()), fontScale, uiScale))
+}
+
+// ScaleLabel formats a scale factor as one of the ScaleOptions labels (e.g. "125%").
+func ScaleLabel(scale float32) string {
+	return fmt.Sprintf("%d%%", int(scale*100+0.5))
+}
+
+// ParseScaleLabel parses a label such as "125%" into a scale factor, returning 1.0 if invalid.
+func ParseScaleLabel(label string) float32 {
+	pct, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(label), "%"))
+	if err != nil || pct <= 0 {
+		return 1
+	}
+	return float32(pct) / 100
+}
+
+// ScaledTheme wraps another theme and multiplies its sizes. FontScale applies
+// to text sizes only; UIScale applies to every size (text, padding, icons...).
+type ScaledTheme struct {
+	Base      fyne.Theme
+	FontScale float32
+	UIScale   float32
+}
+
+// Ensure ScaledTheme implements fyne.Theme
+var _ fyne.Theme = (*ScaledTheme)(nil)
+
+// NewScaledTheme creates a ScaledTheme, treating non-positive scales as 1.0.
+func NewScaledTheme(base fyne.Theme, fontScale, uiScale float32) *ScaledTheme {
+	if fontScale <= 0 {
+		fontScale = 1
+	}
+	if uiScale <= 0 {
+		uiScale = 1
+	}
+	return &ScaledTheme{Base: base, FontScale: fontScale, UIScale: uiScale}
+}
+
+// Color returns the base theme's color.
+func (t *ScaledTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	return t.Base.Color(name, variant)
+}
+
+// Font returns the base theme's font.
+func (t *ScaledTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return t.Base.Font(style)
+}
+
+// Icon returns the base theme's icon.
+func (t *ScaledTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.Base.Icon(name)
+}
+
+// Size returns the base theme's size multiplied by the configured scale factors.
+func (t *ScaledTheme) Size(name fyne.ThemeSizeName) float32 {
+	size := t.Base.Size(name) * t.UIScale
+	switch name {
+	case theme.SizeNameText, theme.SizeNameHeadingText, theme.SizeNameSubHeadingText,
+		theme.SizeNameCaptionText, theme.SizeNameInlineIcon:
+		size *= t.FontScale
+	}
+	return size
+}
+
 // HighContrastTheme defines a custom high-contrast theme.
 type HighContrastTheme struct{}
 
@@ -0,0 +1,380 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"Inference_Engine/analytics"
+	"Inference_Engine/brief"
+	"Inference_Engine/crash"
+	"Inference_Engine/embeddings"
+	"Inference_Engine/i18n"
+	"Inference_Engine/inference"
+	"Inference_Engine/jobs"
+	"Inference_Engine/planning"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxPlanTopPages is how many of the site's most visited pages are sent
+// with a plan request.
+const maxPlanTopPages = 15
+
+// TopicPlannerView clusters a keyword list by meaning and has the model plan
+// one article per cluster, as a content brief for the generator.
+type TopicPlannerView struct {
+	container        fyne.CanvasObject
+	wpService        *wordpress.WordPressService
+	inferenceService *inference.InferenceService
+	jobQueue         *jobs.Queue
+	generatorView    *ContentGeneratorView
+	analytics        *analytics.Service // The site's top pages, sent with plan requests; nil disables it
+	window           fyne.Window
+
+	keywordsEntry  *widget.Entry
+	importButton   *widget.Button
+	thresholdEntry *widget.Entry
+	clusterButton  *widget.Button
+	statusLabel    *widget.Label
+	clusterList    *widget.List
+	detailLabel    *widget.Label
+	planButton     *widget.Button
+
+	// Data
+	clusters []planning.Cluster
+	selected int
+}
+
+// NewTopicPlannerView creates a new TopicPlannerView
+func NewTopicPlannerView(wpService *wordpress.WordPressService, inferenceService *inference.InferenceService, window fyne.Window) *TopicPlannerView {
+	view := &TopicPlannerView{
+		wpService:        wpService,
+		inferenceService: inferenceService,
+		window:           window,
+		selected:         -1,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *TopicPlannerView) initialize() {
+	v.keywordsEntry = widget.NewMultiLineEntry()
+	v.keywordsEntry.SetPlaceHolder(i18n.T("Paste keywords, one per line, or import a CSV export from a keyword tool or Search Console."))
+	v.importButton = widget.NewButton(i18n.T("Import CSV"), v.importCSV)
+	v.thresholdEntry = widget.NewEntry()
+	v.thresholdEntry.SetText(strconv.Itoa(int(planning.DefaultClusterThreshold * 100)))
+	v.clusterButton = widget.NewButton(i18n.T("Cluster Keywords"), v.cluster)
+	v.statusLabel = widget.NewLabel(i18n.T("Keywords that searchers use for the same topic are grouped so one article can target them all."))
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+
+	v.clusterList = widget.NewList(
+		func() int {
+			return len(v.clusters)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template keyword cluster")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.clusters) {
+				return
+			}
+			cluster := v.clusters[id]
+			text := i18n.Tf("%s (%d keywords)", cluster.Label, len(cluster.Keywords))
+			if cluster.Plan != nil {
+				text += "  ✓ " + cluster.Plan.Title
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	v.clusterList.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.updateDetails()
+	}
+	v.clusterList.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.updateDetails()
+	}
+
+	v.detailLabel = widget.NewLabel("")
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.planButton = widget.NewButton(i18n.T("Plan Article"), v.plan)
+
+	keywordsPanel := newReadingOrderBorder(
+		nil, // Top
+		container.NewVBox( // Bottom
+			widget.NewForm(widget.NewFormItem(i18n.T("Similarity at least (%):"), v.thresholdEntry)),
+			container.NewHBox(v.importButton, layout.NewSpacer(), v.clusterButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.keywordsEntry,
+	)
+	clustersPanel := newReadingOrderBorder(
+		v.statusLabel, // Top
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			v.detailLabel,
+			container.NewHBox(layout.NewSpacer(), v.planButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.clusterList,
+	)
+	split := container.NewHSplit(keywordsPanel, clustersPanel)
+	split.Offset = 0.35
+	rememberSplit("topics.keywords", split)
+	v.container = split
+	v.updateDetails()
+}
+
+// SetJobQueue sets the queue that clustering and planning are submitted to
+func (v *TopicPlannerView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetContentGeneratorView sets the view that article plans are sent to
+func (v *TopicPlannerView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
+	v.generatorView = generatorView
+}
+
+// SetAnalytics sets the service whose top pages plans link to rather than
+// repeat.
+func (v *TopicPlannerView) SetAnalytics(service *analytics.Service) {
+	v.analytics = service
+}
+
+// topPages lists the connected site's most visited pages for a plan
+// request, empty without analytics.
+func (v *TopicPlannerView) topPages(ctx context.Context) string {
+	if v.analytics == nil || !v.wpService.IsConnected() {
+		return ""
+	}
+	trends, err := v.analytics.Trends(ctx, v.wpService.GetSiteURL())
+	if err != nil {
+		logger.Warn("TopicPlannerView: planning without analytics", "error", err)
+		return ""
+	}
+	var lines []string
+	for _, trend := range analytics.Top(trends, maxPlanTopPages) {
+		lines = append(lines, fmt.Sprintf("- %s: %d views", trend.Path, trend.Views))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// threshold returns the entered similarity as a fraction, or the default if
+// the entry isn't a percentage.
+func (v *TopicPlannerView) threshold() float64 {
+	percent, err := strconv.Atoi(strings.TrimSpace(v.thresholdEntry.Text))
+	if err != nil || percent <= 0 || percent > 100 {
+		return planning.DefaultClusterThreshold
+	}
+	return float64(percent) / 100
+}
+
+// importCSV loads a keyword list from a CSV or text file into the entry.
+func (v *TopicPlannerView) importCSV() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			ShowError(fmt.Errorf("failed to read keywords: %w", err), v.window)
+			return
+		}
+		keywords, err := planning.ParseKeywords(string(data))
+		if err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		v.keywordsEntry.SetText(strings.Join(keywords, "\n"))
+		logger.Info("TopicPlannerView: imported keywords", "file", reader.URI().Name(), "count", len(keywords))
+	}, v.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".tsv", ".txt"}))
+	open.Show()
+}
+
+// cluster embeds the entered keywords and groups them in the background.
+func (v *TopicPlannerView) cluster() {
+	keywords, err := planning.ParseKeywords(v.keywordsEntry.Text)
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	threshold := v.threshold()
+	v.clusterButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.clusterButton.Enable)
+		progress(-1, i18n.Tf("Embedding %d keywords", len(keywords)))
+		clusters, err := planning.ClusterKeywords(ctx, embeddings.Default(), keywords, threshold)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runOnUI(func() {
+			if err != nil {
+				ShowError(err, v.window)
+				return
+			}
+			v.clusters = clusters
+			v.statusLabel.SetText(i18n.Tf("%d keywords in %d clusters.", len(keywords), len(clusters)))
+			v.selected = -1
+			v.clusterList.UnselectAll()
+			v.clusterList.Refresh()
+			v.updateDetails()
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("TopicPlannerView.cluster", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Keyword Clustering", i18n.Tf("%d keywords", len(keywords)), run)
+}
+
+// updateDetails lists the selected cluster's keywords.
+func (v *TopicPlannerView) updateDetails() {
+	if v.selected < 0 || v.selected >= len(v.clusters) {
+		v.detailLabel.SetText(i18n.T("Select a cluster to plan an article for it."))
+		v.planButton.Disable()
+		return
+	}
+	cluster := v.clusters[v.selected]
+	v.detailLabel.SetText(strings.Join(cluster.Keywords, ", "))
+	if cluster.Plan != nil {
+		v.planButton.SetText(i18n.T("Show Plan"))
+	} else {
+		v.planButton.SetText(i18n.T("Plan Article"))
+	}
+	v.planButton.Enable()
+}
+
+// plan shows the selected cluster's article plan, asking the model for one
+// first if it has none.
+func (v *TopicPlannerView) plan() {
+	if v.selected < 0 || v.selected >= len(v.clusters) {
+		return
+	}
+	if plan := v.clusters[v.selected].Plan; plan != nil {
+		v.showPlan(v.selected, *plan)
+		return
+	}
+	v.requestPlan(v.selected)
+}
+
+// requestPlan has the model plan an article for a cluster in the background.
+func (v *TopicPlannerView) requestPlan(index int) {
+	cluster := v.clusters[index]
+	v.planButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.updateDetails)
+		progress(-1, i18n.T("Planning"))
+		prompt := inference.GetArticlePlanPrompt(v.topPages(ctx), strings.Join(cluster.Keywords, "\n"))
+		output, err := v.inferenceService.Generate(prompt, inference.GenerateOptions{Context: ctx, Task: inference.TaskStructured})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var plan brief.Brief
+		if err == nil {
+			plan, err = planning.ParsePlan(output)
+		}
+		if err != nil {
+			runOnUI(func() { ShowError(fmt.Errorf("failed to plan an article for '%s': %w", cluster.Label, err), v.window) })
+			return err
+		}
+		runOnUI(func() {
+			// The list may have been re-clustered while the model was planning
+			if index >= len(v.clusters) || v.clusters[index].Label != cluster.Label {
+				return
+			}
+			v.clusters[index].Plan = &plan
+			v.clusterList.RefreshItem(index)
+			v.showPlan(index, plan)
+		})
+		return nil
+	}
+	if v.jobQueue == nil {
+		crash.Go("TopicPlannerView.requestPlan", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Article Plan", cluster.Label, run)
+}
+
+// showPlan shows a cluster's plan as an editable content brief, which can be
+// exported or sent to the generator.
+func (v *TopicPlannerView) showPlan(index int, plan brief.Brief) {
+	text, err := plan.YAML()
+	if err != nil {
+		ShowError(err, v.window)
+		return
+	}
+	editor := NewEditorEntry()
+	editor.SetText(text)
+	editor.SetMinRowsVisible(18)
+	header := widget.NewLabel(i18n.Tf("Keywords: %s", strings.Join(v.clusters[index].Keywords, ", ")))
+	header.Wrapping = fyne.TextWrapWord
+
+	// edited parses the editor's brief and keeps it as the cluster's plan
+	edited := func() (brief.Brief, bool) {
+		b, err := brief.Parse([]byte(editor.Text))
+		if err != nil {
+			ShowError(err, v.window)
+			return brief.Brief{}, false
+		}
+		if index < len(v.clusters) {
+			v.clusters[index].Plan = &b
+			v.clusterList.RefreshItem(index)
+		}
+		return b, true
+	}
+
+	var d *dialog.CustomDialog
+	d = dialog.NewCustomWithoutButtons(i18n.T("Article Plan"), newReadingOrderBorder(header, nil, nil, nil, editor), v.window)
+	buttons := []fyne.CanvasObject{
+		widget.NewButton(i18n.T("Close"), func() { d.Hide() }),
+		widget.NewButton(i18n.T("Plan Again"), func() {
+			d.Hide()
+			v.requestPlan(index)
+		}),
+		widget.NewButton(i18n.T("Export Brief"), func() {
+			if _, ok := edited(); ok {
+				exportTextToFile(v.window, i18n.T("Export Brief"), "brief", "yaml", editor.Text)
+			}
+		}),
+	}
+	if v.generatorView != nil {
+		buttons = append(buttons, widget.NewButton(i18n.T("Send to Generator"), func() {
+			b, ok := edited()
+			if !ok {
+				return
+			}
+			v.generatorView.ApplyBrief(b)
+			d.Hide()
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.T("The article plan is in the content generator's prompt and SEO targets."), v.window)
+		}))
+	}
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(720, 620))
+	d.Show()
+}
+
+// Container returns the container for the view
+func (v *TopicPlannerView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"Inference_Engine/analytics"
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/jobs"
+	"Inference_Engine/wordpress"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxTopPages is how many of the most visited pages the traffic report
+// lists.
+const maxTopPages = 50
+
+// trafficPage is a page's views, with the WordPress page when the path
+// matches one.
+type trafficPage struct {
+	analytics.PageTrend
+	page *wordpress.Page
+}
+
+// title returns the page's title, or its path if it isn't a known page.
+func (p trafficPage) title() string {
+	if p.page != nil {
+		return p.page.Title
+	}
+	return p.Path
+}
+
+// TrafficView lists the connected site's most visited pages and those whose
+// traffic is declining, from Google Analytics or Jetpack Stats, and prepares
+// a refresh of one in the generator.
+type TrafficView struct {
+	container     fyne.CanvasObject
+	wpService     *wordpress.WordPressService
+	analytics     *analytics.Service // nil when no analytics are configured
+	jobQueue      *jobs.Queue
+	generatorView *ContentGeneratorView
+	window        fyne.Window
+
+	modeSelect    *widget.Select
+	loadButton    *widget.Button
+	statusLabel   *widget.Label
+	pageList      *widget.List
+	detailLabel   *widget.Label
+	refreshButton *widget.Button
+
+	// Data
+	top       []trafficPage
+	declining []trafficPage
+	shown     []trafficPage
+	selected  int
+}
+
+// NewTrafficView creates a new TrafficView
+func NewTrafficView(wpService *wordpress.WordPressService, window fyne.Window) *TrafficView {
+	view := &TrafficView{
+		wpService: wpService,
+		window:    window,
+		selected:  -1,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize sets up the UI elements for the view
+func (v *TrafficView) initialize() {
+	v.modeSelect = widget.NewSelect([]string{i18n.T("Declining pages"), i18n.T("Top pages")}, func(string) { v.show() })
+	v.loadButton = widget.NewButton(i18n.T("Load Analytics"), v.load)
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+
+	v.pageList = widget.NewList(
+		func() int {
+			return len(v.shown)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template traffic page")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(v.shown) {
+				return
+			}
+			page := v.shown[id]
+			obj.(*widget.Label).SetText(i18n.Tf("%s  %d views (%+.0f%%)", page.title(), page.Views, page.Change()*100))
+		},
+	)
+	v.pageList.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.updateDetails()
+	}
+	v.pageList.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.updateDetails()
+	}
+
+	v.detailLabel = widget.NewLabel("")
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.refreshButton = widget.NewButton(i18n.T("Refresh in Generator"), v.refreshInGenerator)
+
+	v.container = newReadingOrderBorder(
+		container.NewVBox( // Top
+			container.NewBorder(nil, nil, nil, v.loadButton, v.modeSelect),
+			v.statusLabel,
+		),
+		container.NewVBox( // Bottom
+			widget.NewSeparator(),
+			v.detailLabel,
+			container.NewHBox(layout.NewSpacer(), v.refreshButton),
+		),
+		nil, // Left
+		nil, // Right
+		v.pageList,
+	)
+	v.modeSelect.SetSelectedIndex(0)
+	v.SiteChanged()
+}
+
+// SetJobQueue sets the queue that loading is submitted to
+func (v *TrafficView) SetJobQueue(queue *jobs.Queue) {
+	v.jobQueue = queue
+}
+
+// SetContentGeneratorView sets the view that refreshes are prepared in
+func (v *TrafficView) SetContentGeneratorView(generatorView *ContentGeneratorView) {
+	v.generatorView = generatorView
+}
+
+// SetAnalytics sets the service the page views are read from.
+func (v *TrafficView) SetAnalytics(service *analytics.Service) {
+	v.analytics = service
+	v.SiteChanged()
+}
+
+// SiteChanged clears the previous site's pages.
+func (v *TrafficView) SiteChanged() {
+	v.top, v.declining = nil, nil
+	if v.analytics == nil {
+		v.statusLabel.SetText(i18n.T("No analytics are configured. Set GA4_PROPERTY_ID or JETPACK_STATS_TOKEN to see which pages gain and lose traffic."))
+		v.loadButton.Disable()
+	} else {
+		v.statusLabel.SetText(i18n.Tf("Click \"Load Analytics\" to read the last %d days of page views from %s.", analytics.DefaultDays, v.analytics.SourceName()))
+		v.loadButton.Enable()
+	}
+	v.show()
+}
+
+// show lists the pages of the selected mode.
+func (v *TrafficView) show() {
+	if v.modeSelect.SelectedIndex() == 1 {
+		v.shown = v.top
+	} else {
+		v.shown = v.declining
+	}
+	v.selected = -1
+	v.pageList.UnselectAll()
+	v.pageList.Refresh()
+	v.updateDetails()
+}
+
+// updateDetails shows the selected page's link and views.
+func (v *TrafficView) updateDetails() {
+	if v.selected < 0 || v.selected >= len(v.shown) {
+		v.detailLabel.SetText(i18n.T("Select a page to refresh it in the generator."))
+		v.refreshButton.Disable()
+		return
+	}
+	page := v.shown[v.selected]
+	link := page.Path
+	if page.page != nil {
+		link = page.page.Link
+	}
+	v.detailLabel.SetText(fmt.Sprintf("%s\n%s\n%s", page.title(), link,
+		i18n.Tf("Views in the last %d days: %d (previous %d days: %d)", analytics.DefaultDays, page.Views, analytics.DefaultDays, page.PreviousViews)))
+	if page.page != nil && v.generatorView != nil {
+		v.refreshButton.Enable()
+	} else {
+		v.refreshButton.Disable()
+	}
+}
+
+// load reads the site's page views and matches them to its pages in the
+// background.
+func (v *TrafficView) load() {
+	if !v.wpService.IsConnected() {
+		ShowError(fmt.Errorf("not connected to WordPress site"), v.window)
+		return
+	}
+	siteURL := v.wpService.GetSiteURL()
+	v.loadButton.Disable()
+
+	run := func(ctx context.Context, progress jobs.ProgressFunc) error {
+		defer runOnUI(v.loadButton.Enable)
+		progress(-1, i18n.T("Reading page views"))
+		trends, err := v.analytics.Trends(ctx, siteURL)
+		var pages wordpress.PageList
+		if err == nil {
+			progress(-1, i18n.T("Fetching pages"))
+			pages, err = v.wpService.GetPages(1, 100)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runOnUI(func() {
+			if err != nil {
+				ShowError(fmt.Errorf("failed to load analytics: %w", err), v.window)
+				return
+			}
+			byPath := map[string]*wordpress.Page{}
+			for i := range pages {
+				byPath[analytics.Path(pages[i].Link)] = &pages[i]
+			}
+			v.top, v.declining = nil, nil
+			for _, trend := range analytics.Top(trends, maxTopPages) {
+				v.top = append(v.top, trafficPage{trend, byPath[trend.Path]})
+			}
+			for _, trend := range analytics.Declining(trends) {
+				v.declining = append(v.declining, trafficPage{trend, byPath[trend.Path]})
+			}
+			v.statusLabel.SetText(i18n.Tf("%d pages viewed in the last %d days; %d are declining.", len(trends), analytics.DefaultDays, len(v.declining)))
+			v.show()
+		})
+		return err
+	}
+	if v.jobQueue == nil {
+		crash.Go("TrafficView.load", func() { run(context.Background(), func(float64, string) {}) })
+		return
+	}
+	v.jobQueue.Submit("Analytics", v.analytics.SourceName(), run)
+}
+
+// refreshInGenerator adds the selected page to the generator as a True
+// source, with a request to refresh it in light of its traffic.
+func (v *TrafficView) refreshInGenerator() {
+	if v.selected < 0 || v.selected >= len(v.shown) || v.shown[v.selected].page == nil || v.generatorView == nil {
+		return
+	}
+	selected := v.shown[v.selected]
+	page := *selected.page
+	progress := dialog.NewProgressInfinite(i18n.T("Loading Content"), i18n.T("Fetching page content for generator..."), v.window)
+	progress.Show()
+	go func() {
+		defer crash.Recover("TrafficView.refreshInGenerator")
+		content, err := v.wpService.GetPageContent(page.ID)
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				ShowError(fmt.Errorf("failed to load content for '%s': %w", page.Title, err), v.window)
+				return
+			}
+			v.generatorView.AddSourceContent(page.Title, content, "WordPress", page.Link, page.ID, false)
+			v.generatorView.PrefillRequest(selected.RefreshPrompt(page.Title), "")
+			dialog.ShowInformation(i18n.T("Content Added"), i18n.Tf("Added '%s' and its refresh request to the content generator.", page.Title), v.window)
+		})
+	}()
+}
+
+// Container returns the container for the view
+func (v *TrafficView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"Inference_Engine/i18n"
+	"Inference_Engine/jobs"
+	"Inference_Engine/settings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// PrefMinimizeToTray is the setting for hiding the window to the system
+// tray on close, so background jobs keep running.
+var PrefMinimizeToTray = settings.NewKey("app.minimize_to_tray", false)
+
+// MinimizeToTray reports whether closing the window should hide it to the tray.
+func MinimizeToTray() bool {
+	return PrefMinimizeToTray.Get()
+}
+
+// SetMinimizeToTray persists the minimize-to-tray preference.
+func SetMinimizeToTray(enabled bool) {
+	PrefMinimizeToTray.Set(enabled)
+}
+
+// SetupSystemTray adds a tray icon with quick actions for the window and job
+// queue. It returns false if the driver has no system tray support.
+// Fyne appends its own Quit item to the menu.
+func SetupSystemTray(a fyne.App, w fyne.Window, queue *jobs.Queue) bool {
+	desk, ok := a.(desktop.App)
+	if !ok {
+		logger.Info("System tray not supported by this driver")
+		return false
+	}
+
+	lastJob := fyne.NewMenuItem(i18n.T("No jobs yet"), nil)
+	lastJob.Disabled = true
+
+	var pauseItem *fyne.MenuItem
+	pauseItem = fyne.NewMenuItem(i18n.T("Pause Jobs"), func() {
+		if queue.Paused() {
+			queue.Resume()
+		} else {
+			queue.Pause()
+		}
+	})
+
+	menu := fyne.NewMenu("Wordpress Inference Engine",
+		fyne.NewMenuItem(i18n.T("Open Window"), func() {
+			w.Show()
+			w.RequestFocus()
+		}),
+		fyne.NewMenuItemSeparator(),
+		pauseItem,
+		lastJob,
+	)
+
+	update := func() {
+		pauseLabel := i18n.T("Pause Jobs")
+		if queue.Paused() {
+			pauseLabel = i18n.T("Resume Jobs")
+		}
+		lastLabel := i18n.T("No jobs yet")
+		if job, ok := queue.LastFinished(); ok {
+			lastLabel = i18n.Tf("Last job: %s — %s", truncateUTF8(job.Title, 40), job.Status)
+		}
+		if pauseItem.Label == pauseLabel && lastJob.Label == lastLabel {
+			return // Progress updates don't change the tray menu
+		}
+		pauseItem.Label = pauseLabel
+		lastJob.Label = lastLabel
+		menu.Refresh()
+	}
+	queue.OnChange(func() { runOnUI(update) })
+
+	desk.SetSystemTrayMenu(menu)
+	update()
+	return true
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+	"Inference_Engine/update"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Settings of the update check.
+var (
+	PrefUpdateCheck   = settings.NewKey("updates.check_at_startup", true)
+	PrefUpdateSkipped = settings.NewKey("updates.skipped_version", "")
+)
+
+// updateCheckTimeout bounds the release check.
+const updateCheckTimeout = 30 * time.Second
+
+// CheckForUpdatesAtStartup looks for a newer release in the background,
+// unless the user turned the check off, and offers it unless they skipped
+// that version.
+func CheckForUpdatesAtStartup(app fyne.App, checker *update.Checker, window fyne.Window) {
+	if !PrefUpdateCheck.Get() {
+		return
+	}
+	crash.Go("CheckForUpdatesAtStartup", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		release, err := checker.Check(ctx)
+		if err != nil {
+			logger.Warn("Update check failed", "error", err)
+			return
+		}
+		if release == nil || release.Tag == PrefUpdateSkipped.Get() {
+			return
+		}
+		runOnUI(func() { showUpdateDialog(app, checker, release, window) })
+	})
+}
+
+// showUpdateDialog offers a release: install it, open its page, skip it or
+// decide later.
+func showUpdateDialog(app fyne.App, checker *update.Checker, release *update.Release, window fyne.Window) {
+	message := widget.NewLabel(i18n.Tf("Version %s is available; you have %s.", release.Tag, checker.Current))
+	message.Wrapping = fyne.TextWrapWord
+	notes := widget.NewRichTextFromMarkdown(release.Notes)
+	notes.Wrapping = fyne.TextWrapWord
+	notesScroll := container.NewVScroll(notes)
+	notesScroll.SetMinSize(fyne.NewSize(0, 220))
+	body := container.NewBorder(message, nil, nil, nil, notesScroll)
+
+	d := dialog.NewCustomWithoutButtons(i18n.T("Update Available"), body, window)
+	buttons := []fyne.CanvasObject{}
+	if _, ok := release.Binary(); ok {
+		installButton := widget.NewButtonWithIcon(i18n.T("Install Update"), theme.DownloadIcon(), func() {
+			d.Hide()
+			installUpdate(checker, release, window)
+		})
+		installButton.Importance = widget.HighImportance
+		buttons = append(buttons, installButton)
+	}
+	buttons = append(buttons,
+		widget.NewButton(i18n.T("Open Release Page"), func() {
+			if link, err := url.Parse(release.URL); err == nil {
+				app.OpenURL(link)
+			}
+			d.Hide()
+		}),
+		widget.NewButton(i18n.T("Skip This Version"), func() {
+			PrefUpdateSkipped.Set(release.Tag)
+			d.Hide()
+		}),
+		widget.NewButton(i18n.T("Later"), d.Hide),
+	)
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// installUpdate replaces the executable with the release's binary.
+func installUpdate(checker *update.Checker, release *update.Release, window fyne.Window) {
+	progress := dialog.NewProgressInfinite(i18n.T("Updating"), i18n.Tf("Downloading version %s...", release.Tag), window)
+	progress.Show()
+	crash.Go("installUpdate", func() {
+		err := checker.Install(context.Background(), release)
+		runOnUI(func() {
+			progress.Hide()
+			if err != nil {
+				ShowError(fmt.Errorf("failed to install the update: %w", err), window)
+				return
+			}
+			dialog.ShowInformation(i18n.T("Update Installed"), i18n.Tf("Version %s is installed. Restart the app to use it.", release.Tag), window)
+		})
+	})
+}
+
+// UpdateSettingsView shows the running version, turns the startup update
+// check on or off and checks for updates on demand.
+type UpdateSettingsView struct {
+	container *fyne.Container
+	app       fyne.App
+	checker   *update.Checker
+	window    fyne.Window
+
+	checkButton *widget.Button
+	statusLabel *widget.Label
+}
+
+// NewUpdateSettingsView creates a new update settings view
+func NewUpdateSettingsView(app fyne.App, checker *update.Checker, window fyne.Window) *UpdateSettingsView {
+	view := &UpdateSettingsView{
+		app:     app,
+		checker: checker,
+		window:  window,
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the update settings view
+func (v *UpdateSettingsView) initialize() {
+	startupCheck := widget.NewCheck(i18n.T("Check for updates at startup"), PrefUpdateCheck.Set)
+	startupCheck.SetChecked(PrefUpdateCheck.Get())
+	v.checkButton = widget.NewButtonWithIcon(i18n.T("Check Now"), theme.ViewRefreshIcon(), v.checkNow)
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+	if v.checker.Current == "dev" {
+		v.statusLabel.SetText(i18n.T("This is a development build; updates are only checked for released versions."))
+		v.checkButton.Disable()
+	}
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Updates")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.Tf("Version: %s", v.checker.Current)),
+		startupCheck,
+		v.checkButton,
+		v.statusLabel,
+	)
+}
+
+// checkNow looks for a newer release and offers it, even if it was skipped.
+func (v *UpdateSettingsView) checkNow() {
+	v.checkButton.Disable()
+	v.statusLabel.SetText(i18n.T("Checking for updates..."))
+	crash.Go("UpdateSettingsView.checkNow", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		release, err := v.checker.Check(ctx)
+		runOnUI(func() {
+			v.checkButton.Enable()
+			switch {
+			case err != nil:
+				v.statusLabel.SetText("")
+				ShowError(err, v.window)
+			case release == nil:
+				v.statusLabel.SetText(i18n.Tf("You have the latest version (checked %s).", time.Now().Format("15:04")))
+			default:
+				v.statusLabel.SetText(i18n.Tf("Version %s is available.", release.Tag))
+				showUpdateDialog(v.app, v.checker, release, v.window)
+			}
+		})
+	})
+}
+
+// Container returns the container for the view
+func (v *UpdateSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,310 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"Inference_Engine/crash"
+	"Inference_Engine/i18n"
+	"Inference_Engine/settings"
+	"Inference_Engine/vault"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// PrefVaultAutoLock is the setting for the minutes the app may be in the
+// background before the vault locks; 0 never locks it.
+var PrefVaultAutoLock = settings.NewKey("vault.auto_lock_minutes", 15)
+
+// autoLockOptions are the auto-lock delays offered, in minutes.
+var autoLockOptions = []int{0, 5, 15, 30, 60}
+
+// autoLockInterval is how often the vault is checked for inactivity.
+const autoLockInterval = 15 * time.Second
+
+// VaultAutoLockMinutes returns the saved auto-lock delay.
+func VaultAutoLockMinutes() int {
+	return PrefVaultAutoLock.Get()
+}
+
+// autoLockLabel describes an auto-lock delay.
+func autoLockLabel(minutes int) string {
+	if minutes == 0 {
+		return i18n.T("Never")
+	}
+	return i18n.Tf("After %d minutes in the background", minutes)
+}
+
+// VaultLock hides the window behind a master password prompt while the
+// vault is locked, and locks it once the app has been in the background for
+// the auto-lock delay.
+type VaultLock struct {
+	app    fyne.App
+	vault  *vault.Vault
+	window fyne.Window
+
+	mu         sync.Mutex
+	foreground bool
+	onUnlock   []func()
+
+	content       fyne.CanvasObject // The app's content while the lock screen shows
+	passwordEntry *widget.Entry
+	errorLabel    *widget.Label
+}
+
+// NewVaultLock creates the lock for vault and starts watching for
+// inactivity.
+func NewVaultLock(app fyne.App, v *vault.Vault, window fyne.Window) *VaultLock {
+	l := &VaultLock{app: app, vault: v, window: window, foreground: true}
+	app.Lifecycle().SetOnEnteredForeground(func() { l.setForeground(true) })
+	app.Lifecycle().SetOnExitedForeground(func() { l.setForeground(false) })
+	v.OnLock(func() { runOnUI(l.showLockScreen) })
+	crash.Go("VaultLock.watch", l.watch)
+	return l
+}
+
+// OnUnlock registers a function called on the UI goroutine each time the
+// vault is unlocked from the lock screen.
+func (l *VaultLock) OnUnlock(callback func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onUnlock = append(l.onUnlock, callback)
+}
+
+// Lock locks the vault and shows the lock screen.
+func (l *VaultLock) Lock() {
+	if l.vault.Locked() {
+		l.showLockScreen() // Not unlocked yet since startup
+		return
+	}
+	l.vault.Lock()
+}
+
+// setForeground records whether the app has focus. The idle time counts
+// from the moment it loses it.
+func (l *VaultLock) setForeground(foreground bool) {
+	l.mu.Lock()
+	l.foreground = foreground
+	l.mu.Unlock()
+	l.vault.Touch()
+}
+
+// watch locks the vault when the app has been in the background too long.
+func (l *VaultLock) watch() {
+	ticker := time.NewTicker(autoLockInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.mu.Lock()
+		foreground := l.foreground
+		l.mu.Unlock()
+		if foreground {
+			l.vault.Touch()
+			continue
+		}
+		if minutes := VaultAutoLockMinutes(); minutes > 0 && l.vault.LockIfIdle(time.Duration(minutes)*time.Minute) {
+			logger.Info("Vault locked after inactivity", "minutes", minutes)
+		}
+	}
+}
+
+// showLockScreen replaces the window's content with the password prompt.
+func (l *VaultLock) showLockScreen() {
+	if l.content != nil {
+		return
+	}
+	l.content = l.window.Content()
+	l.passwordEntry = widget.NewPasswordEntry()
+	l.passwordEntry.SetPlaceHolder(i18n.T("Master password"))
+	l.passwordEntry.OnSubmitted = func(string) { l.unlock() }
+	l.errorLabel = widget.NewLabel("")
+	unlockButton := widget.NewButtonWithIcon(i18n.T("Unlock"), theme.LoginIcon(), l.unlock)
+	unlockButton.Importance = widget.HighImportance
+
+	form := container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("The vault is locked"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("Enter the master password to use your sites and API keys."), fyne.TextAlignCenter, fyne.TextStyle{}),
+		l.passwordEntry,
+		container.NewHBox(layout.NewSpacer(), unlockButton, layout.NewSpacer()),
+		l.errorLabel,
+	)
+	l.window.SetContent(container.NewCenter(container.NewGridWrap(fyne.NewSize(420, form.MinSize().Height), form)))
+	l.window.Canvas().Focus(l.passwordEntry)
+}
+
+// unlock opens the vault with the entered password and brings back the
+// app's content.
+func (l *VaultLock) unlock() {
+	err := l.vault.Unlock(l.passwordEntry.Text)
+	if errors.Is(err, vault.ErrWrongPassword) {
+		l.errorLabel.SetText(i18n.T("Wrong master password."))
+		l.passwordEntry.SetText("")
+		return
+	}
+	if err != nil {
+		ShowError(err, l.window)
+		return
+	}
+	l.window.SetContent(l.content)
+	l.content, l.passwordEntry = nil, nil
+
+	l.mu.Lock()
+	callbacks := append([]func(){}, l.onUnlock...)
+	l.mu.Unlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// VaultSettingsView creates the vault, changes its master password, locks
+// it and sets the auto-lock delay.
+type VaultSettingsView struct {
+	container *fyne.Container
+	app       fyne.App
+	vault     *vault.Vault
+	lock      *VaultLock
+	window    fyne.Window
+
+	statusLabel    *widget.Label
+	createButton   *widget.Button
+	changeButton   *widget.Button
+	lockButton     *widget.Button
+	autoLockSelect *widget.Select
+
+	onCreated func()
+}
+
+// NewVaultSettingsView creates a new vault settings view
+func NewVaultSettingsView(app fyne.App, v *vault.Vault, lock *VaultLock, window fyne.Window) *VaultSettingsView {
+	view := &VaultSettingsView{
+		app:       app,
+		vault:     v,
+		lock:      lock,
+		window:    window,
+		onCreated: func() {},
+	}
+	view.initialize()
+	return view
+}
+
+// initialize initializes the vault settings view
+func (v *VaultSettingsView) initialize() {
+	v.statusLabel = widget.NewLabel("")
+	v.statusLabel.Wrapping = fyne.TextWrapWord
+	v.createButton = widget.NewButtonWithIcon(i18n.T("Create Vault"), theme.ContentAddIcon(), v.create)
+	v.changeButton = widget.NewButton(i18n.T("Change Master Password"), v.changePassword)
+	v.lockButton = widget.NewButtonWithIcon(i18n.T("Lock Now"), theme.VisibilityOffIcon(), v.lock.Lock)
+
+	var labels []string
+	for _, minutes := range autoLockOptions {
+		labels = append(labels, autoLockLabel(minutes))
+	}
+	v.autoLockSelect = widget.NewSelect(labels, func(selected string) {
+		for _, minutes := range autoLockOptions {
+			if autoLockLabel(minutes) == selected {
+				PrefVaultAutoLock.Set(minutes)
+			}
+		}
+	})
+	v.autoLockSelect.Selected = autoLockLabel(VaultAutoLockMinutes())
+
+	v.container = container.NewVBox(
+		widget.NewLabel(i18n.T("Vault")),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("Keep WordPress application passwords and API keys encrypted with a master password, asked for at startup.")),
+		widget.NewForm(widget.NewFormItem(i18n.T("Auto-lock:"), v.autoLockSelect)),
+		container.NewHBox(v.createButton, v.changeButton, v.lockButton),
+		v.statusLabel,
+	)
+	v.Refresh()
+	v.vault.OnLock(func() { runOnUI(v.Refresh) })
+	v.lock.OnUnlock(v.Refresh)
+}
+
+// SetOnCreated sets the function called once a new vault is created, to
+// move secrets into it.
+func (v *VaultSettingsView) SetOnCreated(callback func()) {
+	v.onCreated = callback
+}
+
+// Refresh shows the vault's state.
+func (v *VaultSettingsView) Refresh() {
+	switch {
+	case !v.vault.Exists():
+		v.statusLabel.SetText(i18n.T("No vault: passwords are kept with the saved sites and API keys in the environment."))
+		v.createButton.Enable()
+		v.changeButton.Disable()
+		v.lockButton.Disable()
+	case v.vault.Locked():
+		v.statusLabel.SetText(i18n.T("The vault is locked."))
+		v.createButton.Disable()
+		v.changeButton.Disable()
+		v.lockButton.Disable()
+	default:
+		v.statusLabel.SetText(i18n.Tf("The vault is unlocked and holds %d secrets.", len(v.vault.Names())))
+		v.createButton.Disable()
+		v.changeButton.Enable()
+		v.lockButton.Enable()
+	}
+}
+
+// create asks for a master password and creates the vault.
+func (v *VaultSettingsView) create() {
+	showNewPasswordDialog(i18n.T("Create Vault"), v.window, func(password string) {
+		if err := v.vault.Create(password); err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		v.onCreated()
+		v.Refresh()
+		dialog.ShowInformation(i18n.T("Vault"), i18n.T("The vault was created and the saved site passwords moved into it. API keys set in the inference settings from now on are kept in it too."), v.window)
+	})
+}
+
+// changePassword asks for a new master password.
+func (v *VaultSettingsView) changePassword() {
+	showNewPasswordDialog(i18n.T("Change Master Password"), v.window, func(password string) {
+		if err := v.vault.ChangePassword(password); err != nil {
+			ShowError(err, v.window)
+			return
+		}
+		dialog.ShowInformation(i18n.T("Vault"), i18n.T("The master password was changed."), v.window)
+	})
+}
+
+// showNewPasswordDialog asks for a master password twice and passes it to
+// onConfirm if both match.
+func showNewPasswordDialog(title string, window fyne.Window, onConfirm func(password string)) {
+	password := widget.NewPasswordEntry()
+	confirm := widget.NewPasswordEntry()
+	hint := widget.NewLabel(i18n.Tf("At least %d characters. It can't be recovered if you forget it.", vault.MinPasswordLength))
+	hint.Wrapping = fyne.TextWrapWord
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Master password:"), password),
+		widget.NewFormItem(i18n.T("Repeat:"), confirm),
+		widget.NewFormItem("", hint),
+	}
+	form := dialog.NewForm(title, i18n.T("OK"), i18n.T("Cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if password.Text != confirm.Text {
+			ShowError(fmt.Errorf("the passwords don't match"), window)
+			return
+		}
+		onConfirm(password.Text)
+	}, window)
+	form.Resize(fyne.NewSize(460, form.MinSize().Height))
+	form.Show()
+}
+
+// Container returns the container for the view
+func (v *VaultSettingsView) Container() fyne.CanvasObject {
+	return v.container
+}
@@ -0,0 +1,332 @@
+// Package update checks GitHub releases for a newer version of the app and
+// can replace the running executable with the release's binary for this
+// platform.
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("update")
+
+// Version is the running version, set when building a release:
+//
+//	go build -ldflags "-X Inference_Engine/update.Version=v1.4.0"
+//
+// Development builds ("dev") never report updates.
+var Version = "dev"
+
+// DefaultRepository is the GitHub repository releases are published to.
+const DefaultRepository = "guiperry/Wordpress-Inference-Engine"
+
+// apiURL is the GitHub API, replaced in tests.
+var apiURL = "https://api.github.com"
+
+// checksumsAsset is the release asset listing "sha256  name" for the
+// binaries, as written by sha256sum.
+const checksumsAsset = "checksums.txt"
+
+// ErrNoBinary is returned by Install when the release has no binary for this
+// platform, only archives or installers to download by hand.
+var ErrNoBinary = errors.New("the release has no binary for this platform")
+
+// ErrNoChecksum is returned by Install when the release publishes no
+// checksums, so its binary can't be verified and is not installed.
+var ErrNoChecksum = errors.New("the release has no checksums to verify its binary")
+
+// Release is a published release.
+type Release struct {
+	Tag       string    `json:"tag_name"`
+	Name      string    `json:"name"`
+	Notes     string    `json:"body"`
+	URL       string    `json:"html_url"` // The release page
+	Published time.Time `json:"published_at"`
+	Assets    []Asset   `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Checker looks for releases newer than the running version.
+type Checker struct {
+	Repository string
+	Current    string
+	client     *http.Client
+}
+
+// NewChecker creates a checker for the running version and the app's
+// repository.
+func NewChecker() *Checker {
+	return &Checker{Repository: DefaultRepository, Current: Version, client: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+// Check returns the latest release if it is newer than the running version,
+// or nil if the app is up to date or is a development build.
+func (c *Checker) Check(ctx context.Context) (*Release, error) {
+	if c.Current == "" || c.Current == "dev" {
+		return nil, nil
+	}
+	release, err := c.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !Newer(release.Tag, c.Current) {
+		logger.Info("App is up to date", "version", c.Current, "latest", release.Tag)
+		return nil, nil
+	}
+	logger.Info("Update available", "version", c.Current, "latest", release.Tag)
+	return release, nil
+}
+
+// Latest returns the latest published release. Drafts and pre-releases are
+// not considered.
+func (c *Checker) Latest(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/latest", apiURL, c.Repository), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to check for updates: HTTP %d - %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to read the latest release: %w", err)
+	}
+	return &release, nil
+}
+
+// Binary returns the release's executable for this platform: an asset named
+// after the OS and architecture, e.g. "wordpress-inference-engine-linux-amd64"
+// or "...-windows-amd64.exe". Archives and installers don't count.
+func (r *Release) Binary() (Asset, bool) {
+	for _, asset := range r.Assets {
+		if matchesPlatform(asset.Name, runtime.GOOS, runtime.GOARCH) {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Install downloads the release's binary for this platform, checks it
+// against the release's checksums, and replaces the running executable. A
+// release without checksums is refused. The new version runs after a restart.
+func (c *Checker) Install(ctx context.Context, release *Release) error {
+	asset, ok := release.Binary()
+	if !ok {
+		return ErrNoBinary
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the running executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to find the running executable: %w", err)
+	}
+
+	want, err := c.checksum(ctx, release, asset.Name)
+	if err != nil {
+		return err
+	}
+	tmp := exe + ".new"
+	sum, err := c.download(ctx, asset.URL, tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if want != sum {
+		os.Remove(tmp)
+		return fmt.Errorf("the downloaded %s doesn't match the release checksum", asset.Name)
+	}
+
+	// Windows can't overwrite a running executable but can rename it
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace the executable: %w", err)
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		os.Rename(old, exe)
+		return fmt.Errorf("failed to replace the executable: %w", err)
+	}
+	logger.Info("Installed update", "version", release.Tag, "path", exe)
+	return nil
+}
+
+// download saves url to path as an executable and returns its SHA-256.
+func (c *Checker) download(ctx context.Context, url, path string) (string, error) {
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("failed to save the update: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download the update: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checksum returns the expected SHA-256 of the named asset from the
+// release's checksums, or ErrNoChecksum if the release has none.
+func (c *Checker) checksum(ctx context.Context, release *Release, name string) (string, error) {
+	for _, asset := range release.Assets {
+		if asset.Name != checksumsAsset {
+			continue
+		}
+		resp, err := c.get(ctx, asset.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+				return strings.ToLower(fields[0]), nil
+			}
+		}
+		return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+	}
+	return "", ErrNoChecksum
+}
+
+// get downloads url, failing on an error status.
+func (c *Checker) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the update: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download the update: HTTP %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// platformNames are the spellings release assets use for each OS and
+// architecture.
+var platformNames = map[string][]string{
+	"darwin":  {"darwin", "macos", "mac"},
+	"windows": {"windows", "win"},
+	"linux":   {"linux"},
+	"amd64":   {"amd64", "x64"},
+	"arm64":   {"arm64", "aarch64"},
+	"386":     {"386", "i386", "x86"},
+}
+
+// notBinary lists the extensions of archives, packages and signatures, which
+// can't replace the executable as they are.
+var notBinary = map[string]bool{
+	".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".xz": true, ".bz2": true, ".7z": true,
+	".dmg": true, ".pkg": true, ".deb": true, ".rpm": true, ".msi": true,
+	".txt": true, ".sha256": true, ".sig": true, ".asc": true, ".pem": true,
+}
+
+// matchesPlatform reports whether an asset name is a bare executable for
+// goos and goarch.
+func matchesPlatform(name, goos, goarch string) bool {
+	name = strings.ReplaceAll(strings.ToLower(name), "x86_64", "amd64") // Before splitting on "_"
+	ext := filepath.Ext(name)
+	if notBinary[ext] || (goos == "windows") != (ext == ".exe") {
+		return false
+	}
+	parts := strings.FieldsFunc(strings.TrimSuffix(name, ".exe"), func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	return hasAny(parts, platformNames[goos], goos) && hasAny(parts, platformNames[goarch], goarch)
+}
+
+// hasAny reports whether parts contains one of names, or fallback.
+func hasAny(parts, names []string, fallback string) bool {
+	if len(names) == 0 {
+		names = []string{fallback}
+	}
+	for _, part := range parts {
+		for _, name := range names {
+			if part == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Newer reports whether version a is newer than b. Versions are compared
+// number by number, ignoring a "v" prefix; a pre-release ("1.2.0-beta") is
+// older than its release.
+func Newer(a, b string) bool {
+	aNums, aPre := parseVersion(a)
+	bNums, bPre := parseVersion(b)
+	for i := 0; i < len(aNums) || i < len(bNums); i++ {
+		var x, y int
+		if i < len(aNums) {
+			x = aNums[i]
+		}
+		if i < len(bNums) {
+			y = bNums[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	if aPre == "" || bPre == "" {
+		return aPre == "" && bPre != ""
+	}
+	return aPre > bPre
+}
+
+// parseVersion splits "v1.2.3-beta+build" into [1 2 3] and "beta".
+func parseVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(part)
+		nums = append(nums, n)
+	}
+	return nums, pre
+}
@@ -0,0 +1,119 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"1.2", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0-beta", true},
+		{"v1.2.0-beta", "v1.2.0", false},
+		{"v1.2.0-rc.2", "v1.2.0-rc.1", true},
+		{"v2.0.0", "v10.0.0", false},
+	} {
+		if got := Newer(tc.a, tc.b); got != tc.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	for _, tc := range []struct {
+		name, goos, goarch string
+		want               bool
+	}{
+		{"wordpress-inference-engine-linux-amd64", "linux", "amd64", true},
+		{"wordpress-inference-engine-v1.2-linux-amd64", "linux", "amd64", true},
+		{"wordpress-inference-engine_Darwin_arm64", "darwin", "arm64", true},
+		{"wordpress-inference-engine-windows-x86_64.exe", "windows", "amd64", true},
+		{"wordpress-inference-engine-linux-x86_64", "linux", "386", false},
+		{"wordpress-inference-engine-windows-amd64.exe", "windows", "amd64", true},
+		{"wordpress-inference-engine-linux-amd64.tar.gz", "linux", "amd64", false},
+		{"wordpress-inference-engine-linux-arm64", "linux", "amd64", false},
+		{"checksums.txt", "linux", "amd64", false},
+	} {
+		if got := matchesPlatform(tc.name, tc.goos, tc.goarch); got != tc.want {
+			t.Errorf("matchesPlatform(%q, %s/%s) = %v, want %v", tc.name, tc.goos, tc.goarch, got, tc.want)
+		}
+	}
+}
+
+func TestCheckFindsNewerRelease(t *testing.T) {
+	binary := []byte("new binary")
+	sum := sha256.Sum256(binary)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/app/releases/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.3.0", "html_url": "https://example.com/release", "body": "Fixes",
+				"assets": [{"name": "checksums.txt", "browser_download_url": "%[1]s/checksums.txt"},
+				           {"name": "app-linux-amd64", "browser_download_url": "%[1]s/app-linux-amd64"}]}`, server.URL)
+		case "/checksums.txt":
+			fmt.Fprintf(w, "%s  app-linux-amd64\n", hex.EncodeToString(sum[:]))
+		case "/app-linux-amd64":
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	apiURL = server.URL
+	defer func() { apiURL = "https://api.github.com" }()
+
+	checker := &Checker{Repository: "owner/app", Current: "v1.2.0", client: server.Client()}
+	release, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if release == nil || release.Tag != "v1.3.0" || release.Notes != "Fixes" {
+		t.Fatalf("Expected release v1.3.0, got %+v", release)
+	}
+	if want, err := checker.checksum(context.Background(), release, "app-linux-amd64"); err != nil || want != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the published checksum, got %q (%v)", want, err)
+	}
+
+	checker.Current = "v1.3.0"
+	if release, err := checker.Check(context.Background()); err != nil || release != nil {
+		t.Errorf("Expected no update for the latest version, got %+v (%v)", release, err)
+	}
+	checker.Current = "dev"
+	if release, err := checker.Check(context.Background()); err != nil || release != nil {
+		t.Errorf("Expected development builds not to check, got %+v (%v)", release, err)
+	}
+}
+
+func TestInstallRefusesReleaseWithoutChecksums(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write([]byte("unverified binary"))
+	}))
+	defer server.Close()
+
+	name := fmt.Sprintf("app-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	release := &Release{Tag: "v1.3.0", Assets: []Asset{{Name: name, URL: server.URL + "/" + name}}}
+	checker := &Checker{Repository: "owner/app", Current: "v1.2.0", client: server.Client()}
+	if err := checker.Install(context.Background(), release); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("Install without checksums = %v, want ErrNoChecksum", err)
+	}
+	if downloads != 0 {
+		t.Errorf("Expected nothing downloaded, got %d requests", downloads)
+	}
+}
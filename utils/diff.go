@@ -0,0 +1,110 @@
+package utils
+
+import "strings"
+
+// DiffOp says whether a line of a diff is kept, removed or added.
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffRemoved
+	DiffAdded
+)
+
+// DiffLine is one line of a diff between two texts.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// maxDiffCells bounds the table the line diff is computed with; longer texts
+// are shown as entirely replaced rather than compared line by line.
+const maxDiffCells = 4_000_000
+
+// DiffLines compares before and after line by line, returning the lines of
+// both in order, each marked as kept, removed or added. Lines common to the
+// start and end are matched first, and the rest by longest common subsequence.
+func DiffLines(before, after string) []DiffLine {
+	a, b := splitLines(before), splitLines(after)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{DiffEqual, line})
+	}
+	diff = append(diff, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{DiffEqual, line})
+	}
+	return diff
+}
+
+// diffMiddle diffs the lines between the common prefix and suffix.
+func diffMiddle(a, b []string) []DiffLine {
+	var diff []DiffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, DiffLine{DiffRemoved, line})
+		}
+		for _, line := range b {
+			diff = append(diff, DiffLine{DiffAdded, line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{DiffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{DiffRemoved, a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DiffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{DiffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{DiffAdded, b[j]})
+	}
+	return diff
+}
+
+// splitLines splits text into lines, with no empty last line for a trailing
+// newline and none at all for empty text.
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
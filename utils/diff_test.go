@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "# Title\nOld intro.\nKept line.\nRemoved line.\nEnd."
+	after := "# Title\nNew intro.\nKept line.\nAdded line.\nEnd.\n"
+
+	got := DiffLines(before, after)
+	want := []DiffLine{
+		{DiffEqual, "# Title"},
+		{DiffRemoved, "Old intro."},
+		{DiffAdded, "New intro."},
+		{DiffEqual, "Kept line."},
+		{DiffRemoved, "Removed line."},
+		{DiffAdded, "Added line."},
+		{DiffEqual, "End."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLines() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffLinesEmpty(t *testing.T) {
+	if got := DiffLines("", ""); len(got) != 0 {
+		t.Errorf("Expected no lines for empty texts, got %v", got)
+	}
+	got := DiffLines("", "One\nTwo")
+	want := []DiffLine{{DiffAdded, "One"}, {DiffAdded, "Two"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLines() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind groups errors by what the user can do about them.
+type ErrorKind int
+
+const (
+	ErrorUnknown   ErrorKind = iota // Anything not recognized below
+	ErrorAuth                       // Bad credentials or missing permissions
+	ErrorRateLimit                  // Provider throttling or exhausted quota
+	ErrorNetwork                    // Site or provider unreachable, timeouts
+	ErrorModel                      // Model missing, overloaded or prompt too large
+)
+
+// String returns a short name for the kind, used in logs.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorAuth:
+		return "auth"
+	case ErrorRateLimit:
+		return "rate_limit"
+	case ErrorNetwork:
+		return "network"
+	case ErrorModel:
+		return "model"
+	default:
+		return "unknown"
+	}
+}
+
+// httpStatusPattern finds status codes in messages such as "HTTP 401",
+// "status 429" and "status code 503".
+var httpStatusPattern = regexp.MustCompile(`(?i)\b(?:http|status(?: code)?)[ :]+(\d{3})\b`)
+
+// Message fragments checked in order; the first match wins. Providers and the
+// WordPress client only return formatted errors, so matching on text is the
+// only option for most of them.
+var errorKindPatterns = []struct {
+	kind      ErrorKind
+	fragments []string
+}{
+	{ErrorRateLimit, []string{"rate limit", "rate_limit", "ratelimit", "too many requests", "quota", "resource_exhausted"}},
+	{ErrorAuth, []string{"unauthorized", "forbidden", "api key", "api_key", "apikey", "authenticate", "authentication", "invalid credentials", "permission denied"}},
+	{ErrorModel, []string{"context_length_exceeded", "context length", "context window", "token limit", "maximum context", "model not found", "model_not_found", "unknown model", "unsupported model", "overloaded", "is not configured"}},
+	{ErrorNetwork, []string{"timeout", "timed out", "connection refused", "connection reset", "no such host", "network is unreachable", "dial tcp", "tls handshake", "eof"}},
+}
+
+// ClassifyError works out which ErrorKind err belongs to, looking through
+// wrapped errors and HTTP status codes in the message.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ErrorUnknown
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorNetwork
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorNetwork
+	}
+
+	message := strings.ToLower(err.Error())
+	var statusErr interface{ HTTPStatus() int } // Such as wordpress.APIError
+	if errors.As(err, &statusErr) {
+		if kind := statusKind(statusErr.HTTPStatus(), message); kind != ErrorUnknown {
+			return kind
+		}
+	}
+	for _, match := range httpStatusPattern.FindAllStringSubmatch(message, -1) {
+		code, _ := strconv.Atoi(match[1])
+		if kind := statusKind(code, message); kind != ErrorUnknown {
+			return kind
+		}
+	}
+	for _, group := range errorKindPatterns {
+		for _, fragment := range group.fragments {
+			if strings.Contains(message, fragment) {
+				return group.kind
+			}
+		}
+	}
+	return ErrorUnknown
+}
+
+// statusKind returns the ErrorKind of an HTTP status code, given the
+// lowercased message it came with.
+func statusKind(code int, message string) ErrorKind {
+	switch {
+	case code == 401 || code == 403:
+		return ErrorAuth
+	case code == 429:
+		return ErrorRateLimit
+	case code == 404 && strings.Contains(message, "model"):
+		return ErrorModel
+	case code == 502 || code == 503 || code == 504:
+		return ErrorNetwork
+	}
+	return ErrorUnknown
+}
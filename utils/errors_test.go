@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{nil, ErrorUnknown},
+		{errors.New("prompt cannot be empty"), ErrorUnknown},
+		{fmt.Errorf("failed to connect: %w", errors.New("failed to authenticate with WordPress site: HTTP 401")), ErrorAuth},
+		{errors.New("failed to update page content: HTTP 403 - rest_cannot_edit"), ErrorAuth},
+		{errors.New("Cerebras API request failed with status 429: slow down"), ErrorRateLimit},
+		{errors.New("googleapi: Error 429: Resource has been exhausted (e.g. check quota)"), ErrorRateLimit},
+		{errors.New("invalid API key provided"), ErrorAuth},
+		{errors.New("Gemini request failed with status 404: model gemini-x was not found"), ErrorModel},
+		{errors.New("context_length_exceeded: please reduce the length of the messages"), ErrorModel},
+		{errors.New("failed to fetch page 2: HTTP 503"), ErrorNetwork},
+		{errors.New("dial tcp: lookup example.invalid: no such host"), ErrorNetwork},
+		{fmt.Errorf("generation failed: %w", context.DeadlineExceeded), ErrorNetwork},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("refused")}, ErrorNetwork},
+		{fmt.Errorf("failed to update page content: %w", statusError(403)), ErrorAuth},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+// statusError is an error carrying its HTTP status out of its message, like
+// wordpress.APIError.
+type statusError int
+
+func (e statusError) Error() string {
+	return "rest_cannot_edit: Sorry, you are not allowed to edit this post."
+}
+func (e statusError) HTTPStatus() int { return int(e) }
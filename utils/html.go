@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLToMarkdown converts an HTML fragment (as produced for WordPress pages)
+// into Markdown so it can be rendered with Fyne's RichText widget.
+// Unsupported tags are dropped but their text content is kept.
+func HTMLToMarkdown(src string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(src))
+
+	var out strings.Builder
+	var listStack []string // "ul" or "ol" for each open list
+	var olCounters []int
+	var linkHref string
+	skipDepth := 0 // >0 while inside <script>/<style>
+	inPre := false
+
+	newBlock := func() {
+		text := out.String()
+		if text == "" || strings.HasSuffix(text, "\n\n") {
+			return
+		}
+		if strings.HasSuffix(text, "\n") {
+			out.WriteString("\n")
+		} else {
+			out.WriteString("\n\n")
+		}
+	}
+
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return strings.TrimSpace(out.String())
+
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			text := string(tokenizer.Text())
+			if !inPre {
+				text = collapseWhitespace(text)
+				if strings.TrimSpace(text) == "" && (out.Len() == 0 || strings.HasSuffix(out.String(), "\n")) {
+					continue
+				}
+			}
+			out.WriteString(text)
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := string(name)
+			attrs := map[string]string{}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				attrs[string(key)] = string(val)
+			}
+			if skipDepth > 0 {
+				if tag == "script" || tag == "style" {
+					skipDepth++
+				}
+				continue
+			}
+			switch tag {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				newBlock()
+				out.WriteString(strings.Repeat("#", int(tag[1]-'0')) + " ")
+			case "p", "div", "section", "article", "header", "footer", "figure", "table", "tr":
+				newBlock()
+			case "br":
+				out.WriteString("\n")
+			case "hr":
+				newBlock()
+				out.WriteString("---\n\n")
+			case "strong", "b":
+				out.WriteString("**")
+			case "em", "i":
+				out.WriteString("*")
+			case "code":
+				if !inPre {
+					out.WriteString("`")
+				}
+			case "pre":
+				newBlock()
+				out.WriteString("```\n")
+				inPre = true
+			case "blockquote":
+				newBlock()
+				out.WriteString("> ")
+			case "ul", "ol":
+				if len(listStack) == 0 {
+					newBlock()
+				}
+				listStack = append(listStack, tag)
+				olCounters = append(olCounters, 0)
+			case "li":
+				if !strings.HasSuffix(out.String(), "\n") && out.Len() > 0 {
+					out.WriteString("\n")
+				}
+				depth := len(listStack)
+				if depth == 0 {
+					out.WriteString("- ")
+					continue
+				}
+				out.WriteString(strings.Repeat("  ", depth-1))
+				if listStack[depth-1] == "ol" {
+					olCounters[depth-1]++
+					out.WriteString(fmt.Sprintf("%d. ", olCounters[depth-1]))
+				} else {
+					out.WriteString("- ")
+				}
+			case "td", "th":
+				out.WriteString(" | ")
+			case "a":
+				linkHref = attrs["href"]
+				if linkHref != "" {
+					out.WriteString("[")
+				}
+			case "img":
+				out.WriteString(fmt.Sprintf("![%s](%s)", attrs["alt"], attrs["src"]))
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if skipDepth > 0 {
+				if tag == "script" || tag == "style" {
+					skipDepth--
+				}
+				continue
+			}
+			switch tag {
+			case "h1", "h2", "h3", "h4", "h5", "h6", "p", "div", "section", "article", "header", "footer", "figure", "table", "blockquote":
+				newBlock()
+			case "tr":
+				out.WriteString("\n")
+			case "strong", "b":
+				out.WriteString("**")
+			case "em", "i":
+				out.WriteString("*")
+			case "code":
+				if !inPre {
+					out.WriteString("`")
+				}
+			case "pre":
+				if !strings.HasSuffix(out.String(), "\n") {
+					out.WriteString("\n")
+				}
+				out.WriteString("```")
+				inPre = false
+				newBlock()
+			case "ul", "ol":
+				if len(listStack) > 0 {
+					listStack = listStack[:len(listStack)-1]
+					olCounters = olCounters[:len(olCounters)-1]
+				}
+				if len(listStack) == 0 {
+					newBlock()
+				}
+			case "a":
+				if linkHref != "" {
+					out.WriteString(fmt.Sprintf("](%s)", linkHref))
+					linkHref = ""
+				}
+			}
+		}
+	}
+}
+
+// collapseWhitespace replaces runs of whitespace with a single space, as a browser would.
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	lastSpace := false
+	for _, r := range s {
+		if r == ' ' || r == '\n' || r == '\t' || r == '\r' {
+			if !lastSpace {
+				b.WriteRune(' ')
+			}
+			lastSpace = true
+			continue
+		}
+		b.WriteRune(r)
+		lastSpace = false
+	}
+	return b.String()
+}
+
+// LooksLikeHTML reports whether the text appears to contain HTML markup.
+func LooksLikeHTML(s string) bool {
+	lower := strings.ToLower(s)
+	for _, tag := range []string{"<p", "<h1", "<h2", "<h3", "<div", "<ul", "<ol", "<br", "<strong", "<a ", "<!-- wp:"} {
+		if strings.Contains(lower, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	src := `<h2>Our Services</h2>
+<p>We offer <strong>fast</strong> and <em>friendly</em> support. <a href="https://example.com">Learn more</a>.</p>
+<ul><li>Design</li><li>Hosting</li></ul>
+<script>alert("x")</script>`
+
+	md := HTMLToMarkdown(src)
+
+	expected := []string{
+		"## Our Services",
+		"We offer **fast** and *friendly* support.",
+		"[Learn more](https://example.com)",
+		"- Design",
+		"- Hosting",
+	}
+	for _, want := range expected {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "alert") {
+		t.Errorf("Script content should be dropped, got:\n%s", md)
+	}
+}
+
+func TestHTMLToMarkdownOrderedList(t *testing.T) {
+	md := HTMLToMarkdown("<ol><li>One</li><li>Two</li></ol>")
+	if !strings.Contains(md, "1. One") || !strings.Contains(md, "2. Two") {
+		t.Errorf("Expected numbered list items, got:\n%s", md)
+	}
+}
+
+func TestLooksLikeHTML(t *testing.T) {
+	if !LooksLikeHTML("<p>Hello</p>") {
+		t.Error("Expected paragraph markup to be detected as HTML")
+	}
+	if LooksLikeHTML("# Heading\n\nSome *markdown* text") {
+		t.Error("Expected Markdown not to be detected as HTML")
+	}
+}
@@ -1,19 +1,21 @@
 package utils
 
 import (
-	"io"
-	"log"
 	"strings"
 	"sync"
+
+	"Inference_Engine/logging"
 )
 
+var logger = logging.For("utils")
+
 const maxLogLinesForDialog = 20 // Number of log lines to keep in the dialog display
 
-// LogRelay captures log output and relays it to a UI callback.
+// LogRelay captures log records and relays them to a UI callback.
 type LogRelay struct {
 	mu                sync.Mutex
 	logMessageChannel chan string
-	originalLogOutput io.Writer    // The log output active before this relay started
+	cancel            func()       // Ends the log subscription
 	uiUpdateCallback  func(string) // Callback to update the UI with new log text
 	logBuffer         []string     // Stores the last N log lines
 	active            bool
@@ -30,26 +32,25 @@ func NewLogRelay(uiUpdateCallback func(string)) *LogRelay {
 	}
 }
 
-// Start begins capturing log output. It replaces the global log output.
+// Start begins capturing log records. Other log outputs keep receiving them.
 func (lr *LogRelay) Start() {
 	lr.mu.Lock()
 	if lr.active {
 		lr.mu.Unlock()
-		log.Println("LogRelay: Start called when already active.")
+		logger.Warn("LogRelay: Start called when already active")
 		return
 	}
-	lr.originalLogOutput = log.Writer() // Capture current global log output
-	log.SetOutput(lr)                    // Set this LogRelay as the new global log output
 	lr.active = true
 	lr.logBuffer = make([]string, 0, maxLogLinesForDialog) // Clear buffer on start
+	lr.cancel = logging.Subscribe(lr.relay)
 	lr.mu.Unlock()
 
 	lr.wg.Add(1)
 	go lr.processLogMessages()
-	log.Println("LogRelay: Started and capturing global logs.") // This log will be captured by the relay
+	logger.Info("LogRelay: started capturing logs") // This log will be captured by the relay
 }
 
-// Stop ceases log capture and restores the original log output.
+// Stop ceases log capture.
 func (lr *LogRelay) Stop() {
 	lr.mu.Lock()
 	if !lr.active {
@@ -57,43 +58,27 @@ func (lr *LogRelay) Stop() {
 		return
 	}
 	lr.active = false
-	// Restore original log output. Any logs after this point from other goroutines
-	// or this function will go to the original output.
-	if lr.originalLogOutput != nil {
-		log.SetOutput(lr.originalLogOutput)
-		log.Println("LogRelay: Stopped and restored original log output.") // This goes to the original output
-	} else {
-		log.Println("LogRelay: Stopped, but originalLogOutput was nil (this should not happen if Start was successful).")
-	}
+	lr.cancel()
+	close(lr.logMessageChannel) // Signal the processing goroutine to stop
 	lr.mu.Unlock()
 
-	close(lr.logMessageChannel) // Signal the processing goroutine to stop
-	lr.wg.Wait()                // Wait for the processing goroutine to finish
+	lr.wg.Wait() // Wait for the processing goroutine to finish
+	logger.Info("LogRelay: stopped capturing logs")
 }
 
-// Write implements io.Writer. This method is called by the log package when LogRelay is set as output.
-func (lr *LogRelay) Write(p []byte) (n int, err error) {
-	// Atomically get the original output and active state
+// relay receives each log record while the relay is active.
+func (lr *LogRelay) relay(r logging.Record) {
 	lr.mu.Lock()
-	originalOutput := lr.originalLogOutput
-	isActive := lr.active
-	lr.mu.Unlock()
-
-	// Write to the original output first
-	if originalOutput != nil {
-		originalOutput.Write(p) // Pass through to what was previously logging
+	defer lr.mu.Unlock()
+	if !lr.active {
+		return // A record handled while Stop was running
 	}
-
-	if isActive {
-		// Send to channel for async processing and UI update
-		// Non-blocking send to prevent log calls from deadlocking if channel is full
-		select {
-		case lr.logMessageChannel <- string(p):
-		default:
-			// Log message dropped for UI if channel is full, but it was still written to originalLogOutput
-		}
+	// Non-blocking send to prevent log calls from deadlocking if channel is full
+	select {
+	case lr.logMessageChannel <- r.String():
+	default:
+		// Log message dropped for the UI if the channel is full; other outputs still got it
 	}
-	return len(p), nil
 }
 
 // processLogMessages reads from the channel, updates the buffer, and calls the UI callback.
@@ -101,7 +86,7 @@ func (lr *LogRelay) processLogMessages() {
 	defer lr.wg.Done()
 	for message := range lr.logMessageChannel {
 		lr.mu.Lock()
-		// Split message by newlines, as a single log.Print can contain multiple lines
+		// Split message by newlines, as a single record can contain multiple lines
 		lines := strings.Split(strings.TrimSpace(message), "\n")
 		for _, line := range lines {
 			trimmedLine := strings.TrimSpace(line)
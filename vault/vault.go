@@ -0,0 +1,378 @@
+// Package vault keeps the app's secrets (WordPress application passwords,
+// API keys) in a file encrypted with a key derived from a master password.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"Inference_Engine/logging"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var logger = logging.For("vault")
+
+// MinPasswordLength is the shortest master password accepted.
+const MinPasswordLength = 8
+
+// Key derivation parameters (Argon2id), stored in the file so they can be
+// raised later without breaking existing vaults.
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024 // KiB
+	kdfThreads = 4
+	keyLength  = 32 // AES-256
+	saltLength = 16
+)
+
+var (
+	// ErrLocked is returned when secrets are used while the vault is locked.
+	ErrLocked = errors.New("the vault is locked")
+	// ErrWrongPassword is returned when the master password doesn't open
+	// the vault.
+	ErrWrongPassword = errors.New("wrong master password")
+)
+
+// envName matches secret names that are environment variable names, such
+// as "GEMINI_API_KEY", as opposed to "wordpress:My Blog".
+var envName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// file is the vault as stored on disk. Data is the AES-GCM encrypted JSON
+// object of secrets.
+type file struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Vault holds the secrets while unlocked. It is safe for concurrent use.
+type Vault struct {
+	path string
+
+	mu           sync.Mutex
+	stored       *file             // nil until the vault is created
+	key          []byte            // nil while locked
+	secrets      map[string]string // nil while locked
+	lastActivity time.Time
+	onLock       []func()
+}
+
+// Open loads the vault stored at path, locked. A missing file gives a vault
+// that doesn't exist yet; see Create.
+func Open(path string) (*Vault, error) {
+	v := &Vault{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the vault: %w", err)
+	}
+	var stored file
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to read the vault: %w", err)
+	}
+	if stored.Version != 1 || stored.KDF != "argon2id" {
+		return nil, fmt.Errorf("unsupported vault format %d (%s)", stored.Version, stored.KDF)
+	}
+	v.stored = &stored
+	return v, nil
+}
+
+// Exists reports whether the vault has been created.
+func (v *Vault) Exists() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.stored != nil
+}
+
+// Locked reports whether the secrets are unavailable, either because the
+// vault is locked or because it doesn't exist.
+func (v *Vault) Locked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.key == nil
+}
+
+// Create makes a new, empty vault protected by password and leaves it
+// unlocked.
+func (v *Vault) Create(password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("the master password must have at least %d characters", MinPasswordLength)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.stored != nil {
+		return fmt.Errorf("a vault already exists")
+	}
+	if err := v.rekeyLocked(password, map[string]string{}); err != nil {
+		return err
+	}
+	logger.Info("Created vault", "path", v.path)
+	return nil
+}
+
+// Unlock decrypts the secrets with password.
+func (v *Vault) Unlock(password string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.stored == nil {
+		return fmt.Errorf("no vault has been created")
+	}
+	key := argon2.IDKey([]byte(password), v.stored.Salt, v.stored.Time, v.stored.Memory, v.stored.Threads, keyLength)
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, v.stored.Nonce, v.stored.Data, nil)
+	if err != nil {
+		return ErrWrongPassword
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("the vault is damaged: %w", err)
+	}
+	v.key, v.secrets = key, secrets
+	v.lastActivity = time.Now()
+	logger.Info("Unlocked vault", "secrets", len(secrets))
+	return nil
+}
+
+// Lock forgets the key and the decrypted secrets, then calls the OnLock
+// listeners.
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	if v.key == nil {
+		v.mu.Unlock()
+		return
+	}
+	clear(v.key)
+	v.key, v.secrets = nil, nil
+	listeners := append([]func(){}, v.onLock...)
+	v.mu.Unlock()
+	logger.Info("Locked vault")
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// OnLock registers a listener called (from any goroutine) whenever the vault
+// locks.
+func (v *Vault) OnLock(listener func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.onLock = append(v.onLock, listener)
+}
+
+// Touch records user activity, postponing LockIfIdle.
+func (v *Vault) Touch() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lastActivity = time.Now()
+}
+
+// LockIfIdle locks the vault if it has seen no activity for idle, and
+// reports whether it did.
+func (v *Vault) LockIfIdle(idle time.Duration) bool {
+	v.mu.Lock()
+	expired := v.key != nil && idle > 0 && time.Since(v.lastActivity) >= idle
+	v.mu.Unlock()
+	if expired {
+		v.Lock()
+	}
+	return expired
+}
+
+// Get returns a secret.
+func (v *Vault) Get(name string) (string, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	value, ok := v.secrets[name]
+	return value, ok
+}
+
+// Set stores a secret, or removes it if value is empty, and saves the
+// vault.
+func (v *Vault) Set(name, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.key == nil {
+		return ErrLocked
+	}
+	if v.secrets[name] == value {
+		return nil
+	}
+	secrets := make(map[string]string, len(v.secrets)+1)
+	for k, s := range v.secrets {
+		secrets[k] = s
+	}
+	if value == "" {
+		delete(secrets, name)
+	} else {
+		secrets[name] = value
+	}
+	if err := v.saveLocked(v.key, v.stored.Salt, secrets); err != nil {
+		return err
+	}
+	v.secrets = secrets
+	return nil
+}
+
+// Names returns the names of the secrets, sorted.
+func (v *Vault) Names() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	names := make([]string, 0, len(v.secrets))
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ChangePassword re-encrypts the secrets with a key derived from password.
+// The vault must be unlocked.
+func (v *Vault) ChangePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("the master password must have at least %d characters", MinPasswordLength)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.key == nil {
+		return ErrLocked
+	}
+	if err := v.rekeyLocked(password, v.secrets); err != nil {
+		return err
+	}
+	logger.Info("Changed vault password")
+	return nil
+}
+
+// ApplyEnv sets the environment variables stored in the vault, such as API
+// keys, for the providers that read them, and returns how many it set.
+func (v *Vault) ApplyEnv() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	count := 0
+	for name, value := range v.secrets {
+		if envName.MatchString(name) {
+			os.Setenv(name, value)
+			count++
+		}
+	}
+	return count
+}
+
+// ImportEnv stores the environment variables among names that are set and
+// differ from the vault's copy, saving the vault once, and returns the names
+// it stored. The vault must be unlocked.
+func (v *Vault) ImportEnv(names []string) ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.key == nil {
+		return nil, ErrLocked
+	}
+	secrets := make(map[string]string, len(v.secrets)+len(names))
+	for k, s := range v.secrets {
+		secrets[k] = s
+	}
+	var imported []string
+	for _, name := range names {
+		if value := os.Getenv(name); envName.MatchString(name) && value != "" && secrets[name] != value {
+			secrets[name] = value
+			imported = append(imported, name)
+		}
+	}
+	if len(imported) == 0 {
+		return nil, nil
+	}
+	if err := v.saveLocked(v.key, v.stored.Salt, secrets); err != nil {
+		return nil, err
+	}
+	v.secrets = secrets
+	logger.Info("Imported environment variables into the vault", "count", len(imported))
+	return imported, nil
+}
+
+// rekeyLocked derives a new key from password with a fresh salt and saves
+// secrets with it. Caller holds the mutex.
+func (v *Vault) rekeyLocked(password string, secrets map[string]string) error {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), salt, kdfTime, kdfMemory, kdfThreads, keyLength)
+	if err := v.saveLocked(key, salt, secrets); err != nil {
+		return err
+	}
+	if v.key != nil {
+		clear(v.key)
+	}
+	v.key, v.secrets = key, secrets
+	v.lastActivity = time.Now()
+	return nil
+}
+
+// saveLocked encrypts secrets with key and writes the vault, replacing the
+// file only once the new one is complete. Caller holds the mutex.
+func (v *Vault) saveLocked(key, salt []byte, secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	stored := &file{Version: 1, KDF: "argon2id", Time: kdfTime, Memory: kdfMemory, Threads: kdfThreads,
+		Salt: salt, Nonce: nonce, Data: gcm.Seal(nil, nonce, plain, nil)}
+	if v.stored != nil && string(v.stored.Salt) == string(salt) {
+		// Same key: keep the parameters it was derived with
+		stored.Time, stored.Memory, stored.Threads = v.stored.Time, v.stored.Memory, v.stored.Threads
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save the vault: %w", err)
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save the vault: %w", err)
+	}
+	if err := os.Rename(tmp, v.path); err != nil {
+		return fmt.Errorf("failed to save the vault: %w", err)
+	}
+	v.stored = stored
+	return nil
+}
+
+// newGCM returns an AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
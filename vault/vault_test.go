@@ -0,0 +1,128 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultKeepsSecretsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if v.Exists() || !v.Locked() {
+		t.Fatalf("Expected a new vault not to exist yet")
+	}
+	if err := v.Create("short"); err == nil {
+		t.Errorf("Expected a short master password to be rejected")
+	}
+	if err := v.Create("correct horse"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := v.Set("GEMINI_API_KEY", "gemini-secret"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := v.Set("wordpress:Blog", "app-password"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "gemini-secret") || strings.Contains(string(data), "GEMINI_API_KEY") {
+		t.Errorf("Expected the secrets to be encrypted on disk, got %s", data)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, ok := reopened.Get("GEMINI_API_KEY"); ok || !reopened.Locked() {
+		t.Fatalf("Expected a reopened vault to be locked")
+	}
+	if err := reopened.Set("X", "y"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected setting a secret in a locked vault to fail, got %v", err)
+	}
+	if err := reopened.Unlock("wrong password"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Expected a wrong password to be rejected, got %v", err)
+	}
+	if err := reopened.Unlock("correct horse"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if value, _ := reopened.Get("wordpress:Blog"); value != "app-password" {
+		t.Errorf("Expected the saved secret, got %q", value)
+	}
+
+	if err := reopened.ChangePassword("battery staple"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	locked := false
+	reopened.OnLock(func() { locked = true })
+	reopened.Lock()
+	if !locked {
+		t.Errorf("Expected the lock listener to be called")
+	}
+	if err := reopened.Unlock("correct horse"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Expected the old password to stop working, got %v", err)
+	}
+	if err := reopened.Unlock("battery staple"); err != nil {
+		t.Fatalf("Unlock with the new password failed: %v", err)
+	}
+	if names := reopened.Names(); len(names) != 2 {
+		t.Errorf("Expected both secrets after the password change, got %v", names)
+	}
+}
+
+func TestVaultLocksWhenIdleAndAppliesEnv(t *testing.T) {
+	v, _ := Open(filepath.Join(t.TempDir(), "vault.json"))
+	if err := v.Create("correct horse"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	v.Set("VAULT_TEST_API_KEY", "secret")
+	v.Set("wordpress:Blog", "app-password")
+	t.Setenv("VAULT_TEST_API_KEY", "")
+	if n := v.ApplyEnv(); n != 1 || os.Getenv("VAULT_TEST_API_KEY") != "secret" {
+		t.Errorf("Expected only the API key to be set in the environment, set %d", n)
+	}
+
+	if v.LockIfIdle(time.Hour) {
+		t.Errorf("Expected a recently used vault to stay unlocked")
+	}
+	v.Touch()
+	time.Sleep(5 * time.Millisecond)
+	if !v.LockIfIdle(time.Millisecond) || !v.Locked() {
+		t.Errorf("Expected an idle vault to lock")
+	}
+}
+
+func TestVaultImportsEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v, _ := Open(path)
+	if _, err := v.ImportEnv([]string{"VAULT_TEST_API_KEY"}); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected importing into a missing vault to fail, got %v", err)
+	}
+	if err := v.Create("correct horse"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	v.Set("VAULT_TEST_SAME_KEY", "same")
+	t.Setenv("VAULT_TEST_API_KEY", "from-env")
+	t.Setenv("VAULT_TEST_SAME_KEY", "same")
+	t.Setenv("VAULT_TEST_EMPTY_KEY", "")
+	imported, err := v.ImportEnv([]string{"VAULT_TEST_API_KEY", "VAULT_TEST_SAME_KEY", "VAULT_TEST_EMPTY_KEY"})
+	if err != nil {
+		t.Fatalf("ImportEnv failed: %v", err)
+	}
+	if len(imported) != 1 || imported[0] != "VAULT_TEST_API_KEY" {
+		t.Errorf("Expected only the changed, set variable to be imported, got %v", imported)
+	}
+
+	reopened, _ := Open(path)
+	if err := reopened.Unlock("correct horse"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if value, _ := reopened.Get("VAULT_TEST_API_KEY"); value != "from-env" {
+		t.Errorf("Expected the imported variable to be saved, got %q", value)
+	}
+}
@@ -0,0 +1,215 @@
+// Package versioning commits generated drafts and page snapshots to a local
+// git repository per site, so their history can be diffed, blamed and
+// reviewed with the usual git tools.
+package versioning
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"Inference_Engine/history"
+	"Inference_Engine/logging"
+)
+
+var logger = logging.For("versioning")
+
+// Commits are authored by the app, so they stand out from reviewers' own.
+const (
+	authorName  = "Wordpress Inference Engine"
+	authorEmail = "inference-engine@localhost"
+)
+
+// noSite names the repository of drafts made without a connected site.
+const noSite = "local"
+
+// maxSlug caps the length of file and directory names made from titles.
+const maxSlug = 60
+
+// queueSize is how many commits may wait before recording blocks.
+const queueSize = 64
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Store keeps one git repository per site under a root directory. Commits
+// are made in order on a background goroutine.
+type Store struct {
+	root  string
+	git   string
+	queue chan commit
+}
+
+// commit is one file version waiting to be committed, or a Flush marker
+// with only done set.
+type commit struct {
+	site, path, content, message string
+	done                         chan struct{}
+}
+
+// FromEnv creates a store in the directory named by GIT_VERSIONS_DIR. It
+// returns nil without an error when the variable is unset, as versioning is
+// optional.
+func FromEnv() (*Store, error) {
+	root := os.Getenv("GIT_VERSIONS_DIR")
+	if root == "" {
+		return nil, nil
+	}
+	return New(root)
+}
+
+// New creates a store keeping its repositories in root. git must be
+// installed.
+func New(root string) (*Store, error) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return nil, fmt.Errorf("git versioning needs git installed: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create versions directory: %w", err)
+	}
+	s := &Store{root: root, git: git, queue: make(chan commit, queueSize)}
+	go s.work()
+	return s, nil
+}
+
+// Dir returns the directory of the site's repository.
+func (s *Store) Dir(site string) string {
+	name := slug(site)
+	if name == "" {
+		name = noSite
+	}
+	return filepath.Join(s.root, name)
+}
+
+// RecordDraft commits a generated draft as drafts/<prompt>.md, so drafts
+// generated again from the same prompt show up as revisions of one file.
+func (s *Store) RecordDraft(site string, d history.Draft) {
+	name := slug(d.Prompt)
+	if name == "" {
+		name = fmt.Sprintf("draft-%d", d.ID)
+	}
+	message := fmt.Sprintf("Draft %d: %s\n\nModel: %s", d.ID, firstLine(d.Prompt), d.Model)
+	if len(d.SourceTitles) > 0 {
+		message += "\nSources: " + strings.Join(d.SourceTitles, ", ")
+	}
+	if d.Instruction != "" {
+		message += "\nInstructions: " + d.Instruction
+	}
+	s.record(commit{site: site, path: filepath.Join("drafts", name+".md"), content: d.Output, message: message})
+}
+
+// RecordPage commits a page's content as pages/<id>.html, fetched from the
+// site or saved to it.
+func (s *Store) RecordPage(site string, pageID int, content string, saved bool) {
+	message := fmt.Sprintf("Snapshot of page %d", pageID)
+	if saved {
+		message = fmt.Sprintf("Save page %d", pageID)
+	}
+	s.record(commit{site: site, path: filepath.Join("pages", fmt.Sprintf("%d.html", pageID)), content: content, message: message})
+}
+
+// record queues a commit.
+func (s *Store) record(c commit) {
+	s.queue <- c
+}
+
+// work makes the queued commits in order.
+func (s *Store) work() {
+	for c := range s.queue {
+		if c.done != nil {
+			close(c.done)
+			continue
+		}
+		if err := s.commit(c); err != nil {
+			logger.Warn("Failed to commit version", "site", c.site, "path", c.path, "error", err)
+		}
+	}
+}
+
+// Flush waits until the commits queued so far are made.
+func (s *Store) Flush() {
+	done := make(chan struct{})
+	s.queue <- commit{done: done}
+	<-done
+}
+
+// commit writes a file to the site's repository and commits it, unless its
+// content is unchanged.
+func (s *Store) commit(c commit) error {
+	dir, err := s.repository(c.site)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, c.path)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, []byte(c.content)) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(c.content), 0o644); err != nil {
+		return err
+	}
+	if _, err := s.run(dir, "add", "--", c.path); err != nil {
+		return err
+	}
+	if _, err := s.run(dir, "commit", "--quiet", "--no-verify", "-m", c.message, "--", c.path); err != nil {
+		return err
+	}
+	logger.Debug("Committed version", "site", c.site, "path", c.path)
+	return nil
+}
+
+// repository returns the site's repository directory, creating it on first
+// use.
+func (s *Store) repository(site string) (string, error) {
+	dir := s.Dir(site)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return dir, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if _, err := s.run(dir, "init", "--quiet"); err != nil {
+		return "", err
+	}
+	logger.Info("Created version repository", "site", site, "dir", dir)
+	return dir, nil
+}
+
+// run runs git in dir, as the app's author.
+func (s *Store) run(dir string, args ...string) (string, error) {
+	cmd := exec.Command(s.git, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+authorName, "GIT_AUTHOR_EMAIL="+authorEmail,
+		"GIT_COMMITTER_NAME="+authorName, "GIT_COMMITTER_EMAIL="+authorEmail)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// slug makes a file name from a title: "Best Cat Food!" is "best-cat-food".
+func slug(title string) string {
+	name := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(firstLine(title)), "-"), "-")
+	if len(name) > maxSlug {
+		name = strings.TrimRight(name[:maxSlug], "-")
+	}
+	return name
+}
+
+// firstLine returns the first non-empty line of text, trimmed.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package versioning
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Inference_Engine/history"
+)
+
+func TestStoreCommitsDraftsAndPages(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	store, err := New(root)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	draft := history.Draft{ID: 3, Prompt: "Write about cats\nin detail", Model: "gemini", SourceTitles: []string{"Cats"}, Output: "Cats are great."}
+	store.RecordDraft("My Blog", draft)
+	draft.ID, draft.Output = 4, "Cats are wonderful."
+	store.RecordDraft("My Blog", draft)
+	store.RecordPage("My Blog", 12, "<p>Old</p>", false)
+	store.RecordPage("My Blog", 12, "<p>Old</p>", false) // Unchanged, not committed
+	store.RecordPage("My Blog", 12, "<p>New</p>", true)
+	store.Flush()
+
+	dir := store.Dir("My Blog")
+	if dir != filepath.Join(root, "my-blog") {
+		t.Errorf("Expected the repository in my-blog, got %s", dir)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "drafts", "write-about-cats.md"))
+	if err != nil || string(content) != "Cats are wonderful." {
+		t.Errorf("Expected the latest draft on disk, got %q (%v)", content, err)
+	}
+	log, err := exec.Command("git", "-C", dir, "log", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	want := []string{"Save page 12", "Snapshot of page 12", "Draft 4: Write about cats", "Draft 3: Write about cats"}
+	if got := strings.Split(strings.TrimSpace(string(log)), "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected commits %q, got %q", want, got)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for title, want := range map[string]string{
+		"Best Cat Food!":        "best-cat-food",
+		"  \n  Two lines\nmore": "two-lines",
+		"":                      "",
+	} {
+		if got := slug(title); got != want {
+			t.Errorf("slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
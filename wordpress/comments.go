@@ -0,0 +1,66 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Comment is an approved reader comment on a page or post.
+type Comment struct {
+	ID      int
+	Author  string
+	Date    time.Time
+	Content string // Rendered HTML
+}
+
+// GetRecentComments fetches the newest approved comments on a page or post,
+// at most limit of them (up to 100), newest first.
+func (s *WordPressService) GetRecentComments(postID, limit int) ([]Comment, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	if limit <= 0 || limit > 100 {
+		limit = 100 // REST API per_page limit
+	}
+	requestURL := fmt.Sprintf("%swp-json/wp/v2/comments?post=%d&per_page=%d&orderby=date&order=desc&_fields=id,author_name,date_gmt,content", siteURL, postID, limit)
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch comments: %w", apiError(resp))
+	}
+
+	var raw []struct {
+		ID      int    `json:"id"`
+		Author  string `json:"author_name"`
+		Date    string `json:"date_gmt"`
+		Content struct {
+			Rendered string `json:"rendered"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse comments: %w", err)
+	}
+	comments := make([]Comment, len(raw))
+	for i, r := range raw {
+		date, _ := time.Parse("2006-01-02T15:04:05", r.Date)
+		comments[i] = Comment{ID: r.ID, Author: r.Author, Date: date, Content: r.Content.Rendered}
+	}
+	return comments, nil
+}
@@ -0,0 +1,100 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Feature is a plugin whose REST API some of the app's features use.
+type Feature string
+
+const (
+	FeatureWooCommerce Feature = "WooCommerce"
+	FeatureACF         Feature = "ACF"
+	FeatureYoast       Feature = "Yoast SEO"
+	FeatureJetpack     Feature = "Jetpack"
+)
+
+// featureNamespaces are the REST namespaces, by prefix, that show a plugin
+// is active.
+var featureNamespaces = map[Feature][]string{
+	FeatureWooCommerce: {"wc/"},
+	FeatureACF:         {"acf/"},
+	FeatureYoast:       {"yoast/"},
+	FeatureJetpack:     {"jetpack/"},
+}
+
+// Features lists the features in the order they are shown.
+var Features = []Feature{FeatureYoast, FeatureACF, FeatureWooCommerce, FeatureJetpack}
+
+// SiteFeatures are the REST namespaces of the connected site, read from the
+// /wp-json/ index when connecting. When they couldn't be read, every feature
+// counts as present and requests to it fail on their own if it isn't.
+type SiteFeatures struct {
+	Known      bool
+	Namespaces []string
+}
+
+// Has reports whether the site has feature.
+func (f SiteFeatures) Has(feature Feature) bool {
+	if !f.Known {
+		return true
+	}
+	for _, namespace := range f.Namespaces {
+		for _, prefix := range featureNamespaces[feature] {
+			if strings.HasPrefix(namespace, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Detected returns the features found on the site, or none if the index
+// couldn't be read.
+func (f SiteFeatures) Detected() []Feature {
+	var detected []Feature
+	for _, feature := range Features {
+		if f.Known && f.Has(feature) {
+			detected = append(detected, feature)
+		}
+	}
+	return detected
+}
+
+// fetchFeatures reads the namespaces from the REST API index. Call it with
+// the mutex held, as Connect does.
+func (s *WordPressService) fetchFeatures(siteURL, username, appPassword string) (SiteFeatures, error) {
+	req, err := http.NewRequest("GET", siteURL+"wp-json/?_fields=namespaces", nil)
+	if err != nil {
+		return SiteFeatures{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return SiteFeatures{}, fmt.Errorf("failed to fetch the REST API index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SiteFeatures{}, fmt.Errorf("failed to fetch the REST API index: %w", apiError(resp))
+	}
+	var index struct {
+		Namespaces []string `json:"namespaces"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return SiteFeatures{}, fmt.Errorf("failed to parse the REST API index: %w", err)
+	}
+	if len(index.Namespaces) == 0 {
+		return SiteFeatures{}, fmt.Errorf("the REST API index lists no namespaces")
+	}
+	return SiteFeatures{Known: true, Namespaces: index.Namespaces}, nil
+}
+
+// Features returns the plugin features found on the connected site.
+func (s *WordPressService) Features() SiteFeatures {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.features
+}
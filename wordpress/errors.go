@@ -0,0 +1,79 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// APIError is an error response from the WordPress REST API, which carries
+// a machine-readable code and a message for people, e.g. "rest_cannot_edit"
+// and "Sorry, you are not allowed to edit this post."
+type APIError struct {
+	Status  int    // HTTP status of the response
+	Code    string // WordPress error code; empty if the body wasn't a WordPress error
+	Message string // WordPress message, or the start of the body if it wasn't one
+}
+
+// Error returns "code: message", or the HTTP status and body for responses
+// that weren't WordPress errors, such as a proxy's error page.
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		if e.Message == "" {
+			return e.Code
+		}
+		return e.Code + ": " + e.Message
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Message)
+}
+
+// HTTPStatus returns the response's status, which utils.ClassifyError uses
+// now that it isn't in the message.
+func (e *APIError) HTTPStatus() int {
+	return e.Status
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// plainText turns the HTML WordPress sometimes puts in messages into text.
+func plainText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(s, ""))), " ")
+}
+
+// apiError reads the error response resp into an APIError. The status in
+// the body's data, when there is one, wins over the response's, which some
+// hosts rewrite.
+func apiError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{Status: resp.StatusCode}
+	var decoded struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Status int `json:"status"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &decoded) == nil && decoded.Code != "" {
+		apiErr.Code, apiErr.Message = decoded.Code, plainText(decoded.Message)
+		if decoded.Data.Status != 0 {
+			apiErr.Status = decoded.Data.Status
+		}
+		return apiErr
+	}
+	message := plainText(string(body))
+	if runes := []rune(message); len(runes) > 200 {
+		message = string(runes[:200]) + "…"
+	}
+	apiErr.Message = message
+	return apiErr
+}
@@ -0,0 +1,156 @@
+package wordpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxMediaDownload is the largest attachment DownloadMedia reads.
+const maxMediaDownload = 8 << 20
+
+// MediaItem is an image attachment of the media library.
+type MediaItem struct {
+	ID        int
+	Title     string
+	SourceURL string
+	MimeType  string
+	AltText   string
+	Caption   string // Raw caption, without the <p> WordPress wraps it in
+}
+
+// GetMediaBatch fetches one batch of the site's image attachments, oldest
+// first, with their raw captions. It also returns the number of batches.
+func (s *WordPressService) GetMediaBatch(page, perPage int) ([]MediaItem, int, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, 0, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	requestURL := fmt.Sprintf("%swp-json/wp/v2/media?media_type=image&context=edit&per_page=%d&page=%d&orderby=id&order=asc&_fields=id,title,source_url,mime_type,alt_text,caption",
+		siteURL, perPage, page)
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request for media batch %d: %w", page, err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch media batch %d: %w", page, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusBadRequest && page > 1 {
+		// WordPress answers 400 (rest_post_invalid_page_number) past the last batch
+		return nil, page - 1, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to fetch media batch %d: %w", page, apiError(resp))
+	}
+	totalBatches, _ := strconv.Atoi(resp.Header.Get("X-WP-TotalPages"))
+
+	var raw []struct {
+		ID        int    `json:"id"`
+		SourceURL string `json:"source_url"`
+		MimeType  string `json:"mime_type"`
+		AltText   string `json:"alt_text"`
+		Title     struct {
+			Raw string `json:"raw"`
+		} `json:"title"`
+		Caption struct {
+			Raw string `json:"raw"`
+		} `json:"caption"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse media batch %d: %w", page, err)
+	}
+	if totalBatches == 0 {
+		totalBatches = page
+		if len(raw) == perPage {
+			totalBatches = page + 1
+		}
+	}
+	items := make([]MediaItem, len(raw))
+	for i, r := range raw {
+		items[i] = MediaItem{ID: r.ID, Title: r.Title.Raw, SourceURL: r.SourceURL, MimeType: r.MimeType,
+			AltText: strings.TrimSpace(r.AltText), Caption: strings.TrimSpace(r.Caption.Raw)}
+	}
+	return items, totalBatches, nil
+}
+
+// DownloadMedia fetches an attachment's file. Files larger than
+// maxMediaDownload are refused.
+func (s *WordPressService) DownloadMedia(item MediaItem) ([]byte, error) {
+	resp, err := s.client.Get(item.SourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", item.SourceURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %w", item.SourceURL, apiError(resp))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMediaDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", item.SourceURL, err)
+	}
+	if len(data) > maxMediaDownload {
+		return nil, fmt.Errorf("%s is larger than %d MB", item.SourceURL, maxMediaDownload>>20)
+	}
+	return data, nil
+}
+
+// UpdateMediaText sets an attachment's alt text and caption. Empty values
+// are left unchanged.
+func (s *WordPressService) UpdateMediaText(mediaID int, altText, caption string) error {
+	fields := map[string]interface{}{}
+	if altText != "" {
+		fields["alt_text"] = altText
+	}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if err := s.CanWrite(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	bodyJSON, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to create request body: %w", err)
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%swp-json/wp/v2/media/%d", siteURL, mediaID), bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update media %d: %w", mediaID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update media %d: %w", mediaID, apiError(resp))
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package wordpress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+)
+
+// ErrQueuedOffline is returned by page updates made offline: the update is
+// kept and saved by ReplayQueued once the network is back.
+var ErrQueuedOffline = errors.New("offline: the update is queued and will be saved when the connection returns")
+
+// offlineCacheNamespace is the cache namespace keeping the page lists and
+// contents read from the site, to show them offline.
+const offlineCacheNamespace = "wordpress_offline"
+
+// queuedUpdatesDocument is the state database document keeping the page
+// updates made offline.
+const queuedUpdatesDocument = "wordpress_queued_updates"
+
+// QueuedUpdate is a page update made offline, waiting to be saved.
+type QueuedUpdate struct {
+	SiteURL  string    `json:"site_url"`
+	PageID   int       `json:"page_id"`
+	Content  string    `json:"content"`
+	Queued   time.Time `json:"queued"`
+	Modified time.Time `json:"modified"` // When the page was last changed, as read online; zero to save it regardless
+}
+
+// QueuedConflictError is returned by ReplayQueued when a page changed on the
+// site after it was edited offline, so saving the queued update would
+// overwrite that change. The update stays queued until ResolveQueued.
+type QueuedConflictError struct {
+	Update   QueuedUpdate
+	Modified time.Time // When the page was changed on the site
+}
+
+func (e *QueuedConflictError) Error() string {
+	return fmt.Sprintf("page %d was changed on the site at %s, after it was edited offline", e.Update.PageID, e.Modified.Format(time.DateTime))
+}
+
+// cachedBatch is a batch of the page list kept for offline mode.
+type cachedBatch struct {
+	Pages        PageList `json:"pages"`
+	TotalBatches int      `json:"total_batches"`
+}
+
+// SetOffline switches offline mode. Offline, page lists and contents are
+// read from what was cached while online, and page updates are queued.
+func (s *WordPressService) SetOffline(offline bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.offline = offline
+}
+
+// Offline reports whether the service is in offline mode.
+func (s *WordPressService) Offline() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.offline
+}
+
+// cacheRead keeps what was read from the site under key, for offline mode.
+// Without the state database nothing is kept.
+func (s *WordPressService) cacheRead(key string, value interface{}) {
+	s.mutex.Lock()
+	db, site := s.db, s.siteURL
+	s.mutex.Unlock()
+	if db == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = db.PutCache(offlineCacheNamespace, site+"|"+key, data)
+	}
+	if err != nil {
+		logger.Warn("Failed to cache for offline mode", "key", key, "error", err)
+	}
+}
+
+// cachedRead reads what cacheRead kept under key into value.
+func (s *WordPressService) cachedRead(key string, value interface{}) error {
+	s.mutex.Lock()
+	db, site := s.db, s.siteURL
+	s.mutex.Unlock()
+	if db != nil {
+		if data, _, ok := db.GetCache(offlineCacheNamespace, site+"|"+key); ok {
+			return json.Unmarshal(data, value)
+		}
+	}
+	return fmt.Errorf("offline: %s was not read while online", key)
+}
+
+// pageModifiedKey is the cache key of when a page was last changed, as read
+// with its content.
+func pageModifiedKey(pageID int) string {
+	return fmt.Sprintf("the modified time of page %d", pageID)
+}
+
+// queueUpdate keeps a page update made offline, with when the page was last
+// changed as read online, to find changes made on the site meanwhile.
+func (s *WordPressService) queueUpdate(pageID int, content string) error {
+	var modified time.Time
+	if err := s.cachedRead(pageModifiedKey(pageID), &modified); err != nil {
+		logger.Warn("Queued update can't be checked for changes made on the site meanwhile", "page_id", pageID, "error", err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	queued := append(s.queued, QueuedUpdate{SiteURL: s.siteURL, PageID: pageID, Content: content, Queued: time.Now(), Modified: modified})
+	if err := saveQueuedUpdates(s.db, queued); err != nil {
+		return err
+	}
+	s.queued = queued
+	logger.Info("Queued page update made offline", logging.Site(s.siteURL), "page_id", pageID, "queued", len(queued))
+	return ErrQueuedOffline
+}
+
+// QueuedUpdates returns the page updates waiting for the network, oldest
+// first.
+func (s *WordPressService) QueuedUpdates() []QueuedUpdate {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]QueuedUpdate(nil), s.queued...)
+}
+
+// ReplayQueued saves the queued updates of the connected site, oldest
+// first, and returns how many it saved. Updates of other sites wait until
+// they are connected; a failed update stays queued and stops the replay.
+// So does an update of a page changed on the site since it was read, with a
+// *QueuedConflictError, rather than overwrite that change.
+func (s *WordPressService) ReplayQueued(ctx context.Context) (int, error) {
+	saved := 0
+	savedPages := map[int]bool{} // Later updates of these pages follow the saved ones
+	for _, update := range s.QueuedUpdates() {
+		if update.SiteURL != s.GetSiteURL() {
+			continue
+		}
+		if !update.Modified.IsZero() && !savedPages[update.PageID] {
+			modified, err := s.pageModified(update.PageID)
+			if err != nil {
+				return saved, fmt.Errorf("failed to check page %d for changes: %w", update.PageID, err)
+			}
+			if !modified.Equal(update.Modified) {
+				logger.Warn("Page changed on the site since it was edited offline", "page_id", update.PageID, "read", update.Modified, "modified", modified)
+				return saved, &QueuedConflictError{Update: update, Modified: modified}
+			}
+		}
+		if err := s.UpdatePageContentContext(ctx, update.PageID, update.Content); err != nil {
+			return saved, fmt.Errorf("failed to save the queued update of page %d: %w", update.PageID, err)
+		}
+		if err := s.unqueue(update, nil); err != nil {
+			return saved, err
+		}
+		savedPages[update.PageID] = true
+		saved++
+	}
+	if saved > 0 {
+		logger.Info("Saved page updates queued offline", "saved", saved)
+	}
+	return saved, nil
+}
+
+// ResolveQueued settles a QueuedConflictError: with overwrite the update is
+// saved by the next ReplayQueued regardless of the change made on the site,
+// otherwise it is discarded.
+func (s *WordPressService) ResolveQueued(update QueuedUpdate, overwrite bool) error {
+	if !overwrite {
+		logger.Info("Discarded page update made offline", "page_id", update.PageID)
+		return s.unqueue(update, nil)
+	}
+	confirmed := update
+	confirmed.Modified = time.Time{}
+	logger.Info("Page update made offline will overwrite the site's changes", "page_id", update.PageID)
+	return s.unqueue(update, &confirmed)
+}
+
+// unqueue removes update from the queue, or puts replacement in its place
+// when there is one, and keeps the queue.
+func (s *WordPressService) unqueue(update QueuedUpdate, replacement *QueuedUpdate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, queued := range s.queued {
+		if queued == update {
+			rest := s.queued[i+1:]
+			if replacement != nil {
+				rest = append([]QueuedUpdate{*replacement}, rest...)
+			}
+			s.queued = append(s.queued[:i:i], rest...)
+			break
+		}
+	}
+	return saveQueuedUpdates(s.db, s.queued)
+}
+
+// pageModified reads when a page was last changed on the site.
+func (s *WordPressService) pageModified(pageID int) (time.Time, error) {
+	var page struct {
+		ModifiedGMT string `json:"modified_gmt"`
+	}
+	if err := s.getJSON(fmt.Sprintf("wp-json/wp/v2/pages/%d?_fields=modified_gmt", pageID), "page", &page); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(wpDateLayout, page.ModifiedGMT)
+}
+
+// saveQueuedUpdates keeps the queued updates in db; without one they only
+// last until the app quits.
+func saveQueuedUpdates(db *storage.DB, queued []QueuedUpdate) error {
+	if db == nil {
+		return nil
+	}
+	data, err := json.Marshal(queued)
+	if err != nil {
+		return err
+	}
+	if err := db.SetDocument(queuedUpdatesDocument, string(data)); err != nil {
+		return fmt.Errorf("failed to queue the update: %w", err)
+	}
+	return nil
+}
+
+// loadQueuedUpdates reads the updates kept by saveQueuedUpdates.
+func loadQueuedUpdates(db *storage.DB) ([]QueuedUpdate, error) {
+	text, ok, err := db.Document(queuedUpdatesDocument)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var queued []QueuedUpdate
+	if err := json.Unmarshal([]byte(text), &queued); err != nil {
+		return nil, fmt.Errorf("failed to read the queued updates: %w", err)
+	}
+	return queued, nil
+}
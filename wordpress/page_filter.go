@@ -0,0 +1,128 @@
+package wordpress
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sort orders supported by PageFilter.
+const (
+	SortByTitleAsc     = "Title (A-Z)"
+	SortByTitleDesc    = "Title (Z-A)"
+	SortByModifiedDesc = "Modified (newest)"
+	SortByModifiedAsc  = "Modified (oldest)"
+	SortByID           = "ID"
+)
+
+// PageSortOptions lists the sort orders in display order.
+var PageSortOptions = []string{SortByTitleAsc, SortByTitleDesc, SortByModifiedDesc, SortByModifiedAsc, SortByID}
+
+// PageStatusOptions lists the WordPress page statuses that can be filtered on.
+var PageStatusOptions = []string{"publish", "draft", "pending", "private", "future"}
+
+// PageFilter describes a search/filter/sort over a page list. Zero values mean "no filter".
+type PageFilter struct {
+	Search         string    // Case-insensitive match against title and slug
+	Status         string    // e.g. "publish", "draft"
+	Author         int       // Author user ID
+	ModifiedAfter  time.Time // Only pages modified after this time
+	ModifiedBefore time.Time // Only pages last modified before this time
+	SortBy         string    // One of the SortBy* constants; defaults to SortByID
+}
+
+// IsEmpty reports whether the filter matches every page.
+func (f PageFilter) IsEmpty() bool {
+	return strings.TrimSpace(f.Search) == "" && f.Status == "" && f.Author == 0 && f.ModifiedAfter.IsZero() && f.ModifiedBefore.IsZero()
+}
+
+// Matches reports whether a page passes the filter.
+func (f PageFilter) Matches(p Page) bool {
+	if query := strings.ToLower(strings.TrimSpace(f.Search)); query != "" {
+		if !strings.Contains(strings.ToLower(p.Title), query) && !strings.Contains(strings.ToLower(p.Slug), query) {
+			return false
+		}
+	}
+	if f.Status != "" && p.Status != f.Status {
+		return false
+	}
+	if f.Author != 0 && p.Author != f.Author {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && !p.Modified.After(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !p.Modified.Before(f.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the pages matching the filter, sorted by f.SortBy.
+// The input list is not modified.
+func (f PageFilter) Apply(pages PageList) PageList {
+	result := make(PageList, 0, len(pages))
+	for _, p := range pages {
+		if f.Matches(p) {
+			result = append(result, p)
+		}
+	}
+	sortPages(result, f.SortBy)
+	return result
+}
+
+// sortPages sorts pages in place by one of the SortBy* orders.
+func sortPages(pages PageList, sortBy string) {
+	less := func(i, j int) bool { return pages[i].ID < pages[j].ID }
+	switch sortBy {
+	case SortByTitleAsc:
+		less = func(i, j int) bool { return strings.ToLower(pages[i].Title) < strings.ToLower(pages[j].Title) }
+	case SortByTitleDesc:
+		less = func(i, j int) bool { return strings.ToLower(pages[i].Title) > strings.ToLower(pages[j].Title) }
+	case SortByModifiedDesc:
+		less = func(i, j int) bool { return pages[i].Modified.After(pages[j].Modified) }
+	case SortByModifiedAsc:
+		less = func(i, j int) bool { return pages[i].Modified.Before(pages[j].Modified) }
+	}
+	sort.SliceStable(pages, less)
+}
+
+// queryParams converts the filter into WordPress REST query parameters.
+func (f PageFilter) queryParams() url.Values {
+	params := url.Values{}
+	if search := strings.TrimSpace(f.Search); search != "" {
+		params.Set("search", search)
+	}
+	if f.Status != "" {
+		params.Set("status", f.Status)
+	}
+	if f.Author != 0 {
+		params.Set("author", strconv.Itoa(f.Author))
+	}
+	if !f.ModifiedAfter.IsZero() {
+		params.Set("modified_after", f.ModifiedAfter.Format(wpDateLayout))
+	}
+	if !f.ModifiedBefore.IsZero() {
+		params.Set("modified_before", f.ModifiedBefore.Format(wpDateLayout))
+	}
+	switch f.SortBy {
+	case SortByTitleAsc:
+		params.Set("orderby", "title")
+		params.Set("order", "asc")
+	case SortByTitleDesc:
+		params.Set("orderby", "title")
+		params.Set("order", "desc")
+	case SortByModifiedDesc:
+		params.Set("orderby", "modified")
+		params.Set("order", "desc")
+	case SortByModifiedAsc:
+		params.Set("orderby", "modified")
+		params.Set("order", "asc")
+	default:
+		params.Set("orderby", "id")
+		params.Set("order", "asc")
+	}
+	return params
+}
@@ -0,0 +1,49 @@
+package wordpress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPageFilterApply(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	pages := PageList{
+		{ID: 1, Title: "About Us", Slug: "about", Status: "publish", Author: 1, Modified: now.AddDate(0, 0, -40)},
+		{ID: 2, Title: "Contact", Slug: "contact", Status: "draft", Author: 2, Modified: now.AddDate(0, 0, -2)},
+		{ID: 3, Title: "about the team", Slug: "team", Status: "publish", Author: 2, Modified: now.AddDate(0, 0, -1)},
+	}
+
+	tests := []struct {
+		name   string
+		filter PageFilter
+		want   []int
+	}{
+		{"empty filter keeps ID order", PageFilter{}, []int{1, 2, 3}},
+		{"search is case-insensitive", PageFilter{Search: "ABOUT"}, []int{1, 3}},
+		{"search matches slug", PageFilter{Search: "team"}, []int{3}},
+		{"status", PageFilter{Status: "draft"}, []int{2}},
+		{"author", PageFilter{Author: 2}, []int{2, 3}},
+		{"modified after", PageFilter{ModifiedAfter: now.AddDate(0, 0, -7)}, []int{2, 3}},
+		{"modified before", PageFilter{ModifiedBefore: now.AddDate(0, 0, -7)}, []int{1}},
+		{"title descending", PageFilter{SortBy: SortByTitleDesc}, []int{2, 1, 3}},
+		{"modified newest first", PageFilter{SortBy: SortByModifiedDesc}, []int{3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.Apply(pages)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d pages, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("page %d: got ID %d, want %d", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+
+	if pages[0].ID != 1 || pages[1].ID != 2 {
+		t.Error("Apply modified the input list")
+	}
+}
@@ -0,0 +1,224 @@
+package wordpress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Post statuses a new post can be created with.
+const (
+	PostDraft   = "draft"
+	PostPublish = "publish"
+)
+
+// NewPost is a post to create: its title, content, status and the IDs of
+// its categories and tags.
+type NewPost struct {
+	Title      string
+	Content    string
+	Status     string // PostDraft or PostPublish
+	Categories []int
+	Tags       []int
+}
+
+// CreatedPost is the ID and link of a post the app created.
+type CreatedPost struct {
+	ID   int
+	Link string
+}
+
+// Term is a post category or tag and the number of posts in it.
+type Term struct {
+	ID    int
+	Name  string
+	Count int
+}
+
+// GetCategories fetches the post categories that have posts, most used
+// first, up to 100 of them.
+func (s *WordPressService) GetCategories() ([]Term, error) {
+	return s.getTerms("categories")
+}
+
+// GetTags fetches the tags that have posts, most used first, up to 100 of
+// them.
+func (s *WordPressService) GetTags() ([]Term, error) {
+	return s.getTerms("tags")
+}
+
+// getTerms fetches the terms of a taxonomy's REST route ("categories" or
+// "tags") that have posts, most used first, up to 100 of them.
+func (s *WordPressService) getTerms(route string) ([]Term, error) {
+	var raw []struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	if err := s.getJSON("wp-json/wp/v2/"+route+"?hide_empty=true&per_page=100&orderby=count&order=desc&_fields=id,name,count", route, &raw); err != nil {
+		return nil, err
+	}
+	terms := make([]Term, len(raw))
+	for i, r := range raw {
+		terms[i] = Term{ID: r.ID, Name: html.UnescapeString(r.Name), Count: r.Count}
+	}
+	return terms, nil
+}
+
+// CreateTag returns the tag named name, creating it unless the site has it
+// already; GetTags leaves out the tags without posts.
+func (s *WordPressService) CreateTag(name string) (Term, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Term{}, fmt.Errorf("the tag name is empty")
+	}
+	if existing, ok, err := s.findTag(name); err != nil || ok {
+		return existing, err
+	}
+	var raw struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	if err := s.postJSON(context.Background(), "wp-json/wp/v2/tags", map[string]interface{}{"name": name}, "tag", &raw); err != nil {
+		return Term{}, err
+	}
+	logger.Info("Created tag", "tag", name, "id", raw.ID)
+	return Term{ID: raw.ID, Name: html.UnescapeString(raw.Name), Count: raw.Count}, nil
+}
+
+// findTag searches the tags for one named name, ignoring case.
+func (s *WordPressService) findTag(name string) (Term, bool, error) {
+	var raw []struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	if err := s.getJSON("wp-json/wp/v2/tags?search="+url.QueryEscape(name)+"&per_page=100&_fields=id,name,count", "tags", &raw); err != nil {
+		return Term{}, false, err
+	}
+	for _, r := range raw {
+		if strings.EqualFold(html.UnescapeString(r.Name), name) {
+			return Term{ID: r.ID, Name: html.UnescapeString(r.Name), Count: r.Count}, true, nil
+		}
+	}
+	return Term{}, false, nil
+}
+
+// CreatePost creates a post, as a draft or published. A draft needs only
+// the edit_posts capability; publishing needs publish_posts as well.
+func (s *WordPressService) CreatePost(ctx context.Context, post NewPost) (CreatedPost, error) {
+	if strings.TrimSpace(post.Title) == "" {
+		return CreatedPost{}, fmt.Errorf("the post title is empty")
+	}
+	status := post.Status
+	if status == "" {
+		status = PostDraft
+	}
+	action := ActionDraftPost
+	if status == PostPublish {
+		action = ActionPublishPost
+	}
+	if err := s.CanDo(action); err != nil {
+		return CreatedPost{}, err
+	}
+	fields := map[string]interface{}{
+		"title":   post.Title,
+		"content": post.Content,
+		"status":  status,
+	}
+	if len(post.Categories) > 0 {
+		fields["categories"] = post.Categories
+	}
+	if len(post.Tags) > 0 {
+		fields["tags"] = post.Tags
+	}
+	var raw struct {
+		ID   int    `json:"id"`
+		Link string `json:"link"`
+	}
+	if err := s.postJSON(ctx, "wp-json/wp/v2/posts", fields, "post", &raw); err != nil {
+		return CreatedPost{}, err
+	}
+	logger.Info("Created post", "id", raw.ID, "status", status, "categories", len(post.Categories), "tags", len(post.Tags))
+	return CreatedPost{ID: raw.ID, Link: raw.Link}, nil
+}
+
+// getJSON fetches a REST API path of the connected site into result; what
+// names the data in errors.
+func (s *WordPressService) getJSON(path, what string, result interface{}) error {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	req, err := http.NewRequest("GET", siteURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: %w", what, apiError(resp))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", what, err)
+	}
+	return nil
+}
+
+// postJSON creates something on the connected site by POSTing fields to a
+// REST API path, and reads the created object into result; what names it
+// in errors.
+func (s *WordPressService) postJSON(ctx context.Context, path string, fields map[string]interface{}, what string, result interface{}) error {
+	if err := s.CanWrite(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	bodyJSON, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to create request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", siteURL+path, bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create %s: %w", what, apiError(resp))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse the created %s: %w", what, err)
+	}
+	return nil
+}
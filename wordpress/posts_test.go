@@ -0,0 +1,97 @@
+package wordpress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreatePostWithTags(t *testing.T) {
+	var post struct {
+		Title      string `json:"title"`
+		Status     string `json:"status"`
+		Categories []int  `json:"categories"`
+		Tags       []int  `json:"tags"`
+	}
+	var createdTags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/wp-json/wp/v2/tags":
+			var body struct {
+				Name string `json:"name"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			createdTags = append(createdTags, body.Name)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 12, "name": "Cold Brew", "count": 0}`))
+		case r.Method == "GET" && r.URL.Path == "/wp-json/wp/v2/tags":
+			if r.URL.Query().Get("search") != "Espresso" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"id": 8, "name": "Espresso Machines", "count": 1}, {"id": 7, "name": "espresso", "count": 0}]`))
+		case r.Method == "POST" && r.URL.Path == "/wp-json/wp/v2/posts":
+			json.NewDecoder(r.Body).Decode(&post)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 99, "link": "https://cafe.example/?p=99"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	s := connectedTo(server)
+
+	created, err := s.CreateTag("Cold Brew")
+	if err != nil || created.ID != 12 {
+		t.Fatalf("CreateTag = %+v, %v", created, err)
+	}
+	existing, err := s.CreateTag("Espresso")
+	if err != nil || existing.ID != 7 {
+		t.Fatalf("CreateTag of an existing tag = %+v, %v", existing, err)
+	}
+	if !reflect.DeepEqual(createdTags, []string{"Cold Brew"}) {
+		t.Errorf("Expected only the new tag to be created, got %v", createdTags)
+	}
+
+	result, err := s.CreatePost(context.Background(), NewPost{Title: "Cold Brew", Content: "<p>Hi</p>", Categories: []int{3}, Tags: []int{12, 7}})
+	if err != nil || result.ID != 99 || result.Link != "https://cafe.example/?p=99" {
+		t.Fatalf("CreatePost = %+v, %v", result, err)
+	}
+	if post.Title != "Cold Brew" || post.Status != PostDraft || !reflect.DeepEqual(post.Categories, []int{3}) || !reflect.DeepEqual(post.Tags, []int{12, 7}) {
+		t.Errorf("Unexpected post sent: %+v", post)
+	}
+
+	if _, err := s.CreatePost(context.Background(), NewPost{Title: " ", Content: "<p>Hi</p>"}); err == nil {
+		t.Error("Expected a post without a title to be refused")
+	}
+}
+
+func TestCreatePostNeedsPublishPostsOnlyToPublish(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wp-json/wp/v2/users/me":
+			w.Write([]byte(`{"name":"Alice","roles":["contributor"],"capabilities":{"edit_posts":true,"read":true}}`))
+		case r.Method == "POST" && r.URL.Path == "/wp-json/wp/v2/posts":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 5, "link": "https://cafe.example/?p=5"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewWordPressService()
+	if err := s.Connect(server.URL, "alice", "secret"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if _, err := s.CreatePost(context.Background(), NewPost{Title: "Cold Brew", Status: PostDraft}); err != nil {
+		t.Errorf("Expected a contributor to create a draft, got %v", err)
+	}
+	if _, err := s.CreatePost(context.Background(), NewPost{Title: "Cold Brew", Status: PostPublish}); err == nil || !strings.Contains(err.Error(), "publish_posts") {
+		t.Errorf("Expected a contributor to be refused publishing, got %v", err)
+	}
+}
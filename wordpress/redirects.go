@@ -0,0 +1,63 @@
+package wordpress
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Redirect sends visitors of a retired page's URL to the page that replaced
+// it. Paths are relative to the site root.
+type Redirect struct {
+	From string
+	To   string
+}
+
+// pagePath returns the path of a page's link, falling back to its ID when
+// the link is missing or has no path (plain permalinks).
+func pagePath(page Page) string {
+	if u, err := url.Parse(page.Link); err == nil && page.Link != "" {
+		if u.Path != "" && u.Path != "/" {
+			return u.Path
+		}
+		if u.RawQuery != "" {
+			return "/?" + u.RawQuery
+		}
+	}
+	return fmt.Sprintf("/?page_id=%d", page.ID)
+}
+
+// MergeRedirects returns the redirects needed when pages are merged into
+// canonical: one from each other page's URL to canonical's.
+func MergeRedirects(pages []Page, canonical Page) []Redirect {
+	to := pagePath(canonical)
+	var redirects []Redirect
+	for _, page := range pages {
+		if from := pagePath(page); page.ID != canonical.ID && from != to {
+			redirects = append(redirects, Redirect{From: from, To: to})
+		}
+	}
+	return redirects
+}
+
+// RedirectNotes describes redirects as a plain list followed by the same
+// rules for Apache (.htaccess) and Nginx, for whoever sets them up.
+func RedirectNotes(redirects []Redirect) string {
+	if len(redirects) == 0 {
+		return "No redirects needed."
+	}
+	var list, apache, nginx strings.Builder
+	for _, r := range redirects {
+		fmt.Fprintf(&list, "%s -> %s\n", r.From, r.To)
+		if strings.HasPrefix(r.From, "/?") {
+			// Query-string URLs need mod_rewrite and a query match in Nginx
+			query := strings.TrimPrefix(r.From, "/?")
+			fmt.Fprintf(&apache, "RewriteCond %%{QUERY_STRING} ^%s$\nRewriteRule ^$ %s? [R=301,L]\n", strings.ReplaceAll(query, ".", `\.`), r.To)
+			fmt.Fprintf(&nginx, "if ($args = \"%s\") { return 301 %s; }\n", query, r.To)
+			continue
+		}
+		fmt.Fprintf(&apache, "Redirect 301 %s %s\n", r.From, r.To)
+		fmt.Fprintf(&nginx, "location = %s { return 301 %s; }\n", r.From, r.To)
+	}
+	return fmt.Sprintf("Redirects (301):\n%s\nApache (.htaccess):\n%s\nNginx:\n%s", list.String(), apache.String(), strings.TrimSpace(nginx.String()))
+}
@@ -0,0 +1,38 @@
+package wordpress
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeRedirects(t *testing.T) {
+	pages := []Page{
+		{ID: 1, Link: "https://example.com/cold-brew/"},
+		{ID: 2, Link: "https://example.com/cold-brew-guide/"},
+		{ID: 3, Link: "https://example.com/?page_id=3"},
+		{ID: 4},
+	}
+	got := MergeRedirects(pages, pages[1])
+	want := []Redirect{
+		{From: "/cold-brew/", To: "/cold-brew-guide/"},
+		{From: "/?page_id=3", To: "/cold-brew-guide/"},
+		{From: "/?page_id=4", To: "/cold-brew-guide/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeRedirects = %+v, want %+v", got, want)
+	}
+
+	notes := RedirectNotes(got)
+	for _, line := range []string{
+		"/cold-brew/ -> /cold-brew-guide/",
+		"Redirect 301 /cold-brew/ /cold-brew-guide/",
+		`RewriteCond %{QUERY_STRING} ^page_id=3$`,
+		"location = /cold-brew/ { return 301 /cold-brew-guide/; }",
+		`if ($args = "page_id=4") { return 301 /cold-brew-guide/; }`,
+	} {
+		if !strings.Contains(notes, line) {
+			t.Errorf("Redirect notes lack %q:\n%s", line, notes)
+		}
+	}
+}
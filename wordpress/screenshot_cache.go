@@ -0,0 +1,128 @@
+package wordpress
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Full screenshots are JPEG
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Inference_Engine/storage"
+
+	"golang.org/x/image/draw"
+)
+
+// ThumbnailWidth is the width in pixels of page list thumbnails.
+const ThumbnailWidth = 160
+
+// ScreenshotCache stores page screenshots on disk or in the state database,
+// keyed by page URL and modified date so an edited page is captured again.
+type ScreenshotCache struct {
+	dir string
+	db  *storage.DB // When set, screenshots are kept in its "screenshots" cache namespace
+}
+
+// screenshotNamespace is the state database cache namespace for screenshots.
+const screenshotNamespace = "screenshots"
+
+// NewScreenshotCache creates a cache in dir, creating the directory if needed.
+func NewScreenshotCache(dir string) (*ScreenshotCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create screenshot cache directory: %w", err)
+	}
+	return &ScreenshotCache{dir: dir}, nil
+}
+
+// NewDBScreenshotCache creates a cache kept in the state database.
+func NewDBScreenshotCache(db *storage.DB) *ScreenshotCache {
+	return &ScreenshotCache{db: db}
+}
+
+// key returns the cache key for a page URL, modified date and kind ("full" or "thumb").
+func (c *ScreenshotCache) key(pageURL string, modified time.Time, kind string) string {
+	sum := sha256.Sum256([]byte(pageURL + "|" + modified.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:16]) + "_" + kind
+}
+
+// path returns the on-disk file for a screenshot's cache key.
+func (c *ScreenshotCache) path(pageURL string, modified time.Time, kind string) string {
+	return filepath.Join(c.dir, c.key(pageURL, modified, kind))
+}
+
+// Get returns a cached image, if present.
+func (c *ScreenshotCache) Get(pageURL string, modified time.Time, kind string) ([]byte, bool) {
+	if c.db != nil {
+		data, _, ok := c.db.GetCache(screenshotNamespace, c.key(pageURL, modified, kind))
+		return data, ok && len(data) > 0
+	}
+	data, err := os.ReadFile(c.path(pageURL, modified, kind))
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores an image in the cache.
+func (c *ScreenshotCache) Put(pageURL string, modified time.Time, kind string, data []byte) error {
+	if c.db != nil {
+		if err := c.db.PutCache(screenshotNamespace, c.key(pageURL, modified, kind), data); err != nil {
+			return fmt.Errorf("failed to write screenshot cache: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(c.path(pageURL, modified, kind), data, 0600); err != nil {
+		return fmt.Errorf("failed to write screenshot cache: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached screenshot.
+func (c *ScreenshotCache) Clear() error {
+	if c.db != nil {
+		if err := c.db.ClearCache(screenshotNamespace); err != nil {
+			return fmt.Errorf("failed to clear screenshot cache: %w", err)
+		}
+		return nil
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read screenshot cache: %w", err)
+	}
+	for _, entry := range entries {
+		os.Remove(filepath.Join(c.dir, entry.Name()))
+	}
+	return nil
+}
+
+// MakeThumbnail scales an encoded screenshot down to width pixels, cropping
+// it to a 16:10 "above the fold" area, and returns it as PNG.
+func MakeThumbnail(data []byte, width int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	bounds := src.Bounds()
+	cropHeight := bounds.Dx() * 10 / 16
+	if cropHeight > bounds.Dy() {
+		cropHeight = bounds.Dy()
+	}
+	crop := image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Min.Y+cropHeight)
+
+	height := width * cropHeight / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,207 @@
+package wordpress
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// latestPostsCount is how many posts {{latest_posts}} lists.
+const latestPostsCount = 5
+
+// TemplateVariable is a {{name}} placeholder that prompts can use, filled in
+// from the connected site when content is generated.
+type TemplateVariable struct {
+	Name        string
+	Description string
+	Feature     Feature // Plugin the variable needs on the site; empty if none
+}
+
+// TemplateVariables are the site data placeholders prompts can use.
+var TemplateVariables = []TemplateVariable{
+	{"site_name", "The site's title", ""},
+	{"site_tagline", "The site's tagline", ""},
+	{"site_url", "The site's home URL", ""},
+	{"latest_posts", "The 5 newest published posts, one per line with their links", ""},
+	{"category_list", "The post categories, most used first", ""},
+	{"product_list", "The 5 newest WooCommerce products, one per line with their prices and links", FeatureWooCommerce},
+}
+
+// AvailableTemplateVariables returns the template variables the connected
+// site has the plugins for.
+func (s *WordPressService) AvailableTemplateVariables() []TemplateVariable {
+	features := s.Features()
+	var available []TemplateVariable
+	for _, v := range TemplateVariables {
+		if v.Feature == "" || features.Has(v.Feature) {
+			available = append(available, v)
+		}
+	}
+	return available
+}
+
+// variablePattern matches {{name}}, with optional spaces inside the braces.
+var variablePattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// isTemplateVariable reports whether name is one of TemplateVariables.
+func isTemplateVariable(name string) bool {
+	for _, v := range TemplateVariables {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UsedTemplateVariables returns the names of the template variables used in
+// texts, each once, in order of first use. Unknown {{names}} are ignored.
+func UsedTemplateVariables(texts ...string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, text := range texts {
+		for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+			if name := match[1]; isTemplateVariable(name) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// ExpandTemplateVariables replaces the template variables in text with
+// values. Variables without a value and unknown {{names}} are left as they
+// are.
+func ExpandTemplateVariables(text string, values map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := values[variablePattern.FindStringSubmatch(match)[1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// TemplateValues fetches the values of the named template variables from
+// the connected site, making only the requests those variables need.
+func (s *WordPressService) TemplateValues(names []string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(names) == 0 {
+		return values, nil
+	}
+	need := map[string]bool{}
+	for _, name := range names {
+		need[name] = true
+	}
+
+	if need["site_name"] || need["site_tagline"] || need["site_url"] {
+		info, err := s.GetSiteInfo()
+		if err != nil {
+			return nil, err
+		}
+		values["site_name"] = html.UnescapeString(info.Name)
+		values["site_tagline"] = html.UnescapeString(info.Description)
+		values["site_url"] = info.URL
+	}
+	if need["latest_posts"] {
+		posts, err := s.GetLatestPosts(latestPostsCount)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, len(posts))
+		for i, post := range posts {
+			lines[i] = fmt.Sprintf("- %s (%s)", post.Title, post.Link)
+		}
+		values["latest_posts"] = strings.Join(lines, "\n")
+	}
+	if need["category_list"] {
+		categories, err := s.GetCategories()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(categories))
+		for i, category := range categories {
+			names[i] = category.Name
+		}
+		values["category_list"] = strings.Join(names, ", ")
+	}
+	if need["product_list"] {
+		if !s.Features().Has(FeatureWooCommerce) {
+			return nil, fmt.Errorf("{{product_list}} needs WooCommerce, which this site doesn't run")
+		}
+		products, err := s.GetLatestProducts(latestPostsCount)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, len(products))
+		for i, product := range products {
+			lines[i] = fmt.Sprintf("- %s, %s (%s)", product.Name, product.Price, product.Link)
+		}
+		values["product_list"] = strings.Join(lines, "\n")
+	}
+	return values, nil
+}
+
+// ProductSummary is a WooCommerce product's name, formatted price and link.
+type ProductSummary struct {
+	Name  string
+	Price string
+	Link  string
+}
+
+// GetLatestProducts fetches the newest WooCommerce products, at most limit
+// of them, from the public Store API.
+func (s *WordPressService) GetLatestProducts(limit int) ([]ProductSummary, error) {
+	var raw []struct {
+		Name      string `json:"name"`
+		Permalink string `json:"permalink"`
+		Prices    struct {
+			Price     string `json:"price"`
+			MinorUnit int    `json:"currency_minor_unit"`
+			Prefix    string `json:"currency_prefix"`
+			Suffix    string `json:"currency_suffix"`
+		} `json:"prices"`
+	}
+	if err := s.getJSON(fmt.Sprintf("wp-json/wc/store/v1/products?per_page=%d&orderby=date&order=desc", limit), "products", &raw); err != nil {
+		return nil, err
+	}
+	products := make([]ProductSummary, len(raw))
+	for i, r := range raw {
+		products[i] = ProductSummary{Name: html.UnescapeString(r.Name), Price: formatPrice(r.Prices.Price, r.Prices.MinorUnit, r.Prices.Prefix, r.Prices.Suffix), Link: r.Permalink}
+	}
+	return products, nil
+}
+
+// formatPrice formats a Store API price, given in minor units ("1250" with
+// 2 minor units is 12.50), with its currency prefix and suffix.
+func formatPrice(minor string, minorUnit int, prefix, suffix string) string {
+	if minorUnit > 0 {
+		minor = strings.Repeat("0", max(0, minorUnit+1-len(minor))) + minor
+		minor = minor[:len(minor)-minorUnit] + "." + minor[len(minor)-minorUnit:]
+	}
+	return html.UnescapeString(prefix + minor + suffix)
+}
+
+// PostSummary is a published post's title and link.
+type PostSummary struct {
+	Title string
+	Link  string
+}
+
+// GetLatestPosts fetches the newest published posts, at most limit of them.
+func (s *WordPressService) GetLatestPosts(limit int) ([]PostSummary, error) {
+	var raw []struct {
+		Title struct {
+			Rendered string `json:"rendered"`
+		} `json:"title"`
+		Link string `json:"link"`
+	}
+	if err := s.getJSON(fmt.Sprintf("wp-json/wp/v2/posts?status=publish&per_page=%d&orderby=date&order=desc&_fields=title,link", limit), "latest posts", &raw); err != nil {
+		return nil, err
+	}
+	posts := make([]PostSummary, len(raw))
+	for i, r := range raw {
+		posts[i] = PostSummary{Title: html.UnescapeString(r.Title.Rendered), Link: r.Link}
+	}
+	return posts, nil
+}
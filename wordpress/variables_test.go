@@ -0,0 +1,104 @@
+package wordpress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	prompt := "Write for {{site_name}} about {{ category_list }}. Not {{unknown}}, and {{site_name}} again."
+	if got := UsedTemplateVariables(prompt, "{{latest_posts}}"); !reflect.DeepEqual(got, []string{"site_name", "category_list", "latest_posts"}) {
+		t.Errorf("UsedTemplateVariables = %v", got)
+	}
+	got := ExpandTemplateVariables(prompt, map[string]string{"site_name": "Corner Cafe", "category_list": "Coffee, Cake"})
+	if want := "Write for Corner Cafe about Coffee, Cake. Not {{unknown}}, and Corner Cafe again."; got != want {
+		t.Errorf("ExpandTemplateVariables = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateValues(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/wp-json/":
+			w.Write([]byte(`{"name": "Corner &amp; Cafe", "description": "Coffee", "home": "https://cafe.example"}`))
+		case "/wp-json/wp/v2/posts":
+			if r.URL.Query().Get("per_page") != "5" || r.URL.Query().Get("status") != "publish" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`[{"title": {"rendered": "Latte &#8211; Art"}, "link": "https://cafe.example/latte/"}, {"title": {"rendered": "Scones"}, "link": "https://cafe.example/scones/"}]`))
+		case "/wp-json/wp/v2/categories":
+			w.Write([]byte(`[{"name": "Recipes", "count": 12}, {"name": "News", "count": 3}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := connectedTo(server)
+	values, err := s.TemplateValues([]string{"site_name", "latest_posts", "category_list"})
+	if err != nil {
+		t.Fatalf("TemplateValues failed: %v", err)
+	}
+	if values["site_name"] != "Corner & Cafe" || values["site_url"] != "https://cafe.example" {
+		t.Errorf("Unexpected site values %v", values)
+	}
+	if want := "- Latte – Art (https://cafe.example/latte/)\n- Scones (https://cafe.example/scones/)"; values["latest_posts"] != want {
+		t.Errorf("latest_posts = %q, want %q", values["latest_posts"], want)
+	}
+	if values["category_list"] != "Recipes, News" {
+		t.Errorf("category_list = %q", values["category_list"])
+	}
+
+	requests = map[string]int{}
+	if _, err := s.TemplateValues([]string{"category_list"}); err != nil {
+		t.Fatalf("TemplateValues failed: %v", err)
+	}
+	if len(requests) != 1 || requests["/wp-json/wp/v2/categories"] != 1 {
+		t.Errorf("Expected only the categories to be fetched, got %v", requests)
+	}
+}
+
+func TestProductList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/":
+			w.Write([]byte(`{"namespaces": ["oembed/1.0", "wc/store/v1", "wc/v3", "wp/v2"]}`))
+		case "/wp-json/wc/store/v1/products":
+			w.Write([]byte(`[{"name": "Beans &amp; Grinder", "permalink": "https://cafe.example/beans/", "prices": {"price": "1250", "currency_minor_unit": 2, "currency_prefix": "&#36;", "currency_suffix": ""}}, {"name": "Mug", "permalink": "https://cafe.example/mug/", "prices": {"price": "5", "currency_minor_unit": 2, "currency_prefix": "", "currency_suffix": " €"}}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewWordPressService()
+	if err := s.Connect(server.URL, "admin", "secret"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if features := s.Features(); !features.Has(FeatureWooCommerce) || features.Has(FeatureYoast) || !reflect.DeepEqual(features.Detected(), []Feature{FeatureWooCommerce}) {
+		t.Errorf("Expected only WooCommerce to be detected, got %+v", features)
+	}
+	if variables := s.AvailableTemplateVariables(); variables[len(variables)-1].Name != "product_list" {
+		t.Errorf("Expected {{product_list}} to be available, got %v", variables)
+	}
+	values, err := s.TemplateValues([]string{"product_list"})
+	if err != nil {
+		t.Fatalf("TemplateValues failed: %v", err)
+	}
+	if want := "- Beans & Grinder, $12.50 (https://cafe.example/beans/)\n- Mug, 0.05 € (https://cafe.example/mug/)"; values["product_list"] != want {
+		t.Errorf("product_list = %q, want %q", values["product_list"], want)
+	}
+
+	s.features = SiteFeatures{Known: true, Namespaces: []string{"wp/v2"}}
+	if _, err := s.TemplateValues([]string{"product_list"}); err == nil {
+		t.Error("Expected {{product_list}} to fail without WooCommerce")
+	}
+	if len(s.AvailableTemplateVariables()) != len(TemplateVariables)-1 {
+		t.Error("Expected {{product_list}} to be hidden without WooCommerce")
+	}
+}
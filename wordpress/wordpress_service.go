@@ -3,11 +3,11 @@ package wordpress
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,9 +17,16 @@ import (
 	"sync"
 	"time"
 
+	"Inference_Engine/logging"
+	"Inference_Engine/storage"
+	"Inference_Engine/tracing"
+
 	"github.com/chromedp/chromedp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var logger = logging.For("wordpress")
+
 // WordPressService manages the interaction with a WordPress site via the REST API
 type WordPressService struct {
 	siteURL            string
@@ -31,15 +38,31 @@ type WordPressService struct {
 	savedSites         []SavedSite
 	currentSiteName    string
 	siteChangeCallback func()
+	pageContentHook    func(site string, pageID int, content string, saved bool)
+	secrets            SecretStore      // Keeps application passwords; nil stores them with the sites
+	screenshotCache    *ScreenshotCache // Created on first use, see screenshots()
+	db                 *storage.DB      // State database; nil keeps saved sites in saved_sites.json
+	writeGuard         func() error     // Refuses writes to the site, e.g. in the editor profile; nil allows them
+	capabilities       Capabilities     // What the connected account may do, see Capabilities
+	features           SiteFeatures     // Plugins found on the connected site, see Features
+	offline            bool             // Reads come from the offline cache and page updates are queued, see SetOffline
+	queued             []QueuedUpdate   // Page updates made offline, oldest first
 }
 
 // Page represents a WordPress page
 type Page struct {
-	ID      int    `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Slug    string `json:"slug"`
-	Link    string `json:"link"`
+	ID       int       `json:"id"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	Slug     string    `json:"slug"`
+	Link     string    `json:"link"`
+	Status   string    `json:"status"`
+	Author   int       `json:"author"`
+	Modified time.Time `json:"modified"`
+
+	// Only fetched by GetPagesForAudit
+	Excerpt         string `json:"excerpt"`          // The hand-written excerpt, empty if WordPress generates it
+	MetaDescription string `json:"meta_description"` // Set by Yoast SEO, if installed
 }
 
 // SavedSite represents a saved WordPress site with credentials
@@ -50,6 +73,13 @@ type SavedSite struct {
 	AppPassword string `json:"appPassword"` // This will be stored encrypted
 }
 
+// SecretStore keeps the application passwords of saved sites apart from
+// the site list, such as in the encrypted vault.
+type SecretStore interface {
+	Get(name string) (string, bool)
+	Set(name, value string) error // An empty value removes the secret
+}
+
 // PageList represents a list of WordPress pages
 type PageList []Page
 
@@ -91,20 +121,32 @@ func (s *WordPressService) GetCurrentSiteName() string {
 	return s.currentSiteName
 }
 
+// GetSiteURL returns the connected site's URL, empty if not connected.
+func (s *WordPressService) GetSiteURL() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.siteURL
+}
+
 // SaveSite saves a site's credentials to the configuration file
 func (s *WordPressService) SaveSite(name, siteURL, username, appPassword string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	stored, err := s.storePassword(name, appPassword)
+	if err != nil {
+		return err
+	}
+
 	// Check if site with this name already exists
 	for i, site := range s.savedSites {
 		if site.Name == name {
 			// Update existing site
 			s.savedSites[i].URL = siteURL
 			s.savedSites[i].Username = username
-			s.savedSites[i].AppPassword = encryptPassword(appPassword)
+			s.savedSites[i].AppPassword = stored
 			s.currentSiteName = name
-			return s.saveSitesToFile()
+			return s.saveSites()
 		}
 	}
 
@@ -113,18 +155,23 @@ func (s *WordPressService) SaveSite(name, siteURL, username, appPassword string)
 		Name:        name,
 		URL:         siteURL,
 		Username:    username,
-		AppPassword: encryptPassword(appPassword),
+		AppPassword: stored,
 	})
 	s.currentSiteName = name
 	if s.siteChangeCallback != nil {
 		s.siteChangeCallback()
 	}
 
-	return s.saveSitesToFile()
+	return s.saveSites()
 }
 
-// saveSitesToFile saves the sites to a JSON file
-func (s *WordPressService) saveSitesToFile() error {
+// saveSites persists the saved sites to the state database, or to a JSON
+// file when there is none. Caller holds the mutex.
+func (s *WordPressService) saveSites() error {
+	if s.db != nil {
+		return s.saveSitesToDB()
+	}
+
 	configDir, err := s.GetConfigDir()
 	if err != nil {
 		return err
@@ -175,6 +222,77 @@ func (s *WordPressService) LoadSavedSites() error {
 	return nil
 }
 
+// SetStateStore moves saved sites and the screenshot cache into the state
+// database. Sites from saved_sites.json are imported the first time.
+func (s *WordPressService) SetStateStore(db *storage.DB) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sites, err := loadSitesFromDB(db)
+	if err != nil {
+		return err
+	}
+	queued, err := loadQueuedUpdates(db)
+	if err != nil {
+		return err
+	}
+	s.db = db
+	s.queued = queued
+	s.screenshotCache = nil // Recreated in the database by screenshots()
+	if configDir, err := s.GetConfigDir(); err == nil && len(sites) == 0 {
+		_, err := storage.ImportLegacyFile(filepath.Join(configDir, "saved_sites.json"), func(data []byte) error {
+			if err := json.Unmarshal(data, &sites); err != nil {
+				return err
+			}
+			s.savedSites = sites
+			return s.saveSitesToDB()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s.savedSites = sites
+	return nil
+}
+
+// loadSitesFromDB reads the saved sites, in the order they were added.
+func loadSitesFromDB(db *storage.DB) ([]SavedSite, error) {
+	rows, err := db.Query(`SELECT name, url, username, app_password FROM sites ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved sites: %w", err)
+	}
+	defer rows.Close()
+	sites := []SavedSite{}
+	for rows.Next() {
+		var site SavedSite
+		if err := rows.Scan(&site.Name, &site.URL, &site.Username, &site.AppPassword); err != nil {
+			return nil, fmt.Errorf("failed to load saved sites: %w", err)
+		}
+		sites = append(sites, site)
+	}
+	return sites, rows.Err()
+}
+
+// saveSitesToDB replaces the stored sites with s.savedSites. Caller holds the mutex.
+func (s *WordPressService) saveSitesToDB() error {
+	err := s.db.Tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM sites`); err != nil {
+			return err
+		}
+		for i, site := range s.savedSites {
+			if _, err := tx.Exec(`INSERT INTO sites (position, name, url, username, app_password) VALUES (?, ?, ?, ?, ?)`,
+				i, site.Name, site.URL, site.Username, site.AppPassword); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save sites: %w", err)
+	}
+	return nil
+}
+
 // GetSavedSites returns the list of saved sites
 func (s *WordPressService) GetSavedSites() []SavedSite {
 	s.mutex.Lock()
@@ -195,11 +313,15 @@ func (s *WordPressService) GetSavedSite(name string) (SavedSite, bool) {
 	for _, site := range s.savedSites {
 		if site.Name == name {
 			// Return a copy with decrypted password
+			password := decryptPassword(site.AppPassword)
+			if site.AppPassword == "" && s.secrets != nil {
+				password, _ = s.secrets.Get(passwordSecret(site.Name))
+			}
 			return SavedSite{
 				Name:        site.Name,
 				URL:         site.URL,
 				Username:    site.Username,
-				AppPassword: decryptPassword(site.AppPassword),
+				AppPassword: password,
 			}, true
 		}
 	}
@@ -216,13 +338,61 @@ func (s *WordPressService) DeleteSavedSite(name string) error {
 		if site.Name == name {
 			// Remove site from slice
 			s.savedSites = append(s.savedSites[:i], s.savedSites[i+1:]...)
-			return s.saveSitesToFile()
+			if s.secrets != nil {
+				if err := s.secrets.Set(passwordSecret(name), ""); err != nil {
+					logger.Warn("Failed to remove the site's password from the vault", "site", name, "error", err)
+				}
+			}
+			return s.saveSites()
 		}
 	}
 
 	return fmt.Errorf("site with name '%s' not found", name)
 }
 
+// SetSecretStore keeps application passwords in store from now on, and
+// moves the passwords of the saved sites into it. The store must accept
+// secrets (an unlocked vault).
+func (s *WordPressService) SetSecretStore(store SecretStore) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.secrets = store
+	moved := 0
+	for i, site := range s.savedSites {
+		if site.AppPassword == "" {
+			continue
+		}
+		if err := store.Set(passwordSecret(site.Name), decryptPassword(site.AppPassword)); err != nil {
+			return fmt.Errorf("failed to move the password of '%s': %w", site.Name, err)
+		}
+		s.savedSites[i].AppPassword = ""
+		moved++
+	}
+	if moved == 0 {
+		return nil
+	}
+	logger.Info("Moved saved site passwords to the secret store", "sites", moved)
+	return s.saveSites()
+}
+
+// storePassword puts a site's password in the secret store and returns
+// what to save with the site: nothing, or the encoded password when there
+// is no store. Caller holds the mutex.
+func (s *WordPressService) storePassword(name, appPassword string) (string, error) {
+	if s.secrets == nil {
+		return encryptPassword(appPassword), nil
+	}
+	if err := s.secrets.Set(passwordSecret(name), appPassword); err != nil {
+		return "", fmt.Errorf("failed to store the password in the vault: %w", err)
+	}
+	return "", nil
+}
+
+// passwordSecret names a site's password in the secret store.
+func passwordSecret(site string) string {
+	return "wordpress:" + site
+}
+
 // Simple encryption/decryption functions (for demonstration purposes)
 // In a production environment, use a more secure encryption method
 
@@ -242,8 +412,9 @@ func decryptPassword(encrypted string) string {
 
 // Connect establishes a connection to the WordPress site
 func (s *WordPressService) Connect(siteURL, username, appPassword string) error {
+	siteLog := logger.With(logging.Site(siteURL))
 	s.mutex.Lock() // Lock at start
-	log.Println("wpService.Connect: Lock acquired.")
+	siteLog.Debug("Connect: lock acquired")
 
 	// Use flags and variables to manage state across the lock release
 	var callbackToCall func() = nil
@@ -255,20 +426,20 @@ func (s *WordPressService) Connect(siteURL, username, appPassword string) error
 		// Only unlock if connection wasn't successful OR if we didn't need a callback
 		// If connection was successful AND callback was needed, it was unlocked manually.
 		if !connectionSuccessful || callbackToCall == nil {
-			log.Println("wpService.Connect: Releasing lock via defer.")
+			siteLog.Debug("Connect: releasing lock via defer")
 			s.mutex.Unlock()
 		} else {
-			log.Println("wpService.Connect: Lock was released manually before callback, defer skipped unlock.")
+			siteLog.Debug("Connect: lock was released before the callback, skipping deferred unlock")
 		}
 	}()
 
 	// ... (Input validation) ...
 	if siteURL == "" || username == "" || appPassword == "" {
-		log.Println("wpService.Connect: Input validation failed.")
+		siteLog.Warn("Connect: input validation failed")
 		// Return error (defer will unlock)
 		return fmt.Errorf("site URL, username, and application password cannot be empty")
 	}
-	log.Println("wpService.Connect: Input validated.")
+	siteLog.Debug("Connect: input validated")
 
 	// Normalize site URL (ensure it ends with /)
 	if !strings.HasSuffix(siteURL, "/") {
@@ -280,49 +451,59 @@ func (s *WordPressService) Connect(siteURL, username, appPassword string) error
 	if err != nil {
 		return fmt.Errorf("invalid site URL: %w", err)
 	}
-	log.Printf("wpService.Connect: Normalized URL: %s", siteURL)
+	siteLog.Debug("Connect: normalized URL", "url", siteURL)
 
 	// Test connection by making a simple request to the WordPress REST API
 	testURL := fmt.Sprintf("%swp-json/wp/v2/pages?per_page=1", siteURL)
-	log.Printf("wpService.Connect: Creating request for test URL: %s", testURL)
+	siteLog.Debug("Connect: creating request", "url", testURL)
 	req, err := http.NewRequest("GET", testURL, nil)
 	if err != nil {
-		log.Printf("wpService.Connect: Error creating request: %v", err)
+		siteLog.Error("Connect: error creating request", "error", err)
 		return fmt.Errorf("failed to create request: %w", err)
 	}
-	log.Println("wpService.Connect: Request created.")
+	siteLog.Debug("Connect: request created")
 
 	// Add basic auth header
 	req.SetBasicAuth(username, appPassword)
-	log.Println("wpService.Connect: Basic auth set.")
+	siteLog.Debug("Connect: basic auth set")
 
 	// Make the request
-	log.Printf("wpService.Connect: Executing client.Do(req). Timeout: %v", s.client.Timeout)
+	siteLog.Info("Connect: testing credentials", "timeout", s.client.Timeout)
 	resp, err := s.client.Do(req)
 	// Check for network errors first
 	if err != nil {
-		log.Printf("wpService.Connect: client.Do(req) failed. Error: %v", err)
+		siteLog.Error("Connect: request failed", "error", err)
 		// Return error (defer will unlock)
 		return fmt.Errorf("failed to connect to WordPress site: %w", err)
 	}
 	// Ensure body is closed even if status check fails
 	defer resp.Body.Close()
-	log.Printf("wpService.Connect: client.Do(req) finished. Response Status: %s", resp.Status)
+	siteLog.Info("Connect: response received", "status", resp.Status)
 
 	// Check response status code
-	log.Printf("wpService.Connect: Response status code: %d", resp.StatusCode)
 	if resp.StatusCode != http.StatusOK {
 		// Return error (defer will unlock)
-		return fmt.Errorf("failed to authenticate with WordPress site: HTTP %d", resp.StatusCode)
+		return fmt.Errorf("failed to authenticate with WordPress site: %w", apiError(resp))
 	}
 
 	// --- If we reach here, connection is successful ---
 	connectionSuccessful = true // Mark as successful for defer logic
-	log.Println("wpService.Connect: Connection successful. Storing credentials.")
+	siteLog.Info("Connect: connection successful, storing credentials")
 	s.siteURL = siteURL
 	s.username = username
 	s.appPassword = appPassword
 	s.isConnected = true
+	capabilities, err := s.fetchCapabilities(siteURL, username, appPassword)
+	if err != nil {
+		siteLog.Warn("Connect: couldn't read the account's capabilities, allowing every action", "error", err)
+	}
+	s.capabilities = capabilities
+	features, err := s.fetchFeatures(siteURL, username, appPassword)
+	if err != nil {
+		siteLog.Warn("Connect: couldn't read the REST API index, enabling every feature", "error", err)
+	}
+	s.features = features
+	siteLog.Info("Connect: detected site features", "features", features.Detected())
 
 	// Check for saved site and prepare for callback
 	for _, site := range s.savedSites {
@@ -338,18 +519,18 @@ func (s *WordPressService) Connect(siteURL, username, appPassword string) error
 
 	// If we need to call the callback, unlock manually FIRST
 	if callbackToCall != nil {
-		log.Println("wpService.Connect: Releasing lock manually before callback.")
+		siteLog.Debug("Connect: releasing lock before callback")
 		s.mutex.Unlock() // Manual unlock
 
-		log.Printf("wpService.Connect: Calling siteChangeCallback for site: %s", siteNameFound)
+		siteLog.Debug("Connect: calling site change callback", "name", siteNameFound)
 		callbackToCall() // Call the callback (lock is released)
-		log.Println("wpService.Connect: siteChangeCallback finished.")
+		siteLog.Debug("Connect: site change callback finished")
 	} else {
-		log.Println("wpService.Connect: No callback needed or no matching site found.")
+		siteLog.Debug("Connect: no callback needed or no matching saved site")
 		// If no callback, the defer will handle the unlock
 	}
 
-	log.Println("wpService.Connect: Returning nil (success).")
+	siteLog.Debug("Connect: done")
 	return nil // Success!
 }
 
@@ -368,20 +549,26 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
         return nil, fmt.Errorf("not connected to WordPress site")
     }
     siteURL := s.siteURL
+    siteLog := logger.With(logging.Site(siteURL))
     username := s.username
     appPassword := s.appPassword
+    offline := s.offline
     s.mutex.Unlock()
+    if offline {
+        var cached PageList
+        return cached, s.cachedRead("the page list", &cached)
+    }
 
     var allPages []map[string]interface{} // Store results from all pages
     currentPage := 1
     totalPages := 1 // Initialize to 1, will be updated after the first request
 
-    log.Printf("wpService.GetPages: Starting pagination fetch (perPage=%d)", perPage)
+    siteLog.Info("GetPages: starting pagination fetch", "per_page", perPage)
 
 	for { // Loop indefinitely until we determine total pages or finish
 		// Create request URL with pagination parameters
-		requestURL := fmt.Sprintf("%swp-json/wp/v2/pages?per_page=%d&page=%d&orderby=id&order=asc&_fields=id,title,content,slug,link", siteURL, perPage, currentPage)
-		log.Printf("wpService.GetPages: Fetching page %d from URL: %s", currentPage, requestURL)
+		requestURL := fmt.Sprintf("%swp-json/wp/v2/pages?per_page=%d&page=%d&orderby=id&order=asc&_fields=%s", siteURL, perPage, currentPage, pageListFields)
+		siteLog.Info("GetPages: fetching batch", "batch", currentPage, "url", requestURL)
 
 		// Create request
 		req, err := http.NewRequest("GET", requestURL, nil)
@@ -395,7 +582,7 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
 		// Make the request
 		resp, err := s.client.Do(req)
 		if err != nil {
-			log.Printf("wpService.GetPages: Failed HTTP request for page %d: %v", currentPage, err)
+			siteLog.Error("GetPages: request failed", "batch", currentPage, "error", err)
 			return nil, fmt.Errorf("failed to fetch page %d: %w", currentPage, err)
 		}
 
@@ -406,13 +593,13 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
 				parsedTotal, parseErr := strconv.Atoi(headerTotalPages)
 				if parseErr == nil && parsedTotal > 0 {
 					totalPages = parsedTotal
-					log.Printf("wpService.GetPages: Determined total pages from header: %d", totalPages)
+					siteLog.Debug("GetPages: total batches from header", "total_batches", totalPages)
 				} else {
-					log.Printf("wpService.GetPages: Warning - Could not parse X-WP-TotalPages header ('%s'): %v", headerTotalPages, parseErr)
+					siteLog.Warn("GetPages: could not parse X-WP-TotalPages header", "header", headerTotalPages, "error", parseErr)
 					// Continue, but we might fetch an extra empty page if parsing failed
 				}
 			} else {
-				log.Println("wpService.GetPages: Warning - X-WP-TotalPages header not found. Will rely on empty batch detection.")
+				siteLog.Warn("GetPages: X-WP-TotalPages header not found, relying on empty batch detection")
 				// If header is missing, we have to rely on the old method (empty batch)
 			}
 		}
@@ -420,49 +607,49 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
 
 		// Check response status
 		if resp.StatusCode != http.StatusOK {
-			errorBodyBytes, _ := io.ReadAll(resp.Body)
+			apiErr := apiError(resp)
 			resp.Body.Close()
-			log.Printf("wpService.GetPages: Received non-OK status for page %d: HTTP %d. Body: %s", currentPage, resp.StatusCode, string(errorBodyBytes))
+			siteLog.Error("GetPages: non-OK status", "batch", currentPage, "status", resp.StatusCode, "error", apiErr)
 			// If we get a 400 on a page we expected based on totalPages, something is wrong
 			if resp.StatusCode == http.StatusBadRequest && currentPage > totalPages {
 				// This might happen if totalPages header was missing/wrong and we overshoot
-				log.Printf("wpService.GetPages: Received status %d on expected page %d (totalPages %d), assuming end.", resp.StatusCode, currentPage, totalPages)
+				siteLog.Info("GetPages: batch past the end, assuming done", "batch", currentPage, "status", resp.StatusCode, "total_batches", totalPages)
 				break // Exit loop gracefully
 			}
 			// For other errors, return the error
-			return nil, fmt.Errorf("failed to fetch page %d: HTTP %d", currentPage, resp.StatusCode)
+			return nil, fmt.Errorf("failed to fetch page %d: %w", currentPage, apiErr)
 		}
 
 		// Read the body
 		bodyBytes, readErr := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		if readErr != nil {
-			log.Printf("wpService.GetPages: Error reading response body for page %d: %v", currentPage, readErr)
+			siteLog.Error("GetPages: error reading response body", "batch", currentPage, "error", readErr)
 			return nil, fmt.Errorf("failed to read page response body for page %d: %w", currentPage, readErr)
 		}
 
-		log.Printf("wpService.GetPages: Received Body for batch %d (length %d)", currentPage, len(bodyBytes)) // Removed body content log for brevity
+		siteLog.Debug("GetPages: received batch body", "batch", currentPage, "bytes", len(bodyBytes))
 
 		// Decode the current batch
 		var batchPages []map[string]interface{}
 		if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&batchPages); err != nil {
-			log.Printf("wpService.GetPages: Error decoding JSON for page %d: %v", currentPage, err)
+			siteLog.Error("GetPages: error decoding JSON", "batch", currentPage, "error", err)
 			return nil, fmt.Errorf("failed to parse pages response for batch %d: %w", currentPage, err)
 		}
 
 		// If the batch is empty (can happen even if header was present but wrong, or if header was missing)
 		if len(batchPages) == 0 {
-			log.Printf("wpService.GetPages: Received empty batch on page %d, stopping fetch.", currentPage)
+			siteLog.Info("GetPages: empty batch, stopping fetch", "batch", currentPage)
 			break // Exit the loop
 		}
 
 		// Append the fetched batch
 		allPages = append(allPages, batchPages...)
-		log.Printf("wpService.GetPages: Added %d pages from batch %d. Total pages so far: %d", len(batchPages), currentPage, len(allPages))
+		siteLog.Info("GetPages: added pages from batch", "batch", currentPage, "count", len(batchPages), "total", len(allPages))
 
 		// Check if we've fetched the last known page
 		if currentPage >= totalPages {
-			log.Printf("wpService.GetPages: Reached expected total pages (%d). Stopping fetch.", totalPages)
+			siteLog.Info("GetPages: reached expected total batches, stopping fetch", "total_batches", totalPages)
 			break // Exit loop
 		}
 
@@ -474,11 +661,26 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
 
 	} // End of pagination loop
 
-	log.Printf("wpService.GetPages: Finished pagination. Total pages fetched: %d. Converting to PageList.", len(allPages))
+	siteLog.Info("GetPages: finished pagination", "total", len(allPages))
 
 	// Convert the combined results to PageList (same conversion logic as before)
+	pageList := parsePageList(allPages)
+
+	siteLog.Debug("GetPages: converted pages to PageList", "count", len(pageList))
+	s.cacheRead("the page list", pageList)
+	return pageList, nil
+}
+
+// pageListFields are the REST fields requested for page listings.
+const pageListFields = "id,title,content,slug,link,status,author,modified"
+
+// wpDateLayout is the format of WordPress REST date fields (site-local, no zone).
+const wpDateLayout = "2006-01-02T15:04:05"
+
+// parsePageList converts decoded REST page objects into a PageList.
+func parsePageList(rawPages []map[string]interface{}) PageList {
 	var pageList PageList
-	for _, pageData := range allPages {
+	for _, pageData := range rawPages {
 		id, _ := pageData["id"].(float64)
 		titleMap, _ := pageData["title"].(map[string]interface{})
 		titleRendered, _ := titleMap["rendered"].(string)
@@ -486,17 +688,149 @@ func (s *WordPressService) GetPages(page, perPage int) (PageList, error) {
 		contentRendered, _ := contentMap["rendered"].(string)
 		slug, _ := pageData["slug"].(string)
 		link, _ := pageData["link"].(string)
+		status, _ := pageData["status"].(string)
+		author, _ := pageData["author"].(float64)
+		modifiedStr, _ := pageData["modified"].(string)
+		modified, _ := time.Parse(wpDateLayout, modifiedStr)
+		excerptMap, _ := pageData["excerpt"].(map[string]interface{})
+		excerptRaw, _ := excerptMap["raw"].(string)
+		yoastHead, _ := pageData["yoast_head_json"].(map[string]interface{})
+		metaDescription, _ := yoastHead["description"].(string)
 
 		pageList = append(pageList, Page{
-			ID:      int(id),
-			Title:   titleRendered,
-			Content: contentRendered,
-			Slug:    slug,
-			Link:    link,
+			ID:       int(id),
+			Title:    titleRendered,
+			Content:  contentRendered,
+			Slug:     slug,
+			Link:     link,
+			Status:   status,
+			Author:   int(author),
+			Modified: modified,
+
+			Excerpt:         excerptRaw,
+			MetaDescription: metaDescription,
 		})
 	}
+	return pageList
+}
+
+// pageSummaryFields are the REST fields requested for lazily loaded list batches.
+// Content is left out to keep batches small; it is fetched per page on demand.
+const pageSummaryFields = "id,title,slug,link,status,author,modified"
+
+// GetPagesBatch fetches a single batch (REST page number `page`) of the page list,
+// without content, and returns the total number of batches reported by the server.
+func (s *WordPressService) GetPagesBatch(page, perPage int) (PageList, int, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, 0, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	siteLog := logger.With(logging.Site(siteURL))
+	username := s.username
+	appPassword := s.appPassword
+	offline := s.offline
+	s.mutex.Unlock()
+	batchKey := fmt.Sprintf("batch %d of the page list (%d per batch)", page, perPage)
+	if offline {
+		var cached cachedBatch
+		err := s.cachedRead(batchKey, &cached)
+		return cached.Pages, cached.TotalBatches, err
+	}
+
+	requestURL := fmt.Sprintf("%swp-json/wp/v2/pages?per_page=%d&page=%d&orderby=id&order=asc&_fields=%s", siteURL, perPage, page, pageSummaryFields)
+	siteLog.Info("GetPagesBatch: fetching batch", "batch", page, "url", requestURL)
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request for batch %d: %w", page, err)
+	}
+	req.SetBasicAuth(username, appPassword)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch batch %d: %w", page, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest && page > 1 {
+		// WordPress answers 400 (rest_post_invalid_page_number) past the last batch
+		siteLog.Info("GetPagesBatch: batch is past the end of the list", "batch", page)
+		return PageList{}, page - 1, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to fetch batch %d: %w", page, apiError(resp))
+	}
+
+	totalBatches := 0
+	if header := resp.Header.Get("X-WP-TotalPages"); header != "" {
+		totalBatches, _ = strconv.Atoi(header)
+	}
+
+	var rawPages []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&rawPages); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse pages response for batch %d: %w", page, err)
+	}
+	if totalBatches == 0 {
+		// Header missing: assume there is more unless this batch came back short
+		totalBatches = page
+		if len(rawPages) == perPage {
+			totalBatches = page + 1
+		}
+	}
+
+	pageList := parsePageList(rawPages)
+	siteLog.Info("GetPagesBatch: received pages", "batch", page, "total_batches", totalBatches, "count", len(pageList))
+	s.cacheRead(batchKey, cachedBatch{Pages: pageList, TotalBatches: totalBatches})
+	return pageList, totalBatches, nil
+}
 
-	log.Printf("wpService.GetPages: Successfully converted %d pages to PageList.", len(pageList))
+// QueryPages runs a server-side page search, for sites too large to filter the
+// cached list locally. Only the first maxResults matches are returned.
+func (s *WordPressService) QueryPages(filter PageFilter, maxResults int) (PageList, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	siteLog := logger.With(logging.Site(siteURL))
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	if maxResults <= 0 || maxResults > 100 {
+		maxResults = 100 // REST API per_page limit
+	}
+	params := filter.queryParams()
+	params.Set("per_page", strconv.Itoa(maxResults))
+	params.Set("_fields", pageListFields)
+	requestURL := fmt.Sprintf("%swp-json/wp/v2/pages?%s", siteURL, params.Encode())
+	siteLog.Info("QueryPages: fetching", "url", requestURL)
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page query request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query pages: %w", apiError(resp))
+	}
+
+	var rawPages []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&rawPages); err != nil {
+		return nil, fmt.Errorf("failed to parse page query response: %w", err)
+	}
+	pageList := parsePageList(rawPages)
+	siteLog.Info("QueryPages: server returned pages", "count", len(pageList))
 	return pageList, nil
 }
 
@@ -510,7 +844,13 @@ func (s *WordPressService) GetPageContent(pageID int) (string, error) {
 	siteURL := s.siteURL
 	username := s.username
 	appPassword := s.appPassword
+	offline := s.offline
 	s.mutex.Unlock()
+	contentKey := fmt.Sprintf("the content of page %d", pageID)
+	if offline {
+		var cached string
+		return cached, s.cachedRead(contentKey, &cached)
+	}
 
 	// Create request URL
 	requestURL := fmt.Sprintf("%swp-json/wp/v2/pages/%d", siteURL, pageID)
@@ -532,7 +872,7 @@ func (s *WordPressService) GetPageContent(pageID int) (string, error) {
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch page content: HTTP %d", resp.StatusCode)
+		return "", fmt.Errorf("failed to fetch page content: %w", apiError(resp))
 	}
 
 	// Parse response
@@ -552,11 +892,30 @@ func (s *WordPressService) GetPageContent(pageID int) (string, error) {
 		return "", fmt.Errorf("invalid page content format")
 	}
 
+	s.pageContentSeen(pageID, contentRendered, false)
+	s.cacheRead(contentKey, contentRendered)
+	if modifiedGMT, ok := page["modified_gmt"].(string); ok {
+		if modified, err := time.Parse(wpDateLayout, modifiedGMT); err == nil {
+			s.cacheRead(pageModifiedKey(pageID), modified) // Checked before replaying updates made offline
+		}
+	}
 	return contentRendered, nil
 }
 
 // UpdatePageContent updates the content of a specific page
 func (s *WordPressService) UpdatePageContent(pageID int, newContent string) error {
+	return s.UpdatePageContentContext(context.Background(), pageID, newContent)
+}
+
+// UpdatePageContentContext is UpdatePageContent with a context that cancels
+// the request and carries the trace it belongs to.
+func (s *WordPressService) UpdatePageContentContext(ctx context.Context, pageID int, newContent string) (err error) {
+	ctx, span := tracing.Start(ctx, "wordpress save", attribute.Int("wordpress.page_id", pageID), attribute.Int("wordpress.content_chars", len(newContent)))
+	defer func() { tracing.End(span, err) }()
+	if err := s.CanWrite(); err != nil {
+		return err
+	}
+
 	s.mutex.Lock()
 	if !s.isConnected {
 		s.mutex.Unlock()
@@ -565,7 +924,11 @@ func (s *WordPressService) UpdatePageContent(pageID int, newContent string) erro
 	siteURL := s.siteURL
 	username := s.username
 	appPassword := s.appPassword
+	offline := s.offline
 	s.mutex.Unlock()
+	if offline {
+		return s.queueUpdate(pageID, newContent)
+	}
 
 	// Create request URL
 	requestURL := fmt.Sprintf("%swp-json/wp/v2/pages/%d", siteURL, pageID)
@@ -581,7 +944,7 @@ func (s *WordPressService) UpdatePageContent(pageID int, newContent string) erro
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(bodyJSON))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -599,13 +962,273 @@ func (s *WordPressService) UpdatePageContent(pageID int, newContent string) erro
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to update page content: HTTP %d - %s", resp.StatusCode, string(bodyBytes))
+		return fmt.Errorf("failed to update page content: %w", apiError(resp))
 	}
 
+	s.pageContentSeen(pageID, newContent, true)
+	var updated struct {
+		ModifiedGMT string `json:"modified_gmt"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&updated) == nil {
+		if modified, err := time.Parse(wpDateLayout, updated.ModifiedGMT); err == nil {
+			s.cacheRead(pageModifiedKey(pageID), modified) // This change isn't one to warn about offline
+		}
+	}
 	return nil
 }
 
+// UpdatePageMeta sets a custom field of a page. WordPress ignores fields
+// that aren't registered for the REST API (register_post_meta with
+// show_in_rest), so the response is checked for the new value.
+func (s *WordPressService) UpdatePageMeta(pageID int, key, value string) error {
+	if err := s.CanWrite(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	requestURL := fmt.Sprintf("%swp-json/wp/v2/pages/%d", siteURL, pageID)
+	bodyJSON, err := json.Marshal(map[string]interface{}{
+		"meta": map[string]string{key: value},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create request body: %w", err)
+	}
+	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update page meta: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update page meta: %w", apiError(resp))
+	}
+
+	var page struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return fmt.Errorf("failed to parse page response: %w", err)
+	}
+	if _, ok := page.Meta[key]; !ok {
+		return fmt.Errorf("the site did not store the field %q; it must be registered for the REST API (register_post_meta with show_in_rest)", key)
+	}
+	return nil
+}
+
+// pageAuditFields are the REST fields requested by GetPagesForAudit. The raw
+// excerpt needs the edit context; the rendered one is generated from the
+// content when the page has none.
+const pageAuditFields = "id,title,content,slug,link,status,author,modified,excerpt,yoast_head_json.description"
+
+// GetPagesForAudit fetches every page with its content, excerpt and meta
+// description, in batches of 100.
+func (s *WordPressService) GetPagesForAudit() (PageList, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	siteLog := logger.With(logging.Site(siteURL))
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	var pageList PageList
+	for batch, totalBatches := 1, 1; batch <= totalBatches; batch++ {
+		requestURL := fmt.Sprintf("%swp-json/wp/v2/pages?per_page=100&page=%d&orderby=id&order=asc&context=edit&_fields=%s", siteURL, batch, pageAuditFields)
+		req, err := http.NewRequest("GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request for batch %d: %w", batch, err)
+		}
+		req.SetBasicAuth(username, appPassword)
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch batch %d: %w", batch, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := apiError(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch batch %d: %w", batch, apiErr)
+		}
+		if n, err := strconv.Atoi(resp.Header.Get("X-WP-TotalPages")); err == nil {
+			totalBatches = n
+		}
+		var rawPages []map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&rawPages)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pages response for batch %d: %w", batch, err)
+		}
+		pageList = append(pageList, parsePageList(rawPages)...)
+	}
+	siteLog.Info("GetPagesForAudit: received pages", "count", len(pageList))
+	return pageList, nil
+}
+
+// UpdatePageTitle renames a page.
+func (s *WordPressService) UpdatePageTitle(pageID int, title string) error {
+	return s.updatePageFields(pageID, map[string]interface{}{"title": title})
+}
+
+// UpdatePageExcerpt sets a page's excerpt, which themes and SEO plugins use
+// as the meta description when none is set.
+func (s *WordPressService) UpdatePageExcerpt(pageID int, excerpt string) error {
+	return s.updatePageFields(pageID, map[string]interface{}{"excerpt": excerpt})
+}
+
+// UpdatePageSlug changes a page's slug, and so its URL.
+func (s *WordPressService) UpdatePageSlug(pageID int, slug string) error {
+	return s.updatePageFields(pageID, map[string]interface{}{"slug": slug})
+}
+
+// SlugsInUse returns which of slugs are used by a page or post of any
+// status other than the one with ID exceptID, mapped to the ID using them.
+func (s *WordPressService) SlugsInUse(slugs []string, exceptID int) (map[string]int, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	used := map[string]int{}
+	if len(slugs) == 0 {
+		return used, nil
+	}
+	params := url.Values{}
+	params.Set("slug", strings.Join(slugs, ","))
+	params.Set("status", "any")
+	params.Set("per_page", "100")
+	params.Set("_fields", "id,slug")
+	for _, collection := range []string{"pages", "posts"} {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%swp-json/wp/v2/%s?%s", siteURL, collection, params.Encode()), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.SetBasicAuth(username, appPassword)
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check slugs: %w", err)
+		}
+		var items []struct {
+			ID   int    `json:"id"`
+			Slug string `json:"slug"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := apiError(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to check slugs: %w", apiErr)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&items)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse slug check response: %w", err)
+		}
+		for _, item := range items {
+			if item.ID != exceptID {
+				used[item.Slug] = item.ID
+			}
+		}
+	}
+	return used, nil
+}
+
+// updatePageFields sets core fields of a page.
+func (s *WordPressService) updatePageFields(pageID int, fields map[string]interface{}) error {
+	if err := s.CanWrite(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	bodyJSON, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to create request body: %w", err)
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%swp-json/wp/v2/pages/%d", siteURL, pageID), bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update page: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update page: %w", apiError(resp))
+	}
+	return nil
+}
+
+// SiteInfo is the site-wide metadata published at the REST API root.
+type SiteInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"home"`
+	IconURL     string `json:"site_icon_url"` // Empty if the site has no icon
+}
+
+// GetSiteInfo fetches the connected site's name, tagline, home URL and icon.
+func (s *WordPressService) GetSiteInfo() (SiteInfo, error) {
+	s.mutex.Lock()
+	if !s.isConnected {
+		s.mutex.Unlock()
+		return SiteInfo{}, fmt.Errorf("not connected to WordPress site")
+	}
+	siteURL := s.siteURL
+	username := s.username
+	appPassword := s.appPassword
+	s.mutex.Unlock()
+
+	req, err := http.NewRequest("GET", siteURL+"wp-json/", nil)
+	if err != nil {
+		return SiteInfo{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, appPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return SiteInfo{}, fmt.Errorf("failed to fetch site info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SiteInfo{}, fmt.Errorf("failed to fetch site info: %w", apiError(resp))
+	}
+	var info SiteInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return SiteInfo{}, fmt.Errorf("failed to parse site info: %w", err)
+	}
+	return info, nil
+}
+
 // Disconnect closes the connection to the WordPress site
 func (s *WordPressService) Disconnect() {
 	s.mutex.Lock()
@@ -615,6 +1238,8 @@ func (s *WordPressService) Disconnect() {
 	s.username = ""
 	s.appPassword = ""
 	s.currentSiteName = ""
+	s.capabilities = Capabilities{}
+	s.features = SiteFeatures{}
 
 	// Capture the callback function while holding the lock
 	callback := s.siteChangeCallback
@@ -635,14 +1260,59 @@ func (s *WordPressService) SetSiteChangeCallback(callback func()) {
 	s.siteChangeCallback = callback
 }
 
+// SetPageContentHook sets a function called with the content of every page
+// fetched (saved false) or saved (saved true), e.g. to keep snapshots. It
+// runs on the caller's goroutine, so it should return quickly.
+func (s *WordPressService) SetPageContentHook(hook func(site string, pageID int, content string, saved bool)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pageContentHook = hook
+}
+
+// SetWriteGuard sets a function asked before every write to the site (page
+// content, metadata, media); an error it returns refuses the write.
+func (s *WordPressService) SetWriteGuard(guard func() error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.writeGuard = guard
+}
+
+// CanWrite returns the write guard's error if writes to the site are
+// refused, so callers can check before preparing one.
+func (s *WordPressService) CanWrite() error {
+	s.mutex.Lock()
+	guard := s.writeGuard
+	s.mutex.Unlock()
+	if guard == nil {
+		return nil
+	}
+	return guard()
+}
+
+// pageContentSeen passes page content to the page content hook, if any.
+func (s *WordPressService) pageContentSeen(pageID int, content string, saved bool) {
+	s.mutex.Lock()
+	hook, site := s.pageContentHook, s.currentSiteName
+	s.mutex.Unlock()
+	if hook != nil {
+		hook(site, pageID, content, saved)
+	}
+}
+
 // GetPageScreenshot captures a screenshot of a given URL.
 // Returns PNG image bytes or an error.
 func (s *WordPressService) GetPageScreenshot(pageURL string) ([]byte, error) {
+	return s.captureScreenshot(pageURL, true)
+}
+
+// captureScreenshot loads pageURL in headless Chrome and captures either the
+// full page (JPEG) or just the first 1280x800 viewport (PNG, used for thumbnails).
+func (s *WordPressService) captureScreenshot(pageURL string, fullPage bool) ([]byte, error) {
 	if pageURL == "" {
 		return nil, fmt.Errorf("page URL cannot be empty")
 	}
 
-	log.Printf("Attempting to capture screenshot for: %s", pageURL)
+	logger.Info("Capturing screenshot", "url", pageURL, "full_page", fullPage)
 
 	// --- Chromedp Setup ---
 	// Consider creating context options once, e.g., disabling headless for debugging
@@ -656,7 +1326,9 @@ func (s *WordPressService) GetPageScreenshot(pageURL string) ([]byte, error) {
 	defer cancelAlloc()
 
 	// Create context
-	ctx, cancelCtx := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
+	ctx, cancelCtx := chromedp.NewContext(allocCtx, chromedp.WithLogf(func(format string, args ...any) {
+		logger.Info(fmt.Sprintf(format, args...), "source", "chromedp")
+	}))
 	defer cancelCtx()
 
 	// Create a timeout context
@@ -665,34 +1337,132 @@ func (s *WordPressService) GetPageScreenshot(pageURL string) ([]byte, error) {
 	// --- End Chromedp Setup ---
 
 	var buf []byte
+	capture := chromedp.FullScreenshot(&buf, 90) // 90% quality JPEG, use 0 for PNG
+	if !fullPage {
+		capture = chromedp.CaptureScreenshot(&buf) // Viewport only, PNG
+	}
 	// Capture screenshot
 	err := chromedp.Run(timeoutCtx,
-		// Set viewport size if desired (optional)
-		// emulation.SetDeviceMetricsOverride(1280, 800, 1, false),
+		chromedp.EmulateViewport(1280, 800),
 		chromedp.Navigate(pageURL),
 		// Wait for page load (adjust selector or wait time as needed)
 		// chromedp.WaitVisible(`body`, chromedp.ByQuery), // Wait for body tag
 		chromedp.Sleep(3*time.Second), // Simple wait, adjust as needed
-		// Capture screenshot
-		chromedp.FullScreenshot(&buf, 90), // 90% quality JPEG, use 0 for PNG
-		// Or capture specific element:
-		// chromedp.Screenshot(`#main-content`, &buf, chromedp.NodeVisible, chromedp.ByID),
+		capture,
 	)
 
 	if err != nil {
-		log.Printf("Chromedp error capturing screenshot for %s: %v", pageURL, err)
+		logger.Error("Chromedp error capturing screenshot", "url", pageURL, "error", err)
 		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
 	}
 
 	if len(buf) == 0 {
-		log.Printf("Captured empty screenshot for %s", pageURL)
+		logger.Warn("Captured empty screenshot", "url", pageURL)
 		return nil, fmt.Errorf("captured empty screenshot")
 	}
 
-	log.Printf("Successfully captured screenshot for %s (size: %d bytes)", pageURL, len(buf))
+	logger.Info("Captured screenshot", "url", pageURL, "bytes", len(buf))
 	return buf, nil
 }
 
+// screenshots returns the screenshot cache, creating it on first use. It lives
+// in the state database when there is one, otherwise on disk.
+func (s *WordPressService) screenshots() (*ScreenshotCache, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.screenshotCache != nil {
+		return s.screenshotCache, nil
+	}
+	if s.db != nil {
+		s.screenshotCache = NewDBScreenshotCache(s.db)
+		return s.screenshotCache, nil
+	}
+	configDir, err := s.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := NewScreenshotCache(filepath.Join(configDir, "screenshots"))
+	if err != nil {
+		return nil, err
+	}
+	s.screenshotCache = cache
+	return cache, nil
+}
+
+// GetCachedPageScreenshot returns the cached full-size screenshot of a page, if any.
+// It never starts a capture.
+func (s *WordPressService) GetCachedPageScreenshot(page Page) ([]byte, bool) {
+	cache, err := s.screenshots()
+	if err != nil {
+		return nil, false
+	}
+	return cache.Get(page.Link, page.Modified, "full")
+}
+
+// GetPageScreenshotCached returns the full-size screenshot of a page, capturing
+// and caching it if the page (at its current modified date) has not been captured yet.
+func (s *WordPressService) GetPageScreenshotCached(page Page) ([]byte, error) {
+	cache, err := s.screenshots()
+	if err != nil {
+		logger.Warn("Screenshot cache unavailable", "error", err)
+		return s.GetPageScreenshot(page.Link)
+	}
+	if data, ok := cache.Get(page.Link, page.Modified, "full"); ok {
+		logger.Info("Using cached screenshot", "url", page.Link)
+		return data, nil
+	}
+
+	data, err := s.GetPageScreenshot(page.Link)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Put(page.Link, page.Modified, "full", data); err != nil {
+		logger.Warn("Could not cache screenshot", "error", err)
+	}
+	// A full capture also gives us the thumbnail for free
+	if thumb, err := MakeThumbnail(data, ThumbnailWidth); err == nil {
+		cache.Put(page.Link, page.Modified, "thumb", thumb)
+	}
+	return data, nil
+}
+
+// GetPageThumbnail returns a small PNG thumbnail of a page. It is built from a
+// cached full screenshot when available, otherwise from a quick viewport capture.
+func (s *WordPressService) GetPageThumbnail(page Page) ([]byte, error) {
+	cache, err := s.screenshots()
+	if err != nil {
+		return nil, err
+	}
+	if thumb, ok := cache.Get(page.Link, page.Modified, "thumb"); ok {
+		return thumb, nil
+	}
+
+	source, ok := cache.Get(page.Link, page.Modified, "full")
+	if !ok {
+		source, err = s.captureScreenshot(page.Link, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	thumb, err := MakeThumbnail(source, ThumbnailWidth)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Put(page.Link, page.Modified, "thumb", thumb); err != nil {
+		logger.Warn("Could not cache thumbnail", "error", err)
+	}
+	return thumb, nil
+}
+
+// ClearScreenshotCache deletes all cached screenshots and thumbnails.
+func (s *WordPressService) ClearScreenshotCache() error {
+	cache, err := s.screenshots()
+	if err != nil {
+		return err
+	}
+	return cache.Clear()
+}
+
 // Ensure your Page struct includes the public URL ('Link')
 // You might need to adjust GetPages to fetch this field if it doesn't already.
 // Example modification in GetPages:
@@ -0,0 +1,539 @@
+package wordpress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Inference_Engine/storage"
+)
+
+func TestSetStateStoreImportsSavedSites(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacy := filepath.Join(home, ".wordpress-inference", "saved_sites.json")
+	os.MkdirAll(filepath.Dir(legacy), 0o700)
+	os.WriteFile(legacy, []byte(`[{"name":"Blog","url":"https://blog.example","username":"admin","appPassword":"`+encryptPassword("secret")+`"}]`), 0o600)
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	s := NewWordPressService()
+	if err := s.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	if site, ok := s.GetSavedSite("Blog"); !ok || site.AppPassword != "secret" {
+		t.Fatalf("Expected the legacy site to be imported, got %+v", site)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("Expected saved_sites.json to be renamed after import")
+	}
+	if err := s.SaveSite("Shop", "https://shop.example", "editor", "pw"); err != nil {
+		t.Fatalf("SaveSite failed: %v", err)
+	}
+
+	reopened := NewWordPressService()
+	if err := reopened.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	sites := reopened.GetSavedSites()
+	if len(sites) != 2 || sites[0].Name != "Blog" || sites[1].Name != "Shop" {
+		t.Errorf("Expected both sites in order, got %+v", sites)
+	}
+}
+
+// mapSecrets is an in-memory SecretStore.
+type mapSecrets map[string]string
+
+func (m mapSecrets) Get(name string) (string, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+func (m mapSecrets) Set(name, value string) error {
+	if value == "" {
+		delete(m, name)
+	} else {
+		m[name] = value
+	}
+	return nil
+}
+
+func TestSetSecretStoreMovesPasswords(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+
+	s := NewWordPressService()
+	if err := s.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	if err := s.SaveSite("Blog", "https://blog.example", "admin", "secret"); err != nil {
+		t.Fatalf("SaveSite failed: %v", err)
+	}
+
+	secrets := mapSecrets{}
+	if err := s.SetSecretStore(secrets); err != nil {
+		t.Fatalf("SetSecretStore failed: %v", err)
+	}
+	if secrets["wordpress:Blog"] != "secret" || s.GetSavedSites()[0].AppPassword != "" {
+		t.Errorf("Expected the password to move to the store, got %v and %+v", secrets, s.GetSavedSites())
+	}
+	if err := s.SaveSite("Shop", "https://shop.example", "editor", "pw"); err != nil {
+		t.Fatalf("SaveSite failed: %v", err)
+	}
+	if site, _ := s.GetSavedSite("Shop"); site.AppPassword != "pw" || secrets["wordpress:Shop"] != "pw" {
+		t.Errorf("Expected new passwords to go to the store, got %+v", site)
+	}
+
+	var stored string
+	db.QueryRow(`SELECT app_password FROM sites WHERE name = 'Blog'`).Scan(&stored)
+	if stored != "" {
+		t.Errorf("Expected no password left in the database, got %q", stored)
+	}
+	if err := s.DeleteSavedSite("Blog"); err != nil {
+		t.Fatalf("DeleteSavedSite failed: %v", err)
+	}
+	if _, ok := secrets["wordpress:Blog"]; ok {
+		t.Errorf("Expected deleting the site to remove its password")
+	}
+}
+
+// connectedTo returns a service connected to a test server, without the
+// credential check Connect makes.
+func connectedTo(server *httptest.Server) *WordPressService {
+	s := NewWordPressService()
+	s.siteURL = server.URL + "/"
+	s.username, s.appPassword = "admin", "secret"
+	s.isConnected = true
+	return s
+}
+
+func TestUpdatePageMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta map[string]string `json:"meta"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/wp-json/wp/v2/pages/7" {
+			http.NotFound(w, r)
+			return
+		}
+		// Like WordPress, only registered fields are stored and returned
+		meta := map[string]string{}
+		if value, ok := body.Meta["schema_json_ld"]; ok {
+			meta["schema_json_ld"] = value
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "meta": meta})
+	}))
+	defer server.Close()
+
+	s := connectedTo(server)
+	if err := s.UpdatePageMeta(7, "schema_json_ld", `{"@type": "Article"}`); err != nil {
+		t.Errorf("UpdatePageMeta failed: %v", err)
+	}
+	if err := s.UpdatePageMeta(7, "unregistered", "x"); err == nil || !strings.Contains(err.Error(), "register_post_meta") {
+		t.Errorf("Expected an unregistered field to be reported, got %v", err)
+	}
+}
+
+func TestWriteGuardRefusesWrites(t *testing.T) {
+	writes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writes++
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+
+	s := connectedTo(server)
+	s.SetWriteGuard(func() error { return errors.New("read-only profile") })
+	if err := s.UpdatePageContent(7, "<p>New</p>"); err == nil || err.Error() != "read-only profile" {
+		t.Errorf("UpdatePageContent = %v, want the guard's error", err)
+	}
+	if err := s.UpdatePageSlug(7, "new-slug"); err == nil {
+		t.Error("Expected UpdatePageSlug to be refused")
+	}
+	if err := s.UpdateMediaText(3, "A cat", ""); err == nil {
+		t.Error("Expected UpdateMediaText to be refused")
+	}
+	if writes != 0 {
+		t.Errorf("Expected no request to reach the site, got %d", writes)
+	}
+	s.SetWriteGuard(nil)
+	if err := s.UpdatePageContent(7, "<p>New</p>"); err != nil {
+		t.Errorf("UpdatePageContent without a guard failed: %v", err)
+	}
+}
+
+func TestGetSiteInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "Corner Cafe", "description": "Coffee and cake", "home": "https://cafe.example", "site_icon_url": ""}`))
+	}))
+	defer server.Close()
+
+	info, err := connectedTo(server).GetSiteInfo()
+	if err != nil {
+		t.Fatalf("GetSiteInfo failed: %v", err)
+	}
+	if info.Name != "Corner Cafe" || info.Description != "Coffee and cake" || info.URL != "https://cafe.example" {
+		t.Errorf("Unexpected site info: %+v", info)
+	}
+}
+
+func TestGetPagesForAudit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("context") != "edit" {
+			http.Error(w, "expected the edit context", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-WP-TotalPages", "2")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Write([]byte(`[{"id": 1, "title": {"rendered": "Home"}, "excerpt": {"raw": "", "rendered": "<p>Generated</p>"}, "yoast_head_json": {"description": "Welcome home"}}]`))
+		case "2":
+			w.Write([]byte(`[{"id": 2, "title": {"rendered": "About"}, "excerpt": {"raw": "Who we are", "rendered": "<p>Who we are</p>"}}]`))
+		default:
+			http.Error(w, "past the end", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	pages, err := connectedTo(server).GetPagesForAudit()
+	if err != nil {
+		t.Fatalf("GetPagesForAudit failed: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(pages))
+	}
+	if pages[0].Excerpt != "" || pages[0].MetaDescription != "Welcome home" {
+		t.Errorf("Unexpected first page: %+v", pages[0])
+	}
+	if pages[1].Excerpt != "Who we are" || pages[1].MetaDescription != "" {
+		t.Errorf("Unexpected second page: %+v", pages[1])
+	}
+}
+
+func TestSlugsInUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slug") != "cold-brew,cold-brew-2" || r.URL.Query().Get("status") != "any" {
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/wp-json/wp/v2/pages":
+			w.Write([]byte(`[{"id": 3, "slug": "cold-brew"}]`))
+		case "/wp-json/wp/v2/posts":
+			w.Write([]byte(`[{"id": 9, "slug": "cold-brew-2"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	used, err := connectedTo(server).SlugsInUse([]string{"cold-brew", "cold-brew-2"}, 3)
+	if err != nil {
+		t.Fatalf("SlugsInUse failed: %v", err)
+	}
+	if len(used) != 1 || used["cold-brew-2"] != 9 {
+		t.Errorf("Expected only the post's slug to be in use, got %v", used)
+	}
+}
+
+func TestUpdatePageTitle(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/wp-json/wp/v2/pages/3" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id": 3}`))
+	}))
+	defer server.Close()
+
+	if err := connectedTo(server).UpdatePageTitle(3, "New title"); err != nil {
+		t.Fatalf("UpdatePageTitle failed: %v", err)
+	}
+	if len(got) != 1 || got["title"] != "New title" {
+		t.Errorf("Unexpected request body: %v", got)
+	}
+	if err := connectedTo(server).UpdatePageExcerpt(4, "Summary"); err == nil {
+		t.Errorf("Expected an error for a missing page")
+	}
+}
+
+func TestMediaBackfillRequests(t *testing.T) {
+	var update map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/wp-json/wp/v2/media":
+			if r.URL.Query().Get("context") != "edit" || r.URL.Query().Get("media_type") != "image" {
+				http.Error(w, "unexpected query", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-WP-TotalPages", "1")
+			w.Write([]byte(`[{"id": 5, "source_url": "https://example.com/a.jpg", "mime_type": "image/jpeg",
+				"alt_text": "", "title": {"raw": "a"}, "caption": {"raw": " A dog "}}]`))
+		case r.Method == "POST" && r.URL.Path == "/wp-json/wp/v2/media/5":
+			json.NewDecoder(r.Body).Decode(&update)
+			w.Write([]byte(`{"id": 5}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := connectedTo(server)
+	items, batches, err := s.GetMediaBatch(1, 100)
+	if err != nil {
+		t.Fatalf("GetMediaBatch failed: %v", err)
+	}
+	if batches != 1 || len(items) != 1 || items[0].Caption != "A dog" || items[0].Title != "a" {
+		t.Fatalf("Unexpected media batch %d: %+v", batches, items)
+	}
+	if err := s.UpdateMediaText(5, "A dog on a beach", ""); err != nil {
+		t.Fatalf("UpdateMediaText failed: %v", err)
+	}
+	if len(update) != 1 || update["alt_text"] != "A dog on a beach" {
+		t.Errorf("Unexpected update body: %v", update)
+	}
+}
+
+func TestGetRecentComments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wp/v2/comments" || r.URL.Query().Get("post") != "12" || r.URL.Query().Get("per_page") != "100" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"id": 4, "author_name": "Ann", "date_gmt": "2025-03-02T10:00:00",
+			"content": {"rendered": "<p>Which plan includes support?</p>"}}]`))
+	}))
+	defer server.Close()
+
+	comments, err := connectedTo(server).GetRecentComments(12, 500)
+	if err != nil {
+		t.Fatalf("GetRecentComments failed: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Author != "Ann" || comments[0].Date.Day() != 2 || comments[0].Content != "<p>Which plan includes support?</p>" {
+		t.Errorf("Unexpected comments %+v", comments)
+	}
+}
+
+func TestOfflineReadsCacheAndQueuesUpdates(t *testing.T) {
+	var saved []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"id": 7, "content": {"rendered": "<p>Old</p>"}}`))
+			return
+		}
+		var body struct {
+			Content string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		saved = append(saved, body.Content)
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+	s := connectedTo(server)
+	if err := s.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	if _, err := s.GetPageContent(7); err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+
+	s.SetOffline(true)
+	if content, err := s.GetPageContent(7); err != nil || content != "<p>Old</p>" {
+		t.Errorf("GetPageContent offline = %q, %v, want the cached content", content, err)
+	}
+	if _, err := s.GetPageContent(8); err == nil {
+		t.Error("Expected a page not read online to fail offline")
+	}
+	const updated = "<!-- wp:paragraph -->\n<p>New</p>\n<!-- /wp:paragraph -->"
+	if err := s.UpdatePageContent(7, updated); !errors.Is(err, ErrQueuedOffline) {
+		t.Errorf("UpdatePageContent offline = %v, want ErrQueuedOffline", err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("Expected no update to reach the site offline, got %v", saved)
+	}
+
+	reopened := connectedTo(server)
+	if err := reopened.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	if queued := reopened.QueuedUpdates(); len(queued) != 1 || queued[0].PageID != 7 {
+		t.Fatalf("Expected the queued update to be kept, got %+v", queued)
+	}
+	if n, err := reopened.ReplayQueued(context.Background()); err != nil || n != 1 {
+		t.Errorf("ReplayQueued = %d, %v, want 1 update saved", n, err)
+	}
+	if len(saved) != 1 || saved[0] != updated || len(reopened.QueuedUpdates()) != 0 {
+		t.Errorf("Expected the queued update to be saved once, got %v", saved)
+	}
+}
+
+func TestReplayQueuedStopsOnSiteChanges(t *testing.T) {
+	modified := "2025-03-01T10:00:00"
+	var saved []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("_fields") == "modified_gmt":
+			w.Write([]byte(`{"modified_gmt": "` + modified + `"}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"id": 7, "content": {"rendered": "<p>Old</p>"}, "modified_gmt": "` + modified + `"}`))
+		default:
+			var body struct {
+				Content string `json:"content"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			saved = append(saved, body.Content)
+			modified = "2025-03-05T09:00:00"
+			w.Write([]byte(`{"id": 7, "modified_gmt": "` + modified + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("storage.Open failed: %v", err)
+	}
+	defer db.Close()
+	s := connectedTo(server)
+	if err := s.SetStateStore(db); err != nil {
+		t.Fatalf("SetStateStore failed: %v", err)
+	}
+	if _, err := s.GetPageContent(7); err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	s.SetOffline(true)
+	s.UpdatePageContent(7, "<p>First</p>")
+	s.UpdatePageContent(7, "<p>Second</p>")
+	s.SetOffline(false)
+
+	modified = "2025-03-02T08:00:00" // Someone else edits the page meanwhile
+	n, err := s.ReplayQueued(context.Background())
+	var conflict *QueuedConflictError
+	if !errors.As(err, &conflict) || n != 0 {
+		t.Fatalf("ReplayQueued = %d, %v, want a conflict", n, err)
+	}
+	if conflict.Update.Content != "<p>First</p>" || conflict.Modified.Day() != 2 || len(saved) != 0 {
+		t.Errorf("Expected the first update to stop unsaved at the change, got %+v and %v", conflict, saved)
+	}
+	if len(s.QueuedUpdates()) != 2 {
+		t.Errorf("Expected both updates to stay queued, got %+v", s.QueuedUpdates())
+	}
+
+	// Overwriting saves both; the second follows the first, not a change on the site
+	if err := s.ResolveQueued(conflict.Update, true); err != nil {
+		t.Fatalf("ResolveQueued failed: %v", err)
+	}
+	if n, err := s.ReplayQueued(context.Background()); err != nil || n != 2 {
+		t.Errorf("ReplayQueued = %d, %v, want 2 updates saved", n, err)
+	}
+	if len(saved) != 2 || saved[0] != "<p>First</p>" || saved[1] != "<p>Second</p>" {
+		t.Errorf("Expected both updates saved in order, got %v", saved)
+	}
+
+	// An update made offline after an online save isn't held up by that save
+	s.SetOffline(true)
+	s.UpdatePageContent(7, "<p>Third</p>")
+	s.SetOffline(false)
+	if n, err := s.ReplayQueued(context.Background()); err != nil || n != 1 {
+		t.Errorf("ReplayQueued = %d, %v, want 1 update saved", n, err)
+	}
+
+	// Discarding drops the update
+	s.SetOffline(true)
+	s.UpdatePageContent(7, "<p>Fourth</p>")
+	s.SetOffline(false)
+	modified = "2025-03-06T12:00:00"
+	if _, err := s.ReplayQueued(context.Background()); !errors.As(err, &conflict) {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+	if err := s.ResolveQueued(conflict.Update, false); err != nil {
+		t.Fatalf("ResolveQueued failed: %v", err)
+	}
+	if n, err := s.ReplayQueued(context.Background()); err != nil || n != 0 || len(saved) != 3 || len(s.QueuedUpdates()) != 0 {
+		t.Errorf("Expected the discarded update to be dropped unsaved, got %d, %v, %v", n, err, saved)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/wp/v2/pages/7":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"code":"rest_cannot_edit","message":"Sorry, you are not allowed to edit this post.","data":{"status":403}}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html><body><h1>502 Bad Gateway</h1></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	s := connectedTo(server)
+	err := s.UpdatePageContent(7, "<p>New</p>")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden || apiErr.Code != "rest_cannot_edit" {
+		t.Fatalf("Expected the WordPress error to be decoded, got %v", err)
+	}
+	if want := "failed to update page content: rest_cannot_edit: Sorry, you are not allowed to edit this post."; err.Error() != want {
+		t.Errorf("Error = %q, want %q", err, want)
+	}
+	if _, err := s.GetSiteInfo(); err == nil || !strings.HasSuffix(err.Error(), "HTTP 502: 502 Bad Gateway") {
+		t.Errorf("Expected a non-WordPress error page as text, got %v", err)
+	}
+}
+
+func TestConnectReadsCapabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/wp/v2/users/me":
+			if r.URL.Query().Get("context") != "edit" {
+				t.Errorf("Expected the edit context, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"name":"Alice","roles":["contributor"],"capabilities":{"edit_pages":true,"read":true}}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewWordPressService()
+	if err := s.Connect(server.URL, "alice", "secret"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	caps := s.Capabilities()
+	if !caps.Known || caps.Allows(ActionPublish) || caps.Allows(ActionUpload) {
+		t.Errorf("Expected a contributor to be refused publishing and uploads, got %+v", caps)
+	}
+	err := s.CanDo(ActionPublish)
+	if err == nil || !strings.Contains(err.Error(), "Alice (contributor)") || !strings.Contains(err.Error(), "edit_published_pages, publish_pages") {
+		t.Errorf("Expected the missing capabilities to be explained, got %v", err)
+	}
+	if (Capabilities{}).Check(ActionDelete) != nil {
+		t.Error("Expected unknown capabilities to allow every action")
+	}
+	s.Disconnect()
+	if s.Capabilities().Known {
+		t.Error("Expected the capabilities to be forgotten on disconnect")
+	}
+}